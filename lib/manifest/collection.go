@@ -10,13 +10,47 @@
 package manifest
 
 type CollectionInfo struct {
-	Name          string                      `json:"-"`
-	SearchMethods map[string]SearchMethodInfo `json:"searchMethods"`
+	Name               string                      `json:"-"`
+	SearchMethods      map[string]SearchMethodInfo `json:"searchMethods"`
+	DuplicateDetection *DuplicateDetectionInfo     `json:"duplicateDetection,omitempty"`
+	PIIDetection       *PIIDetectionInfo           `json:"piiDetection,omitempty"`
+	Access             *CollectionAccessInfo       `json:"access,omitempty"`
+	SoftDelete         *SoftDeleteInfo             `json:"softDelete,omitempty"`
+	Triggers           *CollectionTriggersInfo     `json:"triggers,omitempty"`
+	GraphQL            *CollectionGraphQLInfo      `json:"graphql,omitempty"`
+
+	// Compression is the codec that the collection's texts are compressed with in memory.
+	Compression TextCompression `json:"compression,omitempty"`
 }
 
+type TextCompression string
+
+const (
+	TextCompressionNone   TextCompression = "none"
+	TextCompressionZstd   TextCompression = "zstd"
+	TextCompressionSnappy TextCompression = "snappy"
+)
+
 type SearchMethodInfo struct {
-	Embedder string    `json:"embedder"`
-	Index    IndexInfo `json:"index"`
+	Embedder string `json:"embedder"`
+
+	// ImageEmbedder embeds image items, and images that are searched for.  When both embedders are set,
+	// they must produce vectors in the same space.
+	ImageEmbedder string `json:"imageEmbedder,omitempty"`
+
+	// NamespaceEmbedders overrides the embedder of text items in specific namespaces, such as a language-specific
+	// model for each locale namespace.  Each namespace has its own vector index, so the embedders may differ in dimensions.
+	NamespaceEmbedders map[string]string `json:"namespaceEmbedders,omitempty"`
+
+	Index IndexInfo `json:"index"`
+}
+
+// EmbedderForNamespace returns the embedder of text items in the namespace.
+func (s SearchMethodInfo) EmbedderForNamespace(namespace string) string {
+	if embedder, ok := s.NamespaceEmbedders[namespace]; ok {
+		return embedder
+	}
+	return s.Embedder
 }
 
 type IndexInfo struct {
@@ -25,6 +59,95 @@ type IndexInfo struct {
 }
 
 type OptionsInfo struct {
-	EfConstruction int `json:"efConstruction"`
-	MaxLevels      int `json:"maxLevels"`
+	EfConstruction int          `json:"efConstruction"`
+	MaxLevels      int          `json:"maxLevels"`
+	TargetRecall   float64      `json:"targetRecall,omitempty"`
+	Staging        *StagingInfo `json:"staging,omitempty"`
+}
+
+// StagingInfo configures staging of inserted vectors in a brute-force buffer, which is searchable immediately
+// and merged into the index in the background.
+type StagingInfo struct {
+	MergeBudget    float64 `json:"mergeBudget,omitempty"`
+	MergeBatchSize int     `json:"mergeBatchSize,omitempty"`
+}
+
+type DuplicatePolicy string
+
+const (
+	DuplicatePolicySkip    DuplicatePolicy = "skip"
+	DuplicatePolicyReplace DuplicatePolicy = "replace"
+	DuplicatePolicyFlag    DuplicatePolicy = "flag"
+)
+
+type DuplicateDetectionInfo struct {
+	SearchMethod string          `json:"searchMethod"`
+	Threshold    float64         `json:"threshold"`
+	Policy       DuplicatePolicy `json:"policy"`
+}
+
+type PIIPolicy string
+
+const (
+	PIIPolicyRedact PIIPolicy = "redact"
+	PIIPolicyReject PIIPolicy = "reject"
+	PIIPolicyFlag   PIIPolicy = "flag"
+)
+
+const (
+	PIITypeEmail      = "email"
+	PIITypePhone      = "phone"
+	PIITypeCreditCard = "creditCard"
+	PIITypeSSN        = "ssn"
+	PIITypeIPAddress  = "ipAddress"
+)
+
+// PIIDetectionInfo configures detection of personal information in texts upserted into the collection.
+// When no types are listed, emails and phone numbers are detected.
+type PIIDetectionInfo struct {
+	Types    []string          `json:"types,omitempty"`
+	Patterns map[string]string `json:"patterns,omitempty"`
+	Policy   PIIPolicy         `json:"policy"`
+}
+
+type CollectionPermission string
+
+const (
+	CollectionPermissionRead   CollectionPermission = "read"
+	CollectionPermissionSearch CollectionPermission = "search"
+	CollectionPermissionWrite  CollectionPermission = "write"
+	CollectionPermissionAdmin  CollectionPermission = "admin"
+)
+
+// CollectionAccessRoleAny is the role that applies to every caller, including unauthenticated callers.
+const CollectionAccessRoleAny = "*"
+
+type CollectionAccessInfo struct {
+	RolesClaim string                            `json:"rolesClaim,omitempty"`
+	Roles      map[string][]CollectionPermission `json:"roles"`
+}
+
+type SoftDeleteInfo struct {
+	RetentionDays *int `json:"retentionDays,omitempty"`
+}
+
+type CollectionTriggersInfo struct {
+	OnUpsert string `json:"onUpsert,omitempty"`
+	OnDelete string `json:"onDelete,omitempty"`
+}
+
+type CollectionOperation string
+
+const (
+	CollectionOperationSearch   CollectionOperation = "search"
+	CollectionOperationClassify CollectionOperation = "classify"
+	CollectionOperationVector   CollectionOperation = "vector"
+	CollectionOperationUpsert   CollectionOperation = "upsert"
+	CollectionOperationDelete   CollectionOperation = "delete"
+)
+
+// CollectionGraphQLInfo enables built-in GraphQL fields for the collection.
+// When no operations are listed, fields are generated for all operations.
+type CollectionGraphQLInfo struct {
+	Operations []CollectionOperation `json:"operations,omitempty"`
 }
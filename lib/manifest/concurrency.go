@@ -0,0 +1,39 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+// ConcurrencyInfo declares how concurrent calls to functions are coordinated.
+type ConcurrencyInfo struct {
+	Functions map[string]FunctionConcurrencyInfo `json:"functions,omitempty"`
+}
+
+// FunctionConcurrencyInfo declares that calls to a function execute one at a time for each value of a key,
+// taken from the function's arguments.  Functions in the same group share the same keys, so that, for example,
+// all mutations of a user's data can be serialized by the user's id.  The group defaults to the function name.
+type FunctionConcurrencyInfo struct {
+	SerializeBy string `json:"serializeBy"`
+	Group       string `json:"group,omitempty"`
+}
+
+// SerializationFor returns the serialization settings for the given function,
+// or false if its calls may execute concurrently.
+func (c *ConcurrencyInfo) SerializationFor(fnName string) (FunctionConcurrencyInfo, bool) {
+	if c == nil {
+		return FunctionConcurrencyInfo{}, false
+	}
+	info, ok := c.Functions[fnName]
+	if !ok || info.SerializeBy == "" {
+		return FunctionConcurrencyInfo{}, false
+	}
+	if info.Group == "" {
+		info.Group = fnName
+	}
+	return info, true
+}
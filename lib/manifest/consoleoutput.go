@@ -0,0 +1,32 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+// ConsoleOutputInfo limits how much of each function invocation's stdout and stderr is captured.
+// A limit of zero means that output is not limited.
+type ConsoleOutputInfo struct {
+	MaxBytes *int           `json:"maxBytes,omitempty"`
+	Plugins  map[string]int `json:"plugins,omitempty"`
+}
+
+// MaxBytesFor returns the maximum number of bytes captured from each of stdout and stderr,
+// for an invocation of a function in the given plugin.  The default is used if no limit is set.
+func (c *ConsoleOutputInfo) MaxBytesFor(pluginName string, defaultMaxBytes int) int {
+	if c == nil {
+		return defaultMaxBytes
+	}
+	if maxBytes, ok := c.Plugins[pluginName]; ok {
+		return maxBytes
+	}
+	if c.MaxBytes != nil {
+		return *c.MaxBytes
+	}
+	return defaultMaxBytes
+}
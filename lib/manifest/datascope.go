@@ -0,0 +1,52 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+import "slices"
+
+type DataScopeMode string
+
+const (
+	DataScopeModeShared DataScopeMode = "shared"
+	DataScopeModePlugin DataScopeMode = "plugin"
+)
+
+// DataScopeInfo controls whether the data that plugins store in collections and sessions is shared between them.
+// In plugin mode, the namespaces of collections and the keys of sessions are scoped to the plugin that uses them,
+// unless the collection or key is declared as shared.
+type DataScopeInfo struct {
+	Mode              DataScopeMode `json:"mode,omitempty"`
+	SharedCollections []string      `json:"sharedCollections,omitempty"`
+	SharedKeys        []string      `json:"sharedKeys,omitempty"`
+}
+
+// IsPluginScoped reports whether data is scoped to the plugin that uses it.
+func (d *DataScopeInfo) IsPluginScoped() bool {
+	return d != nil && d.Mode == DataScopeModePlugin
+}
+
+// IsSharedCollection reports whether the collection is shared by all plugins.
+func (d *DataScopeInfo) IsSharedCollection(collectionName string) bool {
+	return !d.IsPluginScoped() || slices.Contains(d.SharedCollections, collectionName)
+}
+
+// IsSharedKey reports whether the key is shared by all plugins.  The shared keys are patterns
+// in which * matches any sequence of characters.
+func (d *DataScopeInfo) IsSharedKey(key string) bool {
+	if !d.IsPluginScoped() {
+		return true
+	}
+	for _, pattern := range d.SharedKeys {
+		if matchWildcard(pattern, key) {
+			return true
+		}
+	}
+	return false
+}
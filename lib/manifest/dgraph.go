@@ -16,6 +16,7 @@ type DgraphConnectionInfo struct {
 	Type       ConnectionType `json:"type"`
 	GrpcTarget string         `json:"grpcTarget"`
 	Key        string         `json:"key"`
+	TLS        *TLSInfo       `json:"tls,omitempty"`
 }
 
 func (info DgraphConnectionInfo) ConnectionName() string {
@@ -27,9 +28,9 @@ func (info DgraphConnectionInfo) ConnectionType() ConnectionType {
 }
 
 func (info DgraphConnectionInfo) Hash() string {
-	return computeHash(info.Name, info.Type, info.GrpcTarget)
+	return computeHash(append([]any{info.Name, info.Type, info.GrpcTarget}, info.TLS.hashElements()...)...)
 }
 
 func (info DgraphConnectionInfo) Variables() []string {
-	return extractVariables(info.Key)
+	return append(extractVariables(info.Key), info.TLS.variables()...)
 }
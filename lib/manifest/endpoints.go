@@ -19,6 +19,7 @@ type EndpointType string
 
 const (
 	EndpointTypeGraphQL EndpointType = "graphql"
+	EndpointTypeWebhook EndpointType = "webhook"
 )
 
 type EndpointAuthType string
@@ -46,3 +47,52 @@ func (e GraphqlEndpointInfo) EndpointType() EndpointType {
 func (e GraphqlEndpointInfo) EndpointAuth() EndpointAuthType {
 	return e.Auth
 }
+
+// WebhookEndpointInfo is an endpoint that receives webhook deliveries from an external service, and passes
+// the payload of each delivery to a function.  The delivery is verified before the function is called.
+type WebhookEndpointInfo struct {
+	Name     string             `json:"-"`
+	Type     EndpointType       `json:"type"`
+	Path     string             `json:"path"`
+	Function string             `json:"function"`
+	Verify   *WebhookVerifyInfo `json:"verify,omitempty"`
+}
+
+func (e WebhookEndpointInfo) EndpointName() string {
+	return e.Name
+}
+
+func (e WebhookEndpointInfo) EndpointType() EndpointType {
+	return e.Type
+}
+
+// EndpointAuth returns none, because a webhook delivery is authenticated by its signature rather than a token.
+func (e WebhookEndpointInfo) EndpointAuth() EndpointAuthType {
+	return EndpointAuthNone
+}
+
+type WebhookProvider string
+
+const (
+	WebhookProviderStripe WebhookProvider = "stripe"
+	WebhookProviderGitHub WebhookProvider = "github"
+	WebhookProviderSlack  WebhookProvider = "slack"
+)
+
+const defaultWebhookToleranceSeconds = 300
+
+// WebhookVerifyInfo declares how the signature of a webhook delivery is verified, using the signature
+// scheme of the provider that sends it, and the name of the secret that the deliveries are signed with.
+type WebhookVerifyInfo struct {
+	Provider         WebhookProvider `json:"provider"`
+	Secret           string          `json:"secret"`
+	ToleranceSeconds int             `json:"toleranceSeconds,omitempty"`
+}
+
+// Tolerance returns how old a delivery can be, by its signed timestamp, before it is rejected as a replay.
+func (v *WebhookVerifyInfo) Tolerance() int {
+	if v.ToleranceSeconds > 0 {
+		return v.ToleranceSeconds
+	}
+	return defaultWebhookToleranceSeconds
+}
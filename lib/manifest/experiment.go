@@ -0,0 +1,38 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+// DefaultExperimentStickyClaim is the JWT claim used to identify callers when assigning them to experiment variants.
+const DefaultExperimentStickyClaim = "sub"
+
+// ExperimentInfo splits the traffic for a model or prompt across variants.  Exactly one of Model or Prompt is set.
+type ExperimentInfo struct {
+	Name        string                  `json:"-"`
+	Model       string                  `json:"model,omitempty"`
+	Prompt      string                  `json:"prompt,omitempty"`
+	StickyClaim string                  `json:"stickyClaim,omitempty"`
+	Variants    []ExperimentVariantInfo `json:"variants"`
+}
+
+// ExperimentVariantInfo is one arm of an experiment.  For a model experiment, Model names the model that
+// serves the variant.  For a prompt experiment, Version names the prompt version that is rendered.
+type ExperimentVariantInfo struct {
+	Name    string `json:"name"`
+	Weight  int    `json:"weight"`
+	Model   string `json:"model,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+func (e *ExperimentInfo) GetStickyClaim() string {
+	if e.StickyClaim == "" {
+		return DefaultExperimentStickyClaim
+	}
+	return e.StickyClaim
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+import "strings"
+
+type FaultTarget string
+
+const (
+	FaultTargetHttp       FaultTarget = "http"
+	FaultTargetModel      FaultTarget = "model"
+	FaultTargetCollection FaultTarget = "collection"
+)
+
+type FaultType string
+
+const (
+	FaultTypeLatency   FaultType = "latency"
+	FaultTypeError     FaultType = "error"
+	FaultTypeMalformed FaultType = "malformed"
+)
+
+// FaultsInfo declares faults to inject into calls that functions make to external services, so that their error
+// handling can be tested.  Faults are only injected in development and test environments.
+type FaultsInfo struct {
+	Seed  *uint64         `json:"seed,omitempty"`
+	Rules []FaultRuleInfo `json:"rules"`
+}
+
+// FaultRuleInfo injects a fault into a share of the calls to a target.
+type FaultRuleInfo struct {
+	Target      FaultTarget `json:"target"`
+	Match       string      `json:"match,omitempty"`
+	Fault       FaultType   `json:"fault"`
+	Probability float64     `json:"probability"`
+	LatencyMs   int         `json:"latencyMs,omitempty"`
+	Message     string      `json:"message,omitempty"`
+}
+
+// Matches reports whether the rule applies to a call to the target, which is identified by a URL for
+// HTTP requests, a model name for model invocations, or a collection name for collection operations.
+// The match is a pattern in which * matches any sequence of characters.  An empty match applies to all calls.
+func (r *FaultRuleInfo) Matches(target FaultTarget, name string) bool {
+	return r.Target == target && (r.Match == "" || matchWildcard(r.Match, name))
+}
+
+func matchWildcard(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return len(s) >= len(last) && strings.HasSuffix(s, last)
+}
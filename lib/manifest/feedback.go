@@ -0,0 +1,35 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+const (
+	DefaultFeedbackMinRating = 1
+	DefaultFeedbackMaxRating = 5
+)
+
+// FeedbackInfo configures how user feedback on function executions is accepted.
+type FeedbackInfo struct {
+	// GraphQL adds a recordFeedback mutation to the GraphQL API.
+	GraphQL   bool `json:"graphql,omitempty"`
+	MinRating *int `json:"minRating,omitempty"`
+	MaxRating *int `json:"maxRating,omitempty"`
+}
+
+// GetRatingRange returns the lowest and highest ratings accepted, which default to 1 and 5.
+func (f *FeedbackInfo) GetRatingRange() (int, int) {
+	lo, hi := DefaultFeedbackMinRating, DefaultFeedbackMaxRating
+	if f != nil && f.MinRating != nil {
+		lo = *f.MinRating
+	}
+	if f != nil && f.MaxRating != nil {
+		hi = *f.MaxRating
+	}
+	return lo, hi
+}
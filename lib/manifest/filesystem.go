@@ -0,0 +1,30 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+// DefaultFilesystemMountPath is the path at which a plugin's directory is mounted, if not specified.
+const DefaultFilesystemMountPath = "/tmp"
+
+// FilesystemInfo gives a plugin access to a sandboxed directory through standard file APIs.
+// If a directory is named, it is persistent and shared by all invocations of the plugin.
+// Otherwise, each invocation gets its own empty scratch directory, which is removed when it completes.
+type FilesystemInfo struct {
+	MountPath string `json:"mountPath,omitempty"`
+	Directory string `json:"directory,omitempty"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
+// GetMountPath returns the path at which the directory is visible to the plugin.
+func (f FilesystemInfo) GetMountPath() string {
+	if f.MountPath == "" {
+		return DefaultFilesystemMountPath
+	}
+	return f.MountPath
+}
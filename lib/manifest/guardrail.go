@@ -0,0 +1,43 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+type GuardrailType string
+
+const (
+	GuardrailTypeModel    GuardrailType = "model"
+	GuardrailTypeFunction GuardrailType = "function"
+	GuardrailTypePatterns GuardrailType = "patterns"
+)
+
+type GuardrailPolicy string
+
+const (
+	GuardrailPolicyBlock GuardrailPolicy = "block"
+	GuardrailPolicyFlag  GuardrailPolicy = "flag"
+)
+
+type GuardrailStage string
+
+const (
+	GuardrailStageInput  GuardrailStage = "input"
+	GuardrailStageOutput GuardrailStage = "output"
+)
+
+type GuardrailInfo struct {
+	Name     string           `json:"-"`
+	Type     GuardrailType    `json:"type"`
+	Model    string           `json:"model,omitempty"`
+	Function string           `json:"function,omitempty"`
+	Patterns []string         `json:"patterns,omitempty"`
+	Models   []string         `json:"models,omitempty"`
+	Stages   []GuardrailStage `json:"stages,omitempty"`
+	Policy   GuardrailPolicy  `json:"policy,omitempty"`
+}
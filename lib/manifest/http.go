@@ -24,6 +24,11 @@ type HTTPConnectionInfo struct {
 	BaseURL         string            `json:"baseURL"`
 	Headers         map[string]string `json:"headers"`
 	QueryParameters map[string]string `json:"queryParameters"`
+	Cookies         bool              `json:"cookies,omitempty"`
+	FollowRedirects *bool             `json:"followRedirects,omitempty"`
+	MaxRedirects    int               `json:"maxRedirects,omitempty"`
+	TLS             *TLSInfo          `json:"tls,omitempty"`
+	Proxy           string            `json:"proxy,omitempty"`
 }
 
 func (info HTTPConnectionInfo) ConnectionName() string {
@@ -35,7 +40,12 @@ func (info HTTPConnectionInfo) ConnectionType() ConnectionType {
 }
 
 func (info HTTPConnectionInfo) Hash() string {
-	return computeHash(info.Name, info.Type, info.Endpoint, info.BaseURL)
+	// optional settings are only hashed when set, so that the hashes of existing connections don't change
+	elements := []any{info.Name, info.Type, info.Endpoint, info.BaseURL}
+	if info.Proxy != "" {
+		elements = append(elements, info.Proxy)
+	}
+	return computeHash(append(elements, info.TLS.hashElements()...)...)
 }
 
 func (info HTTPConnectionInfo) Variables() []string {
@@ -63,5 +73,19 @@ func (info HTTPConnectionInfo) Variables() []string {
 		}
 	}
 
+	for _, v := range extractVariables(info.Proxy) {
+		if _, ok := set[v]; !ok {
+			set[v] = true
+			results = append(results, v)
+		}
+	}
+
+	for _, v := range info.TLS.variables() {
+		if _, ok := set[v]; !ok {
+			set[v] = true
+			results = append(results, v)
+		}
+	}
+
 	return results
 }
@@ -0,0 +1,36 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+type IsolationLevel string
+
+const (
+	IsolationLevelShared  IsolationLevel = "shared"
+	IsolationLevelProcess IsolationLevel = "process"
+)
+
+type IsolationInfo struct {
+	Default   IsolationLevel            `json:"default,omitempty"`
+	Functions map[string]IsolationLevel `json:"functions,omitempty"`
+}
+
+// LevelFor returns the isolation level that applies to the given function.
+func (i *IsolationInfo) LevelFor(fnName string) IsolationLevel {
+	if i == nil {
+		return IsolationLevelShared
+	}
+	if level, ok := i.Functions[fnName]; ok && level != "" {
+		return level
+	}
+	if i.Default != "" {
+		return i.Default
+	}
+	return IsolationLevelShared
+}
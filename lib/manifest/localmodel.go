@@ -0,0 +1,43 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+// DefaultLocalModelHealthPath is the readiness endpoint exposed by vLLM, TGI, and llama.cpp servers.
+const DefaultLocalModelHealthPath = "/health"
+
+// LocalModelInfo configures a model served by a self-hosted inference server, such as vLLM, TGI,
+// or the llama.cpp server.  The server is expected to expose an OpenAI-compatible API.
+type LocalModelInfo struct {
+	// HealthPath is the path of the server's readiness endpoint, relative to the connection's host.
+	HealthPath string `json:"healthPath,omitempty"`
+
+	// Stream requests a streamed response from the server, which the runtime assembles
+	// into a single response before returning it to the plugin.
+	Stream bool `json:"stream,omitempty"`
+
+	// Hints are fields added to each request body when not already present, such as
+	// scheduling or batching hints understood by the server (e.g. "priority" for vLLM,
+	// or "cache_prompt" for llama.cpp).
+	Hints map[string]any `json:"hints,omitempty"`
+}
+
+func (l *LocalModelInfo) GetHealthPath() string {
+	if l.HealthPath == "" {
+		return DefaultLocalModelHealthPath
+	}
+	return l.HealthPath
+}
+
+func (l *LocalModelInfo) hashElements() []any {
+	if l == nil {
+		return nil
+	}
+	return []any{l.HealthPath, l.Stream, l.Hints}
+}
@@ -13,6 +13,7 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"github.com/tidwall/gjson"
@@ -30,11 +31,29 @@ const currentVersion = 3
 var schemaContent string
 
 type Manifest struct {
-	Version     int                       `json:"-"`
-	Endpoints   map[string]EndpointInfo   `json:"endpoints"`
-	Models      map[string]ModelInfo      `json:"models"`
-	Connections map[string]ConnectionInfo `json:"connections"`
-	Collections map[string]CollectionInfo `json:"collections"`
+	Version        int                            `json:"-"`
+	Endpoints      map[string]EndpointInfo        `json:"endpoints"`
+	Models         map[string]ModelInfo           `json:"models"`
+	Connections    map[string]ConnectionInfo      `json:"connections"`
+	Collections    map[string]CollectionInfo      `json:"collections"`
+	Aliases        map[string]string              `json:"collectionAliases"`
+	Guardrails     map[string]GuardrailInfo       `json:"guardrails"`
+	Prompts        map[string]PromptInfo          `json:"prompts"`
+	Warmup         *WarmupInfo                    `json:"warmup,omitempty"`
+	Isolation      *IsolationInfo                 `json:"isolation,omitempty"`
+	Concurrency    *ConcurrencyInfo               `json:"concurrency,omitempty"`
+	Resolvers      map[string]string              `json:"resolvers"`
+	PostProcessors map[string][]PostProcessorInfo `json:"postProcessors"`
+	Pipelines      map[string]PipelineInfo        `json:"pipelines"`
+	ConsoleOutput  *ConsoleOutputInfo             `json:"consoleOutput,omitempty"`
+	Filesystems    map[string]FilesystemInfo      `json:"filesystems"`
+	Experiments    map[string]ExperimentInfo      `json:"experiments"`
+	Feedback       *FeedbackInfo                  `json:"feedback,omitempty"`
+	Redaction      *RedactionInfo                 `json:"redaction,omitempty"`
+	Metering       *MeteringInfo                  `json:"metering,omitempty"`
+	ResponseLimits *ResponseLimitsInfo            `json:"responseLimits,omitempty"`
+	Faults         *FaultsInfo                    `json:"faults,omitempty"`
+	DataScope      *DataScopeInfo                 `json:"dataScope,omitempty"`
 }
 
 func (m *Manifest) IsCurrentVersion() bool {
@@ -91,10 +110,28 @@ func ReadManifest(content []byte) (*Manifest, error) {
 
 func parseManifestJson(data []byte, manifest *Manifest) error {
 	var m struct {
-		Endpoints   map[string]json.RawMessage `json:"endpoints"`
-		Models      map[string]ModelInfo       `json:"models"`
-		Connections map[string]json.RawMessage `json:"connections"`
-		Collections map[string]CollectionInfo  `json:"collections"`
+		Endpoints      map[string]json.RawMessage     `json:"endpoints"`
+		Models         map[string]ModelInfo           `json:"models"`
+		Connections    map[string]json.RawMessage     `json:"connections"`
+		Collections    map[string]CollectionInfo      `json:"collections"`
+		Aliases        map[string]string              `json:"collectionAliases"`
+		Guardrails     map[string]GuardrailInfo       `json:"guardrails"`
+		Prompts        map[string]PromptInfo          `json:"prompts"`
+		Warmup         *WarmupInfo                    `json:"warmup"`
+		Isolation      *IsolationInfo                 `json:"isolation"`
+		Concurrency    *ConcurrencyInfo               `json:"concurrency"`
+		Resolvers      map[string]string              `json:"resolvers"`
+		PostProcessors map[string][]PostProcessorInfo `json:"postProcessors"`
+		Pipelines      map[string]PipelineInfo        `json:"pipelines"`
+		ConsoleOutput  *ConsoleOutputInfo             `json:"consoleOutput"`
+		Filesystems    map[string]FilesystemInfo      `json:"filesystems"`
+		Experiments    map[string]ExperimentInfo      `json:"experiments"`
+		Feedback       *FeedbackInfo                  `json:"feedback"`
+		Redaction      *RedactionInfo                 `json:"redaction"`
+		Metering       *MeteringInfo                  `json:"metering"`
+		ResponseLimits *ResponseLimitsInfo            `json:"responseLimits"`
+		Faults         *FaultsInfo                    `json:"faults"`
+		DataScope      *DataScopeInfo                 `json:"dataScope"`
 	}
 	if err := json.Unmarshal(data, &m); err != nil {
 		return fmt.Errorf("failed to parse manifest: %w", err)
@@ -103,6 +140,24 @@ func parseManifestJson(data []byte, manifest *Manifest) error {
 	manifest.Version = currentVersion
 	manifest.Models = m.Models
 	manifest.Collections = m.Collections
+	manifest.Aliases = m.Aliases
+	manifest.Guardrails = m.Guardrails
+	manifest.Prompts = m.Prompts
+	manifest.Warmup = m.Warmup
+	manifest.Isolation = m.Isolation
+	manifest.Concurrency = m.Concurrency
+	manifest.Resolvers = m.Resolvers
+	manifest.PostProcessors = m.PostProcessors
+	manifest.Pipelines = m.Pipelines
+	manifest.ConsoleOutput = m.ConsoleOutput
+	manifest.Filesystems = m.Filesystems
+	manifest.Experiments = m.Experiments
+	manifest.Feedback = m.Feedback
+	manifest.Redaction = m.Redaction
+	manifest.Metering = m.Metering
+	manifest.ResponseLimits = m.ResponseLimits
+	manifest.Faults = m.Faults
+	manifest.DataScope = m.DataScope
 
 	// Copy map keys to Name fields
 	for key, model := range manifest.Models {
@@ -113,6 +168,22 @@ func parseManifestJson(data []byte, manifest *Manifest) error {
 		collection.Name = key
 		manifest.Collections[key] = collection
 	}
+	for key, guardrail := range manifest.Guardrails {
+		guardrail.Name = key
+		manifest.Guardrails[key] = guardrail
+	}
+	for key, prompt := range manifest.Prompts {
+		prompt.Name = key
+		manifest.Prompts[key] = prompt
+	}
+	for key, experiment := range manifest.Experiments {
+		experiment.Name = key
+		manifest.Experiments[key] = experiment
+	}
+	for key, pipeline := range manifest.Pipelines {
+		pipeline.Name = key
+		manifest.Pipelines[key] = pipeline
+	}
 
 	// Parse the endpoints by type
 	manifest.Endpoints = make(map[string]EndpointInfo, len(m.Endpoints))
@@ -131,6 +202,13 @@ func parseManifestJson(data []byte, manifest *Manifest) error {
 			}
 			info.Name = name
 			manifest.Endpoints[name] = info
+		case EndpointTypeWebhook:
+			var info WebhookEndpointInfo
+			if err := json.Unmarshal(rawEp, &info); err != nil {
+				return fmt.Errorf("failed to parse webhook endpoint [%s]: %w", name, err)
+			}
+			info.Name = name
+			manifest.Endpoints[name] = info
 		default:
 			return fmt.Errorf("unknown type [%s] for endpoint [%s]", epType, name)
 		}
@@ -181,3 +259,9 @@ func parseManifestJson(data []byte, manifest *Manifest) error {
 
 	return nil
 }
+
+// SplitResolverKey splits a resolver key of the form "Type.field" into its type and field names.
+func SplitResolverKey(key string) (typeName, fieldName string, ok bool) {
+	typeName, fieldName, ok = strings.Cut(key, ".")
+	return typeName, fieldName, ok && typeName != "" && fieldName != ""
+}
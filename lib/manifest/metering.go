@@ -0,0 +1,58 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+import "time"
+
+const (
+	DefaultMeteringTenantClaim = "sub"
+	DefaultMeteringInterval    = 60
+)
+
+type MeteringSinkType string
+
+const (
+	MeteringSinkTypeFile  MeteringSinkType = "file"
+	MeteringSinkTypeHttp  MeteringSinkType = "http"
+	MeteringSinkTypeKafka MeteringSinkType = "kafka"
+)
+
+// MeteringInfo configures the recording of billable usage per tenant, and its periodic export to a sink.
+type MeteringInfo struct {
+	TenantClaim string           `json:"tenantClaim,omitempty"`
+	Interval    int              `json:"interval,omitempty"`
+	Sink        MeteringSinkInfo `json:"sink"`
+}
+
+// MeteringSinkInfo is where usage records are exported to.  A file sink appends JSON lines to the path.
+// An http sink posts a JSON array to the endpoint of the connection.  A kafka sink publishes to the topic
+// through the Kafka REST proxy at the base URL of the connection.
+type MeteringSinkInfo struct {
+	Type       MeteringSinkType `json:"type"`
+	Path       string           `json:"path,omitempty"`
+	Connection string           `json:"connection,omitempty"`
+	Topic      string           `json:"topic,omitempty"`
+}
+
+// GetTenantClaim returns the JWT claim that identifies the tenant, which defaults to the subject.
+func (m *MeteringInfo) GetTenantClaim() string {
+	if m.TenantClaim == "" {
+		return DefaultMeteringTenantClaim
+	}
+	return m.TenantClaim
+}
+
+// GetInterval returns the time between exports, which defaults to one minute.
+func (m *MeteringInfo) GetInterval() time.Duration {
+	if m.Interval <= 0 {
+		return DefaultMeteringInterval * time.Second
+	}
+	return time.Duration(m.Interval) * time.Second
+}
@@ -15,8 +15,60 @@ type ModelInfo struct {
 	Provider    string `json:"provider"`
 	Connection  string `json:"connection"`
 	Path        string `json:"path"`
+
+	// Local is set when the model is served by a self-hosted inference server.
+	Local *LocalModelInfo `json:"local,omitempty"`
+
+	// Timeout is the maximum number of seconds to wait for a response before failing over.
+	Timeout int `json:"timeout,omitempty"`
+
+	// Fallbacks are alternate endpoints for the model, tried in order when invoking the model fails.
+	Fallbacks []ModelFallbackInfo `json:"fallbacks,omitempty"`
+
+	// Hedge is set when a slow invocation should also be sent to the first fallback endpoint.
+	Hedge *ModelHedgeInfo `json:"hedge,omitempty"`
+
+	// Tokenizer is the encoding used to count the tokens of text for the model.
+	Tokenizer string `json:"tokenizer,omitempty"`
+
+	// ContextWindow is the maximum number of tokens of the model's input and output together.
+	ContextWindow int `json:"contextWindow,omitempty"`
+}
+
+// ModelFallbackInfo describes an alternate endpoint that can serve a model.
+type ModelFallbackInfo struct {
+	SourceModel string `json:"sourceModel,omitempty"`
+	Connection  string `json:"connection"`
+	Path        string `json:"path,omitempty"`
+	Timeout     int    `json:"timeout,omitempty"`
+}
+
+const DefaultHedgeMaxPercent = 10
+
+// ModelHedgeInfo configures hedged invocations of a model.  When the model's endpoint hasn't responded
+// within the delay, the invocation is also sent to its first fallback endpoint, and whichever responds
+// first is used.  The budget limits hedged requests to a percentage of invocations.
+type ModelHedgeInfo struct {
+	// Delay is the number of milliseconds to wait for the first endpoint before sending the hedged request.
+	Delay int `json:"delay"`
+
+	// MaxPercent is the maximum percentage of invocations that may be hedged.
+	MaxPercent float64 `json:"maxPercent,omitempty"`
+}
+
+// GetMaxPercent returns the maximum percentage of invocations that may be hedged, which defaults to 10.
+func (h *ModelHedgeInfo) GetMaxPercent() float64 {
+	if h.MaxPercent <= 0 {
+		return DefaultHedgeMaxPercent
+	}
+	return h.MaxPercent
 }
 
 func (m ModelInfo) Hash() string {
-	return computeHash(m.Name, m.SourceModel, m.Provider, m.Connection, m.Path)
+	elements := []any{m.Name, m.SourceModel, m.Provider, m.Connection, m.Path}
+	elements = append(elements, m.Local.hashElements()...)
+	if m.Timeout > 0 || len(m.Fallbacks) > 0 {
+		elements = append(elements, m.Timeout, m.Fallbacks)
+	}
+	return computeHash(elements...)
 }
@@ -17,6 +17,7 @@ type Neo4jConnectionInfo struct {
 	DbUri    string         `json:"dbUri"`
 	Username string         `json:"username"`
 	Password string         `json:"password"`
+	TLS      *TLSInfo       `json:"tls,omitempty"`
 }
 
 func (info Neo4jConnectionInfo) ConnectionName() string {
@@ -28,9 +29,10 @@ func (info Neo4jConnectionInfo) ConnectionType() ConnectionType {
 }
 
 func (info Neo4jConnectionInfo) Hash() string {
-	return computeHash(info.Name, info.Type, info.DbUri)
+	return computeHash(append([]any{info.Name, info.Type, info.DbUri}, info.TLS.hashElements()...)...)
 }
 
 func (info Neo4jConnectionInfo) Variables() []string {
-	return append(extractVariables(info.Username), extractVariables(info.Password)...)
+	results := append(extractVariables(info.Username), extractVariables(info.Password)...)
+	return append(results, info.TLS.variables()...)
 }
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+type PipelineInfo struct {
+	Name   string              `json:"-"`
+	Stages []PipelineStageInfo `json:"stages"`
+}
+
+// PipelineStageInfo describes one step of a pipeline.  A stage either calls a function, calls the first of
+// its branches whose condition holds, or calls all of its parallel functions and combines their results.
+type PipelineStageInfo struct {
+	Function string                 `json:"function,omitempty"`
+	Inputs   map[string]string      `json:"inputs,omitempty"`
+	When     *PipelineConditionInfo `json:"when,omitempty"`
+	Branches []PipelineStageInfo    `json:"branches,omitempty"`
+	Parallel []PipelineStageInfo    `json:"parallel,omitempty"`
+	Name     string                 `json:"name,omitempty"`
+}
+
+// PipelineConditionInfo is a predicate on the result of the previous stage.  It either tests the value at a path,
+// or evaluates a CEL expression in which the previous stage's result is available as the variable "result".
+type PipelineConditionInfo struct {
+	Path       string `json:"path,omitempty"`
+	Equals     any    `json:"equals,omitempty"`
+	Expression string `json:"expression,omitempty"`
+	Not        bool   `json:"not,omitempty"`
+}
+
+// ResultName returns the name under which a parallel function's result is combined with the others.
+func (s *PipelineStageInfo) ResultName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Function
+}
@@ -15,6 +15,7 @@ type PostgresqlConnectionInfo struct {
 	Name    string         `json:"-"`
 	Type    ConnectionType `json:"type"`
 	ConnStr string         `json:"connString"`
+	TLS     *TLSInfo       `json:"tls,omitempty"`
 }
 
 func (info PostgresqlConnectionInfo) ConnectionName() string {
@@ -26,9 +27,9 @@ func (info PostgresqlConnectionInfo) ConnectionType() ConnectionType {
 }
 
 func (info PostgresqlConnectionInfo) Hash() string {
-	return computeHash(info.Name, info.Type, info.ConnStr)
+	return computeHash(append([]any{info.Name, info.Type, info.ConnStr}, info.TLS.hashElements()...)...)
 }
 
 func (info PostgresqlConnectionInfo) Variables() []string {
-	return extractVariables(info.ConnStr)
+	return append(extractVariables(info.ConnStr), info.TLS.variables()...)
 }
@@ -0,0 +1,25 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+type PostProcessorType string
+
+const (
+	PostProcessorTypeProject  PostProcessorType = "project"
+	PostProcessorTypeTemplate PostProcessorType = "template"
+	PostProcessorTypeTruncate PostProcessorType = "truncate"
+)
+
+type PostProcessorInfo struct {
+	Type      PostProcessorType `json:"type"`
+	Path      string            `json:"path,omitempty"`
+	Template  string            `json:"template,omitempty"`
+	MaxLength int               `json:"maxLength,omitempty"`
+}
@@ -0,0 +1,21 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+type PromptInfo struct {
+	Name           string                       `json:"-"`
+	DefaultVersion string                       `json:"defaultVersion,omitempty"`
+	Versions       map[string]PromptVersionInfo `json:"versions"`
+}
+
+type PromptVersionInfo struct {
+	Template  string   `json:"template"`
+	Variables []string `json:"variables,omitempty"`
+}
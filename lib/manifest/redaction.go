@@ -0,0 +1,26 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+// RedactionInfo declares fields of function results whose values are masked in responses,
+// unless the caller's token grants the privileged scope.
+type RedactionInfo struct {
+	PrivilegedScope string              `json:"privilegedScope,omitempty"`
+	ScopeClaim      string              `json:"scopeClaim,omitempty"`
+	Functions       map[string][]string `json:"functions,omitempty"`
+}
+
+// FieldsFor returns the paths of the fields that are redacted from the results of the given function.
+func (r *RedactionInfo) FieldsFor(fnName string) []string {
+	if r == nil {
+		return nil
+	}
+	return r.Functions[fnName]
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+type ResponseLimitAction string
+
+const (
+	ResponseLimitError    ResponseLimitAction = "error"
+	ResponseLimitTruncate ResponseLimitAction = "truncate"
+)
+
+// ResponseLimitsInfo limits the size of the response read from each function invocation.
+// A limit of zero means that the response is not limited.
+type ResponseLimitsInfo struct {
+	MaxBytes   *int                         `json:"maxBytes,omitempty"`
+	OnExceeded ResponseLimitAction          `json:"onExceeded,omitempty"`
+	Functions  map[string]ResponseLimitInfo `json:"functions,omitempty"`
+}
+
+// ResponseLimitInfo overrides the response limit of an individual function.
+type ResponseLimitInfo struct {
+	MaxBytes   *int                `json:"maxBytes,omitempty"`
+	OnExceeded ResponseLimitAction `json:"onExceeded,omitempty"`
+}
+
+// LimitFor returns the maximum response size of the given function, and whether a list response that
+// exceeds it is truncated rather than failing.  The default is used if no limit is set.
+func (r *ResponseLimitsInfo) LimitFor(fnName string, defaultMaxBytes int) (maxBytes int, truncate bool) {
+	if r == nil {
+		return defaultMaxBytes, false
+	}
+
+	maxBytes = defaultMaxBytes
+	if r.MaxBytes != nil {
+		maxBytes = *r.MaxBytes
+	}
+	action := r.OnExceeded
+
+	if f, ok := r.Functions[fnName]; ok {
+		if f.MaxBytes != nil {
+			maxBytes = *f.MaxBytes
+		}
+		if f.OnExceeded != "" {
+			action = f.OnExceeded
+		}
+	}
+
+	return maxBytes, action == ResponseLimitTruncate
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+// TLSInfo configures TLS for outbound connections.  Certificates and keys may be PEM-encoded
+// contents, or paths to PEM files relative to the app.  They may contain secret placeholders.
+type TLSInfo struct {
+	CACert     string `json:"caCert,omitempty"`
+	ClientCert string `json:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty"`
+	MinVersion string `json:"minVersion,omitempty"`
+}
+
+func (t *TLSInfo) variables() []string {
+	if t == nil {
+		return nil
+	}
+	results := extractVariables(t.CACert)
+	results = append(results, extractVariables(t.ClientCert)...)
+	results = append(results, extractVariables(t.ClientKey)...)
+	return results
+}
+
+func (t *TLSInfo) hashElements() []any {
+	if t == nil {
+		return nil
+	}
+	return []any{t.CACert, t.ClientCert, t.ClientKey, t.MinVersion}
+}
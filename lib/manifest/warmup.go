@@ -0,0 +1,20 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifest
+
+type WarmupInfo struct {
+	Timeout   int                  `json:"timeout,omitempty"`
+	Functions []WarmupFunctionInfo `json:"functions"`
+}
+
+type WarmupFunctionInfo struct {
+	Function   string         `json:"function"`
+	Parameters map[string]any `json:"parameters,omitempty"`
+}
@@ -67,6 +67,11 @@ func (f *Function) WithDocs(docs Docs) *Function {
 	return f
 }
 
+func (f *Function) WithBatch() *Function {
+	f.Batch = true
+	return f
+}
+
 func (f *Function) WithNamedResult(name string, typ string) *Function {
 	r := &Result{
 		Name: name,
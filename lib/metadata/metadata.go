@@ -22,16 +22,18 @@ type TypeMap map[string]*TypeDefinition
 type FunctionMap map[string]*Function
 
 type Metadata struct {
-	Plugin    string      `json:"plugin"`
-	Module    string      `json:"module"`
-	SDK       string      `json:"sdk"`
-	BuildId   string      `json:"buildId"`
-	BuildTime string      `json:"buildTs"`
-	GitRepo   string      `json:"gitRepo,omitempty"`
-	GitCommit string      `json:"gitCommit,omitempty"`
-	FnExports FunctionMap `json:"fnExports,omitempty"`
-	FnImports FunctionMap `json:"fnImports,omitempty"`
-	Types     TypeMap     `json:"types,omitempty"`
+	Plugin         string      `json:"plugin"`
+	Module         string      `json:"module"`
+	SDK            string      `json:"sdk"`
+	BuildId        string      `json:"buildId"`
+	BuildTime      string      `json:"buildTs"`
+	GitRepo        string      `json:"gitRepo,omitempty"`
+	GitCommit      string      `json:"gitCommit,omitempty"`
+	HostApiVersion int         `json:"hostApiVersion,omitempty"`
+	Snapshot       bool        `json:"snapshot,omitempty"`
+	FnExports      FunctionMap `json:"fnExports,omitempty"`
+	FnImports      FunctionMap `json:"fnImports,omitempty"`
+	Types          TypeMap     `json:"types,omitempty"`
 }
 
 type Docs struct {
@@ -43,6 +45,7 @@ type Function struct {
 	Parameters []*Parameter `json:"parameters,omitempty"`
 	Results    []*Result    `json:"results,omitempty"`
 	Docs       *Docs        `json:"docs,omitempty"`
+	Batch      bool         `json:"batch,omitempty"`
 }
 
 type TypeDefinition struct {
@@ -34,6 +34,21 @@ func SetShuttingDown() {
 	shuttingDown = true
 }
 
+var warmingUp = false
+
+// IsWarmingUp returns true while warm-up functions are being invoked after plugins are loaded.
+func IsWarmingUp() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return warmingUp
+}
+
+func SetWarmingUp(value bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	warmingUp = value
+}
+
 func GetRootSourcePath() string {
 	_, filename, _, ok := runtime.Caller(0)
 	if !ok {
@@ -12,12 +12,15 @@ package aws
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	hmConfig "github.com/hypermodeinc/modus/runtime/config"
 	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/proxy"
 	"github.com/hypermodeinc/modus/runtime/utils"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
@@ -28,6 +31,13 @@ func GetAwsConfig() aws.Config {
 	return awsConfig
 }
 
+// NewHttpClient returns an HTTP client for AWS SDK clients that honors the runtime's proxy settings.
+func NewHttpClient() *awshttp.BuildableClient {
+	return awshttp.NewBuildableClient().WithTransportOptions(func(t *http.Transport) {
+		t.Proxy = proxy.FromConfig
+	})
+}
+
 func Initialize(ctx context.Context) {
 	if !(hmConfig.UseAwsStorage) {
 		return
@@ -43,7 +53,7 @@ func initialize(ctx context.Context) error {
 	span, ctx := utils.NewSentrySpanForCurrentFunc(ctx)
 	defer span.Finish()
 
-	cfg, err := config.LoadDefaultConfig(ctx)
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithHTTPClient(NewHttpClient()))
 	if err != nil {
 		return fmt.Errorf("error loading AWS configuration: %w", err)
 	}
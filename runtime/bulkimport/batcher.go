@@ -0,0 +1,93 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bulkimport
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/collections"
+)
+
+const defaultBatchSize = 256
+const maxBatchSize = 10000
+
+func getBatchSize(size int) int {
+	if size <= 0 {
+		return defaultBatchSize
+	}
+	return min(size, maxBatchSize)
+}
+
+// batcher accumulates rows, split into chunks if requested, and upserts them into a collection
+// each time a batch is full.  Each batch is embedded together, by each of the collection's search methods.
+// Progress is reported through the update function, so that it can be observed while the import runs.
+type batcher struct {
+	ctx        context.Context
+	collection string
+	namespace  string
+	size       int
+	chunking   *ChunkOptions
+	batch      *rowBatch
+	update     func(func(*ImportResult))
+	imported   int
+}
+
+func newBatcher(ctx context.Context, collection, namespace string, size int, chunking *ChunkOptions, update func(func(*ImportResult))) *batcher {
+	return &batcher{
+		ctx:        ctx,
+		collection: collection,
+		namespace:  namespace,
+		size:       getBatchSize(size),
+		chunking:   chunking,
+		batch:      &rowBatch{},
+		update:     update,
+	}
+}
+
+func (b *batcher) addRows(rows *rowBatch) error {
+	b.update(func(r *ImportResult) {
+		r.Rows += rows.read
+		r.Skipped += rows.read - len(rows.texts)
+	})
+
+	for i := range rows.texts {
+		b.batch.add(rows, i, b.chunking)
+		if len(b.batch.texts) >= b.size {
+			if err := b.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *batcher) flush() error {
+	if len(b.batch.texts) == 0 {
+		return nil
+	}
+	if err := b.ctx.Err(); err != nil {
+		return fmt.Errorf("import stopped after %d items: %w", b.imported, err)
+	}
+
+	res, err := collections.Upsert(b.ctx, b.collection, b.namespace, b.batch.keys, b.batch.texts, b.batch.labels)
+	if err != nil {
+		return fmt.Errorf("import stopped after %d items: %w", b.imported, err)
+	}
+
+	b.imported += len(res.Keys)
+	b.update(func(r *ImportResult) {
+		r.Batches++
+		r.Imported += len(res.Keys)
+		r.Duplicates += len(res.Duplicates)
+	})
+	b.batch = &rowBatch{}
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bulkimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/storage"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+// ImportColumns maps the columns of the imported file to the fields of a collection item.
+// The text column is required.  When there is no key column, keys are generated.
+// Each metadata column is added to the item's labels, as "column=value".
+// When a label separator is given, labels columns that hold a single string are split by it.
+type ImportColumns struct {
+	Key            string   `json:"key,omitempty"`
+	Text           string   `json:"text"`
+	Labels         string   `json:"labels,omitempty"`
+	LabelSeparator string   `json:"labelSeparator,omitempty"`
+	Metadata       []string `json:"metadata,omitempty"`
+}
+
+// ImportOptions describes an import job.  The file is read from the app's storage,
+// which is either the local app directory or an object storage bucket.
+type ImportOptions struct {
+	Collection string        `json:"collection"`
+	Namespace  string        `json:"namespace,omitempty"`
+	File       string        `json:"file"`
+	Format     Format        `json:"format,omitempty"`
+	Columns    ImportColumns `json:"columns"`
+	Chunking   *ChunkOptions `json:"chunking,omitempty"`
+	BatchSize  int           `json:"batchSize,omitempty"`
+}
+
+type ImportResult struct {
+	Collection string `json:"collection"`
+	Namespace  string `json:"namespace,omitempty"`
+	Format     Format `json:"format"`
+	Rows       int    `json:"rows"`
+	Imported   int    `json:"imported"`
+	Skipped    int    `json:"skipped"`
+	Duplicates int    `json:"duplicates"`
+	Batches    int    `json:"batches"`
+	Error      string `json:"error,omitempty"`
+}
+
+var wasmHost wasmhost.WasmHost
+
+func Initialize(ctx context.Context) {
+	wasmHost = wasmhost.GetWasmHost(ctx)
+}
+
+// Import reads the rows of a Parquet or Arrow IPC file, and upserts them into a collection in batches.
+// Each batch is embedded together, by each of the collection's search methods.
+// If the import fails part way through, the result reports the rows that were imported before the failure.
+func Import(ctx context.Context, opts *ImportOptions) (*ImportResult, error) {
+	if opts.Collection == "" || opts.File == "" {
+		return nil, errors.New("a collection and file are required")
+	}
+	if opts.Columns.Text == "" {
+		return nil, errors.New("a text column is required")
+	}
+
+	if err := opts.Chunking.validate(); err != nil {
+		return nil, err
+	}
+
+	content, err := storage.GetFileContents(ctx, opts.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", opts.File, err)
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = detectFormat(content)
+	}
+
+	reader, err := openRecords(ctx, content, format, getBatchSize(opts.BatchSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s as %s: %w", opts.File, format, err)
+	}
+	defer reader.Release()
+
+	ctx = context.WithValue(ctx, utils.WasmHostContextKey, wasmHost)
+	ctx = context.WithValue(ctx, utils.PriorityContextKey, utils.PriorityBatch)
+
+	result := &ImportResult{
+		Collection: opts.Collection,
+		Namespace:  opts.Namespace,
+		Format:     format,
+	}
+
+	b := newBatcher(ctx, opts.Collection, opts.Namespace, opts.BatchSize, opts.Chunking, func(update func(*ImportResult)) {
+		update(result)
+	})
+
+	for reader.Next() {
+		rows, err := readRows(reader.Record(), &opts.Columns)
+		if err != nil {
+			return result, err
+		}
+		if err := b.addRows(rows); err != nil {
+			return result, err
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return result, fmt.Errorf("failed to read %s: %w", opts.File, err)
+	}
+	if err := b.flush(); err != nil {
+		return result, err
+	}
+
+	logger.Info(ctx).
+		Str("collection", opts.Collection).
+		Str("namespace", opts.Namespace).
+		Str("file", opts.File).
+		Str("format", string(format)).
+		Int("rows", result.Rows).
+		Int("imported", result.Imported).
+		Int("skipped", result.Skipped).
+		Msg("Collection import completed.")
+
+	return result, nil
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bulkimport
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "id", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "body", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+	{Name: "year", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+}, nil)
+
+func newTestRecord(t *testing.T) arrow.Record {
+	b := array.NewRecordBuilder(memory.NewGoAllocator(), testSchema)
+	defer b.Release()
+
+	b.Field(0).(*array.StringBuilder).AppendValues([]string{"a", "b", "", "d"}, []bool{true, true, false, true})
+	b.Field(1).(*array.StringBuilder).AppendValues([]string{"first", "second", "third", ""}, nil)
+
+	tags := b.Field(2).(*array.ListBuilder)
+	values := tags.ValueBuilder().(*array.StringBuilder)
+	tags.Append(true)
+	values.AppendValues([]string{"x", "y"}, nil)
+	tags.AppendNull()
+	tags.Append(true)
+	values.Append("z")
+	tags.Append(true)
+
+	b.Field(3).(*array.Int64Builder).AppendValues([]int64{2023, 0, 2024, 2025}, []bool{true, false, true, true})
+
+	rec := b.NewRecord()
+	t.Cleanup(rec.Release)
+	return rec
+}
+
+func TestReadRows(t *testing.T) {
+	rec := newTestRecord(t)
+
+	rows, err := readRows(rec, &ImportColumns{Key: "id", Text: "body", Labels: "tags", Metadata: []string{"year"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, rows.read)
+	assert.Equal(t, []string{"a", "b"}, rows.keys)
+	assert.Equal(t, []string{"first", "second"}, rows.texts)
+	assert.Equal(t, [][]string{{"x", "y", "year=2023"}, {}}, rows.labels)
+}
+
+func TestReadRows_TextOnly(t *testing.T) {
+	rec := newTestRecord(t)
+
+	rows, err := readRows(rec, &ImportColumns{Text: "body"})
+	require.NoError(t, err)
+
+	assert.Nil(t, rows.keys)
+	assert.Nil(t, rows.labels)
+	assert.Equal(t, []string{"first", "second", "third"}, rows.texts)
+}
+
+func TestReadRows_MissingColumn(t *testing.T) {
+	rec := newTestRecord(t)
+
+	_, err := readRows(rec, &ImportColumns{Text: "content"})
+	assert.Error(t, err)
+}
+
+func TestOpenRecords(t *testing.T) {
+	rec := newTestRecord(t)
+
+	var stream bytes.Buffer
+	sw := ipc.NewWriter(&stream, ipc.WithSchema(testSchema))
+	require.NoError(t, sw.Write(rec))
+	require.NoError(t, sw.Close())
+
+	var arrowFile bytes.Buffer
+	fw, err := ipc.NewFileWriter(&arrowFile, ipc.WithSchema(testSchema))
+	require.NoError(t, err)
+	require.NoError(t, fw.Write(rec))
+	require.NoError(t, fw.Close())
+
+	var parquetFile bytes.Buffer
+	pw, err := pqarrow.NewFileWriter(testSchema, &parquetFile, parquet.NewWriterProperties(), pqarrow.DefaultWriterProps())
+	require.NoError(t, err)
+	require.NoError(t, pw.Write(rec))
+	require.NoError(t, pw.Close())
+
+	cases := map[Format][]byte{
+		FormatArrowStream: stream.Bytes(),
+		FormatArrow:       arrowFile.Bytes(),
+		FormatParquet:     parquetFile.Bytes(),
+	}
+	for format, content := range cases {
+		t.Run(string(format), func(t *testing.T) {
+			assert.Equal(t, format, detectFormat(content))
+
+			reader, err := openRecords(context.Background(), content, format, 100)
+			require.NoError(t, err)
+			defer reader.Release()
+
+			var texts []string
+			for reader.Next() {
+				rows, err := readRows(reader.Record(), &ImportColumns{Key: "id", Text: "body", Labels: "tags"})
+				require.NoError(t, err)
+				texts = append(texts, rows.texts...)
+			}
+			require.NoError(t, reader.Err())
+			assert.Equal(t, []string{"first", "second"}, texts)
+		})
+	}
+}
+
+func TestRowBatch_Add(t *testing.T) {
+	from := &rowBatch{keys: []string{"a", "b"}, texts: []string{"first", "second"}}
+	batch := &rowBatch{}
+	batch.add(from, 1, nil)
+
+	assert.Equal(t, []string{"b"}, batch.keys)
+	assert.Equal(t, []string{"second"}, batch.texts)
+	assert.Nil(t, batch.labels)
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bulkimport
+
+import (
+	"errors"
+	"unicode"
+)
+
+// ChunkOptions splits long texts into chunks of at most Size characters, where consecutive chunks
+// share Overlap characters.  Chunks end at whitespace where possible, so that words are not split.
+// Each chunk becomes its own item, keyed as "key#n" and carrying the labels of the original row.
+type ChunkOptions struct {
+	Size    int `json:"size"`
+	Overlap int `json:"overlap,omitempty"`
+}
+
+func (c *ChunkOptions) validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Size <= 0 {
+		return errors.New("chunk size must be greater than zero")
+	}
+	if c.Overlap < 0 || c.Overlap >= c.Size {
+		return errors.New("chunk overlap must be at least zero and less than the chunk size")
+	}
+	return nil
+}
+
+// split returns the chunks of the text, or the text itself if chunking is not enabled or not needed.
+func (c *ChunkOptions) split(text string) []string {
+	runes := []rune(text)
+	if c == nil || len(runes) <= c.Size {
+		return []string{text}
+	}
+
+	chunks := []string{}
+	for start := 0; start < len(runes); {
+		end := min(start+c.Size, len(runes))
+		if end < len(runes) {
+			// back off to the last whitespace, if there is one in the second half of the chunk
+			for i := end; i > start+c.Size/2; i-- {
+				if unicode.IsSpace(runes[i]) {
+					end = i
+					break
+				}
+			}
+		}
+
+		chunks = append(chunks, string(runes[start:end]))
+		if end == len(runes) {
+			break
+		}
+
+		start = max(end-c.Overlap, start+1)
+		for start < len(runes) && unicode.IsSpace(runes[start]) {
+			start++
+		}
+	}
+	return chunks
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bulkimport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkOptions_Split(t *testing.T) {
+	var none *ChunkOptions
+	assert.Equal(t, []string{"some text"}, none.split("some text"))
+
+	c := &ChunkOptions{Size: 20}
+	assert.Equal(t, []string{"short text"}, c.split("short text"))
+
+	text := "the quick brown fox jumps over the lazy dog"
+	chunks := c.split(text)
+	assert.Equal(t, []string{"the quick brown fox", "jumps over the lazy", "dog"}, chunks)
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, len(chunk), c.Size)
+	}
+}
+
+func TestChunkOptions_SplitOverlap(t *testing.T) {
+	c := &ChunkOptions{Size: 10, Overlap: 4}
+	chunks := c.split(strings.Repeat("x", 25))
+	assert.Equal(t, []string{strings.Repeat("x", 10), strings.Repeat("x", 10), strings.Repeat("x", 10), strings.Repeat("x", 7)}, chunks)
+}
+
+func TestChunkOptions_Validate(t *testing.T) {
+	var none *ChunkOptions
+	assert.NoError(t, none.validate())
+	assert.NoError(t, (&ChunkOptions{Size: 100, Overlap: 10}).validate())
+	assert.Error(t, (&ChunkOptions{Size: 0}).validate())
+	assert.Error(t, (&ChunkOptions{Size: 10, Overlap: 10}).validate())
+}
+
+func TestRowBatch_AddChunked(t *testing.T) {
+	from := &rowBatch{
+		keys:   []string{"doc"},
+		texts:  []string{"the quick brown fox jumps"},
+		labels: [][]string{{"animals"}},
+	}
+	batch := &rowBatch{}
+	batch.add(from, 0, &ChunkOptions{Size: 15})
+
+	assert.Equal(t, []string{"doc#0", "doc#1"}, batch.keys)
+	assert.Equal(t, []string{"the quick brown", "fox jumps"}, batch.texts)
+	assert.Equal(t, [][]string{{"animals"}, {"animals"}}, batch.labels)
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bulkimport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+type Format string
+
+const (
+	FormatParquet     Format = "parquet"
+	FormatArrow       Format = "arrow"
+	FormatArrowStream Format = "arrow-stream"
+	FormatCSV         Format = "csv"
+	FormatJSONL       Format = "jsonl"
+)
+
+var parquetMagic = []byte("PAR1")
+var arrowFileMagic = []byte("ARROW1")
+
+// detectFormat identifies the format from the leading bytes of the file.
+// Arrow IPC streams have no magic number, so they are assumed when nothing else matches.
+func detectFormat(content []byte) Format {
+	switch {
+	case bytes.HasPrefix(content, parquetMagic):
+		return FormatParquet
+	case bytes.HasPrefix(content, arrowFileMagic):
+		return FormatArrow
+	default:
+		return FormatArrowStream
+	}
+}
+
+// recordReader iterates over the record batches of a file, regardless of its format.
+type recordReader interface {
+	Next() bool
+	Record() arrow.Record
+	Err() error
+	Release()
+}
+
+func openRecords(ctx context.Context, content []byte, format Format, batchSize int) (recordReader, error) {
+	switch format {
+	case FormatParquet:
+		return openParquet(ctx, content, batchSize)
+	case FormatArrow:
+		return openArrowFile(content)
+	case FormatArrowStream:
+		r, err := ipc.NewReader(bytes.NewReader(content), ipc.WithAllocator(memory.DefaultAllocator))
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+type parquetRecordReader struct {
+	pqarrow.RecordReader
+	file *file.Reader
+}
+
+func (r *parquetRecordReader) Release() {
+	r.RecordReader.Release()
+	_ = r.file.Close()
+}
+
+func openParquet(ctx context.Context, content []byte, batchSize int) (recordReader, error) {
+	pf, err := file.NewParquetReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	props := pqarrow.ArrowReadProperties{BatchSize: int64(batchSize)}
+	fr, err := pqarrow.NewFileReader(pf, props, memory.DefaultAllocator)
+	if err != nil {
+		_ = pf.Close()
+		return nil, err
+	}
+
+	rr, err := fr.GetRecordReader(ctx, nil, nil)
+	if err != nil {
+		_ = pf.Close()
+		return nil, err
+	}
+
+	return &parquetRecordReader{rr, pf}, nil
+}
+
+// arrowFileRecordReader adapts the random access reader of the Arrow IPC file format to the recordReader interface.
+type arrowFileRecordReader struct {
+	reader *ipc.FileReader
+	next   int
+	record arrow.Record
+	err    error
+}
+
+func openArrowFile(content []byte) (recordReader, error) {
+	r, err := ipc.NewFileReader(bytes.NewReader(content), ipc.WithAllocator(memory.DefaultAllocator))
+	if err != nil {
+		return nil, err
+	}
+	return &arrowFileRecordReader{reader: r}, nil
+}
+
+func (r *arrowFileRecordReader) Next() bool {
+	if r.err != nil || r.next >= r.reader.NumRecords() {
+		return false
+	}
+	r.record, r.err = r.reader.Record(r.next)
+	r.next++
+	return r.err == nil
+}
+
+func (r *arrowFileRecordReader) Record() arrow.Record {
+	return r.record
+}
+
+func (r *arrowFileRecordReader) Err() error {
+	return r.err
+}
+
+func (r *arrowFileRecordReader) Release() {
+	_ = r.reader.Close()
+}
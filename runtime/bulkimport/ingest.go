@@ -0,0 +1,69 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bulkimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+// IngestOptions describes an ingestion of an uploaded CSV or JSON Lines file.
+type IngestOptions struct {
+	Collection string        `json:"collection"`
+	Namespace  string        `json:"namespace,omitempty"`
+	Format     Format        `json:"format"`
+	Columns    ImportColumns `json:"columns"`
+	Chunking   *ChunkOptions `json:"chunking,omitempty"`
+	BatchSize  int           `json:"batchSize,omitempty"`
+}
+
+// StartIngest parses the uploaded content, and then upserts its rows into the collection in a background job.
+// Problems with the content or the column mapping are reported immediately, and problems with the upsert are
+// reported by the job.  The job keeps the caller's context values, such as JWT claims, but not its cancellation.
+func StartIngest(ctx context.Context, opts *IngestOptions, content []byte) (string, error) {
+	if opts.Collection == "" {
+		return "", errors.New("a collection is required")
+	}
+	if opts.Columns.Text == "" {
+		return "", errors.New("a text column is required")
+	}
+	if err := opts.Chunking.validate(); err != nil {
+		return "", err
+	}
+
+	rows, err := readText(content, opts.Format, &opts.Columns)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s content: %w", opts.Format, err)
+	}
+
+	ctx = wasmhost.ClearSlotHold(context.WithoutCancel(ctx))
+	ctx = context.WithValue(ctx, utils.WasmHostContextKey, wasmHost)
+	ctx = context.WithValue(ctx, utils.PriorityContextKey, utils.PriorityBatch)
+
+	progress := ImportResult{
+		Collection: opts.Collection,
+		Namespace:  opts.Namespace,
+		Format:     opts.Format,
+	}
+
+	id := startJob(ctx, progress, rows.read, func(ctx context.Context, update func(func(*ImportResult))) error {
+		b := newBatcher(ctx, opts.Collection, opts.Namespace, opts.BatchSize, opts.Chunking, update)
+		if err := b.addRows(rows); err != nil {
+			return err
+		}
+		return b.flush()
+	})
+
+	return id, nil
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bulkimport
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// finished jobs are kept for this long, so that their final status can be retrieved
+const jobRetention = time.Hour
+
+type JobStatus string
+
+const (
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+// Job reports the status and progress of an ingestion that runs in the background.
+type Job struct {
+	Id         string       `json:"id"`
+	Status     JobStatus    `json:"status"`
+	TotalRows  int          `json:"totalRows"`
+	Progress   ImportResult `json:"progress"`
+	StartedAt  time.Time    `json:"startedAt"`
+	FinishedAt *time.Time   `json:"finishedAt,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+type jobEntry struct {
+	mu  sync.Mutex
+	job Job
+}
+
+var jobs sync.Map // map[string]*jobEntry
+
+// GetJob returns a snapshot of the job with the given id, or false if there is no such job.
+func GetJob(id string) (Job, bool) {
+	v, ok := jobs.Load(id)
+	if !ok {
+		return Job{}, false
+	}
+
+	entry := v.(*jobEntry)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	return entry.job, true
+}
+
+// startJob runs the work in the background, and returns the new job's id.
+// The work reports its progress through the update function that it is given.
+func startJob(ctx context.Context, progress ImportResult, totalRows int, work func(ctx context.Context, update func(func(*ImportResult))) error) string {
+	pruneJobs()
+
+	entry := &jobEntry{
+		job: Job{
+			Id:        utils.GenerateUUIDv7(),
+			Status:    JobStatusRunning,
+			TotalRows: totalRows,
+			Progress:  progress,
+			StartedAt: time.Now().UTC(),
+		},
+	}
+	jobs.Store(entry.job.Id, entry)
+
+	update := func(fn func(*ImportResult)) {
+		entry.mu.Lock()
+		defer entry.mu.Unlock()
+		fn(&entry.job.Progress)
+	}
+
+	go func() {
+		err := work(ctx, update)
+
+		entry.mu.Lock()
+		defer entry.mu.Unlock()
+
+		now := time.Now().UTC()
+		entry.job.FinishedAt = &now
+		if err != nil {
+			entry.job.Status = JobStatusFailed
+			entry.job.Error = err.Error()
+			logger.Err(ctx, err).
+				Str("job_id", entry.job.Id).
+				Str("collection", entry.job.Progress.Collection).
+				Bool("user_visible", true).
+				Msg("Collection ingestion failed.")
+		} else {
+			entry.job.Status = JobStatusCompleted
+			logger.Info(ctx).
+				Str("job_id", entry.job.Id).
+				Str("collection", entry.job.Progress.Collection).
+				Int("imported", entry.job.Progress.Imported).
+				Msg("Collection ingestion completed.")
+		}
+	}()
+
+	return entry.job.Id
+}
+
+func pruneJobs() {
+	cutoff := time.Now().Add(-jobRetention)
+	jobs.Range(func(key, value any) bool {
+		entry := value.(*jobEntry)
+		entry.mu.Lock()
+		expired := entry.job.FinishedAt != nil && entry.job.FinishedAt.Before(cutoff)
+		entry.mu.Unlock()
+		if expired {
+			jobs.Delete(key)
+		}
+		return true
+	})
+}
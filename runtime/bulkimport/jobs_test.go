@@ -0,0 +1,69 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bulkimport
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartJob(t *testing.T) {
+	release := make(chan struct{})
+	id := startJob(context.Background(), ImportResult{Collection: "docs"}, 10, func(ctx context.Context, update func(func(*ImportResult))) error {
+		update(func(r *ImportResult) { r.Imported = 4 })
+		<-release
+		update(func(r *ImportResult) { r.Imported = 10 })
+		return nil
+	})
+
+	require.Eventually(t, func() bool {
+		job, ok := GetJob(id)
+		return ok && job.Progress.Imported == 4
+	}, time.Second, time.Millisecond)
+
+	job, _ := GetJob(id)
+	assert.Equal(t, JobStatusRunning, job.Status)
+	assert.Equal(t, 10, job.TotalRows)
+	assert.Nil(t, job.FinishedAt)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		job, _ := GetJob(id)
+		return job.Status == JobStatusCompleted
+	}, time.Second, time.Millisecond)
+
+	job, _ = GetJob(id)
+	assert.Equal(t, 10, job.Progress.Imported)
+	assert.NotNil(t, job.FinishedAt)
+}
+
+func TestStartJob_Failed(t *testing.T) {
+	id := startJob(context.Background(), ImportResult{Collection: "docs"}, 1, func(ctx context.Context, update func(func(*ImportResult))) error {
+		return errors.New("embedder unavailable")
+	})
+
+	require.Eventually(t, func() bool {
+		job, _ := GetJob(id)
+		return job.Status == JobStatusFailed
+	}, time.Second, time.Millisecond)
+
+	job, _ := GetJob(id)
+	assert.Equal(t, "embedder unavailable", job.Error)
+}
+
+func TestGetJob_NotFound(t *testing.T) {
+	_, ok := GetJob("missing")
+	assert.False(t, ok)
+}
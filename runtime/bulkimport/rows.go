@@ -0,0 +1,176 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bulkimport
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+)
+
+// rowBatch holds the items read from the file, ready to be upserted.
+// The keys and labels are nil when the file has no columns mapped to them.
+type rowBatch struct {
+	read   int
+	keys   []string
+	texts  []string
+	labels [][]string
+}
+
+func (b *rowBatch) add(from *rowBatch, i int, chunking *ChunkOptions) {
+	chunks := chunking.split(from.texts[i])
+	for n, text := range chunks {
+		if from.keys != nil {
+			key := from.keys[i]
+			if len(chunks) > 1 {
+				key = fmt.Sprintf("%s#%d", key, n)
+			}
+			b.keys = append(b.keys, key)
+		}
+		b.texts = append(b.texts, text)
+		if from.labels != nil {
+			b.labels = append(b.labels, from.labels[i])
+		}
+	}
+}
+
+// readRows converts a record to collection items, according to the column mapping.
+// Rows without text, or without a key when there is a key column, are skipped.
+func readRows(rec arrow.Record, columns *ImportColumns) (*rowBatch, error) {
+	schema := rec.Schema()
+
+	textCol, err := column(rec, schema, columns.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	var keyCol, labelsCol arrow.Array
+	if columns.Key != "" {
+		if keyCol, err = column(rec, schema, columns.Key); err != nil {
+			return nil, err
+		}
+	}
+	if columns.Labels != "" {
+		if labelsCol, err = column(rec, schema, columns.Labels); err != nil {
+			return nil, err
+		}
+	}
+
+	metadataCols := make([]arrow.Array, len(columns.Metadata))
+	for i, name := range columns.Metadata {
+		if metadataCols[i], err = column(rec, schema, name); err != nil {
+			return nil, err
+		}
+	}
+
+	n := int(rec.NumRows())
+	rows := &rowBatch{read: n, texts: make([]string, 0, n)}
+	if keyCol != nil {
+		rows.keys = make([]string, 0, n)
+	}
+	if labelsCol != nil || len(metadataCols) > 0 {
+		rows.labels = make([][]string, 0, n)
+	}
+
+	for i := 0; i < n; i++ {
+		text := valueString(textCol, i)
+		if text == "" {
+			continue
+		}
+
+		if keyCol != nil {
+			key := valueString(keyCol, i)
+			if key == "" {
+				continue
+			}
+			rows.keys = append(rows.keys, key)
+		}
+
+		rows.texts = append(rows.texts, text)
+
+		if rows.labels != nil {
+			labels := []string{}
+			if labelsCol != nil {
+				labels = append(labels, valueStrings(labelsCol, i, columns.LabelSeparator)...)
+			}
+			for j, col := range metadataCols {
+				if !col.IsNull(i) {
+					labels = append(labels, columns.Metadata[j]+"="+valueString(col, i))
+				}
+			}
+			rows.labels = append(rows.labels, labels)
+		}
+	}
+
+	return rows, nil
+}
+
+func column(rec arrow.Record, schema *arrow.Schema, name string) (arrow.Array, error) {
+	indices := schema.FieldIndices(name)
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("column %s was not found", name)
+	}
+	return rec.Column(indices[0]), nil
+}
+
+// valueString returns the value at index i as a string, or an empty string if it is null.
+func valueString(arr arrow.Array, i int) string {
+	if arr.IsNull(i) {
+		return ""
+	}
+
+	switch a := arr.(type) {
+	case *array.String:
+		return strings.Clone(a.Value(i))
+	case *array.LargeString:
+		return strings.Clone(a.Value(i))
+	default:
+		return arr.ValueStr(i)
+	}
+}
+
+// valueStrings returns the values of a list at index i as strings.
+// A column that is not a list is treated as a list of one value, or split by the separator if one is given.
+func valueStrings(arr arrow.Array, i int, separator string) []string {
+	if arr.IsNull(i) {
+		return nil
+	}
+
+	list, ok := arr.(array.ListLike)
+	if !ok {
+		return splitLabels(valueString(arr, i), separator)
+	}
+
+	start, end := list.ValueOffsets(i)
+	values := list.ListValues()
+	result := make([]string, 0, end-start)
+	for j := int(start); j < int(end); j++ {
+		if !values.IsNull(j) {
+			result = append(result, valueString(values, j))
+		}
+	}
+	return result
+}
+
+func splitLabels(value, separator string) []string {
+	if separator == "" {
+		return []string{value}
+	}
+
+	labels := []string{}
+	for _, label := range strings.Split(value, separator) {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
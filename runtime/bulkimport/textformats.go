@@ -0,0 +1,206 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bulkimport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// readText parses a CSV or JSON Lines file into collection items, according to the column mapping.
+// CSV files must have a header row, which names the columns.  Each line of a JSON Lines file is an object,
+// whose properties are the columns.  Rows without text, or without a key when there is a key column, are skipped.
+func readText(content []byte, format Format, columns *ImportColumns) (*rowBatch, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(content, columns)
+	case FormatJSONL:
+		return readJSONL(content, columns)
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+func readCSV(content []byte, columns *ImportColumns) (*rowBatch, error) {
+	r := csv.NewReader(bytes.NewReader(content))
+	r.ReuseRecord = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the header row: %w", err)
+	}
+
+	index := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		if i := slices.Index(header, name); i >= 0 {
+			return i, nil
+		}
+		return -1, fmt.Errorf("column %s was not found", name)
+	}
+
+	textIdx, err := index(columns.Text)
+	if err != nil {
+		return nil, err
+	}
+	keyIdx, err := index(columns.Key)
+	if err != nil {
+		return nil, err
+	}
+	labelsIdx, err := index(columns.Labels)
+	if err != nil {
+		return nil, err
+	}
+	metadataIdx := make([]int, len(columns.Metadata))
+	for i, name := range columns.Metadata {
+		if metadataIdx[i], err = index(name); err != nil {
+			return nil, err
+		}
+	}
+
+	rows := newTextRows(columns)
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid row on line %d: %w", line, err)
+		}
+
+		get := func(i int) string {
+			if i < 0 {
+				return ""
+			}
+			return record[i]
+		}
+
+		var labels []string
+		if labelsIdx >= 0 {
+			if value := get(labelsIdx); value != "" {
+				labels = splitLabels(value, columns.LabelSeparator)
+			}
+		}
+		metadata := make([]string, len(metadataIdx))
+		for i, idx := range metadataIdx {
+			metadata[i] = get(idx)
+		}
+
+		rows.addText(get(keyIdx), get(textIdx), labels, metadata, columns)
+	}
+
+	return rows, nil
+}
+
+func readJSONL(content []byte, columns *ImportColumns) (*rowBatch, error) {
+	rows := newTextRows(columns)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var obj map[string]any
+		if err := utils.JsonDeserialize(data, &obj); err != nil {
+			return nil, fmt.Errorf("invalid object on line %d: %w", line, err)
+		}
+
+		var labels []string
+		if columns.Labels != "" {
+			switch v := obj[columns.Labels].(type) {
+			case nil:
+			case []any:
+				labels = make([]string, 0, len(v))
+				for _, item := range v {
+					if item != nil {
+						labels = append(labels, jsonValueString(item))
+					}
+				}
+			default:
+				labels = splitLabels(jsonValueString(v), columns.LabelSeparator)
+			}
+		}
+		metadata := make([]string, len(columns.Metadata))
+		for i, name := range columns.Metadata {
+			metadata[i] = jsonValueString(obj[name])
+		}
+
+		rows.addText(jsonValueString(obj[columns.Key]), jsonValueString(obj[columns.Text]), labels, metadata, columns)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func newTextRows(columns *ImportColumns) *rowBatch {
+	rows := &rowBatch{texts: []string{}}
+	if columns.Key != "" {
+		rows.keys = []string{}
+	}
+	if columns.Labels != "" || len(columns.Metadata) > 0 {
+		rows.labels = [][]string{}
+	}
+	return rows
+}
+
+// addText adds a row read from a text format, skipping it if it has no text, or no key when keys are mapped.
+func (b *rowBatch) addText(key, text string, labels, metadata []string, columns *ImportColumns) {
+	b.read++
+	if text == "" || (b.keys != nil && key == "") {
+		return
+	}
+
+	if b.keys != nil {
+		b.keys = append(b.keys, key)
+	}
+	b.texts = append(b.texts, text)
+
+	if b.labels != nil {
+		itemLabels := append([]string{}, labels...)
+		for i, value := range metadata {
+			if value != "" {
+				itemLabels = append(itemLabels, columns.Metadata[i]+"="+value)
+			}
+		}
+		b.labels = append(b.labels, itemLabels)
+	}
+}
+
+// jsonValueString converts a JSON value to a string, or an empty string if it is null or missing.
+func jsonValueString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		if b, err := utils.JsonSerialize(v); err == nil {
+			return string(b)
+		}
+		return fmt.Sprint(v)
+	}
+}
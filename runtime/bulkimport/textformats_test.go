@@ -0,0 +1,60 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bulkimport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCSV(t *testing.T) {
+	content := []byte(`id,body,tags,year
+a,first,"x; y",2023
+b,second,,
+,third,z,2024
+d,,z,2025
+`)
+
+	rows, err := readText(content, FormatCSV, &ImportColumns{Key: "id", Text: "body", Labels: "tags", LabelSeparator: ";", Metadata: []string{"year"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, rows.read)
+	assert.Equal(t, []string{"a", "b"}, rows.keys)
+	assert.Equal(t, []string{"first", "second"}, rows.texts)
+	assert.Equal(t, [][]string{{"x", "y", "year=2023"}, {}}, rows.labels)
+}
+
+func TestReadCSV_MissingColumn(t *testing.T) {
+	_, err := readText([]byte("id,body\na,first\n"), FormatCSV, &ImportColumns{Text: "content"})
+	assert.Error(t, err)
+}
+
+func TestReadJSONL(t *testing.T) {
+	content := []byte(`{"id": 1, "body": "first", "tags": ["x", "y"], "year": 2023}
+
+{"id": 2, "body": "second", "tags": "z"}
+{"id": 3}
+`)
+
+	rows, err := readText(content, FormatJSONL, &ImportColumns{Key: "id", Text: "body", Labels: "tags", Metadata: []string{"year"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, rows.read)
+	assert.Equal(t, []string{"1", "2"}, rows.keys)
+	assert.Equal(t, []string{"first", "second"}, rows.texts)
+	assert.Equal(t, [][]string{{"x", "y", "year=2023"}, {"z"}}, rows.labels)
+}
+
+func TestReadJSONL_Invalid(t *testing.T) {
+	_, err := readText([]byte("{\"body\": \"first\"}\nnot json\n"), FormatJSONL, &ImportColumns{Text: "body"})
+	assert.ErrorContains(t, err, "line 2")
+}
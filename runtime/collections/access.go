@@ -0,0 +1,84 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/middleware"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+const defaultRolesClaim = "roles"
+
+// checkAccess verifies that the caller has the given permission on the collection,
+// according to the access policy declared in the manifest.  Collections without an
+// access policy are accessible to every caller.
+func checkAccess(ctx context.Context, collectionName string, permission manifest.CollectionPermission) error {
+	info, ok := manifestdata.GetManifest().Collections[collectionName]
+	if !ok || info.Access == nil {
+		return nil
+	}
+
+	claim := info.Access.RolesClaim
+	if claim == "" {
+		claim = defaultRolesClaim
+	}
+
+	roles := rolesFromClaims(middleware.GetJWTClaims(ctx), claim)
+	if !isPermitted(info.Access, roles, permission) {
+		return fmt.Errorf("access denied: %s permission is required for collection %s", permission, collectionName)
+	}
+
+	return nil
+}
+
+func isPermitted(access *manifest.CollectionAccessInfo, roles []string, permission manifest.CollectionPermission) bool {
+	roles = append(roles, manifest.CollectionAccessRoleAny)
+	for _, role := range roles {
+		permissions := access.Roles[role]
+		if slices.Contains(permissions, permission) || slices.Contains(permissions, manifest.CollectionPermissionAdmin) {
+			return true
+		}
+	}
+	return false
+}
+
+// rolesFromClaims reads the caller's roles from the JWT claims, where the claim may be
+// either a single string or an array of strings.
+func rolesFromClaims(claimsJson, claim string) []string {
+	if claimsJson == "" {
+		return nil
+	}
+
+	var claims map[string]any
+	if err := utils.JsonDeserialize([]byte(claimsJson), &claims); err != nil {
+		return nil
+	}
+
+	switch v := claims[claim].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		roles := make([]string, 0, len(v))
+		for _, r := range v {
+			if s, ok := r.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+		return roles
+	}
+
+	return nil
+}
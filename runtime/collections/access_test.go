@@ -0,0 +1,65 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+)
+
+func TestRolesFromClaims(t *testing.T) {
+	tests := []struct {
+		claims   string
+		expected []string
+	}{
+		{``, nil},
+		{`{"sub":"user1"}`, nil},
+		{`{"roles":"frontend"}`, []string{"frontend"}},
+		{`{"roles":["frontend","backend",1]}`, []string{"frontend", "backend"}},
+	}
+
+	for _, tt := range tests {
+		roles := rolesFromClaims(tt.claims, "roles")
+		if !reflect.DeepEqual(roles, tt.expected) {
+			t.Errorf("claims %s: expected %v, got %v", tt.claims, tt.expected, roles)
+		}
+	}
+}
+
+func TestIsPermitted(t *testing.T) {
+	access := &manifest.CollectionAccessInfo{
+		Roles: map[string][]manifest.CollectionPermission{
+			"*":        {manifest.CollectionPermissionRead},
+			"frontend": {manifest.CollectionPermissionSearch},
+			"backend":  {manifest.CollectionPermissionAdmin},
+		},
+	}
+
+	tests := []struct {
+		roles      []string
+		permission manifest.CollectionPermission
+		expected   bool
+	}{
+		{nil, manifest.CollectionPermissionRead, true},
+		{nil, manifest.CollectionPermissionSearch, false},
+		{[]string{"frontend"}, manifest.CollectionPermissionSearch, true},
+		{[]string{"frontend"}, manifest.CollectionPermissionWrite, false},
+		{[]string{"frontend", "backend"}, manifest.CollectionPermissionWrite, true},
+		{[]string{"other"}, manifest.CollectionPermissionAdmin, false},
+	}
+
+	for _, tt := range tests {
+		if actual := isPermitted(access, tt.roles, tt.permission); actual != tt.expected {
+			t.Errorf("roles %v, permission %s: expected %v, got %v", tt.roles, tt.permission, tt.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/db"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+)
+
+// Aliases are alternate names of collections.  Every operation resolves the collection name it is given,
+// so that clients can keep using an alias while it is re-pointed to a rebuilt or re-embedded collection.
+// Re-pointing is atomic: operations that resolved the alias before it complete on the previous collection,
+// and those that resolve it afterwards use the new one.
+
+var aliases = map[string]string{}
+var aliasesMu sync.RWMutex
+
+// swapMu serializes alias swaps, so that each one is stored and applied before the next begins.
+var swapMu sync.Mutex
+
+// resolveCollectionName returns the collection that the name refers to, which is either an alias or a collection name.
+func resolveCollectionName(name string) string {
+	aliasesMu.RLock()
+	defer aliasesMu.RUnlock()
+
+	if collectionName, ok := aliases[name]; ok {
+		return collectionName
+	}
+	return name
+}
+
+// loadAliases sets the aliases declared in the manifest, and those that were re-pointed at runtime,
+// which take precedence.  Aliases that are now the name of a collection are ignored.
+func loadAliases(ctx context.Context, md *manifest.Manifest) {
+	loaded := make(map[string]string, len(md.Aliases))
+	for alias, collectionName := range md.Aliases {
+		loaded[alias] = collectionName
+	}
+
+	stored, err := db.GetCollectionAliases(ctx)
+	if err != nil && !db.IsNotConfigured(err) {
+		logger.Warn(ctx).Err(err).Msg("Failed to load collection aliases.")
+	}
+	for alias, collectionName := range stored {
+		loaded[alias] = collectionName
+	}
+
+	for alias, collectionName := range loaded {
+		if _, ok := md.Collections[alias]; ok {
+			logger.Warn(ctx).
+				Str("alias", alias).
+				Msg("Collection alias is ignored, because it is the name of a collection.")
+			delete(loaded, alias)
+		} else if _, ok := md.Collections[collectionName]; !ok {
+			logger.Warn(ctx).
+				Str("alias", alias).
+				Str("collection_name", collectionName).
+				Msg("Collection alias points to a collection that is not in the manifest.")
+		}
+	}
+
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+	aliases = loaded
+}
+
+// SwapAlias points the alias to the collection, creating the alias if it doesn't exist, and returns the
+// collection it pointed to before, or an empty string.  The collection must have every search method of the
+// previous collection, so that searches made through the alias keep working.
+func SwapAlias(ctx context.Context, alias, collectionName string) (string, error) {
+	if alias == "" {
+		return "", errors.New("alias is empty")
+	}
+
+	md := manifestdata.GetManifest()
+	if _, ok := md.Collections[alias]; ok {
+		return "", fmt.Errorf("alias %s is the name of a collection", alias)
+	}
+	info, ok := md.Collections[collectionName]
+	if !ok {
+		return "", fmt.Errorf("collection %s not found in manifest", collectionName)
+	}
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionAdmin); err != nil {
+		return "", err
+	}
+
+	swapMu.Lock()
+	defer swapMu.Unlock()
+
+	previous := resolveCollectionName(alias)
+	if previous == alias {
+		previous = ""
+	}
+
+	if previous != "" {
+		if err := checkAccess(ctx, previous, manifest.CollectionPermissionAdmin); err != nil {
+			return "", err
+		}
+		for searchMethod := range md.Collections[previous].SearchMethods {
+			if _, ok := info.SearchMethods[searchMethod]; !ok {
+				return "", fmt.Errorf("collection %s has no search method %s, which is used by collection %s", collectionName, searchMethod, previous)
+			}
+		}
+	}
+
+	// the alias is stored first, so that it is not lost if the runtime restarts
+	if err := db.WriteCollectionAlias(ctx, alias, collectionName); err != nil && !db.IsNotConfigured(err) {
+		return "", fmt.Errorf("failed to store alias %s: %w", alias, err)
+	}
+
+	aliasesMu.Lock()
+	aliases[alias] = collectionName
+	aliasesMu.Unlock()
+
+	logger.Info(ctx).
+		Str("alias", alias).
+		Str("collection_name", collectionName).
+		Str("previous_collection_name", previous).
+		Msg("Collection alias swapped.")
+
+	return previous, nil
+}
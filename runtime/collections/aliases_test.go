@@ -0,0 +1,77 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+)
+
+func TestResolveCollectionName(t *testing.T) {
+	aliasesMu.Lock()
+	aliases = map[string]string{"docs-live": "docs-v3"}
+	aliasesMu.Unlock()
+	t.Cleanup(func() { aliases = map[string]string{} })
+
+	tests := map[string]string{"docs-live": "docs-v3", "docs-v3": "docs-v3", "other": "other"}
+	for name, expected := range tests {
+		if actual := resolveCollectionName(name); actual != expected {
+			t.Errorf("name %s: expected %s, got %s", name, expected, actual)
+		}
+	}
+}
+
+func TestSwapAliasValidation(t *testing.T) {
+	searchMethods := func(names ...string) map[string]manifest.SearchMethodInfo {
+		m := make(map[string]manifest.SearchMethodInfo, len(names))
+		for _, name := range names {
+			m[name] = manifest.SearchMethodInfo{Embedder: "embed"}
+		}
+		return m
+	}
+
+	original := manifestdata.GetManifest()
+	manifestdata.SetManifest(&manifest.Manifest{
+		Collections: map[string]manifest.CollectionInfo{
+			"docs-v2": {Name: "docs-v2", SearchMethods: searchMethods("semantic", "keyword")},
+			"docs-v3": {Name: "docs-v3", SearchMethods: searchMethods("semantic")},
+		},
+	})
+	t.Cleanup(func() { manifestdata.SetManifest(original) })
+
+	aliasesMu.Lock()
+	aliases = map[string]string{"docs-live": "docs-v2"}
+	aliasesMu.Unlock()
+	t.Cleanup(func() { aliases = map[string]string{} })
+
+	tests := []struct {
+		alias, collectionName, expected string
+	}{
+		{"", "docs-v3", "alias is empty"},
+		{"docs-v2", "docs-v3", "is the name of a collection"},
+		{"docs-live", "docs-v4", "not found in manifest"},
+		{"docs-live", "docs-v3", "has no search method keyword"},
+	}
+
+	for _, tt := range tests {
+		_, err := SwapAlias(context.Background(), tt.alias, tt.collectionName)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("alias %q to %s: expected error containing %q, got %v", tt.alias, tt.collectionName, tt.expected, err)
+		}
+	}
+
+	if actual := resolveCollectionName("docs-live"); actual != "docs-v2" {
+		t.Errorf("expected the alias to be unchanged, but it points to %s", actual)
+	}
+}
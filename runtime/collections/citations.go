@@ -0,0 +1,101 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The sources of a RAG query are numbered in the prompt, and the model is asked to cite them by number in square
+// brackets after the statements they support, such as "Paris is the capital of France [1]." or "... [1, 2].".
+// The markers are parsed from the answer, so that each cited statement can be linked to the items it is based on.
+
+var citationMarkerRegex = regexp.MustCompile(`\[\s*(\d+(?:\s*,\s*\d+)*)\s*\]`)
+
+// parseCitationSpans returns the spans of the answer that cite sources.  The span of a marker, or of adjacent
+// markers, is the text of the sentence that precedes it, since the last marker.  Markers that cite numbers
+// which are not the numbers of sources are ignored.  The offsets of the spans are in characters, and the
+// end of a span is before the marker, so the markers themselves are not part of any span.
+func parseCitationSpans(answer string, citations []*CollectionRagCitation) []*CollectionRagSpan {
+	spans := []*CollectionRagSpan{}
+	matches := citationMarkerRegex.FindAllStringSubmatchIndex(answer, -1)
+
+	prev := 0
+	for i := 0; i < len(matches); {
+		// adjacent markers, separated only by whitespace, cite the same text
+		groupStart := matches[i][0]
+		var numbers []int
+		j := i
+		for ; j < len(matches); j++ {
+			if j > i && strings.TrimSpace(answer[matches[j-1][1]:matches[j][0]]) != "" {
+				break
+			}
+			for _, s := range strings.Split(answer[matches[j][2]:matches[j][3]], ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(s))
+				if err == nil && n >= 1 && n <= len(citations) && !slices.Contains(numbers, n) {
+					numbers = append(numbers, n)
+				}
+			}
+		}
+		groupEnd := matches[j-1][1]
+		i = j
+
+		end := len(strings.TrimRight(answer[:groupStart], " \t\r\n"))
+		start := sentenceStart(answer, prev, end)
+		prev = groupEnd
+		if len(numbers) == 0 || start >= end {
+			continue
+		}
+
+		span := &CollectionRagSpan{
+			Start:     int32(utf8.RuneCountInString(answer[:start])),
+			End:       int32(utf8.RuneCountInString(answer[:end])),
+			Text:      answer[start:end],
+			Citations: make([]*CollectionRagCitation, len(numbers)),
+		}
+		for k, n := range numbers {
+			span.Citations[k] = citations[n-1]
+		}
+		spans = append(spans, span)
+	}
+
+	return spans
+}
+
+// sentenceStart returns the start of the sentence that ends at the end offset, but not before the from offset.
+// A sentence ends with a period, exclamation or question mark that is followed by whitespace, or with a line break.
+func sentenceStart(s string, from, end int) int {
+	if from >= end {
+		return end
+	}
+
+	// the sentence may end with its own punctuation, before the marker
+	limit := end
+	for limit > from && strings.ContainsRune(".!?", rune(s[limit-1])) {
+		limit--
+	}
+
+	start := from
+	for i := limit - 1; i > from; i-- {
+		if s[i] == '\n' || (s[i] == ' ' || s[i] == '\t') && strings.ContainsRune(".!?", rune(s[i-1])) {
+			start = i + 1
+			break
+		}
+	}
+
+	for start < end && strings.ContainsRune(" \t\r\n", rune(s[start])) {
+		start++
+	}
+	return start
+}
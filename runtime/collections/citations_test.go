@@ -0,0 +1,65 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCitationSpans(t *testing.T) {
+	citations := []*CollectionRagCitation{
+		NewCollectionRagCitation("", "paris", 0.9),
+		NewCollectionRagCitation("", "population", 0.8),
+		NewCollectionRagCitation("", "history", 0.7),
+	}
+
+	answer := "Paris is the capital of France [1]. It has about two million people [2][3]. The city is old. [3] Nobody knows [7]."
+	spans := parseCitationSpans(answer, citations)
+	require.Len(t, spans, 3)
+
+	require.Equal(t, "Paris is the capital of France", spans[0].Text)
+	require.Equal(t, int32(0), spans[0].Start)
+	require.Equal(t, int32(30), spans[0].End)
+	require.Equal(t, []*CollectionRagCitation{citations[0]}, spans[0].Citations)
+
+	require.Equal(t, "It has about two million people", spans[1].Text)
+	require.Equal(t, []*CollectionRagCitation{citations[1], citations[2]}, spans[1].Citations)
+
+	require.Equal(t, "The city is old.", spans[2].Text)
+	require.Equal(t, []*CollectionRagCitation{citations[2]}, spans[2].Citations)
+
+	for _, span := range spans {
+		require.Equal(t, span.Text, string([]rune(answer)[span.Start:span.End]))
+	}
+}
+
+func TestParseCitationSpansWithLists(t *testing.T) {
+	citations := []*CollectionRagCitation{
+		NewCollectionRagCitation("", "a", 0.9),
+		NewCollectionRagCitation("", "b", 0.8),
+	}
+
+	answer := "Première phrase. Café au lait [1, 2, 1]\nDeuxième ligne [2]"
+	spans := parseCitationSpans(answer, citations)
+	require.Len(t, spans, 2)
+
+	require.Equal(t, "Café au lait", spans[0].Text)
+	require.Equal(t, []*CollectionRagCitation{citations[0], citations[1]}, spans[0].Citations)
+	require.Equal(t, "Deuxième ligne", spans[1].Text)
+
+	for _, span := range spans {
+		require.Equal(t, span.Text, string([]rune(answer)[span.Start:span.End]))
+	}
+
+	require.Empty(t, parseCitationSpans("No citations here.", citations))
+	require.Empty(t, parseCitationSpans("[1] starts with a marker.", citations))
+}
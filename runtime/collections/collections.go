@@ -17,26 +17,43 @@ import (
 	"sort"
 	"time"
 
+	"github.com/hypermodeinc/modus/lib/manifest"
 	"github.com/hypermodeinc/modus/runtime/collections/in_mem"
 	"github.com/hypermodeinc/modus/runtime/collections/index"
 	"github.com/hypermodeinc/modus/runtime/collections/index/interfaces"
 	collection_utils "github.com/hypermodeinc/modus/runtime/collections/utils"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/datascope"
 	"github.com/hypermodeinc/modus/runtime/functions"
 	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/metering"
 	"github.com/hypermodeinc/modus/runtime/utils"
 	"github.com/hypermodeinc/modus/runtime/wasmhost"
+
+	"golang.org/x/sync/errgroup"
 )
 
+// maxConcurrentSearchMethods limits how many search methods are embedded and indexed at once during an upsert.
+const maxConcurrentSearchMethods = 4
+
 var errInvalidEmbedderSignature = errors.New("invalid embedder function signature")
 
 func Initialize(ctx context.Context) {
 	globalNamespaceManager = newCollectionFactory()
+	globalRestore = newRestoreTracker()
 	manifestdata.RegisterManifestLoadedCallback(cleanAndProcessManifest)
 	functions.RegisterFunctionsLoadedCallback(func(ctx context.Context) {
-		globalNamespaceManager.readFromPostgres(ctx)
+		// an embedder's implementation may have changed even though its name has not
+		globalEmbeddingCache.reset(config.EmbeddingCacheSize)
+		globalNamespaceManager.restore(ctx)
+
+		if config.IsDevEnvironment() {
+			reportDiagnostics(ctx)
+		}
 	})
 
 	go globalNamespaceManager.worker(ctx)
+	go globalNamespaceManager.driftMonitor(ctx)
 }
 
 func Shutdown(ctx context.Context) {
@@ -45,6 +62,15 @@ func Shutdown(ctx context.Context) {
 }
 
 func Upsert(ctx context.Context, collectionName, namespace string, keys, texts []string, labels [][]string) (*CollectionMutationResult, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionWrite); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
+		return nil, err
+	}
+	defer lockForWrite(ctx)()
 
 	// Get the collectionName data from the manifest
 	collectionData := manifestdata.GetManifest().Collections[collectionName]
@@ -54,13 +80,10 @@ func Upsert(ctx context.Context, collectionName, namespace string, keys, texts [
 		return nil, err
 	}
 
-	if namespace == "" {
-		namespace = in_mem.DefaultNamespace
-	}
-
+	storedNamespace := resolveNamespace(ctx, collectionName, namespace)
 	collNs, err := func(namespace string, index interfaces.CollectionNamespace) (interfaces.CollectionNamespace, error) {
 		return col.findOrCreateNamespace(namespace, index)
-	}(namespace, in_mem.NewCollectionNamespace(collectionName, namespace))
+	}(storedNamespace, newCollectionNamespace(ctx, collectionName, storedNamespace))
 	if err != nil {
 		return nil, err
 	}
@@ -76,145 +99,180 @@ func Upsert(ctx context.Context, collectionName, namespace string, keys, texts [
 		return nil, fmt.Errorf("mismatch in number of labels and texts: %d != %d", len(labels), len(texts))
 	}
 
+	detections := []*CollectionPIIObject{}
+	if collectionData.PIIDetection != nil {
+		batch, pii, err := detectPII(ctx, collectionName, namespace, collectionData.PIIDetection, keys, texts, labels)
+		if err != nil {
+			return nil, err
+		}
+		keys, texts, labels = batch.keys, batch.texts, batch.labels
+		detections = pii
+	}
+
+	duplicates := []*CollectionDuplicateObject{}
+	var precomputed [][]float32
+	if collectionData.DuplicateDetection != nil {
+		batch, dups, err := detectDuplicates(ctx, collNs, collectionData, keys, texts, labels)
+		if err != nil {
+			return nil, err
+		}
+		keys, texts, labels, precomputed = batch.keys, batch.texts, batch.labels, batch.vectors
+		duplicates = dups
+	}
+
+	if len(texts) == 0 {
+		result := NewCollectionMutationResult(collectionName, "upsert", "success", keys, "")
+		result.Duplicates = duplicates
+		result.PII = detections
+		return result, nil
+	}
+
 	err = collNs.InsertTexts(ctx, keys, texts, labels)
 	if err != nil {
 		return nil, err
 	}
 
 	// compute embeddings for each search method, and insert into vector index
+	// the search methods are independent of each other, so they are processed concurrently
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentSearchMethods)
 	for searchMethodName, searchMethod := range collectionData.SearchMethods {
-		vectorIndex, err := collNs.GetVectorIndex(ctx, searchMethodName)
-		if err == index.ErrVectorIndexNotFound {
-			vectorIndex, err = createIndexObject(searchMethod, searchMethodName)
-			if err != nil {
-				return nil, err
-			}
-			err = collNs.SetVectorIndex(ctx, searchMethodName, vectorIndex)
-			if err != nil {
-				return nil, err
-			}
-		} else if err != nil {
-			return nil, err
+		var textVecs [][]float32
+		if precomputed != nil && searchMethodName == collectionData.DuplicateDetection.SearchMethod {
+			// already embedded during duplicate detection
+			textVecs = precomputed
 		}
 
-		embedder := searchMethod.Embedder
-		if err := validateEmbedder(ctx, embedder); err != nil {
-			return nil, err
-		}
+		g.Go(func() error {
+			if err := upsertVectors(gCtx, collNs, searchMethodName, searchMethod, keys, texts, textVecs); err != nil {
+				return fmt.Errorf("error upserting vectors for search method %s: %w", searchMethodName, err)
+			}
+			return nil
+		})
+	}
 
-		callCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
-		defer cancel()
-		executionInfo, err := wasmhost.CallFunction(callCtx, embedder, texts)
-		if err != nil {
-			return nil, err
-		}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	// storage is metered by the size of the stored items, not including their vectors
+	var size int
+	for _, text := range texts {
+		size += len(text)
+	}
+	metering.Add(ctx, metering.UnitStorageBytes, float64(size))
+
+	fireTrigger(ctx, collectionName, namespace, "upsert", keys)
 
-		result := executionInfo.Result()
+	result := NewCollectionMutationResult(collectionName, "upsert", "success", keys, "")
+	result.Duplicates = duplicates
+	result.PII = detections
+	return result, nil
+}
+
+// upsertVectors embeds the items for a single search method, unless the vectors are provided,
+// and inserts them into that search method's vector index, creating the index if needed.
+func upsertVectors(ctx context.Context, collNs interfaces.CollectionNamespace, searchMethodName string, searchMethod manifest.SearchMethodInfo, keys, texts []string, textVecs [][]float32) error {
+	searchMethod = forNamespace(searchMethod, collNs)
 
-		textVecs, err := collection_utils.ConvertToFloat32_2DArray(result)
+	vectorIndex, err := collNs.GetVectorIndex(ctx, searchMethodName)
+	if err == index.ErrVectorIndexNotFound {
+		vectorIndex, err = createIndexObject(searchMethod, searchMethodName)
 		if err != nil {
-			return nil, err
+			return err
 		}
-
-		if len(textVecs) != len(texts) {
-			return nil, fmt.Errorf("mismatch in number of embeddings generated by embedder %s", embedder)
+		err = collNs.SetVectorIndex(ctx, searchMethodName, vectorIndex)
+		if err != nil {
+			return err
 		}
+	} else if err != nil {
+		return err
+	}
 
-		ids := make([]int64, len(keys))
-		for i := range textVecs {
-			key := keys[i]
-
-			id, err := collNs.GetExternalId(ctx, key)
-			if err != nil {
-				return nil, err
-			}
-			ids[i] = id
+	if textVecs == nil {
+		textVecs, err = embedItems(ctx, collNs.GetCollectionName(), searchMethodName, searchMethod, texts)
+		if err != nil {
+			return err
 		}
+	}
+
+	ids := make([]int64, len(keys))
+	for i := range textVecs {
+		key := keys[i]
 
-		err = vectorIndex.InsertVectors(ctx, ids, textVecs)
+		id, err := collNs.GetExternalId(ctx, key)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		ids[i] = id
 	}
 
-	return NewCollectionMutationResult(collectionName, "upsert", "success", keys, ""), nil
+	return vectorIndex.InsertVectors(ctx, ids, textVecs)
 }
 
 func Delete(ctx context.Context, collectionName, namespace, key string) (*CollectionMutationResult, error) {
-	col, err := globalNamespaceManager.findCollection(collectionName)
-	if err != nil {
+	collectionName = resolveCollectionName(collectionName)
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionWrite); err != nil {
 		return nil, err
 	}
-
-	if namespace == "" {
-		namespace = in_mem.DefaultNamespace
+	if err := waitForRestore(ctx, collectionName); err != nil {
+		return nil, err
 	}
+	defer lockForWrite(ctx)()
 
-	collNs, err := col.findNamespace(namespace)
+	col, err := globalNamespaceManager.findCollection(collectionName)
 	if err != nil {
 		return nil, err
 	}
 
-	textId, err := collNs.GetExternalId(ctx, key)
+	collNs, err := col.findNamespace(resolveNamespace(ctx, collectionName, namespace))
 	if err != nil {
 		return nil, err
 	}
-	for _, vectorIndex := range collNs.GetVectorIndexMap() {
-		err = vectorIndex.DeleteVector(ctx, textId, key)
-		if err != nil {
-			return nil, err
-		}
+
+	if manifestdata.GetManifest().Collections[collectionName].SoftDelete != nil {
+		err = collNs.SoftDeleteText(ctx, key)
+	} else {
+		err = hardDeleteText(ctx, collNs, key)
 	}
-	err = collNs.DeleteText(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 
 	keys := []string{key}
+	fireTrigger(ctx, collectionName, namespace, "delete", keys)
 
 	return NewCollectionMutationResult(collectionName, "delete", "success", keys, ""), nil
 }
 
 func Search(ctx context.Context, collectionName string, namespaces []string, searchMethod, text string, limit int32, returnText bool) (*CollectionSearchResult, error) {
+	collectionName = resolveCollectionName(collectionName)
 
-	col, err := globalNamespaceManager.findCollection(collectionName)
-	if err != nil {
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionSearch); err != nil {
 		return nil, err
 	}
-
-	if len(namespaces) == 0 {
-		namespaces = []string{in_mem.DefaultNamespace}
-	}
-
-	embedder, err := getEmbedder(ctx, collectionName, searchMethod)
-	if err != nil {
+	if err := waitForRestore(ctx, collectionName); err != nil {
 		return nil, err
 	}
+	defer lockForRead(ctx)()
 
-	texts := []string{text}
-
-	callCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
-	defer cancel()
-	executionInfo, err := wasmhost.CallFunction(callCtx, embedder, texts)
+	col, err := globalNamespaceManager.findCollection(collectionName)
 	if err != nil {
 		return nil, err
 	}
 
-	result := executionInfo.Result()
-
-	textVecs, err := collection_utils.ConvertToFloat32_2DArray(result)
-	if err != nil {
-		return nil, err
+	if len(namespaces) == 0 {
+		namespaces = []string{in_mem.DefaultNamespace}
 	}
 
-	if len(textVecs) == 0 {
-		return nil, fmt.Errorf("no embeddings generated by embedder %s", embedder)
-	}
+	// the text is embedded once for each distinct embedder of the namespaces searched
+	queryVecs := make(map[string][]float32, 1)
 
 	// merge all objects
 	mergedObjects := make([]*CollectionSearchResultObject, 0, len(namespaces)*int(limit))
 	for _, ns := range namespaces {
-		collNs, err := col.findNamespace(ns)
+		collNs, err := col.findNamespace(resolveNamespace(ctx, collectionName, ns))
 		if err != nil {
 			return nil, err
 		}
@@ -224,22 +282,34 @@ func Search(ctx context.Context, collectionName string, namespaces []string, sea
 			return nil, err
 		}
 
-		objects, err := vectorIndex.Search(ctx, textVecs[0], int(limit), nil)
+		embedder, err := getEmbedder(ctx, collectionName, ns, searchMethod)
 		if err != nil {
 			return nil, err
 		}
 
-		for _, object := range objects {
-			text, err := collNs.GetText(ctx, object.GetIndex())
+		queryVec, ok := queryVecs[embedder]
+		if !ok {
+			textVecs, err := computeEmbeddings(ctx, embedder, []string{text})
 			if err != nil {
 				return nil, err
 			}
-			labels, err := collNs.GetLabels(ctx, object.GetIndex())
-			if err != nil {
-				return nil, err
-			}
-			mergedObjects = append(mergedObjects, NewCollectionSearchResultObject(ns, object.GetIndex(), text, labels, object.GetValue(), 1-object.GetValue()))
+			queryVec = textVecs[0]
+			queryVecs[embedder] = queryVec
+		}
+
+		filter := activeItemsFilter(ctx, collNs)
+		start := time.Now()
+		objects, err := vectorIndex.Search(ctx, queryVec, int(limit), filter)
+		if err != nil {
+			return nil, err
 		}
+		recordSearch(ctx, collectionName, searchMethod, vectorIndex.VectorIndex, queryVec, int(limit), filter, objects, time.Since(start))
+
+		results, err := newSearchResultObjects(ctx, collNs, ns, objects, returnText)
+		if err != nil {
+			return nil, err
+		}
+		mergedObjects = append(mergedObjects, results...)
 	}
 
 	// sort by score
@@ -254,13 +324,48 @@ func Search(ctx context.Context, collectionName string, namespaces []string, sea
 	return NewCollectionSearchResult(collectionName, searchMethod, "success", mergedObjects, ""), nil
 }
 
+// newSearchResultObjects builds the result objects for the items found in a namespace.  The stored labels
+// are always included, and the texts only when requested, since they make up most of the result's size.
+func newSearchResultObjects(ctx context.Context, collNs interfaces.CollectionNamespace, namespace string, objects collection_utils.MaxTupleHeap, returnText bool) ([]*CollectionSearchResultObject, error) {
+	results := make([]*CollectionSearchResultObject, 0, len(objects))
+	for _, object := range objects {
+		var text string
+		if returnText {
+			t, err := collNs.GetText(ctx, object.GetIndex())
+			if err != nil {
+				return nil, err
+			}
+			text = t
+		}
+		labels, err := collNs.GetLabels(ctx, object.GetIndex())
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, NewCollectionSearchResultObject(namespace, object.GetIndex(), text, labels, object.GetValue(), 1-object.GetValue()))
+	}
+	return results, nil
+}
+
 func SearchByVector(ctx context.Context, collectionName string, namespaces []string, searchMethod string, vector []float32, limit int32, returnText bool) (*CollectionSearchResult, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionSearch); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
+		return nil, err
+	}
+	defer lockForRead(ctx)()
 
 	col, err := globalNamespaceManager.findCollection(collectionName)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(vector) == 0 {
+		return nil, errors.New("query vector is empty")
+	}
+
 	if len(namespaces) == 0 {
 		namespaces = []string{in_mem.DefaultNamespace}
 	}
@@ -268,7 +373,7 @@ func SearchByVector(ctx context.Context, collectionName string, namespaces []str
 	// merge all objects
 	mergedObjects := make([]*CollectionSearchResultObject, 0, len(namespaces)*int(limit))
 	for _, ns := range namespaces {
-		collNs, err := col.findNamespace(ns)
+		collNs, err := col.findNamespace(resolveNamespace(ctx, collectionName, ns))
 		if err != nil {
 			return nil, err
 		}
@@ -278,22 +383,23 @@ func SearchByVector(ctx context.Context, collectionName string, namespaces []str
 			return nil, err
 		}
 
-		objects, err := vectorIndex.Search(ctx, vector, int(limit), nil)
+		if err := validateQueryVector(ctx, vectorIndex, searchMethod, vector); err != nil {
+			return nil, err
+		}
+
+		filter := activeItemsFilter(ctx, collNs)
+		start := time.Now()
+		objects, err := vectorIndex.Search(ctx, vector, int(limit), filter)
 		if err != nil {
 			return nil, err
 		}
+		recordSearch(ctx, collectionName, searchMethod, vectorIndex.VectorIndex, vector, int(limit), filter, objects, time.Since(start))
 
-		for _, object := range objects {
-			text, err := collNs.GetText(ctx, object.GetIndex())
-			if err != nil {
-				return nil, err
-			}
-			labels, err := collNs.GetLabels(ctx, object.GetIndex())
-			if err != nil {
-				return nil, err
-			}
-			mergedObjects = append(mergedObjects, NewCollectionSearchResultObject(ns, object.GetIndex(), text, labels, object.GetValue(), 1-object.GetValue()))
+		results, err := newSearchResultObjects(ctx, collNs, ns, objects, returnText)
+		if err != nil {
+			return nil, err
 		}
+		mergedObjects = append(mergedObjects, results...)
 	}
 
 	// sort by score
@@ -308,50 +414,100 @@ func SearchByVector(ctx context.Context, collectionName string, namespaces []str
 	return NewCollectionSearchResult(collectionName, searchMethod, "success", mergedObjects, ""), nil
 }
 
-func ClassifyText(ctx context.Context, collectionName, namespace, searchMethod, text string) (*CollectionClassificationResult, error) {
+// SearchByKey finds the items most similar to an existing item, using the item's stored vector as the query,
+// so that its text is not embedded again.  The item itself is excluded from the results.
+func SearchByKey(ctx context.Context, collectionName, namespace, searchMethod, key string, limit int32, returnText bool) (*CollectionSearchResult, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionSearch); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
+		return nil, err
+	}
+	defer lockForRead(ctx)()
 
 	col, err := globalNamespaceManager.findCollection(collectionName)
 	if err != nil {
 		return nil, err
 	}
 
-	if namespace == "" {
-		namespace = in_mem.DefaultNamespace
+	collNs, err := col.findNamespace(resolveNamespace(ctx, collectionName, namespace))
+	if err != nil {
+		return nil, err
 	}
 
-	collNs, err := col.findNamespace(namespace)
+	vectorIndex, err := collNs.GetVectorIndex(ctx, searchMethod)
 	if err != nil {
 		return nil, err
 	}
 
-	vectorIndex, err := collNs.GetVectorIndex(ctx, searchMethod)
+	vector, err := vectorIndex.GetVector(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(vector) == 0 || collNs.IsDeleted(ctx, key) {
+		return nil, fmt.Errorf("item with key %s not found in collection %s", key, collectionName)
+	}
+
+	active := activeItemsFilter(ctx, collNs)
+	filter := func(query, vec []float32, k string) bool {
+		return k != key && (active == nil || active(query, vec, k))
+	}
+
+	start := time.Now()
+	objects, err := vectorIndex.Search(ctx, vector, int(limit), filter)
 	if err != nil {
 		return nil, err
 	}
+	recordSearch(ctx, collectionName, searchMethod, vectorIndex.VectorIndex, vector, int(limit), filter, objects, time.Since(start))
 
-	embedder, err := getEmbedder(ctx, collectionName, searchMethod)
+	results, err := newSearchResultObjects(ctx, collNs, namespace, objects, returnText)
 	if err != nil {
 		return nil, err
 	}
 
-	texts := []string{text}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+
+	return NewCollectionSearchResult(collectionName, searchMethod, "success", results, ""), nil
+}
+
+func ClassifyText(ctx context.Context, collectionName, namespace, searchMethod, text string) (*CollectionClassificationResult, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionSearch); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
+		return nil, err
+	}
+	defer lockForRead(ctx)()
 
-	callCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
-	defer cancel()
-	executionInfo, err := wasmhost.CallFunction(callCtx, embedder, texts)
+	col, err := globalNamespaceManager.findCollection(collectionName)
 	if err != nil {
 		return nil, err
 	}
 
-	result := executionInfo.Result()
+	collNs, err := col.findNamespace(resolveNamespace(ctx, collectionName, namespace))
+	if err != nil {
+		return nil, err
+	}
+
+	vectorIndex, err := collNs.GetVectorIndex(ctx, searchMethod)
+	if err != nil {
+		return nil, err
+	}
 
-	textVecs, err := collection_utils.ConvertToFloat32_2DArray(result)
+	embedder, err := getEmbedder(ctx, collectionName, namespace, searchMethod)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(textVecs) == 0 {
-		return nil, fmt.Errorf("no embeddings generated by embedder %s", embedder)
+	textVecs, err := computeEmbeddings(ctx, embedder, []string{text})
+	if err != nil {
+		return nil, err
 	}
 
 	lenTexts, err := collNs.Len(ctx)
@@ -359,7 +515,7 @@ func ClassifyText(ctx context.Context, collectionName, namespace, searchMethod,
 		return nil, err
 	}
 
-	nns, err := vectorIndex.Search(ctx, textVecs[0], int(math.Log10(float64(lenTexts)))*int(math.Log10(float64(lenTexts))), nil)
+	nns, err := vectorIndex.Search(ctx, textVecs[0], int(math.Log10(float64(lenTexts)))*int(math.Log10(float64(lenTexts))), activeItemsFilter(ctx, collNs))
 	if err != nil {
 		return nil, err
 	}
@@ -419,17 +575,22 @@ func ClassifyText(ctx context.Context, collectionName, namespace, searchMethod,
 }
 
 func GetVector(ctx context.Context, collectionName, namespace, searchMethod, key string) ([]float32, error) {
+	collectionName = resolveCollectionName(collectionName)
 
-	col, err := globalNamespaceManager.findCollection(collectionName)
-	if err != nil {
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionRead); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
 		return nil, err
 	}
+	defer lockForRead(ctx)()
 
-	if namespace == "" {
-		namespace = in_mem.DefaultNamespace
+	col, err := globalNamespaceManager.findCollection(collectionName)
+	if err != nil {
+		return nil, err
 	}
 
-	collNs, err := col.findNamespace(namespace)
+	collNs, err := col.findNamespace(resolveNamespace(ctx, collectionName, namespace))
 	if err != nil {
 		return nil, err
 	}
@@ -448,12 +609,22 @@ func GetVector(ctx context.Context, collectionName, namespace, searchMethod, key
 }
 
 func GetLabels(ctx context.Context, collectionName, namespace, key string) ([]string, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionRead); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
+		return nil, err
+	}
+	defer lockForRead(ctx)()
+
 	col, err := globalNamespaceManager.findCollection(collectionName)
 	if err != nil {
 		return nil, err
 	}
 
-	collNs, err := col.findNamespace(namespace)
+	collNs, err := col.findNamespace(resolveNamespace(ctx, collectionName, namespace))
 	if err != nil {
 		return nil, err
 	}
@@ -467,17 +638,22 @@ func GetLabels(ctx context.Context, collectionName, namespace, key string) ([]st
 }
 
 func ComputeDistance(ctx context.Context, collectionName, namespace, searchMethod, key1, key2 string) (*CollectionSearchResultObject, error) {
+	collectionName = resolveCollectionName(collectionName)
 
-	col, err := globalNamespaceManager.findCollection(collectionName)
-	if err != nil {
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionSearch); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
 		return nil, err
 	}
+	defer lockForRead(ctx)()
 
-	if namespace == "" {
-		namespace = in_mem.DefaultNamespace
+	col, err := globalNamespaceManager.findCollection(collectionName)
+	if err != nil {
+		return nil, err
 	}
 
-	collNs, err := col.findNamespace(namespace)
+	collNs, err := col.findNamespace(resolveNamespace(ctx, collectionName, namespace))
 	if err != nil {
 		return nil, err
 	}
@@ -513,18 +689,54 @@ func ComputeDistance(ctx context.Context, collectionName, namespace, searchMetho
 	return NewCollectionSearchResultObject(namespace, "", "", []string{}, distance, 1-distance), nil
 }
 
-func RecomputeIndex(ctx context.Context, collectionName, namespace, searchMethod string) (*SearchMethodMutationResult, error) {
+// TextSimilarity returns the cosine similarity of two texts, as embedded by the embedder of the collection's search method.
+// Neither text needs to be stored in the collection.
+func TextSimilarity(ctx context.Context, collectionName, searchMethod, text1, text2 string) (float64, error) {
+	collectionName = resolveCollectionName(collectionName)
 
-	col, err := globalNamespaceManager.findCollection(collectionName)
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionSearch); err != nil {
+		return 0, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
+		return 0, err
+	}
+
+	embedder, err := getEmbedder(ctx, collectionName, in_mem.DefaultNamespace, searchMethod)
+	if err != nil {
+		return 0, err
+	}
+
+	vecs, err := computeEmbeddings(ctx, embedder, []string{text1, text2})
+	if err != nil {
+		return 0, err
+	}
+
+	// same as the drift distance, the vectors are normalized before comparing them
+	distance, err := driftDistance(vecs[0], vecs[1])
 	if err != nil {
+		return 0, err
+	}
+
+	return 1 - distance, nil
+}
+
+func RecomputeIndex(ctx context.Context, collectionName, namespace, searchMethod string) (*SearchMethodMutationResult, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionAdmin); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
 		return nil, err
 	}
+	defer lockForWrite(ctx)()
 
-	if namespace == "" {
-		namespace = in_mem.DefaultNamespace
+	col, err := globalNamespaceManager.findCollection(collectionName)
+	if err != nil {
+		return nil, err
 	}
 
-	collNs, err := col.findNamespace(namespace)
+	collNs, err := col.findNamespace(resolveNamespace(ctx, collectionName, namespace))
 	if err != nil {
 		return nil, err
 	}
@@ -543,16 +755,30 @@ func RecomputeIndex(ctx context.Context, collectionName, namespace, searchMethod
 }
 
 func GetText(ctx context.Context, collectionName, namespace, key string) (string, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionRead); err != nil {
+		return "", err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
+		return "", err
+	}
+	defer lockForRead(ctx)()
+
 	col, err := globalNamespaceManager.findCollection(collectionName)
 	if err != nil {
 		return "", err
 	}
 
-	collNs, err := col.findNamespace(namespace)
+	collNs, err := col.findNamespace(resolveNamespace(ctx, collectionName, namespace))
 	if err != nil {
 		return "", err
 	}
 
+	if collNs.IsDeleted(ctx, key) {
+		return "", nil
+	}
+
 	text, err := collNs.GetText(ctx, key)
 	if err != nil {
 		return "", err
@@ -562,17 +788,22 @@ func GetText(ctx context.Context, collectionName, namespace, key string) (string
 }
 
 func DumpTexts(ctx context.Context, collectionName, namespace string) (map[string]string, error) {
+	collectionName = resolveCollectionName(collectionName)
 
-	col, err := globalNamespaceManager.findCollection(collectionName)
-	if err != nil {
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionRead); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
 		return nil, err
 	}
+	defer lockForRead(ctx)()
 
-	if namespace == "" {
-		namespace = in_mem.DefaultNamespace
+	col, err := globalNamespaceManager.findCollection(collectionName)
+	if err != nil {
+		return nil, err
 	}
 
-	collNs, err := col.findNamespace(namespace)
+	collNs, err := col.findNamespace(resolveNamespace(ctx, collectionName, namespace))
 	if err != nil {
 		return nil, err
 	}
@@ -585,10 +816,34 @@ func DumpTexts(ctx context.Context, collectionName, namespace string) (map[strin
 		return make(map[string]string), nil
 	}
 
+	deleted, err := collNs.GetDeletedMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(deleted) > 0 {
+		result := make(map[string]string, len(textMap))
+		for key, text := range textMap {
+			if _, isDeleted := deleted[key]; !isDeleted {
+				result[key] = text
+			}
+		}
+		return result, nil
+	}
+
 	return textMap, nil
 }
 
 func GetNamespaces(ctx context.Context, collectionName string) ([]string, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionRead); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
+		return nil, err
+	}
+	defer lockForRead(ctx)()
+
 	col, err := globalNamespaceManager.findCollection(collectionName)
 	if err != nil {
 		return nil, err
@@ -598,13 +853,16 @@ func GetNamespaces(ctx context.Context, collectionName string) ([]string, error)
 
 	namespaces := make([]string, 0, len(namespaceMap))
 	for namespace := range namespaceMap {
-		namespaces = append(namespaces, namespace)
+		if ns, ok := datascope.Visible(ctx, collectionName, namespace); ok {
+			namespaces = append(namespaces, ns)
+		}
 	}
 
 	return namespaces, nil
 }
 
-func getEmbedder(ctx context.Context, collectionName string, searchMethod string) (string, error) {
+// getEmbedder returns the embedder of text items in the namespace, for the search method of the collection.
+func getEmbedder(ctx context.Context, collectionName, namespace, searchMethod string) (string, error) {
 	manifestColl, ok := manifestdata.GetManifest().Collections[collectionName]
 	if !ok {
 		return "", fmt.Errorf("collection %s not found in manifest", collectionName)
@@ -615,7 +873,7 @@ func getEmbedder(ctx context.Context, collectionName string, searchMethod string
 		return "", fmt.Errorf("search method %s not found in collection %s", searchMethod, collectionName)
 	}
 
-	embedder := manifestSearchMethod.Embedder
+	embedder := manifestSearchMethod.EmbedderForNamespace(namespace)
 	if embedder == "" {
 		return "", fmt.Errorf("embedder not found in search method %s of collection %s", searchMethod, collectionName)
 	}
@@ -627,6 +885,21 @@ func getEmbedder(ctx context.Context, collectionName string, searchMethod string
 	return embedder, nil
 }
 
+// forNamespace returns the search method as it applies to items in the namespace.
+func forNamespace(searchMethod manifest.SearchMethodInfo, collNs interfaces.CollectionNamespace) manifest.SearchMethodInfo {
+	searchMethod.Embedder = searchMethod.EmbedderForNamespace(datascope.Unscoped(collNs.GetCollectionName(), collNs.GetNamespace()))
+	return searchMethod
+}
+
+// resolveNamespace returns the stored namespace that a call refers to, which is scoped to the plugin
+// that made the call if the manifest scopes data by plugin.
+func resolveNamespace(ctx context.Context, collectionName, namespace string) string {
+	if namespace == "" {
+		namespace = in_mem.DefaultNamespace
+	}
+	return datascope.Namespace(ctx, collectionName, namespace)
+}
+
 func validateEmbedder(ctx context.Context, embedder string) error {
 
 	info, err := wasmhost.GetWasmHost(ctx).GetFunctionInfo(embedder)
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/hypermodeinc/modus/runtime/collections/in_mem"
+	"github.com/hypermodeinc/modus/runtime/collections/index/interfaces"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+)
+
+// TextMemoryStats reports the memory used by the texts of a collection namespace.
+type TextMemoryStats struct {
+	Collection string `json:"collection"`
+	Namespace  string `json:"namespace"`
+	interfaces.TextMemoryStats
+}
+
+// newCollectionNamespace creates an in-memory collection namespace, which stores its texts
+// with the compression that the manifest sets for the collection.
+func newCollectionNamespace(ctx context.Context, collectionName, namespace string) *in_mem.InMemCollectionNamespace {
+	collNs := in_mem.NewCollectionNamespace(collectionName, namespace)
+	compression := string(manifestdata.GetManifest().Collections[collectionName].Compression)
+	if err := collNs.SetTextCompression(ctx, compression); err != nil {
+		logger.Err(ctx, err).
+			Str("collection_name", collectionName).
+			Str("namespace", namespace).
+			Msg("Failed to set text compression.")
+	}
+	return collNs
+}
+
+// applyTextCompression changes the compression of the texts of a collection namespace, re-encoding any texts
+// already stored, and logs the memory used before and after the change.
+func applyTextCompression(ctx context.Context, collNs interfaces.CollectionNamespace, compression string) {
+	before := collNs.GetTextMemoryStats(ctx)
+	if err := collNs.SetTextCompression(ctx, compression); err != nil {
+		logger.Err(ctx, err).
+			Str("collection_name", collNs.GetCollectionName()).
+			Str("namespace", collNs.GetNamespace()).
+			Msg("Failed to set text compression.")
+		return
+	}
+
+	after := collNs.GetTextMemoryStats(ctx)
+	if after.Compression == before.Compression {
+		return
+	}
+
+	logger.Info(ctx).
+		Str("collection_name", collNs.GetCollectionName()).
+		Str("namespace", collNs.GetNamespace()).
+		Str("compression", after.Compression).
+		Int("texts", after.Texts).
+		Int64("raw_bytes", after.RawBytes).
+		Int64("stored_bytes_before", before.StoredBytes).
+		Int64("stored_bytes_after", after.StoredBytes).
+		Msg("Changed text compression of collection namespace.")
+}
+
+// GetTextMemoryStats returns the memory used by the texts of each collection namespace.
+func GetTextMemoryStats(ctx context.Context) []*TextMemoryStats {
+	globalNamespaceManager.mu.RLock()
+	cols := make(map[string]*collection, len(globalNamespaceManager.collectionMap))
+	for name, col := range globalNamespaceManager.collectionMap {
+		if name != "" {
+			cols[name] = col
+		}
+	}
+	globalNamespaceManager.mu.RUnlock()
+
+	results := []*TextMemoryStats{}
+	for name, col := range cols {
+		for _, collNs := range col.getCollectionNamespaceMap() {
+			results = append(results, &TextMemoryStats{
+				Collection:      name,
+				Namespace:       collNs.GetNamespace(),
+				TextMemoryStats: collNs.GetTextMemoryStats(ctx),
+			})
+		}
+	}
+
+	slices.SortFunc(results, func(a, b *TextMemoryStats) int {
+		return cmp.Or(cmp.Compare(a.Collection, b.Collection), cmp.Compare(a.Namespace, b.Namespace))
+	})
+
+	return results
+}
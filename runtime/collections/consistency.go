@@ -0,0 +1,40 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// ContextWithReadYourWrites returns a context in which collection reads wait for any collection writes
+// already in progress within the same request, so that a read never observes a partially applied write.
+// Since writes complete before they return, fields that execute serially always observe earlier writes.
+func ContextWithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, utils.CollectionWritesContextKey, &sync.RWMutex{})
+}
+
+func lockForRead(ctx context.Context) func() {
+	if mu, ok := ctx.Value(utils.CollectionWritesContextKey).(*sync.RWMutex); ok {
+		mu.RLock()
+		return mu.RUnlock
+	}
+	return func() {}
+}
+
+func lockForWrite(ctx context.Context) func() {
+	if mu, ok := ctx.Value(utils.CollectionWritesContextKey).(*sync.RWMutex); ok {
+		mu.Lock()
+		return mu.Unlock
+	}
+	return func() {}
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"slices"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/collections/index/interfaces"
+	"github.com/hypermodeinc/modus/runtime/collections/utils"
+)
+
+// Namespaces with at most this many items are counted exactly.
+// Larger namespaces are estimated from a sample of this size.
+const exactCountLimit = 10_000
+
+// Count returns the number of active items in a collection namespace that have all of the given labels.
+// The count is exact for small namespaces, and estimated from a random sample for larger ones.
+func Count(ctx context.Context, collectionName, namespace string, labels []string) (*CollectionCountResult, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionRead); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
+		return nil, err
+	}
+	defer lockForRead(ctx)()
+
+	collNs, err := findNamespaceForCount(ctx, collectionName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	textMap, err := collNs.GetTextMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	labelsMap, err := collNs.GetLabelsMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := collNs.GetDeletedMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(textMap)
+	if total <= exactCountLimit {
+		var count int64
+		for key := range textMap {
+			if _, ok := deleted[key]; !ok && hasAllLabels(labelsMap[key], labels) {
+				count++
+			}
+		}
+		return NewCollectionCountResult(collectionName, namespace, "success", count, true, ""), nil
+	}
+
+	// Map iteration order is randomized, so the first items visited are a reasonable sample.
+	var sampled, matched int
+	for key := range textMap {
+		if sampled == exactCountLimit {
+			break
+		}
+		sampled++
+		if _, ok := deleted[key]; !ok && hasAllLabels(labelsMap[key], labels) {
+			matched++
+		}
+	}
+
+	count := int64(float64(matched) / float64(sampled) * float64(total))
+	return NewCollectionCountResult(collectionName, namespace, "success", count, false, ""), nil
+}
+
+// CountLabels returns the number of distinct labels used by active items in a collection namespace.
+// The count is exact for small namespaces, and estimated with a HyperLogLog sketch for larger ones,
+// so that memory use stays constant regardless of how many labels are in use.
+func CountLabels(ctx context.Context, collectionName, namespace string) (*CollectionCountResult, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionRead); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
+		return nil, err
+	}
+	defer lockForRead(ctx)()
+
+	collNs, err := findNamespaceForCount(ctx, collectionName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	labelsMap, err := collNs.GetLabelsMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := collNs.GetDeletedMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(labelsMap) <= exactCountLimit {
+		distinct := make(map[string]struct{})
+		for key, keyLabels := range labelsMap {
+			if _, ok := deleted[key]; ok {
+				continue
+			}
+			for _, label := range keyLabels {
+				distinct[label] = struct{}{}
+			}
+		}
+		return NewCollectionCountResult(collectionName, namespace, "success", int64(len(distinct)), true, ""), nil
+	}
+
+	hll := utils.NewHyperLogLog()
+	for key, keyLabels := range labelsMap {
+		if _, ok := deleted[key]; ok {
+			continue
+		}
+		for _, label := range keyLabels {
+			hll.Add(label)
+		}
+	}
+	return NewCollectionCountResult(collectionName, namespace, "success", int64(hll.Estimate()), false, ""), nil
+}
+
+func findNamespaceForCount(ctx context.Context, collectionName, namespace string) (interfaces.CollectionNamespace, error) {
+	col, err := globalNamespaceManager.findCollection(collectionName)
+	if err != nil {
+		return nil, err
+	}
+	return col.findNamespace(resolveNamespace(ctx, collectionName, namespace))
+}
+
+func hasAllLabels(keyLabels, required []string) bool {
+	for _, label := range required {
+		if !slices.Contains(keyLabels, label) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+// Diagnose checks that every function the manifest's collections depend on is exported by a loaded plugin
+// with the required signature, and returns a human-friendly message for each problem found.
+func Diagnose(ctx context.Context) []string {
+	host := wasmhost.GetWasmHost(ctx)
+	problems := []string{}
+
+	for collectionName, collection := range manifestdata.GetManifest().Collections {
+		for searchMethodName, searchMethod := range collection.SearchMethods {
+			if searchMethod.Embedder != "" {
+				if _, err := host.GetFunctionInfo(searchMethod.Embedder); err != nil {
+					problems = append(problems, fmt.Sprintf("function %s, required as the embedder for collection %s/searchMethod %s, is not exported by any loaded plugin",
+						searchMethod.Embedder, collectionName, searchMethodName))
+				} else if err := validateEmbedder(ctx, searchMethod.Embedder); err != nil {
+					problems = append(problems, fmt.Sprintf("function %s no longer matches the embedder signature required by collection %s/searchMethod %s",
+						searchMethod.Embedder, collectionName, searchMethodName))
+				}
+			}
+
+			for namespace, embedder := range searchMethod.NamespaceEmbedders {
+				if _, err := host.GetFunctionInfo(embedder); err != nil {
+					problems = append(problems, fmt.Sprintf("function %s, required as the embedder of namespace %s for collection %s/searchMethod %s, is not exported by any loaded plugin",
+						embedder, namespace, collectionName, searchMethodName))
+				} else if err := validateEmbedder(ctx, embedder); err != nil {
+					problems = append(problems, fmt.Sprintf("function %s no longer matches the embedder signature required by namespace %s of collection %s/searchMethod %s",
+						embedder, namespace, collectionName, searchMethodName))
+				}
+			}
+
+			if searchMethod.ImageEmbedder != "" {
+				if _, err := host.GetFunctionInfo(searchMethod.ImageEmbedder); err != nil {
+					problems = append(problems, fmt.Sprintf("function %s, required as the image embedder for collection %s/searchMethod %s, is not exported by any loaded plugin",
+						searchMethod.ImageEmbedder, collectionName, searchMethodName))
+				} else if err := validateImageEmbedder(ctx, searchMethod.ImageEmbedder); err != nil {
+					problems = append(problems, fmt.Sprintf("function %s no longer matches the image embedder signature required by collection %s/searchMethod %s",
+						searchMethod.ImageEmbedder, collectionName, searchMethodName))
+				}
+			}
+		}
+
+		for _, event := range []string{"upsert", "delete"} {
+			fn := triggerFunction(collection.Triggers, event)
+			if fn == "" {
+				continue
+			}
+			if _, err := host.GetFunctionInfo(fn); err != nil {
+				problems = append(problems, fmt.Sprintf("function %s, required as the %s trigger for collection %s, is not exported by any loaded plugin",
+					fn, event, collectionName))
+			}
+		}
+	}
+
+	// report in a stable order, regardless of map iteration order
+	slices.Sort(problems)
+	return problems
+}
+
+// reportDiagnostics logs any problems with the functions the manifest's collections depend on.
+// It is called in development whenever the functions are reloaded, so that mistakes are noticed immediately.
+func reportDiagnostics(ctx context.Context) {
+	problems := Diagnose(ctx)
+	for _, problem := range problems {
+		logger.Warn(ctx).
+			Str("detail", problem).
+			Bool("user_visible", true).
+			Msg("Collection configuration problem.")
+	}
+	if len(problems) > 0 {
+		logger.Warn(ctx).
+			Bool("user_visible", true).
+			Msgf("Found %d collection configuration problem(s).  Check the manifest and the plugin's exported functions.", len(problems))
+	}
+}
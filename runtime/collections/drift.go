@@ -0,0 +1,200 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"cmp"
+	"context"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/collections/index/interfaces"
+	"github.com/hypermodeinc/modus/runtime/collections/utils"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/metrics"
+)
+
+// DriftReport describes how far the stored vectors of a collection's search method
+// have drifted from the embeddings its current embedder produces for the same texts.
+type DriftReport struct {
+	Collection   string    `json:"collection"`
+	SearchMethod string    `json:"searchMethod"`
+	Embedder     string    `json:"embedder"`
+	Samples      int       `json:"samples"`
+	MeanDistance float64   `json:"meanDistance"`
+	MaxDistance  float64   `json:"maxDistance"`
+	CheckedAt    time.Time `json:"checkedAt"`
+}
+
+var driftReports = map[string]*DriftReport{}
+var driftMutex sync.RWMutex
+
+// GetDriftReports returns the results of the most recent drift check for each collection search method.
+func GetDriftReports() []*DriftReport {
+	driftMutex.RLock()
+	defer driftMutex.RUnlock()
+
+	reports := make([]*DriftReport, 0, len(driftReports))
+	for _, r := range driftReports {
+		reports = append(reports, r)
+	}
+	slices.SortFunc(reports, func(a, b *DriftReport) int {
+		if a.Collection != b.Collection {
+			return cmp.Compare(a.Collection, b.Collection)
+		}
+		return cmp.Compare(a.SearchMethod, b.SearchMethod)
+	})
+	return reports
+}
+
+func (cf *collectionFactory) driftMonitor(ctx context.Context) {
+	interval := config.DriftCheckInterval
+	if interval <= 0 || config.DriftSampleSize <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			cf.checkDrift(ctx)
+		case <-cf.quit:
+			return
+		}
+	}
+}
+
+// checkDrift samples stored items from each collection, re-embeds them with the current embedder,
+// and records the distance between the stored and re-computed vectors for each search method.
+func (cf *collectionFactory) checkDrift(ctx context.Context) {
+	reports := map[string]*DriftReport{}
+	for name, info := range manifestdata.GetManifest().Collections {
+		col, err := cf.findCollection(name)
+		if err != nil {
+			continue
+		}
+
+		for searchMethodName, searchMethod := range info.SearchMethods {
+			if searchMethod.Embedder == "" && len(searchMethod.NamespaceEmbedders) == 0 {
+				continue
+			}
+
+			report := &DriftReport{
+				Collection:   name,
+				SearchMethod: searchMethodName,
+				Embedder:     searchMethod.Embedder,
+			}
+
+			var total float64
+			for _, collNs := range col.getCollectionNamespaceMap() {
+				embedder := forNamespace(searchMethod, collNs).Embedder
+				if embedder == "" {
+					continue
+				}
+				distances, err := sampleDrift(ctx, collNs, searchMethodName, embedder, config.DriftSampleSize)
+				if err != nil {
+					logger.Warn(ctx).Err(err).
+						Str("collection_name", name).
+						Str("namespace", collNs.GetNamespace()).
+						Str("search_method", searchMethodName).
+						Msg("Failed to check embedding drift.")
+					continue
+				}
+				for _, d := range distances {
+					total += d
+					report.MaxDistance = max(report.MaxDistance, d)
+				}
+				report.Samples += len(distances)
+			}
+
+			if report.Samples == 0 {
+				continue
+			}
+
+			report.MeanDistance = total / float64(report.Samples)
+			report.CheckedAt = time.Now().UTC()
+			reports[name+"/"+searchMethodName] = report
+			metrics.EmbeddingDriftDistance.WithLabelValues(name, searchMethodName).Set(report.MeanDistance)
+		}
+	}
+
+	driftMutex.Lock()
+	defer driftMutex.Unlock()
+	driftReports = reports
+}
+
+func sampleDrift(ctx context.Context, collNs interfaces.CollectionNamespace, searchMethod, embedder string, sampleSize int) ([]float64, error) {
+	vectorIndex, ok := collNs.GetVectorIndexMap()[searchMethod]
+	if !ok {
+		return nil, nil
+	}
+
+	textMap, err := collNs.GetTextMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// only text items are sampled, since they are what the embedder is given
+	keys := make([]string, 0, len(textMap))
+	for key, text := range textMap {
+		if !collNs.IsDeleted(ctx, key) && !isBlob(text) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	rand.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+	keys = keys[:min(sampleSize, len(keys))]
+
+	texts := make([]string, len(keys))
+	for i, key := range keys {
+		texts[i] = textMap[key]
+	}
+
+	// bypass the embedding cache, so that the current embedder is what gets measured
+	vecs, err := callEmbedder(ctx, embedder, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	distances := make([]float64, 0, len(keys))
+	for i, key := range keys {
+		stored, err := vectorIndex.GetVector(ctx, key)
+		if err != nil || stored == nil {
+			continue
+		}
+		d, err := driftDistance(stored, vecs[i])
+		if err != nil {
+			return nil, err
+		}
+		distances = append(distances, d)
+	}
+
+	return distances, nil
+}
+
+func driftDistance(stored, current []float32) (float64, error) {
+	a, err := utils.Normalize(stored)
+	if err != nil {
+		return 0, err
+	}
+	b, err := utils.Normalize(current)
+	if err != nil {
+		return 0, err
+	}
+	return utils.CosineDistance(a, b)
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/collections/index"
+	"github.com/hypermodeinc/modus/runtime/collections/index/interfaces"
+	"github.com/hypermodeinc/modus/runtime/collections/utils"
+)
+
+const defaultDuplicateThreshold = 0.05
+
+// upsertBatch holds the items of an upsert after duplicate detection has been applied.
+// The vectors are those computed for the duplicate detection search method, aligned with keys.
+type upsertBatch struct {
+	keys    []string
+	texts   []string
+	labels  [][]string
+	vectors [][]float32
+}
+
+// detectDuplicates compares each incoming text against the nearest existing item in the namespace, and against
+// the other incoming texts, using the search method configured for duplicate detection, and applies the
+// configured policy.
+func detectDuplicates(ctx context.Context, collNs interfaces.CollectionNamespace, collectionData manifest.CollectionInfo, keys, texts []string, labels [][]string) (*upsertBatch, []*CollectionDuplicateObject, error) {
+	info := collectionData.DuplicateDetection
+
+	searchMethod, ok := collectionData.SearchMethods[info.SearchMethod]
+	if !ok {
+		return nil, nil, fmt.Errorf("search method %s for duplicate detection not found in collection %s", info.SearchMethod, collectionData.Name)
+	}
+
+	vecs, err := embedItems(ctx, collectionData.Name, info.SearchMethod, forNamespace(searchMethod, collNs), texts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vectorIndex, err := collNs.GetVectorIndex(ctx, info.SearchMethod)
+	if err == index.ErrVectorIndexNotFound {
+		// nothing has been indexed yet, so there is nothing to be a duplicate of
+		vectorIndex = nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	threshold := info.Threshold
+	if threshold <= 0 {
+		threshold = defaultDuplicateThreshold
+	}
+
+	nearest := func(i int) (string, float64, error) {
+		if vectorIndex == nil {
+			return "", 0, nil
+		}
+		nns, err := vectorIndex.Search(ctx, vecs[i], 1, func(_, _ []float32, k string) bool {
+			return k != keys[i] && !collNs.IsDeleted(ctx, k)
+		})
+		if err != nil || len(nns) == 0 || nns[0].GetValue() > threshold {
+			return "", 0, err
+		}
+		return nns[0].GetIndex(), nns[0].GetValue(), nil
+	}
+
+	return applyDuplicatePolicy(info.Policy, threshold, keys, texts, labels, vecs, nearest)
+}
+
+// applyDuplicatePolicy builds the batch to upsert from the incoming items and their vectors.  Each item is compared
+// with the nearest existing item, as returned by the nearest function, and with the items before it in the same
+// batch, which are not indexed yet.  When several items end up with the same key, the last one in the batch wins,
+// so the result doesn't depend on the order in which the items are written.
+func applyDuplicatePolicy(policy manifest.DuplicatePolicy, threshold float64, keys, texts []string, labels [][]string, vecs [][]float32, nearest func(i int) (string, float64, error)) (*upsertBatch, []*CollectionDuplicateObject, error) {
+	if policy == "" {
+		policy = manifest.DuplicatePolicySkip
+	}
+
+	batch := &upsertBatch{
+		keys:    make([]string, 0, len(keys)),
+		texts:   make([]string, 0, len(texts)),
+		vectors: make([][]float32, 0, len(vecs)),
+	}
+	if len(labels) != 0 {
+		batch.labels = make([][]string, 0, len(labels))
+	}
+	positions := make(map[string]int, len(keys))
+
+	duplicates := []*CollectionDuplicateObject{}
+	for i, key := range keys {
+		match, distance, err := nearest(i)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for j, k := range batch.keys {
+			if k == key {
+				continue
+			}
+			d, err := utils.CosineDistance(vecs[i], batch.vectors[j])
+			if err != nil {
+				return nil, nil, err
+			}
+			if d <= threshold && (match == "" || d < distance) {
+				match = k
+				distance = d
+			}
+		}
+
+		if match != "" {
+			duplicates = append(duplicates, NewCollectionDuplicateObject(key, match, distance, string(policy)))
+			switch policy {
+			case manifest.DuplicatePolicySkip:
+				continue
+			case manifest.DuplicatePolicyReplace:
+				key = match
+			case manifest.DuplicatePolicyFlag:
+			default:
+				return nil, nil, fmt.Errorf("unknown duplicate detection policy: %s", policy)
+			}
+		}
+
+		if j, ok := positions[key]; ok {
+			batch.texts[j] = texts[i]
+			batch.vectors[j] = vecs[i]
+			if len(labels) != 0 {
+				batch.labels[j] = labels[i]
+			}
+			continue
+		}
+
+		positions[key] = len(batch.keys)
+		batch.keys = append(batch.keys, key)
+		batch.texts = append(batch.texts, texts[i])
+		batch.vectors = append(batch.vectors, vecs[i])
+		if len(labels) != 0 {
+			batch.labels = append(batch.labels, labels[i])
+		}
+	}
+
+	return batch, duplicates, nil
+}
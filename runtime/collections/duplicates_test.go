@@ -0,0 +1,174 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyDuplicatePolicy(t *testing.T) {
+	// unit vectors, so that the cosine distance between equal vectors is 0 and between orthogonal vectors is 1
+	x := []float32{1, 0, 0}
+	y := []float32{0, 1, 0}
+	z := []float32{0, 0, 1}
+
+	// the nearest existing item of any vector equal to x is "existing"
+	nearest := func(vecs [][]float32) func(i int) (string, float64, error) {
+		return func(i int) (string, float64, error) {
+			if vecs[i][0] == 1 {
+				return "existing", 0.01, nil
+			}
+			return "", 0, nil
+		}
+	}
+
+	tests := []struct {
+		name       string
+		policy     manifest.DuplicatePolicy
+		keys       []string
+		vecs       [][]float32
+		wantKeys   []string
+		wantTexts  []string
+		wantLabels [][]string
+		wantDups   [][2]string
+		wantErr    string
+	}{
+		{
+			name:       "no duplicates",
+			policy:     manifest.DuplicatePolicySkip,
+			keys:       []string{"a", "b"},
+			vecs:       [][]float32{y, z},
+			wantKeys:   []string{"a", "b"},
+			wantTexts:  []string{"text 0", "text 1"},
+			wantLabels: [][]string{{"label 0"}, {"label 1"}},
+			wantDups:   [][2]string{},
+		},
+		{
+			name:       "skip existing duplicate",
+			policy:     manifest.DuplicatePolicySkip,
+			keys:       []string{"a", "b"},
+			vecs:       [][]float32{x, y},
+			wantKeys:   []string{"b"},
+			wantTexts:  []string{"text 1"},
+			wantLabels: [][]string{{"label 1"}},
+			wantDups:   [][2]string{{"a", "existing"}},
+		},
+		{
+			name:       "skip is the default policy",
+			keys:       []string{"a"},
+			vecs:       [][]float32{x},
+			wantKeys:   []string{},
+			wantTexts:  []string{},
+			wantLabels: [][]string{},
+			wantDups:   [][2]string{{"a", "existing"}},
+		},
+		{
+			name:       "skip duplicate within the batch",
+			policy:     manifest.DuplicatePolicySkip,
+			keys:       []string{"a", "b", "c"},
+			vecs:       [][]float32{y, z, y},
+			wantKeys:   []string{"a", "b"},
+			wantTexts:  []string{"text 0", "text 1"},
+			wantLabels: [][]string{{"label 0"}, {"label 1"}},
+			wantDups:   [][2]string{{"c", "a"}},
+		},
+		{
+			name:       "replace existing duplicate",
+			policy:     manifest.DuplicatePolicyReplace,
+			keys:       []string{"a", "b"},
+			vecs:       [][]float32{x, y},
+			wantKeys:   []string{"existing", "b"},
+			wantTexts:  []string{"text 0", "text 1"},
+			wantLabels: [][]string{{"label 0"}, {"label 1"}},
+			wantDups:   [][2]string{{"a", "existing"}},
+		},
+		{
+			name:       "replace keeps the last item for the same existing duplicate",
+			policy:     manifest.DuplicatePolicyReplace,
+			keys:       []string{"a", "b", "c"},
+			vecs:       [][]float32{x, y, x},
+			wantKeys:   []string{"existing", "b"},
+			wantTexts:  []string{"text 2", "text 1"},
+			wantLabels: [][]string{{"label 2"}, {"label 1"}},
+			wantDups:   [][2]string{{"a", "existing"}, {"c", "existing"}},
+		},
+		{
+			name:       "replace duplicate within the batch",
+			policy:     manifest.DuplicatePolicyReplace,
+			keys:       []string{"a", "b", "c"},
+			vecs:       [][]float32{y, z, y},
+			wantKeys:   []string{"a", "b"},
+			wantTexts:  []string{"text 2", "text 1"},
+			wantLabels: [][]string{{"label 2"}, {"label 1"}},
+			wantDups:   [][2]string{{"c", "a"}},
+		},
+		{
+			name:       "same key in the batch keeps the last item",
+			policy:     manifest.DuplicatePolicySkip,
+			keys:       []string{"a", "a"},
+			vecs:       [][]float32{y, z},
+			wantKeys:   []string{"a"},
+			wantTexts:  []string{"text 1"},
+			wantLabels: [][]string{{"label 1"}},
+			wantDups:   [][2]string{},
+		},
+		{
+			name:       "flag keeps duplicates",
+			policy:     manifest.DuplicatePolicyFlag,
+			keys:       []string{"a", "b", "c"},
+			vecs:       [][]float32{x, y, y},
+			wantKeys:   []string{"a", "b", "c"},
+			wantTexts:  []string{"text 0", "text 1", "text 2"},
+			wantLabels: [][]string{{"label 0"}, {"label 1"}, {"label 2"}},
+			wantDups:   [][2]string{{"a", "existing"}, {"c", "b"}},
+		},
+		{
+			name:    "unknown policy",
+			policy:  "merge",
+			keys:    []string{"a"},
+			vecs:    [][]float32{x},
+			wantErr: "unknown duplicate detection policy: merge",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			texts := make([]string, len(tt.keys))
+			labels := make([][]string, len(tt.keys))
+			for i := range tt.keys {
+				texts[i] = "text " + string(rune('0'+i))
+				labels[i] = []string{"label " + string(rune('0'+i))}
+			}
+
+			batch, dups, err := applyDuplicatePolicy(tt.policy, defaultDuplicateThreshold, tt.keys, texts, labels, tt.vecs, nearest(tt.vecs))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantKeys, batch.keys)
+			assert.Equal(t, tt.wantTexts, batch.texts)
+			assert.Equal(t, tt.wantLabels, batch.labels)
+			require.Len(t, batch.vectors, len(tt.wantKeys))
+
+			gotDups := make([][2]string, len(dups))
+			for i, d := range dups {
+				gotDups[i] = [2]string{d.Key, d.DuplicateOf}
+			}
+			assert.Equal(t, tt.wantDups, gotDups)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/hypermodeinc/modus/runtime/metrics"
+)
+
+type embeddingCacheKey struct {
+	embedder string
+	textHash [sha256.Size]byte
+}
+
+type embeddingCacheEntry struct {
+	key    embeddingCacheKey
+	vector []float32
+}
+
+// embeddingCache is a size-bounded LRU cache of vectors produced by embedder functions,
+// so that identical texts are not sent to the embedder more than once.
+type embeddingCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[embeddingCacheKey]*list.Element
+	order    *list.List
+}
+
+var globalEmbeddingCache = newEmbeddingCache(0)
+
+func newEmbeddingCache(capacity int) *embeddingCache {
+	return &embeddingCache{
+		capacity: capacity,
+		items:    make(map[embeddingCacheKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func newEmbeddingCacheKey(embedder, text string) embeddingCacheKey {
+	return embeddingCacheKey{
+		embedder: embedder,
+		textHash: sha256.Sum256([]byte(text)),
+	}
+}
+
+func (c *embeddingCache) get(embedder, text string) ([]float32, bool) {
+	if c.capacity <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[newEmbeddingCacheKey(embedder, text)]
+	if !ok {
+		metrics.EmbeddingCacheMissesNum.Inc()
+		return nil, false
+	}
+
+	metrics.EmbeddingCacheHitsNum.Inc()
+	c.order.MoveToFront(el)
+	return el.Value.(*embeddingCacheEntry).vector, true
+}
+
+func (c *embeddingCache) put(embedder, text string, vector []float32) {
+	if c.capacity <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := newEmbeddingCacheKey(embedder, text)
+	if el, ok := c.items[key]; ok {
+		el.Value.(*embeddingCacheEntry).vector = vector
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&embeddingCacheEntry{key: key, vector: vector})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*embeddingCacheEntry).key)
+	}
+}
+
+// reset discards all cached vectors and applies the given capacity.
+func (c *embeddingCache) reset(capacity int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.capacity = capacity
+	c.items = make(map[embeddingCacheKey]*list.Element)
+	c.order.Init()
+}
+
+// forget discards the cached vectors of a text, for every embedder.
+func (c *embeddingCache) forget(text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	textHash := sha256.Sum256([]byte(text))
+	for key, el := range c.items {
+		if key.textHash == textHash {
+			c.order.Remove(el)
+			delete(c.items, key)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbeddingCacheEviction(t *testing.T) {
+	c := newEmbeddingCache(2)
+
+	c.put("embedder", "a", []float32{1})
+	c.put("embedder", "b", []float32{2})
+
+	// touch "a" so that "b" becomes the least recently used
+	_, ok := c.get("embedder", "a")
+	assert.True(t, ok)
+
+	c.put("embedder", "c", []float32{3})
+
+	_, ok = c.get("embedder", "b")
+	assert.False(t, ok)
+
+	vec, ok := c.get("embedder", "a")
+	assert.True(t, ok)
+	assert.Equal(t, []float32{1}, vec)
+
+	// the same text for a different embedder is a different entry
+	_, ok = c.get("other", "a")
+	assert.False(t, ok)
+
+	c.reset(2)
+	_, ok = c.get("embedder", "a")
+	assert.False(t, ok)
+}
+
+func TestEmbeddingCacheDisabled(t *testing.T) {
+	c := newEmbeddingCache(0)
+	c.put("embedder", "a", []float32{1})
+
+	_, ok := c.get("embedder", "a")
+	assert.False(t, ok)
+}
+
+func TestEmbeddingCacheForget(t *testing.T) {
+	c := newEmbeddingCache(4)
+	c.put("embedder", "a", []float32{1})
+	c.put("other", "a", []float32{2})
+	c.put("embedder", "b", []float32{3})
+
+	c.forget("a")
+
+	_, ok := c.get("embedder", "a")
+	assert.False(t, ok)
+	_, ok = c.get("other", "a")
+	assert.False(t, ok)
+	_, ok = c.get("embedder", "b")
+	assert.True(t, ok)
+	assert.Equal(t, 1, c.order.Len())
+}
@@ -13,6 +13,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
@@ -33,6 +35,7 @@ var (
 type collectionFactory struct {
 	collectionMap map[string]*collection
 	mu            sync.RWMutex
+	loadMu        sync.Mutex
 	quit          chan struct{}
 	done          chan struct{}
 }
@@ -83,11 +86,23 @@ func (cf *collectionFactory) removeCollection(name string) error {
 	return nil
 }
 
-func (cf *collectionFactory) readFromPostgres(ctx context.Context) bool {
+// readFromPostgres loads the texts and vectors added since the last time they were read, into memory.
+// The first time, this restores every collection, which reports its progress to the restore tracker.
+func (cf *collectionFactory) readFromPostgres(ctx context.Context, restore *restoreTracker) bool {
+	cf.loadMu.Lock()
+	defer cf.loadMu.Unlock()
+
+	cf.mu.RLock()
+	collectionMap := maps.Clone(cf.collectionMap)
+	cf.mu.RUnlock()
+
 	resetTimerFaster := false
 	var err error
-	for _, namespaceCollectionFactory := range cf.collectionMap {
+	// collections are read in order of name, so that restoration progresses predictably
+	for _, name := range slices.Sorted(maps.Keys(collectionMap)) {
+		namespaceCollectionFactory := collectionMap[name]
 		for _, col := range namespaceCollectionFactory.getCollectionNamespaceMap() {
+			restore.startNamespace(name, col.GetNamespace())
 			resetTimerFaster, err = loadTextsIntoCollection(ctx, col)
 			if err != nil {
 				logger.Err(ctx, err).
@@ -117,7 +132,9 @@ func (cf *collectionFactory) readFromPostgres(ctx context.Context) bool {
 				}
 
 			}
+			restore.finishNamespace(ctx, col)
 		}
+		restore.finishCollection(name)
 	}
 	return resetTimerFaster
 }
@@ -131,7 +148,8 @@ func (cf *collectionFactory) worker(ctx context.Context) {
 		select {
 		case <-timer.C:
 			// read from postgres all collections & searchMethod after lastInsertedID
-			resetTimerFaster := cf.readFromPostgres(ctx)
+			resetTimerFaster := cf.readFromPostgres(ctx, nil)
+			cf.purgeExpiredTexts(ctx)
 			if resetTimerFaster {
 				timer.Reset(10 * time.Second)
 			} else {
@@ -169,6 +187,16 @@ func loadTextsIntoCollection(ctx context.Context, col interfaces.CollectionNames
 		return false, err
 	}
 
+	// Mark any soft-deleted texts
+	deleted, err := db.QueryDeletedCollectionTexts(ctx, col.GetCollectionName(), col.GetNamespace())
+	if err != nil {
+		return false, err
+	}
+	err = col.SetDeletedMapInMemory(ctx, deleted)
+	if err != nil {
+		return false, err
+	}
+
 	return false, nil
 }
 
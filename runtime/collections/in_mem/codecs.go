@@ -0,0 +1,152 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package in_mem
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// TextCodec compresses the texts of a collection namespace that are held in memory.
+// Texts are decompressed when they are read, so a codec must be safe for concurrent use.
+// Both methods append their output to dst and return the extended slice.
+type TextCodec interface {
+	Compress(dst, src []byte) []byte
+	Decompress(dst, src []byte) ([]byte, error)
+}
+
+var textCodecs = map[string]TextCodec{
+	"zstd":   zstdCodec{},
+	"snappy": snappyCodec{},
+}
+var textCodecsMutex sync.RWMutex
+
+// RegisterTextCodec makes a codec available by name, for collections to compress their texts with.
+func RegisterTextCodec(name string, codec TextCodec) {
+	textCodecsMutex.Lock()
+	defer textCodecsMutex.Unlock()
+	textCodecs[name] = codec
+}
+
+func getTextCodec(name string) (TextCodec, error) {
+	if name == "" || name == "none" {
+		return nil, nil
+	}
+
+	textCodecsMutex.RLock()
+	defer textCodecsMutex.RUnlock()
+	if codec, ok := textCodecs[name]; ok {
+		return codec, nil
+	}
+	return nil, fmt.Errorf("unknown text compression %s", name)
+}
+
+var getZstdEncoder = sync.OnceValue(func() *zstd.Encoder {
+	enc, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	return enc
+})
+
+var getZstdDecoder = sync.OnceValue(func() *zstd.Decoder {
+	dec, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+	return dec
+})
+
+type zstdCodec struct{}
+
+func (zstdCodec) Compress(dst, src []byte) []byte {
+	return getZstdEncoder().EncodeAll(src, dst)
+}
+
+func (zstdCodec) Decompress(dst, src []byte) ([]byte, error) {
+	return getZstdDecoder().DecodeAll(src, dst)
+}
+
+type snappyCodec struct{}
+
+// the s2 functions use dst as a scratch buffer rather than appending to it
+
+func (snappyCodec) Compress(dst, src []byte) []byte {
+	return append(dst, s2.EncodeSnappy(nil, src)...)
+}
+
+func (snappyCodec) Decompress(dst, src []byte) ([]byte, error) {
+	buf, err := s2.Decode(nil, src)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, buf...), nil
+}
+
+// When a namespace has a codec, each text is stored with a header byte that says whether it is compressed.
+// Texts that don't get smaller are stored as they are.  A compressed text's header is followed by its
+// original length, so that memory usage can be reported without decompressing it.
+const (
+	storedRaw        byte = 0
+	storedCompressed byte = 1
+)
+
+func encodeText(codec TextCodec, text string) string {
+	if codec == nil {
+		return text
+	}
+
+	buf := make([]byte, 1, 1+binary.MaxVarintLen64)
+	buf[0] = storedCompressed
+	buf = binary.AppendUvarint(buf, uint64(len(text)))
+	buf = codec.Compress(buf, []byte(text))
+	if len(buf) < len(text)+1 {
+		return string(buf)
+	}
+	return string(storedRaw) + text
+}
+
+func decodeText(codec TextCodec, stored string) (string, error) {
+	if codec == nil {
+		return stored, nil
+	}
+	if stored == "" {
+		return "", errors.New("stored text has no header")
+	}
+
+	switch stored[0] {
+	case storedRaw:
+		return stored[1:], nil
+	case storedCompressed:
+		size, n := binary.Uvarint([]byte(stored[1:min(len(stored), 1+binary.MaxVarintLen64)]))
+		if n <= 0 {
+			return "", errors.New("stored text has an invalid length")
+		}
+		buf, err := codec.Decompress(make([]byte, 0, size), []byte(stored[1+n:]))
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress text: %w", err)
+		}
+		return string(buf), nil
+	}
+	return "", errors.New("stored text has an invalid header")
+}
+
+// decodedLen returns the length of a stored text before it was compressed.
+func decodedLen(codec TextCodec, stored string) int {
+	if codec == nil || stored == "" {
+		return len(stored)
+	}
+	if stored[0] == storedCompressed {
+		size, n := binary.Uvarint([]byte(stored[1:min(len(stored), 1+binary.MaxVarintLen64)]))
+		if n > 0 {
+			return int(size)
+		}
+	}
+	return len(stored) - 1
+}
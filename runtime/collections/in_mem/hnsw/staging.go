@@ -0,0 +1,227 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hnsw
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/collections/index"
+	"github.com/hypermodeinc/modus/runtime/collections/utils"
+	"github.com/hypermodeinc/modus/runtime/hnsw"
+	"github.com/hypermodeinc/modus/runtime/logger"
+)
+
+const (
+	defaultMergeBudget    = 0.25
+	defaultMergeBatchSize = 256
+)
+
+// stagingBuffer holds newly inserted vectors that have not yet been merged into the graph.
+// Staged vectors are searched by brute force, so they are visible to searches immediately.
+type stagingBuffer struct {
+	vectors   map[string][]float32
+	order     []string
+	budget    float64
+	batchSize int
+	merging   bool
+}
+
+// EnableStaging makes the index stage inserted vectors in a brute-force buffer, instead of adding them to the graph
+// directly.  A background worker merges staged vectors into the graph in batches, using at most the given fraction
+// of a CPU core.  Zero values select the defaults.
+func (ims *HnswVectorIndex) EnableStaging(budget float64, batchSize int) {
+	if budget <= 0 || budget > 1 {
+		budget = defaultMergeBudget
+	}
+	if batchSize <= 0 {
+		batchSize = defaultMergeBatchSize
+	}
+
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+	if ims.staging == nil {
+		ims.staging = &stagingBuffer{vectors: make(map[string][]float32)}
+	}
+	ims.staging.budget = budget
+	ims.staging.batchSize = batchSize
+}
+
+// StagedCount returns the number of vectors waiting to be merged into the graph.
+func (ims *HnswVectorIndex) StagedCount() int {
+	ims.mu.RLock()
+	defer ims.mu.RUnlock()
+	if ims.staging == nil {
+		return 0
+	}
+	return len(ims.staging.vectors)
+}
+
+// stage adds vectors to the staging buffer, and starts the merge worker if it isn't running.
+// The caller must hold the write lock.
+func (ims *HnswVectorIndex) stage(keys []string, vecs [][]float32) error {
+	if len(keys) != len(vecs) {
+		return fmt.Errorf("keys and vecs must have the same length")
+	}
+
+	// validate up front, since errors from the merge worker can't be returned to the caller
+	dims := ims.HnswIndex.Dims()
+	for _, vec := range vecs {
+		if dims == 0 {
+			dims = ims.stagedDims()
+		}
+		if dims == 0 {
+			dims = len(vec)
+		}
+		if len(vec) != dims {
+			return fmt.Errorf("vector dimension mismatch: %d != %d", len(vec), dims)
+		}
+	}
+
+	s := ims.staging
+	for i, key := range keys {
+		if _, found := s.vectors[key]; !found {
+			s.order = append(s.order, key)
+		}
+		s.vectors[key] = vecs[i]
+	}
+
+	if !s.merging && len(s.order) > 0 {
+		s.merging = true
+		go ims.mergeStaged()
+	}
+	return nil
+}
+
+// stagedDims returns the dimensions of the staged vectors, or zero if none are staged.
+// The caller must hold a lock.
+func (ims *HnswVectorIndex) stagedDims() int {
+	if ims.staging != nil {
+		for _, vec := range ims.staging.vectors {
+			return len(vec)
+		}
+	}
+	return 0
+}
+
+// unstage removes a vector from the staging buffer.  The caller must hold the write lock.
+func (ims *HnswVectorIndex) unstage(key string) {
+	if ims.staging == nil {
+		return
+	}
+	// the key is left in the order list, and skipped when merging
+	delete(ims.staging.vectors, key)
+}
+
+// lookup returns the vector for the key, preferring a staged vector over the one in the graph.
+// The caller must hold a lock.
+func (ims *HnswVectorIndex) lookup(key string) ([]float32, bool) {
+	if ims.staging != nil {
+		if vec, found := ims.staging.vectors[key]; found {
+			return vec, true
+		}
+	}
+	return ims.HnswIndex.Lookup(key)
+}
+
+// isStaged reports whether the key has a staged vector, which supersedes any vector for the key in the graph.
+// The caller must hold a lock.
+func (ims *HnswVectorIndex) isStaged(key string) bool {
+	if ims.staging == nil {
+		return false
+	}
+	_, found := ims.staging.vectors[key]
+	return found
+}
+
+// searchStaged compares the query with every staged vector, and pushes the closest ones onto the results heap.
+// The caller must hold a lock.
+func (ims *HnswVectorIndex) searchStaged(query []float32, maxResults int, filter index.SearchFilter, results *utils.MaxTupleHeap) error {
+	if ims.staging == nil {
+		return nil
+	}
+	for key, vector := range ims.staging.vectors {
+		if filter != nil && !filter(query, vector, key) {
+			continue
+		}
+		distance, err := utils.CosineDistance(query, vector)
+		if err != nil {
+			return err
+		}
+		if results.Len() < maxResults {
+			heap.Push(results, utils.InitHeapElement(distance, key, false))
+		} else if utils.IsBetterScoreForDistance(distance, (*results)[0].GetValue()) {
+			heap.Pop(results)
+			heap.Push(results, utils.InitHeapElement(distance, key, false))
+		}
+	}
+	return nil
+}
+
+// mergeStaged moves staged vectors into the graph one batch at a time, until the buffer is empty.
+// After each batch it sleeps long enough to keep its share of CPU time within the budget,
+// and it holds the write lock only while a batch is being added, so searches can proceed in between.
+func (ims *HnswVectorIndex) mergeStaged() {
+	for {
+		start := time.Now()
+		done, err := ims.mergeBatch()
+		if err != nil {
+			logger.Error(context.Background()).Err(err).
+				Str("search_method", ims.searchMethodName).
+				Msg("Failed to merge staged vectors into the index.")
+		}
+		if done {
+			return
+		}
+
+		ims.mu.RLock()
+		budget := ims.staging.budget
+		ims.mu.RUnlock()
+
+		elapsed := time.Since(start)
+		time.Sleep(time.Duration(float64(elapsed) * (1 - budget) / budget))
+	}
+}
+
+// mergeBatch adds the next batch of staged vectors to the graph.
+// It returns true when the buffer is empty and the worker has stopped.
+func (ims *HnswVectorIndex) mergeBatch() (bool, error) {
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	s := ims.staging
+	n := min(s.batchSize, len(s.order))
+	nodes := make([]hnsw.Node[string], 0, n)
+	for _, key := range s.order[:n] {
+		if vec, found := s.vectors[key]; found {
+			nodes = append(nodes, hnsw.MakeNode(key, vec))
+		}
+	}
+	s.order = s.order[n:]
+
+	var err error
+	if len(nodes) > 0 {
+		err = ims.HnswIndex.Add(nodes...)
+	}
+
+	// on failure the batch is dropped from the buffer, as it would be with a direct insert
+	for _, node := range nodes {
+		delete(s.vectors, node.Key)
+	}
+
+	if len(s.order) == 0 {
+		s.order = nil
+		s.merging = false
+		return true, err
+	}
+	return false, err
+}
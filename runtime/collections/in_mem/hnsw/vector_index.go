@@ -32,6 +32,7 @@ type HnswVectorIndex struct {
 	embedderName      string
 	lastInsertedID    int64
 	lastIndexedTextID int64
+	staging           *stagingBuffer
 	HnswIndex         *hnsw.Graph[string]
 }
 
@@ -71,20 +72,36 @@ func (ims *HnswVectorIndex) Search(ctx context.Context, query []float32, maxResu
 	if ims.HnswIndex == nil {
 		return nil, fmt.Errorf("vector index is not initialized")
 	}
-	neighbors, err := ims.HnswIndex.Search(query, maxResults)
-	if err != nil {
-		return nil, err
-	}
-	keys, distances := make([]string, len(neighbors)), make([]float64, len(neighbors))
-	for i, neighbor := range neighbors {
-		keys[i] = string(neighbor.Key)
-		distances[i] = float64(neighbor.Distance)
+	var neighbors []hnsw.SearchResultNode[string]
+	if ims.HnswIndex.Len() > 0 {
+		// the graph may still be empty while all vectors are staged
+		var err error
+		neighbors, err = ims.HnswIndex.Search(query, maxResults)
+		if err != nil {
+			return nil, err
+		}
 	}
 	var results utils.MaxTupleHeap
 	heap.Init(&results)
 
-	for i, key := range keys {
-		heap.Push(&results, utils.InitHeapElement(distances[i], key, false))
+	for _, neighbor := range neighbors {
+		key := string(neighbor.Key)
+		if ims.isStaged(key) {
+			// superseded by a newer vector that hasn't been merged yet
+			continue
+		}
+		if filter != nil && !filter(query, neighbor.Value, key) {
+			continue
+		}
+		heap.Push(&results, utils.InitHeapElement(float64(neighbor.Distance), key, false))
+	}
+
+	// vectors that haven't been merged into the graph yet are compared directly
+	if err := ims.searchStaged(query, maxResults, filter, &results); err != nil {
+		return nil, err
+	}
+	for results.Len() > maxResults {
+		heap.Pop(&results)
 	}
 
 	// Return top maxResults results
@@ -100,10 +117,74 @@ func (ims *HnswVectorIndex) Search(ctx context.Context, query []float32, maxResu
 	return finalResults, nil
 }
 
+// ExactSearch finds the nearest neighbors of the query by comparing it with every vector in the index.
+// It is much slower than Search, and is used to estimate the recall of Search.
+func (ims *HnswVectorIndex) ExactSearch(ctx context.Context, query []float32, maxResults int, filter index.SearchFilter) (utils.MaxTupleHeap, error) {
+	ims.mu.RLock()
+	defer ims.mu.RUnlock()
+	if ims.HnswIndex == nil {
+		return nil, fmt.Errorf("vector index is not initialized")
+	}
+	if maxResults <= 0 {
+		maxResults = 1
+	}
+
+	var results utils.MaxTupleHeap
+	heap.Init(&results)
+	var loopErr error
+	ims.HnswIndex.Each(func(key string, vector []float32) bool {
+		if ims.isStaged(key) {
+			return true
+		}
+		if filter != nil && !filter(query, vector, key) {
+			return true
+		}
+		distance, err := utils.CosineDistance(query, vector)
+		if err != nil {
+			loopErr = err
+			return false
+		}
+		if results.Len() < maxResults {
+			heap.Push(&results, utils.InitHeapElement(distance, key, false))
+		} else if utils.IsBetterScoreForDistance(distance, results[0].GetValue()) {
+			heap.Pop(&results)
+			heap.Push(&results, utils.InitHeapElement(distance, key, false))
+		}
+		return true
+	})
+	if loopErr != nil {
+		return nil, loopErr
+	}
+	if err := ims.searchStaged(query, maxResults, filter, &results); err != nil {
+		return nil, err
+	}
+
+	var finalResults utils.MaxTupleHeap
+	for results.Len() > 0 {
+		finalResults = append(finalResults, heap.Pop(&results).(utils.MaxHeapElement))
+	}
+	slices.Reverse(finalResults)
+	return finalResults, nil
+}
+
+// EfSearch returns the number of candidates evaluated during search.
+func (ims *HnswVectorIndex) EfSearch() int {
+	ims.mu.RLock()
+	defer ims.mu.RUnlock()
+	return ims.HnswIndex.EfSearch
+}
+
+// SetEfSearch sets the number of candidates evaluated during search.
+func (ims *HnswVectorIndex) SetEfSearch(ef int) {
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+	ims.HnswIndex.EfSearch = ef
+}
+
 func (ims *HnswVectorIndex) SearchWithKey(ctx context.Context, queryKey string, maxResults int, filter index.SearchFilter) (utils.MaxTupleHeap, error) {
 	ims.mu.RLock()
 	defer ims.mu.RUnlock()
-	query, found := ims.HnswIndex.Lookup(queryKey)
+	query, found := ims.lookup(queryKey)
 	if !found {
 		return nil, fmt.Errorf("key not found")
 	}
@@ -140,6 +221,15 @@ func (ims *HnswVectorIndex) InsertVector(ctx context.Context, textId int64, vec
 }
 
 func (ims *HnswVectorIndex) InsertVectorsToMemory(ctx context.Context, textIds []int64, vectorIds []int64, keys []string, vecs [][]float32) error {
+	if ims.staging != nil {
+		if err := ims.stage(keys, vecs); err != nil {
+			return err
+		}
+		ims.lastInsertedID = slices.Max(vectorIds)
+		ims.lastIndexedTextID = slices.Max(textIds)
+		return nil
+	}
+
 	nodes, err := hnsw.MakeNodes(keys, vecs)
 	if err != nil {
 		return err
@@ -156,7 +246,12 @@ func (ims *HnswVectorIndex) InsertVectorsToMemory(ctx context.Context, textIds [
 func (ims *HnswVectorIndex) InsertVectorToMemory(ctx context.Context, textId, vectorId int64, key string, vec []float32) error {
 	ims.mu.Lock()
 	defer ims.mu.Unlock()
-	err := ims.HnswIndex.Add(hnsw.MakeNode(key, vec))
+	var err error
+	if ims.staging != nil {
+		err = ims.stage([]string{key}, [][]float32{vec})
+	} else {
+		err = ims.HnswIndex.Add(hnsw.MakeNode(key, vec))
+	}
 	if err != nil {
 		return err
 	}
@@ -172,6 +267,7 @@ func (ims *HnswVectorIndex) DeleteVector(ctx context.Context, textId int64, key
 	if err != nil {
 		return err
 	}
+	ims.unstage(key)
 	ims.HnswIndex.Delete(key)
 	return nil
 }
@@ -179,10 +275,19 @@ func (ims *HnswVectorIndex) DeleteVector(ctx context.Context, textId int64, key
 func (ims *HnswVectorIndex) GetVector(ctx context.Context, key string) ([]float32, error) {
 	ims.mu.RLock()
 	defer ims.mu.RUnlock()
-	vec, _ := ims.HnswIndex.Lookup(key)
+	vec, _ := ims.lookup(key)
 	return vec, nil
 }
 
+func (ims *HnswVectorIndex) GetDimensions(ctx context.Context) (int, error) {
+	ims.mu.RLock()
+	defer ims.mu.RUnlock()
+	if dims := ims.HnswIndex.Dims(); dims > 0 {
+		return dims, nil
+	}
+	return ims.stagedDims(), nil
+}
+
 func (ims *HnswVectorIndex) GetCheckpointId(ctx context.Context) (int64, error) {
 	ims.mu.RLock()
 	defer ims.mu.RUnlock()
@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/hypermodeinc/modus/runtime/collections/utils"
 )
@@ -115,3 +116,76 @@ func TestMultipleSequentialVectorIndexes(t *testing.T) {
 	// Wait for all goroutines to finish
 	wg.Wait()
 }
+
+func TestStagedVectors(t *testing.T) {
+	ctx := context.Background()
+
+	index := NewHnswVectorIndex("searchMethod", "embedder")
+	index.EnableStaging(1, 2)
+
+	keys := []string{"key1", "key2", "key3", "key4", "key5"}
+	textIds := []int64{1, 2, 3, 4, 5}
+	vecs := make([][]float32, len(keys))
+	for i := range keys {
+		vec, err := utils.Normalize([]float32{float32(i + 1), 1, float32(5 - i)})
+		if err != nil {
+			t.Fatalf("Failed to normalize vector: %v", err)
+		}
+		vecs[i] = vec
+	}
+
+	// hold the lock so the merge worker can't run until we've searched the staged vectors
+	index.mu.Lock()
+	if err := index.InsertVectorsToMemory(ctx, textIds, textIds, keys, vecs); err != nil {
+		index.mu.Unlock()
+		t.Fatalf("Failed to insert vectors into index: %v", err)
+	}
+	if n := len(index.staging.vectors); n != len(keys) {
+		t.Errorf("Expected %d staged vectors, got %d", len(keys), n)
+	}
+	if n := index.HnswIndex.Len(); n != 0 {
+		t.Errorf("Expected no vectors in the graph, got %d", n)
+	}
+	index.mu.Unlock()
+
+	// staged vectors are searchable, and filtered, before they are merged
+	excludeKey1 := func(query, vec []float32, key string) bool { return key != "key1" }
+	objs, err := index.Search(ctx, vecs[0], 2, excludeKey1)
+	if err != nil {
+		t.Fatalf("Failed to search index: %v", err)
+	}
+	if len(objs) != 2 || objs[0].GetIndex() != "key2" {
+		t.Errorf("Expected key2 as the closest of 2 results, got %v", objs)
+	}
+
+	dims, err := index.GetDimensions(ctx)
+	if err != nil || dims != 3 {
+		t.Errorf("Expected 3 dimensions, got %d (%v)", dims, err)
+	}
+
+	if err := index.InsertVectorsToMemory(ctx, []int64{6}, []int64{6}, []string{"bad"}, [][]float32{{1, 0}}); err == nil {
+		t.Errorf("Expected an error when staging a vector with the wrong dimensions")
+	}
+
+	// wait for the background worker to merge everything
+	deadline := time.Now().Add(5 * time.Second)
+	for index.StagedCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := index.StagedCount(); n != 0 {
+		t.Fatalf("Expected all vectors to be merged, %d still staged", n)
+	}
+	if n := index.HnswIndex.Len(); n != len(keys) {
+		t.Errorf("Expected %d vectors in the graph, got %d", len(keys), n)
+	}
+
+	for i, key := range keys {
+		vec, err := index.GetVector(ctx, key)
+		if err != nil {
+			t.Errorf("Failed to get vector: %v", err)
+		}
+		if !utils.EqualFloat32Slices(vecs[i], vec) {
+			t.Errorf("Expected vector %v for %s, got %v", vecs[i], key, vec)
+		}
+	}
+}
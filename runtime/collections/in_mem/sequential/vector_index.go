@@ -172,6 +172,15 @@ func (ims *SequentialVectorIndex) GetVector(ctx context.Context, key string) ([]
 	return ims.VectorMap[key], nil
 }
 
+func (ims *SequentialVectorIndex) GetDimensions(ctx context.Context) (int, error) {
+	ims.mu.RLock()
+	defer ims.mu.RUnlock()
+	for _, vec := range ims.VectorMap {
+		return len(vec), nil
+	}
+	return 0, nil
+}
+
 func (ims *SequentialVectorIndex) GetCheckpointId(ctx context.Context) (int64, error) {
 	ims.mu.RLock()
 	defer ims.mu.RUnlock()
@@ -12,7 +12,9 @@ package in_mem
 import (
 	"context"
 	"fmt"
+	"maps"
 	"sync"
+	"time"
 
 	"github.com/hypermodeinc/modus/runtime/collections/index"
 	"github.com/hypermodeinc/modus/runtime/collections/index/interfaces"
@@ -26,9 +28,12 @@ type InMemCollectionNamespace struct {
 	collectionName string
 	namespace      string
 	lastInsertedID int64
-	TextMap        map[string]string // key: text
+	codec          TextCodec
+	compression    string
+	TextMap        map[string]string // key: text, encoded with the codec (if any)
 	LabelsMap      map[string][]string
 	IdMap          map[string]int64                          // key: postgres id
+	DeletedMap     map[string]time.Time                      // key: deletion time, for soft-deleted texts
 	VectorIndexMap map[string]*interfaces.VectorIndexWrapper // searchMethod: vectorIndex
 }
 
@@ -39,6 +44,7 @@ func NewCollectionNamespace(name, namespace string) *InMemCollectionNamespace {
 		TextMap:        map[string]string{},
 		LabelsMap:      map[string][]string{},
 		IdMap:          map[string]int64{},
+		DeletedMap:     map[string]time.Time{},
 		VectorIndexMap: map[string]*interfaces.VectorIndexWrapper{},
 	}
 }
@@ -125,11 +131,12 @@ func (ti *InMemCollectionNamespace) InsertTextsToMemory(ctx context.Context, ids
 	ti.mu.Lock()
 	defer ti.mu.Unlock()
 	for i, key := range keys {
-		ti.TextMap[key] = texts[i]
+		ti.TextMap[key] = encodeText(ti.codec, texts[i])
 		if len(labelsArr) != 0 {
 			ti.LabelsMap[key] = labelsArr[i]
 		}
 		ti.IdMap[key] = ids[i]
+		delete(ti.DeletedMap, key)
 		ti.lastInsertedID = ids[i]
 	}
 	return nil
@@ -138,11 +145,12 @@ func (ti *InMemCollectionNamespace) InsertTextsToMemory(ctx context.Context, ids
 func (ti *InMemCollectionNamespace) InsertTextToMemory(ctx context.Context, id int64, key string, text string, labels []string) error {
 	ti.mu.Lock()
 	defer ti.mu.Unlock()
-	ti.TextMap[key] = text
+	ti.TextMap[key] = encodeText(ti.codec, text)
 	if len(labels) != 0 {
 		ti.LabelsMap[key] = labels
 	}
 	ti.IdMap[key] = id
+	delete(ti.DeletedMap, key)
 	ti.lastInsertedID = id
 	return nil
 }
@@ -155,19 +163,138 @@ func (ti *InMemCollectionNamespace) DeleteText(ctx context.Context, key string)
 		return err
 	}
 	delete(ti.TextMap, key)
+	delete(ti.LabelsMap, key)
+	delete(ti.IdMap, key)
+	delete(ti.DeletedMap, key)
+	return nil
+}
+
+func (ti *InMemCollectionNamespace) SoftDeleteText(ctx context.Context, key string) error {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	if _, ok := ti.TextMap[key]; !ok {
+		return fmt.Errorf("key %s not found", key)
+	}
+	deletedAt, err := db.SoftDeleteCollectionText(ctx, ti.collectionName, ti.namespace, key)
+	if err != nil {
+		return err
+	}
+	ti.DeletedMap[key] = deletedAt
+	return nil
+}
+
+func (ti *InMemCollectionNamespace) RestoreText(ctx context.Context, key string) error {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	if _, ok := ti.DeletedMap[key]; !ok {
+		return fmt.Errorf("key %s is not deleted", key)
+	}
+	err := db.RestoreCollectionText(ctx, ti.collectionName, ti.namespace, key)
+	if err != nil {
+		return err
+	}
+	delete(ti.DeletedMap, key)
+	return nil
+}
+
+func (ti *InMemCollectionNamespace) IsDeleted(ctx context.Context, key string) bool {
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+	_, ok := ti.DeletedMap[key]
+	return ok
+}
+
+func (ti *InMemCollectionNamespace) GetDeletedMap(ctx context.Context) (map[string]time.Time, error) {
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+	return maps.Clone(ti.DeletedMap), nil
+}
+
+func (ti *InMemCollectionNamespace) SetDeletedMapInMemory(ctx context.Context, deleted map[string]time.Time) error {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	ti.DeletedMap = deleted
 	return nil
 }
 
 func (ti *InMemCollectionNamespace) GetText(ctx context.Context, key string) (string, error) {
 	ti.mu.RLock()
 	defer ti.mu.RUnlock()
-	return ti.TextMap[key], nil
+	stored, ok := ti.TextMap[key]
+	if !ok {
+		return "", nil
+	}
+	return decodeText(ti.codec, stored)
 }
 
+// GetTextMap returns a copy of the map of key to text, made while holding the lock,
+// so that callers can range over it while the namespace is being written to.
 func (ti *InMemCollectionNamespace) GetTextMap(ctx context.Context) (map[string]string, error) {
 	ti.mu.RLock()
 	defer ti.mu.RUnlock()
-	return ti.TextMap, nil
+	if ti.codec == nil {
+		return maps.Clone(ti.TextMap), nil
+	}
+
+	textMap := make(map[string]string, len(ti.TextMap))
+	for key, stored := range ti.TextMap {
+		text, err := decodeText(ti.codec, stored)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read text for key %s: %w", key, err)
+		}
+		textMap[key] = text
+	}
+	return textMap, nil
+}
+
+func (ti *InMemCollectionNamespace) SetTextCompression(ctx context.Context, compression string) error {
+	if compression == "none" {
+		compression = ""
+	}
+
+	codec, err := getTextCodec(compression)
+	if err != nil {
+		return err
+	}
+
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	if compression == ti.compression {
+		return nil
+	}
+
+	// the texts are re-encoded into a new map, so that the namespace is unchanged if any fails to decode
+	textMap := make(map[string]string, len(ti.TextMap))
+	for key, stored := range ti.TextMap {
+		text, err := decodeText(ti.codec, stored)
+		if err != nil {
+			return fmt.Errorf("failed to read text for key %s: %w", key, err)
+		}
+		textMap[key] = encodeText(codec, text)
+	}
+
+	ti.TextMap = textMap
+	ti.codec = codec
+	ti.compression = compression
+	return nil
+}
+
+func (ti *InMemCollectionNamespace) GetTextMemoryStats(ctx context.Context) interfaces.TextMemoryStats {
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+
+	stats := interfaces.TextMemoryStats{
+		Texts:       len(ti.TextMap),
+		Compression: ti.compression,
+	}
+	if stats.Compression == "" {
+		stats.Compression = "none"
+	}
+	for _, stored := range ti.TextMap {
+		stats.RawBytes += int64(decodedLen(ti.codec, stored))
+		stats.StoredBytes += int64(len(stored))
+	}
+	return stats
 }
 
 func (ti *InMemCollectionNamespace) GetLabels(ctx context.Context, key string) ([]string, error) {
@@ -176,10 +303,11 @@ func (ti *InMemCollectionNamespace) GetLabels(ctx context.Context, key string) (
 	return ti.LabelsMap[key], nil
 }
 
+// GetLabelsMap returns a copy of the map of key to labels, made while holding the lock.
 func (ti *InMemCollectionNamespace) GetLabelsMap(ctx context.Context) (map[string][]string, error) {
 	ti.mu.RLock()
 	defer ti.mu.RUnlock()
-	return ti.LabelsMap, nil
+	return maps.Clone(ti.LabelsMap), nil
 }
 
 func (ti *InMemCollectionNamespace) Len(ctx context.Context) (int, error) {
@@ -188,10 +316,22 @@ func (ti *InMemCollectionNamespace) Len(ctx context.Context) (int, error) {
 	return len(ti.TextMap), nil
 }
 
+// GetExternalId returns the database id of the key's text.  If this instance hasn't loaded the text yet,
+// such as when another replica wrote it since the last sync, the id is read from the database,
+// so that vectors are never written against an id that only exists in this process.
 func (ti *InMemCollectionNamespace) GetExternalId(ctx context.Context, key string) (int64, error) {
 	ti.mu.RLock()
-	defer ti.mu.RUnlock()
-	return ti.IdMap[key], nil
+	id, found := ti.IdMap[key]
+	ti.mu.RUnlock()
+	if found {
+		return id, nil
+	}
+
+	id, err := db.QueryCollectionTextId(ctx, ti.collectionName, ti.namespace, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get id for key %s: %w", key, err)
+	}
+	return id, nil
 }
 
 func (ti *InMemCollectionNamespace) GetCheckpointId(ctx context.Context) (int64, error) {
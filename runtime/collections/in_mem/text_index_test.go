@@ -12,6 +12,7 @@ package in_mem
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -85,3 +86,147 @@ func TestMultipleInMemCollections(t *testing.T) {
 	// Wait for all goroutines to finish
 	wg.Wait()
 }
+
+func TestGetExternalIdForUnloadedKey(t *testing.T) {
+	ctx := context.Background()
+	col := NewCollectionNamespace("collection", DefaultNamespace)
+
+	err := col.InsertTextsToMemory(ctx, []int64{7}, []string{"key1"}, []string{"text1"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to insert texts into collection: %v", err)
+	}
+
+	id, err := col.GetExternalId(ctx, "key1")
+	if err != nil || id != 7 {
+		t.Errorf("Expected id 7, got %d (%v)", id, err)
+	}
+
+	// keys that haven't been loaded are looked up in the database, rather than getting a zero id
+	if id, err := col.GetExternalId(ctx, "key2"); err == nil {
+		t.Errorf("Expected an error for a key that isn't stored, got id %d", id)
+	}
+}
+
+func TestGetTextMapReturnsCopy(t *testing.T) {
+	ctx := context.Background()
+	col := NewCollectionNamespace("collection", "")
+	if err := col.InsertTextsToMemory(ctx, []int64{1}, []string{"key1"}, []string{"text1"}, [][]string{{"label1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	texts, _ := col.GetTextMap(ctx)
+	labels, _ := col.GetLabelsMap(ctx)
+
+	// writes to the namespace while the maps are read must not affect them
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 2; i < 100; i++ {
+			_ = col.InsertTextToMemory(ctx, int64(i), fmt.Sprintf("key%d", i), "text", []string{"label"})
+		}
+	}()
+	for range 100 {
+		for key := range texts {
+			_ = labels[key]
+		}
+	}
+	<-done
+
+	if len(texts) != 1 || len(labels) != 1 {
+		t.Errorf("Expected the copies to be unchanged, got %d texts and %d labels", len(texts), len(labels))
+	}
+	delete(texts, "key1")
+	if text, _ := col.GetText(ctx, "key1"); text != "text1" {
+		t.Errorf("Expected changes to the copy not to affect the namespace")
+	}
+}
+
+func TestTextCompression(t *testing.T) {
+	ctx := context.Background()
+
+	for _, compression := range []string{"zstd", "snappy"} {
+		col := NewCollectionNamespace("collection", "")
+		if err := col.SetTextCompression(ctx, compression); err != nil {
+			t.Fatalf("Failed to set %s compression: %v", compression, err)
+		}
+
+		long := strings.Repeat("the quick brown fox jumps over the lazy dog. ", 50)
+		keys := []string{"long", "short", "empty"}
+		texts := []string{long, "hi", ""}
+		if err := col.InsertTextsToMemory(ctx, []int64{1, 2, 3}, keys, texts, nil); err != nil {
+			t.Fatalf("Failed to insert texts into collection: %v", err)
+		}
+
+		for i, key := range keys {
+			text, err := col.GetText(ctx, key)
+			if err != nil {
+				t.Errorf("Failed to get text from collection: %v", err)
+			}
+			if text != texts[i] {
+				t.Errorf("%s: expected text %q, got %q", compression, texts[i], text)
+			}
+		}
+
+		textMap, err := col.GetTextMap(ctx)
+		if err != nil {
+			t.Errorf("Failed to get text map from collection: %v", err)
+		}
+		for i, key := range keys {
+			if textMap[key] != texts[i] {
+				t.Errorf("%s: expected text %q in map, got %q", compression, texts[i], textMap[key])
+			}
+		}
+
+		if col.TextMap["short"] != string(storedRaw)+"hi" {
+			t.Errorf("%s: expected short text to be stored raw", compression)
+		}
+
+		stats := col.GetTextMemoryStats(ctx)
+		if stats.Compression != compression || stats.Texts != 3 {
+			t.Errorf("%s: unexpected stats %+v", compression, stats)
+		}
+		if stats.RawBytes != int64(len(long)+2) {
+			t.Errorf("%s: expected %d raw bytes, got %d", compression, len(long)+2, stats.RawBytes)
+		}
+		if stats.StoredBytes >= stats.RawBytes {
+			t.Errorf("%s: expected stored bytes %d to be less than raw bytes %d", compression, stats.StoredBytes, stats.RawBytes)
+		}
+	}
+}
+
+func TestSetTextCompression(t *testing.T) {
+	ctx := context.Background()
+	col := NewCollectionNamespace("collection", "")
+
+	text := strings.Repeat("abcdefgh", 100)
+	if err := col.InsertTextToMemory(ctx, 1, "key", text, nil); err != nil {
+		t.Fatalf("Failed to insert text into collection: %v", err)
+	}
+
+	before := col.GetTextMemoryStats(ctx)
+	if before.Compression != "none" || before.StoredBytes != before.RawBytes {
+		t.Errorf("Unexpected stats before compression %+v", before)
+	}
+
+	if err := col.SetTextCompression(ctx, "zstd"); err != nil {
+		t.Fatalf("Failed to set compression: %v", err)
+	}
+	after := col.GetTextMemoryStats(ctx)
+	if after.RawBytes != before.RawBytes || after.StoredBytes >= before.StoredBytes {
+		t.Errorf("Expected texts to be compressed, got %+v", after)
+	}
+
+	if err := col.SetTextCompression(ctx, "none"); err != nil {
+		t.Fatalf("Failed to remove compression: %v", err)
+	}
+	if col.TextMap["key"] != text {
+		t.Errorf("Expected text to be stored uncompressed")
+	}
+
+	if err := col.SetTextCompression(ctx, "lz4"); err == nil {
+		t.Errorf("Expected an error for an unknown compression")
+	}
+	if got, _ := col.GetText(ctx, "key"); got != text {
+		t.Errorf("Expected text to be unchanged after a failed compression change")
+	}
+}
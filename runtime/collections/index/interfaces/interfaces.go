@@ -13,6 +13,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/hypermodeinc/modus/runtime/collections/in_mem/sequential"
 	"github.com/hypermodeinc/modus/runtime/collections/index"
@@ -104,16 +105,31 @@ type CollectionNamespace interface {
 	// DeleteText will remove a text and key from the existing VectorIndex
 	DeleteText(ctx context.Context, key string) error
 
+	// SoftDeleteText will mark a text as deleted, without removing it
+	SoftDeleteText(ctx context.Context, key string) error
+
+	// RestoreText will clear the deleted mark of a soft-deleted text
+	RestoreText(ctx context.Context, key string) error
+
+	// IsDeleted returns true if the text for a given key has been soft-deleted
+	IsDeleted(ctx context.Context, key string) bool
+
+	// GetDeletedMap returns the map of key to deletion time, for soft-deleted texts
+	GetDeletedMap(ctx context.Context) (map[string]time.Time, error)
+
+	// SetDeletedMapInMemory replaces the map of soft-deleted texts, without writing to the database
+	SetDeletedMapInMemory(ctx context.Context, deleted map[string]time.Time) error
+
 	// GetText will return the text for a given key
 	GetText(ctx context.Context, key string) (string, error)
 
 	// GetLabel will return the label for a given key
 	GetLabels(ctx context.Context, key string) ([]string, error)
 
-	// GetTextMap returns the map of key to text
+	// GetTextMap returns a copy of the map of key to text
 	GetTextMap(ctx context.Context) (map[string]string, error)
 
-	// GetLabelMap returns the map of key to label
+	// GetLabelsMap returns a copy of the map of key to labels
 	GetLabelsMap(ctx context.Context) (map[string][]string, error)
 
 	//Len returns the number of texts in the collection
@@ -123,6 +139,21 @@ type CollectionNamespace interface {
 	GetExternalId(ctx context.Context, key string) (int64, error)
 
 	GetCheckpointId(ctx context.Context) (int64, error)
+
+	// SetTextCompression changes the codec that texts are compressed with in memory, by name,
+	// re-encoding the texts already stored.  An empty name or "none" stores texts uncompressed.
+	SetTextCompression(ctx context.Context, compression string) error
+
+	// GetTextMemoryStats reports the memory used by the texts, before and after compression
+	GetTextMemoryStats(ctx context.Context) TextMemoryStats
+}
+
+// TextMemoryStats reports the memory used by the texts of a collection namespace.
+type TextMemoryStats struct {
+	Texts       int    `json:"texts"`
+	Compression string `json:"compression"`
+	RawBytes    int64  `json:"rawBytes"`
+	StoredBytes int64  `json:"storedBytes"`
 }
 
 // A VectorIndex can be used to Search for vectors and add vectors to an index.
@@ -174,6 +205,10 @@ type VectorIndex interface {
 	// GetVector will return the vector for a given key
 	GetVector(ctx context.Context, key string) ([]float32, error)
 
+	// GetDimensions will return the number of dimensions of the vectors in the index,
+	// or 0 if the index is empty
+	GetDimensions(ctx context.Context) (int, error)
+
 	GetCheckpointId(ctx context.Context) (int64, error)
 
 	GetLastIndexedTextId(ctx context.Context) (int64, error)
@@ -0,0 +1,286 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/collections/utils"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+// Items of a collection are texts, or binary blobs such as images.  A blob is stored in place of the text
+// of its item as a data URI, which records its media type, and so the modality of the item.
+// Each item is embedded by the embedder of its modality, as declared by the search method.
+
+// Modalities of collection items.
+const (
+	ModalityText  = "text"
+	ModalityImage = "image"
+)
+
+var errInvalidImageEmbedderSignature = errors.New("invalid image embedder function signature")
+
+// encodeBlob returns the stored form of a blob item.
+func encodeBlob(mediaType string, data []byte) string {
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// decodeBlob returns the media type and content of a stored blob item.
+func decodeBlob(item string) (mediaType string, data []byte, ok bool) {
+	rest, found := strings.CutPrefix(item, "data:")
+	if !found {
+		return "", nil, false
+	}
+	mediaType, encoded, found := strings.Cut(rest, ";base64,")
+	if !found || blobModality(mediaType) == "" {
+		return "", nil, false
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, false
+	}
+	return mediaType, data, true
+}
+
+// isBlob reports whether a stored item is a blob, without decoding it.
+func isBlob(item string) bool {
+	rest, found := strings.CutPrefix(item, "data:")
+	if !found {
+		return false
+	}
+	mediaType, _, found := strings.Cut(rest, ";base64,")
+	return found && blobModality(mediaType) != ""
+}
+
+// blobModality returns the modality of blobs of the media type, or an empty string if they aren't supported.
+func blobModality(mediaType string) string {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return ""
+	}
+	if strings.HasPrefix(mt, "image/") {
+		return ModalityImage
+	}
+	return ""
+}
+
+// UpsertBlobs inserts or updates binary items, such as images, in the collection.
+// The media type declares the modality of the items, and must be an image type such as image/png.
+func UpsertBlobs(ctx context.Context, collectionName, namespace string, keys []string, blobs [][]byte, mediaType string, labels [][]string) (*CollectionMutationResult, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if blobModality(mediaType) != ModalityImage {
+		return nil, fmt.Errorf("unsupported media type %q, must be an image type such as image/png", mediaType)
+	}
+	if len(keys) != 0 && len(keys) != len(blobs) {
+		return nil, fmt.Errorf("mismatch in number of keys and blobs: %d != %d", len(keys), len(blobs))
+	}
+
+	items := make([]string, len(blobs))
+	for i, blob := range blobs {
+		if len(blob) == 0 {
+			return nil, fmt.Errorf("blob %d is empty", i)
+		}
+		items[i] = encodeBlob(mediaType, blob)
+	}
+
+	return Upsert(ctx, collectionName, namespace, keys, items, labels)
+}
+
+// SearchByImage finds the items most similar to an image, which is embedded by the search method's image embedder.
+func SearchByImage(ctx context.Context, collectionName string, namespaces []string, searchMethod string, image []byte, limit int32, returnText bool) (*CollectionSearchResult, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if len(image) == 0 {
+		return nil, errors.New("query image is empty")
+	}
+
+	sm, err := getSearchMethod(collectionName, searchMethod)
+	if err != nil {
+		return nil, err
+	}
+	if sm.ImageEmbedder == "" {
+		return nil, fmt.Errorf("search method %s of collection %s has no image embedder", searchMethod, collectionName)
+	}
+	if err := validateImageEmbedder(ctx, sm.ImageEmbedder); err != nil {
+		return nil, err
+	}
+
+	vecs, err := computeImageEmbeddings(ctx, sm.ImageEmbedder, [][]byte{image})
+	if err != nil {
+		return nil, err
+	}
+
+	return SearchByVector(ctx, collectionName, namespaces, searchMethod, vecs[0], limit, returnText)
+}
+
+func getSearchMethod(collectionName, searchMethod string) (manifest.SearchMethodInfo, error) {
+	manifestColl, ok := manifestdata.GetManifest().Collections[collectionName]
+	if !ok {
+		return manifest.SearchMethodInfo{}, fmt.Errorf("collection %s not found in manifest", collectionName)
+	}
+	sm, ok := manifestColl.SearchMethods[searchMethod]
+	if !ok {
+		return manifest.SearchMethodInfo{}, fmt.Errorf("search method %s not found in collection %s", searchMethod, collectionName)
+	}
+	return sm, nil
+}
+
+// embedItems returns a vector for each of the items, computed by the search method's embedder for the item's modality.
+func embedItems(ctx context.Context, collectionName, searchMethodName string, searchMethod manifest.SearchMethodInfo, items []string) ([][]float32, error) {
+	var texts []string
+	var textIndexes []int
+	var images [][]byte
+	var imageIndexes []int
+	for i, item := range items {
+		if _, data, ok := decodeBlob(item); ok {
+			images = append(images, data)
+			imageIndexes = append(imageIndexes, i)
+		} else {
+			texts = append(texts, item)
+			textIndexes = append(textIndexes, i)
+		}
+	}
+
+	vecs := make([][]float32, len(items))
+
+	if len(texts) > 0 {
+		if searchMethod.Embedder == "" {
+			return nil, fmt.Errorf("search method %s of collection %s has no embedder for text items", searchMethodName, collectionName)
+		}
+		if err := validateEmbedder(ctx, searchMethod.Embedder); err != nil {
+			return nil, err
+		}
+		textVecs, err := computeEmbeddings(ctx, searchMethod.Embedder, texts)
+		if err != nil {
+			return nil, err
+		}
+		for i, vec := range textVecs {
+			vecs[textIndexes[i]] = vec
+		}
+	}
+
+	if len(images) > 0 {
+		if searchMethod.ImageEmbedder == "" {
+			return nil, fmt.Errorf("search method %s of collection %s has no image embedder for image items", searchMethodName, collectionName)
+		}
+		if err := validateImageEmbedder(ctx, searchMethod.ImageEmbedder); err != nil {
+			return nil, err
+		}
+		imageVecs, err := computeImageEmbeddings(ctx, searchMethod.ImageEmbedder, images)
+		if err != nil {
+			return nil, err
+		}
+		for i, vec := range imageVecs {
+			vecs[imageIndexes[i]] = vec
+		}
+	}
+
+	return vecs, nil
+}
+
+// computeImageEmbeddings returns a vector for each of the images, computed by the given image embedder function.
+// Images that were recently embedded by the same embedder are served from the embedding cache.
+func computeImageEmbeddings(ctx context.Context, embedder string, images [][]byte) ([][]float32, error) {
+	imageVecs := make([][]float32, len(images))
+
+	misses := make([][]byte, 0, len(images))
+	missIndexes := make([]int, 0, len(images))
+	for i, image := range images {
+		if vec, ok := globalEmbeddingCache.get(embedder, string(image)); ok {
+			imageVecs[i] = vec
+		} else {
+			misses = append(misses, image)
+			missIndexes = append(missIndexes, i)
+		}
+	}
+
+	if len(misses) == 0 {
+		return imageVecs, nil
+	}
+
+	vecs, err := callEmbedderThrottled(ctx, embedder, func(ctx context.Context) ([][]float32, error) {
+		return invokeImageEmbedder(ctx, embedder, misses)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, vec := range vecs {
+		imageVecs[missIndexes[i]] = vec
+		globalEmbeddingCache.put(embedder, string(misses[i]), vec)
+	}
+
+	return imageVecs, nil
+}
+
+func invokeImageEmbedder(ctx context.Context, embedder string, images [][]byte) ([][]float32, error) {
+	callCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	executionInfo, err := wasmhost.CallFunction(callCtx, embedder, images)
+	if err != nil {
+		return nil, err
+	}
+
+	vecs, err := utils.ConvertToFloat32_2DArray(executionInfo.Result())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vecs) != len(images) {
+		return nil, fmt.Errorf("mismatch in number of embeddings generated by image embedder %s", embedder)
+	}
+
+	return vecs, nil
+}
+
+func validateImageEmbedder(ctx context.Context, embedder string) error {
+
+	info, err := wasmhost.GetWasmHost(ctx).GetFunctionInfo(embedder)
+	if err != nil {
+		return err
+	}
+	fn := info.Metadata()
+
+	// Image embedder functions must take a single parameter that is a list of byte arrays,
+	// and return a single f32[][] or f64[][] array.
+
+	if len(fn.Parameters) != 1 || len(fn.Results) != 1 {
+		return errInvalidImageEmbedderSignature
+	}
+
+	lti := info.Plugin().Language.TypeInfo()
+
+	p := fn.Parameters[0]
+	if !lti.IsListType(p.Type) || !lti.IsByteSequenceType(lti.GetListSubtype(p.Type)) {
+		return errInvalidImageEmbedderSignature
+	}
+
+	r := fn.Results[0]
+	if !lti.IsListType(r.Type) {
+		return errInvalidImageEmbedderSignature
+	}
+
+	a := lti.GetListSubtype(r.Type)
+	if !lti.IsListType(a) || !lti.IsFloatType(lti.GetListSubtype(a)) {
+		return errInvalidImageEmbedderSignature
+	}
+
+	return nil
+}
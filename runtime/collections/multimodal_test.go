@@ -0,0 +1,67 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobItems(t *testing.T) {
+	data := []byte{0x89, 'P', 'N', 'G', 0, 1, 2}
+	item := encodeBlob("image/png", data)
+	require.Equal(t, "data:image/png;base64,iVBORwABAg==", item)
+	require.True(t, isBlob(item))
+
+	mediaType, decoded, ok := decodeBlob(item)
+	require.True(t, ok)
+	require.Equal(t, "image/png", mediaType)
+	require.Equal(t, data, decoded)
+
+	for _, text := range []string{"hello", "data:text/plain;base64,aGk=", "data:image/png,not-base64", "data:image/png;base64,!!"} {
+		_, _, ok := decodeBlob(text)
+		require.False(t, ok, text)
+	}
+}
+
+func TestBlobModality(t *testing.T) {
+	require.Equal(t, ModalityImage, blobModality("image/jpeg"))
+	require.Equal(t, ModalityImage, blobModality("image/svg+xml; charset=utf-8"))
+	require.Equal(t, "", blobModality("audio/wav"))
+	require.Equal(t, "", blobModality("image"))
+}
+
+func TestEmbedItemsRequiresEmbedderOfModality(t *testing.T) {
+	ctx := context.Background()
+	image := encodeBlob("image/png", []byte{1, 2, 3})
+
+	_, err := embedItems(ctx, "photos", "clip", manifest.SearchMethodInfo{Embedder: "embedText"}, []string{image})
+	require.ErrorContains(t, err, "search method clip of collection photos has no image embedder")
+
+	_, err = embedItems(ctx, "photos", "clip", manifest.SearchMethodInfo{ImageEmbedder: "embedImages"}, []string{"a caption"})
+	require.ErrorContains(t, err, "search method clip of collection photos has no embedder for text items")
+}
+
+func TestUpsertBlobsValidation(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := UpsertBlobs(ctx, "photos", "", nil, [][]byte{{1}}, "audio/wav", nil)
+	require.ErrorContains(t, err, `unsupported media type "audio/wav"`)
+
+	_, err = UpsertBlobs(ctx, "photos", "", []string{"a", "b"}, [][]byte{{1}}, "image/png", nil)
+	require.ErrorContains(t, err, "mismatch in number of keys and blobs")
+
+	_, err = UpsertBlobs(ctx, "photos", "", nil, [][]byte{{}}, "image/png", nil)
+	require.ErrorContains(t, err, "blob 0 is empty")
+}
@@ -0,0 +1,259 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/metrics"
+)
+
+// piiDetector finds one type of personal information in a text.
+// The optional validate function rejects matches that only look like the type.
+type piiDetector struct {
+	piiType  string
+	re       *regexp.Regexp
+	validate func(match string) bool
+}
+
+var builtinPIIDetectors = map[string]*piiDetector{
+	manifest.PIITypeEmail: {
+		piiType: manifest.PIITypeEmail,
+		re:      regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	},
+	manifest.PIITypePhone: {
+		piiType: manifest.PIITypePhone,
+		re:      regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)\s?|\b\d{3}[\s.-])\d{3}[\s.-]\d{4}\b`),
+	},
+	manifest.PIITypeCreditCard: {
+		piiType:  manifest.PIITypeCreditCard,
+		re:       regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		validate: luhnValid,
+	},
+	manifest.PIITypeSSN: {
+		piiType: manifest.PIITypeSSN,
+		re:      regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+	},
+	manifest.PIITypeIPAddress: {
+		piiType: manifest.PIITypeIPAddress,
+		re:      regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`),
+	},
+}
+
+var defaultPIITypes = []string{manifest.PIITypeEmail, manifest.PIITypePhone}
+
+// piiMatch is the location of a detected item of personal information within a text.
+type piiMatch struct {
+	piiType    string
+	start, end int
+}
+
+func getPIIDetectors(info *manifest.PIIDetectionInfo) ([]*piiDetector, error) {
+	types := info.Types
+	if len(types) == 0 && len(info.Patterns) == 0 {
+		types = defaultPIITypes
+	}
+
+	detectors := make([]*piiDetector, 0, len(types)+len(info.Patterns))
+	for _, t := range types {
+		d, ok := builtinPIIDetectors[t]
+		if !ok {
+			return nil, fmt.Errorf("unknown personal information type: %s", t)
+		}
+		detectors = append(detectors, d)
+	}
+
+	names := make([]string, 0, len(info.Patterns))
+	for name := range info.Patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		re, err := regexp.Compile(info.Patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for personal information type %s: %w", name, err)
+		}
+		detectors = append(detectors, &piiDetector{piiType: name, re: re})
+	}
+
+	return detectors, nil
+}
+
+// findPII returns the non-overlapping matches of all detectors in the text, ordered by position.
+// Where matches overlap, the one that starts first is kept, and then the longest.
+func findPII(detectors []*piiDetector, text string) []piiMatch {
+	var matches []piiMatch
+	for _, d := range detectors {
+		for _, loc := range d.re.FindAllStringIndex(text, -1) {
+			if d.validate != nil && !d.validate(text[loc[0]:loc[1]]) {
+				continue
+			}
+			matches = append(matches, piiMatch{d.piiType, loc[0], loc[1]})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].start != matches[j].start {
+			return matches[i].start < matches[j].start
+		}
+		return matches[i].end > matches[j].end
+	})
+
+	result := matches[:0]
+	end := -1
+	for _, m := range matches {
+		if m.start >= end {
+			result = append(result, m)
+			end = m.end
+		}
+	}
+	return result
+}
+
+// redactPII replaces each match with a placeholder naming its type, such as [EMAIL] or [CREDIT_CARD].
+func redactPII(text string, matches []piiMatch) string {
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		sb.WriteString(text[last:m.start])
+		sb.WriteString(piiPlaceholder(m.piiType))
+		last = m.end
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+func piiPlaceholder(piiType string) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i, r := range piiType {
+		if i > 0 && unicode.IsUpper(r) {
+			sb.WriteByte('_')
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+func piiTypes(matches []piiMatch) []string {
+	types := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if !slices.Contains(types, m.piiType) {
+			types = append(types, m.piiType)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// luhnValid reports whether the digits in the string pass the Luhn checksum used by payment card numbers.
+func luhnValid(s string) bool {
+	sum := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// detectPII scans each incoming text for personal information, and applies the configured policy,
+// before the texts are stored or embedded.  Every detection is recorded in the audit log,
+// without the detected values.
+func detectPII(ctx context.Context, collectionName, namespace string, info *manifest.PIIDetectionInfo, keys, texts []string, labels [][]string) (*upsertBatch, []*CollectionPIIObject, error) {
+	detectors, err := getPIIDetectors(info)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	policy := info.Policy
+	if policy == "" {
+		policy = manifest.PIIPolicyRedact
+	}
+
+	batch := &upsertBatch{
+		keys:  make([]string, 0, len(keys)),
+		texts: make([]string, 0, len(texts)),
+	}
+	if len(labels) != 0 {
+		batch.labels = make([][]string, 0, len(labels))
+	}
+
+	detections := []*CollectionPIIObject{}
+	for i, key := range keys {
+		text := texts[i]
+
+		// binary items such as images are not scanned
+		var matches []piiMatch
+		if !isBlob(text) {
+			matches = findPII(detectors, text)
+		}
+
+		if len(matches) > 0 {
+			types := piiTypes(matches)
+			detections = append(detections, NewCollectionPIIObject(key, types, string(policy)))
+
+			for _, t := range types {
+				metrics.CollectionPIIDetectionsNum.WithLabelValues(collectionName, t, string(policy)).Inc()
+			}
+
+			// audit record
+			logger.Warn(ctx).
+				Str("log_type", "pii_detection").
+				Str("collection", collectionName).
+				Str("namespace", namespace).
+				Str("key", key).
+				Strs("pii_types", types).
+				Int("pii_matches", len(matches)).
+				Str("policy", string(policy)).
+				Bool("user_visible", true).
+				Msg("Personal information was detected in a text upserted into a collection.")
+
+			switch policy {
+			case manifest.PIIPolicyRedact:
+				text = redactPII(text, matches)
+			case manifest.PIIPolicyReject:
+				continue
+			case manifest.PIIPolicyFlag:
+			default:
+				return nil, nil, fmt.Errorf("unknown personal information policy: %s", policy)
+			}
+		}
+
+		batch.keys = append(batch.keys, key)
+		batch.texts = append(batch.texts, text)
+		if len(labels) != 0 {
+			batch.labels = append(batch.labels, labels[i])
+		}
+	}
+
+	return batch, detections, nil
+}
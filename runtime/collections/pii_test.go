@@ -0,0 +1,113 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindPII(t *testing.T) {
+	detectors, err := getPIIDetectors(&manifest.PIIDetectionInfo{
+		Types: []string{
+			manifest.PIITypeEmail,
+			manifest.PIITypePhone,
+			manifest.PIITypeCreditCard,
+			manifest.PIITypeSSN,
+			manifest.PIITypeIPAddress,
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		text  string
+		types []string
+	}{
+		{"contact jane.doe@example.com for details", []string{manifest.PIITypeEmail}},
+		{"call (555) 123-4567 or +1 555 123 4567", []string{manifest.PIITypePhone, manifest.PIITypePhone}},
+		{"card 4111 1111 1111 1111 on file", []string{manifest.PIITypeCreditCard}},
+		{"card 4111 1111 1111 1112 is not a valid number", nil},
+		{"ssn 123-45-6789", []string{manifest.PIITypeSSN}},
+		{"from 192.168.1.20", []string{manifest.PIITypeIPAddress}},
+		{"nothing personal here, version 1.2.3", nil},
+	}
+
+	for _, tt := range tests {
+		var types []string
+		for _, m := range findPII(detectors, tt.text) {
+			types = append(types, m.piiType)
+		}
+		assert.Equal(t, tt.types, types, tt.text)
+	}
+}
+
+func TestFindPIICustomPatterns(t *testing.T) {
+	detectors, err := getPIIDetectors(&manifest.PIIDetectionInfo{
+		Patterns: map[string]string{"employeeId": `EMP-[0-9]{6}`},
+	})
+	require.NoError(t, err)
+
+	matches := findPII(detectors, "assigned to EMP-123456, email bob@example.com")
+	require.Len(t, matches, 1)
+	assert.Equal(t, "employeeId", matches[0].piiType)
+
+	_, err = getPIIDetectors(&manifest.PIIDetectionInfo{
+		Patterns: map[string]string{"broken": `[`},
+	})
+	assert.Error(t, err)
+}
+
+func TestRedactPII(t *testing.T) {
+	detectors, err := getPIIDetectors(&manifest.PIIDetectionInfo{
+		Types:    []string{manifest.PIITypeEmail, manifest.PIITypeCreditCard},
+		Patterns: map[string]string{"employeeId": `EMP-[0-9]{6}`},
+	})
+	require.NoError(t, err)
+
+	text := "EMP-123456 paid with 4111-1111-1111-1111, receipt to a@b.io."
+	redacted := redactPII(text, findPII(detectors, text))
+	assert.Equal(t, "[EMPLOYEE_ID] paid with [CREDIT_CARD], receipt to [EMAIL].", redacted)
+}
+
+func TestDetectPIIPolicies(t *testing.T) {
+	keys := []string{"k1", "k2"}
+	texts := []string{"reach me at a@b.io", "no personal information"}
+	labels := [][]string{{"x"}, {"y"}}
+
+	tests := []struct {
+		policy manifest.PIIPolicy
+		keys   []string
+		texts  []string
+	}{
+		{manifest.PIIPolicyRedact, keys, []string{"reach me at [EMAIL]", "no personal information"}},
+		{manifest.PIIPolicyReject, []string{"k2"}, []string{"no personal information"}},
+		{manifest.PIIPolicyFlag, keys, texts},
+	}
+
+	for _, tt := range tests {
+		info := &manifest.PIIDetectionInfo{Policy: tt.policy}
+		batch, detections, err := detectPII(context.Background(), "c", "ns", info, keys, texts, labels)
+		require.NoError(t, err)
+
+		assert.Equal(t, tt.keys, batch.keys, tt.policy)
+		assert.Equal(t, tt.texts, batch.texts, tt.policy)
+		assert.Len(t, batch.labels, len(tt.keys), tt.policy)
+
+		require.Len(t, detections, 1)
+		assert.Equal(t, "k1", detections[0].Key)
+		assert.Equal(t, []string{manifest.PIITypeEmail}, detections[0].Types)
+		assert.Equal(t, string(tt.policy), detections[0].Policy)
+	}
+}
@@ -0,0 +1,278 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+)
+
+const defaultProjectionSampleSize = 5000
+const maxProjectionSampleSize = 20000
+const pcaIterations = 100
+const pcaTolerance = 1e-9
+
+// Projection is a two-dimensional projection of the stored vectors of a collection namespace,
+// for visualizing the embedding space without exporting the vectors themselves.
+type Projection struct {
+	Collection   string             `json:"collection"`
+	Namespace    string             `json:"namespace"`
+	SearchMethod string             `json:"searchMethod"`
+	Method       string             `json:"method"`
+	Total        int                `json:"total"`
+	Sampled      bool               `json:"sampled"`
+	Explained    [2]float64         `json:"explainedVariance"`
+	Points       []*ProjectionPoint `json:"points"`
+}
+
+type ProjectionPoint struct {
+	Key    string   `json:"key"`
+	Labels []string `json:"labels"`
+	X      float64  `json:"x"`
+	Y      float64  `json:"y"`
+}
+
+// ProjectVectors computes a principal component analysis (PCA) projection of a namespace's vectors onto two dimensions.
+// When the namespace has more items than the sample size, a sample of them is projected.  The sample is the same
+// for the same items, so that repeated projections are comparable.
+func ProjectVectors(ctx context.Context, collectionName, namespace, searchMethod string, sampleSize int) (*Projection, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionRead); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
+		return nil, err
+	}
+	defer lockForRead(ctx)()
+
+	if sampleSize <= 0 {
+		sampleSize = defaultProjectionSampleSize
+	}
+	if sampleSize > maxProjectionSampleSize {
+		return nil, fmt.Errorf("sample size must be at most %d", maxProjectionSampleSize)
+	}
+
+	col, err := globalNamespaceManager.findCollection(collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	collNs, err := col.findNamespace(resolveNamespace(ctx, collectionName, namespace))
+	if err != nil {
+		return nil, err
+	}
+
+	vectorIndex, err := collNs.GetVectorIndex(ctx, searchMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	textMap, err := collNs.GetTextMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(textMap))
+	for key := range textMap {
+		if !collNs.IsDeleted(ctx, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	total := len(keys)
+	if total > sampleSize {
+		rng := rand.New(rand.NewPCG(uint64(total), uint64(sampleSize)))
+		rng.Shuffle(total, func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+		keys = keys[:sampleSize]
+		sort.Strings(keys)
+	}
+
+	vectors := make([][]float32, 0, len(keys))
+	sampledKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		vec, err := vectorIndex.GetVector(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if len(vec) == 0 {
+			// not indexed yet
+			continue
+		}
+		vectors = append(vectors, vec)
+		sampledKeys = append(sampledKeys, key)
+	}
+
+	coords, explained, err := projectPCA(vectors)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]*ProjectionPoint, len(sampledKeys))
+	for i, key := range sampledKeys {
+		labels, err := collNs.GetLabels(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if labels == nil {
+			labels = []string{}
+		}
+		points[i] = &ProjectionPoint{Key: key, Labels: labels, X: coords[i][0], Y: coords[i][1]}
+	}
+
+	return &Projection{
+		Collection:   collectionName,
+		Namespace:    namespace,
+		SearchMethod: searchMethod,
+		Method:       "pca",
+		Total:        total,
+		Sampled:      total > len(keys),
+		Explained:    explained,
+		Points:       points,
+	}, nil
+}
+
+// projectPCA projects the vectors onto their first two principal components, found by power iteration,
+// and returns the coordinates along with the fraction of the total variance each component explains.
+func projectPCA(vectors [][]float32) ([][2]float64, [2]float64, error) {
+	var explained [2]float64
+	coords := make([][2]float64, len(vectors))
+	if len(vectors) == 0 {
+		return coords, explained, nil
+	}
+
+	dims := len(vectors[0])
+	for _, v := range vectors {
+		if len(v) != dims {
+			return nil, explained, errors.New("vectors have inconsistent dimensions")
+		}
+	}
+
+	// center the data
+	mean := make([]float64, dims)
+	for _, v := range vectors {
+		for j, x := range v {
+			mean[j] += float64(x)
+		}
+	}
+	for j := range mean {
+		mean[j] /= float64(len(vectors))
+	}
+
+	data := make([][]float64, len(vectors))
+	totalVariance := 0.0
+	for i, v := range vectors {
+		row := make([]float64, dims)
+		for j, x := range v {
+			row[j] = float64(x) - mean[j]
+			totalVariance += row[j] * row[j]
+		}
+		data[i] = row
+	}
+	if totalVariance == 0 {
+		// all vectors are identical
+		return coords, explained, nil
+	}
+
+	var components [2][]float64
+	for c := range components {
+		component, variance := principalComponent(data, components[:c])
+		components[c] = component
+		explained[c] = variance / totalVariance
+	}
+
+	for i, row := range data {
+		coords[i] = [2]float64{dot(row, components[0]), dot(row, components[1])}
+	}
+	return coords, explained, nil
+}
+
+// principalComponent finds the direction of greatest variance in the centered data that is orthogonal to the
+// given components, and returns it as a unit vector along with the sum of squared projections onto it.
+func principalComponent(data [][]float64, orthogonalTo [][]float64) ([]float64, float64) {
+	dims := len(data[0])
+
+	// start from a fixed, non-degenerate direction, so that results are repeatable
+	v := make([]float64, dims)
+	for j := range v {
+		v[j] = 1 / math.Sqrt(float64(j+1))
+	}
+	orthogonalize(v, orthogonalTo)
+	if normalize(v) == 0 {
+		return v, 0
+	}
+
+	next := make([]float64, dims)
+	variance := 0.0
+	for range pcaIterations {
+		// next = Xᵀ(Xv), which is the covariance matrix applied to v, up to scale
+		clear(next)
+		for _, row := range data {
+			p := dot(row, v)
+			for j, x := range row {
+				next[j] += p * x
+			}
+		}
+		orthogonalize(next, orthogonalTo)
+
+		norm := normalize(next)
+		if norm == 0 {
+			return v, 0
+		}
+
+		delta := 0.0
+		for j := range v {
+			d := next[j] - v[j]
+			delta += d * d
+		}
+		v, next = next, v
+		variance = norm
+		if delta < pcaTolerance {
+			break
+		}
+	}
+
+	return v, variance
+}
+
+func orthogonalize(v []float64, basis [][]float64) {
+	for _, b := range basis {
+		p := dot(v, b)
+		for j := range v {
+			v[j] -= p * b[j]
+		}
+	}
+}
+
+func normalize(v []float64) float64 {
+	norm := math.Sqrt(dot(v, v))
+	if norm == 0 {
+		return 0
+	}
+	for j := range v {
+		v[j] /= norm
+	}
+	return norm
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectPCA(t *testing.T) {
+	// points spread widely along x, narrowly along y, and not at all along z
+	vectors := [][]float32{
+		{-10, 1, 5},
+		{-5, -1, 5},
+		{0, 1, 5},
+		{5, -1, 5},
+		{10, 1, 5},
+	}
+
+	coords, explained, err := projectPCA(vectors)
+	require.NoError(t, err)
+	require.Len(t, coords, len(vectors))
+
+	// the squared deviations sum to 250 along x and 4.8 along y
+	assert.InDelta(t, 250/254.8, explained[0], 1e-6)
+	assert.InDelta(t, 4.8/254.8, explained[1], 1e-6)
+
+	for i, v := range vectors {
+		// the components are unique up to sign
+		assert.InDelta(t, math.Abs(float64(v[0])), math.Abs(coords[i][0]), 1e-6)
+		assert.InDelta(t, math.Abs(float64(v[1])-0.2), math.Abs(coords[i][1]), 1e-6)
+	}
+}
+
+func TestProjectPCADegenerate(t *testing.T) {
+	coords, explained, err := projectPCA(nil)
+	require.NoError(t, err)
+	assert.Empty(t, coords)
+	assert.Equal(t, [2]float64{}, explained)
+
+	coords, _, err = projectPCA([][]float32{{1, 2}, {1, 2}})
+	require.NoError(t, err)
+	assert.Equal(t, [][2]float64{{0, 0}, {0, 0}}, coords)
+
+	_, _, err = projectPCA([][]float32{{1, 2}, {1, 2, 3}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,207 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hypermodeinc/modus/runtime/models"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// A RAG query searches a collection for the texts that are most similar to the query, assembles as many of them
+// as fit in the model's context window into a prompt, and invokes the model with the prompt.  The model is invoked
+// with the OpenAI chat completions format, which is also served by most other providers and inference servers,
+// so a model whose endpoint doesn't serve that format is rejected.
+
+// defaultContextWindow is the context window of a model that doesn't declare one in the manifest.
+const defaultContextWindow = 8192
+
+// A quarter of the context window is reserved for the model's answer, and the model is asked to stay within it.
+const answerShareOfContextWindow = 4
+
+const defaultRagPromptTemplate = `Answer the question using only the numbered sources below.  After each statement, cite the sources that it is based on by their numbers in square brackets, such as [1] or [1, 2].  If the sources do not contain the answer, say that you don't know.
+
+Sources:
+{{context}}
+
+Question: {{query}}`
+
+var ragPlaceholderRegex = regexp.MustCompile(`{{\s*(context|query)\s*}}`)
+
+// RagQuery answers the query with the model, from the texts of the collection that the search method finds for it
+// in the namespaces, or in all namespaces if there are none.  At most k texts are retrieved, and of those, the best that fit in the model's context window are given to the model
+// as numbered sources.  The prompt template places them with {{context}}, and the query with {{query}}.
+// If the template is empty, a default template is used that asks the model to cite its sources.
+// The citation markers in the answer, such as [1], are parsed into spans that link the cited text to its sources.
+func RagQuery(ctx context.Context, collectionName string, namespaces []string, searchMethod, query string, k int32, promptTemplate, modelName string) (*CollectionRagResult, error) {
+	if query == "" {
+		return nil, errors.New("query is empty")
+	}
+	if k <= 0 {
+		return nil, errors.New("k must be greater than zero")
+	}
+
+	template := promptTemplate
+	if template == "" {
+		template = defaultRagPromptTemplate
+	}
+	if !hasRagPlaceholder(template, "context") {
+		return nil, errors.New("prompt template must contain the {{context}} placeholder")
+	}
+
+	model, err := models.GetModel(modelName)
+	if err != nil {
+		return nil, err
+	}
+	if !models.IsChatCompletionsModel(model) {
+		return nil, fmt.Errorf("model %s is not served by an OpenAI-compatible chat completions endpoint", modelName)
+	}
+
+	countTokens := ragTokenCounter(ctx, modelName)
+	window := model.ContextWindow
+	if window <= 0 {
+		window = defaultContextWindow
+	}
+	maxAnswerTokens := window / answerShareOfContextWindow
+	budget := window - maxAnswerTokens - countTokens(renderRagPrompt(template, "", query))
+	if budget <= 0 {
+		return nil, fmt.Errorf("the prompt does not fit in the context window of model %s", modelName)
+	}
+
+	res, err := Search(ctx, collectionName, namespaces, searchMethod, query, k, true)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, citations := assembleRagContext(res.Objects, budget, countTokens)
+	prompt := renderRagPrompt(template, sources, query)
+
+	input, err := newRagModelInput(model.SourceModel, prompt, maxAnswerTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := models.InvokeModel(ctx, modelName, input)
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := parseRagAnswer(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the answer of model %s: %w", modelName, err)
+	}
+
+	spans := parseCitationSpans(answer, citations)
+	return NewCollectionRagResult(res.Collection, searchMethod, "success", answer, citations, spans, ""), nil
+}
+
+// assembleRagContext numbers the texts that were found as sources, in order of their scores, skipping any that
+// would exceed the budget of tokens.  It returns the sources, and a citation of each source that was included.
+func assembleRagContext(objects []*CollectionSearchResultObject, budget int, countTokens func(string) int) (string, []*CollectionRagCitation) {
+	var sb strings.Builder
+	citations := make([]*CollectionRagCitation, 0, len(objects))
+
+	for _, obj := range objects {
+		source := "[" + strconv.Itoa(len(citations)+1) + "] " + obj.Text + "\n\n"
+		tokens := countTokens(source)
+		if tokens > budget {
+			continue
+		}
+		budget -= tokens
+
+		sb.WriteString(source)
+		citations = append(citations, NewCollectionRagCitation(obj.Namespace, obj.Key, obj.Score))
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n\n"), citations
+}
+
+func renderRagPrompt(template, sources, query string) string {
+	return ragPlaceholderRegex.ReplaceAllStringFunc(template, func(match string) string {
+		if ragPlaceholderRegex.FindStringSubmatch(match)[1] == "context" {
+			return sources
+		}
+		return query
+	})
+}
+
+func hasRagPlaceholder(template, name string) bool {
+	for _, m := range ragPlaceholderRegex.FindAllStringSubmatch(template, -1) {
+		if m[1] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ragTokenCounter returns a function that counts the tokens of text with the model's tokenizer.  If the tokenizer
+// of the model is not known, the tokens are estimated from the length of the text instead.
+func ragTokenCounter(ctx context.Context, modelName string) func(string) int {
+	if _, err := models.CountTokens(ctx, modelName, ""); err != nil {
+		return estimateRagTokens
+	}
+	return func(text string) int {
+		n, err := models.CountTokens(ctx, modelName, text)
+		if err != nil {
+			return estimateRagTokens(text)
+		}
+		return int(n)
+	}
+}
+
+// estimateRagTokens is a rough approximation of the number of tokens of English text, of about four characters each.
+func estimateRagTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+func newRagModelInput(sourceModel, prompt string, maxTokens int) (string, error) {
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	req := struct {
+		Model     string    `json:"model,omitempty"`
+		Messages  []message `json:"messages"`
+		MaxTokens int       `json:"max_tokens,omitempty"`
+	}{
+		Model:     sourceModel,
+		Messages:  []message{{Role: "user", Content: prompt}},
+		MaxTokens: maxTokens,
+	}
+
+	bytes, err := utils.JsonSerialize(req)
+	if err != nil {
+		return "", fmt.Errorf("error serializing model request: %w", err)
+	}
+	return string(bytes), nil
+}
+
+func parseRagAnswer(output string) (string, error) {
+	var res struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := utils.JsonDeserialize([]byte(output), &res); err != nil {
+		return "", err
+	}
+	if len(res.Choices) == 0 {
+		return "", errors.New("the response has no choices")
+	}
+	return res.Choices[0].Message.Content, nil
+}
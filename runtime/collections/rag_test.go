@@ -0,0 +1,57 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssembleRagContext(t *testing.T) {
+	objects := []*CollectionSearchResultObject{
+		{Key: "a", Text: "first source", Score: 0.9},
+		{Key: "b", Text: "a source that is much too long to fit in the remaining budget", Score: 0.8},
+		{Key: "c", Text: "third", Score: 0.7},
+	}
+
+	sources, citations := assembleRagContext(objects, 10, estimateRagTokens)
+	require.Equal(t, "[1] first source\n\n[2] third", sources)
+	require.Len(t, citations, 2)
+	require.Equal(t, "a", citations[0].Key)
+	require.Equal(t, 0.9, citations[0].Score)
+	require.Equal(t, "c", citations[1].Key)
+
+	sources, citations = assembleRagContext(objects, 0, estimateRagTokens)
+	require.Empty(t, sources)
+	require.Empty(t, citations)
+}
+
+func TestRenderRagPrompt(t *testing.T) {
+	prompt := renderRagPrompt("Sources:\n{{ context }}\nQuestion: {{query}}", "[1] text", "why?")
+	require.Equal(t, "Sources:\n[1] text\nQuestion: why?", prompt)
+
+	require.True(t, hasRagPlaceholder(defaultRagPromptTemplate, "context"))
+	require.True(t, hasRagPlaceholder(defaultRagPromptTemplate, "query"))
+	require.False(t, hasRagPlaceholder("Question: {{query}}", "context"))
+}
+
+func TestRagModelInputAndAnswer(t *testing.T) {
+	input, err := newRagModelInput("gpt-4o", "the prompt", 2048)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"model":"gpt-4o","messages":[{"role":"user","content":"the prompt"}],"max_tokens":2048}`, input)
+
+	answer, err := parseRagAnswer(`{"choices":[{"message":{"role":"assistant","content":"the answer [1]"}}]}`)
+	require.NoError(t, err)
+	require.Equal(t, "the answer [1]", answer)
+
+	_, err = parseRagAnswer(`{"choices":[]}`)
+	require.Error(t, err)
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/collections/index/interfaces"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/logger"
+)
+
+// When the runtime starts, every collection is restored from the database into memory, which can take a while
+// for large collections.  Restoration runs in the background, so that its progress can be reported.  Until a
+// collection is restored, operations on it wait for it to be restored.  With partial availability, they fail
+// instead, so that functions that don't use collections can be served while restoration continues.
+
+const (
+	RestoreStatePending   = "pending"
+	RestoreStateRestoring = "restoring"
+	RestoreStateComplete  = "complete"
+)
+
+// RestoreProgress reports the progress of restoring the collections at startup.
+type RestoreProgress struct {
+	State               string     `json:"state"`
+	StartedAt           *time.Time `json:"startedAt,omitempty"`
+	CompletedAt         *time.Time `json:"completedAt,omitempty"`
+	ElapsedMs           int64      `json:"elapsedMs"`
+	Collections         int        `json:"collections"`
+	CollectionsRestored int        `json:"collectionsRestored"`
+	Namespaces          int        `json:"namespaces"`
+	NamespacesRestored  int        `json:"namespacesRestored"`
+	TextsRestored       int        `json:"textsRestored"`
+	Current             string     `json:"current,omitempty"`
+	Pending             []string   `json:"pending,omitempty"`
+}
+
+type restoreTracker struct {
+	mu       sync.Mutex
+	progress RestoreProgress
+	started  chan struct{}
+	pending  map[string]chan struct{} // closed when the collection is restored
+}
+
+var globalRestore *restoreTracker
+
+func newRestoreTracker() *restoreTracker {
+	return &restoreTracker{
+		progress: RestoreProgress{State: RestoreStatePending},
+		started:  make(chan struct{}),
+		pending:  make(map[string]chan struct{}),
+	}
+}
+
+// GetRestoreProgress returns the progress of restoring the collections at startup,
+// or nil if collections are not in use.
+func GetRestoreProgress() *RestoreProgress {
+	t := globalRestore
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p := t.progress
+	switch {
+	case p.CompletedAt != nil:
+		p.ElapsedMs = p.CompletedAt.Sub(*p.StartedAt).Milliseconds()
+	case p.StartedAt != nil:
+		p.ElapsedMs = time.Since(*p.StartedAt).Milliseconds()
+	}
+	p.Pending = slices.Sorted(maps.Keys(t.pending))
+	return &p
+}
+
+// IsRestoring returns true while the collections are being restored at startup.
+func IsRestoring() bool {
+	p := GetRestoreProgress()
+	return p != nil && p.State == RestoreStateRestoring
+}
+
+// restore restores the collections into memory, the first time that functions are loaded.
+// Afterwards, it only loads what has been added since, as the worker does periodically.
+func (cf *collectionFactory) restore(ctx context.Context) {
+	if !globalRestore.begin(ctx, cf) {
+		cf.readFromPostgres(ctx, nil)
+		return
+	}
+
+	go func() {
+		cf.readFromPostgres(ctx, globalRestore)
+		globalRestore.complete(ctx)
+	}()
+}
+
+// begin starts tracking the restoration of the collections that exist, returning false if it has already begun.
+func (t *restoreTracker) begin(ctx context.Context, cf *collectionFactory) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.progress.State != RestoreStatePending {
+		return false
+	}
+
+	cf.mu.RLock()
+	for name, col := range cf.collectionMap {
+		if name != "" {
+			t.pending[name] = make(chan struct{})
+			t.progress.Namespaces += col.collectionNamespaceMap.Size()
+		}
+	}
+	cf.mu.RUnlock()
+
+	now := time.Now()
+	t.progress.State = RestoreStateRestoring
+	t.progress.StartedAt = &now
+	t.progress.Collections = len(t.pending)
+	close(t.started)
+
+	logger.Info(ctx).
+		Int("collections", t.progress.Collections).
+		Int("namespaces", t.progress.Namespaces).
+		Bool("partial_availability", config.PartialAvailability).
+		Msg("Restoring collections.")
+
+	return true
+}
+
+func (t *restoreTracker) startNamespace(collectionName, namespace string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress.Current = collectionName + "/" + namespace
+}
+
+func (t *restoreTracker) finishNamespace(ctx context.Context, collNs interfaces.CollectionNamespace) {
+	if t == nil {
+		return
+	}
+	texts, _ := collNs.Len(ctx)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress.NamespacesRestored++
+	t.progress.TextsRestored += texts
+}
+
+func (t *restoreTracker) finishCollection(collectionName string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ch, ok := t.pending[collectionName]; ok {
+		close(ch)
+		delete(t.pending, collectionName)
+		t.progress.CollectionsRestored++
+	}
+}
+
+func (t *restoreTracker) complete(ctx context.Context) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// any collection that was removed while restoring is no longer waited for
+	for name, ch := range t.pending {
+		close(ch)
+		delete(t.pending, name)
+	}
+
+	now := time.Now()
+	t.progress.State = RestoreStateComplete
+	t.progress.CompletedAt = &now
+	t.progress.Current = ""
+
+	logger.Info(ctx).
+		Int("collections", t.progress.CollectionsRestored).
+		Int("namespaces", t.progress.NamespacesRestored).
+		Int("texts", t.progress.TextsRestored).
+		Dur("duration_ms", now.Sub(*t.progress.StartedAt)).
+		Msg("Restored collections.")
+}
+
+// waitForRestore waits until the collection has been restored, or with partial availability,
+// returns an error if it has not been restored yet.
+func waitForRestore(ctx context.Context, collectionName string) error {
+	t := globalRestore
+	if t == nil {
+		return nil
+	}
+
+	select {
+	case <-t.started:
+	default:
+		if config.PartialAvailability {
+			return fmt.Errorf("collection %s is still being restored", collectionName)
+		}
+		select {
+		case <-t.started:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	t.mu.Lock()
+	ch, ok := t.pending[collectionName]
+	t.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	if config.PartialAvailability {
+		return fmt.Errorf("collection %s is still being restored", collectionName)
+	}
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/collections/in_mem"
+	"github.com/hypermodeinc/modus/runtime/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreProgress(t *testing.T) {
+	ctx := context.Background()
+
+	cf := newCollectionFactory()
+	col, err := cf.createCollection("docs", newCollection())
+	require.NoError(t, err)
+	collNs := in_mem.NewCollectionNamespace("docs", in_mem.DefaultNamespace)
+	_, err = col.createCollectionNamespace(in_mem.DefaultNamespace, collNs)
+	require.NoError(t, err)
+	require.NoError(t, collNs.InsertTextToMemory(ctx, 1, "a", "some text", nil))
+
+	globalRestore = newRestoreTracker()
+	defer func() { globalRestore = nil }()
+
+	assert.Equal(t, RestoreStatePending, GetRestoreProgress().State)
+	assert.False(t, IsRestoring())
+
+	require.True(t, globalRestore.begin(ctx, cf))
+	assert.True(t, IsRestoring())
+	assert.False(t, globalRestore.begin(ctx, cf))
+
+	p := GetRestoreProgress()
+	assert.Equal(t, RestoreStateRestoring, p.State)
+	assert.Equal(t, 1, p.Collections)
+	assert.Equal(t, 1, p.Namespaces)
+	assert.Equal(t, []string{"docs"}, p.Pending)
+
+	// a collection that isn't being restored is available
+	assert.NoError(t, waitForRestore(ctx, "other"))
+
+	config.PartialAvailability = true
+	assert.Error(t, waitForRestore(ctx, "docs"))
+	config.PartialAvailability = false
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, waitForRestore(timeoutCtx, "docs"), context.DeadlineExceeded)
+
+	waited := make(chan error)
+	go func() { waited <- waitForRestore(ctx, "docs") }()
+
+	globalRestore.startNamespace("docs", in_mem.DefaultNamespace)
+	assert.Equal(t, "docs/"+in_mem.DefaultNamespace, GetRestoreProgress().Current)
+	globalRestore.finishNamespace(ctx, collNs)
+	globalRestore.finishCollection("docs")
+	assert.NoError(t, <-waited)
+
+	globalRestore.complete(ctx)
+	p = GetRestoreProgress()
+	assert.Equal(t, RestoreStateComplete, p.State)
+	assert.Equal(t, 1, p.CollectionsRestored)
+	assert.Equal(t, 1, p.NamespacesRestored)
+	assert.Equal(t, 1, p.TextsRestored)
+	assert.Empty(t, p.Pending)
+	assert.Empty(t, p.Current)
+	assert.False(t, IsRestoring())
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/collections/index"
+	"github.com/hypermodeinc/modus/runtime/collections/index/interfaces"
+	"github.com/hypermodeinc/modus/runtime/db"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+)
+
+const defaultSoftDeleteRetentionDays = 30
+
+func Restore(ctx context.Context, collectionName, namespace, key string) (*CollectionMutationResult, error) {
+	collectionName = resolveCollectionName(collectionName)
+
+	if err := checkAccess(ctx, collectionName, manifest.CollectionPermissionWrite); err != nil {
+		return nil, err
+	}
+	if err := waitForRestore(ctx, collectionName); err != nil {
+		return nil, err
+	}
+	defer lockForWrite(ctx)()
+
+	col, err := globalNamespaceManager.findCollection(collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	collNs, err := col.findNamespace(resolveNamespace(ctx, collectionName, namespace))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := collNs.RestoreText(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return NewCollectionMutationResult(collectionName, "restore", "success", []string{key}, ""), nil
+}
+
+// hardDeleteText permanently removes a text and its vectors from the collection namespace.
+func hardDeleteText(ctx context.Context, collNs interfaces.CollectionNamespace, key string) error {
+	textId, err := collNs.GetExternalId(ctx, key)
+	if errors.Is(err, db.ErrCollectionTextNotFound) {
+		// nothing is stored for the key, so there are no vectors to delete
+		return collNs.DeleteText(ctx, key)
+	} else if err != nil {
+		return err
+	}
+	for _, vectorIndex := range collNs.GetVectorIndexMap() {
+		err = vectorIndex.DeleteVector(ctx, textId, key)
+		if err != nil {
+			return err
+		}
+	}
+	return collNs.DeleteText(ctx, key)
+}
+
+// activeItemsFilter returns a search filter that excludes soft-deleted items,
+// or nil if the namespace has no soft-deleted items.
+func activeItemsFilter(ctx context.Context, collNs interfaces.CollectionNamespace) index.SearchFilter {
+	deleted, err := collNs.GetDeletedMap(ctx)
+	if err != nil || len(deleted) == 0 {
+		return nil
+	}
+	return func(_, _ []float32, key string) bool {
+		_, isDeleted := deleted[key]
+		return !isDeleted
+	}
+}
+
+func softDeleteRetention(info *manifest.SoftDeleteInfo) time.Duration {
+	days := defaultSoftDeleteRetentionDays
+	if info.RetentionDays != nil {
+		days = *info.RetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// purgeExpiredTexts permanently removes soft-deleted items whose retention period has passed.
+func (cf *collectionFactory) purgeExpiredTexts(ctx context.Context) {
+	for name, info := range manifestdata.GetManifest().Collections {
+		if info.SoftDelete == nil {
+			continue
+		}
+
+		retention := softDeleteRetention(info.SoftDelete)
+		if retention <= 0 {
+			// retain deleted items indefinitely
+			continue
+		}
+
+		col, err := cf.findCollection(name)
+		if err != nil {
+			continue
+		}
+
+		cutoff := time.Now().Add(-retention)
+		for _, collNs := range col.getCollectionNamespaceMap() {
+			deleted, err := collNs.GetDeletedMap(ctx)
+			if err != nil {
+				logger.Err(ctx, err).Str("collection_name", name).Msg("Failed to get deleted items.")
+				continue
+			}
+
+			for key, deletedAt := range deleted {
+				if deletedAt.After(cutoff) {
+					continue
+				}
+				if err := hardDeleteText(ctx, collNs, key); err != nil {
+					logger.Err(ctx, err).
+						Str("collection_name", name).
+						Str("namespace", collNs.GetNamespace()).
+						Str("key", key).
+						Msg("Failed to purge deleted item.")
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,283 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/collections/index"
+	"github.com/hypermodeinc/modus/runtime/collections/index/interfaces"
+	"github.com/hypermodeinc/modus/runtime/collections/utils"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+const (
+	latencyWindowSize   = 1000
+	recallWindowSize    = 100
+	minRecallSamples    = 10
+	recallTolerance     = 0.02
+	maxEfSearch         = 1024
+	efSearchGrowth      = 1.5
+	efSearchShrinkage   = 0.9
+	recallSampleTimeout = 30 * time.Second
+)
+
+// SearchStats summarizes the recent queries of a collection search method.
+type SearchStats struct {
+	Collection    string   `json:"collection"`
+	SearchMethod  string   `json:"searchMethod"`
+	Queries       int64    `json:"queries"`
+	LatencyP50Ms  float64  `json:"latencyP50Ms"`
+	LatencyP95Ms  float64  `json:"latencyP95Ms"`
+	LatencyP99Ms  float64  `json:"latencyP99Ms"`
+	RecallSamples int      `json:"recallSamples"`
+	Recall        *float64 `json:"recall,omitempty"`
+	TargetRecall  float64  `json:"targetRecall,omitempty"`
+	EfSearch      int      `json:"efSearch,omitempty"`
+}
+
+// An exactSearcher is a vector index that can also find the exact nearest neighbors by brute force,
+// which is used to estimate the recall of its approximate search.
+type exactSearcher interface {
+	ExactSearch(ctx context.Context, query []float32, maxResults int, filter index.SearchFilter) (utils.MaxTupleHeap, error)
+}
+
+// An efSearchTuner is a vector index whose search accuracy can be adjusted.
+type efSearchTuner interface {
+	EfSearch() int
+	SetEfSearch(ef int)
+}
+
+type searchStatsRecorder struct {
+	mu        sync.Mutex
+	queries   int64
+	latencies []time.Duration
+	recalls   []float64
+	efSearch  int
+}
+
+type searchStatsKey struct {
+	collection   string
+	searchMethod string
+}
+
+var searchStats = make(map[searchStatsKey]*searchStatsRecorder)
+var searchStatsMutex sync.Mutex
+
+func getSearchStatsRecorder(collectionName, searchMethod string) *searchStatsRecorder {
+	searchStatsMutex.Lock()
+	defer searchStatsMutex.Unlock()
+
+	key := searchStatsKey{collectionName, searchMethod}
+	r, ok := searchStats[key]
+	if !ok {
+		r = &searchStatsRecorder{}
+		searchStats[key] = r
+	}
+	return r
+}
+
+// GetSearchStats returns statistics for every collection search method that has been queried.
+func GetSearchStats() []*SearchStats {
+	searchStatsMutex.Lock()
+	defer searchStatsMutex.Unlock()
+
+	man := manifestdata.GetManifest()
+	results := make([]*SearchStats, 0, len(searchStats))
+	for key, r := range searchStats {
+		s := r.summarize()
+		s.Collection = key.collection
+		s.SearchMethod = key.searchMethod
+		s.TargetRecall = man.Collections[key.collection].SearchMethods[key.searchMethod].Index.Options.TargetRecall
+		results = append(results, s)
+	}
+
+	slices.SortFunc(results, func(a, b *SearchStats) int {
+		return cmp.Or(cmp.Compare(a.Collection, b.Collection), cmp.Compare(a.SearchMethod, b.SearchMethod))
+	})
+
+	return results
+}
+
+func (r *searchStatsRecorder) summarize() *SearchStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := &SearchStats{
+		Queries:       r.queries,
+		RecallSamples: len(r.recalls),
+		EfSearch:      r.efSearch,
+	}
+
+	latencies := slices.Clone(r.latencies)
+	slices.Sort(latencies)
+	s.LatencyP50Ms = durationMs(percentile(latencies, 50))
+	s.LatencyP95Ms = durationMs(percentile(latencies, 95))
+	s.LatencyP99Ms = durationMs(percentile(latencies, 99))
+
+	if len(r.recalls) > 0 {
+		recall := mean(r.recalls)
+		s.Recall = &recall
+	}
+
+	return s
+}
+
+func (r *searchStatsRecorder) recordLatency(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.queries++
+	r.latencies = appendToWindow(r.latencies, d, latencyWindowSize)
+}
+
+// recordRecall records a recall sample, and returns the mean recall of the recent samples.
+func (r *searchStatsRecorder) recordRecall(recall float64) (float64, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.recalls = appendToWindow(r.recalls, recall, recallWindowSize)
+	return mean(r.recalls), len(r.recalls)
+}
+
+// recordSearch records the latency of a vector index search, and for a sample of searches,
+// estimates its recall by comparing the results with an exact search.
+func recordSearch(ctx context.Context, collectionName, searchMethod string, vectorIndex interfaces.VectorIndex,
+	query []float32, limit int, filter index.SearchFilter, results utils.MaxTupleHeap, duration time.Duration) {
+
+	r := getSearchStatsRecorder(collectionName, searchMethod)
+	r.recordLatency(duration)
+
+	es, ok := vectorIndex.(exactSearcher)
+	if !ok || config.RecallSamplePercent <= 0 || rand.Float64()*100 >= config.RecallSamplePercent {
+		return
+	}
+
+	// the exact search is expensive, so it should not delay the caller
+	go func() {
+		ctx, cancel := context.WithTimeout(wasmhost.ClearSlotHold(context.WithoutCancel(ctx)), recallSampleTimeout)
+		defer cancel()
+
+		exact, err := es.ExactSearch(ctx, query, limit, filter)
+		if err != nil {
+			logger.Warn(ctx).Err(err).
+				Str("collection", collectionName).
+				Str("search_method", searchMethod).
+				Msg("Failed to estimate search recall.")
+			return
+		}
+
+		meanRecall, samples := r.recordRecall(estimateRecall(results, exact))
+		if tuner, ok := vectorIndex.(efSearchTuner); ok {
+			r.autoTune(ctx, collectionName, searchMethod, tuner, meanRecall, samples, limit)
+		}
+	}()
+}
+
+// autoTune adjusts the number of candidates evaluated during search toward the target recall, if one is configured.
+func (r *searchStatsRecorder) autoTune(ctx context.Context, collectionName, searchMethod string, tuner efSearchTuner, meanRecall float64, samples, limit int) {
+	target := manifestdata.GetManifest().Collections[collectionName].SearchMethods[searchMethod].Index.Options.TargetRecall
+	current := tuner.EfSearch()
+
+	r.mu.Lock()
+	r.efSearch = current
+	r.mu.Unlock()
+
+	if target <= 0 || samples < minRecallSamples {
+		return
+	}
+
+	ef := current
+	if meanRecall < target {
+		ef = min(int(math.Ceil(float64(current)*efSearchGrowth)), maxEfSearch)
+	} else if meanRecall > target+recallTolerance {
+		ef = max(int(float64(current)*efSearchShrinkage), limit, 1)
+	}
+	if ef == current {
+		return
+	}
+
+	tuner.SetEfSearch(ef)
+
+	// start a new measurement window for the new setting
+	r.mu.Lock()
+	r.efSearch = ef
+	r.recalls = nil
+	r.mu.Unlock()
+
+	logger.Info(ctx).
+		Str("collection", collectionName).
+		Str("search_method", searchMethod).
+		Float64("recall", meanRecall).
+		Float64("target_recall", target).
+		Int("previous_ef_search", current).
+		Int("ef_search", ef).
+		Msg("Tuned vector index search toward target recall.")
+}
+
+// estimateRecall returns the fraction of the exact nearest neighbors that were found by an approximate search.
+func estimateRecall(approximate, exact utils.MaxTupleHeap) float64 {
+	if len(exact) == 0 {
+		return 1
+	}
+
+	found := make(map[string]bool, len(approximate))
+	for _, r := range approximate {
+		found[r.GetIndex()] = true
+	}
+
+	hits := 0
+	for _, r := range exact {
+		if found[r.GetIndex()] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(exact))
+}
+
+// percentile returns the p-th percentile of sorted values, using the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+func appendToWindow[T any](window []T, value T, size int) []T {
+	if len(window) >= size {
+		window = window[1:]
+	}
+	return append(window, value)
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
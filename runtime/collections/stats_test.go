@@ -0,0 +1,71 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/collections/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateRecall(t *testing.T) {
+	exact := utils.MaxTupleHeap{
+		utils.InitHeapElement(0.1, "a", false),
+		utils.InitHeapElement(0.2, "b", false),
+		utils.InitHeapElement(0.3, "c", false),
+		utils.InitHeapElement(0.4, "d", false),
+	}
+	approximate := utils.MaxTupleHeap{
+		utils.InitHeapElement(0.1, "a", false),
+		utils.InitHeapElement(0.3, "c", false),
+		utils.InitHeapElement(0.5, "e", false),
+	}
+
+	assert.Equal(t, 0.5, estimateRecall(approximate, exact))
+	assert.Equal(t, 1.0, estimateRecall(exact, exact))
+	assert.Equal(t, 1.0, estimateRecall(approximate, nil))
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	assert.Equal(t, 50*time.Millisecond, percentile(sorted, 50))
+	assert.Equal(t, 95*time.Millisecond, percentile(sorted, 95))
+	assert.Equal(t, 99*time.Millisecond, percentile(sorted, 99))
+	assert.Equal(t, time.Duration(0), percentile(nil, 50))
+}
+
+func TestSearchStatsRecorder(t *testing.T) {
+	r := &searchStatsRecorder{}
+	for i := 1; i <= latencyWindowSize+10; i++ {
+		r.recordLatency(time.Millisecond)
+	}
+
+	recall, samples := r.recordRecall(0.8)
+	assert.Equal(t, 0.8, recall)
+	assert.Equal(t, 1, samples)
+
+	recall, samples = r.recordRecall(1.0)
+	assert.InDelta(t, 0.9, recall, 1e-9)
+	assert.Equal(t, 2, samples)
+
+	s := r.summarize()
+	assert.Equal(t, int64(latencyWindowSize+10), s.Queries)
+	assert.Equal(t, 1.0, s.LatencyP99Ms)
+	assert.Equal(t, 2, s.RecallSamples)
+	assert.InDelta(t, 0.9, *s.Recall, 1e-9)
+	assert.Len(t, r.latencies, latencyWindowSize)
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/collections/index/interfaces"
+	"github.com/hypermodeinc/modus/runtime/db"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+)
+
+// SubjectDeletionObject reports the items permanently deleted from one namespace of a collection.
+type SubjectDeletionObject struct {
+	Collection string `json:"collection"`
+	Namespace  string `json:"namespace"`
+	Count      int    `json:"count"`
+	Error      string `json:"error,omitempty"`
+}
+
+// compileKeyPattern converts a key pattern, in which * matches any sequence of characters
+// and ? matches any single character, to a regular expression matching whole keys.
+func compileKeyPattern(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// DeleteSubjectData permanently deletes the items of a data subject from every namespace of every collection,
+// including items that have been soft-deleted.  Items are matched by key pattern, by label, or both,
+// in which case an item must match both.  Cached embeddings of the deleted texts are discarded as well.
+// Collections the caller does not have admin permission for are reported with an error, and left unchanged.
+func DeleteSubjectData(ctx context.Context, keyPattern, label string) ([]*SubjectDeletionObject, error) {
+	if keyPattern == "" && label == "" {
+		return nil, errors.New("a key pattern or label is required")
+	}
+
+	var keyRegex *regexp.Regexp
+	if keyPattern != "" {
+		re, err := compileKeyPattern(keyPattern)
+		if err != nil {
+			return nil, err
+		}
+		keyRegex = re
+	}
+
+	names := make([]string, 0)
+	for name := range manifestdata.GetManifest().Collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	defer lockForWrite(ctx)()
+
+	results := []*SubjectDeletionObject{}
+	for _, name := range names {
+		if err := checkAccess(ctx, name, manifest.CollectionPermissionAdmin); err != nil {
+			results = append(results, &SubjectDeletionObject{Collection: name, Error: err.Error()})
+			continue
+		}
+		if err := waitForRestore(ctx, name); err != nil {
+			results = append(results, &SubjectDeletionObject{Collection: name, Error: err.Error()})
+			continue
+		}
+
+		byNamespace := make(map[string]*SubjectDeletionObject)
+		getResult := func(namespace string) *SubjectDeletionObject {
+			result, ok := byNamespace[namespace]
+			if !ok {
+				result = &SubjectDeletionObject{Collection: name, Namespace: namespace}
+				byNamespace[namespace] = result
+			}
+			return result
+		}
+
+		// delete the items loaded in this process, which removes them from the database as well
+		if col, err := globalNamespaceManager.findCollection(name); err == nil {
+			for namespace, collNs := range col.getCollectionNamespaceMap() {
+				result := getResult(namespace)
+				if err := deleteSubjectItems(ctx, collNs, keyRegex, label, result); err != nil {
+					result.Error = err.Error()
+					logger.Err(ctx, err).
+						Str("collection_name", name).
+						Str("namespace", namespace).
+						Msg("Failed to delete data subject items.")
+				}
+			}
+		}
+
+		// then delete any items that are only stored in the database, such as those of namespaces
+		// that haven't been loaded, or that were written by another replica
+		if err := deleteStoredSubjectItems(ctx, name, keyRegex, label, getResult); err != nil {
+			getResult("").Error = err.Error()
+			logger.Err(ctx, err).
+				Str("collection_name", name).
+				Msg("Failed to delete data subject items from the database.")
+		}
+
+		for _, result := range byNamespace {
+			if result.Count > 0 || result.Error != "" {
+				results = append(results, result)
+			}
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Collection != results[j].Collection {
+			return results[i].Collection < results[j].Collection
+		}
+		return results[i].Namespace < results[j].Namespace
+	})
+
+	return results, nil
+}
+
+func deleteStoredSubjectItems(ctx context.Context, collectionName string, keyRegex *regexp.Regexp, label string, getResult func(namespace string) *SubjectDeletionObject) error {
+	pattern := ""
+	if keyRegex != nil {
+		pattern = keyRegex.String()
+	}
+
+	deleted, err := db.DeleteSubjectCollectionTexts(ctx, collectionName, pattern, label)
+	if db.IsNotConfigured(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, t := range deleted {
+		globalEmbeddingCache.forget(t.Text)
+		getResult(t.Namespace).Count++
+	}
+	return nil
+}
+
+func deleteSubjectItems(ctx context.Context, collNs interfaces.CollectionNamespace, keyRegex *regexp.Regexp, label string, result *SubjectDeletionObject) error {
+	texts, err := collNs.GetTextMap(ctx)
+	if err != nil {
+		return err
+	}
+
+	var labels map[string][]string
+	if label != "" {
+		labels, err = collNs.GetLabelsMap(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	matches := make(map[string]string)
+	for key, text := range texts {
+		if keyRegex != nil && !keyRegex.MatchString(key) {
+			continue
+		}
+		if label != "" && !slices.Contains(labels[key], label) {
+			continue
+		}
+		matches[key] = text
+	}
+
+	for key, text := range matches {
+		if err := hardDeleteText(ctx, collNs, key); err != nil {
+			return err
+		}
+		globalEmbeddingCache.forget(text)
+		result.Count++
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileKeyPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		match   bool
+	}{
+		{"user-42:*", "user-42:doc-1", true},
+		{"user-42:*", "user-421:doc-1", false},
+		{"user-42", "user-42", true},
+		{"user-42", "user-42:doc-1", false},
+		{"user-?:*", "user-7:doc", true},
+		{"user-?:*", "user-77:doc", false},
+		{"a.b*", "a.bc", true},
+		{"a.b*", "axbc", false},
+		{"*[1]", "x[1]", true},
+	}
+
+	for _, tt := range tests {
+		re, err := compileKeyPattern(tt.pattern)
+		require.NoError(t, err)
+		assert.Equal(t, tt.match, re.MatchString(tt.key), "%s ~ %s", tt.pattern, tt.key)
+	}
+}
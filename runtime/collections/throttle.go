@@ -0,0 +1,137 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/models"
+)
+
+const (
+	maxEmbedderConcurrency  = 8
+	maxEmbedderAttempts     = 5
+	defaultRateLimitBackoff = time.Second
+	maxRateLimitBackoff     = time.Minute
+	throttlePollInterval    = 10 * time.Millisecond
+)
+
+// embedderThrottle adapts the number of concurrent calls made to an embedder to the rate limits of its model provider.
+// The limit grows by one for every limit's worth of successful calls, and halves whenever the provider rate limits a call,
+// at which point all calls are paused for the duration the provider requested.
+type embedderThrottle struct {
+	mu         sync.Mutex
+	limit      float64
+	inFlight   int
+	pauseUntil time.Time
+	backoff    time.Duration
+}
+
+var embedderThrottles = make(map[string]*embedderThrottle)
+var embedderThrottlesMutex sync.Mutex
+
+func getEmbedderThrottle(embedder string) *embedderThrottle {
+	embedderThrottlesMutex.Lock()
+	defer embedderThrottlesMutex.Unlock()
+
+	t, ok := embedderThrottles[embedder]
+	if !ok {
+		t = &embedderThrottle{limit: maxEmbedderConcurrency}
+		embedderThrottles[embedder] = t
+	}
+	return t
+}
+
+// acquire waits until a call may be made, or the context is done.
+func (t *embedderThrottle) acquire(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		wait := time.Until(t.pauseUntil)
+		if wait <= 0 && float64(t.inFlight) < t.limit {
+			t.inFlight++
+			t.mu.Unlock()
+			return nil
+		}
+		t.mu.Unlock()
+
+		if wait <= 0 {
+			wait = throttlePollInterval
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release records the outcome of a call made after acquire.
+func (t *embedderThrottle) release(rateLimited bool, retryAfter time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.inFlight--
+	if !rateLimited {
+		t.limit = min(t.limit+1/t.limit, maxEmbedderConcurrency)
+		t.backoff = 0
+		return
+	}
+
+	t.limit = max(t.limit/2, 1)
+
+	// without a requested delay, back off exponentially
+	if retryAfter <= 0 {
+		t.backoff = min(max(t.backoff*2, defaultRateLimitBackoff), maxRateLimitBackoff)
+		retryAfter = t.backoff
+	}
+	if until := time.Now().Add(retryAfter); until.After(t.pauseUntil) {
+		t.pauseUntil = until
+	}
+}
+
+// callEmbedderThrottled calls an embedder, slowing down and retrying when its model provider rate limits the call,
+// rather than failing the whole operation.
+func callEmbedderThrottled(ctx context.Context, embedder string, call func(context.Context) ([][]float32, error)) ([][]float32, error) {
+	t := getEmbedderThrottle(embedder)
+
+	var err error
+	for attempt := 1; attempt <= maxEmbedderAttempts; attempt++ {
+		if err := t.acquire(ctx); err != nil {
+			return nil, err
+		}
+
+		var rateLimited bool
+		var retryAfter time.Duration
+		callCtx := models.ContextWithRateLimitObserver(ctx, func(d time.Duration) {
+			rateLimited = true
+			retryAfter = max(retryAfter, d)
+		})
+
+		var vecs [][]float32
+		vecs, err = call(callCtx)
+		t.release(rateLimited && err != nil, retryAfter)
+
+		if err == nil || !rateLimited {
+			return vecs, err
+		}
+
+		logger.Warn(ctx).
+			Str("embedder", embedder).
+			Int("attempt", attempt).
+			Dur("retry_after_ms", retryAfter).
+			Bool("user_visible", true).
+			Msg("Embedder was rate limited by the model provider.  Slowing down and retrying.")
+	}
+
+	return nil, err
+}
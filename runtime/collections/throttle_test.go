@@ -0,0 +1,78 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/models"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbedderThrottleLimit(t *testing.T) {
+	th := &embedderThrottle{limit: 4}
+
+	assert.NoError(t, th.acquire(context.Background()))
+	th.release(true, time.Millisecond)
+	assert.Equal(t, 2.0, th.limit)
+
+	th.mu.Lock()
+	paused := time.Now().Before(th.pauseUntil)
+	th.mu.Unlock()
+	assert.True(t, paused)
+
+	time.Sleep(2 * time.Millisecond)
+	assert.NoError(t, th.acquire(context.Background()))
+	th.release(false, 0)
+	assert.Equal(t, 2.5, th.limit)
+	assert.Equal(t, 0, th.inFlight)
+}
+
+func TestEmbedderThrottleCanceled(t *testing.T) {
+	th := &embedderThrottle{limit: 1, pauseUntil: time.Now().Add(time.Hour)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, th.acquire(ctx), context.DeadlineExceeded)
+}
+
+func TestCallEmbedderThrottledRetries(t *testing.T) {
+	calls := 0
+	vecs, err := callEmbedderThrottled(context.Background(), "test-retry-embedder", func(ctx context.Context) ([][]float32, error) {
+		calls++
+		if calls < 3 {
+			observer := ctx.Value(utils.RateLimitObserverContextKey).(models.RateLimitObserver)
+			observer(time.Millisecond)
+			return nil, errors.New("rate limited")
+		}
+		return [][]float32{{1, 2}}, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, [][]float32{{1, 2}}, vecs)
+}
+
+func TestCallEmbedderThrottledOtherError(t *testing.T) {
+	calls := 0
+	_, err := callEmbedderThrottled(context.Background(), "test-error-embedder", func(ctx context.Context) ([][]float32, error) {
+		calls++
+		return nil, errors.New("boom")
+	})
+
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+const triggerTimeout = 60 * time.Second
+
+// fireTrigger asynchronously invokes the function declared for the given collection event,
+// passing the collection name, namespace, and affected keys.
+// Changes made by a trigger function do not fire further triggers, to prevent loops.
+func fireTrigger(ctx context.Context, collectionName, namespace, event string, keys []string) {
+	if len(keys) == 0 || ctx.Value(utils.CollectionTriggerContextKey) != nil {
+		return
+	}
+
+	fnName := triggerFunction(manifestdata.GetManifest().Collections[collectionName].Triggers, event)
+	if fnName == "" {
+		return
+	}
+
+	// the trigger outlives the operation that caused it, and is not part of the caller's request
+	ctx = context.WithValue(context.WithoutCancel(ctx), utils.CollectionTriggerContextKey, event)
+	ctx = wasmhost.ClearSlotHold(ctx)
+	ctx = context.WithValue(ctx, utils.CollectionWritesContextKey, nil)
+	ctx = context.WithValue(ctx, utils.PriorityContextKey, utils.PriorityBatch)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, triggerTimeout)
+		defer cancel()
+
+		if _, err := wasmhost.CallFunction(ctx, fnName, collectionName, namespace, keys); err != nil {
+			logger.Err(ctx, err).
+				Str("collection_name", collectionName).
+				Str("namespace", namespace).
+				Str("event", event).
+				Str("function", fnName).
+				Bool("user_visible", true).
+				Msg("Collection trigger function failed.")
+		}
+	}()
+}
+
+func triggerFunction(info *manifest.CollectionTriggersInfo, event string) string {
+	if info == nil {
+		return ""
+	}
+	switch event {
+	case "upsert":
+		return info.OnUpsert
+	case "delete":
+		return info.OnDelete
+	}
+	return ""
+}
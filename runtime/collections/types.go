@@ -18,6 +18,8 @@ func NewCollectionMutationResult(collection, operation, status string, keys []st
 		Operation:  operation,
 		Status:     status,
 		Keys:       keys,
+		Duplicates: []*CollectionDuplicateObject{},
+		PII:        []*CollectionPIIObject{},
 		Error:      err,
 	}
 }
@@ -27,9 +29,41 @@ type CollectionMutationResult struct {
 	Operation  string
 	Status     string
 	Keys       []string
+	Duplicates []*CollectionDuplicateObject
+	PII        []*CollectionPIIObject
 	Error      string
 }
 
+func NewCollectionDuplicateObject(key, duplicateOf string, distance float64, policy string) *CollectionDuplicateObject {
+	return &CollectionDuplicateObject{
+		Key:         key,
+		DuplicateOf: duplicateOf,
+		Distance:    distance,
+		Policy:      policy,
+	}
+}
+
+type CollectionDuplicateObject struct {
+	Key         string
+	DuplicateOf string
+	Distance    float64
+	Policy      string
+}
+
+func NewCollectionPIIObject(key string, types []string, policy string) *CollectionPIIObject {
+	return &CollectionPIIObject{
+		Key:    key,
+		Types:  types,
+		Policy: policy,
+	}
+}
+
+type CollectionPIIObject struct {
+	Key    string
+	Types  []string
+	Policy string
+}
+
 func NewSearchMethodMutationResult(collection, searchMethod, operation, status, err string) *SearchMethodMutationResult {
 	return &SearchMethodMutationResult{
 		Collection:   collection,
@@ -148,3 +182,72 @@ type CollectionClassificationResultObject struct {
 	Distance float64
 	Score    float64
 }
+
+func NewCollectionCountResult(collection, namespace, status string, count int64, exact bool, err string) *CollectionCountResult {
+	return &CollectionCountResult{
+		Collection: collection,
+		Namespace:  namespace,
+		Status:     status,
+		Count:      count,
+		Exact:      exact,
+		Error:      err,
+	}
+}
+
+type CollectionCountResult struct {
+	Collection string
+	Namespace  string
+	Status     string
+	Count      int64
+	Exact      bool
+	Error      string
+}
+
+func NewCollectionRagResult(collection, searchMethod, status, answer string, citations []*CollectionRagCitation, spans []*CollectionRagSpan, err string) *CollectionRagResult {
+	if citations == nil {
+		citations = []*CollectionRagCitation{}
+	}
+	if spans == nil {
+		spans = []*CollectionRagSpan{}
+	}
+	return &CollectionRagResult{
+		Collection:   collection,
+		SearchMethod: searchMethod,
+		Status:       status,
+		Answer:       answer,
+		Citations:    citations,
+		Spans:        spans,
+		Error:        err,
+	}
+}
+
+type CollectionRagResult struct {
+	Collection   string
+	SearchMethod string
+	Status       string
+	Answer       string
+	Citations    []*CollectionRagCitation
+	Spans        []*CollectionRagSpan
+	Error        string
+}
+
+func NewCollectionRagCitation(namespace, key string, score float64) *CollectionRagCitation {
+	return &CollectionRagCitation{
+		Namespace: namespace,
+		Key:       key,
+		Score:     score,
+	}
+}
+
+type CollectionRagCitation struct {
+	Namespace string
+	Key       string
+	Score     float64
+}
+
+type CollectionRagSpan struct {
+	Start     int32
+	End       int32
+	Text      string
+	Citations []*CollectionRagCitation
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+const hllPrecision = 14
+const hllRegisters = 1 << hllPrecision
+
+// HyperLogLog estimates the number of distinct strings added to it using a fixed
+// amount of memory, with a standard error of about 0.8%.
+type HyperLogLog struct {
+	registers [hllRegisters]uint8
+}
+
+func NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{}
+}
+
+func (h *HyperLogLog) Add(value string) {
+	x := hashString(value)
+	idx := x >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+func (h *HyperLogLog) Estimate() uint64 {
+	const m = float64(hllRegisters)
+	alpha := 0.7213 / (1 + 1.079/m)
+
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// linear counting is more accurate for small cardinalities
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+func hashString(value string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	x := h.Sum64()
+
+	// FNV alone doesn't distribute the high bits well enough for short strings,
+	// so mix the result before using it to select registers.
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestHyperLogLogEmpty(t *testing.T) {
+	h := NewHyperLogLog()
+	if got := h.Estimate(); got != 0 {
+		t.Errorf("Expected 0, got %d", got)
+	}
+}
+
+func TestHyperLogLogDuplicates(t *testing.T) {
+	h := NewHyperLogLog()
+	for range 1000 {
+		h.Add("a")
+		h.Add("b")
+		h.Add("c")
+	}
+	if got := h.Estimate(); got != 3 {
+		t.Errorf("Expected 3, got %d", got)
+	}
+}
+
+func TestHyperLogLogEstimate(t *testing.T) {
+	for _, n := range []int{100, 10_000, 100_000, 1_000_000} {
+		h := NewHyperLogLog()
+		for i := range n {
+			h.Add(fmt.Sprintf("label-%d", i))
+		}
+		got := float64(h.Estimate())
+		if relErr := math.Abs(got-float64(n)) / float64(n); relErr > 0.03 {
+			t.Errorf("n=%d: estimate %v has relative error %.4f", n, got, relErr)
+		}
+	}
+}
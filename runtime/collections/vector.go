@@ -13,6 +13,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"slices"
 	"time"
 
@@ -52,9 +53,90 @@ func batchInsertVectorsToMemory(ctx context.Context, vectorIndex interfaces.Vect
 	return nil
 }
 
+// computeEmbeddings returns a vector for each of the texts, computed by the given embedder function.
+// Texts that were recently embedded by the same embedder are served from the embedding cache.
+func computeEmbeddings(ctx context.Context, embedder string, texts []string) ([][]float32, error) {
+	textVecs := make([][]float32, len(texts))
+
+	misses := make([]string, 0, len(texts))
+	missIndexes := make([]int, 0, len(texts))
+	for i, text := range texts {
+		if vec, ok := globalEmbeddingCache.get(embedder, text); ok {
+			textVecs[i] = vec
+		} else {
+			misses = append(misses, text)
+			missIndexes = append(missIndexes, i)
+		}
+	}
+
+	if len(misses) == 0 {
+		return textVecs, nil
+	}
+
+	vecs, err := callEmbedder(ctx, embedder, misses)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, vec := range vecs {
+		textVecs[missIndexes[i]] = vec
+		globalEmbeddingCache.put(embedder, misses[i], vec)
+	}
+
+	return textVecs, nil
+}
+
+// validateQueryVector checks that a precomputed query vector can be searched for in the vector index.
+// An empty index accepts any number of dimensions, since it has nothing to compare against.
+func validateQueryVector(ctx context.Context, vectorIndex interfaces.VectorIndex, searchMethod string, vector []float32) error {
+	for _, v := range vector {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return errors.New("query vector contains a value that is not a finite number")
+		}
+	}
+
+	dims, err := vectorIndex.GetDimensions(ctx)
+	if err != nil {
+		return err
+	}
+	if dims > 0 && dims != len(vector) {
+		return fmt.Errorf("query vector has %d dimensions, but the vectors of search method %s have %d", len(vector), searchMethod, dims)
+	}
+	return nil
+}
+
+// callEmbedder invokes the embedder function directly, without consulting the embedding cache.
+// Calls are throttled when the embedder's model provider is rate limiting them.
+func callEmbedder(ctx context.Context, embedder string, texts []string) ([][]float32, error) {
+	return callEmbedderThrottled(ctx, embedder, func(ctx context.Context) ([][]float32, error) {
+		return invokeEmbedder(ctx, embedder, texts)
+	})
+}
+
+func invokeEmbedder(ctx context.Context, embedder string, texts []string) ([][]float32, error) {
+	callCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	executionInfo, err := wasmhost.CallFunction(callCtx, embedder, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	vecs, err := utils.ConvertToFloat32_2DArray(executionInfo.Result())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vecs) != len(texts) {
+		return nil, fmt.Errorf("mismatch in number of embeddings generated by embedder %s", embedder)
+	}
+
+	return vecs, nil
+}
+
 func cleanAndProcessManifest(ctx context.Context) error {
 	deleteIndexesNotInManifest(ctx, manifestdata.GetManifest())
 	processManifestCollections(ctx, manifestdata.GetManifest())
+	loadAliases(ctx, manifestdata.GetManifest())
 	return nil
 }
 
@@ -77,9 +159,20 @@ func createIndexObject(searchMethod manifest.SearchMethodInfo, searchMethodName
 		return nil, fmt.Errorf("Unknown index type: %s", searchMethod.Index.Type)
 	}
 
+	if staging := searchMethod.Index.Options.Staging; staging != nil {
+		if si, ok := vectorIndex.VectorIndex.(stagingIndex); ok {
+			si.EnableStaging(staging.MergeBudget, staging.MergeBatchSize)
+		}
+	}
+
 	return vectorIndex, nil
 }
 
+// A stagingIndex is a vector index that can buffer inserted vectors, and merge them into the index in the background.
+type stagingIndex interface {
+	EnableStaging(budget float64, batchSize int)
+}
+
 func deleteIndexesNotInManifest(ctx context.Context, man *manifest.Manifest) {
 	for collectionName := range globalNamespaceManager.getNamespaceCollectionFactoryMap() {
 		if _, ok := man.Collections[collectionName]; !ok {
@@ -151,7 +244,7 @@ func processManifestCollections(ctx context.Context, man *manifest.Manifest) {
 			}
 
 			for _, namespace := range namespaces {
-				_, err := col.createCollectionNamespace(namespace, in_mem.NewCollectionNamespace(collectionName, namespace))
+				_, err := col.createCollectionNamespace(namespace, newCollectionNamespace(ctx, collectionName, namespace))
 				if err != nil {
 					logger.Err(ctx, err).
 						Str("collection_name", collectionName).
@@ -161,7 +254,10 @@ func processManifestCollections(ctx context.Context, man *manifest.Manifest) {
 			}
 		}
 		for _, collNs := range col.getCollectionNamespaceMap() {
+			applyTextCompression(ctx, collNs, string(collectionInfo.Compression))
+
 			for searchMethodName, searchMethod := range collectionInfo.SearchMethods {
+				searchMethod = forNamespace(searchMethod, collNs)
 				vi, err := collNs.GetVectorIndex(ctx, searchMethodName)
 
 				// if the index does not exist, create it
@@ -211,6 +307,14 @@ func processTexts(ctx context.Context, col interfaces.CollectionNamespace, vecto
 	if len(keys) != len(texts) {
 		return fmt.Errorf("mismatch in keys and texts")
 	}
+
+	// the search method declares the embedder of each modality, and the index records the current text embedder
+	searchMethod, ok := manifestdata.GetManifest().Collections[col.GetCollectionName()].SearchMethods[vectorIndex.GetSearchMethodName()]
+	if ok {
+		searchMethod = forNamespace(searchMethod, col)
+	} else {
+		searchMethod = manifest.SearchMethodInfo{Embedder: vectorIndex.GetEmbedderName()}
+	}
 	for i := 0; i < len(keys); i += batchSize {
 		end := i + batchSize
 		if end > len(keys) {
@@ -219,24 +323,11 @@ func processTexts(ctx context.Context, col interfaces.CollectionNamespace, vecto
 		keysBatch := keys[i:end]
 		textsBatch := texts[i:end]
 
-		callCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
-		defer cancel()
-		executionInfo, err := wasmhost.CallFunction(callCtx, vectorIndex.GetEmbedderName(), textsBatch)
-		if err != nil {
-			return err
-		}
-
-		result := executionInfo.Result()
-
-		textVecs, err := utils.ConvertToFloat32_2DArray(result)
+		textVecs, err := embedItems(ctx, col.GetCollectionName(), vectorIndex.GetSearchMethodName(), searchMethod, textsBatch)
 		if err != nil {
 			return err
 		}
 
-		if len(textVecs) == 0 {
-			return fmt.Errorf("no vectors returned for texts: %v", textsBatch)
-		}
-
 		textIds := make([]int64, len(keysBatch))
 
 		for i, key := range keysBatch {
@@ -0,0 +1,36 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package collections
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/hypermodeinc/modus/runtime/collections/in_mem/sequential"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateQueryVector(t *testing.T) {
+	ctx := context.Background()
+	vectorIndex := sequential.NewSequentialVectorIndex("searchMethod1", "embedder1")
+
+	// an empty index has nothing to compare against
+	assert.NoError(t, validateQueryVector(ctx, vectorIndex, "searchMethod1", []float32{1, 2}))
+
+	require.NoError(t, vectorIndex.InsertVectorToMemory(ctx, 1, 1, "key1", []float32{0.1, 0.2, 0.3}))
+
+	assert.NoError(t, validateQueryVector(ctx, vectorIndex, "searchMethod1", []float32{1, 2, 3}))
+	assert.ErrorContains(t, validateQueryVector(ctx, vectorIndex, "searchMethod1", []float32{1, 2}), "has 2 dimensions")
+	assert.Error(t, validateQueryVector(ctx, vectorIndex, "searchMethod1", []float32{1, float32(math.NaN()), 3}))
+	assert.Error(t, validateQueryVector(ctx, vectorIndex, "searchMethod1", []float32{1, float32(math.Inf(1)), 3}))
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package concurrency serializes calls to functions that the manifest declares must execute one at a time
+// for each value of a key taken from their arguments, such as a user id.  Calls with the same key wait in a
+// queue, in the order they arrive, so that concurrent mutations of the same state don't interleave.
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/tidwall/gjson"
+)
+
+type keyLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+var locks = make(map[string]*keyLock)
+var mu sync.Mutex
+
+// Acquire waits until the function may execute with the given parameters, and returns a function that must be
+// called when it completes.  Functions that are not serialized by the manifest don't wait.
+func Acquire(ctx context.Context, fnName string, parameters map[string]any) (release func(), err error) {
+	info, ok := manifestdata.GetManifest().Concurrency.SerializationFor(fnName)
+	if !ok {
+		return func() {}, nil
+	}
+
+	key, err := serializationKey(info.SerializeBy, parameters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the serialization key of function %s: %w", fnName, err)
+	}
+
+	release, err = lock(ctx, info.Group+"\x00"+key)
+	if err != nil {
+		logger.Warn(ctx).Str("function", fnName).Str("group", info.Group).Msg("Canceled while waiting for a serialized function call.")
+		return nil, err
+	}
+	return release, nil
+}
+
+// serializationKey returns the JSON representation of the argument value at the given path.
+// A missing or null value is a key like any other, so calls without the value are serialized with each other.
+func serializationKey(path string, parameters map[string]any) (string, error) {
+	data, err := utils.JsonSerialize(parameters)
+	if err != nil {
+		return "", err
+	}
+	if v := gjson.GetBytes(data, path); v.Exists() {
+		return v.Raw, nil
+	}
+	return "null", nil
+}
+
+func lock(ctx context.Context, key string) (func(), error) {
+	mu.Lock()
+	l, ok := locks[key]
+	if !ok {
+		l = &keyLock{ch: make(chan struct{}, 1)}
+		locks[key] = l
+	}
+	l.refs++
+	mu.Unlock()
+
+	unref := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		l.refs--
+		if l.refs == 0 {
+			delete(locks, key)
+		}
+	}
+
+	// Goroutines blocked sending on a channel are woken in the order they started waiting, which makes this a queue.
+	select {
+	case l.ch <- struct{}{}:
+		var once sync.Once
+		return func() {
+			once.Do(func() {
+				<-l.ch
+				unref()
+			})
+		}, nil
+	case <-ctx.Done():
+		unref()
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package concurrency
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializationKey(t *testing.T) {
+	params := map[string]any{
+		"userId": "u1",
+		"input":  map[string]any{"userId": 42, "name": "x"},
+	}
+
+	key, err := serializationKey("userId", params)
+	require.NoError(t, err)
+	require.Equal(t, `"u1"`, key)
+
+	key, err = serializationKey("input.userId", params)
+	require.NoError(t, err)
+	require.Equal(t, "42", key)
+
+	key, err = serializationKey("missing", params)
+	require.NoError(t, err)
+	require.Equal(t, "null", key)
+}
+
+func TestLockSerializesSameKey(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := lock(ctx, "user\x00\"u1\"")
+			require.NoError(t, err)
+			defer release()
+
+			mu.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 1, maxRunning)
+	require.Empty(t, locks)
+}
+
+func TestLockAllowsDifferentKeys(t *testing.T) {
+	ctx := context.Background()
+
+	release1, err := lock(ctx, "a")
+	require.NoError(t, err)
+	defer release1()
+
+	release2, err := lock(ctx, "b")
+	require.NoError(t, err)
+	defer release2()
+}
+
+func TestLockIsCancelled(t *testing.T) {
+	release, err := lock(context.Background(), "k")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = lock(ctx, "k")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+	release() // releasing twice has no effect
+	require.Empty(t, locks)
+}
@@ -21,19 +21,52 @@ var AppPath string
 var UseAwsStorage bool
 var S3Bucket string
 var S3Path string
+var UseGcsStorage bool
+var GcsBucket string
+var GcsPath string
 var RefreshInterval time.Duration
 var UseJsonLogging bool
+var SessionRetention time.Duration
+var SessionMaxMessages int
+var EmbeddingCacheSize int
+var DriftCheckInterval time.Duration
+var DriftSampleSize int
+var RecallSamplePercent float64
+var SandboxWorkers int
+var IsSandboxWorker bool
+var ShadowTrafficPercent float64
+var LazyPlugins bool
+var EagerPlugins string
+var JsonOmitNulls bool
+var JsonPretty bool
+var RequestLogSamplePercent float64
+var RequestLogRedactFields string
+var AccessLog string
+var ApolloTracing bool
+var RunFunction string
+var FunctionArgs string
+var HttpMaxConnsPerHost int
+var HttpMaxIdleConnsPerHost int
+var HttpDnsCacheTtl time.Duration
+var HttpProxy string
+var HttpsProxy string
+var NoProxy string
+var PluginCacheDir string
+var PluginSnapshots bool
+var MaxConsoleOutput int
+var MaxResponseSize int
+var PluginDataDir string
+var Deterministic bool
+var DeterministicSeed uint64
+var DeterministicTime string
+var MaxInstances int
+var PriorityAging time.Duration
+var CancellationGracePeriod time.Duration
+var TokenizersDir string
+var PartialAvailability bool
 
 func parseCommandLineFlags() {
-	flag.StringVar(&AppPath, "appPath", "", "REQUIRED - The path to the Modus app to load and run.")
-	flag.IntVar(&Port, "port", 8686, "The HTTP port to listen on.")
-
-	flag.BoolVar(&UseAwsStorage, "useAwsStorage", false, "Use AWS S3 for storage instead of the local filesystem.")
-	flag.StringVar(&S3Bucket, "s3bucket", "", "The S3 bucket to use, if using AWS storage.")
-	flag.StringVar(&S3Path, "s3path", "", "The path within the S3 bucket to use, if using AWS storage.")
-
-	flag.DurationVar(&RefreshInterval, "refresh", time.Second*5, "The refresh interval to reload any changes.")
-	flag.BoolVar(&UseJsonLogging, "jsonlogs", false, "Use JSON format for logging.")
+	registerFlags(flag.CommandLine)
 
 	var showVersion bool
 	const versionUsage = "Show the Runtime version number and exit."
@@ -47,3 +80,78 @@ func parseCommandLineFlags() {
 		os.Exit(0)
 	}
 }
+
+// registerFlags defines the runtime's settings on the flag set, with their default values.
+func registerFlags(fs *flag.FlagSet) {
+	fs.StringVar(&AppPath, "appPath", "", "REQUIRED - The path to the Modus app to load and run.")
+	fs.IntVar(&Port, "port", 8686, "The HTTP port to listen on.")
+
+	fs.BoolVar(&UseAwsStorage, "useAwsStorage", false, "Use AWS S3 for storage instead of the local filesystem.")
+	fs.StringVar(&S3Bucket, "s3bucket", "", "The S3 bucket to use, if using AWS storage.")
+	fs.StringVar(&S3Path, "s3path", "", "The path within the S3 bucket to use, if using AWS storage.")
+
+	fs.BoolVar(&UseGcsStorage, "useGcsStorage", false, "Use Google Cloud Storage for storage instead of the local filesystem.")
+	fs.StringVar(&GcsBucket, "gcsBucket", "", "The GCS bucket to use, if using GCS storage.")
+	fs.StringVar(&GcsPath, "gcsPath", "", "The path within the GCS bucket to use, if using GCS storage.")
+
+	fs.DurationVar(&RefreshInterval, "refresh", time.Second*5, "The refresh interval to reload any changes.")
+	fs.BoolVar(&UseJsonLogging, "jsonlogs", false, "Use JSON format for logging.")
+
+	fs.DurationVar(&SessionRetention, "sessionRetention", time.Hour*24, "How long session history is retained after the last message is appended.")
+	fs.IntVar(&SessionMaxMessages, "sessionMaxMessages", 100, "The maximum number of messages retained per session.  Use 0 for no limit.")
+
+	fs.IntVar(&EmbeddingCacheSize, "embeddingCacheSize", 10000, "The maximum number of embedding vectors to cache.  Use 0 to disable the cache.")
+	fs.DurationVar(&DriftCheckInterval, "driftCheckInterval", time.Hour, "How often stored collection vectors are checked for drift against the current embedders.  Use 0 to disable.")
+	fs.IntVar(&DriftSampleSize, "driftSampleSize", 20, "The number of items sampled per collection namespace and search method when checking for embedding drift.")
+	fs.Float64Var(&RecallSamplePercent, "recallSamplePercent", 1, "The percentage of collection searches whose recall is estimated by comparing with an exact search.  Use 0 to disable.")
+
+	fs.IntVar(&SandboxWorkers, "sandboxWorkers", 2, "The maximum number of sandboxed worker processes used to execute functions that require process isolation.")
+	fs.BoolVar(&IsSandboxWorker, "sandboxWorker", false, "Run as a sandboxed worker process.  This is used internally by the Runtime, and should not be set manually.")
+
+	fs.Float64Var(&ShadowTrafficPercent, "shadowTrafficPercent", 10, "The percentage of function calls that are duplicated to a shadow plugin build, when one is loaded.  Use 0 to disable.")
+
+	fs.BoolVar(&LazyPlugins, "lazyPlugins", false, "Compile plugins when one of their functions is first called, rather than when they are loaded.")
+	fs.StringVar(&EagerPlugins, "eagerPlugins", "", "A comma-separated list of plugin names that are always compiled when they are loaded, even if lazy plugin loading is enabled.")
+
+	fs.BoolVar(&JsonOmitNulls, "jsonOmitNulls", false, "Omit null fields from GraphQL response data.  Can be overridden per request with the X-Modus-Json-Options header.")
+	fs.BoolVar(&JsonPretty, "jsonPretty", false, "Pretty-print GraphQL responses.  Can be overridden per request with the X-Modus-Json-Options header.")
+
+	fs.Float64Var(&RequestLogSamplePercent, "requestLogSamplePercent", 0, "The percentage of GraphQL requests whose operation, variables, and response summary are logged.  Use 0 to disable.")
+	fs.StringVar(&RequestLogRedactFields, "requestLogRedactFields", "password,secret,token,apiKey,authorization", "A comma-separated list of field names whose values are redacted from logged requests and responses.")
+	fs.StringVar(&AccessLog, "accessLog", "", "The file that every HTTP request is recorded in, as a line of JSON with the status, duration, bytes, caller, and a fingerprint of any GraphQL operation.  Use - for stdout.  Disabled by default.")
+	fs.BoolVar(&ApolloTracing, "apolloTracing", false, "Include the timing of each field resolved by the Runtime in GraphQL responses, as a tracing extension in the Apollo tracing format.")
+
+	fs.StringVar(&RunFunction, "runFunction", "", "Call the named function, print its result, and exit, instead of starting the HTTP server.")
+	fs.StringVar(&FunctionArgs, "functionArgs", "", "The path to a JSON file containing the arguments for -runFunction, or - to read them from stdin.")
+
+	fs.IntVar(&HttpMaxConnsPerHost, "httpMaxConnsPerHost", 0, "The maximum number of connections to each host for HTTP requests made by functions.  Use 0 for no limit.")
+	fs.IntVar(&HttpMaxIdleConnsPerHost, "httpMaxIdleConnsPerHost", 32, "The maximum number of idle connections kept open to each host for HTTP requests made by functions.")
+	fs.DurationVar(&HttpDnsCacheTtl, "httpDnsCacheTtl", time.Second*30, "How long DNS lookups are cached for HTTP requests made by functions.  Use 0 to disable.")
+
+	fs.StringVar(&HttpProxy, "httpProxy", "", "The proxy to use for outbound HTTP requests, such as http://proxy:3128 or socks5://proxy:1080.  Defaults to the HTTP_PROXY environment variable.")
+	fs.StringVar(&HttpsProxy, "httpsProxy", "", "The proxy to use for outbound HTTPS requests.  Defaults to -httpProxy if set, or otherwise the HTTPS_PROXY environment variable.")
+	fs.StringVar(&NoProxy, "noProxy", "", "A comma-separated list of hosts, domains, and CIDR ranges that bypass the proxy.  Defaults to the NO_PROXY environment variable.")
+
+	fs.StringVar(&PluginCacheDir, "pluginCacheDir", "", "The directory where large files downloaded from remote storage are cached, keyed by digest.  Defaults to a directory within the user's cache directory.")
+
+	fs.BoolVar(&PluginSnapshots, "pluginSnapshots", true, "Restore instances of plugins that declare support for it from a snapshot of their memory, rather than running their top-level code for every instance.")
+
+	fs.IntVar(&MaxConsoleOutput, "maxConsoleOutput", 1024*1024, "The maximum number of bytes captured from each of stdout and stderr for a single function invocation.  Can be overridden per plugin in the manifest.  Use 0 for no limit.")
+
+	fs.IntVar(&MaxResponseSize, "maxResponseSize", 100*1024*1024, "The maximum number of bytes read from wasm memory for the response of a single function invocation.  Can be overridden per function in the manifest, which can also truncate list responses instead of failing.  Use 0 for no limit.")
+
+	fs.StringVar(&PluginDataDir, "pluginDataDir", "", "The directory that holds the persistent directories that plugins are given access to in the manifest.  Defaults to a directory within the user's cache directory.")
+
+	fs.BoolVar(&Deterministic, "deterministic", false, "Give every function invocation a fixed clock and a seeded random source, so that results are reproducible in tests.  Do not use in production.")
+	fs.Uint64Var(&DeterministicSeed, "deterministicSeed", 1, "The seed for the random source of each function invocation, when -deterministic is set.")
+	fs.StringVar(&DeterministicTime, "deterministicTime", "2024-01-01T00:00:00Z", "The RFC 3339 time at which the clock of each function invocation starts, when -deterministic is set.")
+
+	fs.IntVar(&MaxInstances, "maxInstances", 0, "The maximum number of plugin module instances that can exist at once.  When all are in use, waiting function calls are served by priority class, and fairly across plugins.  Use 0 for no limit.")
+	fs.DurationVar(&PriorityAging, "priorityAging", time.Second*10, "How long a function call waits for a module instance before it is promoted to the next priority class, so that batch calls are not starved by higher priority traffic.  Use 0 to disable.")
+
+	fs.DurationVar(&CancellationGracePeriod, "cancellationGracePeriod", time.Second*2, "How long a function may keep running after its request is cancelled or times out, so that it can return cleanly, before it is terminated.  Use 0 to terminate it immediately.")
+
+	fs.StringVar(&TokenizersDir, "tokenizersDir", "", "The directory that the rank files of model tokenizers are read from.  Files that aren't present are downloaded there when first needed.  Defaults to a directory within the user's cache directory.")
+
+	fs.BoolVar(&PartialAvailability, "partialAvailability", false, "Serve functions while collections are being restored at startup.  Until a collection is restored, operations on it fail rather than wait, and the runtime reports that it is ready.")
+}
@@ -10,6 +10,7 @@
 package config
 
 import (
+	"flag"
 	"os"
 
 	"github.com/fatih/color"
@@ -24,3 +25,17 @@ func Initialize() {
 	parseCommandLineFlags()
 	readEnvironmentVariables()
 }
+
+// InitializeEmbedded initializes the configuration of a runtime that is embedded in another program.
+// The settings are parsed from the given arguments, which use the same names as the command line flags,
+// rather than from the program's own command line.
+func InitializeEmbedded(args []string) error {
+	fs := flag.NewFlagSet("modus", flag.ContinueOnError)
+	registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	readEnvironmentVariables()
+	return nil
+}
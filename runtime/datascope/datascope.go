@@ -0,0 +1,78 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package datascope scopes the data that plugins store to the plugin that stores it, when the manifest
+// declares plugin mode.  The namespaces of collections and the keys of sessions are prefixed by the name
+// of the plugin, so that plugins developed by different teams cannot accidentally use each other's data.
+// Calls that are not made by a plugin, such as those of the admin API, see the data as it is stored.
+package datascope
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/plugins"
+)
+
+// separator separates the name of the plugin from the namespace or key that it is scoped to.
+const separator = "::"
+
+// Namespace returns the stored namespace of a collection that a plugin refers to by the given name.
+func Namespace(ctx context.Context, collectionName, namespace string) string {
+	if prefix, ok := collectionPrefix(ctx, collectionName); ok {
+		return prefix + namespace
+	}
+	return namespace
+}
+
+// Visible returns the name by which a plugin refers to a stored namespace of a collection,
+// and false if the namespace is scoped to another plugin.
+func Visible(ctx context.Context, collectionName, namespace string) (string, bool) {
+	if prefix, ok := collectionPrefix(ctx, collectionName); ok {
+		return strings.CutPrefix(namespace, prefix)
+	}
+	return namespace, true
+}
+
+// Unscoped returns the name of a stored namespace of a collection without the plugin that it is scoped to,
+// which is the name that the manifest uses for it.
+func Unscoped(collectionName, namespace string) string {
+	scope := manifestdata.GetManifest().DataScope
+	if scope.IsSharedCollection(collectionName) {
+		return namespace
+	}
+	if _, ns, found := strings.Cut(namespace, separator); found {
+		return ns
+	}
+	return namespace
+}
+
+// Key returns the stored key of a session that a plugin refers to by the given key.
+func Key(ctx context.Context, key string) string {
+	scope := manifestdata.GetManifest().DataScope
+	if scope.IsSharedKey(key) {
+		return key
+	}
+	if p, ok := plugins.GetPluginFromContext(ctx); ok {
+		return p.Name() + separator + key
+	}
+	return key
+}
+
+func collectionPrefix(ctx context.Context, collectionName string) (string, bool) {
+	scope := manifestdata.GetManifest().DataScope
+	if scope.IsSharedCollection(collectionName) {
+		return "", false
+	}
+	if p, ok := plugins.GetPluginFromContext(ctx); ok {
+		return p.Name() + separator, true
+	}
+	return "", false
+}
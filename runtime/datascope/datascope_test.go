@@ -0,0 +1,71 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package datascope
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/lib/metadata"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/plugins"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withPlugin(name string) context.Context {
+	p := &plugins.Plugin{Metadata: &metadata.Metadata{Plugin: name + "@1.0.0"}}
+	return context.WithValue(context.Background(), utils.PluginContextKey, p)
+}
+
+func TestSharedMode(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{})
+	ctx := withPlugin("team-a")
+
+	assert.Equal(t, "ns1", Namespace(ctx, "collection1", "ns1"))
+	assert.Equal(t, "session1", Key(ctx, "session1"))
+
+	ns, ok := Visible(ctx, "collection1", "team-b::ns1")
+	assert.True(t, ok)
+	assert.Equal(t, "team-b::ns1", ns)
+}
+
+func TestPluginMode(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{
+		DataScope: &manifest.DataScopeInfo{
+			Mode:              manifest.DataScopeModePlugin,
+			SharedCollections: []string{"shared"},
+			SharedKeys:        []string{"global-*"},
+		},
+	})
+	defer manifestdata.SetManifest(&manifest.Manifest{})
+	ctx := withPlugin("team-a")
+
+	assert.Equal(t, "team-a::ns1", Namespace(ctx, "collection1", "ns1"))
+	assert.Equal(t, "team-a::", Namespace(ctx, "collection1", ""))
+	assert.Equal(t, "ns1", Namespace(ctx, "shared", "ns1"))
+	assert.Equal(t, "ns1", Namespace(context.Background(), "collection1", "ns1"))
+
+	ns, ok := Visible(ctx, "collection1", "team-a::ns1")
+	assert.True(t, ok)
+	assert.Equal(t, "ns1", ns)
+	_, ok = Visible(ctx, "collection1", "team-b::ns1")
+	assert.False(t, ok)
+
+	assert.Equal(t, "ns1", Unscoped("collection1", "team-a::ns1"))
+	assert.Equal(t, "a::b", Unscoped("collection1", "team-a::a::b"))
+	assert.Equal(t, "team-a::ns1", Unscoped("shared", "team-a::ns1"))
+
+	assert.Equal(t, "team-a::session1", Key(ctx, "session1"))
+	assert.Equal(t, "global-session", Key(ctx, "global-session"))
+	assert.Equal(t, "session1", Key(context.Background(), "session1"))
+}
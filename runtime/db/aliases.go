@@ -0,0 +1,56 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const collectionAliasesTable = "collection_aliases"
+
+// GetCollectionAliases returns the collection that each stored alias points to.
+func GetCollectionAliases(ctx context.Context) (map[string]string, error) {
+	aliases := make(map[string]string)
+	err := WithTx(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf("SELECT alias, collection FROM %s", collectionAliasesTable)
+		rows, err := tx.Query(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var alias, collection string
+			if err := rows.Scan(&alias, &collection); err != nil {
+				return err
+			}
+			aliases[alias] = collection
+		}
+		return rows.Err()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
+// WriteCollectionAlias stores the collection that an alias points to, replacing any previous target.
+func WriteCollectionAlias(ctx context.Context, alias, collectionName string) error {
+	return WithTx(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf(`INSERT INTO %s (alias, collection) VALUES ($1, $2)
+ON CONFLICT (alias) DO UPDATE SET collection = EXCLUDED.collection, updated_at = now()`, collectionAliasesTable)
+		_, err := tx.Exec(ctx, query, alias, collectionName)
+		return err
+	})
+}
@@ -38,6 +38,9 @@ var globalRuntimePostgresWriter *runtimePostgresWriter = &runtimePostgresWriter{
 
 var errDbNotConfigured = errors.New("database not configured")
 
+// ErrCollectionTextNotFound is returned when a collection key has no text in the database.
+var ErrCollectionTextNotFound = errors.New("collection text not found")
+
 const batchSize = 100
 const chanSize = 10000
 
@@ -45,6 +48,7 @@ const pluginsTable = "plugins"
 const inferencesTable = "inferences"
 const collectionTextsTable = "collection_texts"
 const collectionVectorsTable = "collection_vectors"
+const feedbackTable = "feedback"
 
 const inferenceRefresherInterval = 5 * time.Second
 
@@ -57,16 +61,22 @@ type runtimePostgresWriter struct {
 }
 
 type inferenceHistory struct {
-	model    *manifest.ModelInfo
-	input    any
-	output   any
-	start    time.Time
-	end      time.Time
-	pluginId *string
-	function *string
+	model       *manifest.ModelInfo
+	input       any
+	output      any
+	start       time.Time
+	end         time.Time
+	pluginId    *string
+	function    *string
+	executionId *string
 }
 
 func (w *runtimePostgresWriter) GetPool(ctx context.Context) (*pgxpool.Pool, error) {
+	// A sandboxed worker process leaves all database work to the main process.
+	if config.IsSandboxWorker {
+		return nil, errDbNotConfigured
+	}
+
 	var initErr error
 	w.once.Do(func() {
 		var connStr string
@@ -280,17 +290,35 @@ func WriteInferenceHistory(ctx context.Context, model *manifest.ModelInfo, input
 		function = &functionName
 	}
 
+	var executionId *string
+	if id, ok := ctx.Value(utils.ExecutionIdContextKey).(string); ok {
+		executionId = &id
+	}
+
 	globalRuntimePostgresWriter.Write(inferenceHistory{
-		model:    model,
-		input:    input,
-		output:   output,
-		start:    start,
-		end:      end,
-		pluginId: pluginId,
-		function: function,
+		model:       model,
+		input:       input,
+		output:      output,
+		start:       start,
+		end:         end,
+		pluginId:    pluginId,
+		function:    function,
+		executionId: executionId,
 	})
 }
 
+func WriteFeedback(ctx context.Context, executionId string, rating int, comment, source string) {
+	err := WithTx(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf("INSERT INTO %s (id, execution_id, rating, comment, source) VALUES ($1, $2, $3, $4, $5)", feedbackTable)
+		_, err := tx.Exec(ctx, query, utils.GenerateUUIDv7(), executionId, rating, utils.NilIfEmpty(comment), source)
+		return err
+	})
+
+	if err != nil {
+		logDbWarningOrError(ctx, err, "Feedback not written to database.")
+	}
+}
+
 func GetUniqueNamespaces(ctx context.Context, collectionName string) ([]string, error) {
 	var namespaces []string
 	err := WithTx(ctx, func(tx pgx.Tx) error {
@@ -422,6 +450,61 @@ func DeleteCollectionText(ctx context.Context, collectionName, namespace, key st
 	})
 }
 
+func SoftDeleteCollectionText(ctx context.Context, collectionName, namespace, key string) (deletedAt time.Time, err error) {
+	err = WithTx(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf("UPDATE %s SET deleted_at = now() WHERE collection = $1 AND namespace = $2 AND key = $3 RETURNING deleted_at", collectionTextsTable)
+		row := tx.QueryRow(ctx, query, collectionName, namespace, key)
+		return row.Scan(&deletedAt)
+	})
+
+	if err != nil {
+		return time.Time{}, err
+	}
+	return deletedAt, nil
+}
+
+func RestoreCollectionText(ctx context.Context, collectionName, namespace, key string) error {
+	return WithTx(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE collection = $1 AND namespace = $2 AND key = $3", collectionTextsTable)
+		_, err := tx.Exec(ctx, query, collectionName, namespace, key)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func QueryDeletedCollectionTexts(ctx context.Context, collectionName, namespace string) (map[string]time.Time, error) {
+	deleted := make(map[string]time.Time)
+	err := WithTx(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf("SELECT key, deleted_at FROM %s WHERE collection = $1 AND namespace = $2 AND deleted_at IS NOT NULL", collectionTextsTable)
+		rows, err := tx.Query(ctx, query, collectionName, namespace)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var key string
+			var deletedAt time.Time
+			if err := rows.Scan(&key, &deletedAt); err != nil {
+				return err
+			}
+			deleted[key] = deletedAt
+		}
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
 func WriteCollectionVectors(ctx context.Context, searchMethodName string, textIds []int64, vectors [][]float32) ([]int64, []string, error) {
 	if len(textIds) != len(vectors) {
 		return nil, nil, errors.New("textIds and vectors must have the same length")
@@ -534,6 +617,25 @@ func DeleteCollectionVector(ctx context.Context, searchMethodName string, textId
 	})
 }
 
+// QueryCollectionTextId returns the id of the current text for a key.  Text ids are allocated by a database sequence,
+// so every runtime instance sees the same id for a text, and ids are never reused across restarts.
+func QueryCollectionTextId(ctx context.Context, collection, namespace, key string) (int64, error) {
+	var id int64
+	err := WithTx(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf("SELECT id FROM %s WHERE collection = $1 AND namespace = $2 AND key = $3 ORDER BY id DESC LIMIT 1", collectionTextsTable)
+		err := tx.QueryRow(ctx, query, collection, namespace, key).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCollectionTextNotFound
+		}
+		return err
+	})
+
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
 func QueryCollectionTextsFromCheckpoint(ctx context.Context, collection, namespace string, textCheckpointId int64) ([]int64, []string, []string, [][]string, error) {
 	var textIds []int64
 	var keys []string
@@ -631,8 +733,8 @@ func WriteInferenceHistoryToDB(ctx context.Context, batch []inferenceHistory) {
 				return err
 			}
 			query := fmt.Sprintf(`INSERT INTO %s
-(id, model_hash, input, output, started_at, duration_ms, plugin_id, function)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+(id, model_hash, input, output, started_at, duration_ms, plugin_id, function, execution_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 `, inferencesTable)
 			args := []any{
 				utils.GenerateUUIDv7(),
@@ -643,6 +745,7 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 				data.end.Sub(data.start).Milliseconds(),
 				data.pluginId,
 				data.function,
+				data.executionId,
 			}
 			b.Queue(query, args...)
 		}
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE collection_texts DROP COLUMN IF EXISTS deleted_at;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE collection_texts ADD COLUMN deleted_at TIMESTAMPTZ;
+
+COMMIT;
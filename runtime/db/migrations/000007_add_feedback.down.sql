@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TABLE IF EXISTS feedback;
+
+DROP INDEX IF EXISTS inferences_execution_id_idx;
+
+ALTER TABLE inferences DROP COLUMN IF EXISTS execution_id;
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+ALTER TABLE inferences ADD COLUMN execution_id TEXT;
+
+CREATE INDEX IF NOT EXISTS inferences_execution_id_idx ON inferences (execution_id);
+
+CREATE TABLE IF NOT EXISTS "feedback" (
+    "id" UUID PRIMARY KEY,
+    "execution_id" TEXT NOT NULL,
+    "rating" INTEGER NOT NULL,
+    "comment" TEXT,
+    "source" TEXT NOT NULL,
+    "created_at" TIMESTAMP(3) WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS feedback_execution_id_idx ON feedback (execution_id);
+
+COMMIT;
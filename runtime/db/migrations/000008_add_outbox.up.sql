@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS "outbox" (
+    "id" UUID PRIMARY KEY,
+    "execution_id" TEXT NOT NULL,
+    "function" TEXT,
+    "request" JSONB NOT NULL,
+    "status" TEXT NOT NULL DEFAULT 'pending',
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "last_error" TEXT,
+    "created_at" TIMESTAMP(3) WITH TIME ZONE NOT NULL DEFAULT now(),
+    "next_attempt_at" TIMESTAMP(3) WITH TIME ZONE NOT NULL DEFAULT now(),
+    "delivered_at" TIMESTAMP(3) WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS outbox_status_next_attempt_at_idx ON outbox (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS outbox_execution_id_idx ON outbox (execution_id);
+
+COMMIT;
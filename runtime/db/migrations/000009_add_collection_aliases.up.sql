@@ -0,0 +1,9 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS "collection_aliases" (
+    "alias" TEXT PRIMARY KEY,
+    "collection" TEXT NOT NULL,
+    "updated_at" TIMESTAMP(3) WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+COMMIT;
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const outboxTable = "outbox"
+
+const (
+	outboxStatusPending   = "pending"
+	outboxStatusDelivered = "delivered"
+	outboxStatusFailed    = "failed"
+)
+
+// OutboxRecord is a side-effect message staged by a function, as stored in the outbox table.
+type OutboxRecord struct {
+	Id          string
+	ExecutionId string
+	Function    string
+	Request     []byte
+	Attempts    int
+}
+
+// IsNotConfigured reports whether the error is because the runtime has no database configured.
+func IsNotConfigured(err error) bool {
+	return errors.Is(err, errDbNotConfigured)
+}
+
+// WriteOutboxRecords stores the messages of a completed invocation, ready for dispatch.
+func WriteOutboxRecords(ctx context.Context, records []OutboxRecord) error {
+	return WithTx(ctx, func(tx pgx.Tx) error {
+		b := &pgx.Batch{}
+		query := fmt.Sprintf("INSERT INTO %s (id, execution_id, function, request) VALUES ($1, $2, $3, $4)", outboxTable)
+		for _, r := range records {
+			b.Queue(query, r.Id, r.ExecutionId, r.Function, r.Request)
+		}
+
+		br := tx.SendBatch(ctx, b)
+		defer br.Close()
+		for range records {
+			if _, err := br.Exec(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ClaimOutboxRecords returns up to limit pending messages that are due for delivery.  Each claimed message is leased
+// until its next attempt time is pushed forward by the lease duration, so that other runtime instances skip it.
+func ClaimOutboxRecords(ctx context.Context, limit int, lease time.Duration) ([]OutboxRecord, error) {
+	var records []OutboxRecord
+	err := WithTx(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf(`UPDATE %[1]s SET next_attempt_at = now() + $2 * interval '1 millisecond'
+WHERE id IN (
+	SELECT id FROM %[1]s WHERE status = $3 AND next_attempt_at <= now()
+	ORDER BY next_attempt_at LIMIT $1 FOR UPDATE SKIP LOCKED
+)
+RETURNING id, execution_id, COALESCE(function, ''), request, attempts`, outboxTable)
+
+		rows, err := tx.Query(ctx, query, limit, lease.Milliseconds(), outboxStatusPending)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var r OutboxRecord
+			if err := rows.Scan(&r.Id, &r.ExecutionId, &r.Function, &r.Request, &r.Attempts); err != nil {
+				return err
+			}
+			records = append(records, r)
+		}
+		return rows.Err()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// CompleteOutboxRecord marks a message as delivered.
+func CompleteOutboxRecord(ctx context.Context, id string) error {
+	return WithTx(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf("UPDATE %s SET status = $2, attempts = attempts + 1, delivered_at = now(), last_error = NULL WHERE id = $1", outboxTable)
+		_, err := tx.Exec(ctx, query, id, outboxStatusDelivered)
+		return err
+	})
+}
+
+// RetryOutboxRecord records a failed delivery attempt, and schedules the next one.
+// When failed is true, no further attempts are made.
+func RetryOutboxRecord(ctx context.Context, id string, attempts int, nextAttempt time.Time, lastError string, failed bool) error {
+	status := outboxStatusPending
+	if failed {
+		status = outboxStatusFailed
+	}
+
+	return WithTx(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf("UPDATE %s SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5 WHERE id = $1", outboxTable)
+		_, err := tx.Exec(ctx, query, id, status, attempts, nextAttempt, lastError)
+		return err
+	})
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ScrubSubjectData deletes the stored inference history records whose input or output mention any of the
+// identifiers of a data subject, along with the feedback and outbox records of those executions, and any other
+// feedback and outbox records that mention the identifiers.  It returns the number of records deleted per table.
+func ScrubSubjectData(ctx context.Context, identifiers []string) (map[string]int64, error) {
+	patterns := make([]string, len(identifiers))
+	for i, id := range identifiers {
+		patterns[i] = "%" + escapeLikePattern(id) + "%"
+	}
+
+	counts := make(map[string]int64)
+	err := WithTx(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf(`DELETE FROM %s WHERE input::text ILIKE ANY($1) OR output::text ILIKE ANY($1) RETURNING COALESCE(execution_id, '')`, inferencesTable)
+		rows, err := tx.Query(ctx, query, patterns)
+		if err != nil {
+			return err
+		}
+		executionIds, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return err
+		}
+		counts[inferencesTable] = int64(len(executionIds))
+
+		query = fmt.Sprintf("DELETE FROM %s WHERE comment ILIKE ANY($1) OR execution_id = ANY($2)", feedbackTable)
+		ct, err := tx.Exec(ctx, query, patterns, executionIds)
+		if err != nil {
+			return err
+		}
+		counts[feedbackTable] = ct.RowsAffected()
+
+		query = fmt.Sprintf("DELETE FROM %s WHERE request::text ILIKE ANY($1) OR execution_id = ANY($2)", outboxTable)
+		ct, err = tx.Exec(ctx, query, patterns, executionIds)
+		if err != nil {
+			return err
+		}
+		counts[outboxTable] = ct.RowsAffected()
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// DeletedSubjectText is a collection text deleted by DeleteSubjectCollectionTexts.
+type DeletedSubjectText struct {
+	Namespace string
+	Key       string
+	Text      string
+}
+
+// DeleteSubjectCollectionTexts deletes the texts of a collection, in every namespace, whose keys match the
+// regular expression and that have the label.  Either may be empty, but not both.  Vectors of the texts are
+// deleted with them.  This finds the items of a data subject that are stored, but not loaded in this process.
+func DeleteSubjectCollectionTexts(ctx context.Context, collectionName, keyRegex, label string) ([]DeletedSubjectText, error) {
+	conditions := []string{"collection = $1"}
+	args := []any{collectionName}
+	if keyRegex != "" {
+		args = append(args, keyRegex)
+		conditions = append(conditions, fmt.Sprintf("key ~ $%d", len(args)))
+	}
+	if label != "" {
+		args = append(args, label)
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(labels)", len(args)))
+	}
+	if len(args) == 1 {
+		return nil, fmt.Errorf("a key pattern or label is required")
+	}
+
+	var deleted []DeletedSubjectText
+	err := WithTx(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s RETURNING namespace, key, text", collectionTextsTable, strings.Join(conditions, " AND "))
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		deleted, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (DeletedSubjectText, error) {
+			var t DeletedSubjectText
+			err := row.Scan(&t.Namespace, &t.Key, &t.Text)
+			return t, err
+		})
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+func escapeLikePattern(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/hypermodeinc/modus/lib/manifest"
 	"github.com/hypermodeinc/modus/runtime/manifestdata"
 	"github.com/hypermodeinc/modus/runtime/secrets"
+	"github.com/hypermodeinc/modus/runtime/tlsconfig"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -98,36 +99,33 @@ func createConnector(ctx context.Context, dgName string) (*dgraphConnector, erro
 		return nil, fmt.Errorf("dgraph connection [%s] has empty GrpcTarget", dgName)
 	}
 
-	var opts []grpc.DialOption
-
-	if connection.Key != "" {
-		conKey, err := secrets.ApplySecretsToString(ctx, info, connection.Key)
+	var creds credentials.TransportCredentials
+	if connection.TLS != nil {
+		tlsConfig, err := tlsconfig.ForConnection(ctx, info, connection.TLS)
 		if err != nil {
 			return nil, err
 		}
-
+		creds = credentials.NewTLS(tlsConfig)
+	} else if connection.Key != "" || strings.Split(connection.GrpcTarget, ":")[0] != "localhost" {
 		pool, err := x509.SystemCertPool()
 		if err != nil {
 			return nil, err
 		}
-		creds := credentials.NewClientTLSFromCert(pool, "")
-		opts = []grpc.DialOption{
-			grpc.WithTransportCredentials(creds),
-			grpc.WithPerRPCCredentials(&authCreds{conKey}),
-		}
-	} else if strings.Split(connection.GrpcTarget, ":")[0] != "localhost" {
-		pool, err := x509.SystemCertPool()
+		creds = credentials.NewClientTLSFromCert(pool, "")
+	} else {
+		creds = insecure.NewCredentials()
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+	}
+
+	if connection.Key != "" {
+		conKey, err := secrets.ApplySecretsToString(ctx, info, connection.Key)
 		if err != nil {
 			return nil, err
 		}
-		creds := credentials.NewClientTLSFromCert(pool, "")
-		opts = []grpc.DialOption{
-			grpc.WithTransportCredentials(creds),
-		}
-	} else {
-		opts = []grpc.DialOption{
-			grpc.WithTransportCredentials(insecure.NewCredentials()),
-		}
+		opts = append(opts, grpc.WithPerRPCCredentials(&authCreds{conKey}))
 	}
 
 	conn, err := grpc.NewClient(connection.GrpcTarget, opts...)
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package directives runs the middlewares for the custom GraphQL directives that plugin authors attach to functions.
+// Each middleware is registered by the name of its directive, and is run before a field with that directive is resolved,
+// and every time a function with that directive, or that returns a type with it, is called.
+// Directives without a registered middleware are only written to the schema, for use by clients and gateways.
+package directives
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hypermodeinc/modus/runtime/graphql/schemagen"
+	"github.com/hypermodeinc/modus/runtime/middleware"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// Handler is a middleware for a directive.  It is given the arguments of the directive,
+// and returns an error to prevent the field from being resolved.
+type Handler func(ctx context.Context, args map[string]any) error
+
+var handlers = map[string]Handler{
+	"auth": authorize,
+}
+var mu sync.RWMutex
+
+// Register sets the middleware for the directive with the given name, replacing any existing one.
+func Register(name string, handler Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers[name] = handler
+}
+
+// Validator checks the arguments of a directive when the schema is generated, so that a directive that its
+// middleware can't evaluate is rejected before any field with it can be resolved.
+type Validator func(args map[string]any) error
+
+var validators = map[string]Validator{
+	"auth": validateAuth,
+}
+
+// RegisterValidator sets the validator for the directive with the given name, replacing any existing one.
+func RegisterValidator(name string, validator Validator) {
+	mu.Lock()
+	defer mu.Unlock()
+	validators[name] = validator
+}
+
+// RejectedError is returned when the middleware of a directive prevents a field or function from being resolved.
+type RejectedError struct {
+	Directive string
+	Target    string
+	Err       error
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("@%s directive of %s: %v", e.Directive, e.Target, e.Err)
+}
+
+func (e *RejectedError) Unwrap() error {
+	return e.Err
+}
+
+// Apply runs the middlewares for the directives of a field, in the order the directives are applied.
+func Apply(ctx context.Context, fieldName string, directives []*schemagen.Directive) error {
+	return apply(ctx, "field "+fieldName, directives)
+}
+
+// ApplyToFunction runs the middlewares for the directives of a function, in the order the directives are applied.
+// It is used every time the function is called, whether it resolves a root field, a nested field or a pipeline stage.
+func ApplyToFunction(ctx context.Context, fnName string, directives []*schemagen.Directive) error {
+	return apply(ctx, "function "+fnName, directives)
+}
+
+func apply(ctx context.Context, target string, directives []*schemagen.Directive) error {
+	if len(directives) == 0 {
+		return nil
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, d := range directives {
+		if h, ok := handlers[d.Name]; ok {
+			if err := h(ctx, d.Args()); err != nil {
+				return &RejectedError{d.Name, target, err}
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the arguments of each directive that has a validator, and returns an error for the first
+// directive that its middleware can't evaluate.
+func Validate(target string, directives []*schemagen.Directive) error {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, d := range directives {
+		if v, ok := validators[d.Name]; ok {
+			if err := v(d.Args()); err != nil {
+				return fmt.Errorf("@%s directive of %s: %w", d.Name, target, err)
+			}
+		}
+	}
+	return nil
+}
+
+// authorize is the middleware for the @auth directive.  It requires the caller's token to grant the role given
+// in the role argument, through its roles claim, and the scope given in the scope argument, through its scope claim.
+// A directive that gives neither, or gives any other arguments, denies every caller rather than allowing them.
+func authorize(ctx context.Context, args map[string]any) error {
+	if err := validateAuth(args); err != nil {
+		return err
+	}
+
+	claims := middleware.GetJWTClaims(ctx)
+	if role, ok := args["role"].(string); ok && !slices.Contains(claimValues(claims, "roles"), role) {
+		return fmt.Errorf("the %s role is required", role)
+	}
+	if scope, ok := args["scope"].(string); ok && !slices.Contains(claimValues(claims, "scope"), scope) {
+		return fmt.Errorf("the %s scope is required", scope)
+	}
+	return nil
+}
+
+// validateAuth requires the @auth directive to have a role or a scope, given as strings, and no other arguments.
+func validateAuth(args map[string]any) error {
+	if len(args) == 0 {
+		return errors.New("a role or a scope argument is required")
+	}
+	for _, name := range slices.Sorted(maps.Keys(args)) {
+		value := args[name]
+		if name != "role" && name != "scope" {
+			return fmt.Errorf("unsupported argument %s, only role and scope are supported", name)
+		}
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("the %s argument must be a string", name)
+		}
+	}
+	return nil
+}
+
+// claimValues reads the values of a JWT claim that may be either a space-separated string, or an array of strings.
+func claimValues(claimsJson, claim string) []string {
+	if claimsJson == "" {
+		return nil
+	}
+
+	var claims map[string]any
+	if err := utils.JsonDeserialize([]byte(claimsJson), &claims); err != nil {
+		return nil
+	}
+
+	switch v := claims[claim].(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
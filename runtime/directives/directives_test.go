@@ -0,0 +1,88 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package directives_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hypermodeinc/modus/runtime/directives"
+	"github.com/hypermodeinc/modus/runtime/graphql/schemagen"
+	"github.com/hypermodeinc/modus/runtime/middleware"
+
+	"github.com/stretchr/testify/require"
+)
+
+func authDirective(args ...*schemagen.DirectiveArgument) []*schemagen.Directive {
+	return []*schemagen.Directive{{Name: "auth", Arguments: args}}
+}
+
+func TestAuthDirective(t *testing.T) {
+	ctx := middleware.ContextWithJWTClaims(context.Background(), `{"roles":["admin","reader"],"scope":"people:read people:write"}`)
+
+	require.NoError(t, directives.Apply(ctx, "person", authDirective(&schemagen.DirectiveArgument{Name: "role", Value: "admin"})))
+	require.NoError(t, directives.Apply(ctx, "person", authDirective(
+		&schemagen.DirectiveArgument{Name: "role", Value: "reader"},
+		&schemagen.DirectiveArgument{Name: "scope", Value: "people:write"},
+	)))
+
+	err := directives.Apply(ctx, "person", authDirective(&schemagen.DirectiveArgument{Name: "role", Value: "owner"}))
+	require.EqualError(t, err, "@auth directive of field person: the owner role is required")
+
+	err = directives.Apply(ctx, "person", authDirective(&schemagen.DirectiveArgument{Name: "scope", Value: "people:delete"}))
+	require.EqualError(t, err, "@auth directive of field person: the people:delete scope is required")
+
+	err = directives.Apply(context.Background(), "person", authDirective(&schemagen.DirectiveArgument{Name: "role", Value: "admin"}))
+	require.Error(t, err)
+}
+
+func TestRegisteredDirective(t *testing.T) {
+	var got map[string]any
+	directives.Register("limit", func(ctx context.Context, args map[string]any) error {
+		got = args
+		if args["max"].(int64) < 1 {
+			return errors.New("limit reached")
+		}
+		return nil
+	})
+
+	limit := func(max int64) []*schemagen.Directive {
+		return []*schemagen.Directive{
+			{Name: "unhandled"},
+			{Name: "limit", Arguments: []*schemagen.DirectiveArgument{{Name: "max", Value: max}}},
+		}
+	}
+
+	require.NoError(t, directives.Apply(context.Background(), "people", limit(5)))
+	require.Equal(t, map[string]any{"max": int64(5)}, got)
+	require.EqualError(t, directives.Apply(context.Background(), "people", limit(0)), "@limit directive of field people: limit reached")
+}
+
+func TestAuthDirectiveWithoutRoleOrScope(t *testing.T) {
+	ctx := middleware.ContextWithJWTClaims(context.Background(), `{"roles":["admin"],"scope":"people:read"}`)
+
+	err := directives.ApplyToFunction(ctx, "getPerson", authDirective())
+	require.EqualError(t, err, "@auth directive of function getPerson: a role or a scope argument is required")
+
+	err = directives.ApplyToFunction(ctx, "getPerson", authDirective(&schemagen.DirectiveArgument{Name: "roles", Value: []any{"admin"}}))
+	require.EqualError(t, err, "@auth directive of function getPerson: unsupported argument roles, only role and scope are supported")
+
+	err = directives.ApplyToFunction(ctx, "getPerson", authDirective(&schemagen.DirectiveArgument{Name: "role", Value: []any{"admin"}}))
+	require.EqualError(t, err, "@auth directive of function getPerson: the role argument must be a string")
+
+	var rejected *directives.RejectedError
+	require.ErrorAs(t, err, &rejected)
+	require.Equal(t, "auth", rejected.Directive)
+
+	err = directives.Validate("function getPeople", authDirective(&schemagen.DirectiveArgument{Name: "scope", Value: int64(1)}))
+	require.EqualError(t, err, "@auth directive of function getPeople: the scope argument must be a string")
+	require.NoError(t, directives.Validate("function getPeople", authDirective(&schemagen.DirectiveArgument{Name: "scope", Value: "people:read"})))
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package embedded runs the Modus runtime inside another Go program, without starting its HTTP server.
+// The program can load plugins, call their functions and use collections directly, and can serve the
+// runtime's endpoints from its own HTTP server with Handler.
+//
+// The runtime's configuration and services are global to the process, so only one runtime can be
+// started in a process.
+package embedded
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hypermodeinc/modus/runtime/app"
+	"github.com/hypermodeinc/modus/runtime/collections"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/envfiles"
+	"github.com/hypermodeinc/modus/runtime/functions"
+	"github.com/hypermodeinc/modus/runtime/httpserver"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/pluginmanager"
+	"github.com/hypermodeinc/modus/runtime/services"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+// Runtime is a Modus runtime embedded in the program.
+type Runtime struct {
+	ctx     context.Context
+	host    wasmhost.WasmHost
+	handler http.Handler
+	sentry  bool
+
+	functionsReady chan struct{}
+	closeOnce      sync.Once
+}
+
+var started atomic.Bool
+
+// New starts a runtime with the given options.  Plugins in the app directory are loaded in the background,
+// so use WaitForFunctions before calling their functions.
+func New(ctx context.Context, opts ...Option) (*Runtime, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.appPath == "" {
+		return nil, errors.New("an app path is required")
+	}
+
+	if !started.CompareAndSwap(false, true) {
+		return nil, errors.New("a runtime has already been started in this process")
+	}
+
+	args := append([]string{"-appPath=" + o.appPath}, o.settings...)
+	if o.jsonLogs {
+		args = append(args, "-jsonlogs")
+	}
+	if err := config.InitializeEmbedded(args); err != nil {
+		started.Store(false)
+		return nil, err
+	}
+
+	log := logger.InitializeWithOutput(o.logOutput)
+	log.Info().
+		Str("version", config.GetVersionNumber()).
+		Str("environment", config.GetEnvironmentName()).
+		Msg("Starting embedded Modus Runtime.")
+
+	if err := envfiles.LoadEnvFiles(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to load environment files.")
+	}
+
+	if o.sentry {
+		utils.InitSentry(app.GetRootSourcePath())
+	}
+
+	r := &Runtime{
+		sentry:         o.sentry,
+		functionsReady: make(chan struct{}),
+	}
+
+	var readyOnce sync.Once
+	functions.RegisterFunctionsLoadedCallback(func(ctx context.Context) {
+		readyOnce.Do(func() { close(r.functionsReady) })
+	})
+
+	// the handler reacts to the manifest, so it is created before the manifest is loaded
+	r.handler = httpserver.GetMainHandler(httpserver.WithDefaultGraphQLHandler())
+
+	r.ctx = services.Start(ctx)
+	r.host = wasmhost.GetWasmHost(r.ctx)
+	return r, nil
+}
+
+// Close stops the runtime's services.  The runtime cannot be used after it is closed.
+func (r *Runtime) Close(ctx context.Context) {
+	r.closeOnce.Do(func() {
+		app.SetShuttingDown()
+		services.Stop(r.Context(ctx))
+		if r.sentry {
+			utils.FlushSentryEvents()
+		}
+	})
+}
+
+// Context returns a context for calling into the runtime's packages directly, such as collections,
+// carrying the values from the given context.
+func (r *Runtime) Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, utils.WasmHostContextKey, r.host)
+}
+
+// WaitForFunctions blocks until functions have been registered, from the plugins in the app directory
+// or by LoadPlugin, or until the context is done.
+func (r *Runtime) WaitForFunctions(ctx context.Context) error {
+	select {
+	case <-r.functionsReady:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Handler returns the runtime's HTTP endpoints, including its GraphQL endpoints, health and metrics,
+// for serving from the program's own HTTP server.
+func (r *Runtime) Handler() http.Handler {
+	return r.handler
+}
+
+// LoadPlugin loads a plugin from the content of its wasm file, and registers its functions.
+// The name identifies the plugin, and a plugin loaded again with the same name replaces it.
+func (r *Runtime) LoadPlugin(ctx context.Context, name string, wasm []byte) error {
+	return pluginmanager.LoadPlugin(r.Context(ctx), pluginFileName(name), wasm)
+}
+
+// UnloadPlugin unloads a plugin that was loaded with LoadPlugin, and unregisters its functions.
+func (r *Runtime) UnloadPlugin(ctx context.Context, name string) error {
+	return pluginmanager.UnloadPlugin(r.Context(ctx), pluginFileName(name))
+}
+
+func pluginFileName(name string) string {
+	return name + ".wasm"
+}
+
+// CallFunction calls the named function with the parameters, by parameter name, and returns its result.
+func (r *Runtime) CallFunction(ctx context.Context, fnName string, parameters map[string]any) (any, error) {
+	ctx = r.Context(ctx)
+
+	fnInfo, err := r.host.GetFunctionInfo(fnName)
+	if err != nil {
+		return nil, err
+	}
+
+	execInfo, err := r.host.CallFunction(ctx, fnInfo, parameters)
+	if err != nil {
+		return nil, err
+	}
+	return execInfo.Result(), nil
+}
+
+// Upsert adds or updates texts in a collection, by key.
+func (r *Runtime) Upsert(ctx context.Context, collectionName, namespace string, keys, texts []string, labels [][]string) (*collections.CollectionMutationResult, error) {
+	return collections.Upsert(r.Context(ctx), collectionName, namespace, keys, texts, labels)
+}
+
+// Delete removes a text from a collection, by key.
+func (r *Runtime) Delete(ctx context.Context, collectionName, namespace, key string) (*collections.CollectionMutationResult, error) {
+	return collections.Delete(r.Context(ctx), collectionName, namespace, key)
+}
+
+// Search returns the texts of a collection nearest to the given text, using the search method.
+func (r *Runtime) Search(ctx context.Context, collectionName string, namespaces []string, searchMethod, text string, limit int32, returnText bool) (*collections.CollectionSearchResult, error) {
+	return collections.Search(r.Context(ctx), collectionName, namespaces, searchMethod, text, limit, returnText)
+}
+
+// GetText returns the text of a collection with the given key.
+func (r *Runtime) GetText(ctx context.Context, collectionName, namespace, key string) (string, error) {
+	return collections.GetText(r.Context(ctx), collectionName, namespace, key)
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package embedded
+
+import (
+	"io"
+	"os"
+)
+
+// Option configures an embedded runtime.
+type Option func(*options)
+
+type options struct {
+	appPath   string
+	settings  []string
+	logOutput io.Writer
+	jsonLogs  bool
+	sentry    bool
+}
+
+func defaultOptions() *options {
+	return &options{
+		logOutput: os.Stderr,
+	}
+}
+
+// WithAppPath sets the directory that the app's manifest, environment files and plugins are loaded from.
+// It is required, even if all plugins are loaded with LoadPlugin.
+func WithAppPath(path string) Option {
+	return func(o *options) {
+		o.appPath = path
+	}
+}
+
+// WithSettings sets any of the runtime's other settings, in the same form as its command line flags,
+// such as "-maxInstances=8".
+func WithSettings(args ...string) Option {
+	return func(o *options) {
+		o.settings = append(o.settings, args...)
+	}
+}
+
+// WithLogOutput sets where the runtime writes its logs.  The default is stderr.
+func WithLogOutput(w io.Writer) Option {
+	return func(o *options) {
+		o.logOutput = w
+	}
+}
+
+// WithJsonLogs writes logs as JSON, rather than in console format.
+func WithJsonLogs() Option {
+	return func(o *options) {
+		o.jsonLogs = true
+	}
+}
+
+// WithSentry reports errors and traces to Sentry, when the SENTRY_DSN environment variable is set.
+func WithSentry() Option {
+	return func(o *options) {
+		o.sentry = true
+	}
+}
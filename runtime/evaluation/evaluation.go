@@ -0,0 +1,240 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package evaluation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/collections"
+	"github.com/hypermodeinc/modus/runtime/functions"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/storage"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+const defaultK = 10
+
+// An EvaluationCase is a single query, with the keys that a search should retrieve for it,
+// and optionally the answer that the generation function is expected to give.
+type EvaluationCase struct {
+	Query          string   `json:"query"`
+	ExpectedKeys   []string `json:"expectedKeys,omitempty"`
+	ExpectedAnswer string   `json:"expectedAnswer,omitempty"`
+}
+
+// EvaluationOptions describes an evaluation job.  The cases are given either inline,
+// or as the name of a JSON Lines file in the app's storage, with one EvaluationCase per line.
+//
+// If a generation function is given, it is called for each case with the query and, if it takes
+// a second parameter, the texts that were retrieved.  It must return a string answer.
+type EvaluationOptions struct {
+	Collection   string           `json:"collection"`
+	SearchMethod string           `json:"searchMethod"`
+	Namespaces   []string         `json:"namespaces,omitempty"`
+	K            int              `json:"k,omitempty"`
+	Dataset      string           `json:"dataset,omitempty"`
+	Cases        []EvaluationCase `json:"cases,omitempty"`
+	Function     string           `json:"function,omitempty"`
+}
+
+type CaseResult struct {
+	Index            int      `json:"index"`
+	Query            string   `json:"query"`
+	RetrievedKeys    []string `json:"retrievedKeys"`
+	Recall           *float64 `json:"recall,omitempty"`
+	ReciprocalRank   *float64 `json:"reciprocalRank,omitempty"`
+	Answer           string   `json:"answer,omitempty"`
+	AnswerSimilarity *float64 `json:"answerSimilarity,omitempty"`
+	Error            string   `json:"error,omitempty"`
+}
+
+type EvaluationReport struct {
+	Collection           string        `json:"collection"`
+	SearchMethod         string        `json:"searchMethod"`
+	K                    int           `json:"k"`
+	Total                int           `json:"total"`
+	MeanRecall           *float64      `json:"meanRecall,omitempty"`
+	MeanReciprocalRank   *float64      `json:"meanReciprocalRank,omitempty"`
+	MeanAnswerSimilarity *float64      `json:"meanAnswerSimilarity,omitempty"`
+	Errors               int           `json:"errors"`
+	Cases                []*CaseResult `json:"cases"`
+}
+
+var wasmHost wasmhost.WasmHost
+
+func Initialize(ctx context.Context) {
+	wasmHost = wasmhost.GetWasmHost(ctx)
+}
+
+// Evaluate runs each case of a dataset as a search of the collection, and optionally through a generation function,
+// and reports the retrieval quality as recall@k and mean reciprocal rank, and the answer quality as the
+// similarity of the generated answers to the expected answers.
+func Evaluate(ctx context.Context, opts *EvaluationOptions) (*EvaluationReport, error) {
+	if opts.Collection == "" || opts.SearchMethod == "" {
+		return nil, errors.New("a collection and search method are required")
+	}
+
+	cases := opts.Cases
+	if opts.Dataset != "" {
+		var err error
+		cases, err = readDataset(ctx, opts.Dataset)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(cases) == 0 {
+		return nil, errors.New("no cases to evaluate")
+	}
+
+	k := opts.K
+	if k <= 0 {
+		k = defaultK
+	}
+
+	ctx = context.WithValue(ctx, utils.WasmHostContextKey, wasmHost)
+	ctx = context.WithValue(ctx, utils.PriorityContextKey, utils.PriorityBatch)
+
+	var genFnInfo functions.FunctionInfo
+	if opts.Function != "" {
+		var err error
+		genFnInfo, err = getGenerationFunction(opts.Function)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := &EvaluationReport{
+		Collection:   opts.Collection,
+		SearchMethod: opts.SearchMethod,
+		K:            k,
+		Cases:        make([]*CaseResult, 0, len(cases)),
+	}
+
+	for i, c := range cases {
+		res, err := collections.Search(ctx, opts.Collection, opts.Namespaces, opts.SearchMethod, c.Query, int32(k), genFnInfo != nil)
+		if err != nil {
+			return nil, fmt.Errorf("search failed for case %d: %w", i, err)
+		}
+
+		keys := make([]string, len(res.Objects))
+		texts := make([]string, len(res.Objects))
+		for j, obj := range res.Objects {
+			keys[j] = obj.Key
+			texts[j] = obj.Text
+		}
+
+		result := &CaseResult{
+			Index:         i,
+			Query:         c.Query,
+			RetrievedKeys: keys,
+		}
+		if len(c.ExpectedKeys) > 0 {
+			recall := recallAtK(keys, c.ExpectedKeys, k)
+			rr := reciprocalRank(keys, c.ExpectedKeys)
+			result.Recall = &recall
+			result.ReciprocalRank = &rr
+		}
+
+		if genFnInfo != nil {
+			if err := generateAnswer(ctx, genFnInfo, c, texts, opts, result); err != nil {
+				result.Error = err.Error()
+			}
+		}
+
+		report.Cases = append(report.Cases, result)
+	}
+
+	summarize(report)
+
+	logger.Info(ctx).
+		Str("collection", report.Collection).
+		Str("search_method", report.SearchMethod).
+		Int("k", report.K).
+		Int("total", report.Total).
+		Int("errors", report.Errors).
+		Msg("Collection evaluation completed.")
+
+	return report, nil
+}
+
+func getGenerationFunction(fnName string) (functions.FunctionInfo, error) {
+	fnInfo, err := wasmHost.GetFunctionInfo(fnName)
+	if err != nil {
+		return nil, err
+	}
+
+	fn := fnInfo.Metadata()
+	if len(fn.Parameters) < 1 || len(fn.Parameters) > 2 || len(fn.Results) != 1 {
+		return nil, fmt.Errorf("generation function %s must take a query and optionally the retrieved texts, and return an answer", fnName)
+	}
+
+	return fnInfo, nil
+}
+
+func generateAnswer(ctx context.Context, fnInfo functions.FunctionInfo, c EvaluationCase, texts []string, opts *EvaluationOptions, result *CaseResult) error {
+	fn := fnInfo.Metadata()
+	parameters := map[string]any{fn.Parameters[0].Name: c.Query}
+	if len(fn.Parameters) == 2 {
+		parameters[fn.Parameters[1].Name] = texts
+	}
+
+	execInfo, err := wasmHost.CallFunction(ctx, fnInfo, parameters)
+	if err != nil {
+		return err
+	}
+
+	answer, ok := execInfo.Result().(string)
+	if !ok {
+		return fmt.Errorf("generation function %s did not return a string", fn.Name)
+	}
+	result.Answer = answer
+
+	if c.ExpectedAnswer != "" {
+		similarity, err := collections.TextSimilarity(ctx, opts.Collection, opts.SearchMethod, answer, c.ExpectedAnswer)
+		if err != nil {
+			return err
+		}
+		result.AnswerSimilarity = &similarity
+	}
+
+	return nil
+}
+
+func readDataset(ctx context.Context, filename string) ([]EvaluationCase, error) {
+	content, err := storage.GetFileContents(ctx, filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset %s: %w", filename, err)
+	}
+
+	cases := []EvaluationCase{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var c EvaluationCase
+		if err := utils.JsonDeserialize(data, &c); err != nil {
+			return nil, fmt.Errorf("invalid case on line %d of dataset %s: %w", line, filename, err)
+		}
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dataset %s: %w", filename, err)
+	}
+
+	return cases, nil
+}
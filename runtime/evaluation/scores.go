@@ -0,0 +1,84 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package evaluation
+
+// recallAtK returns the fraction of the expected keys that are found in the first k retrieved keys.
+func recallAtK(retrieved, expected []string, k int) float64 {
+	if len(expected) == 0 {
+		return 0
+	}
+
+	top := make(map[string]bool, k)
+	for _, key := range retrieved[:min(k, len(retrieved))] {
+		top[key] = true
+	}
+
+	found := 0
+	for _, key := range expected {
+		if top[key] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(expected))
+}
+
+// reciprocalRank returns the reciprocal of the rank of the first retrieved key that is expected,
+// or zero if none of the expected keys were retrieved.
+func reciprocalRank(retrieved, expected []string) float64 {
+	want := make(map[string]bool, len(expected))
+	for _, key := range expected {
+		want[key] = true
+	}
+
+	for i, key := range retrieved {
+		if want[key] {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// summarize computes the report totals and means from its case results.
+// Each mean only includes the cases that have the corresponding score.
+func summarize(report *EvaluationReport) {
+	var recall, rr, similarity []float64
+	for _, c := range report.Cases {
+		report.Total++
+		if c.Error != "" {
+			report.Errors++
+		}
+		if c.Recall != nil {
+			recall = append(recall, *c.Recall)
+		}
+		if c.ReciprocalRank != nil {
+			rr = append(rr, *c.ReciprocalRank)
+		}
+		if c.AnswerSimilarity != nil {
+			similarity = append(similarity, *c.AnswerSimilarity)
+		}
+	}
+
+	report.MeanRecall = mean(recall)
+	report.MeanReciprocalRank = mean(rr)
+	report.MeanAnswerSimilarity = mean(similarity)
+}
+
+func mean(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	m := sum / float64(len(values))
+	return &m
+}
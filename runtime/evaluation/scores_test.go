@@ -0,0 +1,65 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package evaluation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecallAtK(t *testing.T) {
+	retrieved := []string{"a", "b", "c", "d"}
+
+	assert.Equal(t, 1.0, recallAtK(retrieved, []string{"a", "c"}, 4))
+	assert.Equal(t, 0.5, recallAtK(retrieved, []string{"a", "d"}, 2))
+	assert.Equal(t, 0.0, recallAtK(retrieved, []string{"x"}, 4))
+	assert.Equal(t, 0.5, recallAtK(retrieved[:1], []string{"a", "b"}, 10))
+}
+
+func TestReciprocalRank(t *testing.T) {
+	retrieved := []string{"a", "b", "c", "d"}
+
+	assert.Equal(t, 1.0, reciprocalRank(retrieved, []string{"a"}))
+	assert.Equal(t, 1.0/3, reciprocalRank(retrieved, []string{"c", "d"}))
+	assert.Equal(t, 0.0, reciprocalRank(retrieved, []string{"x"}))
+}
+
+func TestSummarize(t *testing.T) {
+	one, half, zero := 1.0, 0.5, 0.0
+	report := &EvaluationReport{
+		Cases: []*CaseResult{
+			{Recall: &one, ReciprocalRank: &one, AnswerSimilarity: &half},
+			{Recall: &zero, ReciprocalRank: &zero, Error: "generation failed"},
+			{},
+		},
+	}
+
+	summarize(report)
+
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 1, report.Errors)
+	require.NotNil(t, report.MeanRecall)
+	assert.Equal(t, 0.5, *report.MeanRecall)
+	require.NotNil(t, report.MeanReciprocalRank)
+	assert.Equal(t, 0.5, *report.MeanReciprocalRank)
+	require.NotNil(t, report.MeanAnswerSimilarity)
+	assert.Equal(t, 0.5, *report.MeanAnswerSimilarity)
+}
+
+func TestSummarize_NoScores(t *testing.T) {
+	report := &EvaluationReport{Cases: []*CaseResult{{}}}
+	summarize(report)
+
+	assert.Nil(t, report.MeanRecall)
+	assert.Nil(t, report.MeanReciprocalRank)
+	assert.Nil(t, report.MeanAnswerSimilarity)
+}
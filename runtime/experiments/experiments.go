@@ -0,0 +1,191 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package experiments
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/metrics"
+	"github.com/hypermodeinc/modus/runtime/middleware"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// An Assignment is the variant of an experiment that serves a call.
+type Assignment struct {
+	Experiment string
+	Variant    *manifest.ExperimentVariantInfo
+}
+
+// ForModel returns the caller's assignment for the experiment on the named model,
+// or nil if there is no experiment on the model.
+func ForModel(ctx context.Context, modelName string) *Assignment {
+	exp := findExperiment(func(e *manifest.ExperimentInfo) bool { return e.Model == modelName })
+	if exp == nil {
+		return nil
+	}
+	return assign(ctx, exp)
+}
+
+// ForPrompt returns the caller's assignment for the experiment on the named prompt,
+// or nil if there is no experiment on the prompt.
+func ForPrompt(ctx context.Context, promptName string) *Assignment {
+	exp := findExperiment(func(e *manifest.ExperimentInfo) bool { return e.Prompt == promptName })
+	if exp == nil {
+		return nil
+	}
+	return assign(ctx, exp)
+}
+
+// findExperiment returns the first experiment, by name, that satisfies the predicate.
+func findExperiment(matches func(*manifest.ExperimentInfo) bool) *manifest.ExperimentInfo {
+	experiments := manifestdata.GetManifest().Experiments
+	if len(experiments) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(experiments))
+	for name := range experiments {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		exp := experiments[name]
+		if matches(&exp) {
+			return &exp
+		}
+	}
+	return nil
+}
+
+// assign chooses a variant of the experiment for the caller.  The choice is a stable hash of the caller's
+// identity, so a caller is assigned to the same variant on every call for as long as the variants are unchanged.
+func assign(ctx context.Context, exp *manifest.ExperimentInfo) *Assignment {
+	identity := callerIdentity(ctx, exp.GetStickyClaim())
+	variant := chooseVariant(exp, identity)
+	if variant == nil {
+		return nil
+	}
+
+	metrics.ExperimentAssignmentsNum.WithLabelValues(exp.Name, variant.Name).Inc()
+	return &Assignment{Experiment: exp.Name, Variant: variant}
+}
+
+func chooseVariant(exp *manifest.ExperimentInfo, identity string) *manifest.ExperimentVariantInfo {
+	total := 0
+	for _, v := range exp.Variants {
+		total += max(v.Weight, 0)
+	}
+	if total == 0 {
+		return nil
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(exp.Name))
+	h.Write([]byte{0})
+	h.Write([]byte(identity))
+	point := int(h.Sum64() % uint64(total))
+
+	for i := range exp.Variants {
+		v := &exp.Variants[i]
+		point -= max(v.Weight, 0)
+		if point < 0 {
+			return v
+		}
+	}
+	return nil
+}
+
+// callerIdentity identifies the caller by the given JWT claim, or by their address if the claim is not present.
+// If neither is available, each execution is treated as a separate caller.
+func callerIdentity(ctx context.Context, claim string) string {
+	if claimsJson := middleware.GetJWTClaims(ctx); claimsJson != "" {
+		var claims map[string]any
+		if err := utils.JsonDeserialize([]byte(claimsJson), &claims); err == nil {
+			if v, ok := claims[claim]; ok && v != nil {
+				return fmt.Sprint(v)
+			}
+		}
+	}
+
+	if md, ok := ctx.Value(utils.CallerMetadataContextKey).(map[string]string); ok {
+		if addr := md["remote_addr"]; addr != "" {
+			if host, _, err := net.SplitHostPort(addr); err == nil {
+				return host
+			}
+			return addr
+		}
+	}
+
+	if id, ok := ctx.Value(utils.ExecutionIdContextKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// RecordModelOutcome records the latency, token usage, and success of a model invocation made for the assignment.
+func (a *Assignment) RecordModelOutcome(duration time.Duration, output string, err error) {
+	if a == nil {
+		return
+	}
+
+	metrics.ExperimentLatencySeconds.WithLabelValues(a.Experiment, a.Variant.Name).Observe(duration.Seconds())
+	if err != nil {
+		metrics.ExperimentErrorsNum.WithLabelValues(a.Experiment, a.Variant.Name).Inc()
+		return
+	}
+
+	var res struct {
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if utils.JsonDeserialize([]byte(output), &res) == nil {
+		if res.Usage.PromptTokens > 0 {
+			metrics.ExperimentTokensNum.WithLabelValues(a.Experiment, a.Variant.Name, "prompt").Add(float64(res.Usage.PromptTokens))
+		}
+		if res.Usage.CompletionTokens > 0 {
+			metrics.ExperimentTokensNum.WithLabelValues(a.Experiment, a.Variant.Name, "completion").Add(float64(res.Usage.CompletionTokens))
+		}
+	}
+}
+
+// RecordFeedback records a score for the outcome of the named experiment, as judged by the function or its caller.
+// The score is attributed to the variant the caller is assigned to.  Scores are expected to be between 0 and 1.
+func RecordFeedback(ctx context.Context, experimentName string, score float64) (bool, error) {
+	exp, ok := manifestdata.GetManifest().Experiments[experimentName]
+	if !ok {
+		return false, fmt.Errorf("experiment %s was not found", experimentName)
+	}
+
+	variant := chooseVariant(&exp, callerIdentity(ctx, exp.GetStickyClaim()))
+	if variant == nil {
+		return false, fmt.Errorf("experiment %s has no variants with weight", experimentName)
+	}
+
+	metrics.ExperimentFeedbackScore.WithLabelValues(exp.Name, variant.Name).Observe(score)
+
+	logger.Debug(ctx).
+		Str("experiment", exp.Name).
+		Str("variant", variant.Name).
+		Float64("score", score).
+		Msg("Recorded experiment feedback.")
+
+	return true, nil
+}
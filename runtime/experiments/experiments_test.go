@@ -0,0 +1,108 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package experiments
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/middleware"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	manifestdata.SetManifest(&manifest.Manifest{
+		Experiments: map[string]manifest.ExperimentInfo{
+			"model-test": {
+				Name:  "model-test",
+				Model: "model-a",
+				Variants: []manifest.ExperimentVariantInfo{
+					{Name: "control", Weight: 50},
+					{Name: "candidate", Weight: 50, Model: "model-b"},
+				},
+			},
+			"prompt-test": {
+				Name:        "prompt-test",
+				Prompt:      "greeting",
+				StickyClaim: "tenant",
+				Variants: []manifest.ExperimentVariantInfo{
+					{Name: "v1", Weight: 1, Version: "1"},
+					{Name: "v2", Weight: 0, Version: "2"},
+				},
+			},
+		},
+	})
+
+	os.Exit(m.Run())
+}
+
+func TestForModel(t *testing.T) {
+	a := ForModel(context.Background(), "model-a")
+	require.NotNil(t, a)
+	assert.Equal(t, "model-test", a.Experiment)
+
+	assert.Nil(t, ForModel(context.Background(), "model-b"))
+}
+
+func TestForPromptUsesWeights(t *testing.T) {
+	for i := range 20 {
+		ctx := middleware.ContextWithJWTClaims(context.Background(), fmt.Sprintf(`{"tenant":"t%d"}`, i))
+		a := ForPrompt(ctx, "greeting")
+		require.NotNil(t, a)
+		assert.Equal(t, "v1", a.Variant.Name)
+	}
+}
+
+func TestAssignmentIsSticky(t *testing.T) {
+	exp := manifestdata.GetManifest().Experiments["model-test"]
+
+	counts := map[string]int{}
+	for i := range 1000 {
+		identity := fmt.Sprintf("user-%d", i)
+		v := chooseVariant(&exp, identity)
+		require.NotNil(t, v)
+		counts[v.Name]++
+
+		// the same caller always gets the same variant
+		assert.Equal(t, v, chooseVariant(&exp, identity))
+	}
+
+	// traffic is split roughly according to the weights
+	assert.InDelta(t, 500, counts["control"], 75)
+	assert.InDelta(t, 500, counts["candidate"], 75)
+}
+
+func TestCallerIdentity(t *testing.T) {
+	ctx := context.WithValue(context.Background(), utils.ExecutionIdContextKey, "exec-1")
+	assert.Equal(t, "exec-1", callerIdentity(ctx, "sub"))
+
+	ctx = context.WithValue(ctx, utils.CallerMetadataContextKey, map[string]string{"remote_addr": "10.0.0.1:5000"})
+	assert.Equal(t, "10.0.0.1", callerIdentity(ctx, "sub"))
+
+	ctx = middleware.ContextWithJWTClaims(ctx, `{"sub":"user-1"}`)
+	assert.Equal(t, "user-1", callerIdentity(ctx, "sub"))
+	assert.Equal(t, "10.0.0.1", callerIdentity(ctx, "tenant"))
+}
+
+func TestRecordFeedback(t *testing.T) {
+	ok, err := RecordFeedback(context.Background(), "prompt-test", 0.5)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = RecordFeedback(context.Background(), "missing", 0.5)
+	assert.Error(t, err)
+}
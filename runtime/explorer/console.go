@@ -0,0 +1,182 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package explorer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/metadata"
+	"github.com/hypermodeinc/modus/runtime/pluginmanager"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+var wasmHost wasmhost.WasmHost
+
+// Initialize stores the WASM host, so that the dev console can invoke functions.
+func Initialize(ctx context.Context) {
+	wasmHost = wasmhost.GetWasmHost(ctx)
+}
+
+type consoleParameter struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional"`
+	Default  *any   `json:"default,omitempty"`
+}
+
+type consoleFunction struct {
+	Name       string              `json:"name"`
+	Plugin     string              `json:"plugin"`
+	Docs       []string            `json:"docs,omitempty"`
+	Parameters []*consoleParameter `json:"parameters"`
+	Results    []string            `json:"results"`
+}
+
+type consoleFunctions struct {
+	Functions []*consoleFunction           `json:"functions"`
+	Types     map[string][]*metadata.Field `json:"types"`
+}
+
+// functionsHandler lists the exported functions of all loaded plugins, with their parameter and result types,
+// and the fields of any object types they use, so that the console can generate input forms.
+func functionsHandler(w http.ResponseWriter, r *http.Request) {
+	result := consoleFunctions{
+		Functions: []*consoleFunction{},
+		Types:     map[string][]*metadata.Field{},
+	}
+
+	for _, plugin := range pluginmanager.GetRegisteredPlugins() {
+		md := plugin.Metadata
+		for name, fn := range md.FnExports {
+			cf := &consoleFunction{
+				Name:       name,
+				Plugin:     plugin.Name(),
+				Parameters: make([]*consoleParameter, len(fn.Parameters)),
+				Results:    make([]string, len(fn.Results)),
+			}
+			if fn.Docs != nil {
+				cf.Docs = fn.Docs.Lines
+			}
+			for i, p := range fn.Parameters {
+				cf.Parameters[i] = &consoleParameter{
+					Name:     p.Name,
+					Type:     p.Type,
+					Optional: p.Default != nil || strings.HasSuffix(p.Type, "| null") || strings.HasPrefix(p.Type, "*"),
+					Default:  p.Default,
+				}
+			}
+			for i, r := range fn.Results {
+				cf.Results[i] = r.Type
+			}
+			result.Functions = append(result.Functions, cf)
+		}
+		for name, t := range md.Types {
+			if len(t.Fields) > 0 {
+				result.Types[name] = t.Fields
+			}
+		}
+	}
+
+	slices.SortFunc(result.Functions, func(a, b *consoleFunction) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	j, err := utils.JsonSerialize(result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	_, _ = w.Write(j)
+}
+
+type invokeRequest struct {
+	Function string         `json:"function"`
+	Args     map[string]any `json:"args"`
+}
+
+type invokeResponse struct {
+	ExecutionId string                `json:"executionId,omitempty"`
+	Result      any                   `json:"result"`
+	Error       string                `json:"error,omitempty"`
+	DurationMs  float64               `json:"durationMs"`
+	Memory      *wasmhost.MemoryUsage `json:"memory,omitempty"`
+	Messages    []utils.LogMessage    `json:"messages"`
+	Output      []utils.LogMessage    `json:"output"`
+}
+
+// invokeHandler calls a function with the given arguments, and returns its result along with
+// any log messages and console output it produced.
+func invokeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req invokeRequest
+	if err := utils.JsonDeserialize(body, &req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fnInfo, err := wasmHost.GetFunctionInfo(req.Function)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if req.Args == nil {
+		req.Args = map[string]any{}
+	}
+
+	ctx := context.WithValue(r.Context(), utils.WasmHostContextKey, wasmHost)
+
+	start := time.Now()
+	execInfo, err := wasmHost.CallFunction(ctx, fnInfo, req.Args)
+	duration := time.Since(start)
+
+	res := &invokeResponse{
+		DurationMs: float64(duration.Microseconds()) / 1000,
+		Messages:   []utils.LogMessage{},
+		Output:     []utils.LogMessage{},
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	if execInfo != nil {
+		res.ExecutionId = execInfo.ExecutionId()
+		res.Result = execInfo.Result()
+		res.Memory = execInfo.Memory()
+		res.Messages = append(res.Messages, execInfo.Messages()...)
+		res.Output = append(res.Output, utils.TransformConsoleOutput(execInfo.Buffers())...)
+	}
+
+	j, err := utils.JsonSerialize(res)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	_, _ = w.Write(j)
+}
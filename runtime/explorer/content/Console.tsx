@@ -0,0 +1,315 @@
+import React, { useEffect, useState } from "react";
+
+interface Parameter {
+  name: string;
+  type: string;
+  optional: boolean;
+  default?: unknown;
+}
+
+interface FunctionInfo {
+  name: string;
+  plugin: string;
+  docs?: string[];
+  parameters: Parameter[];
+  results: string[];
+}
+
+interface Field {
+  name: string;
+  type: string;
+}
+
+interface LogMessage {
+  level?: string;
+  message: string;
+}
+
+interface InvokeResponse {
+  executionId?: string;
+  result: unknown;
+  error?: string;
+  durationMs: number;
+  memory?: { initialBytes: number; peakBytes: number; growthBytes: number };
+  messages: LogMessage[];
+  output: LogMessage[];
+}
+
+type InputKind = "boolean" | "number" | "string" | "json";
+
+const numericTypes = new Set([
+  "int",
+  "int8",
+  "int16",
+  "int32",
+  "int64",
+  "uint",
+  "uint8",
+  "uint16",
+  "uint32",
+  "uint64",
+  "float32",
+  "float64",
+  "i8",
+  "i16",
+  "i32",
+  "i64",
+  "u8",
+  "u16",
+  "u32",
+  "u64",
+  "f32",
+  "f64",
+  "isize",
+  "usize",
+  "byte",
+  "rune",
+]);
+
+function baseType(type: string): string {
+  return type.replace(/^\*/, "").replace(/\s*\|\s*null$/, "");
+}
+
+function inputKind(type: string): InputKind {
+  const t = baseType(type);
+  if (t === "bool") return "boolean";
+  if (t === "string" || t === "~lib/string/String") return "string";
+  if (numericTypes.has(t)) return "number";
+  return "json";
+}
+
+// Builds an example JSON value for an object type, so the developer has a starting point to edit.
+function jsonTemplate(type: string, types: Record<string, Field[]>, depth = 0): unknown {
+  const t = baseType(type);
+  if (t.startsWith("[]") || t.startsWith("~lib/array/Array<")) return [];
+  const fields = types[t];
+  if (!fields || depth > 3) return null;
+  const obj: Record<string, unknown> = {};
+  for (const f of fields) {
+    switch (inputKind(f.type)) {
+      case "boolean":
+        obj[f.name] = false;
+        break;
+      case "number":
+        obj[f.name] = 0;
+        break;
+      case "string":
+        obj[f.name] = "";
+        break;
+      default:
+        obj[f.name] = jsonTemplate(f.type, types, depth + 1);
+    }
+  }
+  return obj;
+}
+
+function initialValue(p: Parameter, types: Record<string, Field[]>): string {
+  if (p.default !== undefined) {
+    return typeof p.default === "string" ? p.default : JSON.stringify(p.default);
+  }
+  switch (inputKind(p.type)) {
+    case "boolean":
+      return "false";
+    case "json":
+      return JSON.stringify(jsonTemplate(p.type, types), null, 2);
+    default:
+      return "";
+  }
+}
+
+function parseValue(p: Parameter, value: string): unknown {
+  switch (inputKind(p.type)) {
+    case "boolean":
+      return value === "true";
+    case "number":
+      return Number(value);
+    case "string":
+      return value;
+    default:
+      return JSON.parse(value);
+  }
+}
+
+export default function Console() {
+  const [functions, setFunctions] = useState<FunctionInfo[]>([]);
+  const [types, setTypes] = useState<Record<string, Field[]>>({});
+  const [selected, setSelected] = useState<FunctionInfo | null>(null);
+  const [values, setValues] = useState<Record<string, string>>({});
+  const [response, setResponse] = useState<InvokeResponse | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [running, setRunning] = useState(false);
+
+  useEffect(() => {
+    const fetchFunctions = async () => {
+      try {
+        const res = await fetch("/explorer/api/functions");
+        const data = await res.json();
+        setFunctions(data.functions);
+        setTypes(data.types);
+      } catch (error) {
+        console.error("Failed to fetch functions:", error);
+      }
+    };
+
+    fetchFunctions();
+  }, []);
+
+  const select = (fn: FunctionInfo) => {
+    setSelected(fn);
+    setResponse(null);
+    setError(null);
+    const v: Record<string, string> = {};
+    for (const p of fn.parameters) {
+      v[p.name] = initialValue(p, types);
+    }
+    setValues(v);
+  };
+
+  const invoke = async () => {
+    if (!selected) return;
+    setError(null);
+    setResponse(null);
+
+    const args: Record<string, unknown> = {};
+    try {
+      for (const p of selected.parameters) {
+        const value = values[p.name];
+        if (p.optional && value === "") continue;
+        args[p.name] = parseValue(p, value);
+      }
+    } catch (e) {
+      setError(`Invalid arguments: ${e}`);
+      return;
+    }
+
+    setRunning(true);
+    try {
+      const res = await fetch("/explorer/api/invoke", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ function: selected.name, args }),
+      });
+      if (!res.ok) {
+        setError(await res.text());
+      } else {
+        setResponse(await res.json());
+      }
+    } catch (e) {
+      setError(`${e}`);
+    } finally {
+      setRunning(false);
+    }
+  };
+
+  const logs = response ? [...response.messages, ...response.output] : [];
+
+  return (
+    <div className="flex flex-1 min-h-0 gap-2 text-white/80 text-sm">
+      <ul className="w-64 overflow-auto border border-white/10 rounded p-2">
+        {functions.map((fn) => (
+          <li key={fn.name}>
+            <button
+              className={`w-full text-left px-2 py-1 rounded font-mono ${
+                selected?.name === fn.name ? "bg-white/10 text-white" : "hover:bg-white/5"
+              }`}
+              onClick={() => select(fn)}
+            >
+              {fn.name}
+            </button>
+          </li>
+        ))}
+      </ul>
+
+      {selected && (
+        <div className="flex-1 overflow-auto border border-white/10 rounded p-4 flex flex-col gap-4">
+          <div>
+            <h2 className="font-mono text-lg text-white">{selected.name}</h2>
+            <p className="text-white/50">
+              {selected.plugin} &middot; returns {selected.results.join(", ") || "nothing"}
+            </p>
+            {selected.docs?.map((line, i) => <p key={i}>{line}</p>)}
+          </div>
+
+          <form
+            className="flex flex-col gap-3"
+            onSubmit={(e) => {
+              e.preventDefault();
+              invoke();
+            }}
+          >
+            {selected.parameters.map((p) => {
+              const kind = inputKind(p.type);
+              const set = (v: string) => setValues({ ...values, [p.name]: v });
+              return (
+                <label key={p.name} className="flex flex-col gap-1">
+                  <span className="font-mono">
+                    {p.name}: <span className="text-white/50">{p.type}</span>
+                    {p.optional && <span className="text-white/30"> (optional)</span>}
+                  </span>
+                  {kind === "boolean" ? (
+                    <input
+                      type="checkbox"
+                      className="w-4 h-4"
+                      checked={values[p.name] === "true"}
+                      onChange={(e) => set(String(e.target.checked))}
+                    />
+                  ) : kind === "json" ? (
+                    <textarea
+                      className="bg-black border border-white/10 rounded p-2 font-mono"
+                      rows={4}
+                      value={values[p.name]}
+                      onChange={(e) => set(e.target.value)}
+                    />
+                  ) : (
+                    <input
+                      type={kind === "number" ? "number" : "text"}
+                      className="bg-black border border-white/10 rounded p-2 font-mono"
+                      value={values[p.name]}
+                      onChange={(e) => set(e.target.value)}
+                    />
+                  )}
+                </label>
+              );
+            })}
+            <button
+              type="submit"
+              disabled={running}
+              className="self-start px-4 py-1 rounded bg-green-700 text-white disabled:opacity-50"
+            >
+              {running ? "Running..." : "Invoke"}
+            </button>
+          </form>
+
+          {error && <pre className="text-red-400 whitespace-pre-wrap">{error}</pre>}
+
+          {response && (
+            <div className="flex flex-col gap-2">
+              <p className="text-white/50">
+                {response.durationMs.toFixed(2)} ms
+                {response.memory && <> &middot; {(response.memory.peakBytes / 1048576).toFixed(1)} MiB peak memory</>}
+                {" "}&middot; execution {response.executionId}
+              </p>
+              {response.error && <pre className="text-red-400">{response.error}</pre>}
+              <pre className="bg-black border border-white/10 rounded p-2 overflow-auto">
+                {JSON.stringify(response.result, null, 2)}
+              </pre>
+              {logs.length > 0 && (
+                <ul className="bg-black border border-white/10 rounded p-2 font-mono">
+                  {logs.map((m, i) => (
+                    <li
+                      key={i}
+                      className={m.level === "error" || m.level === "fatal" ? "text-red-400" : ""}
+                    >
+                      {m.level && <span className="text-white/40">[{m.level}] </span>}
+                      {m.message}
+                    </li>
+                  ))}
+                </ul>
+              )}
+            </div>
+          )}
+        </div>
+      )}
+    </div>
+  );
+}
@@ -2,6 +2,7 @@ import React, { useEffect, useState } from "react";
 import { createRoot } from "react-dom/client";
 import { ApiExplorer } from "@hypermode/react-api-explorer";
 import ModusIcon from "./ModusIcon";
+import Console from "./Console";
 import "@hypermode/react-api-explorer/styles.css";
 import "./index.css";
 
@@ -33,6 +34,7 @@ function App() {
     input: "150 35% 17%",
     ring: "150 60% 39%",
   };
+  const [view, setView] = useState<"explorer" | "console">("explorer");
   const [endpoints, setEndpoints] = useState<string[]>([
     "http://localhost:8686/graphql",
   ]);
@@ -61,19 +63,36 @@ function App() {
   }, []);
 
   return (
-    <div className="bg-black p-2 h-dvh flex flex-col">
-      <ApiExplorer
-        endpoints={endpoints}
-        theme={modusTheme}
-        title={
-          <div className="flex items-center">
-            <p className="text-white/80 tracking-wide text-lg">
-              Modus API Explorer
-            </p>
-            <ModusIcon className="w-10 -ml-1" />
-          </div>
-        }
-      />
+    <div className="bg-black p-2 h-dvh flex flex-col gap-2">
+      <nav className="flex gap-2 text-sm">
+        {(["explorer", "console"] as const).map((v) => (
+          <button
+            key={v}
+            className={`px-3 py-1 rounded ${
+              view === v ? "bg-white/10 text-white" : "text-white/60 hover:text-white"
+            }`}
+            onClick={() => setView(v)}
+          >
+            {v === "explorer" ? "API Explorer" : "Function Console"}
+          </button>
+        ))}
+      </nav>
+      {view === "explorer" ? (
+        <ApiExplorer
+          endpoints={endpoints}
+          theme={modusTheme}
+          title={
+            <div className="flex items-center">
+              <p className="text-white/80 tracking-wide text-lg">
+                Modus API Explorer
+              </p>
+              <ModusIcon className="w-10 -ml-1" />
+            </div>
+          }
+        />
+      ) : (
+        <Console />
+      )}
     </div>
   );
 }
@@ -30,6 +30,8 @@ func explorerHandler(w http.ResponseWriter, r *http.Request) {
 	mux := http.NewServeMux()
 	mux.Handle("/explorer/", http.StripPrefix("/explorer/", http.FileServerFS(contentRoot)))
 	mux.HandleFunc("/explorer/api/endpoints", endpointsHandler)
+	mux.HandleFunc("/explorer/api/functions", functionsHandler)
+	mux.HandleFunc("/explorer/api/invoke", invokeHandler)
 
 	mux.ServeHTTP(w, r)
 }
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package expressions
+
+import (
+	"container/list"
+	"sync"
+)
+
+// maxCachedPrograms limits the number of parsed expressions kept in memory.  Expressions can come from plugins,
+// so the cache must not grow with the number of distinct expressions it is given.
+const maxCachedPrograms = 1024
+
+// programCache is a size-bounded LRU cache of parsed expressions, keyed by their source.
+type programCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+var globalProgramCache = newProgramCache(maxCachedPrograms)
+
+func newProgramCache(capacity int) *programCache {
+	return &programCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *programCache) get(expr string) (*Program, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[expr]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*Program), true
+}
+
+func (c *programCache) put(p *Program) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[p.source]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[p.source] = c.order.PushFront(p)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*Program).source)
+	}
+}
+
+func (c *programCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,267 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package expressions evaluates expressions written in the Common Expression Language (CEL), with cel-go.
+// Expressions can't have side effects, and each evaluation is limited in its cost, so they are safe to evaluate
+// on the host, whether they come from the manifest or from a plugin.  Variables are not declared in advance,
+// so expressions are not type-checked, and a reference to a missing variable is an error when it is evaluated.
+package expressions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+const (
+	// maxExpressionLength limits the size of the source of an expression.
+	maxExpressionLength = 4096
+
+	// maxNestingDepth limits how deeply the parts of an expression can be nested.
+	maxNestingDepth = 64
+
+	// maxEvalCost limits the work done by one evaluation, in the cost units of cel-go, so that no expression can run for long.
+	maxEvalCost = 100_000
+)
+
+var getEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.ParserExpressionSizeLimit(maxExpressionLength),
+		cel.ParserRecursionLimit(maxNestingDepth),
+	)
+})
+
+// Program is a parsed expression, which can be evaluated many times.
+type Program struct {
+	source  string
+	program cel.Program
+}
+
+// Compile parses an expression.  Parsed expressions are cached, so compiling the same expression again is cheap.
+func Compile(expr string) (*Program, error) {
+	if p, ok := globalProgramCache.get(expr); ok {
+		return p, nil
+	}
+
+	env, err := getEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, iss := env.Parse(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+
+	program, err := env.Program(ast, cel.CostLimit(maxEvalCost))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Program{source: expr, program: program}
+	globalProgramCache.put(p)
+	return p, nil
+}
+
+// Source returns the text of the expression.
+func (p *Program) Source() string {
+	return p.source
+}
+
+// Eval evaluates the expression with the given variables.  Variable values may be any value that can be
+// represented in JSON, and are converted to the corresponding CEL types.  The result is converted to
+// nil, bool, int64, uint64, float64, string, []any, or map[string]any.
+func (p *Program) Eval(vars map[string]any) (any, error) {
+	out, err := p.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return fromValue(out)
+}
+
+// EvalBool evaluates an expression that must produce a bool, such as a filter or a condition.
+func (p *Program) EvalBool(vars map[string]any) (bool, error) {
+	out, err := p.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := out.(types.Bool)
+	if !ok {
+		return false, fmt.Errorf("expression must evaluate to a bool, not %s", out.Type().TypeName())
+	}
+	return bool(b), nil
+}
+
+func (p *Program) eval(vars map[string]any) (ref.Val, error) {
+	converted := make(map[string]any, len(vars))
+	for k, v := range vars {
+		cv, err := toValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for variable %s: %w", k, err)
+		}
+		converted[k] = cv
+	}
+
+	out, _, err := p.program.Eval(converted)
+	return out, err
+}
+
+// Evaluate compiles and evaluates an expression with the given variables.
+func Evaluate(expr string, vars map[string]any) (any, error) {
+	p, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Eval(vars)
+}
+
+// EvalExpression is the host function that evaluates an expression for a plugin.  The context is a JSON object
+// whose fields are the variables available to the expression, and the result is returned as JSON.
+func EvalExpression(expr string, contextJson *string) (*string, error) {
+	vars := map[string]any{}
+	if contextJson != nil && *contextJson != "" {
+		dec := json.NewDecoder(bytes.NewReader([]byte(*contextJson)))
+		dec.UseNumber()
+		if err := dec.Decode(&vars); err != nil {
+			return nil, fmt.Errorf("the expression context must be a JSON object: %w", err)
+		}
+	}
+
+	result, err := Evaluate(expr, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := utils.JsonSerialize(result)
+	if err != nil {
+		return nil, err
+	}
+	s := string(data)
+	return &s, nil
+}
+
+// toValue converts a Go value to one of the types that are given to cel-go as variables:
+// nil, bool, int64, float64, string, []any, or map[string]any.
+func toValue(v any) (any, error) {
+	switch v := v.(type) {
+	case nil, bool, int64, float64, string:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case float32:
+		return float64(v), nil
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return i, nil
+		}
+		return strconv.ParseFloat(string(v), 64)
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			cv, err := toValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = cv
+		}
+		return list, nil
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			cv, err := toValue(item)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = cv
+		}
+		return m, nil
+	}
+
+	// other types, such as structs and typed slices, are converted through their JSON representation
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u := rv.Uint()
+		if u > math.MaxInt64 {
+			return float64(u), nil
+		}
+		return int64(u), nil
+	}
+
+	data, err := utils.JsonSerialize(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return toValue(decoded)
+}
+
+// fromValue converts the result of an evaluation to a Go value that can be serialized to JSON.
+func fromValue(v ref.Val) (any, error) {
+	switch v := v.(type) {
+	case types.Null:
+		return nil, nil
+	case types.Bool:
+		return bool(v), nil
+	case types.Int:
+		return int64(v), nil
+	case types.Uint:
+		return uint64(v), nil
+	case types.Double:
+		return float64(v), nil
+	case types.String:
+		return string(v), nil
+	case traits.Mapper:
+		m := make(map[string]any)
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			key := it.Next()
+			k, ok := key.(types.String)
+			if !ok {
+				return nil, fmt.Errorf("map keys must be strings, not %s", key.Type().TypeName())
+			}
+			item, err := fromValue(v.Get(key))
+			if err != nil {
+				return nil, err
+			}
+			m[string(k)] = item
+		}
+		return m, nil
+	case traits.Lister:
+		list := make([]any, 0)
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			item, err := fromValue(it.Next())
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("unsupported result type %s", v.Type().TypeName())
+}
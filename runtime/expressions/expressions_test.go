@@ -0,0 +1,137 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package expressions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	vars := map[string]any{
+		"user":  map[string]any{"name": "Alice", "roles": []string{"admin", "editor"}, "age": 42},
+		"items": []any{1, 2.5, 3},
+	}
+
+	tests := []struct {
+		expr     string
+		expected any
+	}{
+		{`1 + 2 * 3`, int64(7)},
+		{`7 / 2`, int64(3)},
+		{`7.0 / 2.0`, 3.5},
+		{`"a" + "b"`, "ab"},
+		{`user.name == "Alice" && "admin" in user.roles`, true},
+		{`user.age >= 18 ? "adult" : "minor"`, "adult"},
+		{`has(user.email)`, false},
+		{`size(user.roles)`, int64(2)},
+		{`user.name.startsWith("Al")`, true},
+		{`user.name.matches(r"^A\w+$")`, true},
+		{`items.map(x, x > 2)`, []any{false, true, true}},
+		{`items.filter(x, x > 2)`, []any{2.5, int64(3)}},
+		{`[1u, 2u].map(x, x * 2u)`, []any{uint64(2), uint64(4)}},
+		{`items.all(x, x > 0)`, true},
+		{`items.exists_one(x, x == 3)`, true},
+		{`{"a": 1}["a"] == 1.0`, true},
+		{`int("12") + int(2.9)`, int64(14)},
+		{`string(1.5)`, "1.5"},
+		{`-9223372036854775808`, int64(-9223372036854775808)},
+		// errors on one side of a logical operator are ignored when the other side decides the result
+		{`user.missing == 1 || true`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			result, err := Evaluate(tt.expr, vars)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{`1 +`, "Syntax error"},
+		{`unknown`, "no such attribute(s): unknown"},
+		{`1 / 0`, "division by zero"},
+		{`9223372036854775807 + 1`, "integer overflow"},
+		{`"a" + 1`, "no such overload"},
+		{`7.0 / 2`, "no such overload"},
+		{`[1, 2][5]`, "index out of bounds"},
+		{`9223372036854775808`, "invalid int literal"},
+		{`exec("rm")`, "no such overload"},
+		{`{1: "a"}`, "map keys must be strings"},
+		{strings.Repeat("(", 100) + "1" + strings.Repeat(")", 100), "recursion limit exceeded"},
+		{strings.Repeat("1+", maxExpressionLength/2) + "1", "size exceeds limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Evaluate(tt.expr, nil)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestEvaluateBudget(t *testing.T) {
+	// each level of nesting multiplies the work, so this would run for a very long time without a budget
+	list := "[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]"
+	expr := strings.Repeat(list+".map(x, ", 8) + "x" + strings.Repeat(")", 8)
+	_, err := Evaluate(expr, nil)
+	require.ErrorContains(t, err, "cost limit exceeded")
+}
+
+func TestEvalBool(t *testing.T) {
+	p, err := Compile(`result.score > 0.5`)
+	require.NoError(t, err)
+
+	ok, err := p.EvalBool(map[string]any{"result": map[string]any{"score": 0.8}})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	p, err = Compile(`result.score`)
+	require.NoError(t, err)
+	_, err = p.EvalBool(map[string]any{"result": map[string]any{"score": 0.8}})
+	require.ErrorContains(t, err, "must evaluate to a bool, not double")
+}
+
+func TestEvalExpression(t *testing.T) {
+	ctx := `{"order": {"total": 120, "items": [{"sku": "a"}, {"sku": "b"}]}}`
+	result, err := EvalExpression(`{"large": order.total > 100, "skus": order.items.map(i, i.sku)}`, &ctx)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"large": true, "skus": ["a", "b"]}`, *result)
+
+	bad := `[1, 2]`
+	_, err = EvalExpression(`true`, &bad)
+	require.ErrorContains(t, err, "must be a JSON object")
+}
+
+func TestProgramCacheIsBounded(t *testing.T) {
+	c := newProgramCache(2)
+	c.put(&Program{source: "a"})
+	c.put(&Program{source: "b"})
+
+	// reading "a" makes "b" the least recently used
+	_, ok := c.get("a")
+	require.True(t, ok)
+	c.put(&Program{source: "c"})
+
+	assert.Equal(t, 2, c.len())
+	_, ok = c.get("b")
+	assert.False(t, ok)
+	_, ok = c.get("a")
+	assert.True(t, ok)
+}
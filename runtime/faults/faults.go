@@ -0,0 +1,209 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package faults
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+)
+
+// Faults declared in the manifest are injected into the host functions that call external services, so that
+// plugin authors can test how their functions handle failures.  A host function is wrapped to inject faults only
+// in development and test environments, so that there is no cost to calling host functions in production.
+
+const defaultMessage = "injected fault"
+
+var rtContext = reflect.TypeFor[context.Context]()
+var rtError = reflect.TypeFor[error]()
+
+var rng *rand.Rand
+var rngSeed *uint64
+var rngMu sync.Mutex
+
+// Enabled reports whether faults can be injected, which is only in development and test environments.
+func Enabled() bool {
+	return config.IsDevEnvironment() || config.GetEnvironmentName() == "test"
+}
+
+// Inject wraps a host function, so that the faults that the manifest declares for the target are injected
+// into calls to it.  The call is identified for matching by the URL field of its first struct argument,
+// or otherwise by its first string argument, such as a model or collection name.
+func Inject(target manifest.FaultTarget, fn any) any {
+	rv := reflect.ValueOf(fn)
+	if rv.Kind() != reflect.Func {
+		return fn
+	}
+
+	rt := rv.Type()
+	return reflect.MakeFunc(rt, func(args []reflect.Value) []reflect.Value {
+		ctx := context.Background()
+		if len(args) > 0 && rt.In(0).Implements(rtContext) {
+			if c, ok := args[0].Interface().(context.Context); ok && c != nil {
+				ctx = c
+			}
+		}
+
+		name := callName(args)
+		rule := chooseRule(target, name)
+		if rule == nil {
+			return rv.Call(args)
+		}
+
+		logger.Warn(ctx).
+			Str("target", string(rule.Target)).
+			Str("fault", string(rule.Fault)).
+			Str("name", name).
+			Msg("Injecting fault.")
+
+		switch rule.Fault {
+		case manifest.FaultTypeLatency:
+			select {
+			case <-time.After(time.Duration(rule.LatencyMs) * time.Millisecond):
+			case <-ctx.Done():
+			}
+			return rv.Call(args)
+
+		case manifest.FaultTypeError:
+			msg := rule.Message
+			if msg == "" {
+				msg = defaultMessage
+			}
+			if results, ok := errorResults(rt, errors.New(msg)); ok {
+				return results
+			}
+			return rv.Call(args)
+
+		case manifest.FaultTypeMalformed:
+			results := rv.Call(args)
+			for i, r := range results {
+				if rt.Out(i) != rtError {
+					results[i] = malformed(r)
+				}
+			}
+			return results
+		}
+
+		return rv.Call(args)
+	}).Interface()
+}
+
+// chooseRule returns the first rule of the manifest that matches the call and is chosen by its probability, if any.
+func chooseRule(target manifest.FaultTarget, name string) *manifest.FaultRuleInfo {
+	faults := manifestdata.GetManifest().Faults
+	if faults == nil {
+		return nil
+	}
+
+	for i := range faults.Rules {
+		rule := &faults.Rules[i]
+		if rule.Matches(target, name) && roll(faults.Seed) < rule.Probability {
+			return rule
+		}
+	}
+	return nil
+}
+
+// roll returns a random number in [0, 1), from a source that is seeded by the manifest if it declares a seed.
+// The source is reseeded whenever the seed changes.
+func roll(seed *uint64) float64 {
+	rngMu.Lock()
+	defer rngMu.Unlock()
+
+	if rng == nil || !sameSeed(seed, rngSeed) {
+		if seed != nil {
+			rng = rand.New(rand.NewPCG(*seed, *seed))
+		} else {
+			rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+		}
+		rngSeed = seed
+	}
+	return rng.Float64()
+}
+
+func sameSeed(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// callName returns the name that identifies a call for matching against the rules.
+func callName(args []reflect.Value) string {
+	for _, arg := range args {
+		v := arg
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			if f := v.FieldByName("Url"); f.IsValid() && f.Kind() == reflect.String {
+				return f.String()
+			}
+		case reflect.String:
+			return v.String()
+		}
+	}
+	return ""
+}
+
+// errorResults returns zero values for the results of a function, with the error as its error result.
+// It returns false if the function has no error result.
+func errorResults(rt reflect.Type, err error) ([]reflect.Value, bool) {
+	found := false
+	results := make([]reflect.Value, rt.NumOut())
+	for i := range results {
+		t := rt.Out(i)
+		if t == rtError {
+			results[i] = reflect.ValueOf(&err).Elem()
+			found = true
+		} else {
+			results[i] = reflect.Zero(t)
+		}
+	}
+	return results, found
+}
+
+// malformed returns a copy of a result that is cut short, as a response that was interrupted would be.
+// Strings and byte slices are cut in half, as is the body of a response.  Other results are empty.
+func malformed(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		return reflect.ValueOf(s[:len(s)/2]).Convert(v.Type())
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Slice(0, v.Len()/2)
+		}
+
+	case reflect.Pointer:
+		if !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+			if body := v.Elem().FieldByName("Body"); body.IsValid() && body.Kind() == reflect.Slice {
+				c := reflect.New(v.Elem().Type())
+				c.Elem().Set(v.Elem())
+				c.Elem().FieldByName("Body").Set(malformed(body))
+				return c
+			}
+		}
+	}
+	return reflect.Zero(v.Type())
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package faults
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+
+	"github.com/stretchr/testify/require"
+)
+
+type request struct {
+	Url string
+}
+
+type response struct {
+	Status uint16
+	Body   []byte
+}
+
+func fetch(ctx context.Context, req *request) (*response, error) {
+	return &response{Status: 200, Body: []byte("0123456789")}, nil
+}
+
+func invokeModel(ctx context.Context, modelName, input string) (string, error) {
+	return "model output", nil
+}
+
+func setFaults(t *testing.T, rules ...manifest.FaultRuleInfo) {
+	seed := uint64(7)
+	manifestdata.SetManifest(&manifest.Manifest{Faults: &manifest.FaultsInfo{Seed: &seed, Rules: rules}})
+	t.Cleanup(func() { manifestdata.SetManifest(&manifest.Manifest{}) })
+}
+
+func TestInjectError(t *testing.T) {
+	setFaults(t, manifest.FaultRuleInfo{
+		Target:      manifest.FaultTargetModel,
+		Match:       "gpt-*",
+		Fault:       manifest.FaultTypeError,
+		Probability: 1,
+		Message:     "model unavailable",
+	})
+
+	fn := Inject(manifest.FaultTargetModel, invokeModel).(func(context.Context, string, string) (string, error))
+
+	out, err := fn(context.Background(), "gpt-4o", "hello")
+	require.EqualError(t, err, "model unavailable")
+	require.Empty(t, out)
+
+	out, err = fn(context.Background(), "llama", "hello")
+	require.NoError(t, err)
+	require.Equal(t, "model output", out)
+}
+
+func TestInjectMalformed(t *testing.T) {
+	setFaults(t, manifest.FaultRuleInfo{
+		Target:      manifest.FaultTargetHttp,
+		Match:       "https://api.example.com/*",
+		Fault:       manifest.FaultTypeMalformed,
+		Probability: 1,
+	})
+
+	fn := Inject(manifest.FaultTargetHttp, fetch).(func(context.Context, *request) (*response, error))
+
+	res, err := fn(context.Background(), &request{Url: "https://api.example.com/items"})
+	require.NoError(t, err)
+	require.Equal(t, uint16(200), res.Status)
+	require.Equal(t, "01234", string(res.Body))
+}
+
+func TestInjectLatency(t *testing.T) {
+	setFaults(t, manifest.FaultRuleInfo{
+		Target:      manifest.FaultTargetHttp,
+		Fault:       manifest.FaultTypeLatency,
+		Probability: 1,
+		LatencyMs:   20,
+	})
+
+	fn := Inject(manifest.FaultTargetHttp, fetch).(func(context.Context, *request) (*response, error))
+
+	start := time.Now()
+	res, err := fn(context.Background(), &request{Url: "https://example.com"})
+	require.NoError(t, err)
+	require.Equal(t, "0123456789", string(res.Body))
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestInjectProbability(t *testing.T) {
+	setFaults(t, manifest.FaultRuleInfo{
+		Target:      manifest.FaultTargetModel,
+		Fault:       manifest.FaultTypeError,
+		Probability: 0.5,
+	})
+
+	fn := Inject(manifest.FaultTargetModel, invokeModel).(func(context.Context, string, string) (string, error))
+
+	run := func() []bool {
+		rngMu.Lock()
+		rng = nil
+		rngMu.Unlock()
+
+		failed := make([]bool, 100)
+		for i := range failed {
+			_, err := fn(context.Background(), "model", "hello")
+			failed[i] = err != nil
+		}
+		return failed
+	}
+
+	first := run()
+	count := 0
+	for _, f := range first {
+		if f {
+			count++
+		}
+	}
+	require.Greater(t, count, 20)
+	require.Less(t, count, 80)
+
+	// the same seed injects faults into the same calls
+	require.Equal(t, first, run())
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package feedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/hypermodeinc/modus/runtime/db"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/metrics"
+)
+
+// Sources of feedback, as recorded with each rating.
+const (
+	SourceFunction = "function"
+	SourceGraphQL  = "graphql"
+)
+
+const maxCommentLength = 4096
+
+// Record attaches a user's rating and optional comment to a previous execution.
+// The feedback is written as an audit record, counted in metrics, and stored alongside
+// the inference history so that evaluations can join the two on the execution id.
+func Record(ctx context.Context, executionId string, rating int, comment, source string) error {
+	if err := validate(executionId, rating, comment); err != nil {
+		return err
+	}
+
+	metrics.FeedbackRatingsNum.WithLabelValues(source, strconv.Itoa(rating)).Inc()
+
+	// audit record
+	logger.Info(ctx).
+		Str("log_type", "feedback").
+		Str("feedback_execution_id", executionId).
+		Int("rating", rating).
+		Str("comment", comment).
+		Str("source", source).
+		Msg("Recorded user feedback.")
+
+	db.WriteFeedback(ctx, executionId, rating, comment, source)
+	return nil
+}
+
+// RecordFromFunction records feedback submitted by a function through the host function.
+func RecordFromFunction(ctx context.Context, executionId string, rating int32, comment string) (bool, error) {
+	if err := Record(ctx, executionId, int(rating), comment, SourceFunction); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func validate(executionId string, rating int, comment string) error {
+	if executionId == "" {
+		return errors.New("an execution id is required")
+	}
+
+	lo, hi := manifestdata.GetManifest().Feedback.GetRatingRange()
+	if rating < lo || rating > hi {
+		return fmt.Errorf("rating %d is out of range, must be between %d and %d", rating, lo, hi)
+	}
+
+	if utf8.RuneCountInString(comment) > maxCommentLength {
+		return fmt.Errorf("comment is too long, must be at most %d characters", maxCommentLength)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package feedback
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{})
+
+	assert.NoError(t, validate("exec-1", 1, ""))
+	assert.NoError(t, validate("exec-1", 5, "great answer"))
+	assert.Error(t, validate("", 3, ""))
+	assert.Error(t, validate("exec-1", 0, ""))
+	assert.Error(t, validate("exec-1", 6, ""))
+	assert.Error(t, validate("exec-1", 3, strings.Repeat("x", maxCommentLength+1)))
+}
+
+func TestValidate_CustomRange(t *testing.T) {
+	lo, hi := -1, 1
+	manifestdata.SetManifest(&manifest.Manifest{
+		Feedback: &manifest.FeedbackInfo{MinRating: &lo, MaxRating: &hi},
+	})
+	defer manifestdata.SetManifest(&manifest.Manifest{})
+
+	assert.NoError(t, validate("exec-1", -1, ""))
+	assert.NoError(t, validate("exec-1", 1, ""))
+	assert.Error(t, validate("exec-1", 2, ""))
+}
+
+func TestRecordFromFunction_Invalid(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{})
+
+	ok, err := RecordFromFunction(context.Background(), "exec-1", 9, "")
+	assert.False(t, ok)
+	assert.Error(t, err)
+}
@@ -33,8 +33,13 @@ func NewFunctionInfo(fnName string, plugin *plugins.Plugin, isImport bool) (Func
 	}
 
 	fnMeta := fnMap[fnName]
+	if fnMeta == nil {
+		return nil, false
+	}
+
+	// a lazily loaded plugin has no execution plans until it is compiled on first use
 	plan := plugin.ExecutionPlans[fnName]
-	if fnMeta == nil || plan == nil {
+	if plan == nil && plugin.IsCompiled() {
 		return nil, false
 	}
 
@@ -50,8 +55,14 @@ type functionInfo struct {
 	plan     langsupport.ExecutionPlan
 }
 
-func (f *functionInfo) Name() string                             { return f.fnName }
-func (f *functionInfo) IsImport() bool                           { return f.isImport }
-func (f *functionInfo) Plugin() *plugins.Plugin                  { return f.plugin }
-func (f *functionInfo) Metadata() *metadata.Function             { return f.fnMeta }
-func (f *functionInfo) ExecutionPlan() langsupport.ExecutionPlan { return f.plan }
+func (f *functionInfo) Name() string                 { return f.fnName }
+func (f *functionInfo) IsImport() bool               { return f.isImport }
+func (f *functionInfo) Plugin() *plugins.Plugin      { return f.plugin }
+func (f *functionInfo) Metadata() *metadata.Function { return f.fnMeta }
+
+func (f *functionInfo) ExecutionPlan() langsupport.ExecutionPlan {
+	if f.plan == nil {
+		return f.plugin.ExecutionPlans[f.fnName]
+	}
+	return f.plan
+}
@@ -60,9 +60,13 @@ func (fr *functionRegistry) RegisterAllFunctions(ctx context.Context, plugins ..
 }
 
 func (fr *functionRegistry) RegisterExports(ctx context.Context, plugin *plugins.Plugin) []string {
-	fnExports := plugin.Module.ExportedFunctions()
-	names := make([]string, 0, len(fnExports))
-	for fnName := range fnExports {
+	fnNames := utils.MapKeys(plugin.Metadata.FnExports)
+	if plugin.IsCompiled() {
+		fnNames = utils.MapKeys(plugin.Module.ExportedFunctions())
+	}
+
+	names := make([]string, 0, len(fnNames))
+	for _, fnName := range fnNames {
 		info, ok := NewFunctionInfo(fnName, plugin, false)
 		if ok {
 			fr.functions[fnName] = info
@@ -79,11 +83,18 @@ func (fr *functionRegistry) RegisterExports(ctx context.Context, plugin *plugins
 }
 
 func (fr *functionRegistry) RegisterImports(ctx context.Context, plugin *plugins.Plugin) []string {
-	fnImports := plugin.Module.ImportedFunctions()
-	names := make([]string, 0, len(fnImports))
-	for _, fnDef := range fnImports {
-		modName, fnName, _ := fnDef.Import()
-		impName := fmt.Sprintf("%s.%s", modName, fnName)
+	impNames := utils.MapKeys(plugin.Metadata.FnImports)
+	if plugin.IsCompiled() {
+		fnImports := plugin.Module.ImportedFunctions()
+		impNames = make([]string, 0, len(fnImports))
+		for _, fnDef := range fnImports {
+			modName, fnName, _ := fnDef.Import()
+			impNames = append(impNames, fmt.Sprintf("%s.%s", modName, fnName))
+		}
+	}
+
+	names := make([]string, 0, len(impNames))
+	for _, impName := range impNames {
 		info, ok := NewFunctionInfo(impName, plugin, true)
 		if ok {
 			fr.functions[impName] = info
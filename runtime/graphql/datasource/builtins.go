@@ -0,0 +1,44 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/feedback"
+	"github.com/hypermodeinc/modus/runtime/graphql/schemagen"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+type feedbackArgs struct {
+	ExecutionId string `json:"executionId"`
+	Rating      int    `json:"rating"`
+	Comment     string `json:"comment"`
+}
+
+// callBuiltin performs the operation of a built-in field that is not backed by a function or collection.
+func (ds *ModusDataSource) callBuiltin(ctx context.Context, ci *callInfo) (any, error) {
+	switch ci.Builtin {
+	case schemagen.BuiltinRecordFeedback:
+		var args feedbackArgs
+		if data, err := utils.JsonSerialize(ci.Parameters); err != nil {
+			return nil, err
+		} else if err := utils.JsonDeserialize(data, &args); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+		if err := feedback.Record(ctx, args.ExecutionId, args.Rating, args.Comment, feedback.SourceGraphQL); err != nil {
+			return nil, err
+		}
+		return true, nil
+	}
+
+	return nil, fmt.Errorf("unknown built-in field %s", ci.Builtin)
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/collections"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+const defaultCollectionSearchLimit = 10
+
+type collectionArgs struct {
+	Text         string     `json:"text"`
+	Vector       []float32  `json:"vector"`
+	SearchMethod string     `json:"searchMethod"`
+	Namespace    string     `json:"namespace"`
+	Namespaces   []string   `json:"namespaces"`
+	Limit        int32      `json:"limit"`
+	ReturnText   bool       `json:"returnText"`
+	Key          string     `json:"key"`
+	Keys         []string   `json:"keys"`
+	Texts        []string   `json:"texts"`
+	Labels       [][]string `json:"labels"`
+}
+
+// callCollection performs the collection operation for a built-in collection field.
+// Access to the collection is enforced by the collections package, using the caller's JWT claims.
+func (ds *ModusDataSource) callCollection(ctx context.Context, ci *callInfo) (any, error) {
+	var args collectionArgs
+	if data, err := utils.JsonSerialize(ci.Parameters); err != nil {
+		return nil, err
+	} else if err := utils.JsonDeserialize(data, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	name := ci.Collection.Collection
+	op := manifest.CollectionOperation(ci.Collection.Operation)
+	if err := checkCollectionFieldAccess(name, op); err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case manifest.CollectionOperationSearch:
+		limit := args.Limit
+		if limit <= 0 {
+			limit = defaultCollectionSearchLimit
+		}
+		var res *collections.CollectionSearchResult
+		var err error
+		switch {
+		case len(args.Vector) > 0 && args.Text != "":
+			return nil, fmt.Errorf("either text or vector must be given, but not both")
+		case len(args.Vector) > 0:
+			// a precomputed query vector skips the embedder
+			res, err = collections.SearchByVector(ctx, name, args.Namespaces, args.SearchMethod, args.Vector, limit, args.ReturnText)
+		case args.Text != "":
+			res, err = collections.Search(ctx, name, args.Namespaces, args.SearchMethod, args.Text, limit, args.ReturnText)
+		default:
+			return nil, fmt.Errorf("either text or vector is required")
+		}
+		if err != nil {
+			return nil, err
+		}
+		return searchResponse(res, args.ReturnText), nil
+
+	case manifest.CollectionOperationClassify:
+		res, err := collections.ClassifyText(ctx, name, args.Namespace, args.SearchMethod, args.Text)
+		if err != nil {
+			return nil, err
+		}
+		return classifyResponse(res), nil
+
+	case manifest.CollectionOperationVector:
+		vector, err := collections.GetVector(ctx, name, args.Namespace, args.SearchMethod, args.Key)
+		if err != nil {
+			return nil, err
+		}
+		if vector == nil {
+			return nil, fmt.Errorf("item with key %s not found in collection %s", args.Key, name)
+		}
+		return map[string]any{
+			"collection":   name,
+			"searchMethod": args.SearchMethod,
+			"namespace":    args.Namespace,
+			"key":          args.Key,
+			"vector":       vector,
+		}, nil
+
+	case manifest.CollectionOperationUpsert:
+		if len(args.Keys) > 0 && len(args.Keys) != len(args.Texts) {
+			return nil, fmt.Errorf("the number of keys must match the number of texts")
+		}
+		res, err := collections.Upsert(ctx, name, args.Namespace, args.Keys, args.Texts, args.Labels)
+		if err != nil {
+			return nil, err
+		}
+		return mutationResponse(res), nil
+
+	case manifest.CollectionOperationDelete:
+		res, err := collections.Delete(ctx, name, args.Namespace, args.Key)
+		if err != nil {
+			return nil, err
+		}
+		return mutationResponse(res), nil
+	}
+
+	return nil, fmt.Errorf("unsupported collection operation: %s", ci.Collection.Operation)
+}
+
+// checkCollectionFieldAccess denies write operations on collections without an access policy.  The collections
+// package allows every caller to use such collections, which is right for plugin code, but built-in fields can be
+// called by any client.  Fields are only generated for collections with a policy, but the manifest may have changed
+// since the schema was generated.
+func checkCollectionFieldAccess(collectionName string, op manifest.CollectionOperation) error {
+	switch op {
+	case manifest.CollectionOperationUpsert, manifest.CollectionOperationDelete:
+		info, ok := manifestdata.GetManifest().Collections[collectionName]
+		if !ok || info.Access == nil {
+			return fmt.Errorf("access denied: collection %s has no access policy, so it cannot be modified through GraphQL", collectionName)
+		}
+	}
+	return nil
+}
+
+func searchResponse(res *collections.CollectionSearchResult, returnText bool) map[string]any {
+	objects := make([]map[string]any, len(res.Objects))
+	for i, o := range res.Objects {
+		var text any
+		if returnText {
+			text = o.Text
+		}
+		objects[i] = map[string]any{
+			"namespace": o.Namespace,
+			"key":       o.Key,
+			"text":      text,
+			"labels":    o.Labels,
+			"distance":  o.Distance,
+			"score":     o.Score,
+		}
+	}
+	return map[string]any{
+		"collection":   res.Collection,
+		"searchMethod": res.SearchMethod,
+		"objects":      objects,
+	}
+}
+
+func classifyResponse(res *collections.CollectionClassificationResult) map[string]any {
+	labels := make([]map[string]any, len(res.LabelsResult))
+	for i, l := range res.LabelsResult {
+		labels[i] = map[string]any{
+			"label":      l.Label,
+			"confidence": l.Confidence,
+		}
+	}
+	cluster := make([]map[string]any, len(res.Cluster))
+	for i, c := range res.Cluster {
+		cluster[i] = map[string]any{
+			"key":      c.Key,
+			"labels":   c.Labels,
+			"distance": c.Distance,
+			"score":    c.Score,
+		}
+	}
+	return map[string]any{
+		"collection":   res.Collection,
+		"searchMethod": res.SearchMethod,
+		"labels":       labels,
+		"cluster":      cluster,
+	}
+}
+
+func mutationResponse(res *collections.CollectionMutationResult) map[string]any {
+	duplicates := make([]map[string]any, len(res.Duplicates))
+	for i, d := range res.Duplicates {
+		duplicates[i] = map[string]any{
+			"key":         d.Key,
+			"duplicateOf": d.DuplicateOf,
+			"distance":    d.Distance,
+			"policy":      d.Policy,
+		}
+	}
+	return map[string]any{
+		"collection": res.Collection,
+		"operation":  res.Operation,
+		"keys":       res.Keys,
+		"duplicates": duplicates,
+	}
+}
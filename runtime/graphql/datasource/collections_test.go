@@ -0,0 +1,48 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package datasource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/graphql/schemagen"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectionWritesRequireAccessPolicy(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{
+		Collections: map[string]manifest.CollectionInfo{
+			"open": {GraphQL: &manifest.CollectionGraphQLInfo{}},
+		},
+	})
+
+	ds := &ModusDataSource{}
+	for _, op := range []manifest.CollectionOperation{manifest.CollectionOperationUpsert, manifest.CollectionOperationDelete} {
+		ci := &callInfo{
+			Collection: &schemagen.CollectionField{Collection: "open", Operation: string(op)},
+			Parameters: map[string]any{"key": "k1", "keys": []string{"k1"}, "texts": []string{"text"}},
+		}
+		_, err := ds.callCollection(context.Background(), ci)
+		require.ErrorContains(t, err, "has no access policy", "operation %s", op)
+	}
+
+	// collections with a policy are checked against the caller's roles instead
+	require.NoError(t, checkCollectionFieldAccess("open", manifest.CollectionOperationSearch))
+	manifestdata.SetManifest(&manifest.Manifest{
+		Collections: map[string]manifest.CollectionInfo{
+			"guarded": {Access: &manifest.CollectionAccessInfo{}, GraphQL: &manifest.CollectionGraphQLInfo{}},
+		},
+	})
+	require.NoError(t, checkCollectionFieldAccess("guarded", manifest.CollectionOperationUpsert))
+}
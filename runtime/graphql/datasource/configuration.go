@@ -10,11 +10,18 @@
 package datasource
 
 import (
+	"github.com/hypermodeinc/modus/runtime/graphql/schemagen"
 	"github.com/hypermodeinc/modus/runtime/wasmhost"
 )
 
 type HypDSConfig struct {
-	WasmHost          wasmhost.WasmHost
-	FieldsToFunctions map[string]string
-	MapTypes          []string
+	WasmHost            wasmhost.WasmHost
+	FieldsToFunctions   map[string]string
+	FieldsToCollections map[string]schemagen.CollectionField
+	FieldsToBuiltins    map[string]string
+	FieldsToPipelines   map[string]string
+	FieldResolvers      map[string]string
+	FieldDirectives     map[string][]*schemagen.Directive
+	FunctionDirectives  map[string][]*schemagen.Directive
+	MapTypes            []string
 }
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package datasource
+
+import (
+	"errors"
+)
+
+// A pathError is an error that occurred while transforming the value at a path within a result.
+// The path contains field aliases (or names) and list indices, relative to the root field.
+type pathError struct {
+	path []any
+	err  error
+}
+
+func (e *pathError) Error() string {
+	return e.err.Error()
+}
+
+func (e *pathError) Unwrap() error {
+	return e.err
+}
+
+// withPathSegment prepends a field alias or list index to the path of an error,
+// so that the full path is assembled as the error propagates out of nested transforms.
+func withPathSegment(err error, segment any) error {
+	var pe *pathError
+	if errors.As(err, &pe) {
+		pe.path = append([]any{segment}, pe.path...)
+		return pe
+	}
+	return &pathError{path: []any{segment}, err: err}
+}
+
+// errorPath returns the full GraphQL response path of an error that occurred under the given root field.
+func errorPath(rootField string, err error) []any {
+	var pe *pathError
+	if errors.As(err, &pe) {
+		return append([]any{rootField}, pe.path...)
+	}
+	return []any{rootField}
+}
@@ -0,0 +1,289 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/expressions"
+	"github.com/hypermodeinc/modus/runtime/functions"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/redaction"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+
+	"github.com/tidwall/gjson"
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/engine/resolve"
+	"golang.org/x/sync/errgroup"
+)
+
+// callPipeline calls the stages of a pipeline in order, passing the result of each one to the next.
+// The first function that fails stops the pipeline, and the error identifies the stage that failed.
+func (ds *ModusDataSource) callPipeline(ctx context.Context, ci *callInfo) (any, []resolve.GraphQLError, error) {
+	pipeline, ok := manifestdata.GetManifest().Pipelines[ci.Pipeline]
+	if !ok {
+		return nil, nil, fmt.Errorf("pipeline %s was not found", ci.Pipeline)
+	}
+
+	r := &pipelineRun{ds: ds, ci: ci}
+	var result any
+	for i := range pipeline.Stages {
+		stage := &pipeline.Stages[i]
+
+		var err error
+		switch {
+		case len(stage.Parallel) > 0:
+			result, err = r.callParallel(ctx, i, stage.Parallel, result)
+		case len(stage.Branches) > 0:
+			if branch := selectBranch(stage.Branches, result); branch != nil {
+				result, err = r.callFunction(ctx, i, branch, result, true)
+			}
+		default:
+			if i == 0 {
+				result, err = r.callFunction(ctx, i, stage, nil, true)
+			} else if stage.When == nil || conditionHolds(stage.When, result) {
+				result, err = r.callFunction(ctx, i, stage, result, true)
+			}
+		}
+		if err != nil {
+			return nil, r.errors(), err
+		}
+	}
+
+	// Mask any fields of the result that the manifest declares sensitive.
+	result = redaction.Apply(ctx, ci.Pipeline, result)
+
+	// Call the functions that resolve nested fields of the result, if any were requested.
+	gqlErrors := r.errors()
+	if ds.hasFieldResolvers(&ci.FieldInfo) {
+		var err error
+		result, err = ds.resolveFields(ctx, result, ci, &gqlErrors)
+		if err != nil {
+			return nil, gqlErrors, err
+		}
+	}
+
+	return result, gqlErrors, nil
+}
+
+// pipelineRun holds the state of one execution of a pipeline.
+type pipelineRun struct {
+	ds        *ModusDataSource
+	ci        *callInfo
+	mu        sync.Mutex
+	gqlErrors []resolve.GraphQLError
+}
+
+func (r *pipelineRun) errors() []resolve.GraphQLError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.gqlErrors
+}
+
+// callFunction calls the function of a stage with the previous stage's result, or with the field's arguments for
+// the first stage.  When report is set, the stage's execution info becomes the one reported for the field.
+func (r *pipelineRun) callFunction(ctx context.Context, i int, stage *manifest.PipelineStageInfo, prev any, report bool) (any, error) {
+	ci := r.ci
+	fnInfo, err := r.ds.WasmHost.GetFunctionInfo(stage.Function)
+	if err != nil {
+		return nil, err
+	}
+
+	params := ci.Parameters
+	if i > 0 {
+		params, err = mapStageInputs(fnInfo, stage, prev)
+		if err != nil {
+			return nil, fmt.Errorf("error mapping inputs for stage %d (%s) of pipeline %s: %w", i+1, stage.Function, ci.Pipeline, err)
+		}
+	}
+
+	execInfo, err := r.ds.callPipelineStage(ctx, ci.Pipeline, i, fnInfo, params)
+	if execInfo != nil {
+		messages := append(execInfo.Messages(), utils.TransformConsoleOutput(execInfo.Buffers())...)
+		r.mu.Lock()
+		r.gqlErrors = append(r.gqlErrors, transformErrors(messages, ci)...)
+		r.mu.Unlock()
+	}
+	if isRejectedByDirective(err) {
+		return nil, err
+	} else if err != nil {
+		// The full error message has already been logged.
+		return nil, fmt.Errorf("error calling stage %d (%s) of pipeline %s", i+1, stage.Function, ci.Pipeline)
+	}
+
+	if report {
+		outputMap := ctx.Value(utils.FunctionOutputContextKey).(map[string]wasmhost.ExecutionInfo)
+		outputMap[ci.FieldInfo.AliasOrName()] = execInfo
+	}
+
+	return unpackResults(fnInfo, execInfo.Result()), nil
+}
+
+// callParallel calls the functions of a parallel stage at the same time, and combines their results into one
+// object, keyed by each function's result name.  Functions whose condition doesn't hold are left out.
+// If any function fails, the others are cancelled.
+func (r *pipelineRun) callParallel(ctx context.Context, i int, stages []manifest.PipelineStageInfo, prev any) (any, error) {
+	results := make([]any, len(stages))
+	called := make([]bool, len(stages))
+
+	g, gctx := errgroup.WithContext(ctx)
+	for j := range stages {
+		stage := &stages[j]
+		if stage.When != nil && !conditionHolds(stage.When, prev) {
+			continue
+		}
+		called[j] = true
+		g.Go(func() error {
+			result, err := r.callFunction(gctx, i, stage, prev, false)
+			results[j] = result
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	combined := make(map[string]any, len(stages))
+	for j := range stages {
+		if called[j] {
+			combined[stages[j].ResultName()] = results[j]
+		}
+	}
+	return combined, nil
+}
+
+// selectBranch returns the first branch whose condition holds on the previous result, or nil if there is none.
+func selectBranch(branches []manifest.PipelineStageInfo, prev any) *manifest.PipelineStageInfo {
+	for j := range branches {
+		b := &branches[j]
+		if b.When == nil || conditionHolds(b.When, prev) {
+			return b
+		}
+	}
+	return nil
+}
+
+// conditionHolds evaluates a stage condition on the previous result.
+func conditionHolds(cond *manifest.PipelineConditionInfo, prev any) bool {
+	holds := false
+	if cond.Expression != "" {
+		if p, err := expressions.Compile(cond.Expression); err == nil {
+			holds, _ = p.EvalBool(map[string]any{"result": prev})
+		}
+	} else if data, err := utils.JsonSerialize(prev); err == nil {
+		r := gjson.GetBytes(data, cond.Path)
+		if cond.Equals != nil {
+			holds = r.Exists() && reflect.DeepEqual(r.Value(), normalizeConditionValue(cond.Equals))
+		} else {
+			holds = isTruthy(r)
+		}
+	}
+	return holds != cond.Not
+}
+
+// normalizeConditionValue converts a value from the manifest to the form returned by gjson, so they can be compared.
+func normalizeConditionValue(v any) any {
+	data, err := utils.JsonSerialize(v)
+	if err != nil {
+		return v
+	}
+	return gjson.ParseBytes(data).Value()
+}
+
+// isTruthy reports whether a value is present, and is not false, null, zero, or empty.
+func isTruthy(r gjson.Result) bool {
+	switch r.Type {
+	case gjson.True:
+		return true
+	case gjson.Number:
+		return r.Num != 0
+	case gjson.String:
+		return r.Str != ""
+	case gjson.JSON:
+		if r.IsArray() {
+			return len(r.Array()) > 0
+		}
+		return len(r.Map()) > 0
+	default:
+		return false
+	}
+}
+
+// callPipelineStage calls the function for one stage of a pipeline, in its own trace span.
+func (ds *ModusDataSource) callPipelineStage(ctx context.Context, pipelineName string, stage int, fnInfo functions.FunctionInfo, params map[string]any) (wasmhost.ExecutionInfo, error) {
+	span, ctx := utils.NewSentrySpan(ctx, fmt.Sprintf("pipeline %s stage %d: %s", pipelineName, stage+1, fnInfo.Name()))
+	defer span.Finish()
+
+	start := time.Now()
+	execInfo, err := ds.invokeFunction(ctx, fnInfo, params)
+
+	var executionId string
+	if execInfo != nil {
+		executionId = execInfo.ExecutionId()
+	}
+	logger.Debug(ctx).
+		Str("pipeline", pipelineName).
+		Int("stage", stage+1).
+		Str("function", fnInfo.Name()).
+		Str("execution_id", executionId).
+		Dur("duration_ms", time.Since(start)).
+		Bool("success", err == nil).
+		Msg("Pipeline stage completed.")
+
+	return execInfo, err
+}
+
+// mapStageInputs builds the parameters for a pipeline stage from the result of the previous stage.
+// Without explicit inputs, a function with one parameter receives the whole result,
+// and otherwise the fields of the result are matched to the parameters by name.
+func mapStageInputs(fnInfo functions.FunctionInfo, stage *manifest.PipelineStageInfo, prev any) (map[string]any, error) {
+	data, err := utils.JsonSerialize(prev)
+	if err != nil {
+		return nil, err
+	}
+
+	params := fnInfo.Metadata().Parameters
+	if len(stage.Inputs) == 0 {
+		var value any
+		if err := utils.JsonDeserialize(data, &value); err != nil {
+			return nil, err
+		}
+		if len(params) == 1 {
+			return map[string]any{params[0].Name: value}, nil
+		}
+		if m, ok := value.(map[string]any); ok {
+			return m, nil
+		}
+		if len(params) == 0 {
+			return map[string]any{}, nil
+		}
+		return nil, fmt.Errorf("function %s has %d parameters, but the previous result is not an object", fnInfo.Name(), len(params))
+	}
+
+	results := make(map[string]any, len(stage.Inputs))
+	for name, path := range stage.Inputs {
+		r := gjson.GetBytes(data, path)
+		if !r.Exists() {
+			continue
+		}
+		var value any
+		if err := utils.JsonDeserialize([]byte(r.Raw), &value); err != nil {
+			return nil, err
+		}
+		results[name] = value
+	}
+	return results, nil
+}
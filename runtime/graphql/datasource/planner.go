@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"slices"
 
+	"github.com/hypermodeinc/modus/runtime/graphql/schemagen"
 	"github.com/hypermodeinc/modus/runtime/logger"
 	"github.com/hypermodeinc/modus/runtime/utils"
 
@@ -33,6 +34,9 @@ type HypDSPlanner struct {
 	template  struct {
 		fieldInfo    *fieldInfo
 		functionName string
+		collection   *schemagen.CollectionField
+		builtin      string
+		pipeline     string
 		data         []byte
 	}
 }
@@ -112,6 +116,11 @@ func (p *HypDSPlanner) EnterField(ref int) {
 
 		p.template.fieldInfo = f
 		p.template.functionName = p.config.FieldsToFunctions[f.Name]
+		if cf, ok := p.config.FieldsToCollections[f.Name]; ok {
+			p.template.collection = &cf
+		}
+		p.template.builtin = p.config.FieldsToBuiltins[f.Name]
+		p.template.pipeline = p.config.FieldsToPipelines[f.Name]
 
 		if err := p.captureInputData(ref); err != nil {
 			logger.Err(p.ctx, err).Msg("Error capturing input data.")
@@ -237,16 +246,37 @@ func (p *HypDSPlanner) ConfigureFetch() resolve.FetchConfiguration {
 		return resolve.FetchConfiguration{}
 	}
 
+	collectionJson, err := utils.JsonSerialize(p.template.collection)
+	if err != nil {
+		logger.Error(p.ctx).Err(err).Msg("Error serializing json while configuring graphql fetch.")
+		return resolve.FetchConfiguration{}
+	}
+
+	builtinJson, err := utils.JsonSerialize(p.template.builtin)
+	if err != nil {
+		logger.Error(p.ctx).Err(err).Msg("Error serializing json while configuring graphql fetch.")
+		return resolve.FetchConfiguration{}
+	}
+
+	pipelineJson, err := utils.JsonSerialize(p.template.pipeline)
+	if err != nil {
+		logger.Error(p.ctx).Err(err).Msg("Error serializing json while configuring graphql fetch.")
+		return resolve.FetchConfiguration{}
+	}
+
 	// Note: we have to build the rest of the template manually, because the data field may
 	// contain placeholders for variables, such as $$0$$ which are not valid in JSON.
 	// They are replaced with the actual values by the time Load is called.
-	inputTemplate := fmt.Sprintf(`{"field":%s,"function":%s,"data":%s}`, fieldInfoJson, functionNameJson, p.template.data)
+	inputTemplate := fmt.Sprintf(`{"field":%s,"function":%s,"collection":%s,"builtin":%s,"pipeline":%s,"data":%s}`, fieldInfoJson, functionNameJson, collectionJson, builtinJson, pipelineJson, p.template.data)
 
 	return resolve.FetchConfiguration{
 		Input:     inputTemplate,
 		Variables: p.variables,
 		DataSource: &ModusDataSource{
-			WasmHost: p.config.WasmHost,
+			WasmHost:           p.config.WasmHost,
+			FieldResolvers:     p.config.FieldResolvers,
+			FieldDirectives:    p.config.FieldDirectives,
+			FunctionDirectives: p.config.FunctionDirectives,
 		},
 		PostProcessing: resolve.PostProcessingConfiguration{
 			SelectResponseDataPath:   []string{"data"},
@@ -0,0 +1,258 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/functions"
+	"github.com/hypermodeinc/modus/runtime/redaction"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/engine/resolve"
+)
+
+// hasFieldResolvers reports whether any nested field in the selection is mapped to a resolver function.
+func (ds *ModusDataSource) hasFieldResolvers(fi *fieldInfo) bool {
+	if len(ds.FieldResolvers) == 0 {
+		return false
+	}
+	for i := range fi.Fields {
+		f := &fi.Fields[i]
+		if _, ok := ds.FieldResolvers[resolverKey(f)]; ok || ds.hasFieldResolvers(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveFields calls the resolver functions for the nested fields in the selection.
+// The result is resolved one level at a time.  At each level, all of the parent objects that need the same
+// resolver function are gathered, so that a batch function receives them in a single call, and the results
+// are stored in the parent objects by index.
+func (ds *ModusDataSource) resolveFields(ctx context.Context, result any, ci *callInfo, gqlErrors *[]resolve.GraphQLError) (any, error) {
+
+	// Normalize the result to plain maps and slices, which are also the form that parameters are passed in.
+	data, err := utils.JsonSerialize(result)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := utils.JsonDeserialize(data, &value); err != nil {
+		return nil, err
+	}
+
+	level := []selection{{collectObjects(value, nil), &ci.FieldInfo, []any{ci.FieldInfo.AliasOrName()}}}
+	for len(level) > 0 {
+		if err := ds.resolveLevel(ctx, level, ci, gqlErrors); err != nil {
+			return nil, err
+		}
+		level = ds.nextLevel(level)
+	}
+	return value, nil
+}
+
+// selection is a set of objects in the result that share the same selected fields.
+type selection struct {
+	objects []map[string]any
+	fi      *fieldInfo
+	path    []any
+}
+
+// resolverBatch holds the pending resolutions of a resolver function at one level of the result.
+type resolverBatch struct {
+	parents []any
+	index   map[string]int
+	targets []resolverTarget
+	fields  []tracedField
+}
+
+// tracedField is a field resolved by a resolver batch, for tracing.
+type tracedField struct {
+	path []any
+	fi   *fieldInfo
+}
+
+// resolverTarget is a field of an object that receives one of the results of a resolver batch.
+type resolverTarget struct {
+	obj    map[string]any
+	field  string
+	parent int
+}
+
+// resolveLevel resolves the fields of all selections at the same depth of the result.  Like a data loader, it
+// coalesces the resolutions that target the same function, even from different fields or different branches of
+// the result, and passes each distinct parent object to the function only once.  A batch function is called
+// once for all of the parents, and any other resolver function is called once for each parent.
+func (ds *ModusDataSource) resolveLevel(ctx context.Context, level []selection, ci *callInfo, gqlErrors *[]resolve.GraphQLError) error {
+	batches := make(map[string]*resolverBatch)
+	var fnNames []string
+
+	for _, sel := range level {
+		for i := range sel.fi.Fields {
+			f := &sel.fi.Fields[i]
+			fnName, ok := ds.FieldResolvers[resolverKey(f)]
+			if !ok {
+				continue
+			}
+
+			b, ok := batches[fnName]
+			if !ok {
+				b = &resolverBatch{index: make(map[string]int)}
+				batches[fnName] = b
+				fnNames = append(fnNames, fnName)
+			}
+			b.fields = append(b.fields, tracedField{append(slices.Clip(sel.path), f.AliasOrName()), f})
+
+			for _, obj := range sel.objects {
+				if _, resolved := obj[f.Name]; resolved {
+					continue
+				}
+
+				key, err := utils.JsonSerialize(obj)
+				if err != nil {
+					return err
+				}
+				idx, ok := b.index[string(key)]
+				if !ok {
+					// The resolvers receive the parent objects as they were returned, without any resolved fields.
+					idx = len(b.parents)
+					b.parents = append(b.parents, maps.Clone(obj))
+					b.index[string(key)] = idx
+				}
+				b.targets = append(b.targets, resolverTarget{obj, f.Name, idx})
+			}
+		}
+	}
+
+	for _, fnName := range fnNames {
+		b := batches[fnName]
+		if len(b.parents) == 0 {
+			continue
+		}
+
+		start := time.Now()
+		results, err := ds.callResolver(ctx, fnName, b.parents, ci, gqlErrors)
+		for _, f := range b.fields {
+			traceResolver(ctx, f.path, f.fi, start)
+		}
+		if err != nil {
+			return err
+		}
+		for _, t := range b.targets {
+			t.obj[t.field] = results[t.parent]
+		}
+	}
+
+	return nil
+}
+
+// nextLevel returns the selections of the objects nested in the fields of the given selections,
+// skipping any that have no fields to resolve.
+func (ds *ModusDataSource) nextLevel(level []selection) []selection {
+	var next []selection
+	for _, sel := range level {
+		for i := range sel.fi.Fields {
+			f := &sel.fi.Fields[i]
+			if !ds.hasFieldResolvers(f) {
+				continue
+			}
+
+			var children []map[string]any
+			for _, obj := range sel.objects {
+				children = collectObjects(obj[f.Name], children)
+			}
+			if len(children) > 0 {
+				next = append(next, selection{children, f, append(slices.Clip(sel.path), f.AliasOrName())})
+			}
+		}
+	}
+	return next
+}
+
+// collectObjects appends the objects in the value, which may be an object or a list of objects, to the slice.
+func collectObjects(value any, objects []map[string]any) []map[string]any {
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			objects = collectObjects(item, objects)
+		}
+	case map[string]any:
+		objects = append(objects, v)
+	}
+	return objects
+}
+
+// callResolver calls a resolver function for a list of parent objects, and returns one result for each parent,
+// in the same order.  A function that declares itself a batch function in its metadata is called once with the
+// list, and must return a list with one result for each parent.  Any other resolver function is called once for
+// each parent, with the parent object as its only parameter.
+func (ds *ModusDataSource) callResolver(ctx context.Context, fnName string, parents []any, ci *callInfo, gqlErrors *[]resolve.GraphQLError) ([]any, error) {
+	fnInfo, err := ds.WasmHost.GetFunctionInfo(fnName)
+	if err != nil {
+		return nil, err
+	}
+
+	params := fnInfo.Metadata().Parameters
+	if len(params) != 1 {
+		return nil, fmt.Errorf("resolver function %s must have exactly one parameter", fnName)
+	}
+
+	if fnInfo.Metadata().Batch {
+		data, err := ds.invokeResolver(ctx, fnInfo, map[string]any{params[0].Name: parents}, ci, gqlErrors)
+		if err != nil {
+			return nil, err
+		}
+		var results []any
+		if err := utils.JsonDeserialize(data, &results); err != nil || len(results) != len(parents) {
+			return nil, fmt.Errorf("resolver function %s must return a list with one result for each of the %d parent objects", fnName, len(parents))
+		}
+		return results, nil
+	}
+
+	results := make([]any, len(parents))
+	for i, parent := range parents {
+		data, err := ds.invokeResolver(ctx, fnInfo, map[string]any{params[0].Name: parent}, ci, gqlErrors)
+		if err != nil {
+			return nil, err
+		}
+		if err := utils.JsonDeserialize(data, &results[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// invokeResolver invokes a resolver function with the parameters, and returns its result as JSON.
+func (ds *ModusDataSource) invokeResolver(ctx context.Context, fnInfo functions.FunctionInfo, parameters map[string]any, ci *callInfo, gqlErrors *[]resolve.GraphQLError) ([]byte, error) {
+	fnName := fnInfo.Name()
+	execInfo, err := ds.invokeFunction(ctx, fnInfo, parameters)
+	if isRejectedByDirective(err) {
+		return nil, err
+	} else if err != nil {
+		// The full error message has already been logged.
+		return nil, fmt.Errorf("error calling resolver function %s", fnName)
+	}
+
+	messages := append(execInfo.Messages(), utils.TransformConsoleOutput(execInfo.Buffers())...)
+	*gqlErrors = append(*gqlErrors, transformErrors(messages, ci)...)
+
+	// Normalize the result, so that any fields it has are resolved in the same way,
+	// and mask any fields that the manifest declares sensitive.
+	return utils.JsonSerialize(redaction.Apply(ctx, fnName, execInfo.Result()))
+}
+
+func resolverKey(f *fieldInfo) string {
+	return f.ParentType + "." + f.Name
+}
@@ -0,0 +1,287 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/metadata"
+	"github.com/hypermodeinc/modus/runtime/functions"
+	"github.com/hypermodeinc/modus/runtime/langsupport"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/engine/resolve"
+)
+
+type mockFunctionInfo struct {
+	functions.FunctionInfo
+	md *metadata.Function
+}
+
+func (f *mockFunctionInfo) Name() string                             { return f.md.Name }
+func (f *mockFunctionInfo) Metadata() *metadata.Function             { return f.md }
+func (f *mockFunctionInfo) ExecutionPlan() langsupport.ExecutionPlan { return nil }
+
+type mockExecutionInfo struct {
+	result any
+}
+
+func (e *mockExecutionInfo) ExecutionId() string                      { return "" }
+func (e *mockExecutionInfo) Buffers() utils.OutputBuffers             { return nil }
+func (e *mockExecutionInfo) Messages() []utils.LogMessage             { return nil }
+func (e *mockExecutionInfo) Result() any                              { return e.result }
+func (e *mockExecutionInfo) Truncation() *wasmhost.ResponseTruncation { return nil }
+func (e *mockExecutionInfo) Trap() *wasmhost.WasmTrap                 { return nil }
+func (e *mockExecutionInfo) Memory() *wasmhost.MemoryUsage            { return nil }
+
+// mockWasmHost calls Go functions in place of resolver functions, and counts the calls.
+// The functions in resolvers are batch functions, and the functions in singleResolvers take a single parent.
+type mockWasmHost struct {
+	wasmhost.WasmHost
+	resolvers       map[string]func(parents []any) []any
+	singleResolvers map[string]func(parent any) any
+	calls           map[string]int
+	parents         map[string]int
+}
+
+func (h *mockWasmHost) GetFunctionInfo(fnName string) (functions.FunctionInfo, error) {
+	if _, ok := h.singleResolvers[fnName]; ok {
+		return &mockFunctionInfo{md: &metadata.Function{
+			Name:       fnName,
+			Parameters: []*metadata.Parameter{{Name: "parent", Type: "any"}},
+			Results:    []*metadata.Result{{Type: "any"}},
+		}}, nil
+	}
+	if _, ok := h.resolvers[fnName]; !ok {
+		return nil, fmt.Errorf("function %s not found", fnName)
+	}
+	return &mockFunctionInfo{md: &metadata.Function{
+		Name:       fnName,
+		Parameters: []*metadata.Parameter{{Name: "parents", Type: "[]any"}},
+		Results:    []*metadata.Result{{Type: "[]any"}},
+		Batch:      true,
+	}}, nil
+}
+
+func (h *mockWasmHost) CallFunction(ctx context.Context, fnInfo functions.FunctionInfo, parameters map[string]any) (wasmhost.ExecutionInfo, error) {
+	h.calls[fnInfo.Name()]++
+	if fn, ok := h.singleResolvers[fnInfo.Name()]; ok {
+		return &mockExecutionInfo{result: fn(parameters["parent"])}, nil
+	}
+	parents := parameters["parents"].([]any)
+	if h.parents != nil {
+		h.parents[fnInfo.Name()] += len(parents)
+	}
+	return &mockExecutionInfo{result: h.resolvers[fnInfo.Name()](parents)}, nil
+}
+
+func TestResolveFieldsCallsResolverOncePerList(t *testing.T) {
+	host := &mockWasmHost{
+		calls: map[string]int{},
+		resolvers: map[string]func(parents []any) []any{
+			"getReviews": func(parents []any) []any {
+				results := make([]any, len(parents))
+				for i, p := range parents {
+					id := p.(map[string]any)["id"]
+					results[i] = []any{
+						map[string]any{"text": fmt.Sprintf("review 1 of %v", id)},
+						map[string]any{"text": fmt.Sprintf("review 2 of %v", id)},
+					}
+				}
+				return results
+			},
+			"getAuthor": func(parents []any) []any {
+				results := make([]any, len(parents))
+				for i, p := range parents {
+					results[i] = map[string]any{"name": "author of " + p.(map[string]any)["text"].(string)}
+				}
+				return results
+			},
+		},
+	}
+
+	ds := &ModusDataSource{
+		WasmHost: host,
+		FieldResolvers: map[string]string{
+			"Product.reviews": "getReviews",
+			"Review.author":   "getAuthor",
+		},
+	}
+
+	ci := &callInfo{
+		FieldInfo: fieldInfo{
+			Name: "products",
+			Fields: []fieldInfo{
+				{Name: "id", ParentType: "Product"},
+				{Name: "reviews", ParentType: "Product", Fields: []fieldInfo{
+					{Name: "text", ParentType: "Review"},
+					{Name: "author", ParentType: "Review", Fields: []fieldInfo{
+						{Name: "name", ParentType: "Author"},
+					}},
+				}},
+			},
+		},
+	}
+
+	products := []any{
+		map[string]any{"id": 1},
+		map[string]any{"id": 2},
+		map[string]any{"id": 3},
+	}
+
+	var gqlErrors []resolve.GraphQLError
+	result, err := ds.resolveFields(context.Background(), products, ci, &gqlErrors)
+	require.NoError(t, err)
+	require.Empty(t, gqlErrors)
+
+	// one call for the three products, and one call for the six reviews
+	require.Equal(t, map[string]int{"getReviews": 1, "getAuthor": 1}, host.calls)
+
+	list := result.([]any)
+	require.Len(t, list, 3)
+	reviews := list[1].(map[string]any)["reviews"].([]any)
+	require.Len(t, reviews, 2)
+	require.Equal(t, "review 2 of 2", reviews[1].(map[string]any)["text"])
+	require.Equal(t, map[string]any{"name": "author of review 2 of 2"}, reviews[1].(map[string]any)["author"])
+}
+
+func TestResolveFieldsCoalescesCallsAcrossBranches(t *testing.T) {
+	host := &mockWasmHost{
+		calls:   map[string]int{},
+		parents: map[string]int{},
+		resolvers: map[string]func(parents []any) []any{
+			"getReviews": func(parents []any) []any {
+				results := make([]any, len(parents))
+				for i, p := range parents {
+					results[i] = []any{map[string]any{"text": fmt.Sprintf("review of %v", p.(map[string]any)["id"])}}
+				}
+				return results
+			},
+		},
+	}
+
+	ds := &ModusDataSource{
+		WasmHost: host,
+		FieldResolvers: map[string]string{
+			"Product.reviews":    "getReviews",
+			"Product.allReviews": "getReviews",
+			"Catalog.unrelated":  "missingFunction",
+		},
+	}
+
+	productFields := []fieldInfo{
+		{Name: "id", ParentType: "Product"},
+		{Name: "reviews", ParentType: "Product", Fields: []fieldInfo{{Name: "text", ParentType: "Review"}}},
+		{Name: "allReviews", ParentType: "Product", Fields: []fieldInfo{{Name: "text", ParentType: "Review"}}},
+	}
+	ci := &callInfo{
+		FieldInfo: fieldInfo{
+			Name: "catalog",
+			Fields: []fieldInfo{
+				{Name: "featured", ParentType: "Catalog", Fields: productFields},
+				{Name: "onSale", ParentType: "Catalog", Fields: productFields},
+				{Name: "unrelated", ParentType: "Catalog"},
+			},
+		},
+	}
+
+	catalog := map[string]any{
+		"featured":  []any{map[string]any{"id": 1}, map[string]any{"id": 2}},
+		"onSale":    []any{map[string]any{"id": 2}, map[string]any{"id": 3}},
+		"unrelated": "already set",
+	}
+
+	var gqlErrors []resolve.GraphQLError
+	result, err := ds.resolveFields(context.Background(), catalog, ci, &gqlErrors)
+	require.NoError(t, err)
+
+	// one call for both fields in both lists, with each distinct product passed once
+	require.Equal(t, map[string]int{"getReviews": 1}, host.calls)
+	require.Equal(t, map[string]int{"getReviews": 3}, host.parents)
+
+	onSale := result.(map[string]any)["onSale"].([]any)
+	for _, field := range []string{"reviews", "allReviews"} {
+		require.Equal(t, []any{map[string]any{"text": "review of 2"}}, onSale[0].(map[string]any)[field])
+		require.Equal(t, []any{map[string]any{"text": "review of 3"}}, onSale[1].(map[string]any)[field])
+	}
+}
+
+func TestResolveFieldsCallsSingleParentResolverOncePerParent(t *testing.T) {
+	host := &mockWasmHost{
+		calls: map[string]int{},
+		singleResolvers: map[string]func(parent any) any{
+			"getMaker": func(parent any) any {
+				return map[string]any{"name": fmt.Sprintf("maker of %v", parent.(map[string]any)["id"])}
+			},
+		},
+	}
+
+	ds := &ModusDataSource{
+		WasmHost:       host,
+		FieldResolvers: map[string]string{"Product.maker": "getMaker"},
+	}
+
+	ci := &callInfo{
+		FieldInfo: fieldInfo{
+			Name: "products",
+			Fields: []fieldInfo{
+				{Name: "id", ParentType: "Product"},
+				{Name: "maker", ParentType: "Product", Fields: []fieldInfo{{Name: "name", ParentType: "Maker"}}},
+			},
+		},
+	}
+
+	products := []any{
+		map[string]any{"id": 1},
+		map[string]any{"id": 2},
+		map[string]any{"id": 1},
+	}
+
+	var gqlErrors []resolve.GraphQLError
+	result, err := ds.resolveFields(context.Background(), products, ci, &gqlErrors)
+	require.NoError(t, err)
+
+	// one call for each distinct product
+	require.Equal(t, map[string]int{"getMaker": 2}, host.calls)
+
+	list := result.([]any)
+	require.Equal(t, map[string]any{"name": "maker of 1"}, list[0].(map[string]any)["maker"])
+	require.Equal(t, map[string]any{"name": "maker of 2"}, list[1].(map[string]any)["maker"])
+	require.Equal(t, map[string]any{"name": "maker of 1"}, list[2].(map[string]any)["maker"])
+}
+
+func TestResolveFieldsRequiresOneResultPerParent(t *testing.T) {
+	host := &mockWasmHost{
+		calls: map[string]int{},
+		resolvers: map[string]func(parents []any) []any{
+			"getReviews": func(parents []any) []any { return parents[:1] },
+		},
+	}
+
+	ds := &ModusDataSource{
+		WasmHost:       host,
+		FieldResolvers: map[string]string{"Product.reviews": "getReviews"},
+	}
+
+	ci := &callInfo{
+		FieldInfo: fieldInfo{
+			Name:   "products",
+			Fields: []fieldInfo{{Name: "reviews", ParentType: "Product"}},
+		},
+	}
+
+	var gqlErrors []resolve.GraphQLError
+	_, err := ds.resolveFields(context.Background(), []any{map[string]any{"id": 1}, map[string]any{"id": 2}}, ci, &gqlErrors)
+	require.ErrorContains(t, err, "must return a list with one result for each of the 2 parent objects")
+}
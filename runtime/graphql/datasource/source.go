@@ -15,8 +15,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/directives"
+	"github.com/hypermodeinc/modus/runtime/functions"
+	"github.com/hypermodeinc/modus/runtime/graphql/schemagen"
+	"github.com/hypermodeinc/modus/runtime/langsupport"
 	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/postprocess"
+	"github.com/hypermodeinc/modus/runtime/redaction"
+	"github.com/hypermodeinc/modus/runtime/shadow"
 	"github.com/hypermodeinc/modus/runtime/utils"
 	"github.com/hypermodeinc/modus/runtime/wasmhost"
 
@@ -28,13 +37,19 @@ import (
 const DataSourceName = "ModusDataSource"
 
 type callInfo struct {
-	FieldInfo    fieldInfo      `json:"field"`
-	FunctionName string         `json:"function"`
-	Parameters   map[string]any `json:"data"`
+	FieldInfo    fieldInfo                  `json:"field"`
+	FunctionName string                     `json:"function"`
+	Collection   *schemagen.CollectionField `json:"collection"`
+	Builtin      string                     `json:"builtin"`
+	Pipeline     string                     `json:"pipeline"`
+	Parameters   map[string]any             `json:"data"`
 }
 
 type ModusDataSource struct {
-	WasmHost wasmhost.WasmHost
+	WasmHost           wasmhost.WasmHost
+	FieldResolvers     map[string]string
+	FieldDirectives    map[string][]*schemagen.Directive
+	FunctionDirectives map[string][]*schemagen.Directive
 }
 
 func (ds *ModusDataSource) Load(ctx context.Context, input []byte, out *bytes.Buffer) error {
@@ -47,7 +62,9 @@ func (ds *ModusDataSource) Load(ctx context.Context, input []byte, out *bytes.Bu
 	}
 
 	// Load the data
+	start := time.Now()
 	result, gqlErrors, err := ds.callFunction(ctx, &ci)
+	traceResolver(ctx, []any{ci.FieldInfo.AliasOrName()}, &ci.FieldInfo, start)
 
 	// Write the response
 	err = writeGraphQLResponse(ctx, out, result, gqlErrors, err, &ci)
@@ -70,6 +87,28 @@ func (ds *ModusDataSource) callFunction(ctx context.Context, callInfo *callInfo)
 		return callInfo.FieldInfo.ParentType, nil, nil
 	}
 
+	// Run the middlewares for any custom directives applied to the field
+	if err := directives.Apply(ctx, callInfo.FieldInfo.Name, ds.FieldDirectives[callInfo.FieldInfo.Name]); err != nil {
+		return nil, nil, err
+	}
+
+	// Built-in collection fields are handled by the runtime, without calling a function
+	if callInfo.Collection != nil {
+		result, err := ds.callCollection(ctx, callInfo)
+		return result, nil, err
+	}
+
+	// Other built-in fields are also handled by the runtime
+	if callInfo.Builtin != "" {
+		result, err := ds.callBuiltin(ctx, callInfo)
+		return result, nil, err
+	}
+
+	// Pipelines call a chain of functions
+	if callInfo.Pipeline != "" {
+		return ds.callPipeline(ctx, callInfo)
+	}
+
 	// Get the function info
 	fnInfo, err := ds.WasmHost.GetFunctionInfo(callInfo.FunctionName)
 	if err != nil {
@@ -77,8 +116,17 @@ func (ds *ModusDataSource) callFunction(ctx context.Context, callInfo *callInfo)
 	}
 
 	// Call the function
-	execInfo, err := ds.WasmHost.CallFunction(ctx, fnInfo, callInfo.Parameters)
-	if err != nil {
+	execInfo, err := ds.invokeFunction(ctx, fnInfo, callInfo.Parameters)
+	if langsupport.IsResponseTooLarge(err) || isRejectedByDirective(err) {
+		// The caller is told that the response was too large, and what the limit is, or which directive rejected the call.
+		return nil, nil, err
+	} else if err != nil {
+		// In development, the stack trace of a trap is included in the response, with the function's invocation.
+		if execInfo != nil && execInfo.Trap() != nil && config.IsDevEnvironment() {
+			outputMap := ctx.Value(utils.FunctionOutputContextKey).(map[string]wasmhost.ExecutionInfo)
+			outputMap[callInfo.FieldInfo.AliasOrName()] = execInfo
+		}
+
 		// The full error message has already been logged.  Return a generic error to the caller, which will be included in the response.
 		return nil, nil, errors.New("error calling function")
 	}
@@ -92,37 +140,103 @@ func (ds *ModusDataSource) callFunction(ctx context.Context, callInfo *callInfo)
 	gqlErrors := transformErrors(messages, callInfo)
 
 	// Get the result.
-	result := execInfo.Result()
-
-	// If we have multiple results, unpack them into a map that matches the schema generated type.
-	if results, ok := result.([]any); ok && len(fnInfo.ExecutionPlan().ResultHandlers()) > 1 {
-		fnMeta := fnInfo.Metadata()
-		m := make(map[string]any, len(results))
-		for i, r := range results {
-			name := fnMeta.Results[i].Name
-			if name == "" {
-				name = fmt.Sprintf("item%d", i+1)
-			}
-			m[name] = r
+	result := unpackResults(fnInfo, execInfo.Result())
+
+	// Apply any post-processing steps declared in the manifest.
+	result, err = postprocess.Apply(ctx, fnInfo.Name(), result)
+	if err != nil {
+		logger.Error(ctx).Err(err).Str("function", fnInfo.Name()).Bool("user_visible", true).Msg("Error post-processing function result.")
+		return nil, gqlErrors, errors.New("error post-processing function result")
+	}
+
+	// Pass a string of JSON through to the response verbatim, for a field of the JSON scalar type.
+	result, err = asRawJson(result, &callInfo.FieldInfo)
+	if err != nil {
+		logger.Error(ctx).Err(err).Str("function", fnInfo.Name()).Bool("user_visible", true).Msg("Function returned invalid JSON.")
+		return nil, gqlErrors, err
+	}
+
+	// Mask any fields of the result that the manifest declares sensitive.
+	result = redaction.Apply(ctx, fnInfo.Name(), result)
+
+	// Call the functions that resolve nested fields of the result, if any were requested.
+	if ds.hasFieldResolvers(&callInfo.FieldInfo) {
+		result, err = ds.resolveFields(ctx, result, callInfo, &gqlErrors)
+		if err != nil {
+			return nil, gqlErrors, err
 		}
-		result = m
 	}
 
 	return result, gqlErrors, err
 }
 
+func (ds *ModusDataSource) invokeFunction(ctx context.Context, fnInfo functions.FunctionInfo, parameters map[string]any) (wasmhost.ExecutionInfo, error) {
+
+	// Run the middlewares for any custom directives of the function, or of the types that it returns
+	if err := directives.ApplyToFunction(ctx, fnInfo.Name(), ds.FunctionDirectives[fnInfo.Name()]); err != nil {
+		return nil, err
+	}
+
+	// Call the function, which the WASM host executes in a sandboxed worker process if the manifest requires process isolation
+	start := time.Now()
+	execInfo, err := ds.WasmHost.CallFunction(ctx, fnInfo, parameters)
+
+	// Duplicate a sample of calls to the shadow build of the plugin, if there is one
+	shadow.Mirror(ctx, fnInfo, parameters, execInfo, err, time.Since(start))
+
+	return execInfo, err
+}
+
+func isRejectedByDirective(err error) bool {
+	var rejected *directives.RejectedError
+	return errors.As(err, &rejected)
+}
+
+// unpackResults converts multiple results into a map that matches the schema generated type.
+func unpackResults(fnInfo functions.FunctionInfo, result any) any {
+	results, ok := result.([]any)
+	if !ok || !hasMultipleResults(fnInfo) {
+		return result
+	}
+
+	fnMeta := fnInfo.Metadata()
+	m := make(map[string]any, len(results))
+	for i, r := range results {
+		name := fnMeta.Results[i].Name
+		if name == "" {
+			name = fmt.Sprintf("item%d", i+1)
+		}
+		m[name] = r
+	}
+	return m
+}
+
+func hasMultipleResults(fnInfo functions.FunctionInfo) bool {
+	// a lazily loaded plugin is not compiled in this process if the function was executed in a sandbox
+	if plan := fnInfo.ExecutionPlan(); plan != nil {
+		return len(plan.ResultHandlers()) > 1
+	}
+	return len(fnInfo.Metadata().Results) > 1
+}
+
 func writeGraphQLResponse(ctx context.Context, out *bytes.Buffer, result any, gqlErrors []resolve.GraphQLError, fnErr error, ci *callInfo) error {
 
 	fieldName := ci.FieldInfo.AliasOrName()
 
 	// Include the function error
 	if fnErr != nil {
+		extensions := map[string]interface{}{
+			"level": "error",
+		}
+		var tooLarge *langsupport.ResponseTooLargeError
+		if errors.As(fnErr, &tooLarge) {
+			extensions["code"] = "RESPONSE_TOO_LARGE"
+			extensions["maxBytes"] = tooLarge.MaxBytes
+		}
 		gqlErrors = append(gqlErrors, resolve.GraphQLError{
-			Message: fnErr.Error(),
-			Path:    []any{fieldName},
-			Extensions: map[string]interface{}{
-				"level": "error",
-			},
+			Message:    fnErr.Error(),
+			Path:       []any{fieldName},
+			Extensions: extensions,
 		})
 	}
 
@@ -139,7 +253,14 @@ func writeGraphQLResponse(ctx context.Context, out *bytes.Buffer, result any, gq
 	// If there is any result data, or if the data is null without errors, serialize the data as json
 	var jsonData []byte
 	if result != nil || len(gqlErrors) == 0 {
-		jsonResult, err := utils.JsonSerialize(result)
+		var jsonResult []byte
+		var err error
+		if raw, ok := result.(json.RawMessage); ok {
+			// raw JSON was validated when the function returned it
+			jsonResult = raw
+		} else {
+			jsonResult, err = utils.JsonSerialize(result)
+		}
 		if err != nil {
 			if err, ok := err.(*json.UnsupportedValueError); ok {
 				msg := fmt.Sprintf("Function completed successfully, but the result contains a %v value that cannot be serialized to JSON.", err.Value)
@@ -154,9 +275,20 @@ func writeGraphQLResponse(ctx context.Context, out *bytes.Buffer, result any, gq
 			return err
 		}
 
-		// Transform the data
+		// Transform the data.  If that fails, the field is null and the error is reported at the path where it occurred.
 		if r, err := transformValue(jsonResult, &ci.FieldInfo); err != nil {
-			return err
+			gqlError := resolve.GraphQLError{
+				Message: err.Error(),
+				Path:    errorPath(fieldName, err),
+				Extensions: map[string]interface{}{
+					"level": "error",
+				},
+			}
+			jsonErrors, err = utils.JsonSerialize(append(gqlErrors, gqlError))
+			if err != nil {
+				return err
+			}
+			jsonData = nullWord
 		} else {
 			jsonData = r
 		}
@@ -186,6 +318,23 @@ func writeGraphQLResponse(ctx context.Context, out *bytes.Buffer, result any, gq
 
 var nullWord = []byte("null")
 
+// asRawJson returns the result of a function as raw JSON, if it is a string of JSON for a field of the JSON scalar type,
+// so that it is written to the response verbatim rather than being parsed and serialized again.
+func asRawJson(result any, fi *fieldInfo) (any, error) {
+	s, ok := result.(string)
+	if !ok || fi.TypeName != "JSON" || len(fi.Fields) > 0 {
+		return result, nil
+	}
+
+	if s == "" {
+		return nil, nil
+	}
+	if !json.Valid([]byte(s)) {
+		return nil, errors.New("function returned invalid JSON")
+	}
+	return json.RawMessage(s), nil
+}
+
 func transformValue(data []byte, tf *fieldInfo) (result []byte, err error) {
 	if len(tf.Fields) == 0 || len(data) == 0 || bytes.Equal(data, nullWord) {
 		return data, nil
@@ -210,15 +359,17 @@ func transformArray(data []byte, tf *fieldInfo) ([]byte, error) {
 	buf.WriteByte('[')
 
 	var loopErr error
+	index := 0
 	_, err := jsonparser.ArrayEach(data, func(val []byte, _ jsonparser.ValueType, _ int, _ error) {
 		if loopErr != nil {
 			return
 		}
 		val, err := transformValue(val, tf)
 		if err != nil {
-			loopErr = err
+			loopErr = withPathSegment(err, index)
 			return
 		}
+		index++
 		if buf.Len() > 1 {
 			buf.WriteByte(',')
 		}
@@ -245,7 +396,7 @@ func transformObject(data []byte, tf *fieldInfo) ([]byte, error) {
 		} else {
 			v, dataType, _, err := jsonparser.Get(data, f.Name)
 			if err != nil {
-				return nil, err
+				return nil, withPathSegment(err, f.AliasOrName())
 			}
 			if dataType == jsonparser.String {
 				// Note, string values here will be escaped for internal quotes, newlines, etc.,
@@ -254,7 +405,7 @@ func transformObject(data []byte, tf *fieldInfo) ([]byte, error) {
 			}
 			val, err = transformValue(v, &f)
 			if err != nil {
-				return nil, err
+				return nil, withPathSegment(err, f.AliasOrName())
 			}
 		}
 		if i > 0 {
@@ -287,6 +438,7 @@ func transformMap(data []byte, tf *fieldInfo) ([]byte, error) {
 
 	buf := bytes.Buffer{}
 	buf.WriteByte('[')
+	index := 0
 	if err := jsonparser.ObjectEach(data, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
 		if buf.Len() > 1 {
 			buf.WriteByte(',')
@@ -316,9 +468,10 @@ func transformMap(data []byte, tf *fieldInfo) ([]byte, error) {
 
 		val, err := transformObject(b.Bytes(), tf)
 		if err != nil {
-			return err
+			return withPathSegment(err, index)
 		}
 		buf.Write(val)
+		index++
 
 		return nil
 	}); err != nil {
@@ -334,6 +487,7 @@ func transformPseudoMap(data []byte, tf *fieldInfo) ([]byte, error) {
 	buf.WriteByte('[')
 
 	var loopErr error
+	index := 0
 	_, err := jsonparser.ArrayEach(data, func(item []byte, _ jsonparser.ValueType, _ int, _ error) {
 		if loopErr != nil {
 			return
@@ -377,10 +531,11 @@ func transformPseudoMap(data []byte, tf *fieldInfo) ([]byte, error) {
 
 		val, err := transformObject(b.Bytes(), tf)
 		if err != nil {
-			loopErr = err
+			loopErr = withPathSegment(err, index)
 			return
 		}
 		buf.Write(val)
+		index++
 	})
 	if err != nil {
 		return nil, err
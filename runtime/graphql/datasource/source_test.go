@@ -0,0 +1,44 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawJsonResultIsWrittenVerbatim(t *testing.T) {
+	ci := &callInfo{FieldInfo: fieldInfo{Name: "getReport", TypeName: "JSON"}}
+
+	// spacing and key order are kept, because the JSON is not parsed and serialized again
+	result, err := asRawJson(`{"b": 1, "a": [true, null]}`, &ci.FieldInfo)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, writeGraphQLResponse(context.Background(), &out, result, nil, nil, ci))
+	require.Equal(t, `{"data":{"getReport":{"b": 1, "a": [true, null]}}}`, out.String())
+
+	_, err = asRawJson(`{"b": 1`, &ci.FieldInfo)
+	require.Error(t, err)
+
+	result, err = asRawJson("", &ci.FieldInfo)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestStringResultIsNotRawJson(t *testing.T) {
+	fi := &fieldInfo{Name: "getName", TypeName: "String"}
+	result, err := asRawJson(`{"b": 1}`, fi)
+	require.NoError(t, err)
+	require.Equal(t, `{"b": 1}`, result)
+}
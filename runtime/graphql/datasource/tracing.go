@@ -0,0 +1,112 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package datasource
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ApolloTracing collects the timing of the fields that are resolved by the runtime during a GraphQL request, for the
+// tracing extension of the response, in the Apollo tracing format.  See https://github.com/apollographql/apollo-tracing
+//
+// The root fields are each timed individually.  Nested fields are resolved in batches, one call to a resolver
+// function for all the objects at the same depth, so a nested field is timed once per batch, and its path is
+// given by the field names alone, without the list indexes of the objects it was resolved for.
+type ApolloTracing struct {
+	start     time.Time
+	execStart time.Time
+	mu        sync.Mutex
+	resolvers []apolloResolverTrace
+}
+
+type apolloResolverTrace struct {
+	Path        []any  `json:"path"`
+	ParentType  string `json:"parentType"`
+	FieldName   string `json:"fieldName"`
+	ReturnType  string `json:"returnType"`
+	StartOffset int64  `json:"startOffset"`
+	Duration    int64  `json:"duration"`
+}
+
+type apolloTracingPhase struct {
+	StartOffset int64 `json:"startOffset"`
+	Duration    int64 `json:"duration"`
+}
+
+type apolloTracingExtension struct {
+	Version    int                `json:"version"`
+	StartTime  time.Time          `json:"startTime"`
+	EndTime    time.Time          `json:"endTime"`
+	Duration   int64              `json:"duration"`
+	Parsing    apolloTracingPhase `json:"parsing"`
+	Validation apolloTracingPhase `json:"validation"`
+	Execution  struct {
+		Resolvers []apolloResolverTrace `json:"resolvers"`
+	} `json:"execution"`
+}
+
+type apolloTracingKey struct{}
+
+// ContextWithApolloTracing returns a context in which the fields resolved during a request are timed.
+// The start is the time the request was received.
+func ContextWithApolloTracing(ctx context.Context, start time.Time) (context.Context, *ApolloTracing) {
+	t := &ApolloTracing{start: start}
+	return context.WithValue(ctx, apolloTracingKey{}, t), t
+}
+
+// StartExecution marks the end of parsing the request, and the start of executing the operation.
+// The engine validates the operation as part of executing it, so validation is not timed separately.
+func (t *ApolloTracing) StartExecution() {
+	t.execStart = time.Now()
+}
+
+// Extension returns the tracing extension of the response, for a request that ended at the given time.
+func (t *ApolloTracing) Extension(end time.Time) any {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var parsed int64
+	if !t.execStart.IsZero() {
+		parsed = t.execStart.Sub(t.start).Nanoseconds()
+	}
+	ext := &apolloTracingExtension{
+		Version:    1,
+		StartTime:  t.start.UTC(),
+		EndTime:    end.UTC(),
+		Duration:   end.Sub(t.start).Nanoseconds(),
+		Parsing:    apolloTracingPhase{StartOffset: 0, Duration: parsed},
+		Validation: apolloTracingPhase{StartOffset: parsed, Duration: 0},
+	}
+	ext.Execution.Resolvers = append([]apolloResolverTrace{}, t.resolvers...)
+	return ext
+}
+
+// traceResolver records the timing of a field that was resolved, if the request is traced.
+func traceResolver(ctx context.Context, path []any, fi *fieldInfo, start time.Time) {
+	t, ok := ctx.Value(apolloTracingKey{}).(*ApolloTracing)
+	if !ok {
+		return
+	}
+
+	trace := apolloResolverTrace{
+		Path:        path,
+		ParentType:  fi.ParentType,
+		FieldName:   fi.Name,
+		ReturnType:  fi.TypeName,
+		StartOffset: start.Sub(t.start).Nanoseconds(),
+		Duration:    time.Since(start).Nanoseconds(),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resolvers = append(t.resolvers, trace)
+}
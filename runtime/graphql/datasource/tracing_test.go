@@ -0,0 +1,52 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package datasource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestApolloTracing(t *testing.T) {
+	start := time.Now().Add(-10 * time.Millisecond)
+
+	// fields are not traced unless the request is
+	traceResolver(context.Background(), []any{"user"}, &fieldInfo{Name: "user"}, start)
+
+	ctx, tracing := ContextWithApolloTracing(context.Background(), start)
+	tracing.StartExecution()
+
+	fi := &fieldInfo{Name: "user", Alias: "me", TypeName: "User", ParentType: "Query"}
+	traceResolver(ctx, []any{fi.AliasOrName()}, fi, time.Now())
+	traceResolver(ctx, []any{"me", "posts"}, &fieldInfo{Name: "posts", TypeName: "Post", ParentType: "User"}, time.Now())
+
+	data, err := utils.JsonSerialize(tracing.Extension(time.Now()))
+	require.NoError(t, err)
+
+	ext := gjson.ParseBytes(data)
+	require.Equal(t, int64(1), ext.Get("version").Int())
+	require.GreaterOrEqual(t, ext.Get("duration").Int(), int64(10*time.Millisecond))
+	require.Greater(t, ext.Get("parsing.duration").Int(), int64(0))
+
+	resolvers := ext.Get("execution.resolvers").Array()
+	require.Len(t, resolvers, 2)
+	require.Equal(t, `["me"]`, resolvers[0].Get("path").Raw)
+	require.Equal(t, "user", resolvers[0].Get("fieldName").String())
+	require.Equal(t, "Query", resolvers[0].Get("parentType").String())
+	require.Equal(t, "User", resolvers[0].Get("returnType").String())
+	require.Equal(t, `["me","posts"]`, resolvers[1].Get("path").Raw)
+	require.GreaterOrEqual(t, resolvers[1].Get("startOffset").Int(), resolvers[0].Get("startOffset").Int())
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package graphql
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/hypermodeinc/modus/lib/metadata"
+	"github.com/hypermodeinc/modus/runtime/logger"
+)
+
+var previousMetadata = make(map[string]*metadata.Metadata)
+var previousMetadataMutex sync.Mutex
+
+// reportSchemaChanges logs the functions that were added, removed, or changed since the plugin was last loaded,
+// so that a developer can see how each rebuild affects the GraphQL schema.
+func reportSchemaChanges(ctx context.Context, md *metadata.Metadata) {
+	previousMetadataMutex.Lock()
+	prev, found := previousMetadata[md.Name()]
+	previousMetadata[md.Name()] = md
+	previousMetadataMutex.Unlock()
+
+	if !found {
+		return
+	}
+
+	names := make([]string, 0, len(md.FnExports)+len(prev.FnExports))
+	for name := range md.FnExports {
+		names = append(names, name)
+	}
+	for name := range prev.FnExports {
+		if _, ok := md.FnExports[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	changes := 0
+	for _, name := range names {
+		before, existed := prev.FnExports[name]
+		after, exists := md.FnExports[name]
+
+		var msg string
+		switch {
+		case !existed:
+			msg = "Function was added."
+		case !exists:
+			msg = "Function was removed."
+		case before.String() != after.String():
+			msg = "Function signature changed."
+		default:
+			continue
+		}
+
+		changes++
+		l := logger.Info(ctx).Str("function", name).Bool("user_visible", true)
+		if existed {
+			l = l.Str("before", before.String())
+		}
+		if exists {
+			l = l.Str("after", after.String())
+		}
+		l.Msg(msg)
+	}
+
+	if changes > 0 {
+		logger.Info(ctx).
+			Str("plugin", md.Name()).
+			Int("changes", changes).
+			Bool("user_visible", true).
+			Msg("Regenerated GraphQL schema.")
+	}
+}
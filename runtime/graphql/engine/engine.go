@@ -20,6 +20,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/hypermodeinc/modus/lib/metadata"
 	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/directives"
 	"github.com/hypermodeinc/modus/runtime/graphql/datasource"
 	"github.com/hypermodeinc/modus/runtime/graphql/schemagen"
 	"github.com/hypermodeinc/modus/runtime/logger"
@@ -80,6 +81,18 @@ func generateSchema(ctx context.Context, md *metadata.Metadata) (*gql.Schema, *d
 		return nil, nil, err
 	}
 
+	// Reject any directives that their middlewares can't evaluate, rather than failing every call that uses them
+	for name, dirs := range generated.FieldDirectives {
+		if err := directives.Validate("field "+name, dirs); err != nil {
+			return nil, nil, err
+		}
+	}
+	for name, dirs := range generated.FunctionDirectives {
+		if err := directives.Validate("function "+name, dirs); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	if utils.DebugModeEnabled() {
 		if config.UseJsonLogging {
 			logger.Debug(ctx).Str("schema", generated.Schema).Msg("Generated schema")
@@ -94,9 +107,15 @@ func generateSchema(ctx context.Context, md *metadata.Metadata) (*gql.Schema, *d
 	}
 
 	cfg := &datasource.HypDSConfig{
-		WasmHost:          wasmhost.GetWasmHost(ctx),
-		FieldsToFunctions: generated.FieldsToFunctions,
-		MapTypes:          generated.MapTypes,
+		WasmHost:            wasmhost.GetWasmHost(ctx),
+		FieldsToFunctions:   generated.FieldsToFunctions,
+		FieldsToCollections: generated.FieldsToCollections,
+		FieldsToBuiltins:    generated.FieldsToBuiltins,
+		FieldsToPipelines:   generated.FieldsToPipelines,
+		FieldResolvers:      generated.FieldResolvers,
+		FieldDirectives:     generated.FieldDirectives,
+		FunctionDirectives:  generated.FunctionDirectives,
+		MapTypes:            generated.MapTypes,
 	}
 
 	return schema, cfg, nil
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// operationFingerprint returns the type of the GraphQL operation and a fingerprint of its shape.  The fingerprint
+// is the operation name and a hash of the query, from which string and numeric literals, comments, commas, and
+// insignificant whitespace are removed, so that it is the same for every request of the operation.
+func operationFingerprint(operationName, query string) (operationType, fingerprint string) {
+	tokens := tokenizeQuery(query)
+	operationType = findOperationType(tokens, operationName)
+
+	h := sha256.Sum256([]byte(strings.Join(tokens, " ")))
+	if operationName == "" {
+		operationName = "anonymous"
+	}
+	return operationType, operationName + ":" + hex.EncodeToString(h[:8])
+}
+
+// tokenizeQuery splits a GraphQL document into its tokens, replacing each string or numeric literal with "?".
+func tokenizeQuery(query string) []string {
+	var tokens []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+
+		case c == '#':
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+
+		case strings.HasPrefix(query[i:], `"""`):
+			i += 3
+			for i < len(query) && !strings.HasPrefix(query[i:], `"""`) {
+				if strings.HasPrefix(query[i:], `\"""`) {
+					i += 4
+				} else {
+					i++
+				}
+			}
+			i += 3
+			tokens = append(tokens, "?")
+
+		case c == '"':
+			i++
+			for i < len(query) && query[i] != '"' && query[i] != '\n' {
+				if query[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+			tokens = append(tokens, "?")
+
+		case c == '-' || isDigit(c):
+			i++
+			for i < len(query) && (isDigit(query[i]) || strings.IndexByte(".eE+-", query[i]) >= 0) {
+				i++
+			}
+			tokens = append(tokens, "?")
+
+		case isNameStart(c):
+			start := i
+			for i < len(query) && (isNameStart(query[i]) || isDigit(query[i])) {
+				i++
+			}
+			tokens = append(tokens, query[start:i])
+
+		case strings.HasPrefix(query[i:], "..."):
+			i += 3
+			tokens = append(tokens, "...")
+
+		default:
+			i++
+			tokens = append(tokens, string(c))
+		}
+	}
+	return tokens
+}
+
+// findOperationType returns the type of the named operation in the tokens of a GraphQL document,
+// or of the first operation if no name is given.
+func findOperationType(tokens []string, operationName string) string {
+	depth := 0
+	for i, t := range tokens {
+		switch t {
+		case "{":
+			// a selection set at the top level is a query without the query keyword
+			if depth == 0 && operationName == "" && (i == 0 || tokens[i-1] == "}") {
+				return "query"
+			}
+			depth++
+		case "}":
+			depth--
+		case "query", "mutation", "subscription":
+			if depth != 0 {
+				continue
+			}
+			if operationName == "" {
+				return t
+			}
+			if i+1 < len(tokens) && tokens[i+1] == operationName {
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package graphql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationFingerprint(t *testing.T) {
+	opType, fp1 := operationFingerprint("GetUser", `query GetUser { user(id: 123, name: "alice") { id name } }`)
+	assert.Equal(t, "query", opType)
+	assert.True(t, strings.HasPrefix(fp1, "GetUser:"))
+
+	// literal values, comments, commas, and whitespace don't change the fingerprint
+	_, fp2 := operationFingerprint("GetUser", `
+		# look up a user
+		query GetUser {
+			user(id: -4.5e3 name: "bob \"b\"") { id, name }
+		}`)
+	assert.Equal(t, fp1, fp2)
+
+	// a different shape does
+	_, fp3 := operationFingerprint("GetUser", `query GetUser { user(id: 123) { id email } }`)
+	assert.NotEqual(t, fp1, fp3)
+
+	_, fp4 := operationFingerprint("", `{ user(id: 1) { id } }`)
+	assert.True(t, strings.HasPrefix(fp4, "anonymous:"))
+}
+
+func TestFindOperationType(t *testing.T) {
+	doc := `
+		fragment F on User { id }
+		query A { user { ...F } }
+		mutation B($text: String = """block "quoted" text""") { add(text: $text) }
+	`
+	tokens := tokenizeQuery(doc)
+	assert.Equal(t, "query", findOperationType(tokens, "A"))
+	assert.Equal(t, "mutation", findOperationType(tokens, "B"))
+	assert.Equal(t, "query", findOperationType(tokens, ""))
+	assert.Equal(t, "", findOperationType(tokens, "C"))
+	assert.Equal(t, "query", findOperationType(tokenizeQuery(`{ user { id } }`), ""))
+	assert.Equal(t, "subscription", findOperationType(tokenizeQuery(`subscription { events }`), ""))
+}
@@ -15,11 +15,16 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hypermodeinc/modus/lib/metadata"
+	"github.com/hypermodeinc/modus/runtime/collections"
 	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/graphql/datasource"
 	"github.com/hypermodeinc/modus/runtime/graphql/engine"
 	"github.com/hypermodeinc/modus/runtime/logger"
 	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/middleware"
 	"github.com/hypermodeinc/modus/runtime/pluginmanager"
 	"github.com/hypermodeinc/modus/runtime/timezones"
 	"github.com/hypermodeinc/modus/runtime/utils"
@@ -40,6 +45,14 @@ func Initialize() {
 	// The GraphQL engine's Activate function should be called when a plugin is loaded.
 	pluginmanager.RegisterPluginLoadedCallback(engine.Activate)
 
+	// In development, report how each rebuild of a plugin changes the schema.
+	if config.IsDevEnvironment() {
+		pluginmanager.RegisterPluginLoadedCallback(func(ctx context.Context, md *metadata.Metadata) error {
+			reportSchemaChanges(ctx, md)
+			return nil
+		})
+	}
+
 	// It should also be called when the manifest changes, since the manifest can affect function filtering.
 	manifestdata.RegisterManifestLoadedCallback(func(ctx context.Context) error {
 		plugins := pluginmanager.GetRegisteredPlugins()
@@ -69,6 +82,7 @@ func handleGraphQLRequest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
+	start := time.Now()
 
 	// Read the incoming GraphQL request
 	var gqlRequest gql.Request
@@ -84,6 +98,12 @@ func handleGraphQLRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Record the operation in the access log, by its fingerprint rather than its query
+	if rec := middleware.GetAccessLogRecord(ctx); rec != nil {
+		rec.Operation = gqlRequest.OperationName
+		rec.OperationType, rec.Fingerprint = operationFingerprint(gqlRequest.OperationName, gqlRequest.Query)
+	}
+
 	// Get the active GraphQL engine, if there is one.
 	engine := engine.GetEngine()
 	if engine == nil {
@@ -114,6 +134,35 @@ func handleGraphQLRequest(w http.ResponseWriter, r *http.Request) {
 	}
 	ctx = context.WithValue(ctx, utils.TimeZoneContextKey, timeZone)
 
+	// Set the priority class, which decides the order in which waiting calls are given module instances
+	ctx = context.WithValue(ctx, utils.PriorityContextKey, middleware.RequestPriority(r))
+
+	// Set the cursor of a truncated list response, to continue reading the list where the previous response ended
+	if cursor := r.Header.Get(wasmhost.ResponseCursorHeader); cursor != "" {
+		ctx = context.WithValue(ctx, utils.ResponseCursorContextKey, cursor)
+	}
+
+	// Set caller metadata in the context, so functions can include it in their own outbound calls
+	callerMetadata := map[string]string{
+		"remote_addr": r.RemoteAddr,
+	}
+	if requestId := r.Header.Get("X-Request-Id"); requestId != "" {
+		callerMetadata["request_id"] = requestId
+	}
+	if userAgent := r.UserAgent(); userAgent != "" {
+		callerMetadata["user_agent"] = userAgent
+	}
+	ctx = context.WithValue(ctx, utils.CallerMetadataContextKey, callerMetadata)
+
+	// Collection reads made while handling this request observe the request's earlier collection writes
+	ctx = collections.ContextWithReadYourWrites(ctx)
+
+	// Time the fields that are resolved, for Apollo tracing tools
+	var apolloTracing *datasource.ApolloTracing
+	if config.ApolloTracing {
+		ctx, apolloTracing = datasource.ContextWithApolloTracing(ctx, start)
+	}
+
 	// Set tracing options
 	var options = []eng.ExecutionOptions{}
 	if utils.TraceModeEnabled() {
@@ -124,6 +173,9 @@ func handleGraphQLRequest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Execute the GraphQL operation
+	if apolloTracing != nil {
+		apolloTracing.StartExecution()
+	}
 	resultWriter := gql.NewEngineResultWriter()
 	if err := engine.Execute(ctx, &gqlRequest, &resultWriter, options...); err != nil {
 
@@ -157,7 +209,11 @@ func handleGraphQLRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if response, err := addOutputToResponse(resultWriter.Bytes(), output); err != nil {
+	response, err := addOutputToResponse(resultWriter.Bytes(), output)
+	if err == nil && apolloTracing != nil {
+		response, err = sjson.SetBytes(response, "extensions.tracing", apolloTracing.Extension(time.Now()))
+	}
+	if err != nil {
 		msg := "Failed to add function output to response."
 		logger.Err(ctx, err).Msg(msg)
 		http.Error(w, fmt.Sprintf("%s\n%v", msg, err), http.StatusInternalServerError)
@@ -177,7 +233,16 @@ func handleGraphQLRequest(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		// Apply any serialization options.  Field order is preserved, so it always matches the operation.
+		if formatted, err := utils.FormatJson(response, getJsonFormatOptions(r)); err != nil {
+			logger.Warn(ctx).Err(err).Msg("Failed to apply JSON serialization options to the response.")
+		} else {
+			response = formatted
+		}
+
 		_, _ = w.Write(response)
+
+		logRequestSample(ctx, &gqlRequest, response, time.Since(start))
 	}
 }
 
@@ -200,6 +265,30 @@ func addOutputToResponse(response []byte, output map[string]wasmhost.ExecutionIn
 			invocations = b
 		}
 
+		if m := item.Memory(); m != nil {
+			if b, err := sjson.SetBytesOptions(invocations, key+".memory", m, jsonOptions); err != nil {
+				return nil, err
+			} else {
+				invocations = b
+			}
+		}
+
+		if t := item.Trap(); t != nil {
+			if b, err := sjson.SetBytesOptions(invocations, key+".trap", t, jsonOptions); err != nil {
+				return nil, err
+			} else {
+				invocations = b
+			}
+		}
+
+		if t := item.Truncation(); t != nil {
+			if b, err := sjson.SetBytesOptions(invocations, key+".truncated", t, jsonOptions); err != nil {
+				return nil, err
+			} else {
+				invocations = b
+			}
+		}
+
 		logMessages := utils.TransformConsoleOutput(item.Buffers())
 		if len(logMessages) == 0 {
 			continue
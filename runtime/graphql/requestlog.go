@@ -0,0 +1,70 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package graphql
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/tidwall/gjson"
+	gql "github.com/wundergraph/graphql-go-tools/execution/graphql"
+)
+
+// logRequestSample logs a sample of GraphQL requests and a summary of their responses,
+// to aid in debugging production incidents.  The values of sensitive fields are redacted.
+func logRequestSample(ctx context.Context, req *gql.Request, response []byte, duration time.Duration) {
+	if config.RequestLogSamplePercent <= 0 || rand.Float64()*100 >= config.RequestLogSamplePercent {
+		return
+	}
+
+	redactFields := strings.Split(config.RequestLogRedactFields, ",")
+	for i, f := range redactFields {
+		redactFields[i] = strings.TrimSpace(f)
+	}
+
+	variables, err := utils.RedactJson(req.Variables, redactFields)
+	if err != nil {
+		logger.Warn(ctx).Err(err).Msg("Failed to redact GraphQL request variables.  The request will not be logged.")
+		return
+	} else if len(variables) == 0 {
+		variables = []byte("null")
+	}
+
+	data := gjson.GetBytes(response, "data")
+	fields := []string{}
+	data.ForEach(func(key, _ gjson.Result) bool {
+		fields = append(fields, key.String())
+		return true
+	})
+
+	errorMessages := []string{}
+	gjson.GetBytes(response, "errors.#.message").ForEach(func(_, msg gjson.Result) bool {
+		errorMessages = append(errorMessages, msg.String())
+		return true
+	})
+
+	// audit record
+	logger.Info(ctx).
+		Str("log_type", "request").
+		Str("operation_name", req.OperationName).
+		Str("query", req.Query).
+		RawJSON("variables", variables).
+		Strs("response_fields", fields).
+		Strs("response_errors", errorMessages).
+		Int("response_bytes", len(response)).
+		Dur("duration_ms", duration).
+		Msg("Sampled GraphQL request.")
+}
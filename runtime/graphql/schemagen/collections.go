@@ -0,0 +1,269 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package schemagen
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// CollectionField identifies the collection operation that a built-in GraphQL field performs.
+type CollectionField struct {
+	Collection string `json:"collection"`
+	Operation  string `json:"operation"`
+}
+
+var allCollectionOperations = []manifest.CollectionOperation{
+	manifest.CollectionOperationSearch,
+	manifest.CollectionOperationClassify,
+	manifest.CollectionOperationVector,
+	manifest.CollectionOperationUpsert,
+	manifest.CollectionOperationDelete,
+}
+
+var collectionResultTypes = []*TypeDefinition{
+	{
+		Name: "CollectionSearchResponse",
+		Fields: []*FieldDefinition{
+			{Name: "collection", Type: "String!"},
+			{Name: "searchMethod", Type: "String!"},
+			{Name: "objects", Type: "[CollectionSearchHit!]!"},
+		},
+	},
+	{
+		Name: "CollectionSearchHit",
+		Fields: []*FieldDefinition{
+			{Name: "namespace", Type: "String!"},
+			{Name: "key", Type: "String!"},
+			{Name: "text", Type: "String"},
+			{Name: "labels", Type: "[String!]!"},
+			{Name: "distance", Type: "Float!"},
+			{Name: "score", Type: "Float!"},
+		},
+	},
+	{
+		Name: "CollectionClassifyResponse",
+		Fields: []*FieldDefinition{
+			{Name: "collection", Type: "String!"},
+			{Name: "searchMethod", Type: "String!"},
+			{Name: "labels", Type: "[CollectionLabelConfidence!]!"},
+			{Name: "cluster", Type: "[CollectionClassifyHit!]!"},
+		},
+	},
+	{
+		Name: "CollectionLabelConfidence",
+		Fields: []*FieldDefinition{
+			{Name: "label", Type: "String!"},
+			{Name: "confidence", Type: "Float!"},
+		},
+	},
+	{
+		Name: "CollectionClassifyHit",
+		Fields: []*FieldDefinition{
+			{Name: "key", Type: "String!"},
+			{Name: "labels", Type: "[String!]!"},
+			{Name: "distance", Type: "Float!"},
+			{Name: "score", Type: "Float!"},
+		},
+	},
+	{
+		Name: "CollectionVectorResponse",
+		Fields: []*FieldDefinition{
+			{Name: "collection", Type: "String!"},
+			{Name: "searchMethod", Type: "String!"},
+			{Name: "namespace", Type: "String!"},
+			{Name: "key", Type: "String!"},
+			{Name: "vector", Type: "[Float!]!"},
+		},
+	},
+	{
+		Name: "CollectionMutationResponse",
+		Fields: []*FieldDefinition{
+			{Name: "collection", Type: "String!"},
+			{Name: "operation", Type: "String!"},
+			{Name: "keys", Type: "[String!]!"},
+			{Name: "duplicates", Type: "[CollectionDuplicate!]!"},
+		},
+	},
+	{
+		Name: "CollectionDuplicate",
+		Fields: []*FieldDefinition{
+			{Name: "key", Type: "String!"},
+			{Name: "duplicateOf", Type: "String!"},
+			{Name: "distance", Type: "Float!"},
+			{Name: "policy", Type: "String!"},
+		},
+	},
+}
+
+// addCollectionFields adds built-in fields for the collections that enable them in the manifest.
+// Only collections with an access policy are exposed, since the fields can be called directly by clients.
+func addCollectionFields(ctx context.Context, root *RootObjects, resultTypeDefs map[string]*TypeDefinition) []*TransformError {
+	collections := manifestdata.GetManifest().Collections
+	names := utils.MapKeys(collections)
+	sort.Strings(names)
+
+	errors := make([]*TransformError, 0)
+	added := false
+	for _, name := range names {
+		collection := collections[name]
+		if collection.GraphQL == nil {
+			continue
+		}
+
+		if collection.Access == nil {
+			logger.Warn(ctx).
+				Str("collection", name).
+				Bool("user_visible", true).
+				Msg("GraphQL fields are not generated for collections without an access policy.")
+			continue
+		}
+
+		existing := make(map[string]bool)
+		for _, f := range root.AllFields() {
+			existing[f.Name] = true
+		}
+
+		for _, field := range getCollectionFields(name, collection.GraphQL) {
+			if existing[field.Name] {
+				errors = append(errors, &TransformError{name, fmt.Errorf("field %s for collection %s conflicts with an existing field", field.Name, name)})
+				continue
+			}
+			switch manifest.CollectionOperation(field.Collection.Operation) {
+			case manifest.CollectionOperationUpsert, manifest.CollectionOperationDelete:
+				root.MutationFields = append(root.MutationFields, field)
+			default:
+				root.QueryFields = append(root.QueryFields, field)
+			}
+			added = true
+		}
+	}
+
+	if added {
+		for _, t := range collectionResultTypes {
+			if _, ok := resultTypeDefs[t.Name]; ok {
+				errors = append(errors, &TransformError{t.Name, fmt.Errorf("type %s conflicts with a built-in collection type", t.Name)})
+				continue
+			}
+			resultTypeDefs[t.Name] = t
+		}
+	}
+
+	return errors
+}
+
+func getCollectionFields(collectionName string, info *manifest.CollectionGraphQLInfo) []*FieldDefinition {
+	operations := info.Operations
+	if len(operations) == 0 {
+		operations = allCollectionOperations
+	}
+
+	prefix := getCollectionFieldPrefix(collectionName)
+	fields := make([]*FieldDefinition, 0, len(operations))
+	for _, op := range allCollectionOperations {
+		if !slices.Contains(operations, op) {
+			continue
+		}
+
+		field := &FieldDefinition{
+			Name:       prefix + strings.ToUpper(string(op[:1])) + string(op[1:]),
+			Collection: &CollectionField{Collection: collectionName, Operation: string(op)},
+		}
+
+		switch op {
+		case manifest.CollectionOperationSearch:
+			field.DocLines = []string{
+				fmt.Sprintf("Search the %s collection for texts similar to the given text.", collectionName),
+				"A precomputed query vector can be given instead of the text, to skip the embedder.",
+			}
+			field.Type = "CollectionSearchResponse!"
+			field.Arguments = []*ArgumentDefinition{
+				{Name: "text", Type: "String"},
+				{Name: "vector", Type: "[Float!]"},
+				{Name: "searchMethod", Type: "String!"},
+				{Name: "namespaces", Type: "[String!]"},
+				{Name: "limit", Type: "Int!", Default: ptr[any](10)},
+				{Name: "returnText", Type: "Boolean!", Default: ptr[any](false)},
+			}
+		case manifest.CollectionOperationClassify:
+			field.DocLines = []string{fmt.Sprintf("Classify the given text using the labels of the nearest items in the %s collection.", collectionName)}
+			field.Type = "CollectionClassifyResponse!"
+			field.Arguments = []*ArgumentDefinition{
+				{Name: "text", Type: "String!"},
+				{Name: "searchMethod", Type: "String!"},
+				{Name: "namespace", Type: "String"},
+			}
+		case manifest.CollectionOperationVector:
+			field.DocLines = []string{fmt.Sprintf("Get the stored vector of an item in the %s collection, for the given search method.", collectionName)}
+			field.Type = "CollectionVectorResponse!"
+			field.Arguments = []*ArgumentDefinition{
+				{Name: "key", Type: "String!"},
+				{Name: "searchMethod", Type: "String!"},
+				{Name: "namespace", Type: "String"},
+			}
+		case manifest.CollectionOperationUpsert:
+			field.DocLines = []string{fmt.Sprintf("Insert or update texts in the %s collection.", collectionName)}
+			field.Type = "CollectionMutationResponse!"
+			field.Arguments = []*ArgumentDefinition{
+				{Name: "texts", Type: "[String!]!"},
+				{Name: "keys", Type: "[String!]"},
+				{Name: "labels", Type: "[[String!]!]"},
+				{Name: "namespace", Type: "String"},
+			}
+		case manifest.CollectionOperationDelete:
+			field.DocLines = []string{fmt.Sprintf("Delete a text from the %s collection.", collectionName)}
+			field.Type = "CollectionMutationResponse!"
+			field.Arguments = []*ArgumentDefinition{
+				{Name: "key", Type: "String!"},
+				{Name: "namespace", Type: "String"},
+			}
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// getCollectionFieldPrefix converts a collection name to a camel-cased prefix that is valid in a GraphQL field name.
+// For example, "my-products" becomes "myProducts".
+func getCollectionFieldPrefix(collectionName string) string {
+	words := strings.FieldsFunc(collectionName, func(r rune) bool {
+		return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+
+	var sb strings.Builder
+	for i, w := range words {
+		if i == 0 {
+			sb.WriteString(strings.ToLower(w[:1]) + w[1:])
+		} else {
+			sb.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+
+	prefix := sb.String()
+	if prefix == "" || unicode.IsDigit(rune(prefix[0])) {
+		prefix = "_" + prefix
+	}
+	return prefix
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
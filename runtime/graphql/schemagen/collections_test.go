@@ -0,0 +1,115 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package schemagen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetCollectionFieldPrefix(t *testing.T) {
+	cases := map[string]string{
+		"products":     "products",
+		"Products":     "products",
+		"my-products":  "myProducts",
+		"my_products":  "myProducts",
+		"my products!": "myProducts",
+		"2024-reports": "_2024Reports",
+		"---":          "_",
+	}
+	for name, expected := range cases {
+		require.Equal(t, expected, getCollectionFieldPrefix(name), name)
+	}
+}
+
+func Test_AddCollectionFields(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{
+		Collections: map[string]manifest.CollectionInfo{
+			"my-products": {
+				Access: &manifest.CollectionAccessInfo{
+					Roles: map[string][]manifest.CollectionPermission{
+						"admin": {manifest.CollectionPermissionAdmin},
+					},
+				},
+				GraphQL: &manifest.CollectionGraphQLInfo{},
+			},
+			"searchOnly": {
+				Access: &manifest.CollectionAccessInfo{
+					Roles: map[string][]manifest.CollectionPermission{
+						"*": {manifest.CollectionPermissionSearch},
+					},
+				},
+				GraphQL: &manifest.CollectionGraphQLInfo{
+					Operations: []manifest.CollectionOperation{manifest.CollectionOperationSearch},
+				},
+			},
+			"noAccessPolicy": {
+				GraphQL: &manifest.CollectionGraphQLInfo{},
+			},
+			"notExposed": {},
+		},
+	})
+
+	root := &RootObjects{}
+	typeDefs := make(map[string]*TypeDefinition)
+	errors := addCollectionFields(context.Background(), root, typeDefs)
+	require.Empty(t, errors)
+
+	names := func(fields []*FieldDefinition) []string {
+		result := make([]string, len(fields))
+		for i, f := range fields {
+			result[i] = f.Name
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"myProductsSearch", "myProductsClassify", "myProductsVector", "searchOnlySearch"}, names(root.QueryFields))
+	require.Equal(t, []string{"myProductsUpsert", "myProductsDelete"}, names(root.MutationFields))
+	require.Equal(t, CollectionField{Collection: "my-products", Operation: "upsert"}, *root.MutationFields[0].Collection)
+
+	searchArgs := make(map[string]string)
+	for _, arg := range root.QueryFields[0].Arguments {
+		searchArgs[arg.Name] = arg.Type
+	}
+	require.Equal(t, "String", searchArgs["text"])
+	require.Equal(t, "[Float!]", searchArgs["vector"])
+	require.Contains(t, typeDefs, "CollectionSearchResponse")
+	require.Contains(t, typeDefs, "CollectionVectorResponse")
+	require.Contains(t, typeDefs, "CollectionMutationResponse")
+}
+
+func Test_AddCollectionFields_Conflict(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{
+		Collections: map[string]manifest.CollectionInfo{
+			"products": {
+				Access: &manifest.CollectionAccessInfo{
+					Roles: map[string][]manifest.CollectionPermission{
+						"*": {manifest.CollectionPermissionSearch},
+					},
+				},
+				GraphQL: &manifest.CollectionGraphQLInfo{
+					Operations: []manifest.CollectionOperation{manifest.CollectionOperationSearch},
+				},
+			},
+		},
+	})
+
+	root := &RootObjects{
+		QueryFields: []*FieldDefinition{{Name: "productsSearch", Type: "String!", Function: "getProductsSearch"}},
+	}
+	errors := addCollectionFields(context.Background(), root, make(map[string]*TypeDefinition))
+	require.Len(t, errors, 1)
+	require.Len(t, root.QueryFields, 1)
+}
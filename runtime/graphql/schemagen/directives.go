@@ -0,0 +1,500 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package schemagen
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hypermodeinc/modus/lib/metadata"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// Plugin authors attach custom GraphQL directives to functions and types with lines in their documentation
+// of the form "@directive @name(arg: value, ...)".  The lines are removed from the description, the directives
+// are written on the generated field or type, and a declaration is generated for each directive, with argument
+// types inferred from the values given.  Directives are also consumed by runtime middlewares, which run them
+// whenever a function is called, whether for a root field, a nested field or a pipeline stage.
+
+const directiveDocPrefix = "@directive "
+
+// Directive is a custom directive applied to a field or type.
+type Directive struct {
+	Name      string               `json:"name"`
+	Arguments []*DirectiveArgument `json:"arguments,omitempty"`
+}
+
+// DirectiveArgument is an argument of a directive.  The value is a string, int64, float64, bool, or a list of them.
+type DirectiveArgument struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+// Args returns the arguments of the directive as a map.
+func (d *Directive) Args() map[string]any {
+	args := make(map[string]any, len(d.Arguments))
+	for _, a := range d.Arguments {
+		args[a.Name] = a.Value
+	}
+	return args
+}
+
+type directiveDeclaration struct {
+	Name      string
+	Arguments []*ArgumentDefinition
+	Locations []string
+}
+
+// directives that are built into GraphQL, which are applied as given, but not declared
+var builtinDirectives = []string{"deprecated", "include", "oneOf", "skip", "specifiedBy"}
+
+// extractDirectives moves the directive lines from the documentation of all fields and types to their directives,
+// and returns the declarations of the custom directives that are used.
+func extractDirectives(root *RootObjects, inputTypeDefs, resultTypeDefs []*TypeDefinition) ([]*directiveDeclaration, []*TransformError) {
+	decls := make(map[string]*directiveDeclaration)
+	errors := make([]*TransformError, 0)
+
+	apply := func(source any, docLines *[]string, directives *[]*Directive, location string) {
+		lines, dirs, err := parseDirectiveLines(*docLines)
+		if err == nil {
+			err = declareDirectives(decls, dirs, location)
+		}
+		if err != nil {
+			errors = append(errors, &TransformError{source, err})
+			return
+		}
+		*docLines = lines
+		*directives = append(*directives, dirs...)
+	}
+
+	for _, f := range root.AllFields() {
+		apply(f, &f.DocLines, &f.Directives, "FIELD_DEFINITION")
+	}
+	for _, t := range inputTypeDefs {
+		apply(t, &t.DocLines, &t.Directives, "INPUT_OBJECT")
+		for _, f := range t.Fields {
+			apply(f, &f.DocLines, &f.Directives, "INPUT_FIELD_DEFINITION")
+		}
+	}
+	for _, t := range resultTypeDefs {
+		apply(t, &t.DocLines, &t.Directives, "OBJECT")
+		for _, f := range t.Fields {
+			apply(f, &f.DocLines, &f.Directives, "FIELD_DEFINITION")
+		}
+	}
+
+	results := utils.MapValues(decls)
+	slices.SortFunc(results, func(a, b *directiveDeclaration) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return results, errors
+}
+
+// collectFunctionDirectives returns the directives that are enforced whenever each function is called, by function
+// name.  They are the directives in the function's documentation, followed by those of the result types that it
+// returns, so that a directive applied to a type protects it wherever it is returned.
+func collectFunctionDirectives(functions metadata.FunctionMap, root *RootObjects, resultTypeDefs []*TypeDefinition) (map[string][]*Directive, []*TransformError) {
+	types := typesByName(resultTypeDefs)
+	results := make(map[string][]*Directive)
+	errors := make([]*TransformError, 0)
+
+	add := func(f *FieldDefinition) {
+		if !isFunctionField(f) {
+			return
+		}
+		dirs := append(slices.Clone(f.Directives), resultTypeDirectives(f.Type, types)...)
+		if len(dirs) > 0 {
+			results[f.Function] = dirs
+		}
+	}
+	for _, f := range root.AllFields() {
+		add(f)
+	}
+	for _, t := range resultTypeDefs {
+		for _, f := range t.Fields {
+			add(f)
+		}
+	}
+
+	// functions that are only called as pipeline stages don't have fields of their own
+	for name, fn := range functions {
+		if _, found := results[name]; found || fn.Docs == nil {
+			continue
+		}
+		_, dirs, err := parseDirectiveLines(fn.Docs.Lines)
+		if err != nil {
+			errors = append(errors, &TransformError{fn, err})
+		} else if len(dirs) > 0 {
+			results[name] = dirs
+		}
+	}
+
+	return results, errors
+}
+
+// resultTypeDirectives returns the directives of the result type of a field, and of the types and fields reachable
+// from it.  Fields that are resolved by their own functions are skipped, because their directives are enforced when
+// those functions are called.
+func resultTypeDirectives(fieldType string, types map[string]*TypeDefinition) []*Directive {
+	var results []*Directive
+	seen := make(map[string]bool)
+
+	var visit func(typ string)
+	visit = func(typ string) {
+		name := strings.Trim(typ, "[]!")
+		t, ok := types[name]
+		if !ok || seen[name] {
+			return
+		}
+		seen[name] = true
+
+		results = append(results, t.Directives...)
+		for _, f := range t.Fields {
+			if !isFunctionField(f) {
+				results = append(results, f.Directives...)
+				visit(f.Type)
+			}
+		}
+	}
+	visit(fieldType)
+
+	return results
+}
+
+func typesByName(typeDefs []*TypeDefinition) map[string]*TypeDefinition {
+	types := make(map[string]*TypeDefinition, len(typeDefs))
+	for _, t := range typeDefs {
+		types[t.Name] = t
+	}
+	return types
+}
+
+func isFunctionField(f *FieldDefinition) bool {
+	return f.Function != "" && f.Collection == nil && f.Builtin == "" && f.Pipeline == ""
+}
+
+func declareDirectives(decls map[string]*directiveDeclaration, directives []*Directive, location string) error {
+	for i, d := range directives {
+		if slices.ContainsFunc(directives[:i], func(o *Directive) bool { return o.Name == d.Name }) {
+			return fmt.Errorf("directive @%s is applied more than once", d.Name)
+		}
+		if slices.Contains(builtinDirectives, d.Name) {
+			continue
+		}
+
+		decl, ok := decls[d.Name]
+		if !ok {
+			decl = &directiveDeclaration{Name: d.Name}
+			decls[d.Name] = decl
+		}
+		if !slices.Contains(decl.Locations, location) {
+			decl.Locations = append(decl.Locations, location)
+			slices.Sort(decl.Locations)
+		}
+
+		for _, a := range d.Arguments {
+			argType, err := directiveArgumentType(a.Value)
+			if err != nil {
+				return fmt.Errorf("argument %s of directive @%s: %w", a.Name, d.Name, err)
+			}
+
+			i := slices.IndexFunc(decl.Arguments, func(arg *ArgumentDefinition) bool { return arg.Name == a.Name })
+			if i < 0 {
+				decl.Arguments = append(decl.Arguments, &ArgumentDefinition{Name: a.Name, Type: argType})
+				continue
+			}
+
+			existing := decl.Arguments[i]
+			switch {
+			case existing.Type == argType:
+			case existing.Type == "Int" && argType == "Float", existing.Type == "[Int]" && argType == "[Float]":
+				existing.Type = argType
+			case existing.Type == "Float" && argType == "Int", existing.Type == "[Float]" && argType == "[Int]":
+			default:
+				return fmt.Errorf("argument %s of directive @%s is used with both %s and %s values", a.Name, d.Name, existing.Type, argType)
+			}
+		}
+	}
+	return nil
+}
+
+func directiveArgumentType(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return "String", nil
+	case int64:
+		return "Int", nil
+	case float64:
+		return "Float", nil
+	case bool:
+		return "Boolean", nil
+	case []any:
+		if len(v) == 0 {
+			return "", fmt.Errorf("the type of an empty list cannot be inferred")
+		}
+		itemType := ""
+		for _, item := range v {
+			t, err := directiveArgumentType(item)
+			if err != nil {
+				return "", err
+			}
+			switch {
+			case strings.HasPrefix(t, "["):
+				return "", fmt.Errorf("nested lists are not supported")
+			case itemType == "", itemType == t, itemType == "Float" && t == "Int":
+				if itemType == "" {
+					itemType = t
+				}
+			case itemType == "Int" && t == "Float":
+				itemType = t
+			default:
+				return "", fmt.Errorf("list items must all have the same type")
+			}
+		}
+		return "[" + itemType + "]", nil
+	}
+	return "", fmt.Errorf("unsupported value %v", value)
+}
+
+// parseDirectiveLines separates the directive lines from the other documentation lines.
+func parseDirectiveLines(lines []string) ([]string, []*Directive, error) {
+	var docLines []string
+	var directives []*Directive
+	for _, line := range lines {
+		s, ok := strings.CutPrefix(strings.TrimSpace(line), directiveDocPrefix)
+		if !ok {
+			docLines = append(docLines, line)
+			continue
+		}
+
+		d, err := parseDirective(s)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid directive %q: %w", strings.TrimSpace(s), err)
+		}
+		directives = append(directives, d)
+	}
+	return docLines, directives, nil
+}
+
+// parseDirective parses a directive such as @auth(role: "admin").
+func parseDirective(s string) (*Directive, error) {
+	p := &directiveParser{s: strings.TrimSpace(s)}
+	if !p.consume('@') {
+		return nil, fmt.Errorf("expected @ before the directive name")
+	}
+	name := p.name()
+	if name == "" {
+		return nil, fmt.Errorf("expected a directive name")
+	}
+	d := &Directive{Name: name}
+
+	p.skipSpace()
+	if p.consume('(') {
+		for {
+			p.skipSpace()
+			if p.consume(')') {
+				break
+			}
+			argName := p.name()
+			if argName == "" {
+				return nil, fmt.Errorf("expected an argument name at position %d", p.pos)
+			}
+			if slices.ContainsFunc(d.Arguments, func(a *DirectiveArgument) bool { return a.Name == argName }) {
+				return nil, fmt.Errorf("argument %s is given more than once", argName)
+			}
+			p.skipSpace()
+			if !p.consume(':') {
+				return nil, fmt.Errorf("expected : after argument %s", argName)
+			}
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			d.Arguments = append(d.Arguments, &DirectiveArgument{Name: argName, Value: value})
+		}
+		if len(d.Arguments) == 0 {
+			return nil, fmt.Errorf("expected at least one argument")
+		}
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.s[p.pos:], p.pos)
+	}
+	return d, nil
+}
+
+type directiveParser struct {
+	s   string
+	pos int
+}
+
+// skipSpace skips whitespace, and the commas that GraphQL treats as whitespace.
+func (p *directiveParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t,", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *directiveParser) consume(c byte) bool {
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *directiveParser) name() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || p.pos > start && c >= '0' && c <= '9' {
+			p.pos++
+		} else {
+			break
+		}
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *directiveParser) value() (any, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return nil, fmt.Errorf("expected a value")
+	}
+
+	switch c := p.s[p.pos]; {
+	case c == '"':
+		end := p.pos + 1
+		for end < len(p.s) && p.s[end] != '"' {
+			if p.s[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.s) {
+			return nil, fmt.Errorf("unterminated string at position %d", p.pos)
+		}
+		s, err := strconv.Unquote(p.s[p.pos : end+1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid string at position %d", p.pos)
+		}
+		p.pos = end + 1
+		return s, nil
+
+	case c == '[':
+		p.pos++
+		items := []any{}
+		for {
+			p.skipSpace()
+			if p.consume(']') {
+				return items, nil
+			}
+			item, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+
+	case c == '-' || c >= '0' && c <= '9':
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.s) && strings.IndexByte("0123456789.eE+-", p.s[p.pos]) >= 0 {
+			p.pos++
+		}
+		num := p.s[start:p.pos]
+		if i, err := strconv.ParseInt(num, 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(num, 64); err == nil {
+			return f, nil
+		}
+		return nil, fmt.Errorf("invalid number %s", num)
+
+	default:
+		start := p.pos
+		switch p.name() {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("unsupported value at position %d, must be a string, number, boolean or list", start)
+	}
+}
+
+func writeDirectiveDeclarations(buf *bytes.Buffer, decls []*directiveDeclaration) {
+	if len(decls) == 0 {
+		return
+	}
+
+	buf.WriteByte('\n')
+	for _, d := range decls {
+		buf.WriteString("directive @")
+		buf.WriteString(d.Name)
+		if len(d.Arguments) > 0 {
+			buf.WriteByte('(')
+			for i, a := range d.Arguments {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				buf.WriteString(a.Name)
+				buf.WriteString(": ")
+				buf.WriteString(a.Type)
+			}
+			buf.WriteByte(')')
+		}
+		buf.WriteString(" on ")
+		buf.WriteString(strings.Join(d.Locations, " | "))
+		buf.WriteByte('\n')
+	}
+}
+
+func writeDirectives(buf *bytes.Buffer, directives []*Directive) {
+	for _, d := range directives {
+		buf.WriteString(" @")
+		buf.WriteString(d.Name)
+		if len(d.Arguments) > 0 {
+			buf.WriteByte('(')
+			for i, a := range d.Arguments {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				buf.WriteString(a.Name)
+				buf.WriteString(": ")
+				writeDirectiveValue(buf, a.Value)
+			}
+			buf.WriteByte(')')
+		}
+	}
+}
+
+func writeDirectiveValue(buf *bytes.Buffer, value any) {
+	if items, ok := value.([]any); ok {
+		buf.WriteByte('[')
+		for i, item := range items {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			writeDirectiveValue(buf, item)
+		}
+		buf.WriteByte(']')
+		return
+	}
+
+	// JSON strings, numbers and booleans are also valid GraphQL values
+	if val, err := utils.JsonSerialize(value); err == nil {
+		buf.Write(val)
+	}
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package schemagen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/lib/metadata"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetGraphQLSchema_Directives(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{})
+
+	md := metadata.NewPluginMetadata()
+	md.SDK = "modus-sdk-go"
+
+	md.FnExports.AddFunction("getPerson").
+		WithParameter("name", "string").
+		WithResult("testdata.Person").
+		WithDocs(metadata.Docs{Lines: []string{
+			"Gets a person by name.",
+			`@directive @auth(role: "admin")`,
+			"@directive @cache(ttl: 60)",
+		}})
+
+	md.FnExports.AddFunction("getPeople").
+		WithResult("[]testdata.Person").
+		WithDocs(metadata.Docs{Lines: []string{
+			`@directive @auth(role: "reader", scope: "people:read")`,
+			"@directive @cache(ttl: 0.5)",
+			`@directive @deprecated(reason: "Use search instead.")`,
+			`@directive @tags(names: ["people", "list"])`,
+		}})
+
+	md.Types.AddType("[]testdata.Person")
+	md.Types.AddType("testdata.Person").
+		WithField("name", "string").
+		WithField("age", "int32", &metadata.Docs{Lines: []string{"@directive @sensitive"}}).
+		WithDocs(metadata.Docs{Lines: []string{"A person.", "@directive @key(fields: \"name\")"}})
+
+	result, err := GetGraphQLSchema(context.Background(), md)
+	require.Nil(t, err)
+
+	expectedSchema := `# Modus GraphQL Schema (auto-generated)
+
+type Query {
+  people: [Person!] @auth(role: "reader", scope: "people:read") @cache(ttl: 0.5) @deprecated(reason: "Use search instead.") @tags(names: ["people", "list"])
+  """
+  Gets a person by name.
+  """
+  person(name: String!): Person! @auth(role: "admin") @cache(ttl: 60)
+}
+
+directive @auth(role: String, scope: String) on FIELD_DEFINITION
+directive @cache(ttl: Float) on FIELD_DEFINITION
+directive @key(fields: String) on OBJECT
+directive @sensitive on FIELD_DEFINITION
+directive @tags(names: [String]) on FIELD_DEFINITION
+
+"""
+A person.
+"""
+type Person @key(fields: "name") {
+  name: String!
+  age: Int! @sensitive
+}
+`[1:]
+
+	require.Equal(t, expectedSchema, result.Schema)
+	require.Empty(t, result.FieldDirectives)
+
+	// the directives of the result type and its fields are enforced with those of each function that returns it
+	require.Equal(t, []string{"auth", "cache", "key", "sensitive"}, directiveNames(result.FunctionDirectives["getPerson"]))
+	require.Equal(t, []string{"auth", "cache", "deprecated", "tags", "key", "sensitive"}, directiveNames(result.FunctionDirectives["getPeople"]))
+	require.Equal(t, map[string]any{"role": "admin"}, result.FunctionDirectives["getPerson"][0].Args())
+	require.Equal(t, map[string]any{"ttl": int64(60)}, result.FunctionDirectives["getPerson"][1].Args())
+}
+
+func Test_GetGraphQLSchema_DirectiveErrors(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{})
+
+	md := metadata.NewPluginMetadata()
+	md.SDK = "modus-sdk-go"
+
+	md.FnExports.AddFunction("getName").
+		WithResult("string").
+		WithDocs(metadata.Docs{Lines: []string{`@directive @auth(role: "admin"`}})
+
+	md.FnExports.AddFunction("getAge").
+		WithResult("int32").
+		WithDocs(metadata.Docs{Lines: []string{"@directive @cache(ttl: 60) @cache(ttl: 30)"}})
+
+	md.FnExports.AddFunction("getEmail").
+		WithResult("string").
+		WithDocs(metadata.Docs{Lines: []string{`@directive @limit(max: "ten")`}})
+
+	md.FnExports.AddFunction("getPhone").
+		WithResult("string").
+		WithDocs(metadata.Docs{Lines: []string{"@directive @limit(max: 10)"}})
+
+	_, err := GetGraphQLSchema(context.Background(), md)
+	require.ErrorContains(t, err, `invalid directive "@auth(role: \"admin\""`)
+	require.ErrorContains(t, err, `invalid directive "@cache(ttl: 60) @cache(ttl: 30)": unexpected "@cache(ttl: 30)"`)
+	require.ErrorContains(t, err, "argument max of directive @limit is used with both")
+}
+
+func Test_ParseDirective(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected *Directive
+		err      string
+	}{
+		{
+			input:    "@public",
+			expected: &Directive{Name: "public"},
+		},
+		{
+			input: `@rateLimit(max: 10, window: "1m", burst: -2.5e1, enabled: true, tags: ["a", "b\"c"])`,
+			expected: &Directive{Name: "rateLimit", Arguments: []*DirectiveArgument{
+				{Name: "max", Value: int64(10)},
+				{Name: "window", Value: "1m"},
+				{Name: "burst", Value: -25.0},
+				{Name: "enabled", Value: true},
+				{Name: "tags", Value: []any{"a", `b"c`}},
+			}},
+		},
+		{input: "auth", err: "expected @ before the directive name"},
+		{input: "@auth()", err: "expected at least one argument"},
+		{input: "@auth(role)", err: "expected : after argument role"},
+		{input: "@auth(role: ADMIN)", err: "unsupported value at position 12"},
+		{input: "@auth(role: 1, role: 2)", err: "argument role is given more than once"},
+		{input: `@auth(role: "admin)`, err: "unterminated string at position 12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			d, err := parseDirective(tt.input)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, d)
+		})
+	}
+}
+
+func directiveNames(directives []*Directive) []string {
+	names := make([]string, len(directives))
+	for i, d := range directives {
+		names[i] = d.Name
+	}
+	return names
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package schemagen
+
+import (
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+)
+
+// BuiltinRecordFeedback identifies the built-in field that records user feedback on an execution.
+const BuiltinRecordFeedback = "recordFeedback"
+
+// addFeedbackFields adds the built-in recordFeedback mutation, when it is enabled in the manifest.
+func addFeedbackFields(root *RootObjects) []*TransformError {
+	info := manifestdata.GetManifest().Feedback
+	if info == nil || !info.GraphQL {
+		return nil
+	}
+
+	for _, f := range root.AllFields() {
+		if f.Name == BuiltinRecordFeedback {
+			return []*TransformError{{f.Name, fmt.Errorf("field %s conflicts with the built-in feedback field", f.Name)}}
+		}
+	}
+
+	lo, hi := info.GetRatingRange()
+	root.MutationFields = append(root.MutationFields, &FieldDefinition{
+		Name:    BuiltinRecordFeedback,
+		Type:    "Boolean!",
+		Builtin: BuiltinRecordFeedback,
+		DocLines: []string{
+			fmt.Sprintf("Record user feedback on a previous execution, with a rating between %d and %d.", lo, hi),
+		},
+		Arguments: []*ArgumentDefinition{
+			{Name: "executionId", Type: "String!"},
+			{Name: "rating", Type: "Int!"},
+			{Name: "comment", Type: "String"},
+		},
+	})
+
+	return nil
+}
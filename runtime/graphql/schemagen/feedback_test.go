@@ -0,0 +1,45 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package schemagen
+
+import (
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AddFeedbackFields(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{})
+	root := &RootObjects{}
+	require.Empty(t, addFeedbackFields(root))
+	require.Empty(t, root.MutationFields)
+
+	manifestdata.SetManifest(&manifest.Manifest{Feedback: &manifest.FeedbackInfo{GraphQL: true}})
+	require.Empty(t, addFeedbackFields(root))
+	require.Len(t, root.MutationFields, 1)
+
+	field := root.MutationFields[0]
+	require.Equal(t, "recordFeedback", field.Name)
+	require.Equal(t, BuiltinRecordFeedback, field.Builtin)
+	require.Equal(t, "Boolean!", field.Type)
+	require.Len(t, field.Arguments, 3)
+}
+
+func Test_AddFeedbackFields_Conflict(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{Feedback: &manifest.FeedbackInfo{GraphQL: true}})
+	root := &RootObjects{
+		MutationFields: []*FieldDefinition{{Name: "recordFeedback", Type: "Boolean!", Function: "recordFeedback"}},
+	}
+	require.Len(t, addFeedbackFields(root), 1)
+	require.Len(t, root.MutationFields, 1)
+}
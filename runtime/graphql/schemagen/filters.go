@@ -22,7 +22,14 @@ func getEmbedderFields() map[string]bool {
 	embedders := make(map[string]bool)
 	for _, collection := range manifestdata.GetManifest().Collections {
 		for _, searchMethod := range collection.SearchMethods {
-			embedders[getFieldName(searchMethod.Embedder)] = true
+			for _, embedder := range []string{searchMethod.Embedder, searchMethod.ImageEmbedder} {
+				if embedder != "" {
+					embedders[getFieldName(embedder)] = true
+				}
+			}
+			for _, embedder := range searchMethod.NamespaceEmbedders {
+				embedders[getFieldName(embedder)] = true
+			}
 		}
 	}
 	return embedders
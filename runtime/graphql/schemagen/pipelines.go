@@ -0,0 +1,178 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package schemagen
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/lib/metadata"
+	"github.com/hypermodeinc/modus/runtime/expressions"
+	"github.com/hypermodeinc/modus/runtime/langsupport"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// addPipelineFields adds a root field for each pipeline in the manifest.  The field takes the parameters of the
+// pipeline's first function, and returns the result type of its last function.  If any function in the pipeline
+// is a mutation, the field is a mutation.
+func addPipelineFields(functions metadata.FunctionMap, root *RootObjects, inputTypeDefs, resultTypeDefs map[string]*TypeDefinition, lti langsupport.LanguageTypeInfo) []*TransformError {
+	pipelines := manifestdata.GetManifest().Pipelines
+	errors := make([]*TransformError, 0)
+
+	names := utils.MapKeys(pipelines)
+	sort.Strings(names)
+	for _, name := range names {
+		pipeline := pipelines[name]
+		pipeline.Name = name
+		if len(pipeline.Stages) == 0 {
+			errors = append(errors, &TransformError{name, fmt.Errorf("pipeline %s has no stages", name)})
+			continue
+		}
+
+		if slices.ContainsFunc(root.AllFields(), func(f *FieldDefinition) bool { return f.Name == name }) {
+			errors = append(errors, &TransformError{name, fmt.Errorf("pipeline %s conflicts with an existing field of the same name", name)})
+			continue
+		}
+
+		first, last, mutation, errs := checkPipelineStages(functions, pipeline, resultTypeDefs, lti)
+		if len(errs) > 0 {
+			errors = append(errors, errs...)
+			continue
+		}
+
+		args, err := convertParameters(first.Parameters, lti, inputTypeDefs)
+		if err != nil {
+			errors = append(errors, &TransformError{first, err})
+			continue
+		}
+		returnType, err := convertResults(last.Results, lti, resultTypeDefs)
+		if err != nil {
+			errors = append(errors, &TransformError{last, err})
+			continue
+		}
+
+		field := &FieldDefinition{
+			Name:      name,
+			Arguments: args,
+			Type:      returnType,
+			Pipeline:  name,
+		}
+		if last.Docs != nil {
+			field.DocLines = last.Docs.Lines
+		}
+
+		if mutation {
+			root.MutationFields = append(root.MutationFields, field)
+		} else {
+			root.QueryFields = append(root.QueryFields, field)
+		}
+	}
+
+	return errors
+}
+
+// checkPipelineStages validates the stages of a pipeline, and returns the functions that determine the field's
+// arguments and result type.  The first stage must call a function unconditionally, since its parameters are the
+// field's arguments.  The last stage must always produce a result of one type, so it must call a function
+// unconditionally, or have branches that return the same type, including one without a condition.
+func checkPipelineStages(functions metadata.FunctionMap, pipeline manifest.PipelineInfo, resultTypeDefs map[string]*TypeDefinition, lti langsupport.LanguageTypeInfo) (first, last *metadata.Function, mutation bool, errors []*TransformError) {
+	name := pipeline.Name
+	fail := func(format string, a ...any) {
+		errors = append(errors, &TransformError{name, fmt.Errorf(format, a...)})
+	}
+
+	lookup := func(stage *manifest.PipelineStageInfo, n int) *metadata.Function {
+		if stage.When != nil && stage.When.Expression != "" {
+			if _, err := expressions.Compile(stage.When.Expression); err != nil {
+				fail("invalid condition for stage %d of pipeline %s: %v", n, name, err)
+			}
+		}
+		fn, ok := functions[stage.Function]
+		if !ok {
+			fail("function %s for stage %d of pipeline %s was not found", stage.Function, n, name)
+			return nil
+		}
+		mutation = mutation || isMutation(fn.Name)
+		return fn
+	}
+
+	for i := range pipeline.Stages {
+		stage := &pipeline.Stages[i]
+		n := i + 1
+		isFirst, isLast := i == 0, i == len(pipeline.Stages)-1
+
+		switch {
+		case len(stage.Parallel) > 0:
+			if isFirst || isLast {
+				fail("stage %d of pipeline %s cannot be parallel, because it is the first or last stage", n, name)
+			}
+			names := make(map[string]bool, len(stage.Parallel))
+			for j := range stage.Parallel {
+				p := &stage.Parallel[j]
+				if p.Function == "" {
+					fail("parallel stages in stage %d of pipeline %s must call a function", n, name)
+					continue
+				}
+				if names[p.ResultName()] {
+					fail("parallel stages in stage %d of pipeline %s have the same name %s", n, name, p.ResultName())
+				}
+				names[p.ResultName()] = true
+				lookup(p, n)
+			}
+
+		case len(stage.Branches) > 0:
+			if isFirst {
+				fail("the first stage of pipeline %s cannot have branches", name)
+			}
+			var resultType string
+			hasDefault := false
+			for j := range stage.Branches {
+				b := &stage.Branches[j]
+				if b.Function == "" {
+					fail("branches in stage %d of pipeline %s must call a function", n, name)
+					continue
+				}
+				hasDefault = hasDefault || b.When == nil
+				fn := lookup(b, n)
+				if fn == nil || !isLast {
+					continue
+				}
+				if t, err := convertResults(fn.Results, lti, resultTypeDefs); err != nil {
+					errors = append(errors, &TransformError{fn, err})
+				} else if resultType == "" {
+					resultType = t
+					last = fn
+				} else if t != resultType {
+					fail("branches in the last stage of pipeline %s must return the same type", name)
+				}
+			}
+			if isLast && !hasDefault {
+				fail("the last stage of pipeline %s must have a branch without a condition", name)
+			}
+
+		default:
+			if stage.When != nil && (isFirst || isLast) {
+				fail("stage %d of pipeline %s cannot have a condition, because it is the first or last stage", n, name)
+			}
+			fn := lookup(stage, n)
+			if isFirst {
+				first = fn
+			}
+			if isLast {
+				last = fn
+			}
+		}
+	}
+
+	return first, last, mutation, errors
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package schemagen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/lib/metadata"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetGraphQLSchema_Pipelines(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{
+		Pipelines: map[string]manifest.PipelineInfo{
+			"personSummary": {
+				Name: "personSummary",
+				Stages: []manifest.PipelineStageInfo{
+					{Function: "getPerson"},
+					{Function: "summarize", Inputs: map[string]string{"text": "name"}},
+				},
+			},
+			"describePerson": {
+				Name: "describePerson",
+				Stages: []manifest.PipelineStageInfo{
+					{Function: "getPerson"},
+					{Parallel: []manifest.PipelineStageInfo{
+						{Function: "summarize", Name: "summary", Inputs: map[string]string{"text": "name"}},
+						{Function: "getPerson", Name: "person", Inputs: map[string]string{"name": "name"}},
+					}},
+					{Branches: []manifest.PipelineStageInfo{
+						{Function: "summarize", When: &manifest.PipelineConditionInfo{Path: "person.age", Equals: 0}, Inputs: map[string]string{"text": "summary"}},
+						{Function: "describe"},
+					}},
+				},
+			},
+			"savePersonSummary": {
+				Name: "savePersonSummary",
+				Stages: []manifest.PipelineStageInfo{
+					{Function: "getPerson"},
+					{Function: "addPerson"},
+				},
+			},
+		},
+	})
+
+	md := metadata.NewPluginMetadata()
+	md.SDK = "modus-sdk-go"
+
+	md.FnExports.AddFunction("getPerson").
+		WithParameter("name", "string").
+		WithResult("testdata.Person")
+
+	md.FnExports.AddFunction("summarize").
+		WithParameter("text", "string").
+		WithResult("string")
+
+	md.FnExports.AddFunction("describe").
+		WithParameter("summary", "string").
+		WithParameter("person", "testdata.Person").
+		WithResult("string")
+
+	md.FnExports.AddFunction("addPerson").
+		WithParameter("person", "testdata.Person").
+		WithResult("bool")
+
+	md.Types.AddType("testdata.Person").
+		WithField("name", "string").
+		WithField("age", "int32")
+
+	result, err := GetGraphQLSchema(context.Background(), md)
+	require.Nil(t, err)
+
+	require.Contains(t, result.Schema, "personSummary(name: String!): String!")
+	require.Contains(t, result.Schema, "describePerson(name: String!): String!")
+	require.Contains(t, result.Schema, "type Mutation {")
+	require.Contains(t, result.Schema, "savePersonSummary(name: String!): Boolean!")
+	require.Equal(t, map[string]string{
+		"describePerson":    "describePerson",
+		"personSummary":     "personSummary",
+		"savePersonSummary": "savePersonSummary",
+	}, result.FieldsToPipelines)
+	require.NotContains(t, result.FieldsToFunctions, "personSummary")
+}
+
+func Test_GetGraphQLSchema_PipelineErrors(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{
+		Pipelines: map[string]manifest.PipelineInfo{
+			"person": {
+				Name:   "person",
+				Stages: []manifest.PipelineStageInfo{{Function: "getPerson"}},
+			},
+			"broken": {
+				Name:   "broken",
+				Stages: []manifest.PipelineStageInfo{{Function: "getPerson"}, {Function: "missingFunction"}},
+			},
+			"endsInParallel": {
+				Name: "endsInParallel",
+				Stages: []manifest.PipelineStageInfo{
+					{Function: "getPerson"},
+					{Parallel: []manifest.PipelineStageInfo{{Function: "getPerson"}, {Function: "getPerson"}}},
+				},
+			},
+			"noDefaultBranch": {
+				Name: "noDefaultBranch",
+				Stages: []manifest.PipelineStageInfo{
+					{Function: "getPerson"},
+					{Branches: []manifest.PipelineStageInfo{
+						{Function: "getPerson", When: &manifest.PipelineConditionInfo{Path: "name"}},
+					}},
+				},
+			},
+			"badExpression": {
+				Name: "badExpression",
+				Stages: []manifest.PipelineStageInfo{
+					{Function: "getPerson"},
+					{Branches: []manifest.PipelineStageInfo{
+						{Function: "getPerson", When: &manifest.PipelineConditionInfo{Expression: "result.name =="}},
+						{Function: "getPerson"},
+					}},
+				},
+			},
+		},
+	})
+
+	md := metadata.NewPluginMetadata()
+	md.SDK = "modus-sdk-go"
+
+	md.FnExports.AddFunction("getPerson").
+		WithParameter("name", "string").
+		WithResult("string")
+
+	_, err := GetGraphQLSchema(context.Background(), md)
+	require.ErrorContains(t, err, "pipeline person conflicts with an existing field of the same name")
+	require.ErrorContains(t, err, "function missingFunction for stage 2 of pipeline broken was not found")
+	require.ErrorContains(t, err, "stage 2 of pipeline endsInParallel cannot be parallel")
+	require.ErrorContains(t, err, "parallel stages in stage 2 of pipeline endsInParallel have the same name getPerson")
+	require.ErrorContains(t, err, "the last stage of pipeline noDefaultBranch must have a branch without a condition")
+	require.ErrorContains(t, err, "invalid condition for stage 2 of pipeline badExpression")
+}
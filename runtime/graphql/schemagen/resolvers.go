@@ -0,0 +1,129 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package schemagen
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/lib/metadata"
+	"github.com/hypermodeinc/modus/runtime/langsupport"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// addResolverFields adds the fields that the manifest maps to resolver functions onto their parent types.
+// A resolver function takes the parent object as its only parameter, unless it declares itself a batch function,
+// in which case it takes a list of parent objects and returns a list with one result for each parent.
+// Either way, resolver functions are removed from the root fields.
+// It returns a map of resolver keys, in the form "Type.field", to function names.
+func addResolverFields(functions metadata.FunctionMap, root *RootObjects, resultTypeDefs map[string]*TypeDefinition, lti langsupport.LanguageTypeInfo) (map[string]string, []*TransformError) {
+	resolvers := manifestdata.GetManifest().Resolvers
+	fieldResolvers := make(map[string]string, len(resolvers))
+	errors := make([]*TransformError, 0)
+
+	keys := utils.MapKeys(resolvers)
+	sort.Strings(keys)
+	for _, key := range keys {
+		fnName := resolvers[key]
+
+		typeName, fieldName, ok := manifest.SplitResolverKey(key)
+		if !ok {
+			errors = append(errors, &TransformError{key, fmt.Errorf("invalid resolver key %s, expected Type.field", key)})
+			continue
+		}
+
+		t, ok := resultTypeDefs[typeName]
+		if !ok {
+			errors = append(errors, &TransformError{key, fmt.Errorf("type %s for resolver %s was not found", typeName, key)})
+			continue
+		}
+
+		if slices.ContainsFunc(t.Fields, func(f *FieldDefinition) bool { return f.Name == fieldName }) {
+			errors = append(errors, &TransformError{key, fmt.Errorf("type %s already has a field named %s", typeName, fieldName)})
+			continue
+		}
+
+		fn, ok := functions[fnName]
+		if !ok {
+			errors = append(errors, &TransformError{key, fmt.Errorf("function %s for resolver %s was not found", fnName, key)})
+			continue
+		}
+
+		returnType, err := resolverReturnType(fn, lti, resultTypeDefs)
+		if err != nil {
+			errors = append(errors, &TransformError{fn, err})
+			continue
+		}
+
+		field := &FieldDefinition{
+			Name:     fieldName,
+			Type:     returnType,
+			Function: fnName,
+		}
+		if fn.Docs != nil {
+			field.DocLines = fn.Docs.Lines
+		}
+
+		t.Fields = append(t.Fields, field)
+		fieldResolvers[key] = fnName
+	}
+
+	resolverFns := make(map[string]bool, len(fieldResolvers))
+	for _, fnName := range fieldResolvers {
+		resolverFns[fnName] = true
+	}
+	isResolver := func(f *FieldDefinition) bool {
+		return f.Collection == nil && resolverFns[f.Function]
+	}
+	root.QueryFields = slices.DeleteFunc(root.QueryFields, isResolver)
+	root.MutationFields = slices.DeleteFunc(root.MutationFields, isResolver)
+
+	return fieldResolvers, errors
+}
+
+// resolverReturnType returns the GraphQL type of the field that the resolver function resolves.
+func resolverReturnType(fn *metadata.Function, lti langsupport.LanguageTypeInfo, resultTypeDefs map[string]*TypeDefinition) (string, error) {
+	if !fn.Batch {
+		if len(fn.Parameters) != 1 {
+			return "", fmt.Errorf("resolver function %s must have exactly one parameter, for the parent object", fn.Name)
+		}
+		if len(fn.Results) != 1 {
+			return "", fmt.Errorf("resolver function %s must return exactly one result", fn.Name)
+		}
+		return convertType(fn.Results[0].Type, lti, resultTypeDefs, false, false)
+	}
+
+	if len(fn.Parameters) != 1 || !isListType(fn.Parameters[0].Type, lti) {
+		return "", fmt.Errorf("batch resolver function %s must have exactly one parameter, for the list of parent objects", fn.Name)
+	}
+	if len(fn.Results) != 1 || !isListType(fn.Results[0].Type, lti) {
+		return "", fmt.Errorf("batch resolver function %s must return a list, with one result for each parent object", fn.Name)
+	}
+	return convertType(lti.GetListSubtype(underlyingType(fn.Results[0].Type, lti)), lti, resultTypeDefs, false, false)
+}
+
+func isListType(typ string, lti langsupport.LanguageTypeInfo) bool {
+	return lti.IsListType(underlyingType(typ, lti))
+}
+
+// underlyingType unwraps nullable types and pointers.
+func underlyingType(typ string, lti langsupport.LanguageTypeInfo) string {
+	for lti.IsNullableType(typ) {
+		t := lti.GetUnderlyingType(typ)
+		if t == typ {
+			break
+		}
+		typ = t
+	}
+	return typ
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package schemagen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/lib/metadata"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetGraphQLSchema_Resolvers(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{
+		Resolvers: map[string]string{
+			"Person.friends": "getFriends",
+		},
+	})
+
+	md := metadata.NewPluginMetadata()
+	md.SDK = "modus-sdk-go"
+
+	md.FnExports.AddFunction("getPerson").
+		WithParameter("name", "string").
+		WithResult("testdata.Person")
+
+	md.FnExports.AddFunction("getFriends").
+		WithParameter("people", "[]testdata.Person").
+		WithResult("[][]testdata.Person").
+		WithBatch()
+
+	md.Types.AddType("[][]testdata.Person")
+	md.Types.AddType("[]testdata.Person")
+	md.Types.AddType("testdata.Person").
+		WithField("name", "string").
+		WithField("age", "int32")
+
+	result, err := GetGraphQLSchema(context.Background(), md)
+	require.Nil(t, err)
+
+	require.Contains(t, result.Schema, "type Person {\n  name: String!\n  age: Int!\n  friends: [Person!]\n}")
+	require.Contains(t, result.Schema, "person(name: String!): Person!")
+	require.NotContains(t, result.Schema, "friends(")
+	require.Equal(t, map[string]string{"Person.friends": "getFriends"}, result.FieldResolvers)
+	require.NotContains(t, result.FieldsToFunctions, "friends")
+}
+
+func Test_GetGraphQLSchema_SingleParentResolvers(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{
+		Resolvers: map[string]string{
+			"Person.parent": "getParent",
+		},
+	})
+
+	md := metadata.NewPluginMetadata()
+	md.SDK = "modus-sdk-go"
+
+	md.FnExports.AddFunction("getPerson").
+		WithParameter("name", "string").
+		WithResult("testdata.Person")
+
+	md.FnExports.AddFunction("getParent").
+		WithParameter("person", "testdata.Person").
+		WithResult("*testdata.Person")
+
+	md.Types.AddType("*testdata.Person")
+	md.Types.AddType("testdata.Person").
+		WithField("name", "string")
+
+	result, err := GetGraphQLSchema(context.Background(), md)
+	require.Nil(t, err)
+
+	require.Contains(t, result.Schema, "type Person {\n  name: String!\n  parent: Person\n}")
+	require.NotContains(t, result.Schema, "parent(")
+	require.Equal(t, map[string]string{"Person.parent": "getParent"}, result.FieldResolvers)
+}
+
+func Test_GetGraphQLSchema_ResolverErrors(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{
+		Resolvers: map[string]string{
+			"Person.name":    "getFriends",
+			"Missing.field":  "getFriends",
+			"Person.related": "missingFunction",
+			"Person.parent":  "getParent",
+			"Person.sibling": "getSibling",
+		},
+	})
+
+	md := metadata.NewPluginMetadata()
+	md.SDK = "modus-sdk-go"
+
+	md.FnExports.AddFunction("getFriends").
+		WithParameter("people", "[]testdata.Person").
+		WithResult("[][]testdata.Person")
+
+	md.FnExports.AddFunction("getParent").
+		WithParameter("person", "testdata.Person").
+		WithResult("testdata.Person").
+		WithBatch()
+
+	md.FnExports.AddFunction("getSibling").
+		WithParameter("person", "testdata.Person").
+		WithParameter("name", "string").
+		WithResult("testdata.Person")
+
+	md.Types.AddType("[][]testdata.Person")
+	md.Types.AddType("[]testdata.Person")
+	md.Types.AddType("testdata.Person").
+		WithField("name", "string")
+
+	_, err := GetGraphQLSchema(context.Background(), md)
+	require.ErrorContains(t, err, "already has a field named name")
+	require.ErrorContains(t, err, "type Missing for resolver Missing.field was not found")
+	require.ErrorContains(t, err, "function missingFunction for resolver Person.related was not found")
+	require.ErrorContains(t, err, "batch resolver function getParent must have exactly one parameter, for the list of parent objects")
+	require.ErrorContains(t, err, "resolver function getSibling must have exactly one parameter, for the parent object")
+}
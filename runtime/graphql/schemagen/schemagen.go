@@ -25,9 +25,15 @@ import (
 )
 
 type GraphQLSchema struct {
-	Schema            string
-	FieldsToFunctions map[string]string
-	MapTypes          []string
+	Schema              string
+	FieldsToFunctions   map[string]string
+	FieldsToCollections map[string]CollectionField
+	FieldsToBuiltins    map[string]string
+	FieldsToPipelines   map[string]string
+	FieldResolvers      map[string]string
+	FieldDirectives     map[string][]*Directive
+	FunctionDirectives  map[string][]*Directive
+	MapTypes            []string
 }
 
 func GetGraphQLSchema(ctx context.Context, md *metadata.Metadata) (*GraphQLSchema, error) {
@@ -45,6 +51,14 @@ func GetGraphQLSchema(ctx context.Context, md *metadata.Metadata) (*GraphQLSchem
 	errors = append(errors, errs...)
 	root, errs := transformFunctions(md.FnExports, inputTypeDefs, resultTypeDefs, lti)
 	errors = append(errors, errs...)
+	fieldResolvers, errs := addResolverFields(md.FnExports, root, resultTypeDefs, lti)
+	errors = append(errors, errs...)
+	errs = addCollectionFields(ctx, root, resultTypeDefs)
+	errors = append(errors, errs...)
+	errs = addPipelineFields(md.FnExports, root, inputTypeDefs, resultTypeDefs, lti)
+	errors = append(errors, errs...)
+	errs = addFeedbackFields(root)
+	errors = append(errors, errs...)
 
 	if len(errors) > 0 {
 		return nil, fmt.Errorf("failed to generate schema: %+v", errors)
@@ -55,8 +69,18 @@ func GetGraphQLSchema(ctx context.Context, md *metadata.Metadata) (*GraphQLSchem
 	inputTypes := filterTypes(utils.MapValues(inputTypeDefs), allFields, true)
 	resultTypes := filterTypes(utils.MapValues(resultTypeDefs), allFields, false)
 
+	directives, errs := extractDirectives(root, inputTypes, resultTypes)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to generate schema: %+v", errs)
+	}
+
+	functionDirectives, errs := collectFunctionDirectives(md.FnExports, root, resultTypes)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to generate schema: %+v", errs)
+	}
+
 	buf := bytes.Buffer{}
-	writeSchema(&buf, root, scalarTypes, inputTypes, resultTypes)
+	writeSchema(&buf, root, scalarTypes, directives, inputTypes, resultTypes)
 
 	mapTypes := make([]string, 0, len(resultTypeDefs))
 	for _, t := range resultTypeDefs {
@@ -66,14 +90,39 @@ func GetGraphQLSchema(ctx context.Context, md *metadata.Metadata) (*GraphQLSchem
 	}
 
 	fieldsToFunctions := make(map[string]string, len(allFields))
+	fieldsToCollections := make(map[string]CollectionField)
+	fieldsToBuiltins := make(map[string]string)
+	fieldsToPipelines := make(map[string]string)
+	fieldDirectives := make(map[string][]*Directive)
+	resultTypesByName := typesByName(resultTypes)
 	for _, f := range allFields {
-		fieldsToFunctions[f.Name] = f.Function
+		// the directives of function fields are enforced when their functions are called
+		if !isFunctionField(f) {
+			if dirs := append(slices.Clone(f.Directives), resultTypeDirectives(f.Type, resultTypesByName)...); len(dirs) > 0 {
+				fieldDirectives[f.Name] = dirs
+			}
+		}
+		if f.Collection != nil {
+			fieldsToCollections[f.Name] = *f.Collection
+		} else if f.Builtin != "" {
+			fieldsToBuiltins[f.Name] = f.Builtin
+		} else if f.Pipeline != "" {
+			fieldsToPipelines[f.Name] = f.Pipeline
+		} else {
+			fieldsToFunctions[f.Name] = f.Function
+		}
 	}
 
 	return &GraphQLSchema{
-		Schema:            buf.String(),
-		FieldsToFunctions: fieldsToFunctions,
-		MapTypes:          mapTypes,
+		Schema:              buf.String(),
+		FieldsToFunctions:   fieldsToFunctions,
+		FieldsToCollections: fieldsToCollections,
+		FieldsToBuiltins:    fieldsToBuiltins,
+		FieldsToPipelines:   fieldsToPipelines,
+		FieldResolvers:      fieldResolvers,
+		FieldDirectives:     fieldDirectives,
+		FunctionDirectives:  functionDirectives,
+		MapTypes:            mapTypes,
 	}, nil
 }
 
@@ -90,7 +139,7 @@ func transformTypes(types metadata.TypeMap, lti langsupport.LanguageTypeInfo, fo
 	typeDefs := make(map[string]*TypeDefinition, len(types))
 	errors := make([]*TransformError, 0)
 	for _, t := range types {
-		if lti.IsListType(t.Name) || lti.IsMapType(t.Name) || lti.IsTimestampType(t.Name) {
+		if lti.IsListType(t.Name) || lti.IsMapType(t.Name) || lti.IsTimestampType(t.Name) || lti.IsRawJsonType(t.Name) {
 			continue
 		}
 		if lti.GetUnderlyingType(t.Name) != t.Name {
@@ -133,18 +182,23 @@ func transformTypes(types metadata.TypeMap, lti langsupport.LanguageTypeInfo, fo
 }
 
 type FieldDefinition struct {
-	Name      string
-	Type      string
-	Arguments []*ArgumentDefinition
-	Function  string
-	DocLines  []string
+	Name       string
+	Type       string
+	Arguments  []*ArgumentDefinition
+	Function   string
+	Collection *CollectionField
+	Builtin    string
+	Pipeline   string
+	DocLines   []string
+	Directives []*Directive
 }
 
 type TypeDefinition struct {
-	Name      string
-	Fields    []*FieldDefinition
-	IsMapType bool
-	DocLines  []string
+	Name       string
+	Fields     []*FieldDefinition
+	IsMapType  bool
+	DocLines   []string
+	Directives []*Directive
 }
 
 type ArgumentDefinition struct {
@@ -290,7 +344,7 @@ func getBaseType(name string) string {
 	return name
 }
 
-func writeSchema(buf *bytes.Buffer, root *RootObjects, scalarTypes []string, inputTypeDefs, resultTypeDefs []*TypeDefinition) {
+func writeSchema(buf *bytes.Buffer, root *RootObjects, scalarTypes []string, directives []*directiveDeclaration, inputTypeDefs, resultTypeDefs []*TypeDefinition) {
 
 	// write header
 	buf.WriteString("# Modus GraphQL Schema (auto-generated)\n")
@@ -341,6 +395,10 @@ func writeSchema(buf *bytes.Buffer, root *RootObjects, scalarTypes []string, inp
 			buf.WriteByte('\n')
 		}
 	}
+
+	// write directive declarations
+	writeDirectiveDeclarations(buf, directives)
+
 	// write input types
 	for _, t := range inputTypeDefs {
 		buf.WriteByte('\n')
@@ -356,6 +414,7 @@ func writeSchema(buf *bytes.Buffer, root *RootObjects, scalarTypes []string, inp
 
 		buf.WriteString("input ")
 		buf.WriteString(t.Name)
+		writeDirectives(buf, t.Directives)
 		buf.WriteString(" {\n")
 		for _, f := range t.Fields {
 
@@ -373,6 +432,7 @@ func writeSchema(buf *bytes.Buffer, root *RootObjects, scalarTypes []string, inp
 			buf.WriteString(f.Name)
 			buf.WriteString(": ")
 			buf.WriteString(f.Type)
+			writeDirectives(buf, f.Directives)
 			buf.WriteByte('\n')
 		}
 		buf.WriteString("}\n")
@@ -393,6 +453,7 @@ func writeSchema(buf *bytes.Buffer, root *RootObjects, scalarTypes []string, inp
 
 		buf.WriteString("type ")
 		buf.WriteString(t.Name)
+		writeDirectives(buf, t.Directives)
 		buf.WriteString(" {\n")
 		for _, f := range t.Fields {
 
@@ -410,6 +471,7 @@ func writeSchema(buf *bytes.Buffer, root *RootObjects, scalarTypes []string, inp
 			buf.WriteString(f.Name)
 			buf.WriteString(": ")
 			buf.WriteString(f.Type)
+			writeDirectives(buf, f.Directives)
 			buf.WriteByte('\n')
 		}
 		buf.WriteString("}\n")
@@ -451,6 +513,7 @@ func writeField(buf *bytes.Buffer, field *FieldDefinition) {
 	}
 	buf.WriteString(": ")
 	buf.WriteString(field.Type)
+	writeDirectives(buf, field.Directives)
 	buf.WriteByte('\n')
 }
 
@@ -589,6 +652,12 @@ func convertType(typ string, lti langsupport.LanguageTypeInfo, typeDefs map[stri
 		typ = t
 	}
 
+	// A result that is a string of JSON is typed as a JSON scalar, and its value is passed through to the response.
+	// As input, it is still a string, to be parsed by the function.
+	if !forInput && lti.IsRawJsonType(typ) {
+		return newScalar("JSON", typeDefs) + n, nil
+	}
+
 	// convert basic types
 	// TODO: How do we want to provide GraphQL "ID" scalar types? Maybe they're annotated? or maybe by naming convention?
 
@@ -621,6 +690,8 @@ func convertType(typ string, lti langsupport.LanguageTypeInfo, typeDefs map[stri
 
 		switch size {
 		case 8:
+			// 64-bit integers don't fit in the GraphQL Int type, or in a JavaScript number.  Values of these
+			// scalars may be given as JSON numbers or as strings, and are converted without losing precision.
 			if signed {
 				return newScalar("Int64", typeDefs) + n, nil
 			} else {
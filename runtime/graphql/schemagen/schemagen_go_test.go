@@ -433,6 +433,9 @@ func Test_ConvertType_Go(t *testing.T) {
 		{"uint32", true, "UInt!", nil, []*TypeDefinition{{Name: "UInt"}}},
 		{"uint64", false, "UInt64!", nil, []*TypeDefinition{{Name: "UInt64"}}},
 		{"uint64", true, "UInt64!", nil, []*TypeDefinition{{Name: "UInt64"}}},
+		{"*int64", false, "Int64", nil, []*TypeDefinition{{Name: "Int64"}}},
+		{"[]uint64", true, "[UInt64!]", nil, []*TypeDefinition{{Name: "UInt64"}}},
+		{"time.Duration", false, "Int64!", nil, []*TypeDefinition{{Name: "Int64"}}},
 
 		// Custom types
 		{"testdata.User", false, "User!",
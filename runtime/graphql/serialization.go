@@ -0,0 +1,53 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package graphql
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+const jsonOptionsHeader = "X-Modus-Json-Options"
+
+// getJsonFormatOptions returns the serialization options for a GraphQL response.
+// The defaults come from the command line, and can be overridden per request with a header
+// containing a comma-separated list of options, such as "omit-nulls,pretty" or "no-pretty".
+func getJsonFormatOptions(r *http.Request) utils.JsonFormatOptions {
+	omitNulls := config.JsonOmitNulls
+	pretty := config.JsonPretty
+
+	for _, opt := range strings.Split(r.Header.Get(jsonOptionsHeader), ",") {
+		switch strings.ToLower(strings.TrimSpace(opt)) {
+		case "omit-nulls":
+			omitNulls = true
+		case "no-omit-nulls":
+			omitNulls = false
+		case "pretty":
+			pretty = true
+		case "no-pretty":
+			pretty = false
+		}
+	}
+
+	opts := utils.JsonFormatOptions{
+		OmitNulls: omitNulls,
+
+		// Keep the members of the top-level response object, and the root fields of the data object,
+		// since GraphQL clients rely on their presence.
+		OmitNullsMinDepth: 2,
+	}
+	if pretty {
+		opts.Indent = "  "
+	}
+	return opts
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package guardrails
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/models"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+// moderationRequest and moderationResponse follow the OpenAI moderation API,
+// which is also implemented by many other moderation providers.
+type moderationRequest struct {
+	Input string `json:"input"`
+}
+
+type moderationResponse struct {
+	Results []struct {
+		Flagged    bool            `json:"flagged"`
+		Categories map[string]bool `json:"categories"`
+	} `json:"results"`
+}
+
+func (g *guardrail) check(ctx context.Context, content string) (flagged bool, categories []string, err error) {
+	switch g.info.Type {
+	case manifest.GuardrailTypeModel:
+		return g.checkWithModel(ctx, content)
+	case manifest.GuardrailTypeFunction:
+		return g.checkWithFunction(ctx, content)
+	case manifest.GuardrailTypePatterns:
+		return g.checkWithPatterns(content)
+	default:
+		return false, nil, fmt.Errorf("unknown guardrail type: %s", g.info.Type)
+	}
+}
+
+func (g *guardrail) checkWithModel(ctx context.Context, content string) (bool, []string, error) {
+	model, err := models.GetModel(g.info.Model)
+	if err != nil {
+		return false, nil, err
+	}
+
+	res, err := models.PostToModelEndpoint[moderationResponse](ctx, model, moderationRequest{Input: content})
+	if err != nil {
+		return false, nil, err
+	}
+
+	flagged := false
+	categories := []string{}
+	for _, r := range res.Results {
+		if r.Flagged {
+			flagged = true
+		}
+		for category, hit := range r.Categories {
+			if hit {
+				categories = append(categories, category)
+			}
+		}
+	}
+	sort.Strings(categories)
+
+	return flagged, categories, nil
+}
+
+func (g *guardrail) checkWithFunction(ctx context.Context, content string) (bool, []string, error) {
+	info, err := wasmhost.CallFunction(ctx, g.info.Function, content)
+	if err != nil {
+		return false, nil, err
+	}
+
+	flagged, ok := info.Result().(bool)
+	if !ok {
+		return false, nil, fmt.Errorf("moderation function %s must return a boolean", g.info.Function)
+	}
+
+	return flagged, nil, nil
+}
+
+func (g *guardrail) checkWithPatterns(content string) (bool, []string, error) {
+	categories := []string{}
+	for _, re := range g.patterns {
+		if re.MatchString(content) {
+			categories = append(categories, re.String())
+		}
+	}
+	return len(categories) > 0, categories, nil
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package guardrails
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/metrics"
+	"github.com/hypermodeinc/modus/runtime/models"
+)
+
+type guardrail struct {
+	info     manifest.GuardrailInfo
+	patterns []*regexp.Regexp
+}
+
+var guardrails []*guardrail
+var mu sync.RWMutex
+
+func Initialize() {
+	manifestdata.RegisterManifestLoadedCallback(loadGuardrails)
+	models.RegisterInvocationHook(&moderationHook{})
+}
+
+func loadGuardrails(ctx context.Context) error {
+	man := manifestdata.GetManifest()
+
+	results := make([]*guardrail, 0, len(man.Guardrails))
+	for _, info := range man.Guardrails {
+		g := &guardrail{info: info}
+		for _, p := range info.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return fmt.Errorf("invalid pattern for guardrail %s: %w", info.Name, err)
+			}
+			g.patterns = append(g.patterns, re)
+		}
+		results = append(results, g)
+	}
+
+	// apply guardrails in a stable order, regardless of map iteration order
+	slices.SortFunc(results, func(a, b *guardrail) int {
+		if a.info.Name < b.info.Name {
+			return -1
+		} else if a.info.Name > b.info.Name {
+			return 1
+		}
+		return 0
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	guardrails = results
+	return nil
+}
+
+func (g *guardrail) appliesTo(model *manifest.ModelInfo, stage manifest.GuardrailStage) bool {
+	// never moderate the moderation model itself
+	if g.info.Type == manifest.GuardrailTypeModel && g.info.Model == model.Name {
+		return false
+	}
+
+	if len(g.info.Models) > 0 && !slices.Contains(g.info.Models, model.Name) {
+		return false
+	}
+
+	stages := g.info.Stages
+	if len(stages) == 0 {
+		stages = []manifest.GuardrailStage{manifest.GuardrailStageOutput}
+	}
+	return slices.Contains(stages, stage)
+}
+
+func (g *guardrail) policy() manifest.GuardrailPolicy {
+	if g.info.Policy == "" {
+		return manifest.GuardrailPolicyBlock
+	}
+	return g.info.Policy
+}
+
+func moderate(ctx context.Context, model *manifest.ModelInfo, stage manifest.GuardrailStage, content string) error {
+	mu.RLock()
+	active := guardrails
+	mu.RUnlock()
+
+	for _, g := range active {
+		if !g.appliesTo(model, stage) {
+			continue
+		}
+
+		flagged, categories, err := g.check(ctx, content)
+		if err != nil {
+			return fmt.Errorf("error checking content with guardrail %s: %w", g.info.Name, err)
+		}
+		if !flagged {
+			continue
+		}
+
+		policy := g.policy()
+		metrics.GuardrailViolationsNum.WithLabelValues(g.info.Name, string(policy)).Inc()
+
+		// audit record
+		logger.Warn(ctx).
+			Str("guardrail", g.info.Name).
+			Str("model", model.Name).
+			Str("stage", string(stage)).
+			Str("policy", string(policy)).
+			Strs("categories", categories).
+			Bool("user_visible", true).
+			Msg("Content was flagged by a guardrail.")
+
+		if policy == manifest.GuardrailPolicyBlock {
+			return fmt.Errorf("model %s was blocked by guardrail %s", stage, g.info.Name)
+		}
+	}
+
+	return nil
+}
+
+type moderationHook struct{}
+
+func (h *moderationHook) BeforeInvoke(ctx context.Context, model *manifest.ModelInfo, input string) (string, error) {
+	if err := moderate(ctx, model, manifest.GuardrailStageInput, input); err != nil {
+		return "", err
+	}
+	return input, nil
+}
+
+func (h *moderationHook) AfterInvoke(ctx context.Context, model *manifest.ModelInfo, input, output string) (string, error) {
+	if err := moderate(ctx, model, manifest.GuardrailStageOutput, output); err != nil {
+		return "", err
+	}
+	return output, nil
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package guardrails
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatternGuardrails(t *testing.T) {
+	ctx := context.Background()
+	manifestdata.SetManifest(&manifest.Manifest{
+		Guardrails: map[string]manifest.GuardrailInfo{
+			"no-secrets": {
+				Name:     "no-secrets",
+				Type:     manifest.GuardrailTypePatterns,
+				Patterns: []string{`(?i)password`},
+				Stages:   []manifest.GuardrailStage{manifest.GuardrailStageInput, manifest.GuardrailStageOutput},
+			},
+			"flag-only": {
+				Name:     "flag-only",
+				Type:     manifest.GuardrailTypePatterns,
+				Patterns: []string{`forbidden`},
+				Models:   []string{"model-a"},
+				Policy:   manifest.GuardrailPolicyFlag,
+			},
+		},
+	})
+
+	err := loadGuardrails(ctx)
+	assert.NoError(t, err)
+
+	modelA := &manifest.ModelInfo{Name: "model-a"}
+	modelB := &manifest.ModelInfo{Name: "model-b"}
+
+	hook := &moderationHook{}
+
+	_, err = hook.BeforeInvoke(ctx, modelA, "what is my PASSWORD?")
+	assert.Error(t, err)
+
+	out, err := hook.AfterInvoke(ctx, modelA, "", "this is forbidden")
+	assert.NoError(t, err)
+	assert.Equal(t, "this is forbidden", out)
+
+	out, err = hook.AfterInvoke(ctx, modelB, "", "hello world")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", out)
+
+	_, err = hook.AfterInvoke(ctx, modelB, "", "the password is hunter2")
+	assert.Error(t, err)
+}
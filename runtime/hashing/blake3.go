@@ -0,0 +1,189 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hashing
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// This is a portable implementation of the BLAKE3 hash function, following the reference implementation at
+// https://github.com/BLAKE3-team/BLAKE3/blob/master/reference_impl/reference_impl.rs.
+// Only the default hash mode with a 32-byte output is needed.
+
+const (
+	blake3BlockLen = 64
+	blake3ChunkLen = 1024
+
+	blake3ChunkStart = 1 << 0
+	blake3ChunkEnd   = 1 << 1
+	blake3Parent     = 1 << 2
+	blake3Root       = 1 << 3
+)
+
+var blake3IV = [8]uint32{
+	0x6A09E667, 0xBB67AE85, 0x3C6EF372, 0xA54FF53A, 0x510E527F, 0x9B05688C, 0x1F83D9AB, 0x5BE0CD19,
+}
+
+var blake3MsgPermutation = [16]int{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8}
+
+func blake3G(s *[16]uint32, a, b, c, d int, mx, my uint32) {
+	s[a] += s[b] + mx
+	s[d] = bits.RotateLeft32(s[d]^s[a], -16)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -12)
+	s[a] += s[b] + my
+	s[d] = bits.RotateLeft32(s[d]^s[a], -8)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -7)
+}
+
+func blake3Compress(cv *[8]uint32, block *[16]uint32, counter uint64, blockLen, flags uint32) [16]uint32 {
+	s := [16]uint32{
+		cv[0], cv[1], cv[2], cv[3], cv[4], cv[5], cv[6], cv[7],
+		blake3IV[0], blake3IV[1], blake3IV[2], blake3IV[3],
+		uint32(counter), uint32(counter >> 32), blockLen, flags,
+	}
+	m := *block
+	for round := range 7 {
+		blake3G(&s, 0, 4, 8, 12, m[0], m[1])
+		blake3G(&s, 1, 5, 9, 13, m[2], m[3])
+		blake3G(&s, 2, 6, 10, 14, m[4], m[5])
+		blake3G(&s, 3, 7, 11, 15, m[6], m[7])
+		blake3G(&s, 0, 5, 10, 15, m[8], m[9])
+		blake3G(&s, 1, 6, 11, 12, m[10], m[11])
+		blake3G(&s, 2, 7, 8, 13, m[12], m[13])
+		blake3G(&s, 3, 4, 9, 14, m[14], m[15])
+		if round < 6 {
+			var p [16]uint32
+			for i, j := range blake3MsgPermutation {
+				p[i] = m[j]
+			}
+			m = p
+		}
+	}
+	for i := range 8 {
+		s[i] ^= s[i+8]
+		s[i+8] ^= cv[i]
+	}
+	return s
+}
+
+type blake3Output struct {
+	cv       [8]uint32
+	block    [16]uint32
+	counter  uint64
+	blockLen uint32
+	flags    uint32
+}
+
+func (o *blake3Output) chainingValue() [8]uint32 {
+	s := blake3Compress(&o.cv, &o.block, o.counter, o.blockLen, o.flags)
+	return [8]uint32(s[:8])
+}
+
+func (o *blake3Output) rootHash() []byte {
+	s := blake3Compress(&o.cv, &o.block, 0, o.blockLen, o.flags|blake3Root)
+	out := make([]byte, 32)
+	for i := range 8 {
+		binary.LittleEndian.PutUint32(out[i*4:], s[i])
+	}
+	return out
+}
+
+func blake3ParentOutput(left, right [8]uint32) *blake3Output {
+	o := &blake3Output{cv: blake3IV, blockLen: blake3BlockLen, flags: blake3Parent}
+	copy(o.block[:8], left[:])
+	copy(o.block[8:], right[:])
+	return o
+}
+
+type blake3ChunkState struct {
+	cv               [8]uint32
+	counter          uint64
+	block            [blake3BlockLen]byte
+	blockLen         int
+	blocksCompressed int
+}
+
+func (c *blake3ChunkState) len() int {
+	return c.blocksCompressed*blake3BlockLen + c.blockLen
+}
+
+func (c *blake3ChunkState) startFlag() uint32 {
+	if c.blocksCompressed == 0 {
+		return blake3ChunkStart
+	}
+	return 0
+}
+
+func (c *blake3ChunkState) update(input []byte) {
+	for len(input) > 0 {
+		if c.blockLen == blake3BlockLen {
+			words := blake3Words(&c.block)
+			s := blake3Compress(&c.cv, &words, c.counter, blake3BlockLen, c.startFlag())
+			c.cv = [8]uint32(s[:8])
+			c.blocksCompressed++
+			c.block = [blake3BlockLen]byte{}
+			c.blockLen = 0
+		}
+		n := copy(c.block[c.blockLen:], input)
+		c.blockLen += n
+		input = input[n:]
+	}
+}
+
+func (c *blake3ChunkState) output() *blake3Output {
+	return &blake3Output{
+		cv:       c.cv,
+		block:    blake3Words(&c.block),
+		counter:  c.counter,
+		blockLen: uint32(c.blockLen),
+		flags:    c.startFlag() | blake3ChunkEnd,
+	}
+}
+
+func blake3Words(block *[blake3BlockLen]byte) [16]uint32 {
+	var words [16]uint32
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(block[i*4:])
+	}
+	return words
+}
+
+// blake3Sum returns the 32-byte BLAKE3 hash of the data.
+func blake3Sum(data []byte) []byte {
+	chunk := &blake3ChunkState{cv: blake3IV}
+	var stack [][8]uint32
+
+	for len(data) > 0 {
+		if chunk.len() == blake3ChunkLen {
+			cv := chunk.output().chainingValue()
+			total := chunk.counter + 1
+			// merge the completed subtrees, as determined by the number of trailing zeros in the chunk count
+			for total&1 == 0 {
+				cv = blake3ParentOutput(stack[len(stack)-1], cv).chainingValue()
+				stack = stack[:len(stack)-1]
+				total >>= 1
+			}
+			stack = append(stack, cv)
+			chunk = &blake3ChunkState{cv: blake3IV, counter: chunk.counter + 1}
+		}
+		n := min(blake3ChunkLen-chunk.len(), len(data))
+		chunk.update(data[:n])
+		data = data[n:]
+	}
+
+	out := chunk.output()
+	for i := len(stack) - 1; i >= 0; i-- {
+		out = blake3ParentOutput(stack[i], out.chainingValue())
+	}
+	return out.rootHash()
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package hashing provides hashing, signing and canonical serialization for plugins,
+// so that they can compute stable cache keys and verify webhook signatures without their own implementations.
+package hashing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"hash"
+)
+
+// Hash algorithms supported by Hash.  HMAC signing supports SHA-256 and SHA-512.
+const (
+	AlgorithmSha256 = "sha256"
+	AlgorithmSha512 = "sha512"
+	AlgorithmBlake3 = "blake3"
+)
+
+// Hash returns the hash of the data, computed with the given algorithm.
+func Hash(algorithm string, data []byte) ([]byte, error) {
+	switch algorithm {
+	case AlgorithmSha256:
+		sum := sha256.Sum256(data)
+		return sum[:], nil
+	case AlgorithmSha512:
+		sum := sha512.Sum512(data)
+		return sum[:], nil
+	case AlgorithmBlake3:
+		return blake3Sum(data), nil
+	}
+	return nil, fmt.Errorf("unsupported hash algorithm %s, must be one of sha256, sha512 or blake3", algorithm)
+}
+
+// Hmac returns the HMAC signature of the data, computed with the given key and hash algorithm.
+func Hmac(algorithm string, key, data []byte) ([]byte, error) {
+	var h func() hash.Hash
+	switch algorithm {
+	case AlgorithmSha256:
+		h = sha256.New
+	case AlgorithmSha512:
+		h = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported HMAC algorithm %s, must be one of sha256 or sha512", algorithm)
+	}
+
+	if len(key) == 0 {
+		return nil, fmt.Errorf("an HMAC key is required")
+	}
+
+	mac := hmac.New(h, key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+// ConstantTimeEqual reports whether a and b are equal, in a time that depends only on their lengths,
+// so that comparing a signature to an expected value doesn't reveal how much of it matched.
+func ConstantTimeEqual(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
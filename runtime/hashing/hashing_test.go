@@ -0,0 +1,95 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hashing_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/hypermodeinc/modus/runtime/hashing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	// the BLAKE3 test vectors use inputs of repeating bytes 0 to 250
+	input := func(n int) []byte {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte(i % 251)
+		}
+		return b
+	}
+
+	tests := []struct {
+		algorithm string
+		data      []byte
+		expected  string
+	}{
+		{hashing.AlgorithmSha256, []byte("abc"), "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{hashing.AlgorithmSha512, []byte("abc"), "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+		{hashing.AlgorithmBlake3, nil, "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+		{hashing.AlgorithmBlake3, []byte("abc"), "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85"},
+		{hashing.AlgorithmBlake3, input(1), "2d3adedff11b61f14c886e35afa036736dcd87a74d27b5c1510225d0f592e213"},
+		{hashing.AlgorithmBlake3, input(1024), "42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af7"},
+		{hashing.AlgorithmBlake3, input(1025), "d00278ae47eb27b34faecf67b4fe263f82d5412916c1ffd97c8cb7fb814b8444"},
+		{hashing.AlgorithmBlake3, input(8192), "aae792484c8efe4f19e2ca7d371d8c467ffb10748d8a5a1ae579948f718a2a63"},
+	}
+
+	for _, tt := range tests {
+		sum, err := hashing.Hash(tt.algorithm, tt.data)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, hex.EncodeToString(sum), "%s of %d bytes", tt.algorithm, len(tt.data))
+	}
+
+	_, err := hashing.Hash("md5", nil)
+	require.ErrorContains(t, err, "unsupported hash algorithm md5")
+}
+
+func TestHmac(t *testing.T) {
+	// RFC 4231 test case 2
+	sig, err := hashing.Hmac(hashing.AlgorithmSha256, []byte("Jefe"), []byte("what do ya want for nothing?"))
+	require.NoError(t, err)
+	require.Equal(t, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", hex.EncodeToString(sig))
+
+	_, err = hashing.Hmac(hashing.AlgorithmBlake3, []byte("key"), nil)
+	require.ErrorContains(t, err, "unsupported HMAC algorithm blake3")
+	_, err = hashing.Hmac(hashing.AlgorithmSha256, nil, nil)
+	require.ErrorContains(t, err, "an HMAC key is required")
+}
+
+func TestConstantTimeEqual(t *testing.T) {
+	require.True(t, hashing.ConstantTimeEqual([]byte("signature"), []byte("signature")))
+	require.False(t, hashing.ConstantTimeEqual([]byte("signature"), []byte("signaturf")))
+	require.False(t, hashing.ConstantTimeEqual([]byte("signature"), []byte("sig")))
+}
+
+func TestCanonicalizeJson(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{ "b": 2, "a": [1, 2.50, -0, 1e21, 0.000001, 1E-7], "c": { "z": null, "y": true } }`, `{"a":[1,2.5,0,1e+21,0.000001,1e-7],"b":2,"c":{"y":true,"z":null}}`},
+		{`"\u00e9\u2028<>&\u001f\/"`, "\"é\u2028<>&\\u001f/\""},
+		// names are sorted by UTF-16 code units, so a character outside the BMP sorts before U+FB33
+		{`{"\ufb33": 1, "\ud83d\ude00": 2, "\r": 3, "1": 4}`, "{\"\\r\":3,\"1\":4,\"😀\":2,\"\ufb33\":1}"},
+	}
+
+	for _, tt := range tests {
+		result, err := hashing.CanonicalizeJson([]byte(tt.input))
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, string(result))
+	}
+
+	_, err := hashing.CanonicalizeJson([]byte(`{"a": 1} {"b": 2}`))
+	require.ErrorContains(t, err, "unexpected data after the top-level value")
+	_, err = hashing.CanonicalizeJson([]byte(`{"a": 1e400}`))
+	require.ErrorContains(t, err, "cannot be represented in canonical JSON")
+}
@@ -614,6 +614,21 @@ func (h *Graph[K]) DeleteWithLock(key K) bool {
 	return deleted
 }
 
+// Each calls fn for every node in the graph, in no particular order, until fn returns false.
+func (h *Graph[K]) Each(fn func(key K, vec Vector) bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.layers) == 0 {
+		return
+	}
+
+	for key, node := range h.layers[0].nodes {
+		if !fn(key, node.Value) {
+			return
+		}
+	}
+}
+
 // Lookup returns the vector with the given key.
 func (h *Graph[K]) Lookup(key K) (Vector, bool) {
 	h.mu.RLock()
@@ -32,6 +32,13 @@ func init() {
 			return fmt.Sprintf("Collection: %s, Namespace: %s, Key: %s", collectionName, namespace, key)
 		}))
 
+	registerHostFunction(module_name, "restore", collections.Restore,
+		withCancelledMessage("Cancelled restoring to collection."),
+		withErrorMessage("Error restoring to collection."),
+		withMessageDetail(func(collectionName, namespace, key string) string {
+			return fmt.Sprintf("Collection: %s, Namespace: %s, Key: %s", collectionName, namespace, key)
+		}))
+
 	registerHostFunction(module_name, "getNamespaces", collections.GetNamespaces,
 		withCancelledMessage("Cancelled getting namespaces from collection."),
 		withErrorMessage("Error getting namespaces from collection."),
@@ -60,6 +67,20 @@ func init() {
 			return fmt.Sprintf("Collection: %s, Namespace: %s, Method: %s, Key: %s", collectionName, namespace, searchMethod, key)
 		}))
 
+	registerHostFunction(module_name, "count", collections.Count,
+		withCancelledMessage("Cancelled counting items in collection."),
+		withErrorMessage("Error counting items in collection."),
+		withMessageDetail(func(collectionName, namespace string, labels []string) string {
+			return fmt.Sprintf("Collection: %s, Namespace: %s, Labels: %v", collectionName, namespace, labels)
+		}))
+
+	registerHostFunction(module_name, "countLabels", collections.CountLabels,
+		withCancelledMessage("Cancelled counting labels in collection."),
+		withErrorMessage("Error counting labels in collection."),
+		withMessageDetail(func(collectionName, namespace string) string {
+			return fmt.Sprintf("Collection: %s, Namespace: %s", collectionName, namespace)
+		}))
+
 	registerHostFunction(module_name, "getLabels", collections.GetLabels,
 		withCancelledMessage("Cancelled getting labels from collection."),
 		withErrorMessage("Error getting labels from collection."),
@@ -90,6 +111,15 @@ func init() {
 			return fmt.Sprintf("Collection: %s, Namespaces: %v, Method: %s", collectionName, namespaces, searchMethod)
 		}))
 
+	registerHostFunction(module_name, "ragQuery", collections.RagQuery,
+		withStartingMessage("Answering query from collection."),
+		withCompletedMessage("Completed answering query from collection."),
+		withCancelledMessage("Cancelled answering query from collection."),
+		withErrorMessage("Error answering query from collection."),
+		withMessageDetail(func(collectionName string, namespaces []string, searchMethod, query string, k int32, promptTemplate, modelName string) string {
+			return fmt.Sprintf("Collection: %s, Namespaces: %v, Method: %s, Model: %s", collectionName, namespaces, searchMethod, modelName)
+		}))
+
 	registerHostFunction(module_name, "searchByVector", collections.SearchByVector,
 		withCancelledMessage("Cancelled searching collection by vector."),
 		withErrorMessage("Error searching collection by vector."),
@@ -97,10 +127,38 @@ func init() {
 			return fmt.Sprintf("Collection: %s, Namespaces: %v, Method: %s", collectionName, namespaces, searchMethod)
 		}))
 
+	registerHostFunction(module_name, "searchByImage", collections.SearchByImage,
+		withCancelledMessage("Cancelled searching collection by image."),
+		withErrorMessage("Error searching collection by image."),
+		withMessageDetail(func(collectionName string, namespaces []string, searchMethod string) string {
+			return fmt.Sprintf("Collection: %s, Namespaces: %v, Method: %s", collectionName, namespaces, searchMethod)
+		}))
+
+	registerHostFunction(module_name, "searchByKey", collections.SearchByKey,
+		withCancelledMessage("Cancelled searching collection by key."),
+		withErrorMessage("Error searching collection by key."),
+		withMessageDetail(func(collectionName, namespace, searchMethod, key string) string {
+			return fmt.Sprintf("Collection: %s, Namespace: %s, Method: %s, Key: %s", collectionName, namespace, searchMethod, key)
+		}))
+
 	registerHostFunction(module_name, "upsert", collections.Upsert,
 		withCancelledMessage("Cancelled upserting to collection."),
 		withErrorMessage("Error upserting to collection."),
 		withMessageDetail(func(collectionName, namespace string, keys []string) string {
 			return fmt.Sprintf("Collection: %s, Namespace: %s, Keys: %v", collectionName, namespace, keys)
 		}))
+
+	registerHostFunction(module_name, "upsertBlobs", collections.UpsertBlobs,
+		withCancelledMessage("Cancelled upserting blobs to collection."),
+		withErrorMessage("Error upserting blobs to collection."),
+		withMessageDetail(func(collectionName, namespace string, keys []string) string {
+			return fmt.Sprintf("Collection: %s, Namespace: %s, Keys: %v", collectionName, namespace, keys)
+		}))
+
+	registerHostFunction(module_name, "swapAlias", collections.SwapAlias,
+		withCancelledMessage("Cancelled swapping collection alias."),
+		withErrorMessage("Error swapping collection alias."),
+		withMessageDetail(func(alias, collectionName string) string {
+			return fmt.Sprintf("Alias: %s, Collection: %s", alias, collectionName)
+		}))
 }
@@ -0,0 +1,26 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hostfunctions
+
+import (
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/experiments"
+)
+
+func init() {
+	const module_name = "modus_experiments"
+
+	registerHostFunction(module_name, "recordFeedback", experiments.RecordFeedback,
+		withErrorMessage("Error recording experiment feedback."),
+		withMessageDetail(func(experimentName string) string {
+			return fmt.Sprintf("Experiment: %s", experimentName)
+		}))
+}
@@ -0,0 +1,26 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hostfunctions
+
+import (
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/feedback"
+)
+
+func init() {
+	const module_name = "modus_feedback"
+
+	registerHostFunction(module_name, "recordFeedback", feedback.RecordFromFunction,
+		withErrorMessage("Error recording feedback."),
+		withMessageDetail(func(executionId string) string {
+			return fmt.Sprintf("Execution ID: %s", executionId)
+		}))
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hostfunctions
+
+import (
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/hashing"
+)
+
+func init() {
+	const module_name = "modus_hashing"
+
+	registerHostFunction(module_name, "canonicalizeJson", CanonicalizeJson,
+		withErrorMessage("Error canonicalizing JSON."))
+
+	registerHostFunction(module_name, "computeHash", hashing.Hash,
+		withErrorMessage("Error computing hash."),
+		withMessageDetail(func(algorithm string) string {
+			return fmt.Sprintf("Algorithm: %s", algorithm)
+		}))
+
+	registerHostFunction(module_name, "computeHmac", hashing.Hmac,
+		withErrorMessage("Error computing HMAC signature."),
+		withMessageDetail(func(algorithm string) string {
+			return fmt.Sprintf("Algorithm: %s", algorithm)
+		}))
+
+	registerHostFunction(module_name, "constantTimeEqual", hashing.ConstantTimeEqual)
+}
+
+func CanonicalizeJson(json string) (string, error) {
+	result, err := hashing.CanonicalizeJson([]byte(json))
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
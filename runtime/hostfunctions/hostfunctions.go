@@ -9,7 +9,12 @@
 
 package hostfunctions
 
-import "github.com/hypermodeinc/modus/runtime/wasmhost"
+import (
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/faults"
+	"github.com/hypermodeinc/modus/runtime/sandbox"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
 
 var registrations []func(wasmhost.WasmHost) error
 
@@ -19,10 +24,29 @@ func GetRegistrations() []func(wasmhost.WasmHost) error {
 
 func registerHostFunction(modName, funcName string, fn any, opts ...wasmhost.HostFunctionOption) {
 	registrations = append(registrations, func(host wasmhost.WasmHost) error {
-		return host.RegisterHostFunction(modName, funcName, fn, opts...)
+		// in development and test environments, faults declared in the manifest are injected into calls to external services
+		hf := fn
+		if target := faultTarget(modName, funcName); target != "" && faults.Enabled() {
+			hf = faults.Inject(target, fn)
+		}
+
+		// sandboxed worker processes forward most host functions to the main process
+		return host.RegisterHostFunction(modName, funcName, sandbox.HostFunction(modName, funcName, hf), opts...)
 	})
 }
 
+func faultTarget(modName, funcName string) manifest.FaultTarget {
+	switch {
+	case modName == "modus_http_client" && funcName == "fetch":
+		return manifest.FaultTargetHttp
+	case modName == "modus_models" && funcName == "invokeModel":
+		return manifest.FaultTargetModel
+	case modName == "modus_collections" && funcName != "computeDistance":
+		return manifest.FaultTargetCollection
+	}
+	return ""
+}
+
 func withStartingMessage(text string) wasmhost.HostFunctionOption {
 	return wasmhost.WithStartingMessage(text)
 }
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hostfunctions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/metrics"
+	"github.com/hypermodeinc/modus/runtime/plugins"
+)
+
+func init() {
+	const module_name = "modus_metrics"
+
+	registerHostFunction(module_name, "recordMetric", RecordMetric,
+		withErrorMessage("Error recording metric."),
+		withMessageDetail(func(name, kind string) string {
+			return fmt.Sprintf("Metric: %s, Kind: %s", name, kind)
+		}))
+}
+
+// RecordMetric records a value for a custom metric, labeled with the name of the plugin that recorded it.
+func RecordMetric(ctx context.Context, name, kind string, value float64, tags map[string]string) (bool, error) {
+	var pluginName string
+	if plugin, ok := plugins.GetPluginFromContext(ctx); ok {
+		pluginName = plugin.Name()
+	}
+
+	if err := metrics.RecordCustomMetric(pluginName, name, kind, value, tags); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -33,4 +33,20 @@ func init() {
 		withMessageDetail(func(modelName string) string {
 			return fmt.Sprintf("Model: %s", modelName)
 		}))
+
+	registerHostFunction(module_name, "countTokens", models.CountTokens,
+		withCancelledMessage("Cancelled counting tokens."),
+		withErrorMessage("Error counting tokens."),
+		withMessageDetail(func(modelName string) string {
+			return fmt.Sprintf("Model: %s", modelName)
+		}))
+
+	registerHostFunction(module_name, "transcribeAudio", models.TranscribeAudio,
+		withStartingMessage("Transcribing audio."),
+		withCompletedMessage("Completed audio transcription."),
+		withCancelledMessage("Cancelled audio transcription."),
+		withErrorMessage("Error transcribing audio."),
+		withMessageDetail(func(modelName string) string {
+			return fmt.Sprintf("Model: %s", modelName)
+		}))
 }
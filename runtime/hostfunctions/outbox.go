@@ -0,0 +1,27 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hostfunctions
+
+import (
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/httpclient"
+	"github.com/hypermodeinc/modus/runtime/outbox"
+)
+
+func init() {
+	const module_name = "modus_outbox"
+
+	registerHostFunction(module_name, "stage", outbox.Stage,
+		withErrorMessage("Error staging outbox message."),
+		withMessageDetail(func(request *httpclient.HttpRequest) string {
+			return fmt.Sprintf("%s %s", request.Method, request.Url)
+		}))
+}
@@ -0,0 +1,26 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hostfunctions
+
+import (
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/progress"
+)
+
+func init() {
+	const module_name = "modus_progress"
+
+	registerHostFunction(module_name, "reportProgress", progress.ReportFromFunction,
+		withErrorMessage("Error reporting progress."),
+		withMessageDetail(func(percent float64, message string) string {
+			return fmt.Sprintf("Progress: %v%%", percent)
+		}))
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hostfunctions
+
+import (
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/sessions"
+)
+
+func init() {
+	const module_name = "modus_sessions"
+
+	registerHostFunction(module_name, "append", sessions.Append,
+		withErrorMessage("Error appending to session."),
+		withMessageDetail(func(sessionId string) string {
+			return fmt.Sprintf("Session: %s", sessionId)
+		}))
+
+	registerHostFunction(module_name, "get", sessions.Get,
+		withErrorMessage("Error getting session history."),
+		withMessageDetail(func(sessionId string) string {
+			return fmt.Sprintf("Session: %s", sessionId)
+		}))
+
+	registerHostFunction(module_name, "clear", sessions.Clear,
+		withErrorMessage("Error clearing session."),
+		withMessageDetail(func(sessionId string) string {
+			return fmt.Sprintf("Session: %s", sessionId)
+		}))
+}
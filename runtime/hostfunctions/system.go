@@ -16,8 +16,12 @@ import (
 	"time"
 
 	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/middleware"
+	"github.com/hypermodeinc/modus/runtime/plugins"
+	"github.com/hypermodeinc/modus/runtime/timeutils"
 	"github.com/hypermodeinc/modus/runtime/timezones"
 	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
 )
 
 func init() {
@@ -26,6 +30,43 @@ func init() {
 	registerHostFunction(module_name, "logMessage", LogMessage)
 	registerHostFunction(module_name, "getTimeInZone", GetTimeInZone)
 	registerHostFunction(module_name, "getTimeZoneData", GetTimeZoneData)
+	registerHostFunction(module_name, "getExecutionInfo", GetExecutionInfo)
+	registerHostFunction(module_name, "isCancelled", wasmhost.IsCancelled)
+	registerHostFunction(module_name, "getDeadlineRemaining", GetDeadlineRemaining)
+	registerHostFunction(module_name, "getJWTClaims", GetJWTClaims)
+
+	registerHostFunction(module_name, "parseTime", ParseTime,
+		withErrorMessage("Error parsing time."),
+		withMessageDetail(func(value, format string) string {
+			return fmt.Sprintf("Value: %s, Format: %s", value, format)
+		}))
+	registerHostFunction(module_name, "formatTime", FormatTime,
+		withErrorMessage("Error formatting time."),
+		withMessageDetail(func(timestamp, format string) string {
+			return fmt.Sprintf("Timestamp: %s, Format: %s", timestamp, format)
+		}))
+	registerHostFunction(module_name, "addDuration", AddDuration,
+		withErrorMessage("Error adding duration."),
+		withMessageDetail(func(timestamp, duration string) string {
+			return fmt.Sprintf("Timestamp: %s, Duration: %s", timestamp, duration)
+		}))
+	registerHostFunction(module_name, "formatRelativeTime", timeutils.FormatRelativeTime,
+		withErrorMessage("Error formatting relative time."))
+	registerHostFunction(module_name, "getNextCronTime", GetNextCronTime,
+		withErrorMessage("Error computing next cron time."),
+		withMessageDetail(func(expression string) string {
+			return fmt.Sprintf("Expression: %s", expression)
+		}))
+}
+
+type ExecutionInfo struct {
+	ExecutionId     string
+	FunctionName    string
+	PluginName      string
+	PluginVersion   string
+	BuildId         string
+	RemainingTimeMs int64
+	CallerMetadata  map[string]string
 }
 
 func LogMessage(ctx context.Context, level, message string) {
@@ -76,3 +117,104 @@ func GetTimeZoneData(tz, format *string) []byte {
 
 	return timezones.GetTimeZoneData(*tz, *format)
 }
+
+func GetExecutionInfo(ctx context.Context) *ExecutionInfo {
+	info := &ExecutionInfo{
+		// -1 indicates that the execution has no deadline
+		RemainingTimeMs: -1,
+		CallerMetadata:  map[string]string{},
+	}
+
+	if id, ok := ctx.Value(utils.ExecutionIdContextKey).(string); ok {
+		info.ExecutionId = id
+	}
+	if fnName, ok := ctx.Value(utils.FunctionNameContextKey).(string); ok {
+		info.FunctionName = fnName
+	}
+	if plugin, ok := plugins.GetPluginFromContext(ctx); ok {
+		info.PluginName, info.PluginVersion = plugin.NameAndVersion()
+		info.BuildId = plugin.BuildId()
+	}
+	if remaining, ok := wasmhost.GetDeadlineRemaining(ctx); ok {
+		info.RemainingTimeMs = remaining.Milliseconds()
+	}
+	if md, ok := ctx.Value(utils.CallerMetadataContextKey).(map[string]string); ok {
+		for k, v := range md {
+			info.CallerMetadata[k] = v
+		}
+	}
+
+	return info
+}
+
+// GetDeadlineRemaining returns the number of milliseconds remaining before the request times out,
+// or -1 if the request has no deadline.
+func GetDeadlineRemaining(ctx context.Context) int64 {
+	if remaining, ok := wasmhost.GetDeadlineRemaining(ctx); ok {
+		return remaining.Milliseconds()
+	}
+	return -1
+}
+
+// GetJWTClaims returns the JSON claims of the caller's token, or nil if the request has none.
+// They are read on every call, rather than from the environment, so that instances of plugins that are
+// restored from a snapshot never see the claims of the caller whose request created the snapshot.
+func GetJWTClaims(ctx context.Context) *string {
+	if claims := middleware.GetJWTClaims(ctx); claims != "" {
+		return &claims
+	}
+	return nil
+}
+
+// ParseTime parses a value with a strftime format, interpreting a value without an offset in the given time zone.
+func ParseTime(ctx context.Context, value, format, tz string) (string, error) {
+	loc, err := getLocation(ctx, tz)
+	if err != nil {
+		return "", err
+	}
+	return timeutils.ParseTime(value, format, loc)
+}
+
+// FormatTime converts a timestamp to the given time zone, and formats it with a strftime format.
+func FormatTime(ctx context.Context, timestamp, format, tz string) (string, error) {
+	loc, err := getLocation(ctx, tz)
+	if err != nil {
+		return "", err
+	}
+	return timeutils.FormatTime(timestamp, format, loc)
+}
+
+// AddDuration adds a duration to a timestamp, adding any calendar units in the given time zone.
+func AddDuration(ctx context.Context, timestamp, duration, tz string) (string, error) {
+	loc, err := getLocation(ctx, tz)
+	if err != nil {
+		return "", err
+	}
+	return timeutils.AddDuration(timestamp, duration, loc)
+}
+
+// GetNextCronTime returns the first time after a timestamp that matches a cron expression, evaluated in the given time zone.
+func GetNextCronTime(ctx context.Context, expression, after, tz string) (string, error) {
+	loc, err := getLocation(ctx, tz)
+	if err != nil {
+		return "", err
+	}
+	return timeutils.NextCronTime(expression, after, loc)
+}
+
+// getLocation returns the location of the given time zone, or if none is given, of the time zone of the request.
+func getLocation(ctx context.Context, tz string) (*time.Location, error) {
+	if tz == "" {
+		if ctxTz, ok := ctx.Value(utils.TimeZoneContextKey).(string); ok && ctxTz != "" {
+			tz = ctxTz
+		} else {
+			tz = timezones.GetLocalTimeZone()
+		}
+	}
+
+	loc := timezones.GetLocation(tz)
+	if loc == nil {
+		return nil, fmt.Errorf("unknown time zone %s", tz)
+	}
+	return loc, nil
+}
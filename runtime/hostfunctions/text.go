@@ -0,0 +1,39 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hostfunctions
+
+import (
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/textseg"
+)
+
+func init() {
+	const module_name = "modus_text"
+
+	registerHostFunction(module_name, "segmentText", SegmentText,
+		withErrorMessage("Error segmenting text."),
+		withMessageDetail(func(text, granularity string) string {
+			return fmt.Sprintf("Granularity: %s", granularity)
+		}))
+}
+
+func SegmentText(text, granularity string) ([]string, error) {
+	segments, err := textseg.Segment(text, granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	// an empty result is returned as an empty list, so that it isn't mistaken for an error
+	if segments == nil {
+		segments = []string{}
+	}
+	return segments, nil
+}
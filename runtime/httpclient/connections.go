@@ -77,5 +77,26 @@ func PostToConnectionEndpoint[TResult any](ctx context.Context, connection *mani
 		return secrets.ApplySecretsToHttpRequest(ctx, connection, req)
 	}
 
+	ctx, err := ContextWithConnectionClient(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
 	return utils.PostHttp[TResult](ctx, connection.Endpoint, payload, bs)
 }
+
+// ContextWithConnectionClient returns a context that directs utils.PostHttp to use an HTTP client
+// with the TLS and proxy settings of the connection.  The context is returned unchanged if the
+// connection has no such settings.
+func ContextWithConnectionClient(ctx context.Context, connection *manifest.HTTPConnectionInfo) (context.Context, error) {
+	if connection.TLS == nil && connection.Proxy == "" {
+		return ctx, nil
+	}
+
+	client, err := getClient(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	return context.WithValue(ctx, utils.HttpClientContextKey, client), nil
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+const defaultMaxRedirects = 10
+
+// cookieJars holds the cookie jars for a single function invocation, one per connection.
+type cookieJars struct {
+	jars map[string]http.CookieJar
+	mu   sync.Mutex
+}
+
+// ContextWithCookieJars returns a context that holds cookie jars for the HTTP connections
+// used within a single function invocation.  Cookies are only retained for connections
+// that have cookies enabled in the manifest.
+func ContextWithCookieJars(ctx context.Context) context.Context {
+	return context.WithValue(ctx, utils.CookieJarsContextKey, &cookieJars{jars: make(map[string]http.CookieJar)})
+}
+
+func getCookieJar(ctx context.Context, connection *manifest.HTTPConnectionInfo) (http.CookieJar, error) {
+	cj, ok := ctx.Value(utils.CookieJarsContextKey).(*cookieJars)
+	if !ok {
+		return nil, nil
+	}
+
+	cj.mu.Lock()
+	defer cj.mu.Unlock()
+
+	if jar, ok := cj.jars[connection.Name]; ok {
+		return jar, nil
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	cj.jars[connection.Name] = jar
+	return jar, nil
+}
+
+// getHttpClient returns an HTTP client that applies the cookie and redirect settings of the connection.
+func getHttpClient(ctx context.Context, connection *manifest.HTTPConnectionInfo) (*http.Client, error) {
+	defaultClient, err := getClient(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+	if !connection.Cookies && connection.FollowRedirects == nil && connection.MaxRedirects == 0 {
+		return defaultClient, nil
+	}
+
+	client := *defaultClient
+
+	if connection.Cookies {
+		jar, err := getCookieJar(ctx, connection)
+		if err != nil {
+			return nil, err
+		}
+		client.Jar = jar
+	}
+
+	followRedirects := connection.FollowRedirects == nil || *connection.FollowRedirects
+	maxRedirects := connection.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !followRedirects {
+			// return the redirect response to the caller
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+
+	return &client, nil
+}
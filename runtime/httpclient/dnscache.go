@@ -0,0 +1,96 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package httpclient
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// dnsCache caches the addresses that host names resolve to, so that requests to the same
+// upstream don't each pay for a DNS lookup when new connections are opened.
+type dnsCache struct {
+	ttl     time.Duration
+	lookup  func(ctx context.Context, host string) ([]string, error)
+	entries map[string]dnsCacheEntry
+	mu      sync.RWMutex
+}
+
+type dnsCacheEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+func newDnsCache(ttl time.Duration) *dnsCache {
+	return &dnsCache{
+		ttl:     ttl,
+		lookup:  net.DefaultResolver.LookupHost,
+		entries: make(map[string]dnsCacheEntry),
+	}
+}
+
+func (c *dnsCache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[host]
+	c.mu.RUnlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	addrs, err := c.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[host] = dnsCacheEntry{addrs: addrs, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return addrs, nil
+}
+
+// Forget removes a host from the cache, so that the next lookup is not served from the cache.
+func (c *dnsCache) Forget(host string) {
+	c.mu.Lock()
+	delete(c.entries, host)
+	c.mu.Unlock()
+}
+
+// dialContext returns a dial function that connects using the cached addresses of the host,
+// trying each address in turn.  If none of them can be reached, the cache entry is discarded.
+func (c *dnsCache) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		addrs, err := c.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var dialErr error
+		for _, ip := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			dialErr = err
+		}
+
+		c.Forget(host)
+		return nil, dialErr
+	}
+}
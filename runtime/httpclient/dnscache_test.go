@@ -0,0 +1,113 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDnsCacheLookupHost(t *testing.T) {
+	lookups := 0
+	c := newDnsCache(time.Minute)
+	c.lookup = func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		return []string{"10.0.0.1"}, nil
+	}
+
+	for range 3 {
+		addrs, err := c.LookupHost(context.Background(), "example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(addrs) != 1 || addrs[0] != "10.0.0.1" {
+			t.Errorf("unexpected addresses: %v", addrs)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("expected 1 lookup, got %d", lookups)
+	}
+
+	c.Forget("example.com")
+	if _, err := c.LookupHost(context.Background(), "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if lookups != 2 {
+		t.Errorf("expected 2 lookups after forgetting the host, got %d", lookups)
+	}
+}
+
+func TestDnsCacheExpiry(t *testing.T) {
+	lookups := 0
+	c := newDnsCache(time.Nanosecond)
+	c.lookup = func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		return []string{"10.0.0.1"}, nil
+	}
+
+	for range 2 {
+		if _, err := c.LookupHost(context.Background(), "example.com"); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if lookups != 2 {
+		t.Errorf("expected expired entries to be looked up again, got %d lookups", lookups)
+	}
+}
+
+func TestDnsCacheLookupError(t *testing.T) {
+	c := newDnsCache(time.Minute)
+	c.lookup = func(ctx context.Context, host string) ([]string, error) {
+		return nil, errors.New("no such host")
+	}
+
+	if _, err := c.LookupHost(context.Background(), "example.com"); err == nil {
+		t.Error("expected an error")
+	}
+	if len(c.entries) != 0 {
+		t.Error("expected failed lookups not to be cached")
+	}
+}
+
+func TestDnsCacheDial(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	c := newDnsCache(time.Minute)
+	c.lookup = func(ctx context.Context, host string) ([]string, error) {
+		// the first address is unreachable, so the dialer should fall back to the second
+		return []string{"127.0.0.2", "127.0.0.1"}, nil
+	}
+	dial := c.dialContext(&net.Dialer{Timeout: time.Second})
+
+	conn, err := dial(context.Background(), "tcp", net.JoinHostPort("upstream.test", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
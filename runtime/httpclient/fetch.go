@@ -15,6 +15,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/hypermodeinc/modus/runtime/secrets"
 	"github.com/hypermodeinc/modus/runtime/utils"
@@ -26,6 +27,12 @@ func Fetch(ctx context.Context, request *HttpRequest) (*HttpResponse, error) {
 		return nil, err
 	}
 
+	if request.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(request.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
 	body := bytes.NewBuffer(request.Body)
 	req, err := http.NewRequestWithContext(ctx, request.Method, request.Url, body)
 	if err != nil {
@@ -38,11 +45,18 @@ func Fetch(ctx context.Context, request *HttpRequest) (*HttpResponse, error) {
 		}
 	}
 
+	utils.InjectTraceContext(ctx, req)
+
 	if err := secrets.ApplySecretsToHttpRequest(ctx, host, req); err != nil {
 		return nil, err
 	}
 
-	resp, err := utils.HttpClient().Do(req)
+	client, err := getHttpClient(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/metrics"
+	"github.com/hypermodeinc/modus/runtime/proxy"
+	"github.com/hypermodeinc/modus/runtime/tlsconfig"
+)
+
+// getSharedClient returns the HTTP client shared by all functions, whose transport pools
+// connections across function invocations.
+var getSharedClient = sync.OnceValue(func() *http.Client {
+	return &http.Client{Transport: &instrumentedTransport{base: newTransport()}}
+})
+
+// connectionClients holds the HTTP clients for connections that have their own TLS or proxy
+// settings, keyed by the connection hash so that changes to the manifest produce a new client.
+// They are all discarded when the manifest is loaded, because the certificates and keys that a
+// connection refers to can be rotated without changing the connection itself.
+var connectionClients sync.Map
+
+func Initialize() {
+	manifestdata.RegisterManifestLoadedCallback(func(ctx context.Context) error {
+		resetConnectionClients()
+		return nil
+	})
+}
+
+func resetConnectionClients() {
+	connectionClients.Range(func(key, client any) bool {
+		connectionClients.Delete(key)
+		client.(*http.Client).CloseIdleConnections()
+		return true
+	})
+}
+
+// getClient returns the HTTP client for the connection, which is the shared client
+// unless the connection has its own TLS or proxy settings.
+func getClient(ctx context.Context, connection *manifest.HTTPConnectionInfo) (*http.Client, error) {
+	if connection.TLS == nil && connection.Proxy == "" {
+		return getSharedClient(), nil
+	}
+
+	key := connection.Hash()
+	if client, ok := connectionClients.Load(key); ok {
+		return client.(*http.Client), nil
+	}
+
+	tlsConfig, err := tlsconfig.ForConnection(ctx, connection, connection.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyFunc, err := proxy.ForConnection(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := newTransport()
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = proxyFunc
+	client := &http.Client{Transport: &instrumentedTransport{base: transport}}
+
+	actual, _ := connectionClients.LoadOrStore(key, client)
+	return actual.(*http.Client), nil
+}
+
+func newTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+	transport.MaxIdleConns = 0
+	transport.MaxIdleConnsPerHost = config.HttpMaxIdleConnsPerHost
+	transport.MaxConnsPerHost = config.HttpMaxConnsPerHost
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.Proxy = proxy.FromConfig
+
+	if config.HttpDnsCacheTtl > 0 {
+		transport.DialContext = newDnsCache(config.HttpDnsCacheTtl).dialContext(dialer)
+	} else {
+		transport.DialContext = dialer.DialContext
+	}
+
+	return transport
+}
+
+// instrumentedTransport records metrics for each request, and for the connection it used.
+type instrumentedTransport struct {
+	base http.RoundTripper
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			metrics.HttpClientConnectionsNum.WithLabelValues(host, strconv.FormatBool(info.Reused)).Inc()
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	metrics.HttpClientRequestDurationSeconds.WithLabelValues(host).Observe(time.Since(start).Seconds())
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.HttpClientRequestsNum.WithLabelValues(host, code).Inc()
+
+	return resp, err
+}
+
+func (t *instrumentedTransport) CloseIdleConnections() {
+	if ct, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		ct.CloseIdleConnections()
+	}
+}
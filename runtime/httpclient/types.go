@@ -10,10 +10,11 @@
 package httpclient
 
 type HttpRequest struct {
-	Url     string
-	Method  string
-	Headers *HttpHeaders
-	Body    []byte
+	Url       string
+	Method    string
+	Headers   *HttpHeaders
+	Body      []byte
+	TimeoutMs int64
 }
 
 type HttpResponse struct {
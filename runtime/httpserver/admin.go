@@ -0,0 +1,367 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package httpserver
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/hypermodeinc/modus/runtime/bulkimport"
+	"github.com/hypermodeinc/modus/runtime/collections"
+	"github.com/hypermodeinc/modus/runtime/evaluation"
+	"github.com/hypermodeinc/modus/runtime/privacy"
+	"github.com/hypermodeinc/modus/runtime/shadow"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// driftHandler reports the results of the most recent embedding drift check for each collection search method.
+var driftHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	j, err := utils.JsonSerialize(collections.GetDriftReports())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	_, _ = w.Write(j)
+})
+
+// searchStatsHandler reports latency and estimated recall statistics for each collection search method.
+var searchStatsHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	j, err := utils.JsonSerialize(collections.GetSearchStats())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	_, _ = w.Write(j)
+})
+
+// restoreHandler reports the progress of restoring the collections at startup.
+var restoreHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	j, err := utils.JsonSerialize(collections.GetRestoreProgress())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	_, _ = w.Write(j)
+})
+
+// textMemoryHandler reports the memory used by the texts of each collection namespace, with and without compression.
+var textMemoryHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	j, err := utils.JsonSerialize(collections.GetTextMemoryStats(r.Context()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	_, _ = w.Write(j)
+})
+
+// projectionHandler computes a two-dimensional projection of a collection namespace's vectors, for visualization.
+// The collection's access policy applies, using the claims of the bearer token given with the request.
+var projectionHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	collection := q.Get("collection")
+	searchMethod := q.Get("searchMethod")
+	if collection == "" || searchMethod == "" {
+		http.Error(w, "collection and searchMethod are required", http.StatusBadRequest)
+		return
+	}
+
+	sampleSize := 0
+	if s := q.Get("sampleSize"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			http.Error(w, "invalid sampleSize: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		sampleSize = n
+	}
+
+	projection, err := collections.ProjectVectors(r.Context(), collection, q.Get("namespace"), searchMethod, sampleSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	j, err := utils.JsonSerialize(projection)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	_, _ = w.Write(j)
+})
+
+// compareHandler replays a corpus of requests against the live and shadow builds of a plugin,
+// and reports the requests whose results differ.
+var compareHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var opts shadow.ComparisonOptions
+	if err := utils.JsonDeserialize(body, &opts); err != nil {
+		http.Error(w, "invalid comparison options: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := shadow.Compare(r.Context(), &opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	j, err := utils.JsonSerialize(report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	_, _ = w.Write(j)
+})
+
+// evaluateHandler runs a dataset of queries against a collection, and reports the retrieval and answer quality.
+// The collection's access policy applies, using the claims of the bearer token given with the request.
+var evaluateHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var opts evaluation.EvaluationOptions
+	if err := utils.JsonDeserialize(body, &opts); err != nil {
+		http.Error(w, "invalid evaluation options: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := evaluation.Evaluate(r.Context(), &opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	j, err := utils.JsonSerialize(report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	_, _ = w.Write(j)
+})
+
+// importHandler loads the rows of a Parquet or Arrow IPC file from the app's storage into a collection.
+// The collection's access policy applies, using the claims of the bearer token given with the request.
+var importHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var opts bulkimport.ImportOptions
+	if err := utils.JsonDeserialize(body, &opts); err != nil {
+		http.Error(w, "invalid import options: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status := http.StatusOK
+	result, err := bulkimport.Import(r.Context(), &opts)
+	if err != nil {
+		if result == nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// report how far the import got, along with the error
+		status = http.StatusInternalServerError
+		result.Error = err.Error()
+	}
+
+	j, err := utils.JsonSerialize(result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	w.WriteHeader(status)
+	_, _ = w.Write(j)
+})
+
+const maxIngestUploadBytes = 256 << 20
+
+// ingestHandler accepts a CSV or JSON Lines upload, as a multipart form with "options" and "file" parts,
+// and starts a background job that ingests it into a collection.  The response has the id of the job.
+// The collection's access policy applies, using the claims of the bearer token given with the request.
+var ingestHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxIngestUploadBytes)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		http.Error(w, "invalid upload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+
+	var opts bulkimport.IngestOptions
+	if err := utils.JsonDeserialize([]byte(r.FormValue("options")), &opts); err != nil {
+		http.Error(w, "invalid ingest options: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "invalid upload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := bulkimport.StartIngest(r.Context(), &opts, content)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	j, err := utils.JsonSerialize(map[string]string{"jobId": id})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = w.Write(j)
+})
+
+// jobsHandler reports the status and progress of the ingestion job given by the id query parameter.
+var jobsHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	job, ok := bulkimport.GetJob(r.URL.Query().Get("id"))
+	if !ok {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+
+	j, err := utils.JsonSerialize(job)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	_, _ = w.Write(j)
+})
+
+// deleteSubjectDataHandler permanently deletes the data of a data subject from all collections and stored logs,
+// and responds with a signed deletion report.  The access policies of the collections apply, using the claims
+// of the bearer token given with the request.
+var deleteSubjectDataHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req privacy.SubjectDataRequest
+	if err := utils.JsonDeserialize(body, &req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := privacy.DeleteSubjectData(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	j, err := utils.JsonSerialize(report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	_, _ = w.Write(j)
+})
+
+// verifyDeletionReportHandler reports whether a deletion report is unaltered since it was signed.
+var verifyDeletionReportHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var report privacy.DeletionReport
+	if err := utils.JsonDeserialize(body, &report); err != nil {
+		http.Error(w, "invalid deletion report: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	valid, err := privacy.VerifyReport(r.Context(), &report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	j, err := utils.JsonSerialize(map[string]bool{"valid": valid})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJsonContentHeader(w)
+	_, _ = w.Write(j)
+})
@@ -12,14 +12,48 @@ package httpserver
 import (
 	"net/http"
 
+	"github.com/hypermodeinc/modus/runtime/app"
+	"github.com/hypermodeinc/modus/runtime/collections"
 	"github.com/hypermodeinc/modus/runtime/config"
 	"github.com/hypermodeinc/modus/runtime/utils"
 )
 
+type healthResponse struct {
+	Status      string                       `json:"status"`
+	Environment string                       `json:"environment"`
+	Version     string                       `json:"version"`
+	Restore     *collections.RestoreProgress `json:"restore,omitempty"`
+}
+
 var healthHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-	env := config.GetEnvironmentName()
-	ver := config.GetVersionNumber()
-	w.WriteHeader(http.StatusOK)
+	resp := healthResponse{
+		Status:      "ok",
+		Environment: config.GetEnvironmentName(),
+		Version:     config.GetVersionNumber(),
+	}
+	code := http.StatusOK
+
+	// report that the runtime is not yet ready while it is warming up, or while collections are being restored,
+	// unless functions are served during restoration, in which case the runtime is only partially available
+	switch {
+	case app.IsWarmingUp():
+		resp.Status, code = "warming_up", http.StatusServiceUnavailable
+	case collections.IsRestoring():
+		resp.Restore = collections.GetRestoreProgress()
+		if config.PartialAvailability {
+			resp.Status = "partial"
+		} else {
+			resp.Status, code = "restoring", http.StatusServiceUnavailable
+		}
+	}
+
+	j, err := utils.JsonSerialize(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	utils.WriteJsonContentHeader(w)
-	_, _ = w.Write([]byte(`{"status":"ok","environment":"` + env + `","version":"` + ver + `"}`))
+	w.WriteHeader(code)
+	_, _ = w.Write(j)
 })
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/progress"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// progressKeepAliveInterval is how often a comment is sent on an idle progress stream, so proxies don't close it.
+const progressKeepAliveInterval = 15 * time.Second
+
+// progressHandler streams the progress events of a function execution as server-sent events.
+// The stream is identified by the last path segment, which is either an execution id,
+// or the request id that the caller gave in the X-Request-Id header of the function call.
+var progressHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/progress/")
+	if key == "" || strings.Contains(key, "/") {
+		http.Error(w, "an execution id or request id is required", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	replay, events, cancel := progress.Subscribe(key)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(ev progress.Event) bool {
+		data, err := utils.JsonSerialize(ev)
+		if err != nil {
+			return false
+		}
+		name := "progress"
+		if ev.Done {
+			name = "done"
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	for _, ev := range replay {
+		if !write(ev) {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(progressKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok || !write(ev) {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+})
@@ -29,6 +29,7 @@ import (
 	"github.com/hypermodeinc/modus/runtime/manifestdata"
 	"github.com/hypermodeinc/modus/runtime/metrics"
 	"github.com/hypermodeinc/modus/runtime/middleware"
+	"github.com/hypermodeinc/modus/runtime/webhooks"
 
 	"github.com/fatih/color"
 	"github.com/rs/cors"
@@ -130,8 +131,21 @@ func GetMainHandler(options ...func(map[string]http.Handler)) http.Handler {
 
 	// Create default routes.
 	defaultRoutes := map[string]http.Handler{
-		"/health":  healthHandler,
-		"/metrics": metrics.MetricsHandler,
+		"/health":                     healthHandler,
+		"/metrics":                    metrics.MetricsHandler,
+		"/admin/collections/drift":    driftHandler,
+		"/admin/collections/stats":    searchStatsHandler,
+		"/admin/collections/evaluate": middleware.HandleJWT(evaluateHandler),
+		"/admin/collections/import":   middleware.HandleJWT(importHandler),
+		"/admin/collections/ingest":   middleware.HandleJWT(ingestHandler),
+		"/admin/collections/jobs":     jobsHandler,
+		"/admin/collections/memory":   textMemoryHandler,
+		"/admin/collections/project":  middleware.HandleJWT(projectionHandler),
+		"/admin/collections/restore":  restoreHandler,
+		"/admin/plugins/compare":      compareHandler,
+		"/admin/privacy/delete":       middleware.HandleJWT(deleteSubjectDataHandler),
+		"/admin/privacy/verify":       verifyDeletionReportHandler,
+		"/progress/":                  middleware.HandleJWT(progressHandler),
 	}
 
 	if config.IsDevEnvironment() {
@@ -175,12 +189,22 @@ func GetMainHandler(options ...func(map[string]http.Handler)) http.Handler {
 					continue
 				}
 
+				handler = middleware.HandleTraceContext(handler)
 				routes[info.Path] = metrics.InstrumentHandler(handler, name)
 
 				url := fmt.Sprintf("http://localhost:%d%s", config.Port, info.Path)
 				logger.Info(ctx).Str("url", url).Msg("Registered GraphQL endpoint.")
 				endpoints = append(endpoints, endpoint{"GraphQL", name, url})
 
+			case manifest.EndpointTypeWebhook:
+				info := ep.(manifest.WebhookEndpointInfo)
+				handler := middleware.HandleTraceContext(webhooks.NewHandler(info))
+				routes[info.Path] = metrics.InstrumentHandler(handler, name)
+
+				url := fmt.Sprintf("http://localhost:%d%s", config.Port, info.Path)
+				logger.Info(ctx).Str("url", url).Str("function", info.Function).Msg("Registered webhook endpoint.")
+				endpoints = append(endpoints, endpoint{"Webhook", name, url})
+
 			default:
 				logger.Warn(ctx).Str("endpoint", name).Msg("Unsupported endpoint type.")
 			}
@@ -234,7 +258,8 @@ func GetMainHandler(options ...func(map[string]http.Handler)) http.Handler {
 		AllowedHeaders: []string{"Authorization", "Content-Type"},
 	})
 
-	return c.Handler(handler)
+	// Record all requests in the access log, if it is enabled.
+	return middleware.HandleAccessLog(c.Handler(handler))
 }
 
 func restrictHttpMethods(next http.Handler) http.Handler {
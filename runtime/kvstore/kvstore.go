@@ -0,0 +1,117 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package kvstore provides a key-value store for runtime state that needs to
+// outlive a single function execution.  Entries can optionally expire.
+package kvstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/puzpuzpuz/xsync/v3"
+)
+
+const sweepInterval = time.Minute
+
+type entry struct {
+	value   []byte
+	expires time.Time
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
+
+var store = xsync.NewMapOf[string, *entry]()
+var quit chan struct{}
+var done chan struct{}
+
+func Initialize(ctx context.Context) {
+	quit = make(chan struct{})
+	done = make(chan struct{})
+	go sweeper()
+}
+
+func Shutdown() {
+	if quit == nil {
+		return
+	}
+	close(quit)
+	<-done
+}
+
+// Get returns the value stored for the key, if it exists and has not expired.
+func Get(key string) ([]byte, bool) {
+	e, ok := store.Load(key)
+	if !ok || e.expired(time.Now()) {
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set stores the value for the key.  If ttl is greater than zero, the entry expires after that duration.
+func Set(key string, value []byte, ttl time.Duration) {
+	e := &entry{value: value}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	store.Store(key, e)
+}
+
+// Update atomically replaces the value stored for the key with the result of fn,
+// which receives the current value (or nil if there is none).
+func Update(key string, ttl time.Duration, fn func(current []byte) ([]byte, error)) error {
+	var fnErr error
+	store.Compute(key, func(old *entry, loaded bool) (*entry, bool) {
+		var current []byte
+		if loaded && !old.expired(time.Now()) {
+			current = old.value
+		}
+
+		value, err := fn(current)
+		if err != nil {
+			fnErr = err
+			return old, !loaded
+		}
+
+		e := &entry{value: value}
+		if ttl > 0 {
+			e.expires = time.Now().Add(ttl)
+		}
+		return e, false
+	})
+	return fnErr
+}
+
+// Delete removes the key from the store.
+func Delete(key string) {
+	store.Delete(key)
+}
+
+func sweeper() {
+	defer close(done)
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			store.Range(func(key string, e *entry) bool {
+				if e.expired(now) {
+					store.Delete(key)
+				}
+				return true
+			})
+		case <-quit:
+			return
+		}
+	}
+}
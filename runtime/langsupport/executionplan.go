@@ -124,7 +124,12 @@ func (plan *executionPlan) InvokeFunction(ctx context.Context, wa WasmAdapter, p
 		indirectPtr = uint32(params[0])
 	}
 
-	// Interpret and return the results
+	// Interpret and return the results, within the result's size limit (if any)
+	var resultType TypeInfo
+	if handlers := plan.ResultHandlers(); len(handlers) == 1 {
+		resultType = handlers[0].TypeInfo()
+	}
+	ctx = withResultBudget(ctx, resultType)
 	return plan.interpretWasmResults(ctx, wa, res, indirectPtr)
 }
 
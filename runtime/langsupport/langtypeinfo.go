@@ -40,4 +40,8 @@ type LanguageTypeInfo interface {
 	IsSignedIntegerType(typ string) bool
 	IsStringType(typ string) bool
 	IsTimestampType(typ string) bool
+
+	// IsRawJsonType reports whether the type is a string of JSON that the function serialized itself,
+	// which is passed through to the response without being serialized again.
+	IsRawJsonType(typ string) bool
 }
@@ -0,0 +1,182 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package langsupport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// The result of a function is read from wasm memory within a size limit, so that a function cannot exhaust the
+// host's memory by returning a huge value.  Type handlers charge the size of the data they read to the result's
+// budget, which is carried in the context.  When the limit is exceeded, reading stops with a ResponseTooLargeError.
+// If truncation is allowed and the result is a list, the list is cut short instead, and a continuation cursor
+// is returned, from which a later invocation of the function can resume reading the list.
+
+// ResultLimit is the size limit of a function's result.
+type ResultLimit struct {
+	// MaxBytes is the maximum number of bytes read from wasm memory.  Zero means there is no limit.
+	MaxBytes int
+
+	// Truncate is set when a list result that exceeds the limit is truncated, rather than failing.
+	Truncate bool
+
+	// Offset is the index of the first item of a list result to read, from a continuation cursor.
+	Offset int
+}
+
+// ResultTruncation describes a list result that was truncated to fit the size limit.
+type ResultTruncation struct {
+	// Offset is the index of the first item that was returned.
+	Offset int `json:"offset"`
+
+	// Returned is the number of items that were returned.
+	Returned int `json:"returned"`
+
+	// Total is the number of items in the full list.
+	Total int `json:"total"`
+}
+
+// Next returns the offset of the first item that was not returned, from which reading can continue.
+func (t *ResultTruncation) Next() int {
+	return t.Offset + t.Returned
+}
+
+// ResponseTooLargeError is returned when a function's result exceeds its size limit.
+type ResponseTooLargeError struct {
+	MaxBytes int
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("the response exceeds the limit of %d bytes", e.MaxBytes)
+}
+
+func IsResponseTooLarge(err error) bool {
+	var e *ResponseTooLargeError
+	return errors.As(err, &e)
+}
+
+type resultLimitKey struct{}
+type resultBudgetKey struct{}
+
+type resultLimitState struct {
+	limit      ResultLimit
+	truncation *ResultTruncation
+}
+
+type resultBudget struct {
+	state     *resultLimitState
+	remaining int
+	truncate  bool
+}
+
+// ContextWithResultLimit returns a context in which the result of the function being invoked is read within
+// the limit.  The returned function reports whether the result was truncated, after the function completes.
+func ContextWithResultLimit(ctx context.Context, limit ResultLimit) (context.Context, func() *ResultTruncation) {
+	s := &resultLimitState{limit: limit}
+	return context.WithValue(ctx, resultLimitKey{}, s), func() *ResultTruncation { return s.truncation }
+}
+
+// withResultBudget returns a context in which reading the result of a function is charged to its budget,
+// if the result has a size limit.  It is used only for reading results, and not for any other data that
+// is read from wasm memory while the function is running.
+func withResultBudget(ctx context.Context, resultType TypeInfo) context.Context {
+	s, ok := ctx.Value(resultLimitKey{}).(*resultLimitState)
+	if !ok || s.limit.MaxBytes <= 0 {
+		return ctx
+	}
+
+	b := &resultBudget{
+		state:     s,
+		remaining: s.limit.MaxBytes,
+		// only a list can be truncated, and only when it is the entire result
+		truncate: s.limit.Truncate && resultType != nil && resultType.IsList() && !resultType.IsByteSequence(),
+	}
+	return context.WithValue(ctx, resultBudgetKey{}, b)
+}
+
+// ChargeResultBytes charges the size of data about to be read from wasm memory to the budget of the result
+// being read, if any.  It returns a ResponseTooLargeError if the data does not fit.
+func ChargeResultBytes(ctx context.Context, size uint32) error {
+	if b, ok := ctx.Value(resultBudgetKey{}).(*resultBudget); ok {
+		return b.charge(int(size))
+	}
+	return nil
+}
+
+func (b *resultBudget) charge(size int) error {
+	if size > b.remaining {
+		b.remaining = 0
+		return &ResponseTooLargeError{MaxBytes: b.state.limit.MaxBytes}
+	}
+	b.remaining -= size
+	return nil
+}
+
+// ListRead is the range of items to read from a list in wasm memory.
+type ListRead struct {
+	Start, End uint32
+	length     uint32
+	budget     *resultBudget
+}
+
+// Len returns the number of items in the range.
+func (r *ListRead) Len() int {
+	return int(r.End - r.Start)
+}
+
+// StartListRead returns the range of items to read from a list of the given length and item size, charging
+// the size of the items to the result's budget.  If the list is a result that can be truncated, the range starts
+// at the continuation offset and ends at the last item whose own size fits the limit.
+func StartListRead(ctx context.Context, length, itemSize uint32) (*ListRead, error) {
+	r := &ListRead{End: length, length: length}
+
+	b, ok := ctx.Value(resultBudgetKey{}).(*resultBudget)
+	if !ok {
+		return r, nil
+	}
+
+	if !b.truncate {
+		return r, b.charge(int(length) * int(itemSize))
+	}
+
+	// the first list read is the result itself, and lists within it cannot be truncated
+	b.truncate = false
+	r.budget = b
+	r.Start = uint32(min(max(b.state.limit.Offset, 0), int(length)))
+	if itemSize > 0 {
+		r.End = r.Start + uint32(min(int(length-r.Start), b.remaining/int(itemSize)))
+	}
+	_ = b.charge(r.Len() * int(itemSize))
+	if r.End < length {
+		r.truncateAt(r.End)
+	}
+	return r, nil
+}
+
+// Truncate reports whether the list can be truncated before the item at index i, because reading the item
+// failed with the given error.  If so, the range ends before the item.
+func (r *ListRead) Truncate(i uint32, err error) bool {
+	if r.budget == nil || !IsResponseTooLarge(err) {
+		return false
+	}
+	r.truncateAt(i)
+	return true
+}
+
+func (r *ListRead) truncateAt(i uint32) {
+	r.End = i
+	r.budget.state.truncation = &ResultTruncation{
+		Offset:   int(r.Start),
+		Returned: r.Len(),
+		Total:    int(r.length),
+	}
+}
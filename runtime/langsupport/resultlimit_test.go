@@ -0,0 +1,111 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package langsupport
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type listTypeInfo struct {
+	TypeInfo
+}
+
+func (listTypeInfo) IsList() bool         { return true }
+func (listTypeInfo) IsByteSequence() bool { return false }
+
+func Test_ResultLimit_Error(t *testing.T) {
+	ctx, truncation := ContextWithResultLimit(context.Background(), ResultLimit{MaxBytes: 100})
+	ctx = withResultBudget(ctx, nil)
+
+	if err := ChargeResultBytes(ctx, 60); err != nil {
+		t.Fatalf("expected the first read to fit, got %v", err)
+	}
+	if err := ChargeResultBytes(ctx, 60); !IsResponseTooLarge(err) {
+		t.Fatalf("expected a ResponseTooLargeError, got %v", err)
+	}
+	if truncation() != nil {
+		t.Error("expected the result not to be truncated")
+	}
+}
+
+func Test_ResultLimit_Unlimited(t *testing.T) {
+	ctx, _ := ContextWithResultLimit(context.Background(), ResultLimit{})
+	ctx = withResultBudget(ctx, listTypeInfo{})
+
+	if err := ChargeResultBytes(ctx, 1<<30); err != nil {
+		t.Fatalf("expected no limit, got %v", err)
+	}
+	r, err := StartListRead(ctx, 1000, 8)
+	if err != nil || r.Start != 0 || r.End != 1000 {
+		t.Fatalf("expected the whole list, got %d-%d, %v", r.Start, r.End, err)
+	}
+}
+
+func Test_ResultLimit_TruncateList(t *testing.T) {
+	ctx, truncation := ContextWithResultLimit(context.Background(), ResultLimit{MaxBytes: 100, Truncate: true, Offset: 2})
+	ctx = withResultBudget(ctx, listTypeInfo{})
+
+	// ten items of 8 bytes fit the limit, starting from the offset
+	r, err := StartListRead(ctx, 20, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Start != 2 || r.End != 14 {
+		t.Fatalf("expected items 2-14, got %d-%d", r.Start, r.End)
+	}
+
+	// an item whose contents don't fit ends the list before it
+	for i := r.Start; i < r.End; i++ {
+		err := ChargeResultBytes(ctx, 1)
+		if i == 5 {
+			err = &ResponseTooLargeError{MaxBytes: 100}
+		}
+		if err != nil {
+			if !r.Truncate(i, err) {
+				t.Fatalf("expected the list to be truncated at item %d", i)
+			}
+			break
+		}
+	}
+
+	tr := truncation()
+	if tr == nil {
+		t.Fatal("expected the result to be truncated")
+	}
+	if tr.Offset != 2 || tr.Returned != 3 || tr.Total != 20 || tr.Next() != 5 {
+		t.Errorf("unexpected truncation %+v", tr)
+	}
+}
+
+func Test_ResultLimit_NestedListsAreNotTruncated(t *testing.T) {
+	ctx, _ := ContextWithResultLimit(context.Background(), ResultLimit{MaxBytes: 100, Truncate: true})
+	ctx = withResultBudget(ctx, listTypeInfo{})
+
+	outer, err := StartListRead(ctx, 2, 12)
+	if err != nil || outer.Len() != 2 {
+		t.Fatalf("expected the whole outer list, got %d items, %v", outer.Len(), err)
+	}
+
+	inner, err := StartListRead(ctx, 100, 8)
+	if !IsResponseTooLarge(err) {
+		t.Fatalf("expected a ResponseTooLargeError for the inner list, got %v", err)
+	}
+	if inner.Truncate(0, err) {
+		t.Error("expected the inner list not to be truncatable")
+	}
+	if outer.Truncate(1, errors.New("other")) {
+		t.Error("expected only a ResponseTooLargeError to truncate the list")
+	}
+	if !outer.Truncate(1, err) || outer.Len() != 1 {
+		t.Error("expected the outer list to be truncated before its second item")
+	}
+}
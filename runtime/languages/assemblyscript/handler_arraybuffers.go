@@ -38,7 +38,7 @@ func (h *arrayBufferHandler) Read(ctx context.Context, wa langsupport.WasmAdapte
 		return nil, errors.New("failed to read ArrayBuffer pointer")
 	}
 
-	return h.doReadBytes(wa, ptr)
+	return h.doReadBytes(ctx, wa, ptr)
 }
 
 func (h *arrayBufferHandler) Write(ctx context.Context, wa langsupport.WasmAdapter, offset uint32, obj any) (utils.Cleaner, error) {
@@ -59,7 +59,7 @@ func (h *arrayBufferHandler) Decode(ctx context.Context, wa langsupport.WasmAdap
 		return nil, fmt.Errorf("expected 1 value when decoding an ArrayBuffer, got %d", len(vals))
 	}
 
-	return h.doReadBytes(wa, uint32(vals[0]))
+	return h.doReadBytes(ctx, wa, uint32(vals[0]))
 }
 
 func (h *arrayBufferHandler) Encode(ctx context.Context, wa langsupport.WasmAdapter, obj any) ([]uint64, utils.Cleaner, error) {
@@ -71,7 +71,7 @@ func (h *arrayBufferHandler) Encode(ctx context.Context, wa langsupport.WasmAdap
 	return []uint64{uint64(ptr)}, cln, nil
 }
 
-func (h *arrayBufferHandler) doReadBytes(wa langsupport.WasmAdapter, offset uint32) ([]byte, error) {
+func (h *arrayBufferHandler) doReadBytes(ctx context.Context, wa langsupport.WasmAdapter, offset uint32) ([]byte, error) {
 	if offset == 0 {
 		if h.typeInfo.IsNullable() {
 			return nil, nil
@@ -93,6 +93,10 @@ func (h *arrayBufferHandler) doReadBytes(wa langsupport.WasmAdapter, offset uint
 		return nil, nil
 	}
 
+	if err := langsupport.ChargeResultBytes(ctx, size); err != nil {
+		return nil, err
+	}
+
 	bytes, ok := wa.Memory().Read(offset, size)
 	if !ok {
 		return nil, fmt.Errorf("failed to read ArrayBuffer data from WASM memory (size: %d)", size)
@@ -69,24 +69,33 @@ func (h *arrayHandler) Read(ctx context.Context, wa langsupport.WasmAdapter, off
 	}
 
 	elementSize := h.elementHandler.TypeInfo().Size()
-	items := reflect.MakeSlice(h.typeInfo.ReflectedType(), int(arrLen), int(arrLen))
-	for i := uint32(0); i < arrLen; i++ {
+	lr, err := langsupport.StartListRead(ctx, arrLen, elementSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := reflect.MakeSlice(h.typeInfo.ReflectedType(), lr.Len(), lr.Len())
+	for i := lr.Start; i < lr.End; i++ {
 		itemOffset := data + i*elementSize
 		item, err := h.elementHandler.Read(ctx, wa, itemOffset)
 		if err != nil {
+			if lr.Truncate(i, err) {
+				break
+			}
 			return nil, err
 		}
-		items.Index(int(i)).Set(reflect.ValueOf(item))
+		items.Index(int(i - lr.Start)).Set(reflect.ValueOf(item))
 	}
 
-	return items.Interface(), nil
+	return items.Slice(0, lr.Len()).Interface(), nil
 }
 
 func (h *arrayHandler) Write(ctx context.Context, wa langsupport.WasmAdapter, offset uint32, obj any) (utils.Cleaner, error) {
-	items, err := utils.ConvertToSlice(obj)
+	items, release, err := utils.ConvertToPooledSlice(obj)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
 	arrLen := uint32(len(items))
 	if arrLen == 0 {
@@ -134,6 +134,10 @@ func (h *mapHandler) Read(ctx context.Context, wa langsupport.WasmAdapter, offse
 	valueOffset := max(keySize, 4)
 	valueAlign := h.valueHandler.TypeInfo().Alignment()
 
+	if err := langsupport.ChargeResultBytes(ctx, entriesCount*entrySize); err != nil {
+		return nil, err
+	}
+
 	if !h.usePseudoMap {
 		// return a map
 		m := reflect.MakeMapWithSize(h.typeInfo.ReflectedType(), mapSize)
@@ -240,10 +244,12 @@ func (h *mapHandler) Write(ctx context.Context, wa langsupport.WasmAdapter, offs
 		switch t := key.(type) {
 		case string:
 			// Special case for string keys, to avoid encoding to UTF16 twice.
-			bytes := utils.EncodeUTF16(t)
+			buf := utils.GetBuffer(utils.UTF16Len(t))
+			bytes := utils.EncodeUTF16To(*buf, t)
 			hashCode = hash.GetHashCode(bytes)
 
 			ptr, c, err := h.keyHandler.(*stringHandler).doWriteBytes(ctx, wa, bytes)
+			utils.PutBuffer(buf)
 			cln.AddCleaner(c)
 			if err != nil {
 				return cln, errors.New("failed to write map entry key")
@@ -92,8 +92,16 @@ func (h *primitiveArrayHandler[T]) Read(ctx context.Context, wa langsupport.Wasm
 		return []T{}, nil
 	}
 
-	bufferSize := arrLen * uint32(h.converter.TypeSize())
-	buf, ok := wa.Memory().Read(data, bufferSize)
+	typeSize := uint32(h.converter.TypeSize())
+	lr, err := langsupport.StartListRead(ctx, arrLen, typeSize)
+	if err != nil {
+		return nil, err
+	}
+	if lr.Len() == 0 {
+		return []T{}, nil
+	}
+
+	buf, ok := wa.Memory().Read(data+lr.Start*typeSize, uint32(lr.Len())*typeSize)
 	if !ok {
 		return nil, errors.New("failed to read array data")
 	}
@@ -40,7 +40,7 @@ func (h *stringHandler) Read(ctx context.Context, wa langsupport.WasmAdapter, of
 		return nil, errors.New("failed to read string pointer")
 	}
 
-	return h.doReadString(wa, ptr)
+	return h.doReadString(ctx, wa, ptr)
 }
 
 func (h *stringHandler) Write(ctx context.Context, wa langsupport.WasmAdapter, offset uint32, obj any) (utils.Cleaner, error) {
@@ -61,7 +61,7 @@ func (h *stringHandler) Decode(ctx context.Context, wa langsupport.WasmAdapter,
 		return nil, fmt.Errorf("expected 1 value when decoding a string, got %d", len(vals))
 	}
 
-	return h.doReadString(wa, uint32(vals[0]))
+	return h.doReadString(ctx, wa, uint32(vals[0]))
 }
 
 func (h *stringHandler) Encode(ctx context.Context, wa langsupport.WasmAdapter, obj any) ([]uint64, utils.Cleaner, error) {
@@ -73,7 +73,7 @@ func (h *stringHandler) Encode(ctx context.Context, wa langsupport.WasmAdapter,
 	return []uint64{uint64(ptr)}, cln, nil
 }
 
-func (h *stringHandler) doReadString(wa langsupport.WasmAdapter, offset uint32) (any, error) {
+func (h *stringHandler) doReadString(ctx context.Context, wa langsupport.WasmAdapter, offset uint32) (any, error) {
 	if offset == 0 {
 		if h.typeInfo.IsNullable() {
 			return nil, nil
@@ -95,6 +95,10 @@ func (h *stringHandler) doReadString(wa langsupport.WasmAdapter, offset uint32)
 		return "", nil
 	}
 
+	if err := langsupport.ChargeResultBytes(ctx, size); err != nil {
+		return nil, err
+	}
+
 	bytes, ok := wa.Memory().Read(offset, size)
 	if !ok {
 		return nil, fmt.Errorf("failed to read string data from WASM memory (size: %d)", size)
@@ -118,7 +122,11 @@ func (h *stringHandler) doWriteString(ctx context.Context, wa langsupport.WasmAd
 		return 0, nil, err
 	}
 
-	bytes := utils.EncodeUTF16(str)
+	// encode into a pooled buffer, since the bytes are copied into wasm memory
+	buf := utils.GetBuffer(utils.UTF16Len(str))
+	defer utils.PutBuffer(buf)
+
+	bytes := utils.EncodeUTF16To(*buf, str)
 	return h.doWriteBytes(ctx, wa, bytes)
 }
 
@@ -90,7 +90,16 @@ func (h *typedArrayHandler[T]) Read(ctx context.Context, wa langsupport.WasmAdap
 		return []T{}, nil
 	}
 
-	buf, ok := wa.Memory().Read(dataStart, byteLen)
+	typeSize := uint32(h.converter.TypeSize())
+	lr, err := langsupport.StartListRead(ctx, byteLen/typeSize, typeSize)
+	if err != nil {
+		return nil, err
+	}
+	if lr.Len() == 0 {
+		return []T{}, nil
+	}
+
+	buf, ok := wa.Memory().Read(dataStart+lr.Start*typeSize, uint32(lr.Len())*typeSize)
 	if !ok {
 		return nil, errors.New("failed to read array data")
 	}
@@ -22,7 +22,7 @@ import (
 
 func TestGetRuntimeTypeInfo(t *testing.T) {
 
-	buffers := utils.NewOutputBuffers()
+	buffers := utils.NewOutputBuffers(0)
 	mod, err := fixture.WasmHost.GetModuleInstance(fixture.Context, fixture.Plugin, buffers)
 	if err != nil {
 		t.Fatal(err)
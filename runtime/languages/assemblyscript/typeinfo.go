@@ -174,6 +174,11 @@ func (lti *langTypeInfo) IsStringType(typ string) bool {
 	return lti.GetUnderlyingType(typ) == "~lib/string/String"
 }
 
+func (lti *langTypeInfo) IsRawJsonType(typ string) bool {
+	// JSON.Raw is an alias of string in json-as, so it can't be told apart from a string in the metadata.
+	return false
+}
+
 func (lti *langTypeInfo) IsArrayBufferType(typ string) bool {
 	return lti.GetUnderlyingType(typ) == "~lib/arraybuffer/ArrayBuffer"
 }
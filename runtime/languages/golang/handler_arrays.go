@@ -61,10 +61,11 @@ func (h *arrayHandler) Read(ctx context.Context, wa langsupport.WasmAdapter, off
 }
 
 func (h *arrayHandler) Write(ctx context.Context, wa langsupport.WasmAdapter, offset uint32, obj any) (utils.Cleaner, error) {
-	items, err := utils.ConvertToSlice(obj)
+	items, release, err := utils.ConvertToPooledSlice(obj)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
 	cln := utils.NewCleaner()
 	elementSize := h.elementHandler.TypeInfo().Size()
@@ -86,8 +87,9 @@ func (h *arrayHandler) Write(ctx context.Context, wa langsupport.WasmAdapter, of
 	// zero out any remaining space in the array
 	remainingItems := h.arrayLen - len(items)
 	if remainingItems > 0 {
-		zeros := make([]byte, remainingItems*int(elementSize))
-		if ok := wa.Memory().Write(offset, zeros); !ok {
+		zeros := utils.GetBuffer(remainingItems * int(elementSize))
+		defer utils.PutBuffer(zeros)
+		if ok := wa.Memory().Write(offset, *zeros); !ok {
 			return nil, errors.New("failed to zero out remaining array space")
 		}
 	}
@@ -109,10 +111,11 @@ func (h *arrayHandler) Decode(ctx context.Context, wa langsupport.WasmAdapter, v
 }
 
 func (h *arrayHandler) Encode(ctx context.Context, wa langsupport.WasmAdapter, obj any) ([]uint64, utils.Cleaner, error) {
-	items, err := utils.ConvertToSlice(obj)
+	items, release, err := utils.ConvertToPooledSlice(obj)
 	if err != nil {
 		return nil, nil, err
 	}
+	defer release()
 
 	itemLen := int(h.elementHandler.TypeInfo().EncodingLength())
 	res := make([]uint64, h.typeInfo.EncodingLength())
@@ -93,7 +93,7 @@ func (h *primitiveSliceHandler[T]) Read(ctx context.Context, wa langsupport.Wasm
 		return nil, err
 	}
 
-	return h.doReadSlice(wa, data, size)
+	return h.doReadSlice(ctx, wa, data, size)
 }
 
 func (h *primitiveSliceHandler[T]) Write(ctx context.Context, wa langsupport.WasmAdapter, offset uint32, obj any) (utils.Cleaner, error) {
@@ -116,7 +116,7 @@ func (h *primitiveSliceHandler[T]) Decode(ctx context.Context, wa langsupport.Wa
 
 	// note: capacity is not used here
 	data, size := uint32(vals[0]), uint32(vals[1])
-	return h.doReadSlice(wa, data, size)
+	return h.doReadSlice(ctx, wa, data, size)
 }
 
 func (h *primitiveSliceHandler[T]) Encode(ctx context.Context, wa langsupport.WasmAdapter, obj any) ([]uint64, utils.Cleaner, error) {
@@ -133,7 +133,7 @@ func (h *primitiveSliceHandler[T]) Encode(ctx context.Context, wa langsupport.Wa
 	return []uint64{uint64(data), uint64(size), uint64(capacity)}, cln, nil
 }
 
-func (h *primitiveSliceHandler[T]) doReadSlice(wa langsupport.WasmAdapter, data, size uint32) (any, error) {
+func (h *primitiveSliceHandler[T]) doReadSlice(ctx context.Context, wa langsupport.WasmAdapter, data, size uint32) (any, error) {
 	if data == 0 {
 		// nil slice
 		return nil, nil
@@ -144,8 +144,16 @@ func (h *primitiveSliceHandler[T]) doReadSlice(wa langsupport.WasmAdapter, data,
 		return []T{}, nil
 	}
 
-	bufferSize := size * uint32(h.converter.TypeSize())
-	buf, ok := wa.Memory().Read(data, bufferSize)
+	typeSize := uint32(h.converter.TypeSize())
+	lr, err := langsupport.StartListRead(ctx, size, typeSize)
+	if err != nil {
+		return nil, err
+	}
+	if lr.Len() == 0 {
+		return []T{}, nil
+	}
+
+	buf, ok := wa.Memory().Read(data+lr.Start*typeSize, uint32(lr.Len())*typeSize)
 	if !ok {
 		return nil, errors.New("failed to read data from WASM memory")
 	}
@@ -112,19 +112,27 @@ func (h *sliceHandler) doReadSlice(ctx context.Context, wa langsupport.WasmAdapt
 	}
 
 	elementSize := h.elementHandler.TypeInfo().Size()
-	items := reflect.MakeSlice(h.typeInfo.ReflectedType(), int(size), int(size))
-	for i := uint32(0); i < size; i++ {
+	lr, err := langsupport.StartListRead(ctx, size, elementSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := reflect.MakeSlice(h.typeInfo.ReflectedType(), lr.Len(), lr.Len())
+	for i := lr.Start; i < lr.End; i++ {
 		itemOffset := data + i*elementSize
 		item, err := h.elementHandler.Read(ctx, wa, itemOffset)
 		if err != nil {
+			if lr.Truncate(i, err) {
+				break
+			}
 			return nil, err
 		}
 		if !utils.HasNil(item) {
-			items.Index(int(i)).Set(reflect.ValueOf(item))
+			items.Index(int(i - lr.Start)).Set(reflect.ValueOf(item))
 		}
 	}
 
-	return items.Interface(), nil
+	return items.Slice(0, lr.Len()).Interface(), nil
 }
 
 type sliceWriter interface {
@@ -136,10 +144,11 @@ func (h *sliceHandler) doWriteSlice(ctx context.Context, wa langsupport.WasmAdap
 		return 0, nil, nil
 	}
 
-	slice, err := utils.ConvertToSlice(obj)
+	slice, release, err := utils.ConvertToPooledSlice(obj)
 	if err != nil {
 		return 0, nil, err
 	}
+	defer release()
 
 	ptr, cln, err = wa.(*wasmAdapter).makeWasmObject(ctx, h.typeDef.Id, uint32(len(slice)))
 	if err != nil {
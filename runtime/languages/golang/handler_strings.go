@@ -41,7 +41,7 @@ func (h *stringHandler) Read(ctx context.Context, wa langsupport.WasmAdapter, of
 		return "", err
 	}
 
-	return h.doReadString(wa, data, size)
+	return h.doReadString(ctx, wa, data, size)
 }
 
 func (h *stringHandler) Write(ctx context.Context, wa langsupport.WasmAdapter, offset uint32, obj any) (utils.Cleaner, error) {
@@ -69,7 +69,7 @@ func (h *stringHandler) Decode(ctx context.Context, wa langsupport.WasmAdapter,
 	}
 
 	data, size := uint32(vals[0]), uint32(vals[1])
-	return h.doReadString(wa, data, size)
+	return h.doReadString(ctx, wa, data, size)
 }
 
 func (h *stringHandler) Encode(ctx context.Context, wa langsupport.WasmAdapter, obj any) ([]uint64, utils.Cleaner, error) {
@@ -91,11 +91,15 @@ func (h *stringHandler) Encode(ctx context.Context, wa langsupport.WasmAdapter,
 	return []uint64{uint64(data), uint64(size)}, cln, nil
 }
 
-func (h *stringHandler) doReadString(wa langsupport.WasmAdapter, offset, size uint32) (string, error) {
+func (h *stringHandler) doReadString(ctx context.Context, wa langsupport.WasmAdapter, offset, size uint32) (string, error) {
 	if offset == 0 || size == 0 {
 		return "", nil
 	}
 
+	if err := langsupport.ChargeResultBytes(ctx, size); err != nil {
+		return "", err
+	}
+
 	bytes, ok := wa.Memory().Read(offset, size)
 	if !ok {
 		return "", fmt.Errorf("failed to read string data from WASM memory (size: %d)", size)
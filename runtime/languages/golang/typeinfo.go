@@ -194,8 +194,15 @@ func (lti *langTypeInfo) IsSignedIntegerType(typ string) bool {
 	}
 }
 
+// The Go SDK's type for a string of JSON.  It is a string in memory, but is passed through to responses verbatim.
+const rawJsonStringType = "github.com/hypermodeinc/modus/sdk/go/pkg/utils.RawJsonString"
+
 func (lti *langTypeInfo) IsStringType(typ string) bool {
-	return typ == "string"
+	return typ == "string" || typ == rawJsonStringType
+}
+
+func (lti *langTypeInfo) IsRawJsonType(typ string) bool {
+	return typ == rawJsonStringType
 }
 
 func (lti *langTypeInfo) IsTimestampType(typ string) bool {
@@ -28,6 +28,11 @@ import (
 var zlsCloser io.Closer
 
 func Initialize() *zerolog.Logger {
+	return InitializeWithOutput(os.Stderr)
+}
+
+// InitializeWithOutput initializes the logger to write to the given output, rather than to stderr.
+func InitializeWithOutput(out io.Writer) *zerolog.Logger {
 	var writer io.Writer
 	if config.UseJsonLogging {
 		// In JSON mode, we'll log UTC with millisecond precision.
@@ -36,12 +41,12 @@ func Initialize() *zerolog.Logger {
 		zerolog.TimestampFunc = func() time.Time {
 			return time.Now().UTC()
 		}
-		writer = os.Stderr
+		writer = out
 	} else {
 		// In console mode, we can use local time and be a bit prettier.
 		// We'll still log with millisecond precision.
 		zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
-		consoleWriter := zerolog.ConsoleWriter{Out: os.Stderr}
+		consoleWriter := zerolog.ConsoleWriter{Out: out}
 		if config.IsDevEnvironment() {
 			consoleWriter.TimeFormat = "15:04:05.000"
 			consoleWriter.FieldsExclude = []string{
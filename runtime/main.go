@@ -11,12 +11,15 @@ package main
 
 import (
 	"context"
+	"os"
 
 	"github.com/hypermodeinc/modus/runtime/app"
 	"github.com/hypermodeinc/modus/runtime/config"
 	"github.com/hypermodeinc/modus/runtime/envfiles"
 	"github.com/hypermodeinc/modus/runtime/httpserver"
 	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/runner"
+	"github.com/hypermodeinc/modus/runtime/sandbox"
 	"github.com/hypermodeinc/modus/runtime/services"
 	"github.com/hypermodeinc/modus/runtime/utils"
 )
@@ -26,6 +29,14 @@ func main() {
 	// Initialize the configuration
 	config.Initialize()
 
+	// Exit with a non-zero code if requested, after all other deferred cleanup has run.
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
 	// Create the main background context
 	ctx := context.Background()
 
@@ -50,6 +61,21 @@ func main() {
 	ctx = services.Start(ctx)
 	defer services.Stop(ctx)
 
+	// A sandboxed worker process executes functions on behalf of the main process, instead of serving HTTP.
+	if config.IsSandboxWorker {
+		sandbox.ServeWorker(ctx)
+		return
+	}
+
+	// Run a single function from the command line, instead of serving HTTP.
+	if config.RunFunction != "" {
+		if err := runner.RunFunction(ctx, config.RunFunction, config.FunctionArgs, os.Stdout); err != nil {
+			log.Error().Err(err).Str("function", config.RunFunction).Msg("Failed to run function.")
+			exitCode = 1
+		}
+		return
+	}
+
 	// Set local mode in development
 	local := config.IsDevEnvironment()
 
@@ -11,6 +11,9 @@ package manifestdata
 
 import (
 	"context"
+	"fmt"
+	"path"
+	"slices"
 	"sync"
 
 	"github.com/hypermodeinc/modus/lib/manifest"
@@ -21,6 +24,10 @@ import (
 
 const manifestFileName = "modus.json"
 
+// Overlay manifests are merged onto the base manifest, in order of their file names.
+// For example, modus.search.json or modus.team-a.json.
+const overlayFilePattern = "modus.*.json"
+
 var mu sync.RWMutex
 var man = &manifest.Manifest{}
 
@@ -38,10 +45,10 @@ func SetManifest(m *manifest.Manifest) {
 
 func MonitorManifestFile(ctx context.Context) {
 	loadFile := func(file storage.FileInfo) error {
-		logger.Info(ctx).Str("filename", file.Name).Msg("Loading manifest file.")
-		if file.Name != manifestFileName {
+		if !isManifestFile(file.Name) {
 			return nil
 		}
+		logger.Info(ctx).Str("filename", file.Name).Msg("Loading manifest file.")
 
 		if err := loadManifest(ctx); err != nil {
 			logger.Err(ctx, err).Str("filename", file.Name).Msg("Failed to load manifest file.")
@@ -61,6 +68,12 @@ func MonitorManifestFile(ctx context.Context) {
 				logger.Err(ctx, err).Str("filename", file.Name).Msg("Failed to unload manifest file.")
 				return err
 			}
+		} else if isOverlayFile(file.Name) {
+			logger.Info(ctx).Str("filename", file.Name).Msg("Manifest overlay file removed.")
+			if err := loadManifest(ctx); err != nil {
+				logger.Err(ctx, err).Str("filename", file.Name).Msg("Failed to reload manifest after removing overlay file.")
+				return err
+			}
 		}
 		return nil
 	}
@@ -71,27 +84,72 @@ func loadManifest(ctx context.Context) error {
 	span, ctx := utils.NewSentrySpanForCurrentFunc(ctx)
 	defer span.Finish()
 
-	bytes, err := storage.GetFileContents(ctx, manifestFileName)
+	base, err := readManifestFile(ctx, manifestFileName)
 	if err != nil {
 		return err
 	}
 
-	m, err := manifest.ReadManifest(bytes)
+	files, err := storage.ListFiles(ctx, overlayFilePattern)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		if isOverlayFile(file.Name) {
+			names = append(names, file.Name)
+		}
+	}
+	slices.Sort(names)
+
+	overlays := make([]manifestFile, 0, len(names))
+	for _, name := range names {
+		m, err := readManifestFile(ctx, name)
+		if err != nil {
+			return err
+		}
+		overlays = append(overlays, manifestFile{name, m})
+	}
+
+	m, err := mergeManifests(base, overlays)
 	if err != nil {
 		return err
 	}
 
+	// Only update the Manifest global when we have successfully read and merged all of the manifests.
+	SetManifest(m)
+
+	return triggerManifestLoaded(ctx)
+}
+
+func readManifestFile(ctx context.Context, filename string) (*manifest.Manifest, error) {
+	bytes, err := storage.GetFileContents(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := manifest.ReadManifest(bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
 	if !m.IsCurrentVersion() {
 		logger.Warn(ctx).
-			Str("filename", manifestFileName).
+			Str("filename", filename).
 			Int("manifest_version", m.Version).
 			Msg("The manifest file is in a deprecated format.  Please update it to the current format.")
 	}
 
-	// Only update the Manifest global when we have successfully read the manifest.
-	SetManifest(m)
+	return m, nil
+}
 
-	return triggerManifestLoaded(ctx)
+func isManifestFile(filename string) bool {
+	return filename == manifestFileName || isOverlayFile(filename)
+}
+
+func isOverlayFile(filename string) bool {
+	ok, _ := path.Match(overlayFilePattern, filename)
+	return ok
 }
 
 func unloadManifest(ctx context.Context) error {
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifestdata
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+)
+
+// A manifestFile is a manifest along with the name of the file it was read from.
+type manifestFile struct {
+	name     string
+	manifest *manifest.Manifest
+}
+
+// mergeManifests merges overlay manifests onto a base manifest.
+//
+// Each named entry (endpoint, model, connection, collection, etc.) may be defined by any manifest.
+// An overlay may replace an entry defined by the base manifest, but it is an error for two overlays
+// to define the same entry, since neither can be said to own it.  The same rules apply to the
+// top-level settings that are not named entries, such as warmup and isolation.
+//
+// Neither the base nor the overlays are modified.
+func mergeManifests(base *manifest.Manifest, overlays []manifestFile) (*manifest.Manifest, error) {
+	result := *base
+	resultValue := reflect.ValueOf(&result).Elem()
+	manifestType := resultValue.Type()
+
+	// tracks which overlay defined each entry, keyed by section and entry name
+	owners := make(map[string]map[string]string)
+
+	for i := 0; i < manifestType.NumField(); i++ {
+		field := manifestType.Field(i)
+		section := jsonFieldName(field)
+		target := resultValue.Field(i)
+
+		switch field.Type.Kind() {
+		case reflect.Map:
+			merged := reflect.MakeMap(field.Type)
+			if !target.IsNil() {
+				for _, key := range target.MapKeys() {
+					merged.SetMapIndex(key, target.MapIndex(key))
+				}
+			}
+
+			owners[section] = make(map[string]string)
+			for _, overlay := range overlays {
+				src := reflect.ValueOf(overlay.manifest).Elem().Field(i)
+				for _, key := range src.MapKeys() {
+					name := key.String()
+					if owner, ok := owners[section][name]; ok {
+						return nil, fmt.Errorf("%s %q is defined in both %s and %s", section, name, owner, overlay.name)
+					}
+					owners[section][name] = overlay.name
+					merged.SetMapIndex(key, src.MapIndex(key))
+				}
+			}
+			target.Set(merged)
+
+		case reflect.Pointer:
+			var owner string
+			for _, overlay := range overlays {
+				src := reflect.ValueOf(overlay.manifest).Elem().Field(i)
+				if src.IsNil() {
+					continue
+				}
+				if owner != "" {
+					return nil, fmt.Errorf("%s is defined in both %s and %s", section, owner, overlay.name)
+				}
+				owner = overlay.name
+				target.Set(src)
+			}
+		}
+	}
+
+	return &result, nil
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package manifestdata
+
+import (
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeManifests(t *testing.T) {
+	base := &manifest.Manifest{
+		Models: map[string]manifest.ModelInfo{
+			"chat":  {Name: "chat", SourceModel: "base-chat"},
+			"embed": {Name: "embed", SourceModel: "base-embed"},
+		},
+		Warmup: &manifest.WarmupInfo{},
+	}
+
+	overlays := []manifestFile{
+		{"modus.a.json", &manifest.Manifest{
+			Models: map[string]manifest.ModelInfo{
+				"chat": {Name: "chat", SourceModel: "team-a-chat"},
+			},
+			Collections: map[string]manifest.CollectionInfo{
+				"docs": {Name: "docs"},
+			},
+		}},
+		{"modus.b.json", &manifest.Manifest{
+			Prompts: map[string]manifest.PromptInfo{
+				"summarize": {Name: "summarize"},
+			},
+			Isolation: &manifest.IsolationInfo{Default: manifest.IsolationLevelProcess},
+		}},
+	}
+
+	m, err := mergeManifests(base, overlays)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "team-a-chat", m.Models["chat"].SourceModel)
+	assert.Equal(t, "base-embed", m.Models["embed"].SourceModel)
+	assert.Contains(t, m.Collections, "docs")
+	assert.Contains(t, m.Prompts, "summarize")
+	assert.Equal(t, base.Warmup, m.Warmup)
+	assert.Equal(t, manifest.IsolationLevelProcess, m.Isolation.Default)
+
+	// the base manifest is not modified
+	assert.Equal(t, "base-chat", base.Models["chat"].SourceModel)
+	assert.NotContains(t, base.Collections, "docs")
+}
+
+func TestMergeManifestsConflict(t *testing.T) {
+	base := &manifest.Manifest{}
+
+	overlays := []manifestFile{
+		{"modus.a.json", &manifest.Manifest{
+			Collections: map[string]manifest.CollectionInfo{"docs": {Name: "docs"}},
+		}},
+		{"modus.b.json", &manifest.Manifest{
+			Collections: map[string]manifest.CollectionInfo{"docs": {Name: "docs"}},
+		}},
+	}
+
+	_, err := mergeManifests(base, overlays)
+	assert.EqualError(t, err, `collections "docs" is defined in both modus.a.json and modus.b.json`)
+}
+
+func TestMergeManifestsSettingConflict(t *testing.T) {
+	base := &manifest.Manifest{}
+
+	overlays := []manifestFile{
+		{"modus.a.json", &manifest.Manifest{Warmup: &manifest.WarmupInfo{}}},
+		{"modus.b.json", &manifest.Manifest{Warmup: &manifest.WarmupInfo{}}},
+	}
+
+	_, err := mergeManifests(base, overlays)
+	assert.EqualError(t, err, "warmup is defined in both modus.a.json and modus.b.json")
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package metering records billable usage per tenant, when enabled in the manifest, and exports it
+// periodically to the configured sink.  Usage is aggregated in memory by tenant, function and unit,
+// so that each export holds one record for each combination that was used during the period.
+package metering
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/middleware"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// Units of usage.
+const (
+	UnitInvocations  = "invocations"
+	UnitWasmMs       = "wasm_ms"
+	UnitModelTokens  = "model_tokens"
+	UnitStorageBytes = "storage_bytes"
+)
+
+// AnonymousTenant is the tenant of callers whose token doesn't identify one.
+const AnonymousTenant = "anonymous"
+
+// UsageRecord is the usage of a unit by a tenant's calls to a function during a period.
+type UsageRecord struct {
+	Tenant      string    `json:"tenant"`
+	Function    string    `json:"function"`
+	Unit        string    `json:"unit"`
+	Quantity    float64   `json:"quantity"`
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+}
+
+type usageKey struct {
+	tenant   string
+	function string
+	unit     string
+}
+
+type meter struct {
+	mu          sync.Mutex
+	usage       map[usageKey]float64
+	periodStart time.Time
+	quit        chan struct{}
+	done        chan struct{}
+}
+
+var globalMeter = newMeter()
+
+func newMeter() *meter {
+	return &meter{
+		usage:       make(map[usageKey]float64),
+		periodStart: time.Now().UTC(),
+		quit:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+func Initialize(ctx context.Context) {
+	go globalMeter.worker(ctx)
+}
+
+// Shutdown stops the periodic export, and exports the usage recorded since the last one.
+func Shutdown(ctx context.Context) {
+	close(globalMeter.quit)
+	<-globalMeter.done
+}
+
+// Add records usage of a unit by the tenant of the caller, for the function being executed.
+// It does nothing unless metering is enabled in the manifest, or if the call is a shadow execution.
+// A sandboxed worker process doesn't record usage, as its calls are also recorded by the main process.
+func Add(ctx context.Context, unit string, quantity float64) {
+	info := manifestdata.GetManifest().Metering
+	if info == nil || quantity <= 0 || config.IsSandboxWorker || ctx.Value(utils.ShadowExecutionContextKey) != nil {
+		return
+	}
+
+	fnName, _ := ctx.Value(utils.FunctionNameContextKey).(string)
+	tenant := tenantFromClaims(middleware.GetJWTClaims(ctx), info.GetTenantClaim())
+	globalMeter.add(usageKey{tenant, fnName, unit}, quantity)
+}
+
+func (m *meter) add(key usageKey, quantity float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage[key] += quantity
+}
+
+// takeRecords returns the usage recorded since the previous call, and starts a new period.
+func (m *meter) takeRecords(now time.Time) []UsageRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	records := make([]UsageRecord, 0, len(m.usage))
+	for key, quantity := range m.usage {
+		records = append(records, UsageRecord{
+			Tenant:      key.tenant,
+			Function:    key.function,
+			Unit:        key.unit,
+			Quantity:    quantity,
+			PeriodStart: m.periodStart,
+			PeriodEnd:   now,
+		})
+	}
+
+	m.usage = make(map[usageKey]float64)
+	m.periodStart = now
+	return records
+}
+
+// restore returns records that could not be exported, so that they are included in the next export.
+// They are merged into the current period, as the sink never received the earlier one.
+func (m *meter) restore(records []UsageRecord) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, r := range records {
+		m.usage[usageKey{r.Tenant, r.Function, r.Unit}] += r.Quantity
+		if r.PeriodStart.Before(m.periodStart) {
+			m.periodStart = r.PeriodStart
+		}
+	}
+}
+
+func (m *meter) worker(ctx context.Context) {
+	defer close(m.done)
+
+	timer := time.NewTimer(exportInterval())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			m.export(ctx)
+			// the interval is read again, as the manifest may have changed
+			timer.Reset(exportInterval())
+		case <-m.quit:
+			m.export(ctx)
+			return
+		}
+	}
+}
+
+func exportInterval() time.Duration {
+	if info := manifestdata.GetManifest().Metering; info != nil {
+		return info.GetInterval()
+	}
+	return time.Minute
+}
+
+func (m *meter) export(ctx context.Context) {
+	info := manifestdata.GetManifest().Metering
+	if info == nil {
+		// metering was disabled, so any usage recorded before then is discarded
+		m.takeRecords(time.Now().UTC())
+		return
+	}
+
+	records := m.takeRecords(time.Now().UTC())
+	if len(records) == 0 {
+		return
+	}
+
+	if err := exportRecords(ctx, &info.Sink, records); err != nil {
+		logger.Warn(ctx).Err(err).
+			Str("sink", string(info.Sink.Type)).
+			Int("records", len(records)).
+			Msg("Failed to export usage records.  They will be included in the next export.")
+		m.restore(records)
+	}
+}
+
+// tenantFromClaims reads the tenant from the JWT claims, where the claim may be a string or a number.
+func tenantFromClaims(claimsJson, claim string) string {
+	if claimsJson == "" {
+		return AnonymousTenant
+	}
+
+	var claims map[string]any
+	if err := utils.JsonDeserialize([]byte(claimsJson), &claims); err != nil {
+		return AnonymousTenant
+	}
+
+	switch v := claims[claim].(type) {
+	case string:
+		if v != "" {
+			return v
+		}
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return AnonymousTenant
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package metering
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantFromClaims(t *testing.T) {
+	tests := []struct {
+		claims, claim, expected string
+	}{
+		{`{"sub":"user-1"}`, "sub", "user-1"},
+		{`{"sub":"user-1","org":"acme"}`, "org", "acme"},
+		{`{"account":12345}`, "account", "12345"},
+		{`{"sub":""}`, "sub", AnonymousTenant},
+		{`{"org":"acme"}`, "sub", AnonymousTenant},
+		{"", "sub", AnonymousTenant},
+		{"not json", "sub", AnonymousTenant},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tenantFromClaims(tt.claims, tt.claim), tt.claims)
+	}
+}
+
+func TestAddAggregatesUsage(t *testing.T) {
+	original := manifestdata.GetManifest()
+	manifestdata.SetManifest(&manifest.Manifest{Metering: &manifest.MeteringInfo{}})
+	t.Cleanup(func() { manifestdata.SetManifest(original) })
+
+	m := newMeter()
+	globalMeter, m = m, globalMeter
+	t.Cleanup(func() { globalMeter = m })
+
+	ctx := context.WithValue(context.Background(), utils.FunctionNameContextKey, "sayHello")
+	Add(ctx, UnitInvocations, 1)
+	Add(ctx, UnitInvocations, 1)
+	Add(ctx, UnitWasmMs, 12.5)
+
+	shadowCtx := context.WithValue(ctx, utils.ShadowExecutionContextKey, true)
+	Add(shadowCtx, UnitInvocations, 1)
+
+	records := globalMeter.takeRecords(time.Now().UTC())
+	quantities := make(map[string]float64, len(records))
+	for _, r := range records {
+		assert.Equal(t, AnonymousTenant, r.Tenant)
+		assert.Equal(t, "sayHello", r.Function)
+		quantities[r.Unit] = r.Quantity
+	}
+	assert.Equal(t, map[string]float64{UnitInvocations: 2, UnitWasmMs: 12.5}, quantities)
+
+	assert.Empty(t, globalMeter.takeRecords(time.Now().UTC()))
+}
+
+func TestRestoreMergesRecords(t *testing.T) {
+	m := newMeter()
+	key := usageKey{"acme", "sayHello", UnitInvocations}
+	m.add(key, 2)
+
+	start := m.periodStart
+	records := m.takeRecords(start.Add(time.Minute))
+	m.add(key, 3)
+	m.restore(records)
+
+	assert.Equal(t, 5.0, m.usage[key])
+	assert.Equal(t, start, m.periodStart)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	sink := &manifest.MeteringSinkInfo{Type: manifest.MeteringSinkTypeFile, Path: path}
+
+	now := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	records := []UsageRecord{
+		{Tenant: "acme", Function: "sayHello", Unit: UnitInvocations, Quantity: 2, PeriodStart: now, PeriodEnd: now.Add(time.Minute)},
+		{Tenant: "acme", Function: "sayHello", Unit: UnitWasmMs, Quantity: 12.5, PeriodStart: now, PeriodEnd: now.Add(time.Minute)},
+	}
+	require.NoError(t, exportRecords(context.Background(), sink, records[:1]))
+	require.NoError(t, exportRecords(context.Background(), sink, records[1:]))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+	for i, line := range lines {
+		var r UsageRecord
+		require.NoError(t, utils.JsonDeserialize([]byte(line), &r))
+		assert.Equal(t, records[i], r)
+	}
+}
+
+func TestKafkaRecords(t *testing.T) {
+	records := []UsageRecord{{Tenant: "acme", Function: "sayHello", Unit: UnitInvocations, Quantity: 1}}
+	body := kafkaRecords(records)
+	assert.Equal(t, map[string][]kafkaRecord{"records": {{Key: "acme", Value: records[0]}}}, body)
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package metering
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/httpclient"
+	"github.com/hypermodeinc/modus/runtime/secrets"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+func exportRecords(ctx context.Context, sink *manifest.MeteringSinkInfo, records []UsageRecord) error {
+	switch sink.Type {
+	case manifest.MeteringSinkTypeFile:
+		return writeFile(sink.Path, records)
+	case manifest.MeteringSinkTypeHttp:
+		return postRecords(ctx, sink.Connection, "", "", records)
+	case manifest.MeteringSinkTypeKafka:
+		return postRecords(ctx, sink.Connection, "/topics/"+sink.Topic, "application/vnd.kafka.json.v2+json", kafkaRecords(records))
+	default:
+		return fmt.Errorf("unknown metering sink type %s", sink.Type)
+	}
+}
+
+// writeFile appends the records to the file, one JSON object per line.
+func writeFile(path string, records []UsageRecord) error {
+	var buf bytes.Buffer
+	for _, r := range records {
+		line, err := utils.JsonSerialize(r)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+type kafkaRecord struct {
+	Key   string      `json:"key"`
+	Value UsageRecord `json:"value"`
+}
+
+// kafkaRecords returns the body of a Kafka REST proxy produce request.  Records are keyed by tenant,
+// so that the usage of each tenant is kept in order within a partition.
+func kafkaRecords(records []UsageRecord) map[string][]kafkaRecord {
+	kr := make([]kafkaRecord, len(records))
+	for i, r := range records {
+		kr[i] = kafkaRecord{Key: r.Tenant, Value: r}
+	}
+	return map[string][]kafkaRecord{"records": kr}
+}
+
+// postRecords posts the payload to the connection.  When a path is given, it is appended to the base URL
+// of the connection, and otherwise the payload is posted to the endpoint of the connection.
+func postRecords(ctx context.Context, connection, path, contentType string, payload any) error {
+	connInfo, err := httpclient.GetHttpConnectionInfo(connection)
+	if err != nil {
+		return err
+	}
+
+	var url string
+	if path == "" {
+		url = connInfo.Endpoint
+	} else {
+		url = strings.TrimSuffix(connInfo.BaseURL, "/") + path
+	}
+	if url == "" || url == path {
+		return fmt.Errorf("connection %s has no URL for the metering sink", connection)
+	}
+
+	ctx, err = httpclient.ContextWithConnectionClient(ctx, connInfo)
+	if err != nil {
+		return err
+	}
+
+	bs := func(ctx context.Context, req *http.Request) error {
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		return secrets.ApplySecretsToHttpRequest(ctx, connInfo, req)
+	}
+
+	_, err = utils.PostHttp[[]byte](ctx, url, payload, bs)
+	return err
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Custom metrics are recorded by plugins, and exposed with the runtime metrics.
+// Each is named plugin_{name}, and has a plugin label in addition to the tags given when it is recorded.
+// The tag names of a metric are fixed by its first recording.  Limits on the number of metrics, tags and
+// distinct tag values protect the runtime from unbounded memory use by a misbehaving plugin.
+
+// Kinds of custom metrics.
+const (
+	CustomMetricCounter   = "counter"
+	CustomMetricGauge     = "gauge"
+	CustomMetricHistogram = "histogram"
+)
+
+const (
+	customMetricPrefix         = "plugin_"
+	maxCustomMetrics           = 200
+	maxCustomMetricTags        = 8
+	maxCustomMetricSeries      = 1000
+	maxCustomMetricNameLength  = 100
+	maxCustomMetricValueLength = 128
+)
+
+type customMetric struct {
+	kind      string
+	tagNames  []string
+	collector prometheus.Collector
+	series    map[string]struct{}
+}
+
+var customMetrics = make(map[string]*customMetric)
+var customMetricsMu sync.Mutex
+
+// RecordCustomMetric records a value for a custom metric.  A counter is incremented by the value,
+// a gauge is set to the value, and a histogram observes the value.
+func RecordCustomMetric(pluginName, name, kind string, value float64, tags map[string]string) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("the value of metric %s must be a finite number", name)
+	}
+	if kind == CustomMetricCounter && value < 0 {
+		return fmt.Errorf("counter %s cannot be decreased", name)
+	}
+
+	metricName, err := sanitizeMetricName(name)
+	if err != nil {
+		return err
+	}
+	if len(tags) > maxCustomMetricTags {
+		return fmt.Errorf("metric %s has %d tags, but at most %d are allowed", name, len(tags), maxCustomMetricTags)
+	}
+
+	labels := prometheus.Labels{"plugin": sanitizeLabelValue(pluginName)}
+	for k, v := range tags {
+		labelName := sanitizeLabelName(k)
+		if labelName == "plugin" {
+			return fmt.Errorf("tag name %s is reserved", k)
+		}
+		if _, ok := labels[labelName]; ok {
+			return fmt.Errorf("tag name %s is the same as another tag of metric %s, after replacing characters that aren't allowed", k, name)
+		}
+		labels[labelName] = sanitizeLabelValue(v)
+	}
+	tagNames := make([]string, 0, len(labels))
+	for k := range labels {
+		tagNames = append(tagNames, k)
+	}
+	slices.Sort(tagNames)
+
+	customMetricsMu.Lock()
+	defer customMetricsMu.Unlock()
+
+	m, err := getCustomMetric(metricName, kind, tagNames)
+	if err != nil {
+		return err
+	}
+
+	var series strings.Builder
+	for _, k := range tagNames {
+		series.WriteString(labels[k])
+		series.WriteByte(0)
+	}
+	if _, ok := m.series[series.String()]; !ok {
+		if len(m.series) >= maxCustomMetricSeries {
+			return fmt.Errorf("metric %s has reached the limit of %d distinct tag values", name, maxCustomMetricSeries)
+		}
+		m.series[series.String()] = struct{}{}
+	}
+
+	switch c := m.collector.(type) {
+	case *prometheus.CounterVec:
+		c.With(labels).Add(value)
+	case *prometheus.GaugeVec:
+		c.With(labels).Set(value)
+	case *prometheus.HistogramVec:
+		c.With(labels).Observe(value)
+	}
+	return nil
+}
+
+// getCustomMetric must be called with customMetricsMu held.
+func getCustomMetric(name, kind string, tagNames []string) (*customMetric, error) {
+	if m, ok := customMetrics[name]; ok {
+		if m.kind != kind {
+			return nil, fmt.Errorf("metric %s is a %s, not a %s", name, m.kind, kind)
+		}
+		if !slices.Equal(m.tagNames, tagNames) {
+			return nil, fmt.Errorf("metric %s must always be recorded with the tags %s", name, strings.Join(m.tagNames, ", "))
+		}
+		return m, nil
+	}
+
+	if len(customMetrics) >= maxCustomMetrics {
+		return nil, fmt.Errorf("the limit of %d custom metrics has been reached", maxCustomMetrics)
+	}
+
+	help := "Custom metric recorded by plugins"
+	var collector prometheus.Collector
+	switch kind {
+	case CustomMetricCounter:
+		collector = prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, tagNames)
+	case CustomMetricGauge:
+		collector = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, tagNames)
+	case CustomMetricHistogram:
+		collector = prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: prometheus.DefBuckets}, tagNames)
+	default:
+		return nil, fmt.Errorf("unknown metric kind %s, must be one of counter, gauge or histogram", kind)
+	}
+
+	if err := runtimePromRegistry.Register(collector); err != nil {
+		return nil, fmt.Errorf("failed to register metric %s: %w", name, err)
+	}
+
+	m := &customMetric{kind: kind, tagNames: tagNames, collector: collector, series: make(map[string]struct{})}
+	customMetrics[name] = m
+	return m, nil
+}
+
+func sanitizeMetricName(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("a metric name is required")
+	}
+	if len(name) > maxCustomMetricNameLength {
+		return "", fmt.Errorf("metric name %s is too long, must be at most %d characters", name, maxCustomMetricNameLength)
+	}
+	return customMetricPrefix + sanitizeName(name), nil
+}
+
+func sanitizeLabelName(name string) string {
+	s := sanitizeName(name)
+	if s == "" || s[0] >= '0' && s[0] <= '9' {
+		s = "_" + s
+	}
+	// names beginning with two underscores are reserved by Prometheus
+	for strings.HasPrefix(s, "__") {
+		s = s[1:]
+	}
+	return s
+}
+
+// sanitizeName replaces characters that aren't allowed in metric and label names with underscores.
+func sanitizeName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+func sanitizeLabelValue(value string) string {
+	value = strings.ToValidUTF8(value, "�")
+	if utf8.RuneCountInString(value) > maxCustomMetricValueLength {
+		value = string([]rune(value)[:maxCustomMetricValueLength])
+	}
+	return value
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package metrics
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordCustomMetric(t *testing.T) {
+	tags := map[string]string{"model-name": "gpt", "2fa": "yes"}
+
+	for range 3 {
+		if err := RecordCustomMetric("my-plugin", "documents.indexed", CustomMetricCounter, 2, tags); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := RecordCustomMetric("my-plugin", "queue depth", CustomMetricGauge, 7, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	counter := customMetrics["plugin_documents_indexed"]
+	if counter == nil {
+		t.Fatal("expected the counter to be registered with a sanitized name")
+	}
+	expectedTags := []string{"_2fa", "model_name", "plugin"}
+	if !slices.Equal(counter.tagNames, expectedTags) {
+		t.Fatalf("expected tags %v, got %v", expectedTags, counter.tagNames)
+	}
+	if v := testutil.ToFloat64(counter.collector); v != 6 {
+		t.Fatalf("expected the counter to be 6, got %v", v)
+	}
+	if v := testutil.ToFloat64(customMetrics["plugin_queue_depth"].collector); v != 7 {
+		t.Fatalf("expected the gauge to be 7, got %v", v)
+	}
+}
+
+func TestRecordCustomMetricErrors(t *testing.T) {
+	if err := RecordCustomMetric("p", "errors_a", CustomMetricCounter, -1, nil); err == nil {
+		t.Error("expected an error for decreasing a counter")
+	}
+	if err := RecordCustomMetric("p", "errors_b", "summary", 1, nil); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+	if err := RecordCustomMetric("p", "errors_c", CustomMetricGauge, 1, map[string]string{"plugin": "x"}); err == nil {
+		t.Error("expected an error for a reserved tag name")
+	}
+
+	if err := RecordCustomMetric("p", "errors_d", CustomMetricGauge, 1, map[string]string{"a": "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordCustomMetric("p", "errors_d", CustomMetricHistogram, 1, map[string]string{"a": "1"}); err == nil {
+		t.Error("expected an error for changing the kind of a metric")
+	}
+	if err := RecordCustomMetric("p", "errors_d", CustomMetricGauge, 1, map[string]string{"b": "1"}); err == nil {
+		t.Error("expected an error for changing the tags of a metric")
+	}
+}
+
+func TestRecordCustomMetricSeriesLimit(t *testing.T) {
+	for i := range maxCustomMetricSeries {
+		if err := RecordCustomMetric("p", "limited", CustomMetricHistogram, 1, map[string]string{"id": strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := RecordCustomMetric("p", "limited", CustomMetricHistogram, 1, map[string]string{"id": "one more"}); err == nil {
+		t.Error("expected an error when the series limit is reached")
+	}
+	if err := RecordCustomMetric("p", "limited", CustomMetricHistogram, 1, map[string]string{"id": "0"}); err != nil {
+		t.Errorf("expected an existing series to be recorded, got %v", err)
+	}
+}
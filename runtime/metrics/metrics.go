@@ -105,6 +105,269 @@ var (
 			Help: "Number of dropped inference requests",
 		},
 	)
+
+	// GuardrailViolationsNum is a counter for model content flagged by guardrails.
+	// # of series = # of guardrails x 2
+	GuardrailViolationsNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runtime_guardrail_violations_num",
+			Help: "Number of model inputs and outputs flagged by guardrails",
+		},
+		[]string{"guardrail", "policy"},
+	)
+
+	// EmbeddingCacheHitsNum and EmbeddingCacheMissesNum count lookups in the collections embedding cache.
+	// # of series = 1 each
+	EmbeddingCacheHitsNum = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "runtime_embedding_cache_hits_num",
+			Help: "Number of texts whose embeddings were served from the cache",
+		},
+	)
+	EmbeddingCacheMissesNum = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "runtime_embedding_cache_misses_num",
+			Help: "Number of texts whose embeddings were not found in the cache",
+		},
+	)
+
+	// EmbeddingDriftDistance is a gauge of the mean cosine distance between stored vectors
+	// and freshly computed embeddings of the same texts, as of the most recent drift check.
+	// # of series = # of collections x # of search methods
+	EmbeddingDriftDistance = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "runtime_embedding_drift_distance",
+			Help: "Mean cosine distance between stored vectors and re-computed embeddings of sampled items",
+		},
+		[]string{"collection", "search_method"},
+	)
+
+	// ShadowExecutionsNum is a counter of function calls duplicated to shadow plugin builds, by how the results compared.
+	// # of series = # of functions x 5
+	ShadowExecutionsNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runtime_shadow_executions_num",
+			Help: "Number of function calls duplicated to shadow plugin builds",
+		},
+		[]string{"function_name", "outcome"},
+	)
+	// ShadowDurationDeltaMilliseconds is a histogram of how much longer shadow function calls took than the live calls.
+	// # of series = # of functions x 11
+	ShadowDurationDeltaMilliseconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "runtime_shadow_duration_delta_milliseconds",
+			Help:    "A histogram of the difference in latency between shadow and live function calls",
+			Buckets: []float64{-1000, -250, -100, -25, -5, 0, 5, 25, 100, 250, 1000},
+		},
+		[]string{"function_name"},
+	)
+
+	// HttpClientRequestsNum is a counter of outbound HTTP requests made by functions, by host and response status code.
+	// # of series = # of hosts x # of HTTP response codes
+	HttpClientRequestsNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runtime_http_client_requests_num",
+			Help: "Number of HTTP requests made by functions",
+		},
+		[]string{"host", "code"},
+	)
+	// HttpClientRequestDurationSeconds is a histogram of latencies for outbound HTTP requests made by functions.
+	// # of series = # of hosts x 8
+	HttpClientRequestDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "runtime_http_client_request_duration_seconds",
+			Help:    "A histogram of latencies for HTTP requests made by functions",
+			Buckets: []float64{.01, .05, .1, .25, .5, 1, 5, 10},
+		},
+		[]string{"host"},
+	)
+	// HttpClientConnectionsNum is a counter of connections used for outbound HTTP requests, by whether they were reused from the pool.
+	// # of series = # of hosts x 2
+	HttpClientConnectionsNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runtime_http_client_connections_num",
+			Help: "Number of connections used for HTTP requests made by functions",
+		},
+		[]string{"host", "reused"},
+	)
+
+	// FunctionMemoryPeakBytes is a histogram of the peak size of the linear memory of wasm function executions.
+	// # of series = # of functions x 13
+	FunctionMemoryPeakBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "runtime_function_memory_peak_bytes",
+			Help:    "A histogram of the peak size of the linear memory of wasm function executions",
+			Buckets: prometheus.ExponentialBuckets(1<<20, 2, 13),
+		},
+		[]string{"function_name"},
+	)
+
+	// FunctionMemoryGrowthBytes is a histogram of how much the linear memory of wasm function executions grew.
+	// # of series = # of functions x 10
+	FunctionMemoryGrowthBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "runtime_function_memory_growth_bytes",
+			Help:    "A histogram of how much the linear memory of wasm function executions grew",
+			Buckets: append([]float64{0}, prometheus.ExponentialBuckets(64<<10, 4, 9)...),
+		},
+		[]string{"function_name"},
+	)
+
+	// FunctionOutputDiscardedBytesNum is a counter of stdout and stderr bytes discarded because a function invocation exceeded its output limit.
+	// # of series = # of functions x 2
+	FunctionOutputDiscardedBytesNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runtime_function_output_discarded_bytes_num",
+			Help: "Number of bytes of function output discarded because the output limit was exceeded",
+		},
+		[]string{"function_name", "stream"},
+	)
+
+	// WasmInstanceSlotsNum is a gauge of the maximum number of concurrent module instances, or 0 if unlimited.
+	// # of series = 1
+	WasmInstanceSlotsNum = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "runtime_wasm_instance_slots_num",
+			Help: "Maximum number of concurrent plugin module instances, or 0 if unlimited",
+		},
+	)
+	// WasmInstancesInUseNum is a gauge of the module instances currently in use, by plugin.
+	// # of series = # of plugins
+	WasmInstancesInUseNum = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "runtime_wasm_instances_in_use_num",
+			Help: "Number of plugin module instances currently in use",
+		},
+		[]string{"plugin"},
+	)
+	// WasmInstancesWaitingNum is a gauge of the function calls waiting for a module instance slot, by plugin.
+	// # of series = # of plugins
+	WasmInstancesWaitingNum = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "runtime_wasm_instances_waiting_num",
+			Help: "Number of function calls waiting for a plugin module instance slot",
+		},
+		[]string{"plugin"},
+	)
+	// WasmInstanceWaitSeconds is a histogram of how long function calls waited for a module instance slot, by plugin.
+	// # of series = # of plugins x 8
+	WasmInstanceWaitSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "runtime_wasm_instance_wait_seconds",
+			Help:    "A histogram of the time function calls waited for a plugin module instance slot",
+			Buckets: []float64{.001, .005, .01, .05, .1, .5, 1, 5},
+		},
+		[]string{"plugin"},
+	)
+
+	// LocalModelFirstTokenSeconds is a histogram of the time until the first token was received
+	// from a local inference server that streams its responses, by model.
+	// # of series = # of local models x 8
+	LocalModelFirstTokenSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "runtime_local_model_first_token_seconds",
+			Help:    "A histogram of the time until the first token was received from a local inference server",
+			Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5},
+		},
+		[]string{"model"},
+	)
+
+	// ModelFallbacksNum is a counter of model invocations served by a fallback endpoint, by model and connection.
+	// # of series = # of model fallbacks
+	ModelFallbacksNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runtime_model_fallbacks_num",
+			Help: "Number of model invocations served by a fallback endpoint",
+		},
+		[]string{"model", "connection"},
+	)
+
+	// ModelHedgesNum is a counter of hedged model invocations, by model and outcome.  The outcome is the endpoint
+	// that responded first, either "primary" or "hedge", or "failed" if neither did, or "budget_exceeded" if the
+	// hedged request was not sent because too many invocations were already hedged.
+	// # of series = # of hedged models * 4
+	ModelHedgesNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runtime_model_hedges_num",
+			Help: "Number of hedged model invocations, by outcome",
+		},
+		[]string{"model", "outcome"},
+	)
+
+	// ExperimentAssignmentsNum is a counter of calls assigned to each experiment variant.
+	// # of series = # of experiment variants
+	ExperimentAssignmentsNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runtime_experiment_assignments_num",
+			Help: "Number of calls assigned to each experiment variant",
+		},
+		[]string{"experiment", "variant"},
+	)
+	// ExperimentLatencySeconds is a histogram of the latency of model invocations, by experiment variant.
+	// # of series = # of experiment variants x 10
+	ExperimentLatencySeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "runtime_experiment_latency_seconds",
+			Help:    "A histogram of the latency of model invocations, by experiment variant",
+			Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"experiment", "variant"},
+	)
+	// ExperimentTokensNum is a counter of the tokens reported by model invocations, by experiment variant and token type.
+	// # of series = # of experiment variants x 2
+	ExperimentTokensNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runtime_experiment_tokens_num",
+			Help: "Number of tokens used by model invocations, by experiment variant",
+		},
+		[]string{"experiment", "variant", "type"},
+	)
+	// ExperimentErrorsNum is a counter of failed model invocations, by experiment variant.
+	// # of series = # of experiment variants
+	ExperimentErrorsNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runtime_experiment_errors_num",
+			Help: "Number of failed model invocations, by experiment variant",
+		},
+		[]string{"experiment", "variant"},
+	)
+	// ExperimentFeedbackScore is a histogram of the feedback scores recorded by functions, by experiment variant.
+	// # of series = # of experiment variants x 10
+	ExperimentFeedbackScore = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "runtime_experiment_feedback_score",
+			Help:    "A histogram of the feedback scores recorded for each experiment variant",
+			Buckets: prometheus.LinearBuckets(0.1, 0.1, 10),
+		},
+		[]string{"experiment", "variant"},
+	)
+	// FeedbackRatingsNum is a counter of user feedback recorded against executions, by source and rating.
+	// # of series = 2 x # of distinct ratings
+	FeedbackRatingsNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runtime_feedback_ratings_total",
+			Help: "Number of feedback ratings recorded against executions, by source and rating",
+		},
+		[]string{"source", "rating"},
+	)
+	// OutboxMessagesNum is a counter of outbox messages, by outcome.
+	// # of series = 5
+	OutboxMessagesNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runtime_outbox_messages_total",
+			Help: "Number of outbox messages staged, discarded, delivered, retried or failed",
+		},
+		[]string{"outcome"},
+	)
+	// CollectionPIIDetectionsNum is a counter of texts in which personal information was detected on upsert.
+	// # of series = # of collections x # of personal information types
+	CollectionPIIDetectionsNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runtime_collection_pii_detections_total",
+			Help: "Number of texts upserted into collections in which personal information was detected, by type and policy",
+		},
+		[]string{"collection", "type", "policy"},
+	)
 )
 
 func init() {
@@ -117,6 +380,33 @@ func init() {
 		FunctionExecutionDurationMilliseconds,
 		FunctionExecutionDurationMillisecondsSummary,
 		DroppedInferencesNum,
+		GuardrailViolationsNum,
+		EmbeddingCacheHitsNum,
+		EmbeddingCacheMissesNum,
+		EmbeddingDriftDistance,
+		ShadowExecutionsNum,
+		ShadowDurationDeltaMilliseconds,
+		HttpClientRequestsNum,
+		HttpClientRequestDurationSeconds,
+		HttpClientConnectionsNum,
+		FunctionOutputDiscardedBytesNum,
+		FunctionMemoryPeakBytes,
+		FunctionMemoryGrowthBytes,
+		WasmInstanceSlotsNum,
+		WasmInstancesInUseNum,
+		WasmInstancesWaitingNum,
+		WasmInstanceWaitSeconds,
+		LocalModelFirstTokenSeconds,
+		ModelFallbacksNum,
+		ModelHedgesNum,
+		ExperimentAssignmentsNum,
+		ExperimentLatencySeconds,
+		ExperimentTokensNum,
+		ExperimentErrorsNum,
+		ExperimentFeedbackScore,
+		FeedbackRatingsNum,
+		OutboxMessagesNum,
+		CollectionPIIDetectionsNum,
 	)
 }
 
@@ -0,0 +1,238 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// The access log records every HTTP request as a line of JSON.  GraphQL requests are recorded with a fingerprint
+// of their operation, rather than the query itself, so that traffic can be analyzed without logging queries.
+// Records are written in the background, and are dropped rather than delaying requests if the writer falls behind.
+
+const accessLogBufferSize = 4096
+
+// AccessLogRecord is a line of the access log.
+type AccessLogRecord struct {
+	Time          time.Time `json:"time"`
+	Method        string    `json:"method"`
+	Path          string    `json:"path"`
+	Status        int       `json:"status"`
+	DurationMs    float64   `json:"durationMs"`
+	BytesIn       int64     `json:"bytesIn"`
+	BytesOut      int64     `json:"bytesOut"`
+	RemoteAddr    string    `json:"remoteAddr"`
+	RequestId     string    `json:"requestId,omitempty"`
+	Caller        string    `json:"caller,omitempty"`
+	Operation     string    `json:"operation,omitempty"`
+	OperationType string    `json:"operationType,omitempty"`
+	Fingerprint   string    `json:"fingerprint,omitempty"`
+}
+
+type accessLogKey struct{}
+
+type accessLogWriter struct {
+	mu      sync.RWMutex
+	closed  bool
+	records chan *AccessLogRecord
+	done    chan struct{}
+	dropped atomic.Int64
+}
+
+var globalAccessLog *accessLogWriter
+
+// GetAccessLogRecord returns the access log record of the request being handled, so that handlers can add
+// details to it.  It returns nil if the access log is disabled.
+func GetAccessLogRecord(ctx context.Context) *AccessLogRecord {
+	if rec, ok := ctx.Value(accessLogKey{}).(*AccessLogRecord); ok {
+		return rec
+	}
+	return nil
+}
+
+// HandleAccessLog records each request in the access log, if it is enabled.
+func HandleAccessLog(next http.Handler) http.Handler {
+	if config.AccessLog == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &AccessLogRecord{
+			Time:       start.UTC(),
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			RemoteAddr: r.RemoteAddr,
+			RequestId:  r.Header.Get("X-Request-Id"),
+		}
+
+		body := &countingReader{ReadCloser: r.Body}
+		r.Body = body
+		rw := &accessLogResponseWriter{ResponseWriter: w, status: http.StatusOK}
+
+		ctx := context.WithValue(r.Context(), accessLogKey{}, rec)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		rec.Status = rw.status
+		rec.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+		rec.BytesIn = body.n
+		rec.BytesOut = rw.n
+		globalAccessLog.write(rec)
+	})
+}
+
+// setAccessLogCaller records the caller identified by the verified claims of a request's token.
+func setAccessLogCaller(ctx context.Context, claims map[string]any) {
+	if rec := GetAccessLogRecord(ctx); rec != nil {
+		if sub, ok := claims["sub"].(string); ok {
+			rec.Caller = sub
+		}
+	}
+}
+
+func initAccessLog(ctx context.Context) {
+	if config.AccessLog == "" {
+		return
+	}
+
+	var out io.WriteCloser = os.Stdout
+	if config.AccessLog != "-" {
+		f, err := os.OpenFile(config.AccessLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			logger.Error(ctx).Err(err).Str("path", config.AccessLog).Msg("Failed to open the access log.  Requests will not be logged.")
+			return
+		}
+		out = f
+	}
+
+	w := &accessLogWriter{
+		records: make(chan *AccessLogRecord, accessLogBufferSize),
+		done:    make(chan struct{}),
+	}
+	go w.run(ctx, out)
+	globalAccessLog = w
+}
+
+func shutdownAccessLog(ctx context.Context) {
+	w := globalAccessLog
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	w.closed = true
+	close(w.records)
+	w.mu.Unlock()
+	<-w.done
+
+	if n := w.dropped.Load(); n > 0 {
+		logger.Warn(ctx).Int64("dropped", n).Msg("Some requests were not recorded in the access log, because it could not be written fast enough.")
+	}
+}
+
+func (w *accessLogWriter) write(rec *AccessLogRecord) {
+	if w == nil {
+		return
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return
+	}
+
+	select {
+	case w.records <- rec:
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+func (w *accessLogWriter) run(ctx context.Context, out io.WriteCloser) {
+	defer close(w.done)
+
+	buf := bufio.NewWriter(out)
+	for rec := range w.records {
+		line, err := utils.JsonSerialize(rec)
+		if err != nil {
+			logger.Error(ctx).Err(err).Msg("Failed to serialize access log record.")
+			continue
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+
+		// flush once there are no more records waiting, so that lines are written promptly but in batches
+		if len(w.records) == 0 {
+			if err := buf.Flush(); err != nil {
+				logger.Error(ctx).Err(err).Msg("Failed to write to the access log.")
+			}
+		}
+	}
+
+	_ = buf.Flush()
+	if out != os.Stdout {
+		_ = out.Close()
+	}
+}
+
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+type accessLogResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	n           int64
+}
+
+func (w *accessLogResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessLogResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.n += int64(n)
+	return n, err
+}
+
+// Flush supports streamed responses, such as server-sent events.
+func (w *accessLogResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *accessLogResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
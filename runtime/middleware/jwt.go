@@ -37,6 +37,7 @@ func Init(ctx context.Context) {
 	go globalAuthKeys.worker(ctx)
 	envfiles.RegisterEnvFilesLoadedCallback(initKeys)
 	initKeys(ctx)
+	initAccessLog(ctx)
 }
 
 func initKeys(ctx context.Context) {
@@ -73,6 +74,7 @@ func initKeys(ctx context.Context) {
 func Shutdown() {
 	close(globalAuthKeys.quit)
 	<-globalAuthKeys.done
+	shutdownAccessLog(context.Background())
 }
 
 func HandleJWT(next http.Handler) http.Handler {
@@ -163,6 +165,7 @@ func HandleJWT(next http.Handler) http.Handler {
 }
 
 func addClaimsToContext(ctx context.Context, claims jwt.MapClaims) context.Context {
+	setAccessLogCaller(ctx, claims)
 	claimsJson, err := utils.JsonSerialize(claims)
 	if err != nil {
 		logger.Error(ctx).Err(err).Msg("JWT claims serialization error")
@@ -171,6 +174,11 @@ func addClaimsToContext(ctx context.Context, claims jwt.MapClaims) context.Conte
 	return context.WithValue(ctx, jwtClaims, string(claimsJson))
 }
 
+// ContextWithJWTClaims returns a context carrying JWT claims that have already been verified and serialized.
+func ContextWithJWTClaims(ctx context.Context, claims string) context.Context {
+	return context.WithValue(ctx, jwtClaims, claims)
+}
+
 func GetJWTClaims(ctx context.Context) string {
 	if claims, ok := ctx.Value(jwtClaims).(string); ok {
 		return claims
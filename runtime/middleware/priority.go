@@ -0,0 +1,51 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// priorityClaim is the JWT claim that sets the highest priority class the caller may use.
+const priorityClaim = "priority"
+
+// RequestPriority returns the priority class of the request.  The X-Modus-Priority header selects a class,
+// but a caller can only raise its priority as far as the priority claim of its token allows.  Without the
+// claim, callers are limited to normal priority, unless authentication is disabled.
+func RequestPriority(r *http.Request) utils.Priority {
+	ceiling := priorityCeiling(GetJWTClaims(r.Context()))
+
+	requested, ok := utils.ParsePriority(r.Header.Get(utils.PriorityHeader))
+	if !ok {
+		// without a valid header, the caller gets the priority its token grants, up to normal
+		return min(ceiling, utils.PriorityNormal)
+	}
+	return min(requested, ceiling)
+}
+
+func priorityCeiling(claimsJson string) utils.Priority {
+	if claimsJson != "" {
+		var claims map[string]any
+		if utils.JsonDeserialize([]byte(claimsJson), &claims) == nil {
+			if s, ok := claims[priorityClaim].(string); ok {
+				if p, ok := utils.ParsePriority(s); ok {
+					return p
+				}
+			}
+		}
+	}
+
+	if globalAuthKeys == nil || (len(globalAuthKeys.getPemPublicKeys()) == 0 && len(globalAuthKeys.getJwksPublicKeys()) == 0) {
+		return utils.PriorityHigh
+	}
+	return utils.PriorityNormal
+}
@@ -0,0 +1,25 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// HandleTraceContext propagates W3C trace context headers from the incoming request
+// into the request context, so they can be forwarded on outbound requests.
+func HandleTraceContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := utils.ContextWithTraceContext(r.Context(), r)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
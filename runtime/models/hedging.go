@@ -0,0 +1,146 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package models
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/metrics"
+)
+
+// A hedged invocation is sent to the next endpoint of a model when the first hasn't responded within the
+// hedging delay.  Whichever responds first is used, and the other request is canceled.  This trades extra
+// requests for lower tail latency, so each model has a budget that limits the share of invocations hedged.
+
+// hedgeBudgetBurst is the number of hedged requests that a model can save up budget for.
+const hedgeBudgetBurst = 10
+
+type routeResult struct {
+	route  *manifest.ModelInfo
+	output string
+	err    error
+}
+
+// hedgeBudget is a token bucket, to which each invocation adds a fraction of a hedged request.
+// The tokens are counted in hundredths of a percent of a request, so that they add up exactly.
+type hedgeBudget struct {
+	mu     sync.Mutex
+	tokens int64
+}
+
+// hedgeCost is the number of tokens that a hedged request costs.
+const hedgeCost = 100 * 100
+
+// hedgeBudgets holds the budget of each hedged model, keyed by model name.
+var hedgeBudgets sync.Map
+
+func getHedgeBudget(modelName string) *hedgeBudget {
+	b, _ := hedgeBudgets.LoadOrStore(modelName, &hedgeBudget{})
+	return b.(*hedgeBudget)
+}
+
+// deposit adds the share of a hedged request that an invocation earns.
+func (b *hedgeBudget) deposit(percent float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+int64(math.Round(percent*100)), hedgeBudgetBurst*hedgeCost)
+}
+
+// withdraw spends the budget for a hedged request, or returns false if there isn't enough.
+func (b *hedgeBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < hedgeCost {
+		return false
+	}
+	b.tokens -= hedgeCost
+	return true
+}
+
+// canHedge reports whether the invocation of the model can be hedged across the routes.  Streamed responses
+// are never hedged, because their output is passed to the caller before it is known which request wins.
+func canHedge(model *manifest.ModelInfo, routes []*manifest.ModelInfo) bool {
+	if model.Hedge == nil || model.Hedge.Delay <= 0 || len(routes) < 2 {
+		return false
+	}
+	for _, route := range routes[:2] {
+		if route.Local != nil && route.Local.Stream {
+			return false
+		}
+	}
+	return true
+}
+
+// invokeHedged invokes the primary route, and if it hasn't responded within the hedging delay, also the secondary
+// route.  It returns the result of the first request to succeed, or otherwise the failed result of each request sent.
+func invokeHedged(ctx context.Context, model, primary, secondary *manifest.ModelInfo, input string) []routeResult {
+	budget := getHedgeBudget(model.Name)
+	budget.deposit(model.Hedge.GetMaxPercent())
+
+	// the request that loses is canceled when this returns
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan routeResult, 2)
+	send := func(route *manifest.ModelInfo) {
+		go func() {
+			output, err := invokeRoute(ctx, model, route, input)
+			results <- routeResult{route, output, err}
+		}()
+	}
+
+	send(primary)
+	pending := 1
+	hedged := false
+
+	timer := time.NewTimer(time.Duration(model.Hedge.Delay) * time.Millisecond)
+	defer timer.Stop()
+
+	var failed []routeResult
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if !budget.withdraw() {
+				metrics.ModelHedgesNum.WithLabelValues(model.Name, "budget_exceeded").Inc()
+				continue
+			}
+			send(secondary)
+			pending++
+			hedged = true
+
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				if hedged {
+					outcome := "primary"
+					if r.route == secondary {
+						outcome = "hedge"
+					}
+					metrics.ModelHedgesNum.WithLabelValues(model.Name, outcome).Inc()
+				}
+				return []routeResult{r}
+			}
+			failed = append(failed, r)
+
+			// if the primary request failed before the hedged request was sent, it is not sent,
+			// as the caller fails over to the next route as usual
+			if !hedged {
+				return failed
+			}
+		}
+	}
+
+	metrics.ModelHedgesNum.WithLabelValues(model.Name, "failed").Inc()
+	return failed
+}
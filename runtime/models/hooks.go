@@ -0,0 +1,70 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package models
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+)
+
+// An InvocationHook can inspect or rewrite the request sent to a model provider,
+// and the response returned from it, for every model invocation made by a plugin.
+// Returning an error from either method aborts the invocation with that error.
+type InvocationHook interface {
+	// BeforeInvoke is called before the request is sent to the provider.
+	// It returns the input that should be sent in place of the original.
+	BeforeInvoke(ctx context.Context, model *manifest.ModelInfo, input string) (string, error)
+
+	// AfterInvoke is called after the provider has responded.
+	// It returns the output that should be returned to the plugin in place of the original.
+	AfterInvoke(ctx context.Context, model *manifest.ModelInfo, input, output string) (string, error)
+}
+
+var invocationHooks []InvocationHook
+var hooksMutex = sync.RWMutex{}
+
+// RegisterInvocationHook adds a hook to the model invocation pipeline.
+// BeforeInvoke hooks run in registration order, and AfterInvoke hooks run in reverse order,
+// so that the first hook registered sees the original request and the final response.
+func RegisterInvocationHook(hook InvocationHook) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	invocationHooks = append(invocationHooks, hook)
+}
+
+func runBeforeInvokeHooks(ctx context.Context, model *manifest.ModelInfo, input string) (string, error) {
+	hooksMutex.RLock()
+	defer hooksMutex.RUnlock()
+
+	for _, hook := range invocationHooks {
+		var err error
+		input, err = hook.BeforeInvoke(ctx, model, input)
+		if err != nil {
+			return "", err
+		}
+	}
+	return input, nil
+}
+
+func runAfterInvokeHooks(ctx context.Context, model *manifest.ModelInfo, input, output string) (string, error) {
+	hooksMutex.RLock()
+	defer hooksMutex.RUnlock()
+
+	for i := len(invocationHooks) - 1; i >= 0; i-- {
+		var err error
+		output, err = invocationHooks[i].AfterInvoke(ctx, model, input, output)
+		if err != nil {
+			return "", err
+		}
+	}
+	return output, nil
+}
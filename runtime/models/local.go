@@ -0,0 +1,179 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/db"
+	"github.com/hypermodeinc/modus/runtime/httpclient"
+	"github.com/hypermodeinc/modus/runtime/secrets"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// localHealthTTL is how long a successful health check of a local inference server is trusted
+// before the server is checked again.
+const localHealthTTL = 30 * time.Second
+
+const localHealthTimeout = 5 * time.Second
+
+// localServerHealth holds the time of the last successful health check, keyed by health check URL.
+var localServerHealth sync.Map
+
+// invokeLocalModel invokes a model served by a self-hosted inference server.  The server's health endpoint
+// is checked before the request is sent, so that a server that is still loading the model fails fast.
+func invokeLocalModel(ctx context.Context, model *manifest.ModelInfo, input string) (string, error) {
+	span, ctx := utils.NewSentrySpanForCurrentFunc(ctx)
+	defer span.Finish()
+
+	connInfo, err := httpclient.GetHttpConnectionInfo(model.Connection)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint, err := getModelEndpointUrl(model, connInfo)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, err = httpclient.ContextWithConnectionClient(ctx, connInfo)
+	if err != nil {
+		return "", err
+	}
+
+	healthUrl, err := getLocalHealthUrl(endpoint, model.Local)
+	if err != nil {
+		return "", err
+	}
+
+	if err := ensureLocalServerReady(ctx, connInfo, healthUrl); err != nil {
+		return "", fmt.Errorf("local inference server for model %s is not ready: %w", model.Name, err)
+	}
+
+	payload, err := applyLocalModelOptions(input, model.Local)
+	if err != nil {
+		return "", err
+	}
+
+	bs := func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Content-Type", "application/json")
+		return secrets.ApplySecretsToHttpRequest(ctx, connInfo, req)
+	}
+
+	var output string
+	var startTime, endTime time.Time
+	if model.Local.Stream {
+		startTime = utils.GetTime()
+		output, err = postStreaming(ctx, model, endpoint, payload, bs)
+		endTime = utils.GetTime()
+	} else {
+		var res *utils.HttpResult[string]
+		res, err = utils.PostHttp[string](ctx, endpoint, payload, bs)
+		if err == nil {
+			output, startTime, endTime = res.Data, res.StartTime, res.EndTime
+		}
+	}
+
+	if err != nil {
+		// if the server could not be reached, check its health again before the next request
+		var httpErr *utils.HttpError
+		if !errors.As(err, &httpErr) {
+			localServerHealth.Delete(healthUrl)
+		}
+		notifyIfRateLimited(ctx, err)
+		return "", err
+	}
+
+	db.WriteInferenceHistory(ctx, model, payload, output, startTime, endTime)
+
+	return output, nil
+}
+
+func getLocalHealthUrl(endpoint string, info *manifest.LocalModelInfo) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("error parsing model endpoint url: %w", err)
+	}
+	u.Path = info.GetHealthPath()
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String(), nil
+}
+
+func ensureLocalServerReady(ctx context.Context, connection *manifest.HTTPConnectionInfo, healthUrl string) error {
+	if t, ok := localServerHealth.Load(healthUrl); ok && time.Since(t.(time.Time)) < localHealthTTL {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, localHealthTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthUrl, nil)
+	if err != nil {
+		return err
+	}
+	if err := secrets.ApplySecretsToHttpRequest(ctx, connection, req); err != nil {
+		return err
+	}
+
+	res, err := utils.HttpClientFromContext(ctx).Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return utils.NewHttpError(res, body)
+	}
+
+	localServerHealth.Store(healthUrl, time.Now())
+	return nil
+}
+
+// applyLocalModelOptions adds the configured hints to the request, and requests a streamed response if enabled.
+// Fields already present in the request take precedence over the hints.
+func applyLocalModelOptions(input string, info *manifest.LocalModelInfo) (string, error) {
+	if !info.Stream && len(info.Hints) == 0 {
+		return input, nil
+	}
+
+	var req map[string]any
+	if err := utils.JsonDeserialize([]byte(input), &req); err != nil {
+		return "", fmt.Errorf("error parsing model request: %w", err)
+	}
+
+	for k, v := range info.Hints {
+		if _, ok := req[k]; !ok {
+			req[k] = v
+		}
+	}
+
+	if info.Stream {
+		req["stream"] = true
+		if _, ok := req["stream_options"]; !ok {
+			req["stream_options"] = map[string]any{"include_usage": true}
+		}
+	}
+
+	bytes, err := utils.JsonSerialize(req)
+	if err != nil {
+		return "", fmt.Errorf("error serializing model request: %w", err)
+	}
+	return string(bytes), nil
+}
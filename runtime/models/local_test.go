@@ -0,0 +1,157 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLocalModelName = "local"
+
+func setupLocalModel(t *testing.T, handler http.Handler, info *manifest.LocalModelInfo) {
+	tsrv := httptest.NewServer(handler)
+	t.Cleanup(tsrv.Close)
+
+	h := manifestdata.GetManifest().Connections[testConnectionName].(manifest.HTTPConnectionInfo)
+	h.Endpoint = tsrv.URL + "/v1/chat/completions"
+	manifestdata.GetManifest().Connections[testConnectionName] = h
+
+	manifestdata.GetManifest().Models[testLocalModelName] = manifest.ModelInfo{
+		Name:       testLocalModelName,
+		Connection: testConnectionName,
+		Local:      info,
+	}
+	t.Cleanup(func() { delete(manifestdata.GetManifest().Models, testLocalModelName) })
+}
+
+func TestInvokeLocalModelNotReady(t *testing.T) {
+	called := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	setupLocalModel(t, mux, &manifest.LocalModelInfo{})
+
+	_, err := InvokeModel(context.Background(), testLocalModelName, `{}`)
+	assert.ErrorContains(t, err, "not ready")
+	assert.False(t, called)
+}
+
+func TestInvokeLocalModelWithHints(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var input map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+		_ = json.NewEncoder(w).Encode(input)
+	})
+	setupLocalModel(t, mux, &manifest.LocalModelInfo{
+		HealthPath: "/ready",
+		Hints: map[string]any{
+			"priority":     1,
+			"cache_prompt": true,
+		},
+	})
+
+	resp, err := InvokeModel(context.Background(), testLocalModelName, `{"model":"m","priority":5}`)
+	require.NoError(t, err)
+
+	// hints do not override fields already in the request
+	assert.JSONEq(t, `{"model":"m","priority":5,"cache_prompt":true}`, resp)
+}
+
+func TestInvokeLocalModelStreaming(t *testing.T) {
+	chunks := []string{
+		`{"id":"c1","object":"chat.completion.chunk","created":1,"model":"m","choices":[{"index":0,"delta":{"role":"assistant","content":""},"finish_reason":null}]}`,
+		`{"id":"c1","object":"chat.completion.chunk","created":1,"model":"m","choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}`,
+		`{"id":"c1","object":"chat.completion.chunk","created":1,"model":"m","choices":[{"index":0,"delta":{"content":", world"},"finish_reason":null}]}`,
+		`{"id":"c1","object":"chat.completion.chunk","created":1,"model":"m","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`{"id":"c1","object":"chat.completion.chunk","created":1,"model":"m","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`,
+		`[DONE]`,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var input map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+		assert.Equal(t, true, input["stream"])
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, c := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", c)
+		}
+	})
+	setupLocalModel(t, mux, &manifest.LocalModelInfo{Stream: true})
+
+	resp, err := InvokeModel(context.Background(), testLocalModelName, `{"model":"m"}`)
+	require.NoError(t, err)
+
+	expected := `{
+		"id": "c1",
+		"object": "chat.completion",
+		"created": 1,
+		"model": "m",
+		"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hello, world"}, "finish_reason": "stop"}],
+		"usage": {"prompt_tokens": 3, "completion_tokens": 2, "total_tokens": 5}
+	}`
+	assert.JSONEq(t, expected, resp)
+}
+
+func TestStreamAssemblerToolCalls(t *testing.T) {
+	stream := strings.Join([]string{
+		`data: {"id":"c2","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+		`data: {"id":"c2","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+		`data: {"id":"c2","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]},"finish_reason":"tool_calls"}]}`,
+		`data: [DONE]`,
+	}, "\n\n")
+
+	a := &streamAssembler{}
+	require.NoError(t, readServerSentEvents(strings.NewReader(stream), a.add))
+
+	result, err := a.result()
+	require.NoError(t, err)
+
+	expected := `{
+		"id": "c2",
+		"object": "chat.completion",
+		"created": 0,
+		"model": "",
+		"choices": [{
+			"index": 0,
+			"message": {
+				"role": "assistant",
+				"content": "",
+				"tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}}]
+			},
+			"finish_reason": "tool_calls"
+		}]
+	}`
+	assert.JSONEq(t, expected, string(result))
+}
@@ -0,0 +1,320 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/metrics"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+const maxStreamEventSize = 4 * 1024 * 1024
+
+// postStreaming sends a request that asks for a streamed response, and assembles the server-sent events
+// into the equivalent non-streamed OpenAI-compatible response.
+func postStreaming(ctx context.Context, model *manifest.ModelInfo, url string, payload string, beforeSend func(context.Context, *http.Request) error) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+
+	utils.InjectTraceContext(ctx, req)
+	req.Header.Set("Accept", "text/event-stream")
+	if err := beforeSend(ctx, req); err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	res, err := utils.HttpClientFromContext(ctx).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return "", fmt.Errorf("error reading response body: %w", err)
+		}
+		return "", utils.NewHttpError(res, body)
+	}
+
+	a := &streamAssembler{}
+	firstToken := true
+	err = readServerSentEvents(res.Body, func(data []byte) error {
+		if firstToken {
+			firstToken = false
+			metrics.LocalModelFirstTokenSeconds.WithLabelValues(model.Name).Observe(time.Since(start).Seconds())
+		}
+		return a.add(data)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	output, err := a.result()
+	if err != nil {
+		return "", fmt.Errorf("error serializing streamed response: %w", err)
+	}
+	return string(output), nil
+}
+
+// readServerSentEvents calls fn with the data of each event in the stream, until the stream ends
+// or the "[DONE]" event is received.
+func readServerSentEvents(r io.Reader, fn func(data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamEventSize)
+
+	var data []byte
+	dispatch := func() (bool, error) {
+		if len(data) == 0 {
+			return false, nil
+		}
+		defer func() { data = data[:0] }()
+		if string(data) == "[DONE]" {
+			return true, nil
+		}
+		return false, fn(data)
+	}
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			if done, err := dispatch(); done || err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := bytes.CutPrefix(line, []byte("data:"))
+		if !ok {
+			// comments and other fields are not used
+			continue
+		}
+		value = bytes.TrimPrefix(value, []byte(" "))
+		if len(data) > 0 {
+			data = append(data, '\n')
+		}
+		data = append(data, value...)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading streamed response: %w", err)
+	}
+
+	_, err := dispatch()
+	return err
+}
+
+type streamChunk struct {
+	Id                string         `json:"id"`
+	Object            string         `json:"object"`
+	Created           int64          `json:"created"`
+	Model             string         `json:"model"`
+	SystemFingerprint string         `json:"system_fingerprint"`
+	Choices           []streamChoice `json:"choices"`
+	Usage             map[string]any `json:"usage"`
+	Message           string         `json:"message"`
+	Error             any            `json:"error"`
+}
+
+type streamChoice struct {
+	Index        int          `json:"index"`
+	Delta        *streamDelta `json:"delta"`
+	Text         string       `json:"text"`
+	FinishReason string       `json:"finish_reason"`
+}
+
+type streamDelta struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []streamToolCall `json:"tool_calls"`
+}
+
+type streamToolCall struct {
+	Index    int    `json:"index"`
+	Id       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// streamAssembler accumulates the chunks of a streamed chat completion or text completion.
+type streamAssembler struct {
+	chunk   streamChunk
+	chat    bool
+	choices []*assembledChoice
+}
+
+type assembledChoice struct {
+	Index        int           `json:"index"`
+	Message      *assembledMsg `json:"message,omitempty"`
+	Text         *string       `json:"text,omitempty"`
+	FinishReason string        `json:"finish_reason"`
+
+	text *strings.Builder
+}
+
+type assembledMsg struct {
+	Role      string               `json:"role"`
+	Content   string               `json:"content"`
+	ToolCalls []*assembledToolCall `json:"tool_calls,omitempty"`
+
+	content strings.Builder
+}
+
+type assembledToolCall struct {
+	Index    int    `json:"-"`
+	Id       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+func (a *streamAssembler) add(data []byte) error {
+	var chunk streamChunk
+	if err := utils.JsonDeserialize(data, &chunk); err != nil {
+		return fmt.Errorf("error parsing streamed response: %w", err)
+	}
+
+	if chunk.Error != nil {
+		return fmt.Errorf("local inference server returned an error: %v", chunk.Error)
+	}
+	if chunk.Object == "error" {
+		return fmt.Errorf("local inference server returned an error: %s", chunk.Message)
+	}
+
+	if a.chunk.Id == "" {
+		a.chunk.Id = chunk.Id
+		a.chunk.Created = chunk.Created
+		a.chunk.Model = chunk.Model
+		a.chunk.SystemFingerprint = chunk.SystemFingerprint
+	}
+	if chunk.Usage != nil {
+		a.chunk.Usage = chunk.Usage
+	}
+
+	for _, c := range chunk.Choices {
+		choice := a.getChoice(c.Index)
+		if c.FinishReason != "" {
+			choice.FinishReason = c.FinishReason
+		}
+
+		if c.Delta == nil {
+			if choice.text == nil {
+				choice.text = &strings.Builder{}
+			}
+			choice.text.WriteString(c.Text)
+			continue
+		}
+
+		a.chat = true
+		if choice.Message == nil {
+			choice.Message = &assembledMsg{}
+		}
+		msg := choice.Message
+		if c.Delta.Role != "" {
+			msg.Role = c.Delta.Role
+		}
+		msg.content.WriteString(c.Delta.Content)
+
+		for _, tc := range c.Delta.ToolCalls {
+			call := msg.getToolCall(tc.Index)
+			if tc.Id != "" {
+				call.Id = tc.Id
+			}
+			if tc.Type != "" {
+				call.Type = tc.Type
+			}
+			if tc.Function.Name != "" {
+				call.Function.Name = tc.Function.Name
+			}
+			call.Function.Arguments += tc.Function.Arguments
+		}
+	}
+
+	return nil
+}
+
+func (a *streamAssembler) getChoice(index int) *assembledChoice {
+	for _, c := range a.choices {
+		if c.Index == index {
+			return c
+		}
+	}
+	c := &assembledChoice{Index: index}
+	a.choices = append(a.choices, c)
+	return c
+}
+
+func (m *assembledMsg) getToolCall(index int) *assembledToolCall {
+	for _, tc := range m.ToolCalls {
+		if tc.Index == index {
+			return tc
+		}
+	}
+	tc := &assembledToolCall{Index: index, Type: "function"}
+	m.ToolCalls = append(m.ToolCalls, tc)
+	return tc
+}
+
+// result returns the assembled response, in the form the server would have returned without streaming.
+func (a *streamAssembler) result() ([]byte, error) {
+	slices.SortFunc(a.choices, func(x, y *assembledChoice) int { return x.Index - y.Index })
+
+	object := "text_completion"
+	if a.chat {
+		object = "chat.completion"
+	}
+
+	for _, c := range a.choices {
+		if c.Message != nil {
+			c.Message.Content = c.Message.content.String()
+			if c.Message.Role == "" {
+				c.Message.Role = "assistant"
+			}
+		} else if c.text != nil {
+			text := c.text.String()
+			c.Text = &text
+		}
+	}
+
+	return utils.JsonSerialize(struct {
+		Id                string             `json:"id"`
+		Object            string             `json:"object"`
+		Created           int64              `json:"created"`
+		Model             string             `json:"model"`
+		SystemFingerprint string             `json:"system_fingerprint,omitempty"`
+		Choices           []*assembledChoice `json:"choices"`
+		Usage             map[string]any     `json:"usage,omitempty"`
+	}{
+		Id:                a.chunk.Id,
+		Object:            object,
+		Created:           a.chunk.Created,
+		Model:             a.chunk.Model,
+		SystemFingerprint: a.chunk.SystemFingerprint,
+		Choices:           a.choices,
+		Usage:             a.chunk.Usage,
+	})
+}
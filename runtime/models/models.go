@@ -13,13 +13,18 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/hypermodeinc/modus/lib/manifest"
 	"github.com/hypermodeinc/modus/runtime/db"
+	"github.com/hypermodeinc/modus/runtime/experiments"
 	"github.com/hypermodeinc/modus/runtime/httpclient"
 	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/metering"
 	"github.com/hypermodeinc/modus/runtime/secrets"
+	"github.com/hypermodeinc/modus/runtime/tokenizers"
 	"github.com/hypermodeinc/modus/runtime/utils"
 )
 
@@ -46,19 +51,91 @@ func GetModelInfo(modelName string) (*ModelInfo, error) {
 	return info, nil
 }
 
+// CountTokens returns the number of tokens that the text is encoded as by the model's tokenizer.
+func CountTokens(ctx context.Context, modelName string, text string) (int32, error) {
+	model, err := GetModel(modelName)
+	if err != nil {
+		return 0, err
+	}
+
+	name := model.Tokenizer
+	if name == "" {
+		var ok bool
+		if name, ok = tokenizers.EncodingForModel(model.SourceModel); !ok {
+			return 0, fmt.Errorf("the tokenizer of model %s is not known, and must be set in the manifest", modelName)
+		}
+	}
+
+	encoding, err := tokenizers.GetEncoding(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	return int32(encoding.CountTokens(text)), nil
+}
+
 func InvokeModel(ctx context.Context, modelName string, input string) (string, error) {
 	model, err := GetModel(modelName)
 	if err != nil {
 		return "", err
 	}
 
+	// an experiment on the model may assign the caller to a variant that is served by another model
+	assignment := experiments.ForModel(ctx, modelName)
+	if assignment != nil && assignment.Variant.Model != "" && assignment.Variant.Model != modelName {
+		variant, err := GetModel(assignment.Variant.Model)
+		if err != nil {
+			return "", fmt.Errorf("variant %s of experiment %s: %w", assignment.Variant.Name, assignment.Experiment, err)
+		}
+		input, err = replaceSourceModel(input, model.SourceModel, variant.SourceModel)
+		if err != nil {
+			return "", err
+		}
+		model = variant
+	}
+
 	// NOTE: Bedrock support is temporarily disabled
 	// TODO: use the provider pattern instead of branching
 	// if model.Connection == "aws-bedrock" {
 	// 	return invokeAwsBedrockModel(ctx, model, input)
 	// }
 
-	return PostToModelEndpoint[string](ctx, model, input)
+	input, err = runBeforeInvokeHooks(ctx, model, input)
+	if err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	output, err := invokeModelRoutes(ctx, model, input)
+	assignment.RecordModelOutcome(time.Since(start), output, err)
+	if err != nil {
+		return "", err
+	}
+	metering.Add(ctx, metering.UnitModelTokens, float64(usedTokens(output)))
+
+	return runAfterInvokeHooks(ctx, model, input, output)
+}
+
+// usedTokens returns the number of tokens that the model reports using for an invocation, or zero if it doesn't.
+// The usage is reported by OpenAI-compatible models as prompt and completion tokens, and by others as input and output tokens.
+func usedTokens(output string) int {
+	var res struct {
+		Usage struct {
+			TotalTokens      int `json:"total_tokens"`
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			InputTokens      int `json:"input_tokens"`
+			OutputTokens     int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if utils.JsonDeserialize([]byte(output), &res) != nil {
+		return 0
+	}
+
+	u := res.Usage
+	if u.TotalTokens > 0 {
+		return u.TotalTokens
+	}
+	return u.PromptTokens + u.CompletionTokens + u.InputTokens + u.OutputTokens
 }
 
 func PostToModelEndpoint[TResult any](ctx context.Context, model *manifest.ModelInfo, payload any) (TResult, error) {
@@ -77,6 +154,12 @@ func PostToModelEndpoint[TResult any](ctx context.Context, model *manifest.Model
 		return empty, err
 	}
 
+	ctx, err = httpclient.ContextWithConnectionClient(ctx, connInfo)
+	if err != nil {
+		var empty TResult
+		return empty, err
+	}
+
 	bs := func(ctx context.Context, req *http.Request) error {
 		req.Header.Set("Content-Type", "application/json")
 		if connInfo.Name == httpclient.HypermodeConnectionName {
@@ -88,6 +171,7 @@ func PostToModelEndpoint[TResult any](ctx context.Context, model *manifest.Model
 
 	res, err := utils.PostHttp[TResult](ctx, url, payload, bs)
 	if err != nil {
+		notifyIfRateLimited(ctx, err)
 		var empty TResult
 		return empty, err
 	}
@@ -121,3 +205,27 @@ func getModelEndpointUrl(model *manifest.ModelInfo, connection *manifest.HTTPCon
 
 	return connection.Endpoint, nil
 }
+
+// IsChatCompletionsModel reports whether the model is served by an endpoint of the OpenAI chat completions API,
+// which is also served by most other providers and inference servers, at a path that ends with /chat/completions.
+func IsChatCompletionsModel(model *manifest.ModelInfo) bool {
+	if model.Connection == httpclient.HypermodeConnectionName {
+		return false
+	}
+
+	connInfo, err := httpclient.GetHttpConnectionInfo(model.Connection)
+	if err != nil {
+		return false
+	}
+
+	endpoint, err := getModelEndpointUrl(model, connInfo)
+	if err != nil {
+		return false
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(strings.TrimRight(u.Path, "/"), "/chat/completions")
+}
@@ -12,9 +12,11 @@ package models
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/hypermodeinc/modus/lib/manifest"
@@ -117,3 +119,82 @@ func TestPostExternalModelEndpoint(t *testing.T) {
 	// as the mock server just echoes the inputs
 	assert.Equal(t, sentenceMap, resp)
 }
+
+func TestIsChatCompletionsModel(t *testing.T) {
+	connections := manifestdata.GetManifest().Connections
+	connections["chat-endpoint"] = manifest.HTTPConnectionInfo{
+		Name:     "chat-endpoint",
+		Endpoint: "https://example.com/openai/deployments/gpt/chat/completions?api-version=2024-06-01",
+	}
+	connections["chat-base-url"] = manifest.HTTPConnectionInfo{
+		Name:    "chat-base-url",
+		BaseURL: "https://example.com/v1/",
+	}
+
+	tests := []struct {
+		desc     string
+		model    manifest.ModelInfo
+		expected bool
+	}{
+		{"chat completions endpoint", manifest.ModelInfo{Connection: "chat-endpoint"}, true},
+		{"chat completions path", manifest.ModelInfo{Connection: "chat-base-url", Path: "chat/completions/"}, true},
+		{"embeddings path", manifest.ModelInfo{Connection: "chat-base-url", Path: "embeddings"}, false},
+		{"hypermode model", manifest.ModelInfo{Connection: "hypermode", Provider: "hugging-face"}, false},
+		{"unknown connection", manifest.ModelInfo{Connection: "unknown"}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsChatCompletionsModel(&tc.model))
+		})
+	}
+}
+
+type testInvocationHook struct {
+	name string
+}
+
+func (h *testInvocationHook) BeforeInvoke(ctx context.Context, model *manifest.ModelInfo, input string) (string, error) {
+	return strings.ReplaceAll(input, "secret", "[redacted]") + " " + h.name, nil
+}
+
+func (h *testInvocationHook) AfterInvoke(ctx context.Context, model *manifest.ModelInfo, input, output string) (string, error) {
+	return output + " " + h.name, nil
+}
+
+func TestInvokeModelWithHooks(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.Copy(w, r.Body)
+	})
+
+	tsrv := httptest.NewServer(handler)
+	defer tsrv.Close()
+
+	h := manifestdata.GetManifest().Connections[testConnectionName].(manifest.HTTPConnectionInfo)
+	h.Endpoint = tsrv.URL
+	manifestdata.GetManifest().Connections[testConnectionName] = h
+
+	RegisterInvocationHook(&testInvocationHook{name: "a"})
+	RegisterInvocationHook(&testInvocationHook{name: "b"})
+	defer func() { invocationHooks = nil }()
+
+	resp, err := InvokeModel(context.Background(), testModelName, "my secret")
+	assert.NoError(t, err)
+
+	// before hooks run in registration order, after hooks in reverse order
+	assert.Equal(t, "my [redacted] a b b a", resp)
+}
+
+func TestUsedTokens(t *testing.T) {
+	tests := map[string]int{
+		`{"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`: 15,
+		`{"usage":{"prompt_tokens":10,"completion_tokens":5}}`:                   15,
+		`{"usage":{"input_tokens":7,"output_tokens":3}}`:                         10,
+		`{"choices":[]}`: 0,
+		`not json`:       0,
+	}
+	for output, expected := range tests {
+		assert.Equal(t, expected, usedTokens(output), output)
+	}
+}
@@ -0,0 +1,39 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// A RateLimitObserver is notified when a model provider rejects a request because of rate limiting.
+// The retryAfter duration is the delay the provider requested, or zero if it did not specify one.
+type RateLimitObserver func(retryAfter time.Duration)
+
+// ContextWithRateLimitObserver returns a context in which any model invocations that are rate limited
+// by the provider notify the given observer.  This lets callers that invoke models indirectly,
+// such as through a plugin function, slow down in response.
+func ContextWithRateLimitObserver(ctx context.Context, observer RateLimitObserver) context.Context {
+	return context.WithValue(ctx, utils.RateLimitObserverContextKey, observer)
+}
+
+func notifyIfRateLimited(ctx context.Context, err error) {
+	var httpErr *utils.HttpError
+	if !errors.As(err, &httpErr) || !httpErr.IsRateLimited() {
+		return
+	}
+	if observer, ok := ctx.Value(utils.RateLimitObserverContextKey).(RateLimitObserver); ok {
+		observer(httpErr.RetryAfter)
+	}
+}
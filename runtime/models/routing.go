@@ -0,0 +1,188 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/metrics"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// routeCooldown is how long an endpoint that failed is tried after the other endpoints of a model.
+const routeCooldown = 30 * time.Second
+
+// routeFailures holds the time of the last failure of each endpoint, keyed by connection and path.
+var routeFailures sync.Map
+
+// getModelRoutes returns the endpoints that can serve the model, in the order they should be tried.
+// Each is represented as a copy of the model with the endpoint's connection details.  Endpoints that
+// failed recently are moved to the end, so that calls are not delayed by an endpoint that is down.
+func getModelRoutes(model *manifest.ModelInfo) []*manifest.ModelInfo {
+	primary := *model
+	primary.Fallbacks = nil
+	routes := []*manifest.ModelInfo{&primary}
+
+	for _, fb := range model.Fallbacks {
+		route := primary
+		route.Connection = fb.Connection
+		route.Path = fb.Path
+		route.Timeout = fb.Timeout
+		route.Local = nil
+		if fb.SourceModel != "" {
+			route.SourceModel = fb.SourceModel
+		}
+		routes = append(routes, &route)
+	}
+
+	healthy := make([]*manifest.ModelInfo, 0, len(routes))
+	var failing []*manifest.ModelInfo
+	for _, route := range routes {
+		if t, ok := routeFailures.Load(routeKey(route)); ok && time.Since(t.(time.Time)) < routeCooldown {
+			failing = append(failing, route)
+		} else {
+			healthy = append(healthy, route)
+		}
+	}
+	return append(healthy, failing...)
+}
+
+func routeKey(route *manifest.ModelInfo) string {
+	return route.Connection + "|" + route.Path
+}
+
+// invokeModelRoutes invokes the model, failing over to its fallback endpoints in order
+// when an endpoint cannot be reached, times out, is rate limited, or has a server error.
+// If the model is hedged, a slow first endpoint is raced against the next one.
+func invokeModelRoutes(ctx context.Context, model *manifest.ModelInfo, input string) (string, error) {
+	if len(model.Fallbacks) == 0 {
+		return invokeRoute(ctx, model, model, input)
+	}
+
+	routes := getModelRoutes(model)
+	errs := make([]error, 0, len(routes))
+	for i := 0; i < len(routes); {
+		var results []routeResult
+		if i == 0 && canHedge(model, routes) {
+			results = invokeHedged(ctx, model, routes[0], routes[1], input)
+		} else {
+			output, err := invokeRoute(ctx, model, routes[i], input)
+			results = []routeResult{{routes[i], output, err}}
+		}
+		i += len(results)
+
+		if r := results[0]; r.err == nil {
+			routeFailures.Delete(routeKey(r.route))
+			if r.route.Connection != model.Connection || r.route.Path != model.Path {
+				annotateFallback(ctx, model, r.route)
+			}
+			return r.output, nil
+		}
+
+		for _, r := range results {
+			if ctx.Err() != nil || !shouldFailOver(r.err) {
+				return "", r.err
+			}
+			routeFailures.Store(routeKey(r.route), time.Now())
+			errs = append(errs, fmt.Errorf("connection %s: %w", r.route.Connection, r.err))
+		}
+
+		if i < len(routes) {
+			last := results[len(results)-1]
+			logger.Warn(ctx).Err(last.err).
+				Str("model", model.Name).
+				Str("connection", last.route.Connection).
+				Str("next_connection", routes[i].Connection).
+				Bool("user_visible", true).
+				Msg("Model invocation failed.  Trying the next endpoint.")
+		}
+	}
+
+	return "", fmt.Errorf("all endpoints failed for model %s: %w", model.Name, errors.Join(errs...))
+}
+
+func invokeRoute(ctx context.Context, model, route *manifest.ModelInfo, input string) (string, error) {
+	if route.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(route.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	input, err := replaceSourceModel(input, model.SourceModel, route.SourceModel)
+	if err != nil {
+		return "", err
+	}
+
+	if route.Local != nil {
+		return invokeLocalModel(ctx, route, input)
+	}
+	return PostToModelEndpoint[string](ctx, route, input)
+}
+
+// replaceSourceModel replaces the "model" field of the request when it names the model's own source model,
+// so that a fallback endpoint receives the name of the source model it serves.
+func replaceSourceModel(input string, from, to string) (string, error) {
+	if from == to {
+		return input, nil
+	}
+
+	var req map[string]any
+	if err := utils.JsonDeserialize([]byte(input), &req); err != nil {
+		// not a JSON object, so there is no model field to replace
+		return input, nil
+	}
+
+	if m, ok := req["model"].(string); !ok || m != from {
+		return input, nil
+	}
+
+	req["model"] = to
+	bytes, err := utils.JsonSerialize(req)
+	if err != nil {
+		return "", fmt.Errorf("error serializing model request: %w", err)
+	}
+	return string(bytes), nil
+}
+
+func shouldFailOver(err error) bool {
+	var httpErr *utils.HttpError
+	if errors.As(err, &httpErr) {
+		return httpErr.IsRateLimited() || httpErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	// connection errors, timeouts, and servers that are not ready
+	return true
+}
+
+// annotateFallback records that the model invocation was served by a fallback endpoint,
+// both in the logs and in the messages returned to the function's caller.
+func annotateFallback(ctx context.Context, model, route *manifest.ModelInfo) {
+	metrics.ModelFallbacksNum.WithLabelValues(model.Name, route.Connection).Inc()
+
+	logger.Info(ctx).
+		Str("model", model.Name).
+		Str("connection", route.Connection).
+		Bool("user_visible", true).
+		Msg("Model invocation served by a fallback endpoint.")
+
+	if messages, ok := ctx.Value(utils.FunctionMessagesContextKey).(*[]utils.LogMessage); ok {
+		*messages = append(*messages, utils.LogMessage{
+			Level:   "info",
+			Message: fmt.Sprintf("Model %s was served by fallback connection %s.", model.Name, route.Connection),
+		})
+	}
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRoutedModelName        = "routed"
+	testFallbackConnectionName = "mock-fallback"
+)
+
+func setupRoutedModel(t *testing.T, primary, fallback http.HandlerFunc) {
+	psrv := httptest.NewServer(primary)
+	t.Cleanup(psrv.Close)
+	fsrv := httptest.NewServer(fallback)
+	t.Cleanup(fsrv.Close)
+
+	man := manifestdata.GetManifest()
+	h := man.Connections[testConnectionName].(manifest.HTTPConnectionInfo)
+	h.Endpoint = psrv.URL
+	man.Connections[testConnectionName] = h
+	man.Connections[testFallbackConnectionName] = manifest.HTTPConnectionInfo{
+		Name:     testFallbackConnectionName,
+		Endpoint: fsrv.URL,
+	}
+	man.Models[testRoutedModelName] = manifest.ModelInfo{
+		Name:        testRoutedModelName,
+		SourceModel: "primary-model",
+		Connection:  testConnectionName,
+		Fallbacks: []manifest.ModelFallbackInfo{
+			{Connection: testFallbackConnectionName, SourceModel: "fallback-model"},
+		},
+	}
+
+	t.Cleanup(func() {
+		delete(man.Models, testRoutedModelName)
+		delete(man.Connections, testFallbackConnectionName)
+		routeFailures.Clear()
+	})
+}
+
+func echoModelField(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var input map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+		_ = json.NewEncoder(w).Encode(map[string]any{"served_by": input["model"]})
+	}
+}
+
+func TestInvokeModelFailsOver(t *testing.T) {
+	primaryCalls := 0
+	setupRoutedModel(t, func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, echoModelField(t))
+
+	resp, err := InvokeModel(context.Background(), testRoutedModelName, `{"model":"primary-model"}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"served_by":"fallback-model"}`, resp)
+	assert.Equal(t, 1, primaryCalls)
+
+	// the failed endpoint is tried last while it is cooling down
+	model := manifestdata.GetManifest().Models[testRoutedModelName]
+	routes := getModelRoutes(&model)
+	assert.Equal(t, testFallbackConnectionName, routes[0].Connection)
+	assert.Equal(t, testConnectionName, routes[1].Connection)
+}
+
+func TestInvokeModelDoesNotFailOverOnClientError(t *testing.T) {
+	fallbackCalls := 0
+	setupRoutedModel(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}, func(w http.ResponseWriter, r *http.Request) {
+		fallbackCalls++
+	})
+
+	_, err := InvokeModel(context.Background(), testRoutedModelName, `{"model":"primary-model"}`)
+	assert.Error(t, err)
+	assert.Equal(t, 0, fallbackCalls)
+}
+
+func TestInvokeModelAllEndpointsFail(t *testing.T) {
+	fail := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	setupRoutedModel(t, fail, fail)
+
+	_, err := InvokeModel(context.Background(), testRoutedModelName, `{"model":"primary-model"}`)
+	assert.ErrorContains(t, err, "all endpoints failed")
+}
+
+func setHedge(t *testing.T, hedge *manifest.ModelHedgeInfo) {
+	man := manifestdata.GetManifest()
+	model := man.Models[testRoutedModelName]
+	model.Hedge = hedge
+	man.Models[testRoutedModelName] = model
+	t.Cleanup(func() { hedgeBudgets.Clear() })
+}
+
+func TestInvokeModelHedges(t *testing.T) {
+	canceled := make(chan struct{})
+	setupRoutedModel(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			close(canceled)
+		case <-time.After(5 * time.Second):
+		}
+	}, echoModelField(t))
+
+	// at 100 percent, every invocation earns enough budget to be hedged
+	setHedge(t, &manifest.ModelHedgeInfo{Delay: 20, MaxPercent: 100})
+
+	resp, err := InvokeModel(context.Background(), testRoutedModelName, `{"model":"primary-model"}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"served_by":"fallback-model"}`, resp)
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("expected the slower request to be canceled")
+	}
+}
+
+func TestInvokeModelHedgeBudget(t *testing.T) {
+	fallbackCalls := 0
+	setupRoutedModel(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		echoModelField(t)(w, r)
+	}, func(w http.ResponseWriter, r *http.Request) {
+		fallbackCalls++
+	})
+
+	// a single invocation at 10 percent doesn't earn enough budget for a hedged request
+	setHedge(t, &manifest.ModelHedgeInfo{Delay: 5, MaxPercent: 10})
+
+	resp, err := InvokeModel(context.Background(), testRoutedModelName, `{"model":"primary-model"}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"served_by":"primary-model"}`, resp)
+	assert.Equal(t, 0, fallbackCalls)
+}
+
+func TestHedgeBudget(t *testing.T) {
+	b := &hedgeBudget{}
+	for range 9 {
+		b.deposit(10)
+	}
+	assert.False(t, b.withdraw())
+
+	b.deposit(10)
+	assert.True(t, b.withdraw())
+	assert.False(t, b.withdraw())
+
+	// the budget that can be saved up is limited
+	for range 100 {
+		b.deposit(100)
+	}
+	for range hedgeBudgetBurst {
+		assert.True(t, b.withdraw())
+	}
+	assert.False(t, b.withdraw())
+}
+
+func TestReplaceSourceModel(t *testing.T) {
+	out, err := replaceSourceModel(`{"model":"a","x":1}`, "a", "b")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"model":"b","x":1}`, out)
+
+	// a model chosen explicitly by the function is left alone
+	out, err = replaceSourceModel(`{"model":"c"}`, "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, `{"model":"c"}`, out)
+
+	out, err = replaceSourceModel(`not json`, "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, `not json`, out)
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package models
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/db"
+	"github.com/hypermodeinc/modus/runtime/httpclient"
+	"github.com/hypermodeinc/modus/runtime/secrets"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// Audio is transcribed by a model that implements the OpenAI audio transcription API, which is a multipart form
+// containing the audio file.  This is served by the Whisper API itself, and by self-hosted servers such as
+// faster-whisper-server and whisper.cpp, which are declared with the local section of the model in the manifest.
+
+// TranscriptionOptions are the optional settings of an audio transcription.
+type TranscriptionOptions struct {
+	// Language is the ISO-639-1 code of the language spoken in the audio.  It is detected if not given.
+	Language string
+
+	// Prompt is text that guides the style of the transcript, or continues a previous segment of audio.
+	Prompt string
+
+	// Temperature is the sampling temperature, between 0 and 1.
+	Temperature float64
+}
+
+// Transcription is the text of an audio transcription, along with its timed segments.
+type Transcription struct {
+	Text     string
+	Language string
+	Duration float64
+	Segments []TranscriptionSegment
+}
+
+// TranscriptionSegment is a segment of a transcription, with its start and end in seconds from the start of the audio.
+type TranscriptionSegment struct {
+	Start float64
+	End   float64
+	Text  string
+}
+
+type transcriptionResponse struct {
+	Text     string  `json:"text"`
+	Language string  `json:"language"`
+	Duration float64 `json:"duration"`
+	Segments []struct {
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Text  string  `json:"text"`
+	} `json:"segments"`
+}
+
+// TranscribeAudio transcribes the audio with the model, which is sent to the model's endpoint by the host,
+// rather than by the calling function.  The format of the audio is detected from its content.
+func TranscribeAudio(ctx context.Context, modelName string, audio []byte, options *TranscriptionOptions) (*Transcription, error) {
+	span, ctx := utils.NewSentrySpanForCurrentFunc(ctx)
+	defer span.Finish()
+
+	if len(audio) == 0 {
+		return nil, errors.New("audio is empty")
+	}
+
+	model, err := GetModel(modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName, err := audioFileName(audio)
+	if err != nil {
+		return nil, err
+	}
+
+	connInfo, err := httpclient.GetHttpConnectionInfo(model.Connection)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := getModelEndpointUrl(model, connInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = httpclient.ContextWithConnectionClient(ctx, connInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	if model.Local != nil {
+		healthUrl, err := getLocalHealthUrl(endpoint, model.Local)
+		if err != nil {
+			return nil, err
+		}
+		if err := ensureLocalServerReady(ctx, connInfo, healthUrl); err != nil {
+			return nil, fmt.Errorf("local inference server for model %s is not ready: %w", model.Name, err)
+		}
+	}
+
+	body, contentType, err := newTranscriptionForm(model, fileName, audio, options)
+	if err != nil {
+		return nil, err
+	}
+
+	bs := func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Content-Type", contentType)
+		return secrets.ApplySecretsToHttpRequest(ctx, connInfo, req)
+	}
+
+	res, err := utils.PostHttp[transcriptionResponse](ctx, endpoint, body, bs)
+	if err != nil {
+		notifyIfRateLimited(ctx, err)
+		return nil, err
+	}
+
+	// the audio itself is not recorded in the inference history, as it may be large
+	input := map[string]any{"file": fileName, "size": len(audio), "options": options}
+	db.WriteInferenceHistory(ctx, model, input, res.Data.Text, res.StartTime, res.EndTime)
+
+	result := &Transcription{
+		Text:     res.Data.Text,
+		Language: res.Data.Language,
+		Duration: res.Data.Duration,
+		Segments: make([]TranscriptionSegment, len(res.Data.Segments)),
+	}
+	for i, s := range res.Data.Segments {
+		result.Segments[i] = TranscriptionSegment{Start: s.Start, End: s.End, Text: s.Text}
+	}
+	return result, nil
+}
+
+// newTranscriptionForm returns the multipart form of a transcription request, and its content type.
+func newTranscriptionForm(model *manifest.ModelInfo, fileName string, audio []byte, options *TranscriptionOptions) ([]byte, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	fw, err := w.CreateFormFile("file", fileName)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := fw.Write(audio); err != nil {
+		return nil, "", err
+	}
+
+	fields := [][2]string{{"response_format", "verbose_json"}}
+	if model.SourceModel != "" {
+		fields = append(fields, [2]string{"model", model.SourceModel})
+	}
+	if options != nil {
+		if options.Language != "" {
+			fields = append(fields, [2]string{"language", options.Language})
+		}
+		if options.Prompt != "" {
+			fields = append(fields, [2]string{"prompt", options.Prompt})
+		}
+		if options.Temperature != 0 {
+			fields = append(fields, [2]string{"temperature", strconv.FormatFloat(options.Temperature, 'f', -1, 64)})
+		}
+	}
+	for _, f := range fields {
+		if err := w.WriteField(f[0], f[1]); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// audioFileName returns a file name for the audio, whose extension declares its format,
+// as transcription servers determine the format from the file name.
+func audioFileName(audio []byte) (string, error) {
+	switch {
+	case len(audio) >= 12 && string(audio[0:4]) == "RIFF" && string(audio[8:12]) == "WAVE":
+		return "audio.wav", nil
+	case bytes.HasPrefix(audio, []byte("ID3")),
+		len(audio) >= 2 && audio[0] == 0xFF && audio[1]&0xE0 == 0xE0 && audio[1]&0x06 != 0:
+		return "audio.mp3", nil
+	case bytes.HasPrefix(audio, []byte("fLaC")):
+		return "audio.flac", nil
+	case bytes.HasPrefix(audio, []byte("OggS")):
+		return "audio.ogg", nil
+	case bytes.HasPrefix(audio, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return "audio.webm", nil
+	case len(audio) >= 8 && string(audio[4:8]) == "ftyp":
+		return "audio.m4a", nil
+	}
+	return "", errors.New("unrecognized audio format, must be one of wav, mp3, flac, ogg, webm or m4a")
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package models
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testWavAudio = []byte("RIFF\x24\x00\x00\x00WAVEfmt ")
+
+func TestTranscribeAudio(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "verbose_json", r.FormValue("response_format"))
+		assert.Equal(t, "en", r.FormValue("language"))
+		assert.Equal(t, "0.2", r.FormValue("temperature"))
+		assert.Empty(t, r.FormValue("prompt"))
+
+		f, h, err := r.FormFile("file")
+		require.NoError(t, err)
+		defer f.Close()
+		assert.Equal(t, "audio.wav", h.Filename)
+		content, _ := io.ReadAll(f)
+		assert.Equal(t, testWavAudio, content)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"text":"Hello there.","language":"english","duration":1.5,` +
+			`"segments":[{"id":0,"start":0,"end":1.5,"text":"Hello there."}]}`))
+	})
+	setupLocalModel(t, mux, &manifest.LocalModelInfo{})
+
+	options := &TranscriptionOptions{Language: "en", Temperature: 0.2}
+	result, err := TranscribeAudio(context.Background(), testLocalModelName, testWavAudio, options)
+	require.NoError(t, err)
+	assert.Equal(t, &Transcription{
+		Text:     "Hello there.",
+		Language: "english",
+		Duration: 1.5,
+		Segments: []TranscriptionSegment{{Start: 0, End: 1.5, Text: "Hello there."}},
+	}, result)
+}
+
+func TestTranscribeAudioUnrecognizedFormat(t *testing.T) {
+	_, err := TranscribeAudio(context.Background(), testModelName, []byte("not audio"), nil)
+	assert.ErrorContains(t, err, "unrecognized audio format")
+}
+
+func TestAudioFileName(t *testing.T) {
+	tests := map[string][]byte{
+		"audio.wav":  testWavAudio,
+		"audio.mp3":  []byte("ID3\x04\x00"),
+		"audio.flac": []byte("fLaC\x00\x00"),
+		"audio.ogg":  []byte("OggS\x00\x02"),
+		"audio.webm": {0x1A, 0x45, 0xDF, 0xA3, 0x01},
+		"audio.m4a":  []byte("\x00\x00\x00\x20ftypM4A "),
+	}
+	for name, audio := range tests {
+		got, err := audioFileName(audio)
+		assert.NoError(t, err)
+		assert.Equal(t, name, got)
+	}
+
+	got, err := audioFileName([]byte{0xFF, 0xFB, 0x90, 0x00})
+	assert.NoError(t, err)
+	assert.Equal(t, "audio.mp3", got)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/hypermodeinc/modus/lib/manifest"
 	"github.com/hypermodeinc/modus/runtime/manifestdata"
 	"github.com/hypermodeinc/modus/runtime/secrets"
+	"github.com/hypermodeinc/modus/runtime/tlsconfig"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 	"github.com/puzpuzpuz/xsync/v3"
@@ -92,9 +93,21 @@ func createDriver(ctx context.Context, n4jName string) (neo4j.DriverWithContext,
 		return nil, err
 	}
 
+	var configurers []func(*neo4j.Config)
+	if connection.TLS != nil {
+		tlsConfig, err := tlsconfig.ForConnection(ctx, info, connection.TLS)
+		if err != nil {
+			return nil, err
+		}
+		configurers = append(configurers, func(c *neo4j.Config) {
+			c.TlsConfig = tlsConfig
+		})
+	}
+
 	driver, err := neo4j.NewDriverWithContext(
 		dbUri,
 		neo4j.BasicAuth(username, password, ""),
+		configurers...,
 	)
 	if err != nil {
 		return nil, err
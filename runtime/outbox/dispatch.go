@@ -0,0 +1,204 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/db"
+	"github.com/hypermodeinc/modus/runtime/httpclient"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/metrics"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const pollInterval = 2 * time.Second
+const claimLimit = 50
+const claimLease = 2 * time.Minute
+const maxConcurrentDeliveries = 8
+const maxAttempts = 10
+const initialBackoff = time.Second
+const maxBackoff = 10 * time.Minute
+const defaultDeliveryTimeout = 30 * time.Second
+
+type memoryMessage struct {
+	record      db.OutboxRecord
+	nextAttempt time.Time
+}
+
+// dispatcher delivers committed outbox messages, from the database and from memory.
+type dispatcher struct {
+	mu     sync.Mutex
+	memory []*memoryMessage
+	wake   chan struct{}
+}
+
+var globalDispatcher = &dispatcher{wake: make(chan struct{}, 1)}
+
+// deliver sends a message.  It is replaced in tests.
+var deliver = deliverHttpRequest
+
+func Initialize(ctx context.Context) {
+	go globalDispatcher.run(ctx)
+}
+
+func (d *dispatcher) wakeUp() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *dispatcher) enqueue(records ...db.OutboxRecord) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, r := range records {
+		d.memory = append(d.memory, &memoryMessage{record: r, nextAttempt: time.Now()})
+	}
+}
+
+// takeDue removes and returns the in-memory messages that are due for delivery.
+func (d *dispatcher) takeDue(now time.Time) []db.OutboxRecord {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var due []db.OutboxRecord
+	remaining := d.memory[:0]
+	for _, m := range d.memory {
+		if m.nextAttempt.After(now) {
+			remaining = append(remaining, m)
+		} else {
+			due = append(due, m.record)
+		}
+	}
+	d.memory = remaining
+	return due
+}
+
+func (d *dispatcher) run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+		d.dispatchDue(ctx)
+	}
+}
+
+func (d *dispatcher) dispatchDue(ctx context.Context) {
+	records, err := db.ClaimOutboxRecords(ctx, claimLimit, claimLease)
+	if err != nil && !db.IsNotConfigured(err) {
+		logger.Err(ctx, err).Msg("Error reading outbox messages from the database.")
+	}
+
+	g := &errgroup.Group{}
+	g.SetLimit(maxConcurrentDeliveries)
+	for _, r := range records {
+		g.Go(func() error {
+			d.attempt(ctx, r, true)
+			return nil
+		})
+	}
+	for _, r := range d.takeDue(time.Now()) {
+		g.Go(func() error {
+			d.attempt(ctx, r, false)
+			return nil
+		})
+	}
+	_ = g.Wait()
+}
+
+// attempt delivers a message once, and records the outcome.  Failed messages are retried with exponential backoff,
+// until the maximum number of attempts is reached.
+func (d *dispatcher) attempt(ctx context.Context, r db.OutboxRecord, durable bool) {
+	err := deliver(ctx, r.Request)
+	attempts := r.Attempts + 1
+
+	if err == nil {
+		metrics.OutboxMessagesNum.WithLabelValues("delivered").Inc()
+		if durable {
+			if err := db.CompleteOutboxRecord(ctx, r.Id); err != nil {
+				logger.Err(ctx, err).Str("outbox_id", r.Id).Msg("Error marking outbox message as delivered.")
+			}
+		}
+		return
+	}
+
+	failed := attempts >= maxAttempts
+	nextAttempt := time.Now().Add(backoff(attempts))
+
+	if failed {
+		metrics.OutboxMessagesNum.WithLabelValues("failed").Inc()
+		logger.Error(ctx).Err(err).
+			Str("outbox_id", r.Id).
+			Str("execution_id", r.ExecutionId).
+			Str("function", r.Function).
+			Int("attempts", attempts).
+			Bool("user_visible", true).
+			Msg("Outbox message could not be delivered, and will not be retried.")
+	} else {
+		metrics.OutboxMessagesNum.WithLabelValues("retried").Inc()
+		logger.Warn(ctx).Err(err).
+			Str("outbox_id", r.Id).
+			Str("execution_id", r.ExecutionId).
+			Int("attempts", attempts).
+			Time("next_attempt", nextAttempt).
+			Msg("Outbox message delivery failed, and will be retried.")
+	}
+
+	if durable {
+		if err := db.RetryOutboxRecord(ctx, r.Id, attempts, nextAttempt, err.Error(), failed); err != nil {
+			logger.Err(ctx, err).Str("outbox_id", r.Id).Msg("Error recording outbox delivery attempt.")
+		}
+	} else if !failed {
+		r.Attempts = attempts
+		d.mu.Lock()
+		d.memory = append(d.memory, &memoryMessage{record: r, nextAttempt: nextAttempt})
+		d.mu.Unlock()
+	}
+}
+
+// backoff returns the delay before the next attempt, doubling with each attempt made.
+func backoff(attempts int) time.Duration {
+	delay := initialBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+func deliverHttpRequest(ctx context.Context, data []byte) error {
+	var request httpclient.HttpRequest
+	if err := utils.JsonDeserialize(data, &request); err != nil {
+		return fmt.Errorf("invalid outbox message: %w", err)
+	}
+	if request.TimeoutMs <= 0 {
+		request.TimeoutMs = defaultDeliveryTimeout.Milliseconds()
+	}
+
+	response, err := httpclient.Fetch(ctx, &request)
+	if err != nil {
+		return err
+	}
+	if response.Status < 200 || response.Status >= 300 {
+		return fmt.Errorf("%s %s returned status %d", request.Method, request.Url, response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/db"
+	"github.com/hypermodeinc/modus/runtime/httpclient"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/metrics"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+const maxMessagesPerInvocation = 100
+
+// An outbox holds the side-effect messages staged during a function invocation.
+// They are dispatched only if the invocation completes successfully.
+type outbox struct {
+	mu       sync.Mutex
+	messages []*httpclient.HttpRequest
+}
+
+// persist stores committed messages durably.  It is replaced in tests.
+var persist = db.WriteOutboxRecords
+
+// Begin returns a context with an outbox for a function invocation, and a function to call when the invocation ends.
+// Functions called from within another invocation share the caller's outbox, so that their messages are only
+// dispatched if the outermost invocation succeeds.  Messages staged during shadow executions are never dispatched.
+func Begin(ctx context.Context) (context.Context, func(success bool)) {
+	if _, ok := ctx.Value(utils.OutboxContextKey).(*outbox); ok {
+		return ctx, func(bool) {}
+	}
+
+	ob := &outbox{}
+	ctx = context.WithValue(ctx, utils.OutboxContextKey, ob)
+	return ctx, func(success bool) {
+		ob.mu.Lock()
+		messages := ob.messages
+		ob.messages = nil
+		ob.mu.Unlock()
+
+		if len(messages) == 0 {
+			return
+		}
+
+		if !success || ctx.Value(utils.ShadowExecutionContextKey) != nil {
+			metrics.OutboxMessagesNum.WithLabelValues("discarded").Add(float64(len(messages)))
+			logger.Info(ctx).
+				Int("messages", len(messages)).
+				Bool("user_visible", true).
+				Msg("Discarded outbox messages, because the function did not complete successfully.")
+			return
+		}
+
+		commit(ctx, messages)
+	}
+}
+
+// Stage adds an HTTP request to the outbox of the current invocation.  The request is sent after the
+// invocation completes successfully, and is retried until it succeeds or the attempts are exhausted.
+func Stage(ctx context.Context, request *httpclient.HttpRequest) (bool, error) {
+	ob, ok := ctx.Value(utils.OutboxContextKey).(*outbox)
+	if !ok {
+		return false, errors.New("messages can only be staged during a function invocation")
+	}
+
+	if _, err := url.ParseRequestURI(request.Url); err != nil {
+		return false, fmt.Errorf("invalid url: %w", err)
+	}
+	if _, err := httpclient.GetHttpConnectionForUrl(request.Url); err != nil {
+		return false, err
+	}
+	request.Method = strings.ToUpper(request.Method)
+
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	if len(ob.messages) >= maxMessagesPerInvocation {
+		return false, fmt.Errorf("a function can stage at most %d outbox messages", maxMessagesPerInvocation)
+	}
+	ob.messages = append(ob.messages, request)
+
+	metrics.OutboxMessagesNum.WithLabelValues("staged").Inc()
+	return true, nil
+}
+
+// commit stores the messages of a successful invocation and wakes the dispatcher.  When the database is
+// unavailable, the messages are held in memory instead, which preserves retries but not across restarts.
+func commit(ctx context.Context, messages []*httpclient.HttpRequest) {
+	executionId, _ := ctx.Value(utils.ExecutionIdContextKey).(string)
+	function, _ := ctx.Value(utils.FunctionNameContextKey).(string)
+
+	records := make([]db.OutboxRecord, 0, len(messages))
+	for _, m := range messages {
+		request, err := utils.JsonSerialize(m)
+		if err != nil {
+			logger.Err(ctx, err).Str("url", m.Url).Msg("Error serializing outbox message.")
+			continue
+		}
+		records = append(records, db.OutboxRecord{
+			Id:          utils.GenerateUUIDv7(),
+			ExecutionId: executionId,
+			Function:    function,
+			Request:     request,
+		})
+	}
+
+	if err := persist(ctx, records); err != nil {
+		if !db.IsNotConfigured(err) {
+			logger.Err(ctx, err).Msg("Error storing outbox messages.  They will be dispatched from memory.")
+		} else if !config.IsDevEnvironment() {
+			logger.Warn(ctx).Msg("Database has not been configured.  Outbox messages will be dispatched from memory.")
+		}
+		globalDispatcher.enqueue(records...)
+	}
+
+	globalDispatcher.wakeUp()
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/db"
+	"github.com/hypermodeinc/modus/runtime/httpclient"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func stageDirect(ctx context.Context, url string) {
+	ob := ctx.Value(utils.OutboxContextKey).(*outbox)
+	ob.messages = append(ob.messages, &httpclient.HttpRequest{Url: url, Method: "POST"})
+}
+
+func capturePersisted(t *testing.T) *[]db.OutboxRecord {
+	var persisted []db.OutboxRecord
+	original := persist
+	persist = func(ctx context.Context, records []db.OutboxRecord) error {
+		persisted = append(persisted, records...)
+		return nil
+	}
+	t.Cleanup(func() { persist = original })
+	return &persisted
+}
+
+func TestBeginCommitsOnSuccess(t *testing.T) {
+	persisted := capturePersisted(t)
+
+	ctx := context.WithValue(context.Background(), utils.ExecutionIdContextKey, "exec-1")
+	ctx, end := Begin(ctx)
+	stageDirect(ctx, "https://example.com/a")
+	stageDirect(ctx, "https://example.com/b")
+	end(true)
+
+	require.Len(t, *persisted, 2)
+	require.Equal(t, "exec-1", (*persisted)[0].ExecutionId)
+
+	var request httpclient.HttpRequest
+	require.NoError(t, utils.JsonDeserialize((*persisted)[1].Request, &request))
+	require.Equal(t, "https://example.com/b", request.Url)
+}
+
+func TestBeginDiscardsOnFailure(t *testing.T) {
+	persisted := capturePersisted(t)
+
+	ctx, end := Begin(context.Background())
+	stageDirect(ctx, "https://example.com/a")
+	end(false)
+
+	require.Empty(t, *persisted)
+}
+
+func TestBeginDiscardsShadowExecutions(t *testing.T) {
+	persisted := capturePersisted(t)
+
+	ctx := context.WithValue(context.Background(), utils.ShadowExecutionContextKey, true)
+	ctx, end := Begin(ctx)
+	stageDirect(ctx, "https://example.com/a")
+	end(true)
+
+	require.Empty(t, *persisted)
+}
+
+func TestBeginNestedJoinsOuterOutbox(t *testing.T) {
+	persisted := capturePersisted(t)
+
+	outer, endOuter := Begin(context.Background())
+	inner, endInner := Begin(outer)
+	stageDirect(inner, "https://example.com/a")
+	endInner(true)
+	require.Empty(t, *persisted)
+
+	endOuter(true)
+	require.Len(t, *persisted, 1)
+}
+
+func TestCommitFallsBackToMemory(t *testing.T) {
+	original := persist
+	persist = func(ctx context.Context, records []db.OutboxRecord) error {
+		return errors.New("connection refused")
+	}
+	t.Cleanup(func() { persist = original })
+
+	d := globalDispatcher
+	d.takeDue(time.Now().Add(time.Hour))
+
+	ctx, end := Begin(context.Background())
+	stageDirect(ctx, "https://example.com/a")
+	end(true)
+
+	due := d.takeDue(time.Now())
+	require.Len(t, due, 1)
+}
+
+func TestStageRequiresInvocation(t *testing.T) {
+	ok, err := Stage(context.Background(), &httpclient.HttpRequest{Url: "https://example.com"})
+	require.False(t, ok)
+	require.Error(t, err)
+}
+
+func TestAttemptRetriesInMemory(t *testing.T) {
+	original := deliver
+	deliver = func(ctx context.Context, data []byte) error {
+		return errors.New("unavailable")
+	}
+	t.Cleanup(func() { deliver = original })
+
+	d := &dispatcher{wake: make(chan struct{}, 1)}
+	d.attempt(context.Background(), db.OutboxRecord{Id: "1"}, false)
+	require.Len(t, d.memory, 1)
+	require.Equal(t, 1, d.memory[0].record.Attempts)
+	require.Empty(t, d.takeDue(time.Now()))
+
+	d.memory[0].record.Attempts = maxAttempts - 1
+	d.memory[0].nextAttempt = time.Now()
+	r := d.takeDue(time.Now())[0]
+	d.attempt(context.Background(), r, false)
+	require.Empty(t, d.memory)
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, time.Second, backoff(1))
+	require.Equal(t, 2*time.Second, backoff(2))
+	require.Equal(t, 8*time.Second, backoff(4))
+	require.Equal(t, maxBackoff, backoff(20))
+}
@@ -12,14 +12,21 @@ package pluginmanager
 import (
 	"context"
 	"fmt"
+	"runtime"
+	"strings"
+	"sync"
 
 	"github.com/hypermodeinc/modus/lib/metadata"
+	"github.com/hypermodeinc/modus/runtime/config"
 	"github.com/hypermodeinc/modus/runtime/db"
 	"github.com/hypermodeinc/modus/runtime/logger"
 	"github.com/hypermodeinc/modus/runtime/plugins"
+	"github.com/hypermodeinc/modus/runtime/shadow"
 	"github.com/hypermodeinc/modus/runtime/storage"
 	"github.com/hypermodeinc/modus/runtime/utils"
 	"github.com/hypermodeinc/modus/runtime/wasmhost"
+
+	"github.com/tetratelabs/wazero"
 )
 
 func monitorPlugins(ctx context.Context) {
@@ -33,7 +40,9 @@ func monitorPlugins(ctx context.Context) {
 		return err
 	}
 
+	// plugins are compiled concurrently, which is the slowest part of loading them
 	sm := storage.NewStorageMonitor("*.wasm")
+	sm.Concurrency = runtime.NumCPU()
 	sm.Added = loadPluginFile
 	sm.Modified = loadPluginFile
 	sm.Removed = func(fi storage.FileInfo) error {
@@ -47,14 +56,42 @@ func monitorPlugins(ctx context.Context) {
 	}
 	sm.Changed = func(errors []error) {
 		if len(errors) == 0 {
-			plugins := globalPluginRegistry.GetAll()
-			registry := wasmhost.GetWasmHost(ctx).GetFunctionRegistry()
-			registry.RegisterAllFunctions(ctx, plugins...)
+			registerAllFunctions(ctx)
 		}
 	}
 	sm.Start(ctx)
 }
 
+func registerAllFunctions(ctx context.Context) {
+	registrationMutex.Lock()
+	defer registrationMutex.Unlock()
+
+	plugins := globalPluginRegistry.GetAll()
+	registry := wasmhost.GetWasmHost(ctx).GetFunctionRegistry()
+	registry.RegisterAllFunctions(ctx, plugins...)
+}
+
+// LoadPlugin loads a plugin from its binary content, rather than from storage, and registers its functions.
+// The filename identifies the plugin, and replaces any plugin loaded with the same filename.
+func LoadPlugin(ctx context.Context, filename string, content []byte) error {
+	if err := loadPluginContent(ctx, filename, content); err != nil {
+		return err
+	}
+	registerAllFunctions(ctx)
+	return nil
+}
+
+// UnloadPlugin unloads a plugin that was loaded with LoadPlugin, and unregisters its functions.
+func UnloadPlugin(ctx context.Context, filename string) error {
+	if err := unloadPlugin(ctx, filename); err != nil {
+		return err
+	}
+	registerAllFunctions(ctx)
+	return nil
+}
+
+var registrationMutex sync.Mutex
+
 func loadPlugin(ctx context.Context, filename string) error {
 	span, ctx := utils.NewSentrySpanForCurrentFunc(ctx)
 	defer span.Finish()
@@ -65,12 +102,10 @@ func loadPlugin(ctx context.Context, filename string) error {
 		return err
 	}
 
-	// Compile the plugin into a module
-	cm, err := wasmhost.GetWasmHost(ctx).CompileModule(ctx, bytes)
-	if err != nil {
-		return err
-	}
+	return loadPluginContent(ctx, filename, bytes)
+}
 
+func loadPluginContent(ctx context.Context, filename string, bytes []byte) error {
 	// Get the metadata for the plugin.
 	md, err := metadata.GetMetadataFromWasm(bytes)
 	if err == metadata.ErrMetadataNotFound {
@@ -82,15 +117,48 @@ func loadPlugin(ctx context.Context, filename string) error {
 		return err
 	}
 
-	// Make the plugin object.
-	plugin, err := plugins.NewPlugin(ctx, cm, filename, md)
+	// Make the plugin object, deferring compilation until first use if lazy loading is enabled.
+	var plugin *plugins.Plugin
+	if isLazy(filename, md) {
+		plugin, err = plugins.NewLazyPlugin(filename, md, func(ctx context.Context) (wazero.CompiledModule, error) {
+			return compilePlugin(ctx, filename, bytes, md)
+		})
+	} else {
+		var cm wazero.CompiledModule
+		cm, err = compilePlugin(ctx, filename, bytes, md)
+		if err != nil {
+			return err
+		}
+		plugin, err = plugins.NewPlugin(ctx, cm, filename, md)
+	}
 	if err != nil {
 		return err
 	}
 
-	// Write the plugin info to the database.
+	if md.Snapshot && config.PluginSnapshots {
+		plugin.EnableSnapshots(bytes)
+	}
+
+	// The remaining steps update shared state, so are done for one plugin at a time.
+	registrationMutex.Lock()
+	defer registrationMutex.Unlock()
+
+	// A shadow build receives duplicated traffic, but does not replace the live build.
+	if shadow.IsShadowFile(filename) {
+		shadow.SetPlugin(plugin)
+		logger.Info(ctx).
+			Str("filename", filename).
+			Str("plugin", plugin.Name()).
+			Str("build_id", plugin.BuildId()).
+			Msg("Loaded shadow plugin build.")
+		return nil
+	}
+
+	// Write the plugin info to the database, unless this is a sandboxed worker process.
 	// Note, this may update the ID if a plugin with the same BuildID is in the db already.
-	db.WritePluginInfo(ctx, plugin)
+	if !config.IsSandboxWorker {
+		db.WritePluginInfo(ctx, plugin)
+	}
 
 	// Register the plugin.
 	globalPluginRegistry.AddOrUpdate(plugin)
@@ -104,6 +172,40 @@ func loadPlugin(ctx context.Context, filename string) error {
 	return err
 }
 
+// compilePlugin compiles the plugin into a module, and makes sure that the plugin's
+// host function imports match what this runtime exports.
+func compilePlugin(ctx context.Context, filename string, bytes []byte, md *metadata.Metadata) (wazero.CompiledModule, error) {
+	host := wasmhost.GetWasmHost(ctx)
+	cm, err := host.CompileModule(ctx, bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := host.CheckCompatibility(cm, md); err != nil {
+		logger.Error(ctx).Err(err).
+			Str("filename", filename).
+			Bool("user_visible", true).
+			Msg("Plugin is not compatible with this runtime.")
+		_ = cm.Close(ctx)
+		return nil, err
+	}
+
+	return cm, nil
+}
+
+// isLazy reports whether the plugin should be compiled on first use, rather than when it is loaded.
+func isLazy(filename string, md *metadata.Metadata) bool {
+	if !config.LazyPlugins || shadow.IsShadowFile(filename) {
+		return false
+	}
+	for _, name := range strings.Split(config.EagerPlugins, ",") {
+		if strings.TrimSpace(name) == md.Name() {
+			return false
+		}
+	}
+	return true
+}
+
 func logPluginLoaded(ctx context.Context, plugin *plugins.Plugin) {
 	evt := logger.Info(ctx)
 	evt.Str("filename", plugin.FileName)
@@ -152,6 +254,16 @@ func unloadPlugin(ctx context.Context, filename string) error {
 	span, ctx := utils.NewSentrySpanForCurrentFunc(ctx)
 	defer span.Finish()
 
+	if shadow.IsShadowFile(filename) {
+		if p := shadow.RemovePlugin(filename); p != nil {
+			logger.Info(ctx).
+				Str("plugin", p.Name()).
+				Str("build_id", p.BuildId()).
+				Msg("Unloading shadow plugin build.")
+			return p.Close(ctx)
+		}
+	}
+
 	p := globalPluginRegistry.GetByFile(filename)
 	if p == nil {
 		return fmt.Errorf("plugin not found: %s", filename)
@@ -163,5 +275,5 @@ func unloadPlugin(ctx context.Context, filename string) error {
 		Msg("Unloading plugin.")
 
 	globalPluginRegistry.Remove(p)
-	return p.Module.Close(ctx)
+	return p.Close(ctx)
 }
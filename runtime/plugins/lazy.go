@@ -0,0 +1,110 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/metadata"
+	"github.com/hypermodeinc/modus/runtime/languages"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/tetratelabs/wazero"
+)
+
+// A Compiler compiles the module of a lazily loaded plugin.
+type Compiler func(ctx context.Context) (wazero.CompiledModule, error)
+
+// NewLazyPlugin creates a plugin from its metadata alone.  The plugin's module is not compiled,
+// and its execution plans are not built, until EnsureCompiled is first called.
+func NewLazyPlugin(filename string, md *metadata.Metadata, compiler Compiler) (*Plugin, error) {
+	language, err := languages.GetLanguageForSDK(md.SDK)
+	if err != nil {
+		return nil, err
+	}
+
+	plugin := &Plugin{
+		Id:       utils.GenerateUUIDv7(),
+		Metadata: md,
+		FileName: filename,
+		Language: language,
+		compiler: compiler,
+	}
+
+	return plugin, nil
+}
+
+// IsCompiled reports whether the plugin's module has been compiled.
+func (p *Plugin) IsCompiled() bool {
+	p.compileMu.Lock()
+	defer p.compileMu.Unlock()
+	return p.Module != nil
+}
+
+// EnsureCompiled compiles the plugin's module and builds its execution plans, if not already done.
+func (p *Plugin) EnsureCompiled(ctx context.Context) error {
+	p.compileMu.Lock()
+	defer p.compileMu.Unlock()
+
+	if p.Module != nil {
+		return nil
+	}
+	if p.compiler == nil {
+		return fmt.Errorf("plugin %s has no compiled module", p.Name())
+	}
+
+	start := time.Now()
+	cm, err := p.compiler(ctx)
+	if err != nil {
+		return err
+	}
+
+	md := p.Metadata
+	plans, ok := getCachedPlans(md.Name(), md.BuildId)
+	if !ok {
+		plans, err = buildExecutionPlans(ctx, cm, md, p.Language)
+		if err != nil {
+			_ = cm.Close(ctx)
+			return err
+		}
+		cachePlans(md.Name(), md.BuildId, plans)
+	}
+
+	p.Module = cm
+	p.ExecutionPlans = plans
+
+	logger.Info(ctx).
+		Str("plugin", p.Name()).
+		Str("build_id", p.BuildId()).
+		Dur("duration_ms", time.Since(start)).
+		Msg("Compiled plugin on first use.")
+
+	return nil
+}
+
+// Close releases the plugin's compiled module, if it has one.
+func (p *Plugin) Close(ctx context.Context) error {
+	p.compileMu.Lock()
+	defer p.compileMu.Unlock()
+
+	if p.snapshots != nil {
+		if err := p.snapshots.close(ctx); err != nil {
+			return err
+		}
+	}
+
+	if p.Module == nil {
+		return nil
+	}
+	return p.Module.Close(ctx)
+}
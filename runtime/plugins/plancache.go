@@ -0,0 +1,48 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package plugins
+
+import (
+	"github.com/hypermodeinc/modus/runtime/langsupport"
+
+	"github.com/puzpuzpuz/xsync/v3"
+)
+
+// Execution plans only depend on the plugin's metadata and wasm function signatures,
+// so they are cached by plugin name and reused when the same build of a plugin is loaded again.
+// A different build of the plugin replaces the cached plans.  The plans are kept when the plugin is unloaded,
+// because a reload of the plugin unloads it before loading the same build again.
+type planCacheEntry struct {
+	buildId string
+	plans   map[string]langsupport.ExecutionPlan
+}
+
+var planCache = xsync.NewMapOf[string, *planCacheEntry]()
+
+func getCachedPlans(name, buildId string) (map[string]langsupport.ExecutionPlan, bool) {
+	if buildId == "" {
+		return nil, false
+	}
+
+	entry, ok := planCache.Load(name)
+	if !ok || entry.buildId != buildId {
+		return nil, false
+	}
+
+	return entry.plans, true
+}
+
+func cachePlans(name, buildId string, plans map[string]langsupport.ExecutionPlan) {
+	if buildId == "" {
+		return
+	}
+
+	planCache.Store(name, &planCacheEntry{buildId, plans})
+}
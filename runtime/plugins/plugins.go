@@ -12,6 +12,7 @@ package plugins
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/hypermodeinc/modus/lib/metadata"
 	"github.com/hypermodeinc/modus/runtime/langsupport"
@@ -30,6 +31,13 @@ type Plugin struct {
 	FileName       string
 	Language       langsupport.Language
 	ExecutionPlans map[string]langsupport.ExecutionPlan
+
+	// for lazily compiled plugins
+	compiler  Compiler
+	compileMu sync.Mutex
+
+	// for plugins whose instances are restored from snapshots
+	snapshots *snapshotter
 }
 
 func NewPlugin(ctx context.Context, cm wazero.CompiledModule, filename string, md *metadata.Metadata) (*Plugin, error) {
@@ -41,6 +49,28 @@ func NewPlugin(ctx context.Context, cm wazero.CompiledModule, filename string, m
 		return nil, err
 	}
 
+	plans, ok := getCachedPlans(md.Name(), md.BuildId)
+	if !ok {
+		plans, err = buildExecutionPlans(ctx, cm, md, language)
+		if err != nil {
+			return nil, err
+		}
+		cachePlans(md.Name(), md.BuildId, plans)
+	}
+
+	plugin := &Plugin{
+		Id:             utils.GenerateUUIDv7(),
+		Module:         cm,
+		Metadata:       md,
+		FileName:       filename,
+		Language:       language,
+		ExecutionPlans: plans,
+	}
+
+	return plugin, nil
+}
+
+func buildExecutionPlans(ctx context.Context, cm wazero.CompiledModule, md *metadata.Metadata, language langsupport.Language) (map[string]langsupport.ExecutionPlan, error) {
 	planner := language.NewPlanner(md)
 	imports := cm.ImportedFunctions()
 	exports := cm.ExportedFunctions()
@@ -83,16 +113,7 @@ func NewPlugin(ctx context.Context, cm wazero.CompiledModule, filename string, m
 		plans[importName] = plan
 	}
 
-	plugin := &Plugin{
-		Id:             utils.GenerateUUIDv7(),
-		Module:         cm,
-		Metadata:       md,
-		FileName:       filename,
-		Language:       language,
-		ExecutionPlans: plans,
-	}
-
-	return plugin, nil
+	return plans, nil
 }
 
 func (p *Plugin) NameAndVersion() (name string, version string) {
@@ -0,0 +1,294 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package plugins
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	"github.com/hypermodeinc/modus/runtime/logger"
+
+	"github.com/tetratelabs/wazero"
+	wasm "github.com/tetratelabs/wazero/api"
+)
+
+// the start functions that run the plugin's top-level code when it is instantiated
+var startFunctions = []string{"_initialize", "_start"}
+
+// the number of snapshots kept for each plugin, one for each distinct instance environment
+const maxSnapshotsPerPlugin = 16
+
+const wasmPageSize = 65536
+
+// memory is compared with its initial contents in chunks of this size, and only changed chunks are kept
+const snapshotChunkSize = 4096
+
+// snapshotter restores instances of a plugin from snapshots of the memory and globals of an instance
+// that has already run the plugin's start functions.  Instances that receive a different environment
+// get their own snapshot, so that values read from the environment at startup are never shared.
+type snapshotter struct {
+	wasmBytes []byte
+
+	initOnce sync.Once
+	initErr  error
+	module   wazero.CompiledModule
+	globals  []string
+	baseline []byte
+
+	mu        sync.Mutex
+	snapshots map[[sha256.Size]byte]*list.Element
+	order     *list.List
+}
+
+type memorySnapshot struct {
+	key        [sha256.Size]byte
+	memorySize uint32
+	chunks     []memoryChunk
+	globals    []uint64
+}
+
+type memoryChunk struct {
+	offset uint32
+	data   []byte
+}
+
+// EnableSnapshots allows instances of the plugin to be restored from memory snapshots, rather than
+// running the plugin's start functions for each instance.  This must only be used for plugins that
+// declare that their top-level code has no side effects that must happen for every instance.
+// The state of the language runtime is restored with the memory, so the seeds of its pseudo-random
+// number generators and hash functions are the same in every instance restored from a snapshot.
+// Only the random source and clocks of the host are read anew by each instance.
+func (p *Plugin) EnableSnapshots(wasmBytes []byte) {
+	p.snapshots = &snapshotter{
+		wasmBytes: wasmBytes,
+		snapshots: make(map[[sha256.Size]byte]*list.Element),
+		order:     list.New(),
+	}
+}
+
+// SnapshotsEnabled reports whether instances of the plugin may be restored from memory snapshots.
+func (p *Plugin) SnapshotsEnabled() bool {
+	return p.snapshots != nil
+}
+
+// InstantiateModule creates an instance of the plugin's module, running its start functions.
+// If snapshots are enabled, the start functions only run for the first instance with a given
+// environment, and later instances with the same environment are restored from a snapshot.
+// An empty environment disables snapshots for the instance.
+func (p *Plugin) InstantiateModule(ctx context.Context, rt wazero.Runtime, cfg wazero.ModuleConfig, env string) (wasm.Module, error) {
+	s := p.snapshots
+	if s == nil || env == "" {
+		return rt.InstantiateModule(ctx, p.Module, cfg.WithStartFunctions(startFunctions...))
+	}
+
+	if err := s.init(ctx, rt); err != nil {
+		return rt.InstantiateModule(ctx, p.Module, cfg.WithStartFunctions(startFunctions...))
+	}
+
+	key := sha256.Sum256([]byte(env))
+	if snap, ok := s.get(key); ok {
+		mod, err := rt.InstantiateModule(ctx, s.module, cfg.WithStartFunctions())
+		if err != nil {
+			return nil, err
+		}
+		if err := s.restore(mod, snap); err != nil {
+			_ = mod.Close(ctx)
+			return nil, fmt.Errorf("failed to restore snapshot of plugin %s: %w", p.Name(), err)
+		}
+		return mod, nil
+	}
+
+	mod, err := rt.InstantiateModule(ctx, s.module, cfg.WithStartFunctions(startFunctions...))
+	if err != nil {
+		return nil, err
+	}
+
+	if snap, err := s.capture(mod, key); err != nil {
+		logger.Warn(ctx).Err(err).Str("plugin", p.Name()).Msg("Failed to capture a snapshot of the plugin's memory.")
+	} else {
+		s.put(snap)
+	}
+
+	return mod, nil
+}
+
+// init compiles the module with its mutable globals exported, and records the initial contents of its memory.
+func (s *snapshotter) init(ctx context.Context, rt wazero.Runtime) error {
+	s.initOnce.Do(func() {
+		s.initErr = s.compile(ctx, rt)
+		if s.initErr != nil {
+			logger.Warn(ctx).Err(s.initErr).Msg("Plugin cannot be restored from snapshots.  Instances will run the plugin's start functions instead.")
+		}
+
+		// the original binary is no longer needed
+		s.wasmBytes = nil
+	})
+	return s.initErr
+}
+
+func (s *snapshotter) compile(ctx context.Context, rt wazero.Runtime) error {
+	wasmBytes, globals, err := exportMutableGlobals(s.wasmBytes)
+	if err != nil {
+		return err
+	}
+
+	cm, err := rt.CompileModule(ctx, wasmBytes)
+	if err != nil {
+		return err
+	}
+
+	mod, err := rt.InstantiateModule(ctx, cm, wazero.NewModuleConfig().WithName("").WithStartFunctions())
+	if err != nil {
+		_ = cm.Close(ctx)
+		return err
+	}
+	defer mod.Close(ctx)
+
+	mem := mod.Memory()
+	if mem == nil {
+		_ = cm.Close(ctx)
+		return fmt.Errorf("module has no memory")
+	}
+	baseline, _ := mem.Read(0, mem.Size())
+
+	s.module = cm
+	s.globals = globals
+	s.baseline = bytes.Clone(baseline)
+	return nil
+}
+
+// capture records the parts of the instance's memory that differ from the module's initial memory,
+// along with the values of its mutable globals.
+func (s *snapshotter) capture(mod wasm.Module, key [sha256.Size]byte) (*memorySnapshot, error) {
+	mem := mod.Memory()
+	size := mem.Size()
+	data, ok := mem.Read(0, size)
+	if !ok {
+		return nil, fmt.Errorf("unable to read memory")
+	}
+
+	snap := &memorySnapshot{key: key, memorySize: size}
+	for offset := uint32(0); offset < size; offset += snapshotChunkSize {
+		end := min(offset+snapshotChunkSize, size)
+		chunk := data[offset:end]
+		if chunkUnchanged(chunk, s.baseline, offset) {
+			continue
+		}
+
+		// extend the previous chunk if this one is adjacent to it
+		if n := len(snap.chunks); n > 0 {
+			prev := &snap.chunks[n-1]
+			if prev.offset+uint32(len(prev.data)) == offset {
+				prev.data = append(prev.data, chunk...)
+				continue
+			}
+		}
+		snap.chunks = append(snap.chunks, memoryChunk{offset: offset, data: bytes.Clone(chunk)})
+	}
+
+	snap.globals = make([]uint64, len(s.globals))
+	for i, name := range s.globals {
+		g := mod.ExportedGlobal(name)
+		if g == nil {
+			return nil, fmt.Errorf("global %s is not exported", name)
+		}
+		snap.globals[i] = g.Get()
+	}
+
+	return snap, nil
+}
+
+func chunkUnchanged(chunk, baseline []byte, offset uint32) bool {
+	if int(offset) >= len(baseline) {
+		for _, b := range chunk {
+			if b != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	end := min(int(offset)+len(chunk), len(baseline))
+	if !bytes.Equal(chunk[:end-int(offset)], baseline[offset:end]) {
+		return false
+	}
+	for _, b := range chunk[end-int(offset):] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// restore applies a snapshot to a new instance of the module, whose start functions have not been run.
+func (s *snapshotter) restore(mod wasm.Module, snap *memorySnapshot) error {
+	mem := mod.Memory()
+	if size := mem.Size(); size < snap.memorySize {
+		if _, ok := mem.Grow((snap.memorySize - size) / wasmPageSize); !ok {
+			return fmt.Errorf("unable to grow memory to %d bytes", snap.memorySize)
+		}
+	}
+
+	for _, chunk := range snap.chunks {
+		if !mem.Write(chunk.offset, chunk.data) {
+			return fmt.Errorf("unable to write memory at offset %d", chunk.offset)
+		}
+	}
+
+	for i, name := range s.globals {
+		g, ok := mod.ExportedGlobal(name).(wasm.MutableGlobal)
+		if !ok {
+			return fmt.Errorf("global %s is not mutable", name)
+		}
+		g.Set(snap.globals[i])
+	}
+
+	return nil
+}
+
+func (s *snapshotter) get(key [sha256.Size]byte) (*memorySnapshot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.snapshots[key]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(el)
+	return el.Value.(*memorySnapshot), true
+}
+
+func (s *snapshotter) put(snap *memorySnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.snapshots[snap.key]; ok {
+		el.Value = snap
+		s.order.MoveToFront(el)
+		return
+	}
+
+	s.snapshots[snap.key] = s.order.PushFront(snap)
+	for s.order.Len() > maxSnapshotsPerPlugin {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.snapshots, oldest.Value.(*memorySnapshot).key)
+	}
+}
+
+func (s *snapshotter) close(ctx context.Context) error {
+	if s.module == nil {
+		return nil
+	}
+	return s.module.Close(ctx)
+}
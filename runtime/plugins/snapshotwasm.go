@@ -0,0 +1,271 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package plugins
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	sectionImport = 2
+	sectionGlobal = 6
+	sectionExport = 7
+
+	kindMemory = 2
+	kindGlobal = 3
+)
+
+const snapshotGlobalPrefix = "__modus_snapshot_global_"
+
+var wasmMagic = []byte{0x00, 0x61, 0x73, 0x6D, 0x01, 0x00, 0x00, 0x00}
+
+// exportMutableGlobals rewrites a wasm binary so that each of its mutable globals is exported,
+// which allows their values to be captured and restored from outside of the module.
+// It returns the rewritten binary, and the export names of the globals.
+func exportMutableGlobals(wasmBytes []byte) ([]byte, []string, error) {
+	if !bytes.HasPrefix(wasmBytes, wasmMagic) {
+		return nil, nil, errors.New("invalid wasm binary")
+	}
+
+	type section struct {
+		id   byte
+		data []byte
+	}
+
+	var sections []section
+	r := &wasmReader{data: wasmBytes, offset: len(wasmMagic)}
+	for !r.done() {
+		id := r.byte()
+		size := r.uleb()
+		data := r.bytes(int(size))
+		if r.err != nil {
+			return nil, nil, fmt.Errorf("malformed wasm binary: %w", r.err)
+		}
+		sections = append(sections, section{id, data})
+	}
+
+	var importedGlobals uint64
+	var mutableGlobals []uint64
+	hasExports := false
+	for _, s := range sections {
+		switch s.id {
+		case sectionImport:
+			n, err := countImportedGlobals(s.data)
+			if err != nil {
+				return nil, nil, err
+			}
+			importedGlobals = n
+		case sectionGlobal:
+			globals, err := findMutableGlobals(s.data)
+			if err != nil {
+				return nil, nil, err
+			}
+			mutableGlobals = globals
+		case sectionExport:
+			hasExports = true
+		}
+	}
+
+	if !hasExports {
+		return nil, nil, errors.New("wasm binary has no exports")
+	}
+
+	names := make([]string, len(mutableGlobals))
+	out := append([]byte{}, wasmMagic...)
+	for _, s := range sections {
+		data := s.data
+		if s.id == sectionExport {
+			r := &wasmReader{data: data}
+			count := r.uleb()
+			if r.err != nil {
+				return nil, nil, fmt.Errorf("malformed export section: %w", r.err)
+			}
+
+			entries := data[r.offset:]
+			data = binary.AppendUvarint(nil, count+uint64(len(mutableGlobals)))
+			data = append(data, entries...)
+			for i, g := range mutableGlobals {
+				index := importedGlobals + g
+				names[i] = fmt.Sprintf("%s%d", snapshotGlobalPrefix, index)
+				data = binary.AppendUvarint(data, uint64(len(names[i])))
+				data = append(data, names[i]...)
+				data = append(data, kindGlobal)
+				data = binary.AppendUvarint(data, index)
+			}
+		}
+
+		out = append(out, s.id)
+		out = binary.AppendUvarint(out, uint64(len(data)))
+		out = append(out, data...)
+	}
+
+	return out, names, nil
+}
+
+// countImportedGlobals returns the number of globals in the import section, which precede
+// the module's own globals in the global index space.
+func countImportedGlobals(data []byte) (uint64, error) {
+	r := &wasmReader{data: data}
+	var globals uint64
+	count := r.uleb()
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		r.name()
+		r.name()
+		switch kind := r.byte(); kind {
+		case 0: // function
+			r.uleb()
+		case 1: // table
+			r.byte()
+			r.limits()
+		case kindMemory:
+			return 0, errors.New("modules that import their memory are not supported")
+		case kindGlobal:
+			r.byte()
+			r.byte()
+			globals++
+		case 4: // tag
+			r.byte()
+			r.uleb()
+		default:
+			return 0, fmt.Errorf("unknown import kind %d", kind)
+		}
+	}
+	if r.err != nil {
+		return 0, fmt.Errorf("malformed import section: %w", r.err)
+	}
+	return globals, nil
+}
+
+// findMutableGlobals returns the indexes of the mutable globals defined in the global section.
+func findMutableGlobals(data []byte) ([]uint64, error) {
+	r := &wasmReader{data: data}
+	var results []uint64
+	count := r.uleb()
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		valType := r.byte()
+		mutable := r.byte() == 1
+		if err := r.skipConstExpr(); err != nil {
+			return nil, err
+		}
+		if !mutable {
+			continue
+		}
+		switch valType {
+		case 0x7F, 0x7E, 0x7D, 0x7C: // i32, i64, f32, f64
+			results = append(results, i)
+		default:
+			return nil, fmt.Errorf("mutable globals of type 0x%x are not supported", valType)
+		}
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("malformed global section: %w", r.err)
+	}
+	return results, nil
+}
+
+type wasmReader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+var errUnexpectedEnd = errors.New("unexpected end of data")
+
+func (r *wasmReader) done() bool {
+	return r.err != nil || r.offset >= len(r.data)
+}
+
+func (r *wasmReader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if r.offset >= len(r.data) {
+		r.err = errUnexpectedEnd
+		return 0
+	}
+	b := r.data[r.offset]
+	r.offset++
+	return b
+}
+
+func (r *wasmReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.offset+n > len(r.data) {
+		r.err = errUnexpectedEnd
+		return nil
+	}
+	b := r.data[r.offset : r.offset+n]
+	r.offset += n
+	return b
+}
+
+func (r *wasmReader) uleb() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data[r.offset:])
+	if n <= 0 {
+		r.err = errUnexpectedEnd
+		return 0
+	}
+	r.offset += n
+	return v
+}
+
+// sleb skips a signed LEB128 value, which is encoded with the same continuation bits as an unsigned one.
+func (r *wasmReader) sleb() {
+	r.uleb()
+}
+
+func (r *wasmReader) name() {
+	r.bytes(int(r.uleb()))
+}
+
+func (r *wasmReader) limits() {
+	flags := r.byte()
+	r.uleb()
+	if flags&1 != 0 {
+		r.uleb()
+	}
+}
+
+// skipConstExpr skips a constant expression, such as the initializer of a global.
+func (r *wasmReader) skipConstExpr() error {
+	for r.err == nil {
+		switch op := r.byte(); op {
+		case 0x0B: // end
+			return nil
+		case 0x41, 0x42: // i32.const, i64.const
+			r.sleb()
+		case 0x43: // f32.const
+			r.bytes(4)
+		case 0x44: // f64.const
+			r.bytes(8)
+		case 0x23, 0xD2: // global.get, ref.func
+			r.uleb()
+		case 0xD0: // ref.null
+			r.sleb()
+		case 0x6A, 0x6B, 0x6C, 0x7C, 0x7D, 0x7E: // extended constant arithmetic
+		case 0xFD: // v128.const
+			if r.uleb() != 12 {
+				return errors.New("unsupported instruction in constant expression")
+			}
+			r.bytes(16)
+		default:
+			return fmt.Errorf("unsupported instruction 0x%x in constant expression", op)
+		}
+	}
+	return r.err
+}
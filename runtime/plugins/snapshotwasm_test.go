@@ -0,0 +1,127 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package plugins
+
+import (
+	"bytes"
+	"testing"
+)
+
+// buildTestModule returns a minimal wasm module that imports one global,
+// defines a mutable and an immutable global, and exports its memory.
+func buildTestModule(withExports bool) []byte {
+	section := func(id byte, data ...byte) []byte {
+		return append([]byte{id, byte(len(data))}, data...)
+	}
+
+	b := append([]byte{}, wasmMagic...)
+	b = append(b, section(1, 0x01, 0x60, 0x00, 0x00)...)                                       // type: () -> ()
+	b = append(b, section(2, 0x01, 0x03, 'e', 'n', 'v', 0x01, 'g', kindGlobal, 0x7F, 0x00)...) // import: env.g i32
+	b = append(b, section(3, 0x01, 0x00)...)                                                   // function
+	b = append(b, section(5, 0x01, 0x00, 0x01)...)                                             // memory: min 1 page
+	b = append(b, section(6, 0x02,
+		0x7F, 0x01, 0x41, 0x05, 0x0B, // i32 mutable = 5
+		0x7E, 0x00, 0x42, 0x01, 0x0B, // i64 immutable = 1
+	)...)
+	if withExports {
+		b = append(b, section(7, 0x01, 0x06, 'm', 'e', 'm', 'o', 'r', 'y', kindMemory, 0x00)...)
+	}
+	b = append(b, section(10, 0x01, 0x02, 0x00, 0x0B)...) // code: empty body
+	return b
+}
+
+func TestExportMutableGlobals(t *testing.T) {
+	original := buildTestModule(true)
+	result, names, err := exportMutableGlobals(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(names) != 1 || names[0] != "__modus_snapshot_global_1" {
+		t.Fatalf("unexpected global names: %v", names)
+	}
+
+	// find the export section in the result, and check its entries
+	r := &wasmReader{data: result, offset: len(wasmMagic)}
+	var exports []byte
+	for !r.done() {
+		id := r.byte()
+		data := r.bytes(int(r.uleb()))
+		if id == sectionExport {
+			exports = data
+		}
+	}
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+
+	er := &wasmReader{data: exports}
+	if count := er.uleb(); count != 2 {
+		t.Fatalf("expected 2 exports, got %d", count)
+	}
+	if name := er.bytes(int(er.uleb())); string(name) != "memory" {
+		t.Errorf("expected memory export first, got %s", name)
+	}
+	er.byte()
+	er.uleb()
+	if name := er.bytes(int(er.uleb())); string(name) != names[0] {
+		t.Errorf("expected %s export, got %s", names[0], name)
+	}
+	if kind := er.byte(); kind != kindGlobal {
+		t.Errorf("expected global export, got kind %d", kind)
+	}
+	if index := er.uleb(); index != 1 {
+		t.Errorf("expected global index 1, got %d", index)
+	}
+	if er.err != nil || !er.done() {
+		t.Error("malformed export section")
+	}
+
+	// the other sections must be unchanged
+	if !bytes.HasPrefix(result, original[:bytes.Index(original, []byte{sectionExport, 0x0A})]) {
+		t.Error("sections before the export section were changed")
+	}
+}
+
+func TestExportMutableGlobalsErrors(t *testing.T) {
+	if _, _, err := exportMutableGlobals([]byte("not wasm")); err == nil {
+		t.Error("expected an error for an invalid binary")
+	}
+	if _, _, err := exportMutableGlobals(buildTestModule(false)); err == nil {
+		t.Error("expected an error for a module without exports")
+	}
+
+	truncated := buildTestModule(true)
+	if _, _, err := exportMutableGlobals(truncated[:len(truncated)-2]); err == nil {
+		t.Error("expected an error for a truncated binary")
+	}
+}
+
+func TestChunkUnchanged(t *testing.T) {
+	baseline := []byte{1, 2, 3, 4}
+
+	tests := []struct {
+		chunk     []byte
+		offset    uint32
+		unchanged bool
+	}{
+		{[]byte{1, 2}, 0, true},
+		{[]byte{1, 9}, 0, false},
+		{[]byte{3, 4, 0, 0}, 2, true},
+		{[]byte{3, 4, 0, 1}, 2, false},
+		{[]byte{0, 0}, 8, true},
+		{[]byte{0, 7}, 8, false},
+	}
+	for _, tt := range tests {
+		if actual := chunkUnchanged(tt.chunk, baseline, tt.offset); actual != tt.unchanged {
+			t.Errorf("chunkUnchanged(%v, %d) = %v, expected %v", tt.chunk, tt.offset, actual, tt.unchanged)
+		}
+	}
+}
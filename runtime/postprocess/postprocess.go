@@ -0,0 +1,177 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package postprocess applies the post-processing steps declared in the manifest to function results,
+// before they are serialized into the GraphQL response.
+package postprocess
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/tidwall/gjson"
+)
+
+type step struct {
+	info     manifest.PostProcessorInfo
+	template *template.Template
+}
+
+var pipelines map[string][]*step
+var mu sync.RWMutex
+
+func Initialize() {
+	manifestdata.RegisterManifestLoadedCallback(loadPostProcessors)
+}
+
+func loadPostProcessors(ctx context.Context) error {
+	man := manifestdata.GetManifest()
+
+	results := make(map[string][]*step, len(man.PostProcessors))
+	for fnName, infos := range man.PostProcessors {
+		steps := make([]*step, 0, len(infos))
+		for i, info := range infos {
+			s := &step{info: info}
+			if info.Type == manifest.PostProcessorTypeTemplate {
+				t, err := template.New(fnName).Option("missingkey=zero").Parse(info.Template)
+				if err != nil {
+					return fmt.Errorf("invalid template in post-processing step %d for function %s: %w", i+1, fnName, err)
+				}
+				s.template = t
+			}
+			steps = append(steps, s)
+		}
+		results[fnName] = steps
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	pipelines = results
+	return nil
+}
+
+// Apply runs the post-processing steps declared for the function on its result, and returns the final value.
+// If the function has no post-processing steps, the result is returned unchanged.
+func Apply(ctx context.Context, fnName string, result any) (any, error) {
+	mu.RLock()
+	steps := pipelines[fnName]
+	mu.RUnlock()
+
+	for i, s := range steps {
+		var err error
+		result, err = s.apply(result)
+		if err != nil {
+			return nil, fmt.Errorf("post-processing step %d (%s) failed: %w", i+1, s.info.Type, err)
+		}
+	}
+	return result, nil
+}
+
+func (s *step) apply(value any) (any, error) {
+	switch s.info.Type {
+	case manifest.PostProcessorTypeProject:
+		return project(value, s.info.Path)
+	case manifest.PostProcessorTypeTemplate:
+		return render(value, s.template)
+	case manifest.PostProcessorTypeTruncate:
+		v, err := normalize(value)
+		if err != nil {
+			return nil, err
+		}
+		return truncate(v, s.info.MaxLength), nil
+	default:
+		return nil, fmt.Errorf("unknown post-processor type: %s", s.info.Type)
+	}
+}
+
+// project returns the value at the path.  A string that contains JSON is parsed first,
+// so that paths can reach into JSON text returned by a function, such as model output.
+func project(value any, path string) (any, error) {
+	var data []byte
+	if s, ok := value.(string); ok && gjson.Valid(s) {
+		data = []byte(s)
+	} else {
+		var err error
+		data, err = utils.JsonSerialize(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r := gjson.GetBytes(data, path)
+	if !r.Exists() {
+		return nil, nil
+	}
+	return r.Value(), nil
+}
+
+// render executes the template with the value as its data.
+func render(value any, t *template.Template) (any, error) {
+	v, err := normalize(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	if err := t.Execute(&sb, v); err != nil {
+		return nil, err
+	}
+	return sb.String(), nil
+}
+
+// truncate shortens every string and array in the value to at most maxLength characters or items.
+func truncate(value any, maxLength int) any {
+	switch v := value.(type) {
+	case string:
+		if r := []rune(v); len(r) > maxLength {
+			return string(r[:maxLength])
+		}
+		return v
+	case []any:
+		if len(v) > maxLength {
+			v = v[:maxLength]
+		}
+		for i, item := range v {
+			v[i] = truncate(item, maxLength)
+		}
+		return v
+	case map[string]any:
+		for k, item := range v {
+			v[k] = truncate(item, maxLength)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// normalize converts a function result to plain JSON values, so that steps don't depend on how it was decoded.
+func normalize(value any) (any, error) {
+	switch value.(type) {
+	case nil, string, bool, float64:
+		return value, nil
+	}
+
+	data, err := utils.JsonSerialize(value)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := utils.JsonDeserialize(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package postprocess
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostProcessors(t *testing.T) {
+	ctx := context.Background()
+	manifestdata.SetManifest(&manifest.Manifest{
+		PostProcessors: map[string][]manifest.PostProcessorInfo{
+			"summarize": {
+				{Type: manifest.PostProcessorTypeProject, Path: "summary"},
+				{Type: manifest.PostProcessorTypeTruncate, MaxLength: 5},
+			},
+			"greet": {
+				{Type: manifest.PostProcessorTypeTemplate, Template: "Hello, {{.name}}!"},
+			},
+			"listItems": {
+				{Type: manifest.PostProcessorTypeTruncate, MaxLength: 2},
+			},
+		},
+	})
+	require.NoError(t, loadPostProcessors(ctx))
+
+	// JSON text is parsed before projecting
+	result, err := Apply(ctx, "summarize", `{"summary":"a long summary","score":3}`)
+	require.NoError(t, err)
+	assert.Equal(t, "a lon", result)
+
+	result, err = Apply(ctx, "greet", map[string]any{"name": "World"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, World!", result)
+
+	result, err = Apply(ctx, "listItems", []string{"one", "two", "three"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"on", "tw"}, result)
+
+	// results of functions without post-processors are unchanged
+	result, err = Apply(ctx, "other", 42)
+	require.NoError(t, err)
+	assert.Equal(t, 42, result)
+}
+
+func TestInvalidTemplate(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{
+		PostProcessors: map[string][]manifest.PostProcessorInfo{
+			"greet": {
+				{Type: manifest.PostProcessorTypeTemplate, Template: "Hello, {{.name"},
+			},
+		},
+	})
+	assert.Error(t, loadPostProcessors(context.Background()))
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package privacy implements the deletion of a data subject's data, for right-to-be-forgotten requests.
+package privacy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/collections"
+	"github.com/hypermodeinc/modus/runtime/db"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// SubjectDataRequest identifies the data of a data subject.  Collection items are matched by key pattern,
+// in which * and ? are wildcards, by label, or both.  The identifiers, such as the subject's email address
+// or user id, are used to find the subject's records in the stored inference history, feedback and outbox.
+type SubjectDataRequest struct {
+	KeyPattern  string   `json:"keyPattern,omitempty"`
+	Label       string   `json:"label,omitempty"`
+	Identifiers []string `json:"identifiers,omitempty"`
+}
+
+// DeleteSubjectData permanently deletes the data of a data subject from all collections and from the stored logs,
+// and returns a signed report of what was deleted.  The report records hashes of the identifiers, not the identifiers.
+// Logs already written to the runtime's output are not affected.
+func DeleteSubjectData(ctx context.Context, request *SubjectDataRequest) (*DeletionReport, error) {
+	if request.KeyPattern == "" && request.Label == "" && len(request.Identifiers) == 0 {
+		return nil, errors.New("a key pattern, label, or identifiers are required")
+	}
+	for _, id := range request.Identifiers {
+		if id == "" {
+			return nil, errors.New("identifiers must not be empty")
+		}
+	}
+
+	// ensure the report can be signed before deleting anything
+	key, err := getSigningKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DeletionReport{
+		Id:               utils.GenerateUUIDv7(),
+		KeyPattern:       request.KeyPattern,
+		Label:            request.Label,
+		IdentifierHashes: hashIdentifiers(request.Identifiers),
+		StartedAt:        time.Now().UTC(),
+		Collections:      []*collections.SubjectDeletionObject{},
+		Logs:             map[string]int64{},
+	}
+
+	if request.KeyPattern != "" || request.Label != "" {
+		results, err := collections.DeleteSubjectData(ctx, request.KeyPattern, request.Label)
+		if err != nil {
+			return nil, err
+		}
+		report.Collections = results
+		for _, r := range results {
+			if r.Error != "" {
+				report.Errors = append(report.Errors, r.Collection+": "+r.Error)
+			}
+		}
+	}
+
+	if len(request.Identifiers) > 0 {
+		counts, err := db.ScrubSubjectData(ctx, request.Identifiers)
+		if err == nil {
+			report.Logs = counts
+		} else if !db.IsNotConfigured(err) {
+			logger.Err(ctx, err).Str("report_id", report.Id).Msg("Failed to delete data subject records from the database.")
+			report.Errors = append(report.Errors, "logs: "+err.Error())
+		}
+	}
+
+	report.CompletedAt = time.Now().UTC()
+	if err := report.sign(key); err != nil {
+		return nil, err
+	}
+
+	items := 0
+	for _, r := range report.Collections {
+		items += r.Count
+	}
+	records := int64(0)
+	for _, n := range report.Logs {
+		records += n
+	}
+
+	// audit record
+	logger.Info(ctx).
+		Str("log_type", "subject_deletion").
+		Str("report_id", report.Id).
+		Int("collection_items", items).
+		Int64("log_records", records).
+		Int("errors", len(report.Errors)).
+		Bool("user_visible", true).
+		Msg("Deleted data subject data.")
+
+	return report, nil
+}
+
+func hashIdentifiers(identifiers []string) []string {
+	hashes := make([]string, len(identifiers))
+	for i, id := range identifiers {
+		h := sha256.Sum256([]byte(id))
+		hashes[i] = hex.EncodeToString(h[:])
+	}
+	return hashes
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package privacy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/collections"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+const signingKeyEnvVar = "MODUS_DELETION_REPORT_KEY"
+const signatureAlgorithm = "HMAC-SHA256"
+
+// DeletionReport records what was deleted for a data subject.  It is signed with the deletion report key,
+// so that it can later be shown to be an unaltered report produced by the runtime.
+type DeletionReport struct {
+	Id               string                               `json:"id"`
+	KeyPattern       string                               `json:"keyPattern,omitempty"`
+	Label            string                               `json:"label,omitempty"`
+	IdentifierHashes []string                             `json:"identifierHashes,omitempty"`
+	StartedAt        time.Time                            `json:"startedAt"`
+	CompletedAt      time.Time                            `json:"completedAt"`
+	Collections      []*collections.SubjectDeletionObject `json:"collections"`
+	Logs             map[string]int64                     `json:"logs"`
+	Errors           []string                             `json:"errors,omitempty"`
+	Signature        *ReportSignature                     `json:"signature,omitempty"`
+}
+
+type ReportSignature struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+}
+
+var devSigningKey = sync.OnceValue(func() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return key
+})
+
+// getSigningKey returns the key used to sign deletion reports.  In the dev environment, a key is generated
+// for the lifetime of the process when none is configured.
+func getSigningKey(ctx context.Context) ([]byte, error) {
+	if key := os.Getenv(signingKeyEnvVar); key != "" {
+		return []byte(key), nil
+	}
+
+	if config.IsDevEnvironment() {
+		logger.Warn(ctx).Msgf("%s is not set.  Deletion reports are signed with a temporary key.", signingKeyEnvVar)
+		return devSigningKey(), nil
+	}
+
+	return nil, fmt.Errorf("%s must be set to sign deletion reports", signingKeyEnvVar)
+}
+
+// computeSignature returns the signature of the report's contents, excluding any existing signature.
+func (r *DeletionReport) computeSignature(key []byte) ([]byte, error) {
+	unsigned := *r
+	unsigned.Signature = nil
+	data, err := utils.JsonSerialize(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+func (r *DeletionReport) sign(key []byte) error {
+	sig, err := r.computeSignature(key)
+	if err != nil {
+		return err
+	}
+	r.Signature = &ReportSignature{
+		Algorithm: signatureAlgorithm,
+		Value:     base64.StdEncoding.EncodeToString(sig),
+	}
+	return nil
+}
+
+// VerifyReport reports whether the report is unaltered since it was signed by this runtime.
+func VerifyReport(ctx context.Context, report *DeletionReport) (bool, error) {
+	if report.Signature == nil {
+		return false, errors.New("the report is not signed")
+	}
+	if report.Signature.Algorithm != signatureAlgorithm {
+		return false, fmt.Errorf("unsupported signature algorithm: %s", report.Signature.Algorithm)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(report.Signature.Value)
+	if err != nil {
+		return false, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	key, err := getSigningKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	expected, err := report.computeSignature(key)
+	if err != nil {
+		return false, err
+	}
+	return hmac.Equal(sig, expected), nil
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package privacy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/collections"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportSignature(t *testing.T) {
+	t.Setenv(signingKeyEnvVar, "test-key")
+	ctx := context.Background()
+
+	key, err := getSigningKey(ctx)
+	require.NoError(t, err)
+
+	report := &DeletionReport{
+		Id:               "report-1",
+		KeyPattern:       "user-42:*",
+		IdentifierHashes: hashIdentifiers([]string{"jane@example.com"}),
+		StartedAt:        time.Now().UTC(),
+		CompletedAt:      time.Now().UTC(),
+		Collections: []*collections.SubjectDeletionObject{
+			{Collection: "docs", Namespace: "", Count: 3},
+		},
+		Logs: map[string]int64{"inferences": 2, "feedback": 1},
+	}
+	require.NoError(t, report.sign(key))
+	require.NotNil(t, report.Signature)
+	assert.Equal(t, signatureAlgorithm, report.Signature.Algorithm)
+
+	// the report is verified as it is received by a client
+	data, err := utils.JsonSerialize(report)
+	require.NoError(t, err)
+	var received DeletionReport
+	require.NoError(t, utils.JsonDeserialize(data, &received))
+
+	valid, err := VerifyReport(ctx, &received)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	received.Collections[0].Count = 4
+	valid, err = VerifyReport(ctx, &received)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	t.Setenv(signingKeyEnvVar, "other-key")
+	received.Collections[0].Count = 3
+	valid, err = VerifyReport(ctx, &received)
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestVerifyUnsignedReport(t *testing.T) {
+	_, err := VerifyReport(context.Background(), &DeletionReport{Id: "report-1"})
+	assert.Error(t, err)
+}
+
+func TestHashIdentifiers(t *testing.T) {
+	hashes := hashIdentifiers([]string{"jane@example.com", "jane@example.com", "bob"})
+	require.Len(t, hashes, 3)
+	assert.Equal(t, hashes[0], hashes[1])
+	assert.NotEqual(t, hashes[0], hashes[2])
+	assert.Len(t, hashes[0], 64)
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package progress relays progress events reported by long-running functions to the clients that are waiting on them.
+// Events are published to a stream keyed by the execution id of the function call, and also by the request id
+// given by the caller in the X-Request-Id header, so that a client can subscribe before the execution id is known.
+// Recent events are retained for a short time after the function completes, so late subscribers can replay them.
+package progress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+const (
+	maxMessageLength  = 1024
+	maxEventsRetained = 100
+	subscriberBuffer  = 16
+)
+
+// retention is how long a completed stream is kept for late subscribers.
+var retention = time.Minute
+
+// Event is a progress event reported by a function.
+type Event struct {
+	ExecutionId string    `json:"executionId"`
+	Function    string    `json:"function,omitempty"`
+	Percent     float64   `json:"percent"`
+	Message     string    `json:"message,omitempty"`
+	Time        time.Time `json:"time"`
+	Done        bool      `json:"done,omitempty"`
+}
+
+type stream struct {
+	events      []Event
+	subscribers map[chan Event]struct{}
+	done        bool
+}
+
+var streams = make(map[string]*stream)
+var mu sync.Mutex
+
+// Report publishes a progress event for the function executing in the context.
+// The percentage must be between 0 and 100.
+func Report(ctx context.Context, percent float64, message string) error {
+	if math.IsNaN(percent) || percent < 0 || percent > 100 {
+		return fmt.Errorf("progress percentage %v is out of range, must be between 0 and 100", percent)
+	}
+	if utf8.RuneCountInString(message) > maxMessageLength {
+		return fmt.Errorf("progress message is too long, must be at most %d characters", maxMessageLength)
+	}
+
+	executionId, _ := ctx.Value(utils.ExecutionIdContextKey).(string)
+	if executionId == "" {
+		return errors.New("progress can only be reported while executing a function")
+	}
+	fnName, _ := ctx.Value(utils.FunctionNameContextKey).(string)
+
+	ev := Event{
+		ExecutionId: executionId,
+		Function:    fnName,
+		Percent:     percent,
+		Message:     message,
+		Time:        time.Now().UTC(),
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, key := range streamKeys(ctx, executionId) {
+		publish(getStream(key), ev)
+	}
+	return nil
+}
+
+// ReportFromFunction reports progress for a function through the host function.
+func ReportFromFunction(ctx context.Context, percent float64, message string) (bool, error) {
+	if err := Report(ctx, percent, message); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Finish publishes the final event for a function execution, and ends its streams.
+// It is called when the function completes, whether or not it reported any progress.
+func Finish(ctx context.Context, executionId string) {
+	if executionId == "" {
+		return
+	}
+
+	fnName, _ := ctx.Value(utils.FunctionNameContextKey).(string)
+	ev := Event{
+		ExecutionId: executionId,
+		Function:    fnName,
+		Percent:     100,
+		Time:        time.Now().UTC(),
+		Done:        true,
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, key := range streamKeys(ctx, executionId) {
+		s, ok := streams[key]
+		if !ok || s.done {
+			continue
+		}
+		publish(s, ev)
+		s.done = true
+		for ch := range s.subscribers {
+			close(ch)
+		}
+		s.subscribers = nil
+
+		time.AfterFunc(retention, func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if streams[key] == s {
+				delete(streams, key)
+			}
+		})
+	}
+}
+
+// Subscribe returns the events already published to the stream with the given key, and a channel that receives
+// new events until the stream ends.  The channel is closed when the stream ends, or when cancel is called.
+// A slow subscriber may miss events, but always receives the final event.
+func Subscribe(key string) (replay []Event, events <-chan Event, cancel func()) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s := getStream(key)
+	replay = append([]Event(nil), s.events...)
+
+	ch := make(chan Event, subscriberBuffer)
+	if s.done {
+		close(ch)
+		return replay, ch, func() {}
+	}
+	s.subscribers[ch] = struct{}{}
+
+	cancel = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := s.subscribers[ch]; !ok {
+			return
+		}
+		delete(s.subscribers, ch)
+		close(ch)
+		if len(s.subscribers) == 0 && len(s.events) == 0 && streams[key] == s {
+			delete(streams, key)
+		}
+	}
+	return replay, ch, cancel
+}
+
+func streamKeys(ctx context.Context, executionId string) []string {
+	keys := []string{executionId}
+	if md, ok := ctx.Value(utils.CallerMetadataContextKey).(map[string]string); ok {
+		if id := md["request_id"]; id != "" && id != executionId {
+			keys = append(keys, id)
+		}
+	}
+	return keys
+}
+
+// getStream must be called with mu held.
+func getStream(key string) *stream {
+	s, ok := streams[key]
+	if !ok {
+		s = &stream{subscribers: make(map[chan Event]struct{})}
+		streams[key] = s
+	}
+	return s
+}
+
+// publish must be called with mu held.
+func publish(s *stream, ev Event) {
+	if s.done {
+		return
+	}
+
+	if len(s.events) == maxEventsRetained {
+		s.events = append(s.events[:0], s.events[1:]...)
+	}
+	s.events = append(s.events, ev)
+
+	for ch := range s.subscribers {
+		if ev.Done {
+			// make room for the final event, so that every subscriber receives it
+			select {
+			case <-ch:
+			default:
+			}
+		}
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
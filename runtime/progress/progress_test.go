@@ -0,0 +1,116 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package progress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func executionContext(t *testing.T, executionId, requestId string) context.Context {
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		clear(streams)
+	})
+
+	ctx := context.WithValue(context.Background(), utils.ExecutionIdContextKey, executionId)
+	ctx = context.WithValue(ctx, utils.FunctionNameContextKey, "myFunction")
+	if requestId != "" {
+		ctx = context.WithValue(ctx, utils.CallerMetadataContextKey, map[string]string{"request_id": requestId})
+	}
+	return ctx
+}
+
+func TestProgressIsStreamedUntilFinished(t *testing.T) {
+	ctx := executionContext(t, "exec1", "")
+
+	require.NoError(t, Report(ctx, 10, "started"))
+
+	replay, events, cancel := Subscribe("exec1")
+	defer cancel()
+	require.Len(t, replay, 1)
+	require.Equal(t, "started", replay[0].Message)
+	require.Equal(t, "myFunction", replay[0].Function)
+
+	require.NoError(t, Report(ctx, 50, "halfway"))
+	ev := <-events
+	require.Equal(t, 50.0, ev.Percent)
+	require.Equal(t, "halfway", ev.Message)
+
+	Finish(ctx, "exec1")
+	ev = <-events
+	require.True(t, ev.Done)
+	require.Equal(t, "exec1", ev.ExecutionId)
+	_, ok := <-events
+	require.False(t, ok)
+
+	// a late subscriber replays the events of the finished stream
+	replay, events, cancel2 := Subscribe("exec1")
+	defer cancel2()
+	require.Len(t, replay, 3)
+	require.True(t, replay[2].Done)
+	_, ok = <-events
+	require.False(t, ok)
+}
+
+func TestProgressIsStreamedByRequestId(t *testing.T) {
+	ctx := executionContext(t, "exec2", "req2")
+
+	_, events, cancel := Subscribe("req2")
+	defer cancel()
+
+	require.NoError(t, Report(ctx, 25, "working"))
+	ev := <-events
+	require.Equal(t, "exec2", ev.ExecutionId)
+
+	Finish(ctx, "exec2")
+	ev = <-events
+	require.True(t, ev.Done)
+}
+
+func TestSlowSubscriberReceivesFinalEvent(t *testing.T) {
+	ctx := executionContext(t, "exec3", "")
+
+	_, events, cancel := Subscribe("exec3")
+	defer cancel()
+
+	for i := range subscriberBuffer * 2 {
+		require.NoError(t, Report(ctx, float64(i), ""))
+	}
+	Finish(ctx, "exec3")
+
+	var last Event
+	for ev := range events {
+		last = ev
+	}
+	require.True(t, last.Done)
+}
+
+func TestReportValidation(t *testing.T) {
+	ctx := executionContext(t, "exec4", "")
+
+	require.ErrorContains(t, Report(ctx, -1, ""), "out of range")
+	require.ErrorContains(t, Report(ctx, 101, ""), "out of range")
+	require.ErrorContains(t, Report(context.Background(), 1, ""), "while executing a function")
+}
+
+func TestCancelledSubscriptionIsRemoved(t *testing.T) {
+	_, _, cancel := Subscribe("unused")
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotContains(t, streams, "unused")
+}
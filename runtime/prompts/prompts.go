@@ -0,0 +1,123 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/experiments"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+)
+
+var placeholderRegex = regexp.MustCompile(`{{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*}}`)
+
+// RenderPrompt renders the named prompt template from the manifest, substituting the given variables.
+// If the version is empty, the version is chosen by the experiment on the prompt, if there is one.
+// Otherwise the prompt's default version is used, or the highest version if no default is set.
+func RenderPrompt(ctx context.Context, name, version string, variables map[string]string) (string, error) {
+	prompt, ok := manifestdata.GetManifest().Prompts[name]
+	if !ok {
+		return "", fmt.Errorf("prompt %s was not found", name)
+	}
+
+	if version == "" {
+		if a := experiments.ForPrompt(ctx, name); a != nil && a.Variant.Version != "" {
+			version = a.Variant.Version
+		} else {
+			version = getDefaultVersion(&prompt)
+		}
+	}
+
+	pv, ok := prompt.Versions[version]
+	if !ok {
+		return "", fmt.Errorf("version %s of prompt %s was not found", version, name)
+	}
+
+	if err := validateVariables(&pv, variables); err != nil {
+		return "", fmt.Errorf("invalid variables for version %s of prompt %s: %w", version, name, err)
+	}
+
+	var missing []string
+	result := placeholderRegex.ReplaceAllStringFunc(pv.Template, func(match string) string {
+		key := placeholderRegex.FindStringSubmatch(match)[1]
+		if val, ok := variables[key]; ok {
+			return val
+		}
+		if !slices.Contains(missing, key) {
+			missing = append(missing, key)
+		}
+		return match
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing values for variables in version %s of prompt %s: %s", version, name, strings.Join(missing, ", "))
+	}
+
+	return result, nil
+}
+
+func validateVariables(pv *manifest.PromptVersionInfo, variables map[string]string) error {
+	if len(pv.Variables) == 0 {
+		return nil
+	}
+
+	for _, v := range pv.Variables {
+		if _, ok := variables[v]; !ok {
+			return fmt.Errorf("variable %s is required", v)
+		}
+	}
+
+	for k := range variables {
+		if !slices.Contains(pv.Variables, k) {
+			return fmt.Errorf("variable %s is not accepted", k)
+		}
+	}
+
+	return nil
+}
+
+func getDefaultVersion(prompt *manifest.PromptInfo) string {
+	if prompt.DefaultVersion != "" {
+		return prompt.DefaultVersion
+	}
+
+	latest := ""
+	for v := range prompt.Versions {
+		if latest == "" || compareVersions(v, latest) > 0 {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// compareVersions compares dotted version strings such as "2" or "1.10",
+// numerically where possible and lexically otherwise.
+func compareVersions(a, b string) int {
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		if errA == nil && errB == nil {
+			if na != nb {
+				return na - nb
+			}
+		} else if c := strings.Compare(pa[i], pb[i]); c != 0 {
+			return c
+		}
+	}
+	return len(pa) - len(pb)
+}
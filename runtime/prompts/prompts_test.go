@@ -0,0 +1,99 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package prompts
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	manifestdata.SetManifest(&manifest.Manifest{
+		Prompts: map[string]manifest.PromptInfo{
+			"greeting": {
+				Name: "greeting",
+				Versions: map[string]manifest.PromptVersionInfo{
+					"1":  {Template: "Hello {{name}}."},
+					"2":  {Template: "Hi {{ name }}, welcome to {{place}}!", Variables: []string{"name", "place"}},
+					"10": {Template: "Hey {{name}}."},
+				},
+			},
+			"pinned": {
+				Name:           "pinned",
+				DefaultVersion: "1",
+				Versions: map[string]manifest.PromptVersionInfo{
+					"1": {Template: "one"},
+					"2": {Template: "two"},
+				},
+			},
+			"trial": {
+				Name:           "trial",
+				DefaultVersion: "1",
+				Versions: map[string]manifest.PromptVersionInfo{
+					"1": {Template: "one"},
+					"2": {Template: "two"},
+				},
+			},
+		},
+		Experiments: map[string]manifest.ExperimentInfo{
+			"trial-test": {
+				Name:   "trial-test",
+				Prompt: "trial",
+				Variants: []manifest.ExperimentVariantInfo{
+					{Name: "control", Weight: 0, Version: "1"},
+					{Name: "candidate", Weight: 1, Version: "2"},
+				},
+			},
+		},
+	})
+
+	os.Exit(m.Run())
+}
+
+func TestRenderPrompt(t *testing.T) {
+	tests := []struct {
+		desc      string
+		name      string
+		version   string
+		variables map[string]string
+		expected  string
+		valid     bool
+	}{
+		{"specific version", "greeting", "1", map[string]string{"name": "Ann"}, "Hello Ann.", true},
+		{"declared variables", "greeting", "2", map[string]string{"name": "Ann", "place": "Modus"}, "Hi Ann, welcome to Modus!", true},
+		{"highest version by default", "greeting", "", map[string]string{"name": "Ann"}, "Hey Ann.", true},
+		{"default version", "pinned", "", nil, "one", true},
+		{"version chosen by experiment", "trial", "", nil, "two", true},
+		{"specific version in experiment", "trial", "1", nil, "one", true},
+		{"missing declared variable", "greeting", "2", map[string]string{"name": "Ann"}, "", false},
+		{"undeclared variable", "greeting", "2", map[string]string{"name": "Ann", "place": "Modus", "x": "y"}, "", false},
+		{"missing placeholder value", "greeting", "1", nil, "", false},
+		{"unknown version", "greeting", "3", nil, "", false},
+		{"unknown prompt", "missing", "", nil, "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			result, err := RenderPrompt(context.Background(), tc.name, tc.version, tc.variables)
+			if tc.valid {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, result)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
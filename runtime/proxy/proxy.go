@@ -0,0 +1,105 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package proxy resolves the proxies used for outbound traffic from the runtime.
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/secrets"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// NoProxy is the value of a connection's proxy setting that bypasses all proxies for that connection.
+const NoProxy = "none"
+
+var getProxyFunc = sync.OnceValue(func() func(*url.URL) (*url.URL, error) {
+	return proxyConfig().ProxyFunc()
+})
+
+func proxyConfig() *httpproxy.Config {
+	cfg := httpproxy.FromEnvironment()
+	if config.HttpProxy != "" {
+		cfg.HTTPProxy = config.HttpProxy
+		cfg.HTTPSProxy = config.HttpProxy
+	}
+	if config.HttpsProxy != "" {
+		cfg.HTTPSProxy = config.HttpsProxy
+	}
+	if config.NoProxy != "" {
+		cfg.NoProxy = config.NoProxy
+	}
+	return cfg
+}
+
+// FromConfig returns the proxy to use for the request, according to the runtime's proxy settings
+// and the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables.  It has the signature
+// of http.Transport.Proxy, and returns nil when the request should not use a proxy.
+func FromConfig(req *http.Request) (*url.URL, error) {
+	return getProxyFunc()(req.URL)
+}
+
+// Initialize applies the runtime's proxy settings to the default HTTP transport,
+// so that they are honored by clients that don't set their own transport.
+func Initialize() {
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		t.Proxy = FromConfig
+	}
+}
+
+// ForConnection returns the proxy function for requests made through the connection.
+// The connection's own proxy is used if it has one, in which case the global NO_PROXY rules
+// don't apply.  Otherwise, the runtime's proxy settings are used.
+func ForConnection(ctx context.Context, connection *manifest.HTTPConnectionInfo) (func(*http.Request) (*url.URL, error), error) {
+	switch connection.Proxy {
+	case "":
+		return FromConfig, nil
+	case NoProxy:
+		return nil, nil
+	}
+
+	value, err := secrets.ApplySecretsToString(ctx, connection, connection.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyUrl, err := parseProxyUrl(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy for connection [%s]: %w", connection.Name, err)
+	}
+
+	return http.ProxyURL(proxyUrl), nil
+}
+
+func parseProxyUrl(value string) (*url.URL, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme [%s]", u.Scheme)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy url has no host")
+	}
+
+	return u, nil
+}
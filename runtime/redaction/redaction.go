@@ -0,0 +1,114 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package redaction masks sensitive fields of function results, as declared in the manifest,
+// before they are written to GraphQL responses or logs.  Callers whose token grants the
+// privileged scope see the actual values.
+package redaction
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/middleware"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+const defaultScopeClaim = "scope"
+
+// Apply returns the result of the function with the values of its redacted fields replaced by utils.RedactedValue.
+// The result is returned unchanged if the function has no redacted fields, or if the caller is privileged.
+func Apply(ctx context.Context, fnName string, result any) any {
+	info := manifestdata.GetManifest().Redaction
+	fields := info.FieldsFor(fnName)
+	if len(fields) == 0 || result == nil || isPrivileged(ctx, info) {
+		return result
+	}
+
+	// Normalize the result, so that it only contains maps and slices, and so that the original is not modified.
+	data, err := utils.JsonSerialize(result)
+	if err != nil {
+		logger.Warn(ctx).Err(err).Str("function", fnName).Msg("Failed to redact function result.")
+		return nil
+	}
+	var v any
+	if err := utils.JsonDeserialize(data, &v); err != nil {
+		logger.Warn(ctx).Err(err).Str("function", fnName).Msg("Failed to redact function result.")
+		return nil
+	}
+
+	for _, f := range fields {
+		redactPath(v, strings.Split(f, "."))
+	}
+	return v
+}
+
+// redactPath masks the value at the path, applying the path to each item of any list along the way.
+func redactPath(v any, path []string) {
+	switch t := v.(type) {
+	case []any:
+		for _, item := range t {
+			redactPath(item, path)
+		}
+	case map[string]any:
+		val, ok := t[path[0]]
+		if !ok || val == nil {
+			return
+		}
+		if len(path) == 1 {
+			t[path[0]] = utils.RedactedValue
+		} else {
+			redactPath(val, path[1:])
+		}
+	}
+}
+
+func isPrivileged(ctx context.Context, info *manifest.RedactionInfo) bool {
+	if info.PrivilegedScope == "" {
+		return false
+	}
+
+	claim := info.ScopeClaim
+	if claim == "" {
+		claim = defaultScopeClaim
+	}
+	return slices.Contains(scopesFromClaims(middleware.GetJWTClaims(ctx), claim), info.PrivilegedScope)
+}
+
+// scopesFromClaims reads the caller's scopes from the JWT claims, where the claim may be
+// either a space-separated string, as in OAuth 2.0, or an array of strings.
+func scopesFromClaims(claimsJson, claim string) []string {
+	if claimsJson == "" {
+		return nil
+	}
+
+	var claims map[string]any
+	if err := utils.JsonDeserialize([]byte(claimsJson), &claims); err != nil {
+		return nil
+	}
+
+	switch v := claims[claim].(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		scopes := make([]string, 0, len(v))
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				scopes = append(scopes, s)
+			}
+		}
+		return scopes
+	}
+
+	return nil
+}
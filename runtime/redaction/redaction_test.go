@@ -0,0 +1,77 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package redaction
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/middleware"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+type customer struct {
+	Name     string            `json:"name"`
+	SSN      string            `json:"ssn"`
+	Payment  map[string]string `json:"payment"`
+	Accounts []map[string]any  `json:"accounts"`
+}
+
+func TestApply(t *testing.T) {
+	manifestdata.SetManifest(&manifest.Manifest{
+		Redaction: &manifest.RedactionInfo{
+			PrivilegedScope: "pii:read",
+			Functions: map[string][]string{
+				"getCustomer": {"ssn", "payment.cardNumber", "accounts.number", "missing.field"},
+			},
+		},
+	})
+	defer manifestdata.SetManifest(&manifest.Manifest{})
+
+	c := &customer{
+		Name:     "Ada",
+		SSN:      "123-45-6789",
+		Payment:  map[string]string{"cardNumber": "4111", "brand": "visa"},
+		Accounts: []map[string]any{{"number": "1"}, {"number": "2", "type": "savings"}},
+	}
+
+	result := Apply(context.Background(), "getCustomer", []*customer{c})
+	require.Equal(t, []any{map[string]any{
+		"name":    "Ada",
+		"ssn":     utils.RedactedValue,
+		"payment": map[string]any{"cardNumber": utils.RedactedValue, "brand": "visa"},
+		"accounts": []any{
+			map[string]any{"number": utils.RedactedValue},
+			map[string]any{"number": utils.RedactedValue, "type": "savings"},
+		},
+	}}, result)
+	require.Equal(t, "123-45-6789", c.SSN, "the original result must not be modified")
+
+	// other functions are not redacted
+	require.Same(t, c, Apply(context.Background(), "getOther", c))
+
+	// a privileged caller sees the actual values
+	ctx := middleware.ContextWithJWTClaims(context.Background(), `{"scope":"openid pii:read"}`)
+	require.Same(t, c, Apply(ctx, "getCustomer", c))
+
+	ctx = middleware.ContextWithJWTClaims(context.Background(), `{"scope":["openid"]}`)
+	require.NotSame(t, c, Apply(ctx, "getCustomer", c))
+}
+
+func TestScopesFromClaims(t *testing.T) {
+	require.Equal(t, []string{"a", "b"}, scopesFromClaims(`{"scope":"a  b"}`, "scope"))
+	require.Equal(t, []string{"a", "b"}, scopesFromClaims(`{"scp":["a","b"]}`, "scp"))
+	require.Nil(t, scopesFromClaims(`{"scope":1}`, "scope"))
+	require.Nil(t, scopesFromClaims("", "scope"))
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package runner executes a single function from the command line, without starting the HTTP server.
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/functions"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+const functionsReadyTimeout = 60 * time.Second
+
+var functionsReady = make(chan struct{})
+var functionsReadyOnce sync.Once
+
+func Initialize() {
+	functions.RegisterFunctionsLoadedCallback(func(ctx context.Context) {
+		functionsReadyOnce.Do(func() { close(functionsReady) })
+	})
+}
+
+// RunFunction waits for the plugins and manifest to load, then calls the named function
+// and writes its result as JSON to the output writer.
+// The function arguments are read as a JSON object from the file at argsPath,
+// or from stdin if argsPath is "-".  If argsPath is empty, the function is called without arguments.
+func RunFunction(ctx context.Context, fnName, argsPath string, out io.Writer) error {
+	params, err := readArgs(argsPath)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-functionsReady:
+	case <-time.After(functionsReadyTimeout):
+		return errors.New("timed out waiting for functions to load")
+	}
+
+	host := wasmhost.GetWasmHost(ctx)
+	fnInfo, err := host.GetFunctionInfo(fnName)
+	if err != nil {
+		return err
+	}
+
+	execInfo, err := host.CallFunction(ctx, fnInfo, params)
+	if err != nil {
+		// the details have already been logged
+		return fmt.Errorf("error calling function %s: %w", fnName, err)
+	}
+
+	result, err := utils.JsonSerialize(execInfo.Result())
+	if err != nil {
+		return fmt.Errorf("error serializing result of function %s: %w", fnName, err)
+	}
+
+	result, err = utils.FormatJson(result, utils.JsonFormatOptions{Indent: "  "})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, string(result))
+	return err
+}
+
+func readArgs(argsPath string) (map[string]any, error) {
+	var data []byte
+	var err error
+	switch argsPath {
+	case "":
+		return map[string]any{}, nil
+	case "-":
+		data, err = io.ReadAll(os.Stdin)
+	default:
+		data, err = os.ReadFile(argsPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading function arguments: %w", err)
+	}
+
+	params := map[string]any{}
+	if err := utils.JsonDeserialize(data, &params); err != nil {
+		return nil, fmt.Errorf("function arguments must be a JSON object: %w", err)
+	}
+	return params, nil
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+// localModules are the host function modules that a sandboxed worker process executes itself, because they only
+// use the state of the function call.  All other host functions use collections, databases, secrets or connections
+// that belong to the main process, so the worker forwards their calls to the main process.
+var localModules = map[string]bool{
+	"modus_system":      true,
+	"modus_expressions": true,
+	"modus_hashing":     true,
+	"modus_text":        true,
+}
+
+var hostFunctions = make(map[string]reflect.Value)
+var hostFunctionsMu sync.RWMutex
+
+var rtContext = reflect.TypeFor[context.Context]()
+var rtError = reflect.TypeFor[error]()
+
+type hostCall struct {
+	Function     string            `json:"function"`
+	Args         []json.RawMessage `json:"args"`
+	ExecutionId  string            `json:"executionId,omitempty"`
+	FunctionName string            `json:"functionName,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+type hostResult struct {
+	Result   json.RawMessage    `json:"result,omitempty"`
+	Messages []utils.LogMessage `json:"messages,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// HostFunction records a host function, so that calls forwarded from sandboxed worker processes can be executed,
+// and returns the function to register with the wasm host.  In a sandboxed worker process, unless the function
+// can be executed locally, it returns a function with the same signature that forwards calls to the main process.
+func HostFunction(modName, funcName string, fn any) any {
+	name := modName + "." + funcName
+	rv := reflect.ValueOf(fn)
+	if rv.Kind() != reflect.Func {
+		return fn
+	}
+
+	hostFunctionsMu.Lock()
+	hostFunctions[name] = rv
+	hostFunctionsMu.Unlock()
+
+	if !config.IsSandboxWorker || localModules[modName] {
+		return fn
+	}
+
+	rt := rv.Type()
+	return reflect.MakeFunc(rt, func(args []reflect.Value) []reflect.Value {
+		return forwardHostCall(name, rt, args)
+	}).Interface()
+}
+
+// forwardHostCall sends a host function call to the main process, and converts its result to the function's results.
+func forwardHostCall(name string, rt reflect.Type, args []reflect.Value) []reflect.Value {
+	ctx := context.Background()
+	if len(args) > 0 && rt.In(0).Implements(rtContext) {
+		if c, ok := args[0].Interface().(context.Context); ok && c != nil {
+			ctx = c
+		}
+		args = args[1:]
+	}
+
+	call := &hostCall{Function: name, Args: make([]json.RawMessage, len(args))}
+	call.ExecutionId, _ = ctx.Value(utils.ExecutionIdContextKey).(string)
+	call.FunctionName, _ = ctx.Value(utils.FunctionNameContextKey).(string)
+	call.Metadata, _ = ctx.Value(utils.CallerMetadataContextKey).(map[string]string)
+
+	res, err := func() (*hostResult, error) {
+		for i, arg := range args {
+			data, err := utils.JsonSerialize(arg.Interface())
+			if err != nil {
+				return nil, err
+			}
+			call.Args[i] = data
+		}
+		return callParent(call)
+	}()
+
+	if err != nil {
+		err = fmt.Errorf("failed to forward call to host function %s to the main process: %w", name, err)
+	} else {
+		if messages, ok := ctx.Value(utils.FunctionMessagesContextKey).(*[]utils.LogMessage); ok {
+			*messages = append(*messages, res.Messages...)
+		}
+		if res.Error != "" {
+			err = errors.New(res.Error)
+		}
+	}
+
+	results := make([]reflect.Value, rt.NumOut())
+	for i := range results {
+		t := rt.Out(i)
+		switch {
+		case t == rtError:
+			if err != nil {
+				results[i] = reflect.ValueOf(&err).Elem()
+			} else {
+				results[i] = reflect.Zero(t)
+			}
+		case res != nil && len(res.Result) > 0:
+			v := reflect.New(t)
+			if e := utils.JsonDeserialize(res.Result, v.Interface()); e != nil && err == nil {
+				err = fmt.Errorf("failed to decode result of host function %s from the main process: %w", name, e)
+			}
+			results[i] = v.Elem()
+		default:
+			results[i] = reflect.Zero(t)
+		}
+	}
+	return results
+}
+
+// executeHostCall executes a host function call forwarded from a sandboxed worker process.
+func executeHostCall(ctx context.Context, call *hostCall) *hostResult {
+	modName, _, _ := strings.Cut(call.Function, ".")
+
+	hostFunctionsMu.RLock()
+	fn, ok := hostFunctions[call.Function]
+	hostFunctionsMu.RUnlock()
+	if !ok || localModules[modName] {
+		return &hostResult{Error: fmt.Sprintf("host function %s cannot be forwarded from a sandboxed worker process", call.Function)}
+	}
+
+	var messages []utils.LogMessage
+	ctx = context.WithValue(ctx, utils.ExecutionIdContextKey, call.ExecutionId)
+	ctx = context.WithValue(ctx, utils.FunctionNameContextKey, call.FunctionName)
+	ctx = context.WithValue(ctx, utils.FunctionMessagesContextKey, &messages)
+	if call.Metadata != nil {
+		ctx = context.WithValue(ctx, utils.CallerMetadataContextKey, call.Metadata)
+	}
+	if host, ok := ctx.Value(utils.WasmHostContextKey).(wasmhost.WasmHost); ok {
+		if fnInfo, err := host.GetFunctionInfo(call.FunctionName); err == nil {
+			ctx = context.WithValue(ctx, utils.PluginContextKey, fnInfo.Plugin())
+			ctx = context.WithValue(ctx, utils.MetadataContextKey, fnInfo.Plugin().Metadata)
+		}
+	}
+
+	rt := fn.Type()
+	args := make([]reflect.Value, 0, rt.NumIn())
+	if rt.NumIn() > 0 && rt.In(0).Implements(rtContext) {
+		args = append(args, reflect.ValueOf(ctx))
+	}
+	if len(args)+len(call.Args) != rt.NumIn() {
+		return &hostResult{Error: fmt.Sprintf("host function %s expects %d arguments, but received %d", call.Function, rt.NumIn()-len(args), len(call.Args))}
+	}
+	for _, data := range call.Args {
+		v := reflect.New(rt.In(len(args)))
+		if err := utils.JsonDeserialize(data, v.Interface()); err != nil {
+			return &hostResult{Error: fmt.Sprintf("failed to decode argument of host function %s: %v", call.Function, err)}
+		}
+		args = append(args, v.Elem())
+	}
+
+	res := &hostResult{}
+	for _, out := range fn.Call(args) {
+		if out.Type() == rtError {
+			if !out.IsNil() {
+				res.Error = out.Interface().(error).Error()
+			}
+			continue
+		}
+		data, err := utils.JsonSerialize(out.Interface())
+		if err != nil {
+			res.Error = err.Error()
+			continue
+		}
+		res.Result = data
+	}
+	res.Messages = messages
+	return res
+}
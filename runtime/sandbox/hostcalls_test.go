@@ -0,0 +1,101 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testItem struct {
+	Key    string   `json:"key"`
+	Labels []string `json:"labels"`
+}
+
+// connectTestParent connects the worker side of the sandbox protocol to a goroutine
+// that executes forwarded host function calls, as the main process does.
+func connectTestParent(t *testing.T) {
+	toWorkerR, toWorkerW := io.Pipe()
+	toParentR, toParentW := io.Pipe()
+	parent.requests = json.NewDecoder(toWorkerR)
+	parent.results = json.NewEncoder(toParentW)
+
+	calls := json.NewDecoder(toParentR)
+	results := json.NewEncoder(toWorkerW)
+	go func() {
+		for {
+			var msg workerMessage
+			if err := calls.Decode(&msg); err != nil {
+				return
+			}
+			_ = results.Encode(&parentMessage{HostResult: executeHostCall(context.Background(), msg.HostCall)})
+		}
+	}()
+
+	t.Cleanup(func() {
+		toParentW.Close()
+		toWorkerW.Close()
+		parent.requests = nil
+		parent.results = nil
+	})
+}
+
+func TestHostFunctionsAreForwardedFromWorker(t *testing.T) {
+	config.IsSandboxWorker = true
+	t.Cleanup(func() { config.IsSandboxWorker = false })
+	connectTestParent(t)
+
+	var calledWith string
+	getItem := HostFunction("modus_test", "getItem", func(ctx context.Context, key string, labels []string) (*testItem, error) {
+		calledWith, _ = ctx.Value(utils.FunctionNameContextKey).(string)
+		if key == "" {
+			return nil, errors.New("key is required")
+		}
+		messages := ctx.Value(utils.FunctionMessagesContextKey).(*[]utils.LogMessage)
+		*messages = append(*messages, utils.LogMessage{Level: "info", Message: "getting item " + key})
+		return &testItem{Key: key, Labels: labels}, nil
+	}).(func(context.Context, string, []string) (*testItem, error))
+
+	var messages []utils.LogMessage
+	ctx := context.WithValue(context.Background(), utils.FunctionNameContextKey, "myFunction")
+	ctx = context.WithValue(ctx, utils.FunctionMessagesContextKey, &messages)
+
+	item, err := getItem(ctx, "k1", []string{"a", "b"})
+	require.NoError(t, err)
+	require.Equal(t, &testItem{Key: "k1", Labels: []string{"a", "b"}}, item)
+	require.Equal(t, "myFunction", calledWith)
+	require.Len(t, messages, 1)
+	require.Equal(t, "getting item k1", messages[0].Message)
+
+	item, err = getItem(ctx, "", nil)
+	require.EqualError(t, err, "key is required")
+	require.Nil(t, item)
+}
+
+func TestLocalHostFunctionsAreNotForwarded(t *testing.T) {
+	config.IsSandboxWorker = true
+	t.Cleanup(func() { config.IsSandboxWorker = false })
+
+	// there is no connection to the main process, so a forwarded call would fail
+	getTime := HostFunction("modus_system", "getTime", func() string { return "now" }).(func() string)
+	require.Equal(t, "now", getTime())
+
+	forwarded := HostFunction("modus_test", "getValue", func() (string, error) { return "value", nil }).(func() (string, error))
+	_, err := forwarded()
+	require.ErrorContains(t, err, "not connected to the main process")
+}
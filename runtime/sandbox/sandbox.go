@@ -0,0 +1,368 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package sandbox executes functions in separate worker processes, for functions whose
+// manifest isolation level requires it.  A worker process is another instance of the runtime,
+// started in worker mode, that receives function calls from the main process over a pipe.
+// If a worker crashes or runs out of memory, only the call it was executing fails.
+//
+// A worker process only executes functions.  It forwards host function calls that need the
+// services of the main process, such as collections, databases and secrets, back over the pipe.
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/concurrency"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/langsupport"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/middleware"
+	"github.com/hypermodeinc/modus/runtime/progress"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+type request struct {
+	Function   string            `json:"function"`
+	Parameters map[string]any    `json:"parameters"`
+	TimeZone   string            `json:"timeZone,omitempty"`
+	Claims     string            `json:"claims,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Deadline   *time.Time        `json:"deadline,omitempty"`
+	Cursor     string            `json:"cursor,omitempty"`
+}
+
+// parentMessage is sent from the main process to a worker process.
+type parentMessage struct {
+	Request    *request    `json:"request,omitempty"`
+	HostResult *hostResult `json:"hostResult,omitempty"`
+}
+
+// workerMessage is sent from a worker process to the main process.
+type workerMessage struct {
+	Response *response `json:"response,omitempty"`
+	HostCall *hostCall `json:"hostCall,omitempty"`
+}
+
+type response struct {
+	ExecutionId string             `json:"executionId"`
+	Result      json.RawMessage    `json:"result,omitempty"`
+	Messages    []utils.LogMessage `json:"messages,omitempty"`
+	StdOut      string             `json:"stdout,omitempty"`
+	StdErr      string             `json:"stderr,omitempty"`
+	Error       string             `json:"error,omitempty"`
+
+	// set when the function's response was truncated, or exceeded its size limit
+	Truncation      *wasmhost.ResponseTruncation `json:"truncation,omitempty"`
+	MaxResponseSize int                          `json:"maxResponseSize,omitempty"`
+
+	// set when the function trapped
+	Trap *wasmhost.WasmTrap `json:"trap,omitempty"`
+
+	Memory *wasmhost.MemoryUsage `json:"memory,omitempty"`
+}
+
+type executionInfo struct {
+	executionId string
+	buffers     utils.OutputBuffers
+	messages    []utils.LogMessage
+	result      any
+	truncation  *wasmhost.ResponseTruncation
+	trap        *wasmhost.WasmTrap
+	memory      *wasmhost.MemoryUsage
+}
+
+func (e *executionInfo) ExecutionId() string {
+	return e.executionId
+}
+
+func (e *executionInfo) Buffers() utils.OutputBuffers {
+	return e.buffers
+}
+
+func (e *executionInfo) Messages() []utils.LogMessage {
+	return e.messages
+}
+
+func (e *executionInfo) Result() any {
+	return e.result
+}
+
+func (e *executionInfo) Truncation() *wasmhost.ResponseTruncation {
+	return e.truncation
+}
+
+func (e *executionInfo) Trap() *wasmhost.WasmTrap {
+	return e.trap
+}
+
+func (e *executionInfo) Memory() *wasmhost.MemoryUsage {
+	return e.memory
+}
+
+type worker struct {
+	cmd      *exec.Cmd
+	requests *json.Encoder
+	results  *json.Decoder
+	closers  []*os.File
+}
+
+var idleWorkers []*worker
+var slots chan struct{}
+var mu sync.Mutex
+
+// processIsolator routes every call of a function that requires process isolation to a worker process.
+type processIsolator struct{}
+
+func (processIsolator) IsIsolated(fnName string) bool {
+	return IsIsolated(fnName)
+}
+
+func (processIsolator) CallFunction(ctx context.Context, fnName string, parameters map[string]any) (wasmhost.ExecutionInfo, error) {
+	return CallFunction(ctx, fnName, parameters)
+}
+
+// IsIsolated reports whether the given function must be executed in a sandboxed worker process.
+func IsIsolated(fnName string) bool {
+	if config.IsSandboxWorker {
+		return false
+	}
+	return manifestdata.GetManifest().Isolation.LevelFor(fnName) == manifest.IsolationLevelProcess
+}
+
+// CallFunction executes the function in a sandboxed worker process, and waits for its result.
+// If the context is cancelled before the function completes, the worker process is terminated.
+func CallFunction(ctx context.Context, fnName string, parameters map[string]any) (wasmhost.ExecutionInfo, error) {
+	releaseKey, err := concurrency.Acquire(ctx, fnName, parameters)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseKey()
+
+	select {
+	case getSlots() <- struct{}{}:
+		defer func() { <-slots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	w, err := getWorker(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &request{
+		Function:   fnName,
+		Parameters: parameters,
+		Claims:     middleware.GetJWTClaims(ctx),
+	}
+	if tz, ok := ctx.Value(utils.TimeZoneContextKey).(string); ok {
+		req.TimeZone = tz
+	}
+	if md, ok := ctx.Value(utils.CallerMetadataContextKey).(map[string]string); ok {
+		req.Metadata = md
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Deadline = &deadline
+	}
+	if cursor, ok := ctx.Value(utils.ResponseCursorContextKey).(string); ok {
+		req.Cursor = cursor
+	}
+
+	type result struct {
+		res *response
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		res, err := w.call(ctx, req)
+		ch <- result{res, err}
+	}()
+
+	var res *response
+	select {
+	case r := <-ch:
+		if r.err != nil {
+			w.kill()
+			logger.Err(ctx, r.err).Str("function", fnName).Msg("Sandboxed worker process failed.")
+			return nil, fmt.Errorf("sandboxed worker process failed while executing function %s: %w", fnName, r.err)
+		}
+		res = r.res
+	case <-ctx.Done():
+		// give the function the same grace period to return as it would have in this process
+		select {
+		case r := <-ch:
+			if r.err == nil {
+				putWorker(w)
+			} else {
+				w.kill()
+			}
+		case <-time.After(config.CancellationGracePeriod):
+			w.kill()
+			<-ch
+		}
+		return nil, ctx.Err()
+	}
+
+	putWorker(w)
+	progress.Finish(ctx, res.ExecutionId)
+
+	info := &executionInfo{
+		executionId: res.ExecutionId,
+		buffers:     utils.NewOutputBuffers(0), // limits were applied by the worker
+		messages:    res.Messages,
+		truncation:  res.Truncation,
+		trap:        res.Trap,
+		memory:      res.Memory,
+	}
+	info.buffers.StdOut().WriteString(res.StdOut)
+	info.buffers.StdErr().WriteString(res.StdErr)
+
+	if len(res.Result) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(res.Result))
+		dec.UseNumber()
+		if err := dec.Decode(&info.result); err != nil {
+			return nil, fmt.Errorf("failed to decode result of function %s from sandboxed worker process: %w", fnName, err)
+		}
+	}
+
+	if res.MaxResponseSize > 0 {
+		return info, &langsupport.ResponseTooLargeError{MaxBytes: res.MaxResponseSize}
+	} else if res.Error != "" {
+		return info, errors.New(res.Error)
+	}
+	return info, nil
+}
+
+// Shutdown terminates any idle worker processes.
+func Shutdown() {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, w := range idleWorkers {
+		w.kill()
+	}
+	idleWorkers = nil
+}
+
+func getSlots() chan struct{} {
+	mu.Lock()
+	defer mu.Unlock()
+	if slots == nil {
+		slots = make(chan struct{}, max(config.SandboxWorkers, 1))
+	}
+	return slots
+}
+
+func getWorker(ctx context.Context) (*worker, error) {
+	mu.Lock()
+	if n := len(idleWorkers); n > 0 {
+		w := idleWorkers[n-1]
+		idleWorkers = idleWorkers[:n-1]
+		mu.Unlock()
+		return w, nil
+	}
+	mu.Unlock()
+
+	return startWorker(ctx)
+}
+
+func putWorker(w *worker) {
+	mu.Lock()
+	defer mu.Unlock()
+	idleWorkers = append(idleWorkers, w)
+}
+
+func startWorker(ctx context.Context) (*worker, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	// requests are sent on fd 3, and results are received on fd 4,
+	// leaving stdout and stderr of the worker for its logs
+	reqR, reqW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	resR, resW, err := os.Pipe()
+	if err != nil {
+		reqR.Close()
+		reqW.Close()
+		return nil, err
+	}
+
+	args := append(os.Args[1:], "-sandboxWorker")
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{reqR, resW}
+	if err := cmd.Start(); err != nil {
+		for _, f := range []*os.File{reqR, reqW, resR, resW} {
+			f.Close()
+		}
+		return nil, fmt.Errorf("failed to start sandboxed worker process: %w", err)
+	}
+
+	// the child process has its own copies of these
+	reqR.Close()
+	resW.Close()
+
+	logger.Info(ctx).Int("pid", cmd.Process.Pid).Msg("Started sandboxed worker process.")
+
+	return &worker{
+		cmd:      cmd,
+		requests: json.NewEncoder(reqW),
+		results:  json.NewDecoder(resR),
+		closers:  []*os.File{reqW, resR},
+	}, nil
+}
+
+// call sends a request to the worker, and executes the host function calls that the worker
+// forwards while it executes the function, until it returns the response.
+func (w *worker) call(ctx context.Context, req *request) (*response, error) {
+	if err := w.requests.Encode(&parentMessage{Request: req}); err != nil {
+		return nil, err
+	}
+	for {
+		var msg workerMessage
+		if err := w.results.Decode(&msg); err != nil {
+			return nil, err
+		}
+		switch {
+		case msg.Response != nil:
+			return msg.Response, nil
+		case msg.HostCall != nil:
+			res := executeHostCall(ctx, msg.HostCall)
+			if err := w.requests.Encode(&parentMessage{HostResult: res}); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.New("received an empty message from sandboxed worker process")
+		}
+	}
+}
+
+func (w *worker) kill() {
+	_ = w.cmd.Process.Kill()
+	for _, f := range w.closers {
+		f.Close()
+	}
+	_ = w.cmd.Wait()
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/functions"
+	"github.com/hypermodeinc/modus/runtime/langsupport"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/middleware"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+const functionsReadyTimeout = 60 * time.Second
+
+var functionsReady = make(chan struct{})
+var functionsReadyOnce sync.Once
+
+// parent is the connection of a worker process to the main process.
+var parent struct {
+	mu       sync.Mutex
+	requests *json.Decoder
+	results  *json.Encoder
+}
+
+func Initialize() {
+	wasmhost.RegisterFunctionIsolator(processIsolator{})
+	functions.RegisterFunctionsLoadedCallback(func(ctx context.Context) {
+		functionsReadyOnce.Do(func() { close(functionsReady) })
+	})
+}
+
+// ServeWorker runs the worker side of the sandbox protocol, executing function calls received
+// from the main process until the main process closes the pipe.
+func ServeWorker(ctx context.Context) {
+	parent.requests = json.NewDecoder(os.NewFile(3, "sandbox-requests"))
+	parent.results = json.NewEncoder(os.NewFile(4, "sandbox-results"))
+
+	select {
+	case <-functionsReady:
+	case <-time.After(functionsReadyTimeout):
+		logger.Error(ctx).Msg("Timed out waiting for functions to load in sandboxed worker process.")
+	}
+
+	for {
+		var msg parentMessage
+		if err := parent.requests.Decode(&msg); err != nil {
+			if !errors.Is(err, io.EOF) {
+				logger.Err(ctx, err).Msg("Failed to read request in sandboxed worker process.")
+			}
+			return
+		}
+		if msg.Request == nil {
+			logger.Error(ctx).Msg("Received a message without a request in sandboxed worker process.")
+			return
+		}
+
+		res := execute(ctx, msg.Request)
+
+		parent.mu.Lock()
+		err := parent.results.Encode(&workerMessage{Response: res})
+		parent.mu.Unlock()
+		if err != nil {
+			logger.Err(ctx, err).Msg("Failed to write result in sandboxed worker process.")
+			return
+		}
+	}
+}
+
+// callParent forwards a host function call to the main process, and waits for its result.
+// The main process handles one message at a time from each worker, so concurrent calls are serialized.
+func callParent(call *hostCall) (*hostResult, error) {
+	parent.mu.Lock()
+	defer parent.mu.Unlock()
+
+	if parent.results == nil {
+		return nil, errors.New("not connected to the main process")
+	}
+	if err := parent.results.Encode(&workerMessage{HostCall: call}); err != nil {
+		return nil, err
+	}
+
+	var msg parentMessage
+	if err := parent.requests.Decode(&msg); err != nil {
+		return nil, err
+	}
+	if msg.HostResult == nil {
+		return nil, errors.New("expected the result of a host function call from the main process")
+	}
+	return msg.HostResult, nil
+}
+
+func execute(ctx context.Context, req *request) *response {
+	if req.TimeZone != "" {
+		ctx = context.WithValue(ctx, utils.TimeZoneContextKey, req.TimeZone)
+	}
+	if req.Deadline != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, *req.Deadline)
+		defer cancel()
+	}
+	if req.Metadata != nil {
+		ctx = context.WithValue(ctx, utils.CallerMetadataContextKey, req.Metadata)
+	}
+	if req.Claims != "" {
+		ctx = middleware.ContextWithJWTClaims(ctx, req.Claims)
+	}
+	if req.Cursor != "" {
+		ctx = context.WithValue(ctx, utils.ResponseCursorContextKey, req.Cursor)
+	}
+
+	host := wasmhost.GetWasmHost(ctx)
+	fnInfo, err := host.GetFunctionInfo(req.Function)
+	if err != nil {
+		return &response{Error: err.Error()}
+	}
+
+	execInfo, err := host.CallFunction(ctx, fnInfo, req.Parameters)
+	if execInfo == nil {
+		return &response{Error: err.Error()}
+	}
+
+	res := &response{
+		ExecutionId: execInfo.ExecutionId(),
+		Messages:    execInfo.Messages(),
+		StdOut:      execInfo.Buffers().StdOut().String(),
+		StdErr:      execInfo.Buffers().StdErr().String(),
+		Truncation:  execInfo.Truncation(),
+		Trap:        execInfo.Trap(),
+		Memory:      execInfo.Memory(),
+	}
+	if err != nil {
+		res.Error = err.Error()
+		var tooLarge *langsupport.ResponseTooLargeError
+		if errors.As(err, &tooLarge) {
+			res.MaxResponseSize = tooLarge.MaxBytes
+		}
+	}
+	if result := execInfo.Result(); result != nil {
+		if j, err := utils.JsonSerialize(result); err != nil {
+			res.Error = err.Error()
+		} else {
+			res.Result = j
+		}
+	}
+	return res
+}
@@ -13,22 +13,38 @@ import (
 	"context"
 
 	"github.com/hypermodeinc/modus/runtime/aws"
+	"github.com/hypermodeinc/modus/runtime/bulkimport"
 	"github.com/hypermodeinc/modus/runtime/collections"
+	"github.com/hypermodeinc/modus/runtime/config"
 	"github.com/hypermodeinc/modus/runtime/db"
 	"github.com/hypermodeinc/modus/runtime/dgraphclient"
 	"github.com/hypermodeinc/modus/runtime/envfiles"
+	"github.com/hypermodeinc/modus/runtime/evaluation"
+	"github.com/hypermodeinc/modus/runtime/explorer"
 	"github.com/hypermodeinc/modus/runtime/graphql"
+	"github.com/hypermodeinc/modus/runtime/guardrails"
 	"github.com/hypermodeinc/modus/runtime/hostfunctions"
+	"github.com/hypermodeinc/modus/runtime/httpclient"
+	"github.com/hypermodeinc/modus/runtime/kvstore"
 	"github.com/hypermodeinc/modus/runtime/logger"
 	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/metering"
 	"github.com/hypermodeinc/modus/runtime/middleware"
 	"github.com/hypermodeinc/modus/runtime/neo4jclient"
+	"github.com/hypermodeinc/modus/runtime/outbox"
 	"github.com/hypermodeinc/modus/runtime/pluginmanager"
+	"github.com/hypermodeinc/modus/runtime/postprocess"
+	"github.com/hypermodeinc/modus/runtime/proxy"
+	"github.com/hypermodeinc/modus/runtime/runner"
+	"github.com/hypermodeinc/modus/runtime/sandbox"
 	"github.com/hypermodeinc/modus/runtime/secrets"
+	"github.com/hypermodeinc/modus/runtime/shadow"
 	"github.com/hypermodeinc/modus/runtime/sqlclient"
 	"github.com/hypermodeinc/modus/runtime/storage"
 	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/warmup"
 	"github.com/hypermodeinc/modus/runtime/wasmhost"
+	"github.com/hypermodeinc/modus/runtime/webhooks"
 )
 
 // Starts any services that need to be started when the runtime starts.
@@ -41,24 +57,60 @@ func Start(ctx context.Context) context.Context {
 	host := wasmhost.InitWasmHost(ctx, registrations...)
 	ctx = context.WithValue(ctx, utils.WasmHostContextKey, host)
 
+	// A sandboxed worker process only executes functions.  It forwards the host functions that use collections,
+	// databases, secrets or connections to the main process, so it only starts what it needs to load plugins.
+	if config.IsSandboxWorker {
+		runStartupSteps(ctx, []*startupStep{
+			{name: "proxy", fn: proxy.Initialize},
+			{name: "aws", deps: []string{"proxy"}, fn: func() { aws.Initialize(ctx) }},
+			{name: "storage", deps: []string{"aws"}, fn: func() { storage.Initialize(ctx) }},
+			{name: "sandbox", fn: sandbox.Initialize},
+			{name: "manifest", deps: []string{"storage"}, fn: func() { manifestdata.MonitorManifestFile(ctx) }},
+			{name: "envfiles", deps: []string{"storage"}, fn: func() { envfiles.MonitorEnvFiles(ctx) }},
+			{name: "plugins", deps: []string{"manifest", "envfiles", "sandbox"}, fn: func() { pluginmanager.Initialize(ctx) }},
+		})
+		return ctx
+	}
+
 	// Start the background services.  None of these should block. If they need to do background work, they should start a goroutine internally.
 
-	// NOTE: Initialization order is important in some cases.
-	// If you need to change the order or add new services, be sure to test thoroughly.
-	// Generally, new services should be added to the end of the list, unless there is a specific reason to do otherwise.
-
-	sqlclient.Initialize()
-	dgraphclient.Initialize()
-	neo4jclient.Initialize()
-	aws.Initialize(ctx)
-	secrets.Initialize(ctx)
-	storage.Initialize(ctx)
-	db.Initialize(ctx)
-	collections.Initialize(ctx)
-	manifestdata.MonitorManifestFile(ctx)
-	envfiles.MonitorEnvFiles(ctx)
-	pluginmanager.Initialize(ctx)
-	graphql.Initialize()
+	// NOTE: Each service lists the services that must be initialized before it.
+	// Services without dependencies between them are initialized concurrently.
+	// If you add a new service, be sure to declare its dependencies and test thoroughly.
+
+	runStartupSteps(ctx, []*startupStep{
+		{name: "sqlclient", fn: sqlclient.Initialize},
+		{name: "dgraphclient", fn: dgraphclient.Initialize},
+		{name: "neo4jclient", fn: neo4jclient.Initialize},
+		{name: "httpclient", fn: httpclient.Initialize},
+		{name: "proxy", fn: proxy.Initialize},
+		{name: "aws", deps: []string{"proxy"}, fn: func() { aws.Initialize(ctx) }},
+		{name: "secrets", deps: []string{"aws"}, fn: func() { secrets.Initialize(ctx) }},
+		{name: "storage", deps: []string{"aws"}, fn: func() { storage.Initialize(ctx) }},
+		{name: "db", deps: []string{"secrets"}, fn: func() { db.Initialize(ctx) }},
+		{name: "kvstore", fn: func() { kvstore.Initialize(ctx) }},
+		{name: "collections", deps: []string{"db"}, fn: func() { collections.Initialize(ctx) }},
+		{name: "guardrails", fn: guardrails.Initialize},
+		{name: "postprocess", fn: postprocess.Initialize},
+		{name: "warmup", fn: warmup.Initialize},
+		{name: "sandbox", fn: sandbox.Initialize},
+		{name: "runner", fn: runner.Initialize},
+		{name: "shadow", fn: func() { shadow.Initialize(ctx) }},
+		{name: "evaluation", fn: func() { evaluation.Initialize(ctx) }},
+		{name: "bulkimport", fn: func() { bulkimport.Initialize(ctx) }},
+		{name: "outbox", deps: []string{"db"}, fn: func() { outbox.Initialize(ctx) }},
+		{name: "explorer", fn: func() { explorer.Initialize(ctx) }},
+		{name: "webhooks", fn: func() { webhooks.Initialize(ctx) }},
+		{name: "metering", fn: func() { metering.Initialize(ctx) }},
+
+		// the manifest must not be loaded until everything that reacts to it is ready
+		{name: "manifest", deps: []string{"storage", "secrets", "db", "kvstore", "collections", "guardrails", "postprocess", "warmup", "httpclient"}, fn: func() { manifestdata.MonitorManifestFile(ctx) }},
+		{name: "envfiles", deps: []string{"storage"}, fn: func() { envfiles.MonitorEnvFiles(ctx) }},
+
+		// plugins must not be loaded until everything that reacts to them is ready
+		{name: "plugins", deps: []string{"manifest", "envfiles", "sandbox", "runner", "shadow", "sqlclient", "dgraphclient", "neo4jclient"}, fn: func() { pluginmanager.Initialize(ctx) }},
+		{name: "graphql", deps: []string{"plugins"}, fn: graphql.Initialize},
+	})
 
 	return ctx
 }
@@ -68,6 +120,13 @@ func Stop(ctx context.Context) {
 
 	// Stop the wasm host first
 	wasmhost.GetWasmHost(ctx).Close(ctx)
+	sandbox.Shutdown()
+
+	// A sandboxed worker process didn't start the other services.
+	if config.IsSandboxWorker {
+		logger.Close()
+		return
+	}
 
 	// Stop the rest of the background services.
 	// NOTE: Stopping services also has an order dependency.
@@ -75,6 +134,8 @@ func Stop(ctx context.Context) {
 	// Unlike start, these should each block until they are fully stopped.
 
 	collections.Shutdown(ctx)
+	metering.Shutdown(ctx)
+	kvstore.Shutdown()
 	middleware.Shutdown()
 	sqlclient.ShutdownPGPools()
 	dgraphclient.ShutdownConns()
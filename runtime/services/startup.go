@@ -0,0 +1,118 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/logger"
+)
+
+type startupStep struct {
+	name string
+	deps []string
+	fn   func()
+
+	done chan struct{}
+}
+
+// runStartupSteps runs each step once all of the steps it depends on have completed,
+// running independent steps concurrently.  It returns when all steps have completed,
+// and logs the time taken by each step.
+func runStartupSteps(ctx context.Context, steps []*startupStep) {
+	if err := validateStartupSteps(steps); err != nil {
+		logger.Fatal(ctx).Err(err).Msg("Invalid startup dependency graph.")
+		return
+	}
+
+	byName := make(map[string]*startupStep, len(steps))
+	for _, s := range steps {
+		s.done = make(chan struct{})
+		byName[s.name] = s
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, s := range steps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(s.done)
+
+			for _, dep := range s.deps {
+				<-byName[dep].done
+			}
+
+			stepStart := time.Now()
+			s.fn()
+			logger.Info(ctx).
+				Str("step", s.name).
+				Dur("duration_ms", time.Since(stepStart)).
+				Dur("elapsed_ms", time.Since(start)).
+				Msg("Startup step completed.")
+		}()
+	}
+	wg.Wait()
+
+	logger.Info(ctx).
+		Dur("duration_ms", time.Since(start)).
+		Int("steps", len(steps)).
+		Msg("Services started.")
+}
+
+// validateStartupSteps ensures every dependency exists, and that there are no dependency cycles.
+func validateStartupSteps(steps []*startupStep) error {
+	byName := make(map[string]*startupStep, len(steps))
+	for _, s := range steps {
+		if _, found := byName[s.name]; found {
+			return fmt.Errorf("duplicate startup step %s", s.name)
+		}
+		byName[s.name] = s
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(steps))
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("startup step %s has a circular dependency", name)
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		for _, dep := range byName[name].deps {
+			if _, found := byName[dep]; !found {
+				return fmt.Errorf("startup step %s depends on unknown step %s", name, dep)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+
+	for _, s := range steps {
+		if err := visit(s.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package sessions
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/datascope"
+	"github.com/hypermodeinc/modus/runtime/kvstore"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+const keyPrefix = "session:"
+
+// Rough approximation of the number of characters per token, and the per-message overhead,
+// used for truncating the history to a token window without depending on a specific tokenizer.
+const charsPerToken = 4
+const tokensPerMessage = 4
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Append adds messages to the end of the session's history, creating the session if needed.
+// The history is capped at the configured maximum number of messages, dropping the oldest first,
+// and the session's retention period is extended from the time of the last append.
+func Append(ctx context.Context, sessionId string, messages []*Message) (bool, error) {
+	if sessionId == "" {
+		return false, errors.New("session id is required")
+	}
+
+	err := kvstore.Update(sessionKey(ctx, sessionId), config.SessionRetention, func(current []byte) ([]byte, error) {
+		history := []*Message{}
+		if current != nil {
+			if err := utils.JsonDeserialize(current, &history); err != nil {
+				return nil, err
+			}
+		}
+
+		history = append(history, messages...)
+		if limit := config.SessionMaxMessages; limit > 0 && len(history) > limit {
+			history = history[len(history)-limit:]
+		}
+
+		return utils.JsonSerialize(history)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Get returns the session's history, oldest message first.  If maxTokens is greater than zero,
+// only the most recent messages that fit within that (estimated) number of tokens are returned.
+func Get(ctx context.Context, sessionId string, maxTokens int32) ([]*Message, error) {
+	if sessionId == "" {
+		return nil, errors.New("session id is required")
+	}
+
+	history := []*Message{}
+	data, found := kvstore.Get(sessionKey(ctx, sessionId))
+	if !found {
+		return history, nil
+	}
+
+	if err := utils.JsonDeserialize(data, &history); err != nil {
+		return nil, err
+	}
+
+	if maxTokens <= 0 {
+		return history, nil
+	}
+
+	total := 0
+	start := len(history)
+	for start > 0 {
+		tokens := estimateTokens(history[start-1])
+		if total+tokens > int(maxTokens) {
+			break
+		}
+		total += tokens
+		start--
+	}
+
+	return history[start:], nil
+}
+
+// Clear removes the session and all of its history.
+func Clear(ctx context.Context, sessionId string) (bool, error) {
+	if sessionId == "" {
+		return false, errors.New("session id is required")
+	}
+
+	kvstore.Delete(sessionKey(ctx, sessionId))
+	return true, nil
+}
+
+// sessionKey returns the key of the session in the KV store, which is scoped to the plugin that uses it
+// if the manifest scopes data by plugin.
+func sessionKey(ctx context.Context, sessionId string) string {
+	return keyPrefix + datascope.Key(ctx, sessionId)
+}
+
+func estimateTokens(m *Message) int {
+	return (len(m.Content)+charsPerToken-1)/charsPerToken + tokensPerMessage
+}
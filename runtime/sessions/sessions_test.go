@@ -0,0 +1,70 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package sessions
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendAndGet(t *testing.T) {
+	ctx := context.Background()
+	config.SessionMaxMessages = 3
+
+	_, err := Append(ctx, "s1", []*Message{{Role: "user", Content: "one"}, {Role: "assistant", Content: "two"}})
+	assert.NoError(t, err)
+	_, err = Append(ctx, "s1", []*Message{{Role: "user", Content: "three"}, {Role: "assistant", Content: "four"}})
+	assert.NoError(t, err)
+
+	history, err := Get(ctx, "s1", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Message{
+		{Role: "assistant", Content: "two"},
+		{Role: "user", Content: "three"},
+		{Role: "assistant", Content: "four"},
+	}, history)
+
+	history, err = Get(ctx, "other", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+
+	_, err = Clear(ctx, "s1")
+	assert.NoError(t, err)
+	history, err = Get(ctx, "s1", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestGetWithTokenWindow(t *testing.T) {
+	ctx := context.Background()
+	config.SessionMaxMessages = 100
+
+	_, err := Append(ctx, "s2", []*Message{
+		{Role: "user", Content: strings.Repeat("a", 400)},
+		{Role: "assistant", Content: strings.Repeat("b", 40)},
+		{Role: "user", Content: strings.Repeat("c", 40)},
+	})
+	assert.NoError(t, err)
+
+	// each short message is estimated at 10 + 4 tokens
+	history, err := Get(ctx, "s2", 30)
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, "assistant", history[0].Role)
+
+	history, err = Get(ctx, "s2", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+}
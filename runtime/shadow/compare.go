@@ -0,0 +1,192 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package shadow
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/functions"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/storage"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+// A CorpusRequest is a single recorded function call to replay during a comparison.
+type CorpusRequest struct {
+	Function   string         `json:"function"`
+	Parameters map[string]any `json:"parameters"`
+}
+
+// ComparisonOptions describes a comparison job.  The requests to replay are given either inline,
+// or as the name of a JSON Lines file in the app's storage, with one CorpusRequest per line.
+type ComparisonOptions struct {
+	Plugin    string          `json:"plugin"`
+	Corpus    string          `json:"corpus,omitempty"`
+	Requests  []CorpusRequest `json:"requests,omitempty"`
+	Tolerance float64         `json:"tolerance,omitempty"`
+}
+
+type ComparisonMismatch struct {
+	Index       int          `json:"index"`
+	Function    string       `json:"function"`
+	LiveError   string       `json:"liveError,omitempty"`
+	ShadowError string       `json:"shadowError,omitempty"`
+	Differences []Difference `json:"differences,omitempty"`
+}
+
+type ComparisonReport struct {
+	Plugin        string                `json:"plugin"`
+	LiveBuildId   string                `json:"liveBuildId"`
+	ShadowBuildId string                `json:"shadowBuildId"`
+	Total         int                   `json:"total"`
+	Matched       int                   `json:"matched"`
+	Mismatched    int                   `json:"mismatched"`
+	Mismatches    []*ComparisonMismatch `json:"mismatches"`
+}
+
+var wasmHost wasmhost.WasmHost
+
+func Initialize(ctx context.Context) {
+	wasmHost = wasmhost.GetWasmHost(ctx)
+}
+
+// Compare replays a corpus of requests against both the live and shadow builds of a plugin,
+// and reports any requests whose results differ.
+func Compare(ctx context.Context, opts *ComparisonOptions) (*ComparisonReport, error) {
+	shadowPlugin := getPlugin(opts.Plugin)
+	if shadowPlugin == nil {
+		return nil, fmt.Errorf("no shadow build is loaded for plugin %s", opts.Plugin)
+	}
+
+	requests := opts.Requests
+	if opts.Corpus != "" {
+		var err error
+		requests, err = readCorpus(ctx, opts.Corpus)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(requests) == 0 {
+		return nil, errors.New("no requests to compare")
+	}
+
+	ctx = context.WithValue(ctx, utils.WasmHostContextKey, wasmHost)
+	ctx = context.WithValue(ctx, utils.ShadowExecutionContextKey, true)
+
+	report := &ComparisonReport{
+		Plugin:        opts.Plugin,
+		ShadowBuildId: shadowPlugin.BuildId(),
+		Mismatches:    []*ComparisonMismatch{},
+	}
+
+	for i, req := range requests {
+		liveFnInfo, err := wasmHost.GetFunctionInfo(req.Function)
+		if err != nil {
+			return nil, err
+		}
+		if liveFnInfo.Plugin().Name() != opts.Plugin {
+			return nil, fmt.Errorf("function %s does not belong to plugin %s", req.Function, opts.Plugin)
+		}
+		if wasmhost.IsIsolated(req.Function) {
+			return nil, fmt.Errorf("function %s requires process isolation, so its shadow build cannot be executed", req.Function)
+		}
+		report.LiveBuildId = liveFnInfo.Plugin().BuildId()
+
+		shadowFnInfo, ok := functions.NewFunctionInfo(req.Function, shadowPlugin, false)
+		if !ok {
+			return nil, fmt.Errorf("function %s is not exported by the shadow build of plugin %s", req.Function, opts.Plugin)
+		}
+
+		mismatch, err := compareCall(ctx, liveFnInfo, shadowFnInfo, req.Parameters, opts.Tolerance)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Total++
+		if mismatch == nil {
+			report.Matched++
+		} else {
+			mismatch.Index = i
+			mismatch.Function = req.Function
+			report.Mismatched++
+			report.Mismatches = append(report.Mismatches, mismatch)
+		}
+	}
+
+	logger.Info(ctx).
+		Str("plugin", opts.Plugin).
+		Str("live_build_id", report.LiveBuildId).
+		Str("shadow_build_id", report.ShadowBuildId).
+		Int("total", report.Total).
+		Int("mismatched", report.Mismatched).
+		Msg("Plugin build comparison completed.")
+
+	return report, nil
+}
+
+func compareCall(ctx context.Context, liveFnInfo, shadowFnInfo functions.FunctionInfo, parameters map[string]any, tolerance float64) (*ComparisonMismatch, error) {
+	live, liveErr := wasmHost.CallFunction(ctx, liveFnInfo, parameters)
+	shadow, shadowErr := wasmHost.CallFunction(ctx, shadowFnInfo, parameters)
+
+	if liveErr != nil || shadowErr != nil {
+		if liveErr != nil && shadowErr != nil {
+			return nil, nil
+		}
+		m := &ComparisonMismatch{}
+		if liveErr != nil {
+			m.LiveError = liveErr.Error()
+		}
+		if shadowErr != nil {
+			m.ShadowError = shadowErr.Error()
+		}
+		return m, nil
+	}
+
+	diffs, err := diffResults(live.Result(), shadow.Result(), tolerance)
+	if err != nil {
+		return nil, err
+	}
+	if len(diffs) == 0 {
+		return nil, nil
+	}
+	return &ComparisonMismatch{Differences: diffs}, nil
+}
+
+func readCorpus(ctx context.Context, filename string) ([]CorpusRequest, error) {
+	content, err := storage.GetFileContents(ctx, filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read corpus %s: %w", filename, err)
+	}
+
+	requests := []CorpusRequest{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var req CorpusRequest
+		if err := utils.JsonDeserialize(data, &req); err != nil {
+			return nil, fmt.Errorf("invalid request on line %d of corpus %s: %w", line, filename, err)
+		}
+		requests = append(requests, req)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read corpus %s: %w", filename, err)
+	}
+
+	return requests, nil
+}
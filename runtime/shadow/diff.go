@@ -0,0 +1,103 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package shadow
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// A Difference is a location where two results differ, identified by a JSON path.
+type Difference struct {
+	Path   string `json:"path"`
+	Live   any    `json:"live"`
+	Shadow any    `json:"shadow"`
+}
+
+// diffResults structurally compares two function results after normalizing them to JSON values.
+// Numbers are considered equal if they differ by no more than the tolerance, relative to their magnitude.
+func diffResults(live, shadow any, tolerance float64) ([]Difference, error) {
+	a, err := normalize(live)
+	if err != nil {
+		return nil, err
+	}
+	b, err := normalize(shadow)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := []Difference{}
+	diffValues("$", a, b, tolerance, &diffs)
+	return diffs, nil
+}
+
+func normalize(v any) (any, error) {
+	j, err := utils.JsonSerialize(v)
+	if err != nil {
+		return nil, err
+	}
+	var result any
+	if err := json.Unmarshal(j, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func diffValues(path string, a, b any, tolerance float64, diffs *[]Difference) {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := utils.MapKeys(av)
+		for k := range bv {
+			if _, found := av[k]; !found {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			diffValues(path+"."+k, av[k], bv[k], tolerance, diffs)
+		}
+		return
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			break
+		}
+		for i := range av {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), av[i], bv[i], tolerance, diffs)
+		}
+		return
+	case float64:
+		if bv, ok := b.(float64); ok && floatsEqual(av, bv, tolerance) {
+			return
+		}
+	default:
+		if reflect.DeepEqual(a, b) {
+			return
+		}
+	}
+
+	*diffs = append(*diffs, Difference{Path: path, Live: a, Shadow: b})
+}
+
+func floatsEqual(a, b, tolerance float64) bool {
+	if a == b {
+		return true
+	}
+	return math.Abs(a-b) <= tolerance*max(1, math.Abs(a), math.Abs(b))
+}
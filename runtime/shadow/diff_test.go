@@ -0,0 +1,65 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package shadow
+
+import (
+	"testing"
+)
+
+func Test_DiffResults(t *testing.T) {
+	live := map[string]any{
+		"name":   "a",
+		"score":  0.12345678,
+		"tags":   []string{"x", "y"},
+		"nested": map[string]any{"count": 3},
+	}
+	shadow := map[string]any{
+		"name":   "a",
+		"score":  0.12345679,
+		"tags":   []string{"x", "z"},
+		"nested": map[string]any{"count": 4},
+		"extra":  true,
+	}
+
+	diffs, err := diffResults(live, shadow, 1e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"$.extra", "$.nested.count", "$.tags[1]"}
+	if len(diffs) != len(expected) {
+		t.Fatalf("expected %d differences, got %d: %v", len(expected), len(diffs), diffs)
+	}
+	for i, d := range diffs {
+		if d.Path != expected[i] {
+			t.Errorf("expected difference at %s, got %s", expected[i], d.Path)
+		}
+	}
+}
+
+func Test_DiffResults_NoTolerance(t *testing.T) {
+	diffs, err := diffResults([]float64{1.0, 2.0}, []float64{1.0, 2.0000001}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 || diffs[0].Path != "$[1]" {
+		t.Errorf("expected one difference at $[1], got %v", diffs)
+	}
+}
+
+func Test_DiffResults_Equal(t *testing.T) {
+	diffs, err := diffResults(map[string]any{"a": []any{1, "b", nil}}, map[string]any{"a": []any{1, "b", nil}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("expected no differences, got %v", diffs)
+	}
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package shadow duplicates a sample of live function calls to a shadow build of a plugin,
+// so that a new build can be validated against production traffic before it replaces the live build.
+// Shadow builds are plugin files named with a ".shadow.wasm" suffix.  Their results are discarded,
+// but whether they match the live results, and how long they took, are recorded in metrics and logs.
+// Note that host functions called by a shadow build are executed normally, so shadow builds should
+// only be used for functions whose side effects can safely be repeated.
+package shadow
+
+import (
+	"bytes"
+	"context"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/functions"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/metrics"
+	"github.com/hypermodeinc/modus/runtime/plugins"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+const fileSuffix = ".shadow.wasm"
+const shadowTimeout = 60 * time.Second
+
+var shadowPlugins = map[string]*plugins.Plugin{}
+var mu sync.RWMutex
+
+// IsShadowFile reports whether the plugin file is a shadow build.
+func IsShadowFile(filename string) bool {
+	return strings.HasSuffix(filename, fileSuffix)
+}
+
+// IsShadowExecution reports whether the context belongs to a shadow function call.
+func IsShadowExecution(ctx context.Context) bool {
+	shadow, _ := ctx.Value(utils.ShadowExecutionContextKey).(bool)
+	return shadow
+}
+
+// SetPlugin makes the plugin the shadow build for the live plugin of the same name.
+func SetPlugin(plugin *plugins.Plugin) {
+	mu.Lock()
+	defer mu.Unlock()
+	shadowPlugins[plugin.Name()] = plugin
+}
+
+// RemovePlugin removes the shadow build loaded from the given file, and returns it if found.
+func RemovePlugin(filename string) *plugins.Plugin {
+	mu.Lock()
+	defer mu.Unlock()
+	for name, p := range shadowPlugins {
+		if p.FileName == filename {
+			delete(shadowPlugins, name)
+			return p
+		}
+	}
+	return nil
+}
+
+func getPlugin(name string) *plugins.Plugin {
+	mu.RLock()
+	defer mu.RUnlock()
+	return shadowPlugins[name]
+}
+
+// Mirror asynchronously calls the same function in the shadow build of the live function's plugin,
+// for the configured percentage of calls, and records how the shadow call compares to the live call.
+func Mirror(ctx context.Context, fnInfo functions.FunctionInfo, parameters map[string]any, live wasmhost.ExecutionInfo, liveErr error, liveDuration time.Duration) {
+	if config.ShadowTrafficPercent <= 0 || rand.Float64()*100 >= config.ShadowTrafficPercent {
+		return
+	}
+
+	shadowPlugin := getPlugin(fnInfo.Plugin().Name())
+	if shadowPlugin == nil {
+		return
+	}
+
+	// only the live build of a function can be executed in a sandboxed worker process
+	fnName := fnInfo.Name()
+	if wasmhost.IsIsolated(fnName) {
+		metrics.ShadowExecutionsNum.WithLabelValues(fnName, "isolated").Inc()
+		return
+	}
+
+	shadowFnInfo, ok := functions.NewFunctionInfo(fnName, shadowPlugin, false)
+	if !ok {
+		metrics.ShadowExecutionsNum.WithLabelValues(fnName, "missing").Inc()
+		return
+	}
+
+	// the shadow call must not delay or be cancelled with the live request
+	ctx = context.WithValue(context.WithoutCancel(ctx), utils.ShadowExecutionContextKey, true)
+	ctx = wasmhost.ClearSlotHold(ctx)
+	ctx = context.WithValue(ctx, utils.CollectionWritesContextKey, nil)
+	ctx = context.WithValue(ctx, utils.PriorityContextKey, utils.PriorityBatch)
+
+	var liveResult any
+	if live != nil {
+		liveResult = live.Result()
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, shadowTimeout)
+		defer cancel()
+
+		start := time.Now()
+		info, err := wasmhost.GetWasmHost(ctx).CallFunction(ctx, shadowFnInfo, parameters)
+		duration := time.Since(start)
+
+		var shadowResult any
+		if info != nil {
+			shadowResult = info.Result()
+		}
+
+		outcome := compare(liveResult, liveErr, shadowResult, err)
+		metrics.ShadowExecutionsNum.WithLabelValues(fnName, outcome).Inc()
+		metrics.ShadowDurationDeltaMilliseconds.WithLabelValues(fnName).Observe(float64((duration - liveDuration).Milliseconds()))
+
+		evt := logger.Info(ctx)
+		if outcome != "match" {
+			evt = logger.Warn(ctx)
+		}
+		evt.Str("function", fnName).
+			Str("build_id", shadowPlugin.BuildId()).
+			Str("outcome", outcome).
+			Dur("live_duration_ms", liveDuration).
+			Dur("shadow_duration_ms", duration).
+			Msg("Shadow function call completed.")
+	}()
+}
+
+func compare(liveResult any, liveErr error, shadowResult any, shadowErr error) string {
+	switch {
+	case liveErr != nil && shadowErr != nil:
+		return "match"
+	case shadowErr != nil:
+		return "shadow_error"
+	case liveErr != nil:
+		return "live_error"
+	}
+
+	a, errA := utils.JsonSerialize(liveResult)
+	b, errB := utils.JsonSerialize(shadowResult)
+	if errA != nil || errB != nil || !bytes.Equal(a, b) {
+		return "mismatch"
+	}
+	return "match"
+}
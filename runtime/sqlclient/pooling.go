@@ -16,6 +16,7 @@ import (
 	"github.com/hypermodeinc/modus/lib/manifest"
 	"github.com/hypermodeinc/modus/runtime/manifestdata"
 	"github.com/hypermodeinc/modus/runtime/secrets"
+	"github.com/hypermodeinc/modus/runtime/tlsconfig"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -69,7 +70,28 @@ func createDS(ctx context.Context, dsName string) (*postgresqlDS, error) {
 		return nil, fmt.Errorf("failed to apply secrets to connection string for connection [%s]: %w", dsName, err)
 	}
 
-	pool, err := pgxpool.New(ctx, connStr)
+	cfg, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection string for connection [%s]: %w", dsName, err)
+	}
+
+	if conf.TLS != nil {
+		tlsConfig, err := tlsconfig.ForConnection(ctx, info, conf.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure TLS for connection [%s]: %w", dsName, err)
+		}
+		tlsConfig.ServerName = cfg.ConnConfig.Host
+		cfg.ConnConfig.TLSConfig = tlsConfig
+		for _, fallback := range cfg.ConnConfig.Fallbacks {
+			if fallback.TLSConfig != nil {
+				fallbackConfig := tlsConfig.Clone()
+				fallbackConfig.ServerName = fallback.Host
+				fallback.TLSConfig = fallbackConfig
+			}
+		}
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to postgres connection [%s]: %w", dsName, err)
 	}
@@ -11,9 +11,6 @@ package storage
 
 import (
 	"context"
-	"fmt"
-	"io"
-	"path"
 
 	"github.com/hypermodeinc/modus/runtime/aws"
 	"github.com/hypermodeinc/modus/runtime/config"
@@ -23,7 +20,7 @@ import (
 )
 
 type awsStorageProvider struct {
-	s3Client *s3.Client
+	bucketStorageProvider
 }
 
 func (stg *awsStorageProvider) initialize(ctx context.Context) {
@@ -35,64 +32,8 @@ func (stg *awsStorageProvider) initialize(ctx context.Context) {
 	// This is safe to hold onto for the lifetime of the application.
 	// See https://github.com/aws/aws-sdk-go-v2/discussions/2566
 	cfg := aws.GetAwsConfig()
-	stg.s3Client = s3.NewFromConfig(cfg)
-}
-
-func (stg *awsStorageProvider) listFiles(ctx context.Context, patterns ...string) ([]FileInfo, error) {
-
-	input := &s3.ListObjectsV2Input{
-		Bucket: &config.S3Bucket,
-		Prefix: &config.S3Path,
-	}
-
-	result, err := stg.s3Client.ListObjectsV2(ctx, input)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list files in S3 bucket: %w", err)
-	}
-
-	var files = make([]FileInfo, 0, *result.KeyCount)
-	for _, obj := range result.Contents {
-
-		_, filename := path.Split(*obj.Key)
-
-		matched := false
-		for _, pattern := range patterns {
-			if match, err := path.Match(pattern, filename); err == nil && match {
-				matched = true
-				break
-			}
-		}
-		if !matched {
-			continue
-		}
-
-		files = append(files, FileInfo{
-			Name:         filename,
-			Hash:         *obj.ETag,
-			LastModified: *obj.LastModified,
-		})
-	}
-
-	return files, nil
-}
-
-func (stg *awsStorageProvider) getFileContents(ctx context.Context, name string) ([]byte, error) {
-	key := path.Join(config.S3Path, name)
-	input := &s3.GetObjectInput{
-		Bucket: &config.S3Bucket,
-		Key:    &key,
-	}
-
-	obj, err := stg.s3Client.GetObject(ctx, input)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get file %s from S3: %w", name, err)
-	}
-
-	defer obj.Body.Close()
-	content, err := io.ReadAll(obj.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read contents of file %s from S3: %w", name, err)
-	}
-
-	return content, nil
+	stg.client = s3.NewFromConfig(cfg)
+	stg.bucket = config.S3Bucket
+	stg.prefix = config.S3Path
+	stg.cache = newDownloadCache(ctx)
 }
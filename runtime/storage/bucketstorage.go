@@ -0,0 +1,126 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// bucketStorageProvider reads files from an S3-compatible object storage bucket.
+// Changes are detected by comparing the ETag of each object between polls.
+type bucketStorageProvider struct {
+	client *s3.Client
+	bucket string
+	prefix string
+
+	// the ETag and size of each file, as of the most recent listing
+	objects   map[string]objectInfo
+	objectsMu sync.Mutex
+
+	// the local cache for large files, or nil if caching is unavailable
+	cache *downloadCache
+}
+
+type objectInfo struct {
+	etag string
+	size int64
+}
+
+func (stg *bucketStorageProvider) listFiles(ctx context.Context, patterns ...string) ([]FileInfo, error) {
+
+	input := &s3.ListObjectsV2Input{
+		Bucket: &stg.bucket,
+		Prefix: &stg.prefix,
+	}
+
+	var files []FileInfo
+	var sizes []int64
+	paginator := s3.NewListObjectsV2Paginator(stg.client, input)
+	for paginator.HasMorePages() {
+		result, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files in bucket %s: %w", stg.bucket, err)
+		}
+
+		for _, obj := range result.Contents {
+
+			_, filename := path.Split(*obj.Key)
+
+			matched := false
+			for _, pattern := range patterns {
+				if match, err := path.Match(pattern, filename); err == nil && match {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+
+			files = append(files, FileInfo{
+				Name:         filename,
+				Hash:         *obj.ETag,
+				LastModified: *obj.LastModified,
+			})
+			sizes = append(sizes, aws.ToInt64(obj.Size))
+		}
+	}
+
+	stg.objectsMu.Lock()
+	defer stg.objectsMu.Unlock()
+	if stg.objects == nil {
+		stg.objects = make(map[string]objectInfo, len(files))
+	}
+	for i, file := range files {
+		stg.objects[file.Name] = objectInfo{etag: file.Hash, size: sizes[i]}
+	}
+
+	return files, nil
+}
+
+func (stg *bucketStorageProvider) getFileContents(ctx context.Context, name string) ([]byte, error) {
+	key := path.Join(stg.prefix, name)
+	input := &s3.GetObjectInput{
+		Bucket: &stg.bucket,
+		Key:    &key,
+	}
+
+	// Only read the version of the file that was listed, so that a file being replaced while it is read
+	// is never seen partially updated.  If it has changed, the next poll will detect the new version.
+	stg.objectsMu.Lock()
+	info, ok := stg.objects[name]
+	stg.objectsMu.Unlock()
+	if ok {
+		input.IfMatch = &info.etag
+		if stg.cache != nil && info.size >= largeFileSize {
+			return stg.downloadLargeFile(ctx, name, key, info)
+		}
+	}
+
+	obj, err := stg.client.GetObject(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file %s from bucket %s: %w", name, stg.bucket, err)
+	}
+
+	defer obj.Body.Close()
+	content, err := io.ReadAll(obj.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contents of file %s from bucket %s: %w", name, stg.bucket, err)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/logger"
+)
+
+// cached files that haven't been used for this long are removed when the cache is opened
+const downloadCacheRetention = 7 * 24 * time.Hour
+
+// downloadCache is a local content-addressed cache of files downloaded from remote storage,
+// so that large files aren't downloaded again when the runtime restarts.
+type downloadCache struct {
+	dir string
+}
+
+// newDownloadCache opens the download cache, or returns nil if it can't be used.
+func newDownloadCache(ctx context.Context) *downloadCache {
+	dir := config.PluginCacheDir
+	if dir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			cacheDir = os.TempDir()
+		}
+		dir = filepath.Join(cacheDir, "modus", "downloads")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.Warn(ctx).Err(err).Str("path", dir).Msg("Unable to create the download cache directory.  Large files will not be cached.")
+		return nil
+	}
+
+	c := &downloadCache{dir: dir}
+	c.prune(ctx)
+	return c
+}
+
+// digestKey returns the cache key for the content of an object.  The SHA-256 checksum is used when
+// the storage provides a full-object checksum, and otherwise the ETag, which identifies the content of the object.
+func digestKey(checksumSHA256, etag string) string {
+	if sum, ok := decodeSHA256(checksumSHA256); ok {
+		return "sha256-" + hex.EncodeToString(sum)
+	}
+	sum := sha256.Sum256([]byte(strings.Trim(etag, `"`)))
+	return "etag-" + hex.EncodeToString(sum[:])
+}
+
+// decodeSHA256 decodes a base64-encoded SHA-256 checksum.  Composite checksums of multipart uploads
+// don't cover the whole object, and are not decoded.
+func decodeSHA256(checksum string) ([]byte, bool) {
+	if checksum == "" {
+		return nil, false
+	}
+	sum, err := base64.StdEncoding.DecodeString(checksum)
+	if err != nil || len(sum) != sha256.Size {
+		return nil, false
+	}
+	return sum, true
+}
+
+func (c *downloadCache) path(digest string) string {
+	return filepath.Join(c.dir, digest)
+}
+
+func (c *downloadCache) partialPath(digest string) string {
+	return filepath.Join(c.dir, digest+".partial")
+}
+
+// get returns the cached content for the digest, if present.
+func (c *downloadCache) get(digest string) ([]byte, bool) {
+	p := c.path(digest)
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return nil, false
+	}
+
+	// mark the entry as recently used, so it isn't pruned
+	now := time.Now()
+	_ = os.Chtimes(p, now, now)
+
+	return content, true
+}
+
+// commit moves a completed download into the cache.
+func (c *downloadCache) commit(digest string) error {
+	return os.Rename(c.partialPath(digest), c.path(digest))
+}
+
+func (c *downloadCache) prune(ctx context.Context) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Now().Add(-downloadCacheRetention)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.IsDir() || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Warn(ctx).Err(err).Str("filename", entry.Name()).Msg("Unable to remove an expired file from the download cache.")
+		}
+	}
+}
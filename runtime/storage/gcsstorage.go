@@ -0,0 +1,57 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package storage
+
+import (
+	"context"
+	"os"
+
+	hmAws "github.com/hypermodeinc/modus/runtime/aws"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/logger"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Google Cloud Storage is accessed through its S3-compatible XML API, using an HMAC key.
+// See https://cloud.google.com/storage/docs/interoperability
+const gcsEndpoint = "https://storage.googleapis.com"
+
+type gcsStorageProvider struct {
+	bucketStorageProvider
+}
+
+func (stg *gcsStorageProvider) initialize(ctx context.Context) {
+	if config.GcsBucket == "" {
+		logger.Fatal(ctx).Msg("A GCS bucket is required when using GCS storage.  Exiting.")
+	}
+
+	accessId := os.Getenv("MODUS_GCS_HMAC_ACCESS_ID")
+	secret := os.Getenv("MODUS_GCS_HMAC_SECRET")
+	if accessId == "" || secret == "" {
+		logger.Fatal(ctx).Msg("The MODUS_GCS_HMAC_ACCESS_ID and MODUS_GCS_HMAC_SECRET environment variables are required when using GCS storage.  Exiting.")
+	}
+
+	creds := aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: accessId, SecretAccessKey: secret, Source: "MODUS_GCS_HMAC"}, nil
+	})
+
+	stg.client = s3.New(s3.Options{
+		BaseEndpoint: aws.String(gcsEndpoint),
+		Region:       "auto",
+		Credentials:  aws.NewCredentialsCache(creds),
+		UsePathStyle: true,
+		HTTPClient:   hmAws.NewHttpClient(),
+	})
+	stg.bucket = config.GcsBucket
+	stg.prefix = config.GcsPath
+	stg.cache = newDownloadCache(ctx)
+}
@@ -0,0 +1,210 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/logger"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// files at least this large are downloaded resumably, and cached locally
+const largeFileSize = 16 * 1024 * 1024
+
+const maxDownloadAttempts = 5
+
+// the ETag of an unencrypted object that was not uploaded in parts is the MD5 digest of its content
+var md5EtagRegex = regexp.MustCompile(`^"?([0-9a-fA-F]{32})"?$`)
+
+// downloadLargeFile downloads a large file, resuming from where it left off if the download is
+// interrupted, and verifying its checksum.  Completed downloads are kept in the local cache,
+// so that they don't need to be downloaded again after a restart.
+func (stg *bucketStorageProvider) downloadLargeFile(ctx context.Context, name, key string, info objectInfo) ([]byte, error) {
+	head, err := stg.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       &stg.bucket,
+		Key:          &key,
+		IfMatch:      &info.etag,
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file %s from bucket %s: %w", name, stg.bucket, err)
+	}
+
+	checksum := aws.ToString(head.ChecksumSHA256)
+	size := aws.ToInt64(head.ContentLength)
+	digest := digestKey(checksum, info.etag)
+
+	if content, ok := stg.cache.get(digest); ok {
+		logger.Info(ctx).Str("filename", name).Str("digest", digest).Msg("Using cached copy of file.")
+		return content, nil
+	}
+
+	partialPath := stg.cache.partialPath(digest)
+	for attempt := 1; ; attempt++ {
+		err = stg.resumeDownload(ctx, name, key, info.etag, size, partialPath)
+		if err == nil {
+			break
+		}
+		if attempt == maxDownloadAttempts || ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to download file %s from bucket %s: %w", name, stg.bucket, err)
+		}
+
+		delay := time.Duration(attempt) * time.Second
+		logger.Warn(ctx).Err(err).
+			Str("filename", name).
+			Int("attempt", attempt).
+			Dur("retry_in", delay).
+			Msg("Download interrupted.  Resuming.")
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	content, err := os.ReadFile(partialPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read downloaded file %s: %w", name, err)
+	}
+
+	// the ETag of an encrypted object is not an MD5 digest of its content
+	etagIsMD5 := aws.ToString(head.SSECustomerAlgorithm) == "" &&
+		head.ServerSideEncryption != types.ServerSideEncryptionAwsKms &&
+		head.ServerSideEncryption != types.ServerSideEncryptionAwsKmsDsse
+
+	if err := verifyChecksum(content, checksum, info.etag, etagIsMD5); err != nil {
+		_ = os.Remove(partialPath)
+		return nil, fmt.Errorf("downloaded file %s is corrupt: %w", name, err)
+	}
+
+	if err := stg.cache.commit(digest); err != nil {
+		logger.Warn(ctx).Err(err).Str("filename", name).Msg("Unable to cache downloaded file.")
+	}
+
+	return content, nil
+}
+
+// resumeDownload downloads the remainder of the file, appending to any partial download already on disk.
+func (stg *bucketStorageProvider) resumeDownload(ctx context.Context, name, key, etag string, size int64, partialPath string) error {
+	f, err := os.OpenFile(partialPath, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	offset := stat.Size()
+	if offset > size {
+		offset = 0
+	}
+	if offset == size {
+		return nil
+	}
+	if err := f.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket:  &stg.bucket,
+		Key:     &key,
+		IfMatch: &etag,
+	}
+	if offset > 0 {
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-", offset))
+		logger.Info(ctx).Str("filename", name).Int64("offset", offset).Int64("size", size).Msg("Resuming partial download.")
+	}
+
+	obj, err := stg.client.GetObject(ctx, input)
+	if err != nil {
+		return err
+	}
+	defer obj.Body.Close()
+
+	w := &progressWriter{ctx: ctx, name: name, written: offset, size: size}
+	if _, err := io.Copy(io.MultiWriter(f, w), obj.Body); err != nil {
+		return err
+	}
+
+	if w.written != size {
+		return fmt.Errorf("expected %d bytes, but received %d", size, w.written)
+	}
+
+	return nil
+}
+
+func verifyChecksum(content []byte, checksumSHA256, etag string, etagIsMD5 bool) error {
+	if expected, ok := decodeSHA256(checksumSHA256); ok {
+		actual := sha256.Sum256(content)
+		if !bytes.Equal(actual[:], expected) {
+			return errors.New("SHA-256 checksum mismatch")
+		}
+		return nil
+	}
+
+	if m := md5EtagRegex.FindStringSubmatch(etag); m != nil && etagIsMD5 {
+		actual := md5.Sum(content)
+		if hex.EncodeToString(actual[:]) != strings.ToLower(m[1]) {
+			return errors.New("MD5 checksum mismatch")
+		}
+	}
+
+	return nil
+}
+
+// progressWriter logs the progress of a download, at every tenth of the file.
+type progressWriter struct {
+	ctx     context.Context
+	name    string
+	written int64
+	size    int64
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	if w.size <= 0 {
+		return len(p), nil
+	}
+
+	before := w.written * 10 / w.size
+	w.written += int64(len(p))
+	after := w.written * 10 / w.size
+
+	if after > before {
+		logger.Info(w.ctx).
+			Str("filename", w.name).
+			Int64("downloaded", w.written).
+			Int64("size", w.size).
+			Msgf("Downloading file: %d%% complete.", after*10)
+	}
+
+	return len(p), nil
+}
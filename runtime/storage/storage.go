@@ -36,9 +36,12 @@ func Initialize(ctx context.Context) {
 	span, ctx := utils.NewSentrySpanForCurrentFunc(ctx)
 	defer span.Finish()
 
-	if config.UseAwsStorage {
+	switch {
+	case config.UseAwsStorage:
 		provider = &awsStorageProvider{}
-	} else {
+	case config.UseGcsStorage:
+		provider = &gcsStorageProvider{}
+	default:
 		provider = &localStorageProvider{}
 	}
 
@@ -11,10 +11,13 @@ package storage
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/hypermodeinc/modus/runtime/config"
 	"github.com/hypermodeinc/modus/runtime/logger"
+
+	"golang.org/x/sync/errgroup"
 )
 
 type StorageMonitor struct {
@@ -24,6 +27,10 @@ type StorageMonitor struct {
 	Modified func(FileInfo) error
 	Removed  func(FileInfo) error
 	Changed  func([]error)
+
+	// Concurrency is the maximum number of Added and Modified callbacks that may run at the same time
+	// during a single pass.  The default of 1 runs them one at a time.
+	Concurrency int
 }
 
 type monitoredFile struct {
@@ -49,6 +56,16 @@ func (sm *StorageMonitor) Start(ctx context.Context) {
 
 		var loggedError = false
 
+		// waits for the next cycle, returning false if the monitor should stop
+		wait := func() bool {
+			select {
+			case <-ticker.C:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
 		for {
 			files, err := provider.listFiles(ctx, sm.patterns...)
 			if err != nil {
@@ -57,6 +74,9 @@ func (sm *StorageMonitor) Start(ctx context.Context) {
 					logger.Err(ctx, err).Msgf("Failed to list %s files.", sm.patterns)
 					loggedError = true
 				}
+				if !wait() {
+					return
+				}
 				continue
 			} else {
 				loggedError = false
@@ -65,31 +85,39 @@ func (sm *StorageMonitor) Start(ctx context.Context) {
 			// Compare list of files retrieved to existing files
 			var changed = false
 			var errors []error
+			var errorsMutex sync.Mutex
 			var thisTime = time.Now()
+			g := errgroup.Group{}
+			g.SetLimit(max(sm.Concurrency, 1))
+			notify := func(callback func(FileInfo) error, file FileInfo) {
+				g.Go(func() error {
+					if err := callback(file); err != nil {
+						errorsMutex.Lock()
+						errors = append(errors, err)
+						errorsMutex.Unlock()
+					}
+					return nil
+				})
+			}
 			for _, file := range files {
 				existing, found := sm.files[file.Name]
 				if !found {
 					// New file
 					changed = true
 					sm.files[file.Name] = &monitoredFile{file, thisTime}
-					err := sm.Added(file)
-					if err != nil {
-						errors = append(errors, err)
-					}
+					notify(sm.Added, file)
 				} else if file.Hash != existing.file.Hash ||
 					(file.Hash == "" && file.LastModified.After(existing.file.LastModified)) {
 					// Modified file
 					changed = true
 					sm.files[file.Name] = &monitoredFile{file, thisTime}
-					err := sm.Modified(file)
-					if err != nil {
-						errors = append(errors, err)
-					}
+					notify(sm.Modified, file)
 				} else {
 					// No change
 					existing.lastSeen = thisTime
 				}
 			}
+			_ = g.Wait()
 
 			// Check for removed files
 			for name, file := range sm.files {
@@ -109,10 +137,7 @@ func (sm *StorageMonitor) Start(ctx context.Context) {
 			}
 
 			// Wait for next cycle
-			select {
-			case <-ticker.C:
-				continue
-			case <-ctx.Done():
+			if !wait() {
 				return
 			}
 		}
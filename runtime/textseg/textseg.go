@@ -0,0 +1,270 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package textseg splits text into words, sentences and paragraphs, following the rules of
+// Unicode Standard Annex #29 with the tailorings that ICU applies by default.
+// Scripts that are written without spaces between words are not segmented with a dictionary.
+// Each Han ideograph and Hiragana character is a word, and runs of other such scripts are a single word.
+package textseg
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Granularities of segmentation.
+const (
+	Word      = "word"
+	Sentence  = "sentence"
+	Paragraph = "paragraph"
+)
+
+// Segment splits the text into segments of the given granularity.
+// Surrounding whitespace is removed from each segment, and empty segments are omitted.
+// Word segments include only words and numbers, not punctuation or whitespace.
+func Segment(text, granularity string) ([]string, error) {
+	switch granularity {
+	case Word:
+		return Words(text), nil
+	case Sentence:
+		return Sentences(text), nil
+	case Paragraph:
+		return Paragraphs(text), nil
+	default:
+		return nil, fmt.Errorf("unknown text granularity %q, must be one of word, sentence or paragraph", granularity)
+	}
+}
+
+// Words returns the words and numbers of the text.
+func Words(text string) []string {
+	var words []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if isIdeographic(r) {
+			end := i + 1
+			for end < len(runes) && isExtend(runes[end]) {
+				end++
+			}
+			words = append(words, string(runes[i:end]))
+			i = end
+			continue
+		}
+		if !isWordChar(r) {
+			i++
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) {
+			c := runes[end]
+			if isWordChar(c) && !isIdeographic(c) {
+				end++
+				continue
+			}
+			if end+1 < len(runes) && joinsWord(runes[end-1], c, runes[end+1]) {
+				end += 2
+				continue
+			}
+			break
+		}
+		words = append(words, string(runes[i:end]))
+		i = end
+	}
+	return words
+}
+
+// Sentences returns the sentences of the text.
+func Sentences(text string) []string {
+	var sentences []string
+	for _, para := range Paragraphs(text) {
+		runes := []rune(para)
+		start := 0
+		for i := 0; i < len(runes); i++ {
+			if !isTerminator(runes[i]) {
+				continue
+			}
+
+			// a sentence ends after a run of terminators, followed by any closing punctuation and spaces
+			end := i
+			sterm := false
+			for end < len(runes) && isTerminator(runes[end]) {
+				if !isATerm(runes[end]) {
+					sterm = true
+				}
+				end++
+			}
+			for end < len(runes) && isClose(runes[end]) {
+				end++
+			}
+			next := end
+			for next < len(runes) && unicode.IsSpace(runes[next]) {
+				next++
+			}
+
+			if next < len(runes) && !sterm && !breaksAfterATerm(runes, start, i, end, next) {
+				i = end - 1
+				continue
+			}
+
+			sentences = appendTrimmed(sentences, string(runes[start:end]))
+			start = next
+			i = next - 1
+		}
+		sentences = appendTrimmed(sentences, string(runes[start:]))
+	}
+	return sentences
+}
+
+// Paragraphs returns the paragraphs of the text, which are separated by blank lines or paragraph separators.
+func Paragraphs(text string) []string {
+	var paragraphs []string
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case r == '\u2029':
+			paragraphs = appendTrimmed(paragraphs, text[start:i])
+			start = i + size
+		case r == '\n':
+			// a blank line is a newline followed by another, with only other whitespace between them
+			j := i + size
+			for j < len(text) && (text[j] == ' ' || text[j] == '\t' || text[j] == '\r') {
+				j++
+			}
+			if j < len(text) && text[j] == '\n' {
+				paragraphs = appendTrimmed(paragraphs, text[start:i])
+				start = j + 1
+				i = j + 1
+				continue
+			}
+		}
+		i += size
+	}
+	return appendTrimmed(paragraphs, text[start:])
+}
+
+func appendTrimmed(segments []string, s string) []string {
+	if s = strings.TrimSpace(s); s != "" {
+		segments = append(segments, s)
+	}
+	return segments
+}
+
+// breaksAfterATerm reports whether a sentence ends after a full stop at position i, when the text continues.
+// The terminators and closing punctuation end before position end, and the next sentence would begin at next.
+func breaksAfterATerm(runes []rune, start, i, end, next int) bool {
+	spaced := next > end
+
+	// a full stop between digits, or between uppercase letters, is not the end of a sentence (3.14, U.S.A)
+	if !spaced {
+		if unicode.IsDigit(runes[next]) {
+			return false
+		}
+		if i > start && unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[next]) {
+			return false
+		}
+	}
+
+	// nor is one that is followed by a lowercase word
+	for j := next; j < len(runes); j++ {
+		r := runes[j]
+		if unicode.IsLetter(r) {
+			if unicode.IsLower(r) {
+				return false
+			}
+			break
+		}
+		if isTerminator(r) {
+			break
+		}
+	}
+
+	// nor is one after a common abbreviation or an initial
+	word := precedingWord(runes, start, i)
+	if abbreviations[word] {
+		return false
+	}
+	if r, size := utf8.DecodeRuneInString(word); size == len(word) && unicode.IsUpper(r) {
+		return false
+	}
+
+	return true
+}
+
+// precedingWord returns the word that ends at position i, including any full stops within it.
+func precedingWord(runes []rune, start, i int) string {
+	j := i
+	for j > start && (unicode.IsLetter(runes[j-1]) || isATerm(runes[j-1])) {
+		j--
+	}
+	return string(runes[j:i])
+}
+
+// abbreviations that are usually followed by a full stop within a sentence
+var abbreviations = map[string]bool{
+	"Mr": true, "Mrs": true, "Ms": true, "Dr": true, "Prof": true, "Sr": true, "Jr": true, "St": true, "Mt": true,
+	"Capt": true, "Col": true, "Gen": true, "Gov": true, "Lt": true, "Rep": true, "Sen": true, "Sgt": true,
+	"Co": true, "Corp": true, "Inc": true, "Ltd": true, "No": true, "Fig": true, "vs": true, "cf": true,
+	"e.g": true, "i.e": true, "approx": true,
+}
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Pc, r) || isExtend(r)
+}
+
+func isExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) || r == '\u200d'
+}
+
+func isIdeographic(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana)
+}
+
+// joinsWord reports whether the character c joins the characters before and after it into a single word,
+// such as the apostrophe in "can't", or the separators in "3.14" and "1,000".
+func joinsWord(before, c, after rune) bool {
+	if !isWordChar(after) || isIdeographic(after) {
+		return false
+	}
+	letters := unicode.IsLetter(before) && unicode.IsLetter(after)
+	digits := unicode.IsNumber(before) && unicode.IsNumber(after)
+	switch c {
+	case '\'', '’', '.', '․', '﹒', '．':
+		return letters || digits
+	case '·', '‧':
+		return letters
+	case ',', ';', '٬', '﹐', '﹔', '，', '；':
+		return digits
+	}
+	return false
+}
+
+func isATerm(r rune) bool {
+	return r == '.' || r == '․' || r == '﹒' || r == '．'
+}
+
+func isTerminator(r rune) bool {
+	switch r {
+	case '!', '?', '‼', '‽', '⁇', '⁈', '⁉',
+		'؟', '۔', '।', '॥', '。', '！', '？', '｡':
+		return true
+	}
+	return isATerm(r)
+}
+
+func isClose(r rune) bool {
+	switch r {
+	case '"', '\'', '’', '”', '»', '›':
+		return true
+	}
+	return unicode.In(r, unicode.Pe, unicode.Pf)
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package textseg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"Hello, world!", []string{"Hello", "world"}},
+		{"I can't pay $1,000.50 for snake_case e.g. this", []string{"I", "can't", "pay", "1,000.50", "for", "snake_case", "e.g", "this"}},
+		{"well-known 'quoted' words", []string{"well", "known", "quoted", "words"}},
+		{"東京へ行きます", []string{"東", "京", "へ", "行", "き", "ま", "す"}},
+		{"カタカナ and café", []string{"カタカナ", "and", "café"}},
+		{"  ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			require.Equal(t, tt.expected, Words(tt.text))
+		})
+	}
+}
+
+func TestSentences(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"Hello there. How are you? I'm fine!", []string{"Hello there.", "How are you?", "I'm fine!"}},
+		{`He said "Stop." Then he left.`, []string{`He said "Stop."`, "Then he left."}},
+		{"Mr. Smith paid $3.50 in the U.S.A. last week.", []string{"Mr. Smith paid $3.50 in the U.S.A. last week."}},
+		{"See e.g. the manual. It helps.", []string{"See e.g. the manual.", "It helps."}},
+		{"J. R. R. Tolkien wrote it. Really?! Yes...", []string{"J. R. R. Tolkien wrote it.", "Really?!", "Yes..."}},
+		{"The end. the lowercase continues.", []string{"The end. the lowercase continues."}},
+		{"第一句。第二句！", []string{"第一句。", "第二句！"}},
+		{"A heading\n\nNo terminator here\nbut a wrapped line.", []string{"A heading", "No terminator here\nbut a wrapped line."}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			require.Equal(t, tt.expected, Sentences(tt.text))
+		})
+	}
+}
+
+func TestParagraphs(t *testing.T) {
+	text := "First paragraph,\nstill first.\n \t\r\nSecond.\u2029Third.\n\n\n"
+	require.Equal(t, []string{"First paragraph,\nstill first.", "Second.", "Third."}, Paragraphs(text))
+}
+
+func TestSegment(t *testing.T) {
+	segments, err := Segment("One. Two.", Sentence)
+	require.NoError(t, err)
+	require.Equal(t, []string{"One.", "Two."}, segments)
+
+	_, err = Segment("One. Two.", "grapheme")
+	require.ErrorContains(t, err, `unknown text granularity "grapheme"`)
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package timeutils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron expressions have the standard five fields: minute, hour, day of month, month and day of week.
+// Each field is a list of values, ranges and steps, such as 1,15 or 9-17 or */5, and months and days of the week
+// may be given by their names.  As in most cron implementations, a day matches if either the day of month or
+// the day of week matches, when both are restricted.  The @yearly, @monthly, @weekly, @daily and @hourly
+// shorthands are also accepted.
+
+// the number of years ahead to look for a matching time, after which the expression is considered to never match
+const maxCronSearch = 5
+
+var cronShorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+var cronDayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domRestricted, dowRestricted  bool
+}
+
+func parseCron(expression string) (*cronSchedule, error) {
+	expr := strings.TrimSpace(expression)
+	if s, ok := cronShorthands[strings.ToLower(expr)]; ok {
+		expr = s
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q, must have 5 fields", expression)
+	}
+
+	s := &cronSchedule{}
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid minute in cron expression %q: %w", expression, err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid hour in cron expression %q: %w", expression, err)
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid day of month in cron expression %q: %w", expression, err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("invalid month in cron expression %q: %w", expression, err)
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return nil, fmt.Errorf("invalid day of week in cron expression %q: %w", expression, err)
+	}
+
+	// 7 is also Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domRestricted = fields[2] != "*" && fields[2] != "?"
+	s.dowRestricted = fields[4] != "*" && fields[4] != "?"
+	return s, nil
+}
+
+// parseCronField returns a bit set of the values that the field matches.
+func parseCronField(field string, min, max int, names []string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepText)
+			}
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*" || rng == "?":
+			lo, hi = min, max
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = parseCronValue(a, min, max, names); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(b, min, max, names); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			var err error
+			if lo, err = parseCronValue(rng, min, max, names); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if hasStep {
+				hi = max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(s string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(s, name) {
+			return i + min, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("value %d is out of range, must be between %d and %d", v, min, max)
+	}
+	return v, nil
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<t.Weekday()) != 0
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// next returns the first matching time after t, in the location of t.  Each field that doesn't match advances the time
+// to the start of the next value of that field, so that the search takes at most a few steps per matching day.
+// A time that doesn't exist on the day of a daylight saving time change is skipped.
+func (s *cronSchedule) next(t time.Time) (time.Time, error) {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxCronSearch, 0, 0)
+
+	// advance moves to the given wall clock time, but always moves forward, in case of a daylight saving time change
+	advance := func(next time.Time, minimum time.Duration) time.Time {
+		if !next.After(t) {
+			return t.Add(minimum)
+		}
+		return next
+	}
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<t.Month()) == 0:
+			t = advance(time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc), time.Minute)
+		case !s.dayMatches(t):
+			t = advance(time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc), time.Minute)
+		case s.hour&(1<<t.Hour()) == 0:
+			t = advance(time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc), time.Minute)
+		case s.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("the cron expression does not match any time in the next %d years", maxCronSearch)
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package timeutils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextCronTime(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		expression, after, expected string
+	}{
+		{"*/15 * * * *", "2024-07-04T12:07:30-04:00", "2024-07-04T12:15:00-04:00"},
+		{"0 9 * * MON-FRI", "2024-07-05T09:00:00-04:00", "2024-07-08T09:00:00-04:00"},
+		{"30 8,17 * * *", "2024-07-04T12:00:00-04:00", "2024-07-04T17:30:00-04:00"},
+		{"0 0 29 feb *", "2024-03-01T00:00:00-05:00", "2028-02-29T00:00:00-05:00"},
+		{"@monthly", "2024-12-15T00:00:00-05:00", "2025-01-01T00:00:00-05:00"},
+		{"0 12 * * 7", "2024-07-04T12:00:00-04:00", "2024-07-07T12:00:00-04:00"},
+		// when both the day of month and day of week are restricted, either may match
+		{"0 0 13 * fri", "2024-07-01T00:00:00-04:00", "2024-07-05T00:00:00-04:00"},
+		// 2:30 does not exist on the day that daylight saving time starts
+		{"30 2 * * *", "2024-03-09T12:00:00-05:00", "2024-03-11T02:30:00-04:00"},
+		// the time is evaluated in the location, regardless of the offset of the given timestamp
+		{"0 9 * * *", "2024-07-04T14:00:00Z", "2024-07-05T09:00:00-04:00"},
+	}
+
+	for _, tt := range tests {
+		result, err := NextCronTime(tt.expression, tt.after, ny)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, result, tt.expression)
+	}
+}
+
+func TestNextCronTimeErrors(t *testing.T) {
+	tests := []struct {
+		expression, err string
+	}{
+		{"* * * *", "must have 5 fields"},
+		{"60 * * * *", "invalid minute in cron expression \"60 * * * *\": value 60 is out of range"},
+		{"* * * foo *", "invalid month"},
+		{"*/0 * * * *", "invalid step"},
+		{"* 5-1 * * *", "invalid range"},
+		{"0 0 31 feb *", "does not match any time in the next 5 years"},
+	}
+
+	for _, tt := range tests {
+		_, err := NextCronTime(tt.expression, "2024-07-04T12:00:00Z", time.UTC)
+		require.ErrorContains(t, err, tt.err, tt.expression)
+	}
+}
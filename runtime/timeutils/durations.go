@@ -0,0 +1,100 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package timeutils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type duration struct {
+	years, months, days int
+	clock               time.Duration
+}
+
+var isoDurationRegex = regexp.MustCompile(`^(-)?P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+
+// parseDuration parses an ISO 8601 duration, such as P1Y2M3DT4H5M6.5S, or a Go duration, such as 1h30m.
+func parseDuration(s string) (duration, error) {
+	if !strings.Contains(s, "P") {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return duration{}, fmt.Errorf("invalid duration %q, must be an ISO 8601 duration such as P1DT2H, or a duration such as 1h30m", s)
+		}
+		return duration{clock: d}, nil
+	}
+
+	m := isoDurationRegex.FindStringSubmatch(s)
+	if m == nil || strings.HasSuffix(s, "P") || strings.HasSuffix(s, "T") {
+		return duration{}, fmt.Errorf("invalid ISO 8601 duration %q", s)
+	}
+
+	n := func(i int) int {
+		v, _ := strconv.Atoi(m[i])
+		return v
+	}
+	var seconds float64
+	if m[8] != "" {
+		seconds, _ = strconv.ParseFloat(strings.Replace(m[8], ",", ".", 1), 64)
+	}
+
+	d := duration{
+		years:  n(2),
+		months: n(3),
+		days:   n(4)*7 + n(5),
+		clock:  time.Duration(n(6))*time.Hour + time.Duration(n(7))*time.Minute + time.Duration(math.Round(seconds*1e9)),
+	}
+	if m[1] == "-" {
+		d = duration{-d.years, -d.months, -d.days, -d.clock}
+	}
+	return d, nil
+}
+
+var relativeUnits = []struct {
+	name string
+	size time.Duration
+	// durations up to this limit are described in this unit, rather than the next larger one
+	limit time.Duration
+}{
+	{"minute", time.Minute, 45 * time.Minute},
+	{"hour", time.Hour, 22 * time.Hour},
+	{"day", 24 * time.Hour, 26 * 24 * time.Hour},
+	{"month", 30 * 24 * time.Hour, 320 * 24 * time.Hour},
+	{"year", 365 * 24 * time.Hour, math.MaxInt64},
+}
+
+// formatRelative describes a duration from a reference time, such as "3 hours ago" or "in 2 days".
+func formatRelative(d time.Duration) string {
+	abs := d.Abs()
+	if abs < 45*time.Second {
+		return "just now"
+	}
+
+	var text string
+	for _, u := range relativeUnits {
+		if abs < u.limit {
+			count := max(int64(math.Round(float64(abs)/float64(u.size))), 1)
+			text = fmt.Sprintf("%d %s", count, u.name)
+			if count > 1 {
+				text += "s"
+			}
+			break
+		}
+	}
+
+	if d < 0 {
+		return text + " ago"
+	}
+	return "in " + text
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package timeutils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// strftimeLayouts maps the supported strftime directives to their equivalent Go layouts.
+var strftimeLayouts = map[string]string{
+	"Y":  "2006",
+	"y":  "06",
+	"m":  "01",
+	"d":  "02",
+	"e":  "_2",
+	"j":  "002",
+	"H":  "15",
+	"I":  "03",
+	"M":  "04",
+	"S":  "05",
+	"f":  "000000",
+	"p":  "PM",
+	"a":  "Mon",
+	"A":  "Monday",
+	"b":  "Jan",
+	"B":  "January",
+	"Z":  "MST",
+	"z":  "-0700",
+	":z": "-07:00",
+}
+
+// a time in which every Go layout element formats differently from the element itself,
+// used to detect literal text that Go would mistake for a layout element
+var layoutProbe = time.Date(1999, time.November, 28, 9, 58, 59, 123456789, time.FixedZone("XYZ", 3600))
+
+type strftimeSegment struct {
+	text      string
+	directive bool
+}
+
+func splitStrftime(format string) ([]strftimeSegment, error) {
+	var segments []strftimeSegment
+	var literal strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			literal.WriteByte(format[i])
+			continue
+		}
+		i++
+		if i == len(format) {
+			return nil, fmt.Errorf("format %q ends with an incomplete directive", format)
+		}
+
+		d := format[i : i+1]
+		if d == ":" && i+1 < len(format) {
+			i++
+			d = format[i-1 : i+1]
+		}
+		if d == "%" {
+			literal.WriteByte('%')
+			continue
+		}
+		if _, ok := strftimeLayouts[d]; !ok {
+			return nil, fmt.Errorf("unsupported directive %%%s in format %q", d, format)
+		}
+
+		if literal.Len() > 0 {
+			segments = append(segments, strftimeSegment{text: literal.String()})
+			literal.Reset()
+		}
+		segments = append(segments, strftimeSegment{text: d, directive: true})
+	}
+	if literal.Len() > 0 {
+		segments = append(segments, strftimeSegment{text: literal.String()})
+	}
+	return segments, nil
+}
+
+// parseLayout converts a strftime format to a Go layout, for parsing.
+func parseLayout(format string) (string, error) {
+	segments, err := splitStrftime(format)
+	if err != nil {
+		return "", err
+	}
+
+	var layout strings.Builder
+	for _, s := range segments {
+		if !s.directive {
+			if layoutProbe.Format(s.text) != s.text {
+				return "", fmt.Errorf("the text %q in format %q is not supported for parsing", s.text, format)
+			}
+			layout.WriteString(s.text)
+			continue
+		}
+		if s.text == "f" && !strings.HasSuffix(layout.String(), ".") && !strings.HasSuffix(layout.String(), ",") {
+			return "", fmt.Errorf("the %%f directive in format %q must follow a period or comma", format)
+		}
+		layout.WriteString(strftimeLayouts[s.text])
+	}
+	return layout.String(), nil
+}
+
+// formatStrftime formats a time with a strftime format.  Each part is formatted separately,
+// so that the literal text of the format is never interpreted as a Go layout element.
+func formatStrftime(t time.Time, format string) (string, error) {
+	segments, err := splitStrftime(format)
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	for _, s := range segments {
+		switch {
+		case !s.directive:
+			result.WriteString(s.text)
+		case s.text == "f":
+			fmt.Fprintf(&result, "%06d", t.Nanosecond()/1000)
+		default:
+			result.WriteString(t.Format(strftimeLayouts[s.text]))
+		}
+	}
+	return result.String(), nil
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package timeutils provides time zone aware parsing, formatting and arithmetic for plugins,
+// using the time zone database of the host.  Times are exchanged with plugins as RFC 3339 timestamps,
+// and formats are given with strftime directives, which are more familiar than Go layouts.
+package timeutils
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseTime parses a value with the given strftime format, and returns it as an RFC 3339 timestamp.
+// A value without a time zone offset is interpreted in the given location.  An empty format parses an RFC 3339 timestamp.
+func ParseTime(value, format string, loc *time.Location) (string, error) {
+	layout := time.RFC3339Nano
+	if format != "" {
+		var err error
+		if layout, err = parseLayout(format); err != nil {
+			return "", err
+		}
+	}
+
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %q with format %q: %w", value, format, err)
+	}
+	return t.Format(time.RFC3339Nano), nil
+}
+
+// FormatTime converts an RFC 3339 timestamp to the given location, and formats it with the given strftime format.
+// An empty format formats it as an RFC 3339 timestamp, so that the timestamp is only converted to the location.
+func FormatTime(timestamp, format string, loc *time.Location) (string, error) {
+	t, err := parseTimestamp(timestamp)
+	if err != nil {
+		return "", err
+	}
+	t = t.In(loc)
+
+	if format == "" {
+		return t.Format(time.RFC3339Nano), nil
+	}
+	return formatStrftime(t, format)
+}
+
+// AddDuration adds a duration to an RFC 3339 timestamp.  The duration is either an ISO 8601 duration, such as P1DT12H,
+// or a Go duration, such as 1h30m, and may be negative.  Years, months, weeks and days are added to the calendar date
+// in the given location, so that adding a day keeps the same wall clock time across a daylight saving time change.
+func AddDuration(timestamp, duration string, loc *time.Location) (string, error) {
+	t, err := parseTimestamp(timestamp)
+	if err != nil {
+		return "", err
+	}
+
+	d, err := parseDuration(duration)
+	if err != nil {
+		return "", err
+	}
+
+	t = t.In(loc).AddDate(d.years, d.months, d.days).Add(d.clock)
+	return t.Format(time.RFC3339Nano), nil
+}
+
+// FormatRelativeTime describes an RFC 3339 timestamp relative to a reference timestamp, such as "3 hours ago" or "in 2 days".
+func FormatRelativeTime(timestamp, reference string) (string, error) {
+	t, err := parseTimestamp(timestamp)
+	if err != nil {
+		return "", err
+	}
+	ref, err := parseTimestamp(reference)
+	if err != nil {
+		return "", err
+	}
+	return formatRelative(t.Sub(ref)), nil
+}
+
+// NextCronTime returns the first time after the given RFC 3339 timestamp that matches a cron expression,
+// evaluated in the given location.
+func NextCronTime(expression, after string, loc *time.Location) (string, error) {
+	t, err := parseTimestamp(after)
+	if err != nil {
+		return "", err
+	}
+
+	s, err := parseCron(expression)
+	if err != nil {
+		return "", err
+	}
+
+	next, err := s.next(t.In(loc))
+	if err != nil {
+		return "", err
+	}
+	return next.Format(time.RFC3339Nano), nil
+}
+
+func parseTimestamp(timestamp string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q, must be in RFC 3339 format", timestamp)
+	}
+	return t, nil
+}
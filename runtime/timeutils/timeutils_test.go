@@ -0,0 +1,121 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package timeutils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustLoadLocation(t *testing.T, tz string) *time.Location {
+	loc, err := time.LoadLocation(tz)
+	require.NoError(t, err)
+	return loc
+}
+
+func TestParseTime(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		value, format, expected string
+	}{
+		{"2024-07-04 09:30", "%Y-%m-%d %H:%M", "2024-07-04T09:30:00-04:00"},
+		{"2024-12-25 09:30", "%Y-%m-%d %H:%M", "2024-12-25T09:30:00-05:00"},
+		{"Thursday, July 4 2024 at 09:30PM", "%A, %B %e %Y at %I:%M%p", "2024-07-04T21:30:00-04:00"},
+		{"04/07/24 09:30:15.250000 +0200", "%d/%m/%y %H:%M:%S.%f %z", "2024-07-04T09:30:15.25+02:00"},
+		{"2024-07-04T13:30:00Z", "", "2024-07-04T13:30:00Z"},
+	}
+
+	for _, tt := range tests {
+		result, err := ParseTime(tt.value, tt.format, ny)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, result)
+	}
+
+	_, err := ParseTime("2024", "%Y %Q", ny)
+	require.ErrorContains(t, err, "unsupported directive %Q")
+	_, err = ParseTime("July 2024", "Jan %Y", ny)
+	require.ErrorContains(t, err, `the text "Jan " in format "Jan %Y" is not supported for parsing`)
+	_, err = ParseTime("09:30 250000", "%H:%M %f", ny)
+	require.ErrorContains(t, err, "must follow a period or comma")
+	_, err = ParseTime("yesterday", "%Y-%m-%d", ny)
+	require.ErrorContains(t, err, `failed to parse "yesterday"`)
+}
+
+func TestFormatTime(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+
+	result, err := FormatTime("2024-07-04T13:30:15.5Z", "%a %d %b %Y, %H:%M:%S.%f %Z (%:z) 100%% on day %j", tokyo)
+	require.NoError(t, err)
+	require.Equal(t, "Thu 04 Jul 2024, 22:30:15.500000 JST (+09:00) 100% on day 186", result)
+
+	// literal text is never mistaken for a layout element when formatting
+	result, err = FormatTime("2024-07-04T13:30:15Z", "Mon Jan 2 is not %d", time.UTC)
+	require.NoError(t, err)
+	require.Equal(t, "Mon Jan 2 is not 04", result)
+
+	result, err = FormatTime("2024-07-04T13:30:15Z", "", tokyo)
+	require.NoError(t, err)
+	require.Equal(t, "2024-07-04T22:30:15+09:00", result)
+
+	_, err = FormatTime("July 4", "%Y", tokyo)
+	require.ErrorContains(t, err, "must be in RFC 3339 format")
+}
+
+func TestAddDuration(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		timestamp, duration, expected string
+	}{
+		// a day keeps the wall clock time across the daylight saving time change, but 24 hours does not
+		{"2024-03-09T12:00:00-05:00", "P1D", "2024-03-10T12:00:00-04:00"},
+		{"2024-03-09T12:00:00-05:00", "PT24H", "2024-03-10T13:00:00-04:00"},
+		{"2024-03-09T12:00:00-05:00", "24h", "2024-03-10T13:00:00-04:00"},
+		{"2024-01-31T00:00:00-05:00", "P1Y1M1W", "2025-03-10T00:00:00-04:00"},
+		{"2024-07-04T12:00:00-04:00", "-P1DT1H30M0.5S", "2024-07-03T10:29:59.5-04:00"},
+		{"2024-07-04T12:00:00-04:00", "-90m", "2024-07-04T10:30:00-04:00"},
+	}
+
+	for _, tt := range tests {
+		result, err := AddDuration(tt.timestamp, tt.duration, ny)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, result, tt.duration)
+	}
+
+	for _, d := range []string{"P", "PT", "P1H", "1 day", ""} {
+		_, err := AddDuration("2024-07-04T12:00:00Z", d, ny)
+		require.ErrorContains(t, err, "invalid", d)
+	}
+}
+
+func TestFormatRelativeTime(t *testing.T) {
+	ref := "2024-07-04T12:00:00Z"
+
+	tests := []struct {
+		timestamp, expected string
+	}{
+		{"2024-07-04T12:00:30Z", "just now"},
+		{"2024-07-04T11:59:00Z", "1 minute ago"},
+		{"2024-07-04T12:10:00Z", "in 10 minutes"},
+		{"2024-07-04T09:00:00Z", "3 hours ago"},
+		{"2024-07-06T12:00:00Z", "in 2 days"},
+		{"2024-05-04T12:00:00Z", "2 months ago"},
+		{"2027-07-04T12:00:00Z", "in 3 years"},
+	}
+
+	for _, tt := range tests {
+		result, err := FormatRelativeTime(tt.timestamp, ref)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, result)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/secrets"
+)
+
+// ForConnection builds the TLS configuration for an outbound connection from its manifest settings.
+// It returns nil if the connection has no TLS settings, in which case the client's defaults apply.
+func ForConnection(ctx context.Context, connection manifest.ConnectionInfo, info *manifest.TLSInfo) (*tls.Config, error) {
+	if info == nil {
+		return nil, nil
+	}
+
+	cfg, err := build(info, func(s string) (string, error) {
+		return secrets.ApplySecretsToString(ctx, connection, s)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS settings for connection [%s]: %w", connection.ConnectionName(), err)
+	}
+	return cfg, nil
+}
+
+func build(info *manifest.TLSInfo, applySecrets func(string) (string, error)) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	switch info.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		cfg.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported minimum TLS version %q", info.MinVersion)
+	}
+
+	if info.CACert != "" {
+		pem, err := loadPEM(info.CACert, applySecrets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load CA certificate: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA certificate")
+		}
+		cfg.RootCAs = pool
+	}
+
+	if info.ClientCert != "" || info.ClientKey != "" {
+		if info.ClientCert == "" || info.ClientKey == "" {
+			return nil, fmt.Errorf("both a client certificate and a client key are required")
+		}
+
+		certPEM, err := loadPEM(info.ClientCert, applySecrets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		keyPEM, err := loadPEM(info.ClientKey, applySecrets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client key: %w", err)
+		}
+
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// loadPEM returns PEM-encoded contents, either given directly or read from a file relative to the app.
+func loadPEM(value string, applySecrets func(string) (string, error)) ([]byte, error) {
+	value, err := applySecrets(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(value, "-----BEGIN ") {
+		return []byte(value), nil
+	}
+
+	path := value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(config.AppPath, path)
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/config"
+)
+
+func noSecrets(s string) (string, error) {
+	return s, nil
+}
+
+func generateCert(t *testing.T) (certPEM, keyPEM string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+	return certPEM, keyPEM
+}
+
+func TestBuildDefaults(t *testing.T) {
+	cfg, err := build(&manifest.TLSInfo{}, noSecrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MinVersion != tls.VersionTLS12 {
+		t.Errorf("expected TLS 1.2 minimum, got %x", cfg.MinVersion)
+	}
+	if cfg.RootCAs != nil || len(cfg.Certificates) != 0 {
+		t.Error("expected system roots and no client certificate")
+	}
+}
+
+func TestBuildMinVersion(t *testing.T) {
+	cfg, err := build(&manifest.TLSInfo{MinVersion: "1.3"}, noSecrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MinVersion != tls.VersionTLS13 {
+		t.Errorf("expected TLS 1.3 minimum, got %x", cfg.MinVersion)
+	}
+
+	if _, err := build(&manifest.TLSInfo{MinVersion: "1.0"}, noSecrets); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+}
+
+func TestBuildInlinePEM(t *testing.T) {
+	certPEM, keyPEM := generateCert(t)
+
+	cfg, err := build(&manifest.TLSInfo{
+		CACert:     certPEM,
+		ClientCert: certPEM,
+		ClientKey:  "{{CLIENT_KEY}}",
+	}, func(s string) (string, error) {
+		return strings.ReplaceAll(s, "{{CLIENT_KEY}}", keyPEM), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RootCAs == nil {
+		t.Error("expected custom root CAs")
+	}
+	if len(cfg.Certificates) != 1 {
+		t.Errorf("expected one client certificate, got %d", len(cfg.Certificates))
+	}
+}
+
+func TestBuildFromFiles(t *testing.T) {
+	certPEM, keyPEM := generateCert(t)
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "certs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "certs", "client.pem"), []byte(certPEM), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "certs", "client.key"), []byte(keyPEM), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	appPath := config.AppPath
+	config.AppPath = dir
+	defer func() { config.AppPath = appPath }()
+
+	cfg, err := build(&manifest.TLSInfo{
+		CACert:     "certs/client.pem",
+		ClientCert: "certs/client.pem",
+		ClientKey:  filepath.Join(dir, "certs", "client.key"),
+	}, noSecrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RootCAs == nil || len(cfg.Certificates) != 1 {
+		t.Error("expected custom root CAs and a client certificate")
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	certPEM, _ := generateCert(t)
+
+	if _, err := build(&manifest.TLSInfo{ClientCert: certPEM}, noSecrets); err == nil {
+		t.Error("expected an error for a client certificate without a key")
+	}
+	if _, err := build(&manifest.TLSInfo{CACert: "-----BEGIN CERTIFICATE-----\ninvalid\n-----END CERTIFICATE-----"}, noSecrets); err == nil {
+		t.Error("expected an error for an invalid CA certificate")
+	}
+	if _, err := build(&manifest.TLSInfo{CACert: "missing.pem"}, noSecrets); err == nil {
+		t.Error("expected an error for a missing CA certificate file")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package tokenizers
+
+import "math"
+
+// bytePairCount returns the number of tokens that the piece is encoded as, by repeatedly merging
+// the adjacent pair of parts with the lowest rank, as tiktoken does.
+func bytePairCount(piece string, ranks map[string]int) int {
+	if len(piece) <= 1 {
+		return len(piece)
+	}
+	if _, ok := ranks[piece]; ok {
+		return 1
+	}
+
+	// the boundaries of the parts, and the rank of the pair of parts that begins at each boundary
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+	pairRanks := make([]int, len(piece))
+	pairRank := func(i int) int {
+		if i+2 >= len(bounds) {
+			return math.MaxInt
+		}
+		if rank, ok := ranks[piece[bounds[i]:bounds[i+2]]]; ok {
+			return rank
+		}
+		return math.MaxInt
+	}
+	for i := range pairRanks {
+		pairRanks[i] = pairRank(i)
+	}
+
+	for len(bounds) > 2 {
+		best := -1
+		minRank := math.MaxInt
+		for i, rank := range pairRanks[:len(bounds)-2] {
+			if rank < minRank {
+				best, minRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		// merge the pair, and update the ranks of the pairs on either side of it
+		bounds = append(bounds[:best+1], bounds[best+2:]...)
+		pairRanks = append(pairRanks[:best+1], pairRanks[best+2:]...)
+		pairRanks[best] = pairRank(best)
+		if best > 0 {
+			pairRanks[best-1] = pairRank(best - 1)
+		}
+	}
+
+	return len(bounds) - 1
+}
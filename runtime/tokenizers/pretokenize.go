@@ -0,0 +1,200 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package tokenizers
+
+import (
+	"unicode"
+)
+
+// Before byte pair encoding, text is split into pieces by a regular expression that is specific to each encoding.
+// The expressions use possessive quantifiers and lookahead, which Go's regexp package doesn't support,
+// so the splitters below match the same pieces by hand.  Each function returns the end of the piece that begins at i.
+
+// splitText splits the text into the pieces matched by the splitter.
+func splitText(text string, match func(r []rune, i int) int) []string {
+	runes := []rune(text)
+	pieces := make([]string, 0, len(runes)/4+1)
+	for i := 0; i < len(runes); {
+		end := match(runes, i)
+		if end <= i {
+			end = i + 1
+		}
+		pieces = append(pieces, string(runes[i:end]))
+		i = end
+	}
+	return pieces
+}
+
+// matchCl100k matches the pieces of the cl100k_base encoding:
+//
+//	'(?i:[sdmt]|ll|ve|re)|[^\r\n\p{L}\p{N}]?+\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]++[\r\n]*|\s*[\r\n]|\s+(?!\S)|\s+
+func matchCl100k(r []rune, i int) int {
+	n := len(r)
+
+	if r[i] == '\'' && i+1 < n {
+		switch unicode.ToLower(r[i+1]) {
+		case 's', 'ſ', 'd', 'm', 't':
+			return i + 2
+		}
+		if i+2 < n {
+			switch string([]rune{unicode.ToLower(r[i+1]), unicode.ToLower(r[i+2])}) {
+			case "ll", "ve", "re":
+				return i + 3
+			}
+		}
+	}
+
+	j := i
+	if isPrefix(r[j]) {
+		j++
+	}
+	if j < n && unicode.IsLetter(r[j]) {
+		return runEnd(r, j, unicode.IsLetter)
+	}
+
+	if unicode.IsNumber(r[i]) {
+		return numberEnd(r, i)
+	}
+
+	if end := punctuationEnd(r, i, isNewline); end > i {
+		return end
+	}
+
+	return whitespaceEnd(r, i)
+}
+
+// matchO200k matches the pieces of the o200k_base encoding:
+//
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func matchO200k(r []rune, i int) int {
+	n := len(r)
+
+	// the optional prefix is tried first, and then without it
+	starts := []int{i}
+	if isPrefix(r[i]) && i+1 < n {
+		starts = []int{i + 1, i}
+	}
+
+	for _, p := range starts {
+		// an uppercase run (which may be empty), followed by a lowercase run, giving back uppercase letters as needed
+		for k := runEnd(r, p, isUpperClass); k >= p; k-- {
+			if k < n && isLowerClass(r[k]) {
+				return contractionEnd(r, runEnd(r, k, isLowerClass))
+			}
+		}
+	}
+	for _, p := range starts {
+		// an uppercase run, followed by a lowercase run which may be empty
+		if k := runEnd(r, p, isUpperClass); k > p {
+			return contractionEnd(r, runEnd(r, k, isLowerClass))
+		}
+	}
+
+	if unicode.IsNumber(r[i]) {
+		return numberEnd(r, i)
+	}
+
+	if end := punctuationEnd(r, i, func(c rune) bool { return isNewline(c) || c == '/' }); end > i {
+		return end
+	}
+
+	return whitespaceEnd(r, i)
+}
+
+// isPrefix reports whether a character can precede a word in a piece.
+func isPrefix(c rune) bool {
+	return !isNewline(c) && !unicode.IsLetter(c) && !unicode.IsNumber(c)
+}
+
+func isNewline(c rune) bool {
+	return c == '\r' || c == '\n'
+}
+
+func isUpperClass(c rune) bool {
+	return unicode.In(c, unicode.Lu, unicode.Lt, unicode.Lm, unicode.Lo, unicode.M)
+}
+
+func isLowerClass(c rune) bool {
+	return unicode.In(c, unicode.Ll, unicode.Lm, unicode.Lo, unicode.M)
+}
+
+func isPunctuation(c rune) bool {
+	return !unicode.IsSpace(c) && !unicode.IsLetter(c) && !unicode.IsNumber(c)
+}
+
+// runEnd returns the end of the run of characters of the class that begins at i.
+func runEnd(r []rune, i int, class func(rune) bool) int {
+	for i < len(r) && class(r[i]) {
+		i++
+	}
+	return i
+}
+
+// numberEnd matches \p{N}{1,3}.
+func numberEnd(r []rune, i int) int {
+	end := i
+	for end < len(r) && end-i < 3 && unicode.IsNumber(r[end]) {
+		end++
+	}
+	return end
+}
+
+// punctuationEnd matches " ?[^\s\p{L}\p{N}]+" followed by any characters of the trailing class.
+func punctuationEnd(r []rune, i int, trailing func(rune) bool) int {
+	j := i
+	if r[j] == ' ' {
+		j++
+	}
+	if j >= len(r) || !isPunctuation(r[j]) {
+		return i
+	}
+	return runEnd(r, runEnd(r, j, isPunctuation), trailing)
+}
+
+// whitespaceEnd matches "\s*[\r\n]+|\s+(?!\S)|\s+".
+func whitespaceEnd(r []rune, i int) int {
+	end := runEnd(r, i, unicode.IsSpace)
+	if end == i {
+		return i + 1
+	}
+
+	// whitespace up to and including the last line break
+	for k := end - 1; k >= i; k-- {
+		if isNewline(r[k]) {
+			return k + 1
+		}
+	}
+
+	// otherwise whitespace that isn't followed by anything else, leaving the last space to begin the next word
+	if end < len(r) && end-i > 1 {
+		return end - 1
+	}
+	return end
+}
+
+// contractionEnd matches an optional (?i:'s|'t|'re|'ve|'m|'ll|'d) at i.
+func contractionEnd(r []rune, i int) int {
+	if i+1 >= len(r) || r[i] != '\'' {
+		return i
+	}
+	switch unicode.ToLower(r[i+1]) {
+	case 's', 'ſ', 't', 'm', 'd':
+		return i + 2
+	}
+	if i+2 < len(r) {
+		switch string([]rune{unicode.ToLower(r[i+1]), unicode.ToLower(r[i+2])}) {
+		case "re", "ve", "ll":
+			return i + 3
+		}
+	}
+	return i
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package tokenizers counts the tokens that models split text into, using the byte pair encodings
+// published for use with tiktoken.  The rank file of each encoding is read from the tokenizers directory,
+// and is downloaded there when it is first needed.
+package tokenizers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// Names of the supported encodings.
+const (
+	Cl100kBase = "cl100k_base"
+	O200kBase  = "o200k_base"
+)
+
+const rankFileBaseUrl = "https://openaipublic.blob.core.windows.net/encodings/"
+
+var splitters = map[string]func(r []rune, i int) int{
+	Cl100kBase: matchCl100k,
+	O200kBase:  matchO200k,
+}
+
+// Encoding is a byte pair encoding that text can be tokenized with.
+type Encoding struct {
+	Name  string
+	ranks map[string]int
+	match func(r []rune, i int) int
+}
+
+// CountTokens returns the number of tokens that the text is encoded as.
+// Special tokens are not recognized, so text such as <|endoftext|> is counted as ordinary text.
+func (e *Encoding) CountTokens(text string) int {
+	count := 0
+	for _, piece := range splitText(text, e.match) {
+		count += bytePairCount(piece, e.ranks)
+	}
+	return count
+}
+
+var encodings = make(map[string]*Encoding)
+var encodingsMu sync.Mutex
+
+// GetEncoding returns the encoding with the given name, loading it if needed.
+func GetEncoding(ctx context.Context, name string) (*Encoding, error) {
+	match, ok := splitters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tokenizer %s, must be one of %s or %s", name, Cl100kBase, O200kBase)
+	}
+
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+
+	if e, ok := encodings[name]; ok {
+		return e, nil
+	}
+
+	content, err := readRankFile(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	ranks, err := parseRanks(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the rank file of tokenizer %s: %w", name, err)
+	}
+
+	e := &Encoding{Name: name, ranks: ranks, match: match}
+	encodings[name] = e
+	return e, nil
+}
+
+// EncodingForModel returns the name of the encoding used by a model, based on the name that its provider uses for it.
+func EncodingForModel(sourceModel string) (string, bool) {
+	// the name may be qualified, as in openai/gpt-4o
+	name := strings.ToLower(sourceModel[strings.LastIndex(sourceModel, "/")+1:])
+
+	for _, prefix := range []string{"gpt-4o", "chatgpt-4o", "gpt-4.1", "gpt-4.5", "gpt-5", "o1", "o3", "o4"} {
+		if strings.HasPrefix(name, prefix) {
+			return O200kBase, true
+		}
+	}
+	for _, prefix := range []string{"gpt-4", "gpt-3.5", "gpt-35", "text-embedding-3", "text-embedding-ada-002"} {
+		if strings.HasPrefix(name, prefix) {
+			return Cl100kBase, true
+		}
+	}
+	return "", false
+}
+
+// tokenizersDir returns the directory that rank files are read from and downloaded to.
+func tokenizersDir() string {
+	if config.TokenizersDir != "" {
+		return config.TokenizersDir
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	return filepath.Join(cacheDir, "modus", "tokenizers")
+}
+
+func readRankFile(ctx context.Context, name string) ([]byte, error) {
+	dir := tokenizersDir()
+	path := filepath.Join(dir, name+".tiktoken")
+	content, err := os.ReadFile(path)
+	if err == nil {
+		return content, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read the rank file of tokenizer %s: %w", name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rankFileBaseUrl+name+".tiktoken", nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := utils.HttpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the rank file of tokenizer %s: %w", name, err)
+	}
+	defer response.Body.Close()
+	content, err = io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the rank file of tokenizer %s: %w", name, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download the rank file of tokenizer %s: %w", name, utils.NewHttpError(response, content))
+	}
+
+	// the file is written to a temporary path and renamed, so that a partial file is never read
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the tokenizers directory: %w", err)
+	}
+	partial := path + ".partial"
+	if err := os.WriteFile(partial, content, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save the rank file of tokenizer %s: %w", name, err)
+	}
+	if err := os.Rename(partial, path); err != nil {
+		return nil, fmt.Errorf("failed to save the rank file of tokenizer %s: %w", name, err)
+	}
+	return content, nil
+}
+
+// parseRanks parses a rank file, in which each line holds a base64-encoded token and its rank.
+func parseRanks(content []byte) (map[string]int, error) {
+	ranks := make(map[string]int, bytes.Count(content, []byte{'\n'})+1)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		token, rank, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("line %d is not a token and a rank", line)
+		}
+		b, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("line %d has an invalid token: %w", line, err)
+		}
+		r, err := strconv.Atoi(rank)
+		if err != nil {
+			return nil, fmt.Errorf("line %d has an invalid rank: %w", line, err)
+		}
+		ranks[string(b)] = r
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return nil, errors.New("the file is empty")
+	}
+	return ranks, nil
+}
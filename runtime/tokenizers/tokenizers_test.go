@@ -0,0 +1,135 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package tokenizers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCl100k(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"Hello world's 1234567  end\n\n!!!?", []string{"Hello", " world", "'s", " ", "123", "456", "7", " ", " end", "\n\n", "!!!?"}},
+		{"don't CamelCase", []string{"don", "'t", " CamelCase"}},
+		{"$price: 5 \n", []string{"$price", ":", " ", "5", " \n"}},
+		{"trailing  ", []string{"trailing", "  "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			require.Equal(t, tt.expected, splitText(tt.text, matchCl100k))
+		})
+	}
+}
+
+func TestSplitO200k(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"don't CamelCase HELLOworld", []string{"don't", " Camel", "Case", " HELLOworld"}},
+		{"foo/bar\n\n1234", []string{"foo", "/bar", "\n\n", "123", "4"}},
+		{"ABC's a/b\n", []string{"ABC's", " a", "/b", "\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			require.Equal(t, tt.expected, splitText(tt.text, matchO200k))
+		})
+	}
+}
+
+func testRanks() map[string]int {
+	ranks := make(map[string]int)
+	for i := range 256 {
+		ranks[string([]byte{byte(i)})] = i
+	}
+	for i, token := range []string{"he", "ll", "hell", " w", "or", " wor", "ld", " world"} {
+		ranks[token] = 256 + i
+	}
+	return ranks
+}
+
+func TestBytePairCount(t *testing.T) {
+	ranks := testRanks()
+	require.Equal(t, 0, bytePairCount("", ranks))
+	require.Equal(t, 1, bytePairCount("x", ranks))
+	require.Equal(t, 1, bytePairCount(" world", ranks))
+	require.Equal(t, 2, bytePairCount("hello", ranks))
+	require.Equal(t, 5, bytePairCount("xyzzy", ranks))
+
+	e := &Encoding{Name: Cl100kBase, ranks: ranks, match: matchCl100k}
+	require.Equal(t, 3, e.CountTokens("hello world"))
+	require.Equal(t, 3, e.CountTokens("é!"))
+}
+
+func TestGetEncodingReadsRankFile(t *testing.T) {
+	dir := t.TempDir()
+	config.TokenizersDir = dir
+	t.Cleanup(func() {
+		config.TokenizersDir = ""
+		clear(encodings)
+	})
+
+	var b strings.Builder
+	for token, rank := range testRanks() {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), rank)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "o200k_base.tiktoken"), []byte(b.String()), 0644))
+
+	e, err := GetEncoding(context.Background(), O200kBase)
+	require.NoError(t, err)
+	require.Equal(t, 3, e.CountTokens("hello world"))
+
+	_, err = GetEncoding(context.Background(), "p50k_base")
+	require.ErrorContains(t, err, "unknown tokenizer p50k_base")
+}
+
+func TestParseRanksErrors(t *testing.T) {
+	_, err := parseRanks([]byte("aGk= 1\naGk=\n"))
+	require.ErrorContains(t, err, "line 2 is not a token and a rank")
+
+	_, err = parseRanks([]byte("aGk= one\n"))
+	require.ErrorContains(t, err, "line 1 has an invalid rank")
+
+	_, err = parseRanks([]byte("\n"))
+	require.ErrorContains(t, err, "empty")
+}
+
+func TestEncodingForModel(t *testing.T) {
+	tests := map[string]string{
+		"gpt-4o-mini":            O200kBase,
+		"openai/gpt-4.1":         O200kBase,
+		"o3-mini":                O200kBase,
+		"gpt-4-turbo":            Cl100kBase,
+		"gpt-3.5-turbo":          Cl100kBase,
+		"text-embedding-3-small": Cl100kBase,
+	}
+	for model, expected := range tests {
+		name, ok := EncodingForModel(model)
+		require.True(t, ok, model)
+		require.Equal(t, expected, name, model)
+	}
+
+	_, ok := EncodingForModel("claude-3-5-sonnet")
+	require.False(t, ok)
+}
@@ -9,29 +9,101 @@
 
 package utils
 
-import "bytes"
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
 
 type OutputBuffers interface {
-	StdOut() *bytes.Buffer
-	StdErr() *bytes.Buffer
+	StdOut() *OutputBuffer
+	StdErr() *OutputBuffer
 }
 
-func NewOutputBuffers() OutputBuffers {
+// NewOutputBuffers returns buffers that each capture up to maxBytes of output.  Use 0 for no limit.
+func NewOutputBuffers(maxBytes int) OutputBuffers {
 	return &outputBuffers{
-		stdOut: &bytes.Buffer{},
-		stdErr: &bytes.Buffer{},
+		stdOut: &OutputBuffer{maxBytes: maxBytes},
+		stdErr: &OutputBuffer{maxBytes: maxBytes},
 	}
 }
 
 type outputBuffers struct {
-	stdOut *bytes.Buffer
-	stdErr *bytes.Buffer
+	stdOut *OutputBuffer
+	stdErr *OutputBuffer
 }
 
-func (b *outputBuffers) StdOut() *bytes.Buffer {
+func (b *outputBuffers) StdOut() *OutputBuffer {
 	return b.stdOut
 }
 
-func (b *outputBuffers) StdErr() *bytes.Buffer {
+func (b *outputBuffers) StdErr() *OutputBuffer {
 	return b.stdErr
 }
+
+// OutputBuffer captures output up to a maximum size.  Output beyond the maximum is discarded,
+// but counted, so that a truncation marker can be included when the output is read.
+type OutputBuffer struct {
+	buf       bytes.Buffer
+	maxBytes  int
+	discarded int64
+}
+
+func (b *OutputBuffer) Write(p []byte) (int, error) {
+	b.accept(p)
+	return len(p), nil
+}
+
+func (b *OutputBuffer) WriteString(s string) (int, error) {
+	return b.Write([]byte(s))
+}
+
+// accept writes as much of p to the buffer as the limit allows, and returns the part that was written.
+func (b *OutputBuffer) accept(p []byte) []byte {
+	if b.maxBytes > 0 {
+		room := max(b.maxBytes-b.buf.Len(), 0)
+		if len(p) > room {
+			b.discarded += int64(len(p) - room)
+			p = p[:room]
+		}
+	}
+	b.buf.Write(p)
+	return p
+}
+
+// Tee returns a writer that captures output in the buffer, and also writes the captured output to w.
+// Output discarded by the buffer is not written to w.
+func (b *OutputBuffer) Tee(w io.Writer) io.Writer {
+	return &teeWriter{b, w}
+}
+
+// Discarded returns the number of bytes that were discarded because the limit was exceeded.
+func (b *OutputBuffer) Discarded() int64 {
+	return b.discarded
+}
+
+// String returns the captured output, followed by a truncation marker if any output was discarded.
+func (b *OutputBuffer) String() string {
+	if b.discarded == 0 {
+		return b.buf.String()
+	}
+	s := b.buf.String()
+	if len(s) > 0 && s[len(s)-1] != '\n' {
+		s += "\n"
+	}
+	return s + fmt.Sprintf("Warning: Output truncated after %d bytes.  %d additional bytes were discarded.\n", b.buf.Len(), b.discarded)
+}
+
+type teeWriter struct {
+	buf *OutputBuffer
+	w   io.Writer
+}
+
+func (t *teeWriter) Write(p []byte) (int, error) {
+	if accepted := t.buf.accept(p); len(accepted) > 0 {
+		if _, err := t.w.Write(accepted); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOutputBufferUnlimited(t *testing.T) {
+	b := NewOutputBuffers(0).StdOut()
+	_, _ = b.WriteString("hello\n")
+	_, _ = b.WriteString("world\n")
+
+	if b.String() != "hello\nworld\n" {
+		t.Errorf("unexpected output: %q", b.String())
+	}
+	if b.Discarded() != 0 {
+		t.Errorf("expected no discarded bytes, got %d", b.Discarded())
+	}
+}
+
+func TestOutputBufferTruncated(t *testing.T) {
+	b := NewOutputBuffers(8).StdOut()
+	n, err := b.WriteString("hello\nworld\n")
+	if err != nil || n != 12 {
+		t.Fatalf("expected the full write to be reported, got %d, %v", n, err)
+	}
+	_, _ = b.WriteString("more")
+
+	if b.Discarded() != 8 {
+		t.Errorf("expected 8 discarded bytes, got %d", b.Discarded())
+	}
+
+	expected := "hello\nwo\nWarning: Output truncated after 8 bytes.  8 additional bytes were discarded.\n"
+	if b.String() != expected {
+		t.Errorf("unexpected output: %q", b.String())
+	}
+
+	messages := TransformConsoleOutput(&outputBuffers{stdOut: b, stdErr: &OutputBuffer{}})
+	last := messages[len(messages)-1]
+	if last.Level != "warning" {
+		t.Errorf("expected the truncation marker to be a warning, got %q", last.Level)
+	}
+}
+
+func TestOutputBufferTee(t *testing.T) {
+	var log bytes.Buffer
+	b := NewOutputBuffers(5).StdErr()
+	w := b.Tee(&log)
+
+	n, err := w.Write([]byte("abcdefgh"))
+	if err != nil || n != 8 {
+		t.Fatalf("expected the full write to be reported, got %d, %v", n, err)
+	}
+	_, _ = w.Write([]byte("ij"))
+
+	if log.String() != "abcde" {
+		t.Errorf("expected only captured output to be teed, got %q", log.String())
+	}
+	if b.Discarded() != 5 {
+		t.Errorf("expected 5 discarded bytes, got %d", b.Discarded())
+	}
+}
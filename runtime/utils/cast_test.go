@@ -0,0 +1,59 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+func Test_Cast_Int64_Lossless(t *testing.T) {
+	tests := []any{
+		json.Number("9007199254740993"),
+		"9007199254740993",
+		json.Number("9007199254740993.0"),
+	}
+	for _, input := range tests {
+		v, err := utils.Cast[int64](input)
+		if err != nil {
+			t.Fatalf("unexpected error for %v: %v", input, err)
+		}
+		if v != 9007199254740993 {
+			t.Errorf("expected 9007199254740993, got %d", v)
+		}
+	}
+
+	v, err := utils.Cast[int64](json.Number("-9223372036854775808"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != math.MinInt64 {
+		t.Errorf("expected %d, got %d", int64(math.MinInt64), v)
+	}
+}
+
+func Test_Cast_Uint64_Lossless(t *testing.T) {
+	for _, input := range []any{json.Number("18446744073709551615"), "18446744073709551615"} {
+		v, err := utils.Cast[uint64](input)
+		if err != nil {
+			t.Fatalf("unexpected error for %v: %v", input, err)
+		}
+		if v != math.MaxUint64 {
+			t.Errorf("expected %d, got %d", uint64(math.MaxUint64), v)
+		}
+	}
+
+	if _, err := utils.Cast[uint64](json.Number("18446744073709551616")); err == nil {
+		t.Error("expected an overflow error")
+	}
+}
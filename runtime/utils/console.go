@@ -10,7 +10,6 @@
 package utils
 
 import (
-	"bytes"
 	"strings"
 )
 
@@ -27,7 +26,7 @@ func TransformConsoleOutput(buffers OutputBuffers) []LogMessage {
 	return append(transformConsoleOutputLines(buffers.StdOut()), transformConsoleOutputLines(buffers.StdErr())...)
 }
 
-func transformConsoleOutputLines(buf *bytes.Buffer) []LogMessage {
+func transformConsoleOutputLines(buf *OutputBuffer) []LogMessage {
 	lines := strings.Split(buf.String(), "\n")
 	messages := make([]LogMessage, 0, len(lines))
 	for _, line := range lines {
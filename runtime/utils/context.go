@@ -21,3 +21,17 @@ const FunctionOutputContextKey contextKey = "function_output"
 const FunctionMessagesContextKey contextKey = "function_messages"
 const CustomTypesContextKey contextKey = "custom_types"
 const TimeZoneContextKey contextKey = "time_zone"
+const CallerMetadataContextKey contextKey = "caller_metadata"
+const TraceContextContextKey contextKey = "trace_context"
+const CookieJarsContextKey contextKey = "cookie_jars"
+const CollectionTriggerContextKey contextKey = "collection_trigger"
+const CollectionWritesContextKey contextKey = "collection_writes"
+const ShadowExecutionContextKey contextKey = "shadow_execution"
+const RateLimitObserverContextKey contextKey = "rate_limit_observer"
+const HttpClientContextKey contextKey = "http_client"
+const SysOverridesContextKey contextKey = "sys_overrides"
+const OutboxContextKey contextKey = "outbox"
+const InstanceSlotContextKey contextKey = "instance_slot"
+const RequestContextContextKey contextKey = "request_context"
+const PriorityContextKey contextKey = "priority"
+const ResponseCursorContextKey contextKey = "response_cursor"
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -24,8 +25,17 @@ func HttpClient() *http.Client {
 	return httpClient
 }
 
+// HttpClientFromContext returns the HTTP client assigned to the context for a specific connection,
+// or the shared client if there is none.
+func HttpClientFromContext(ctx context.Context) *http.Client {
+	if c, ok := ctx.Value(HttpClientContextKey).(*http.Client); ok {
+		return c
+	}
+	return httpClient
+}
+
 func sendHttp(req *http.Request) ([]byte, error) {
-	response, err := httpClient.Do(req)
+	response, err := HttpClientFromContext(req.Context()).Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -37,16 +47,58 @@ func sendHttp(req *http.Request) ([]byte, error) {
 	}
 
 	if response.StatusCode != http.StatusOK {
-		if len(body) == 0 {
-			return nil, fmt.Errorf("HTTP error: %s", response.Status)
-		} else {
-			return nil, fmt.Errorf("HTTP error: %s\n%s", response.Status, body)
-		}
+		return nil, NewHttpError(response, body)
 	}
 
 	return body, nil
 }
 
+// NewHttpError returns an error for a response that completed with a non-success status code.
+func NewHttpError(response *http.Response, body []byte) *HttpError {
+	return &HttpError{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Body:       body,
+		RetryAfter: parseRetryAfter(response.Header.Get("Retry-After")),
+	}
+}
+
+// HttpError is returned when an HTTP request completes with a non-success status code.
+type HttpError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+
+	// RetryAfter is the delay requested by the server's Retry-After header, or zero if there was none.
+	RetryAfter time.Duration
+}
+
+func (e *HttpError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("HTTP error: %s", e.Status)
+	}
+	return fmt.Sprintf("HTTP error: %s\n%s", e.Status, e.Body)
+}
+
+// IsRateLimited reports whether the server rejected the request because too many requests were made.
+func (e *HttpError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// parseRetryAfter parses a Retry-After header, which may contain either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
 type HttpResult[T any] struct {
 	Data      T
 	StartTime time.Time
@@ -82,6 +134,8 @@ func PostHttp[TResult any](ctx context.Context, url string, payload any, beforeS
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
+	InjectTraceContext(ctx, req)
+
 	if beforeSend != nil {
 		err = beforeSend(ctx, req)
 		if err != nil {
@@ -12,12 +12,14 @@ package utils
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func Test_SendHttp(t *testing.T) {
@@ -67,6 +69,55 @@ func Test_SendHttp_ErrorResponse(t *testing.T) {
 	}
 }
 
+func Test_SendHttp_RateLimited(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		http.Error(w, "Slow down!", http.StatusTooManyRequests)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	_, err = sendHttp(req)
+	var httpErr *HttpError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Expected an HttpError, but got %v", err)
+	}
+
+	if !httpErr.IsRateLimited() {
+		t.Errorf("Expected the error to be rate limited, got status %d", httpErr.StatusCode)
+	}
+	if httpErr.RetryAfter != 3*time.Second {
+		t.Errorf("Unexpected retry after. Got: %v, want: %v", httpErr.RetryAfter, 3*time.Second)
+	}
+}
+
+func Test_ParseRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":                              0,
+		"0":                             0,
+		"120":                           2 * time.Minute,
+		"-5":                            0,
+		"invalid":                       0,
+		"Wed, 21 Oct 2015 07:28:00 GMT": 0,
+	}
+
+	for value, expected := range tests {
+		if actual := parseRetryAfter(value); actual != expected {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", value, actual, expected)
+		}
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if actual := parseRetryAfter(future); actual <= 59*time.Minute || actual > time.Hour {
+		t.Errorf("parseRetryAfter(%q) = %v, want about 1h", future, actual)
+	}
+}
+
 func Test_PostHttp(t *testing.T) {
 	type Payload struct {
 		Message string `json:"message"`
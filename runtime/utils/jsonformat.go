@@ -0,0 +1,157 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// JsonFormatOptions control how FormatJson rewrites a JSON document.
+type JsonFormatOptions struct {
+	// OmitNulls removes object members whose value is null.
+	// Members of objects nested fewer than OmitNullsMinDepth levels deep are always kept.
+	OmitNulls         bool
+	OmitNullsMinDepth int
+
+	// Indent, if not empty, pretty-prints the output using the given indentation.
+	Indent string
+}
+
+// FormatJson rewrites a JSON document according to the options.
+// Object members are always written in the order they appear in the input.
+func FormatJson(data []byte, opts JsonFormatOptions) ([]byte, error) {
+	if !opts.OmitNulls && opts.Indent == "" {
+		return data, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	buf := &bytes.Buffer{}
+	buf.Grow(len(data))
+	if err := formatJsonValue(dec, buf, opts, 0); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after end of JSON value")
+	}
+
+	if opts.Indent == "" {
+		return buf.Bytes(), nil
+	}
+
+	out := &bytes.Buffer{}
+	out.Grow(buf.Len() * 2)
+	if err := json.Indent(out, buf.Bytes(), "", opts.Indent); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func formatJsonValue(dec *json.Decoder, buf *bytes.Buffer, opts JsonFormatOptions, depth int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			return formatJsonObject(dec, buf, opts, depth)
+		case '[':
+			return formatJsonArray(dec, buf, opts, depth)
+		default:
+			return errors.New("unexpected JSON delimiter")
+		}
+	default:
+		return writeJsonToken(buf, tok)
+	}
+}
+
+func formatJsonObject(dec *json.Decoder, buf *bytes.Buffer, opts JsonFormatOptions, depth int) error {
+	buf.WriteByte('{')
+	first := true
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := keyTok.(string)
+		if !ok {
+			return errors.New("expected JSON object key")
+		}
+
+		// write the member to a scratch buffer, so it can be dropped if the value is null
+		member := &bytes.Buffer{}
+		if err := formatJsonValue(dec, member, opts, depth+1); err != nil {
+			return err
+		}
+		if opts.OmitNulls && depth >= opts.OmitNullsMinDepth && bytes.Equal(member.Bytes(), []byte("null")) {
+			continue
+		}
+
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		if err := writeJsonToken(buf, key); err != nil {
+			return err
+		}
+		buf.WriteByte(':')
+		buf.Write(member.Bytes())
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func formatJsonArray(dec *json.Decoder, buf *bytes.Buffer, opts JsonFormatOptions, depth int) error {
+	buf.WriteByte('[')
+	first := true
+	for dec.More() {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		if err := formatJsonValue(dec, buf, opts, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+func writeJsonToken(buf *bytes.Buffer, tok json.Token) error {
+	if tok == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	if n, ok := tok.(json.Number); ok {
+		buf.WriteString(n.String())
+		return nil
+	}
+	b, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
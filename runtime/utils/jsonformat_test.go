@@ -0,0 +1,51 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import (
+	"testing"
+)
+
+func Test_FormatJson_OmitNulls(t *testing.T) {
+	input := `{"data":{"b":null,"a":{"z":1,"y":null,"x":[null,{"k":null}]}},"errors":null}`
+	expected := `{"data":{"a":{"z":1,"x":[null,{}]}},"errors":null}`
+
+	actual, err := FormatJson([]byte(input), JsonFormatOptions{OmitNulls: true, OmitNullsMinDepth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func Test_FormatJson_Pretty(t *testing.T) {
+	input := `{"b":1.50,"a":"x<y"}`
+	expected := "{\n  \"b\": 1.50,\n  \"a\": \"x\\u003cy\"\n}"
+
+	actual, err := FormatJson([]byte(input), JsonFormatOptions{Indent: "  "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func Test_FormatJson_NoOptions(t *testing.T) {
+	input := `{"a":null}`
+	actual, err := FormatJson([]byte(input), JsonFormatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != input {
+		t.Errorf("expected %s, got %s", input, actual)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import "sync"
+
+// Buffers larger than this are not returned to the pool, so that an occasional
+// very large payload doesn't keep a large amount of memory alive indefinitely.
+const maxPooledBufferSize = 1 << 20
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 512)
+		return &b
+	},
+}
+
+// GetBuffer returns a byte buffer of the given length from a shared pool.
+// The contents of the buffer are zeroed.  The buffer must not be retained
+// after it is passed back to PutBuffer.
+func GetBuffer(size int) *[]byte {
+	buf := bufferPool.Get().(*[]byte)
+	if cap(*buf) < size {
+		*buf = make([]byte, size)
+	} else {
+		*buf = (*buf)[:size]
+		clear(*buf)
+	}
+	return buf
+}
+
+// PutBuffer returns a buffer obtained from GetBuffer to the shared pool.
+func PutBuffer(buf *[]byte) {
+	if buf == nil || cap(*buf) > maxPooledBufferSize {
+		return
+	}
+	*buf = (*buf)[:0]
+	bufferPool.Put(buf)
+}
+
+// Slices longer than this are not returned to the pool, for the same reason.
+const maxPooledSliceLen = 1 << 16
+
+var slicePool = sync.Pool{
+	New: func() any {
+		s := make([]any, 0, 64)
+		return &s
+	},
+}
+
+// GetSlice returns a slice of the given length from a shared pool.
+// The slice must not be retained after it is passed back to PutSlice.
+func GetSlice(size int) *[]any {
+	s := slicePool.Get().(*[]any)
+	if cap(*s) < size {
+		*s = make([]any, size)
+	} else {
+		*s = (*s)[:size]
+	}
+	return s
+}
+
+// PutSlice returns a slice obtained from GetSlice to the shared pool.
+// The items are cleared, so that the pool doesn't keep them alive.
+func PutSlice(s *[]any) {
+	if s == nil || cap(*s) > maxPooledSliceLen {
+		return
+	}
+	clear(*s)
+	*s = (*s)[:0]
+	slicePool.Put(s)
+}
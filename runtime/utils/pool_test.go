@@ -0,0 +1,63 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvertToPooledSlice(t *testing.T) {
+	items, release, err := ConvertToPooledSlice([]string{"a", "b", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(items, []any{"a", "b", "c"}) {
+		t.Errorf("unexpected items: %v", items)
+	}
+	release()
+
+	items, release, err = ConvertToPooledSlice([2]int32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(items, []any{int32(1), int32(2)}) {
+		t.Errorf("unexpected items: %v", items)
+	}
+	release()
+
+	// a []any is used as is, and is not cleared by the release function
+	input := []any{"x", 1}
+	items, release, err = ConvertToPooledSlice(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if &items[0] != &input[0] || input[0] != "x" {
+		t.Errorf("expected the input slice to be returned unchanged")
+	}
+
+	if _, _, err := ConvertToPooledSlice("not a slice"); err == nil {
+		t.Error("expected an error for a non-slice input")
+	}
+}
+
+func TestPutSliceClearsItems(t *testing.T) {
+	s := GetSlice(3)
+	(*s)[0], (*s)[1], (*s)[2] = "a", "b", "c"
+	backing := (*s)[:3]
+	PutSlice(s)
+
+	for i, v := range backing {
+		if v != nil {
+			t.Errorf("expected item %d to be cleared, got %v", i, v)
+		}
+	}
+}
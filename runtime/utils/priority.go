@@ -0,0 +1,61 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import (
+	"context"
+	"strings"
+)
+
+// PriorityHeader is the request header that sets the priority class of a request.
+const PriorityHeader = "X-Modus-Priority"
+
+// Priority is the class of a function call, which decides the order in which waiting calls are given
+// module instances when they are scarce.  Interactive traffic is normal priority by default, and
+// background work such as collection triggers and bulk imports is batch priority.
+type Priority int
+
+const (
+	PriorityBatch Priority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+// ParsePriority parses the name of a priority class.  It returns false if the name is not valid.
+func ParsePriority(s string) (Priority, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "batch", "low", "background":
+		return PriorityBatch, true
+	case "normal", "default":
+		return PriorityNormal, true
+	case "high", "interactive":
+		return PriorityHigh, true
+	}
+	return PriorityNormal, false
+}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityBatch:
+		return "batch"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+// GetPriority returns the priority class of the call in the context, which is normal if not set.
+func GetPriority(ctx context.Context) Priority {
+	if p, ok := ctx.Value(PriorityContextKey).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+func Test_ParsePriority(t *testing.T) {
+	tests := map[string]utils.Priority{
+		"high":   utils.PriorityHigh,
+		" High ": utils.PriorityHigh,
+		"normal": utils.PriorityNormal,
+		"batch":  utils.PriorityBatch,
+		"low":    utils.PriorityBatch,
+	}
+	for s, expected := range tests {
+		p, ok := utils.ParsePriority(s)
+		if !ok || p != expected {
+			t.Errorf("%q: expected %s, got %s (valid: %v)", s, expected, p, ok)
+		}
+	}
+
+	if _, ok := utils.ParsePriority("urgent"); ok {
+		t.Error("expected an unknown priority class to be invalid")
+	}
+}
+
+func Test_GetPriority(t *testing.T) {
+	if p := utils.GetPriority(context.Background()); p != utils.PriorityNormal {
+		t.Errorf("expected normal priority by default, got %s", p)
+	}
+
+	ctx := context.WithValue(context.Background(), utils.PriorityContextKey, utils.PriorityBatch)
+	if p := utils.GetPriority(ctx); p != utils.PriorityBatch {
+		t.Errorf("expected batch priority, got %s", p)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// RedactedValue replaces the values of sensitive fields in redacted JSON.
+const RedactedValue = "[REDACTED]"
+
+// RedactJson returns a copy of a JSON document in which the values of any object members
+// whose names match one of the given field names (case-insensitively) are replaced with RedactedValue.
+// Matching members are redacted at any depth, including within arrays.
+func RedactJson(data []byte, fields []string) ([]byte, error) {
+	if len(fields) == 0 || len(data) == 0 {
+		return data, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		names[strings.ToLower(f)] = true
+	}
+
+	return json.Marshal(redactValue(v, names))
+}
+
+func redactValue(v any, names map[string]bool) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if names[strings.ToLower(k)] {
+				t[k] = RedactedValue
+			} else {
+				t[k] = redactValue(val, names)
+			}
+		}
+	case []any:
+		for i, val := range t {
+			t[i] = redactValue(val, names)
+		}
+	}
+	return v
+}
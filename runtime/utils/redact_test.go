@@ -0,0 +1,38 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import (
+	"testing"
+)
+
+func Test_RedactJson(t *testing.T) {
+	input := `{"user":{"name":"alice","Password":"hunter2","keys":[{"apiKey":"abc","id":1}]},"token":{"nested":true}}`
+	expected := `{"token":"[REDACTED]","user":{"Password":"[REDACTED]","keys":[{"apiKey":"[REDACTED]","id":1}],"name":"alice"}}`
+
+	actual, err := RedactJson([]byte(input), []string{"password", "apikey", "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func Test_RedactJson_NoFields(t *testing.T) {
+	input := `{"password":"hunter2"}`
+	actual, err := RedactJson([]byte(input), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != input {
+		t.Errorf("expected %s, got %s", input, actual)
+	}
+}
@@ -17,46 +17,71 @@ import (
 )
 
 func ConvertToSlice(input any) ([]any, error) {
+	if s, ok := input.([]any); ok {
+		return s, nil
+	}
+	return convertToSlice(input, func(n int) []any { return make([]any, n) })
+}
+
+// ConvertToPooledSlice is like ConvertToSlice, but when the input must be converted, the items are stored
+// in a slice from the shared pool, preallocated to the length of the input.  The release function returns
+// the slice to the pool, after which the items must not be used.
+func ConvertToPooledSlice(input any) (items []any, release func(), err error) {
+	if s, ok := input.([]any); ok {
+		return s, func() {}, nil
+	}
+
+	var buf *[]any
+	items, err = convertToSlice(input, func(n int) []any {
+		buf = GetSlice(n)
+		return *buf
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, func() { PutSlice(buf) }, nil
+}
+
+// convertToSlice copies the items of the input into a slice of the input's length, obtained from alloc.
+func convertToSlice(input any, alloc func(n int) []any) ([]any, error) {
 	// Handle slices of common types directly
 	switch input := input.(type) {
-	case []any:
-		return input, nil
 	case []string:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []uint:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []uint8:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []uint16:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []uint32:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []uint64:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []int:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []int8:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []int16:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []int32:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []int64:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []float32:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []float64:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []bool:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []uintptr:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []unsafe.Pointer:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []time.Time:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	case []JSONTime:
-		return convertSlice(input)
+		return convertSlice(input, alloc), nil
 	}
 
 	// We need to use reflection for the general case.
@@ -67,20 +92,20 @@ func ConvertToSlice(input any) ([]any, error) {
 		return nil, fmt.Errorf("input is not a slice")
 	}
 
-	out := make([]any, 0, rv.Len())
-	for i := 0; i < rv.Len(); i++ {
-		out = append(out, rv.Index(i).Interface())
+	out := alloc(rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
 	}
 
 	return out, nil
 }
 
-func convertSlice[T any](input []T) ([]any, error) {
-	out := make([]any, len(input))
+func convertSlice[T any](input []T, alloc func(n int) []any) []any {
+	out := alloc(len(input))
 	for i, v := range input {
 		out[i] = v
 	}
-	return out, nil
+	return out
 }
 
 func ConvertToSliceOf[T any](obj any) ([]T, bool) {
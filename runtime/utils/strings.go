@@ -10,6 +10,7 @@
 package utils
 
 import (
+	"encoding/binary"
 	"unicode/utf16"
 	"unsafe"
 )
@@ -44,3 +45,35 @@ func EncodeUTF16(str string) []byte {
 	bytes := unsafe.Slice((*byte)(ptr), len(words)*2)
 	return bytes
 }
+
+// UTF16Len returns the number of bytes needed to encode the string as UTF-16.
+func UTF16Len(str string) int {
+	n := 0
+	for _, r := range str {
+		if r >= 0x10000 {
+			n += 4
+		} else {
+			n += 2
+		}
+	}
+	return n
+}
+
+// EncodeUTF16To encodes the string as little-endian UTF-16 into the given buffer,
+// which must be at least UTF16Len(str) bytes long.  It returns the portion of the
+// buffer that was written.
+func EncodeUTF16To(buf []byte, str string) []byte {
+	i := 0
+	for _, r := range str {
+		if r >= 0x10000 {
+			r1, r2 := utf16.EncodeRune(r)
+			binary.LittleEndian.PutUint16(buf[i:], uint16(r1))
+			binary.LittleEndian.PutUint16(buf[i+2:], uint16(r2))
+			i += 4
+		} else {
+			binary.LittleEndian.PutUint16(buf[i:], uint16(r))
+			i += 2
+		}
+	}
+	return buf[:i]
+}
@@ -43,3 +43,15 @@ func Test_DecodeUTF16(t *testing.T) {
 		t.Errorf("expected %s, got %s", testString, str)
 	}
 }
+
+func Test_EncodeUTF16To(t *testing.T) {
+
+	for _, s := range []string{testString, "abc", "😀 emoji", ""} {
+		buf := make([]byte, utils.UTF16Len(s))
+		arr := utils.EncodeUTF16To(buf, s)
+
+		if expected := utils.EncodeUTF16(s); !bytes.Equal(arr, expected) {
+			t.Errorf("expected %x, got %x", expected, arr)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+)
+
+// W3C Trace Context headers.  See https://www.w3.org/TR/trace-context/
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+var traceParentRegex = regexp.MustCompile(`^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+type TraceContext struct {
+	Version  string
+	TraceId  string
+	ParentId string
+	Flags    string
+	State    string
+}
+
+// ParseTraceParent parses the value of a traceparent header.
+// It returns false if the value is not a valid traceparent.
+func ParseTraceParent(traceParent string) (*TraceContext, bool) {
+	m := traceParentRegex.FindStringSubmatch(traceParent)
+	if m == nil || m[1] == "ff" || m[2] == "00000000000000000000000000000000" || m[3] == "0000000000000000" {
+		return nil, false
+	}
+
+	return &TraceContext{
+		Version:  m[1],
+		TraceId:  m[2],
+		ParentId: m[3],
+		Flags:    m[4],
+	}, true
+}
+
+// TraceParent returns the traceparent header value for the trace context.
+func (tc *TraceContext) TraceParent() string {
+	return tc.Version + "-" + tc.TraceId + "-" + tc.ParentId + "-" + tc.Flags
+}
+
+// ContextWithTraceContext extracts the trace context headers from an incoming request, if present,
+// and adds them to the context.
+func ContextWithTraceContext(ctx context.Context, r *http.Request) context.Context {
+	tc, ok := ParseTraceParent(r.Header.Get(TraceParentHeader))
+	if !ok {
+		return ctx
+	}
+
+	tc.State = r.Header.Get(TraceStateHeader)
+	return context.WithValue(ctx, TraceContextContextKey, tc)
+}
+
+// GetTraceContext returns the trace context of the incoming request, if there is one.
+func GetTraceContext(ctx context.Context) (*TraceContext, bool) {
+	tc, ok := ctx.Value(TraceContextContextKey).(*TraceContext)
+	return tc, ok
+}
+
+// InjectTraceContext adds trace context headers to an outbound request, so that the downstream
+// service participates in the same trace as the incoming request.  A new parent id is generated
+// for each outbound request.  Headers that have already been set on the request are left as-is.
+func InjectTraceContext(ctx context.Context, req *http.Request) {
+	tc, ok := GetTraceContext(ctx)
+	if !ok || req.Header.Get(TraceParentHeader) != "" {
+		return
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return
+	}
+
+	child := *tc
+	child.ParentId = hex.EncodeToString(b)
+	req.Header.Set(TraceParentHeader, child.TraceParent())
+	if tc.State != "" {
+		req.Header.Set(TraceStateHeader, tc.State)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func Test_ParseTraceParent(t *testing.T) {
+	tc, ok := ParseTraceParent(testTraceParent)
+	if !ok {
+		t.Fatal("Expected traceparent to be valid")
+	}
+	if tc.TraceId != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Unexpected trace id: %s", tc.TraceId)
+	}
+	if tc.TraceParent() != testTraceParent {
+		t.Errorf("Unexpected traceparent: %s", tc.TraceParent())
+	}
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	for _, s := range invalid {
+		if _, ok := ParseTraceParent(s); ok {
+			t.Errorf("Expected traceparent %q to be invalid", s)
+		}
+	}
+}
+
+func Test_InjectTraceContext(t *testing.T) {
+	incoming, _ := http.NewRequest(http.MethodPost, "http://localhost/graphql", nil)
+	incoming.Header.Set(TraceParentHeader, testTraceParent)
+	incoming.Header.Set(TraceStateHeader, "vendor=value")
+	ctx := ContextWithTraceContext(context.Background(), incoming)
+
+	outgoing, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	InjectTraceContext(ctx, outgoing)
+
+	tc, ok := ParseTraceParent(outgoing.Header.Get(TraceParentHeader))
+	if !ok {
+		t.Fatalf("Expected a valid traceparent, got: %s", outgoing.Header.Get(TraceParentHeader))
+	}
+	if tc.TraceId != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected trace id to be propagated, got: %s", tc.TraceId)
+	}
+	if strings.HasSuffix(tc.ParentId, "00f067aa0ba902b7") {
+		t.Errorf("Expected a new parent id, got: %s", tc.ParentId)
+	}
+	if outgoing.Header.Get(TraceStateHeader) != "vendor=value" {
+		t.Errorf("Expected tracestate to be propagated, got: %s", outgoing.Header.Get(TraceStateHeader))
+	}
+
+	// no trace context on the incoming request
+	outgoing, _ = http.NewRequest(http.MethodGet, "http://example.com", nil)
+	InjectTraceContext(context.Background(), outgoing)
+	if outgoing.Header.Get(TraceParentHeader) != "" {
+		t.Error("Expected no traceparent header")
+	}
+}
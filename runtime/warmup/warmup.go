@@ -0,0 +1,89 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package warmup
+
+import (
+	"context"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/app"
+	"github.com/hypermodeinc/modus/runtime/functions"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+const defaultTimeout = 30 * time.Second
+
+func Initialize() {
+	functions.RegisterFunctionsLoadedCallback(run)
+}
+
+// run invokes the warm-up functions declared in the manifest, after plugins are (re)loaded.
+// Warm-up failures are logged, but never prevent the runtime from becoming ready.
+func run(ctx context.Context) {
+	man := manifestdata.GetManifest()
+	if man.Warmup == nil || len(man.Warmup.Functions) == 0 {
+		return
+	}
+
+	timeout := defaultTimeout
+	if man.Warmup.Timeout > 0 {
+		timeout = time.Duration(man.Warmup.Timeout) * time.Second
+	}
+
+	app.SetWarmingUp(true)
+	defer app.SetWarmingUp(false)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	logger.Info(ctx).
+		Int("functions", len(man.Warmup.Functions)).
+		Dur("timeout", timeout).
+		Msg("Warming up.")
+
+	start := time.Now()
+	host := wasmhost.GetWasmHost(ctx)
+	for _, fn := range man.Warmup.Functions {
+		if ctx.Err() != nil {
+			logger.Warn(ctx).
+				Str("function", fn.Function).
+				Bool("user_visible", true).
+				Msg("Warm-up timed out before all functions were invoked.")
+			break
+		}
+
+		info, err := host.GetFunctionInfo(fn.Function)
+		if err != nil {
+			logger.Warn(ctx).Err(err).
+				Str("function", fn.Function).
+				Bool("user_visible", true).
+				Msg("Warm-up function not found.")
+			continue
+		}
+
+		parameters := fn.Parameters
+		if parameters == nil {
+			parameters = map[string]any{}
+		}
+
+		if _, err := host.CallFunction(ctx, info, parameters); err != nil {
+			logger.Warn(ctx).Err(err).
+				Str("function", fn.Function).
+				Bool("user_visible", true).
+				Msg("Warm-up function failed.")
+		}
+	}
+
+	logger.Info(ctx).
+		Dur("duration_ms", time.Since(start)).
+		Msg("Warm-up complete.")
+}
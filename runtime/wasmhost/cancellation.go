@@ -0,0 +1,66 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import (
+	"context"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// withCancellationGracePeriod returns the context that a function is invoked with.  When the request context
+// is cancelled or its deadline passes, the function is given a grace period in which it can notice the cancellation
+// with the isCancelled host function and return cleanly.  After that, the invocation context is cancelled as well,
+// which closes the module and terminates the function.  The request context is kept in the invocation context,
+// so that host functions can report on its state.
+func withCancellationGracePeriod(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	if grace <= 0 {
+		return context.WithCancel(context.WithValue(ctx, utils.RequestContextContextKey, ctx))
+	}
+
+	callCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	callCtx = context.WithValue(callCtx, utils.RequestContextContextKey, ctx)
+
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(grace, func() {
+			cancel(context.Cause(ctx))
+		})
+	})
+
+	return callCtx, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
+
+// requestContext returns the context of the request that a function is executing for.
+func requestContext(ctx context.Context) context.Context {
+	if reqCtx, ok := ctx.Value(utils.RequestContextContextKey).(context.Context); ok {
+		return reqCtx
+	}
+	return ctx
+}
+
+// IsCancelled reports whether the request that a function is executing for has been cancelled, or has timed out.
+// A function that notices the cancellation should return promptly, before it is terminated.
+func IsCancelled(ctx context.Context) bool {
+	return requestContext(ctx).Err() != nil
+}
+
+// GetDeadlineRemaining returns the time remaining before the request that a function is executing for times out.
+// The second return value is false if the request has no deadline.
+func GetDeadlineRemaining(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := requestContext(ctx).Deadline()
+	if !ok {
+		return 0, false
+	}
+	return max(time.Until(deadline), 0), true
+}
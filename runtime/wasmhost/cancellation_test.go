@@ -0,0 +1,70 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_CancellationGracePeriod(t *testing.T) {
+	reqCtx, cancelReq := context.WithCancel(context.Background())
+	callCtx, endCall := withCancellationGracePeriod(reqCtx, 50*time.Millisecond)
+	defer endCall()
+
+	if IsCancelled(callCtx) {
+		t.Fatal("expected the request not to be cancelled")
+	}
+
+	cancelReq()
+	if !IsCancelled(callCtx) {
+		t.Fatal("expected the request to be cancelled")
+	}
+	if callCtx.Err() != nil {
+		t.Fatal("expected the invocation to continue during the grace period")
+	}
+
+	select {
+	case <-callCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the invocation to be cancelled after the grace period")
+	}
+}
+
+func Test_CancellationGracePeriod_Disabled(t *testing.T) {
+	reqCtx, cancelReq := context.WithCancel(context.Background())
+	callCtx, endCall := withCancellationGracePeriod(reqCtx, 0)
+	defer endCall()
+
+	cancelReq()
+	if callCtx.Err() == nil {
+		t.Fatal("expected the invocation to be cancelled with the request")
+	}
+}
+
+func Test_DeadlineRemaining(t *testing.T) {
+	if _, ok := GetDeadlineRemaining(context.Background()); ok {
+		t.Fatal("expected no deadline")
+	}
+
+	reqCtx, cancelReq := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelReq()
+	callCtx, endCall := withCancellationGracePeriod(reqCtx, time.Second)
+	defer endCall()
+
+	if _, ok := callCtx.Deadline(); ok {
+		t.Fatal("expected the invocation context to have no deadline of its own")
+	}
+	remaining, ok := GetDeadlineRemaining(callCtx)
+	if !ok || remaining <= 0 || remaining > time.Minute {
+		t.Fatalf("expected about a minute remaining, got %v", remaining)
+	}
+}
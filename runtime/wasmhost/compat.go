@@ -0,0 +1,79 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hypermodeinc/modus/lib/metadata"
+
+	"github.com/tetratelabs/wazero"
+	wasm "github.com/tetratelabs/wazero/api"
+)
+
+// HostApiVersion is the version of the host function API exported by this runtime.
+// It must be incremented whenever a host function is added or its signature changes,
+// and must match the version the SDKs embed in plugin metadata.
+const HostApiVersion = 1
+
+// CheckCompatibility verifies that the plugin's imported host functions are all exported by this
+// runtime with matching signatures, so that an incompatible plugin is rejected when it is loaded,
+// rather than failing when one of its functions is called.
+func (host *wasmHost) CheckCompatibility(cm wazero.CompiledModule, md *metadata.Metadata) error {
+	if md.HostApiVersion > HostApiVersion {
+		return fmt.Errorf("plugin %s requires host API version %d, but this runtime supports version %d.  Please upgrade the Modus runtime, or build the plugin with an older Modus SDK", md.Name(), md.HostApiVersion, HostApiVersion)
+	}
+
+	hostFns := make(map[string]*hostFunction, len(host.hostFunctions))
+	for _, hf := range host.hostFunctions {
+		hostFns[hf.Name()] = hf
+	}
+
+	var problems []string
+	for _, fnDef := range cm.ImportedFunctions() {
+		modName, fnName, ok := fnDef.Import()
+		if !ok || !strings.HasPrefix(modName, "modus_") {
+			continue
+		}
+
+		importName := modName + "." + fnName
+		hf, ok := hostFns[importName]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is not exported by this runtime", importName))
+			continue
+		}
+
+		if !slices.Equal(fnDef.ParamTypes(), hf.wasmParamTypes) || !slices.Equal(fnDef.ResultTypes(), hf.wasmResultTypes) {
+			problems = append(problems, fmt.Sprintf("%s has signature %s, but this runtime exports %s", importName,
+				formatSignature(fnDef.ParamTypes(), fnDef.ResultTypes()),
+				formatSignature(hf.wasmParamTypes, hf.wasmResultTypes)))
+		}
+	}
+
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return fmt.Errorf("plugin %s is not compatible with this runtime: %s.  Please use matching versions of the Modus SDK and runtime", md.Name(), strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func formatSignature(params, results []wasm.ValueType) string {
+	formatTypes := func(types []wasm.ValueType) string {
+		names := make([]string, len(types))
+		for i, t := range types {
+			names[i] = wasm.ValueTypeName(t)
+		}
+		return strings.Join(names, ", ")
+	}
+	return "(" + formatTypes(params) + ") -> (" + formatTypes(results) + ")"
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/logger"
+
+	"github.com/tetratelabs/wazero"
+	wasm "github.com/tetratelabs/wazero/api"
+)
+
+// getFSConfig returns the filesystem configuration for an instance of the plugin, and a function
+// that removes the instance's scratch directory, if it has one, after the instance is closed.
+func getFSConfig(ctx context.Context, pluginName string, info manifest.FilesystemInfo) (wazero.FSConfig, func(), error) {
+	mountPath := info.GetMountPath()
+
+	if info.Directory == "" {
+		dir, err := os.MkdirTemp("", "modus-scratch-")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create scratch directory for plugin %s: %w", pluginName, err)
+		}
+		cleanup := func() {
+			if err := os.RemoveAll(dir); err != nil {
+				logger.Warn(ctx).Err(err).Str("path", dir).Msg("Failed to remove scratch directory.")
+			}
+		}
+		return wazero.NewFSConfig().WithDirMount(dir, mountPath), cleanup, nil
+	}
+
+	dir, err := getPluginDataDir(pluginName, info.Directory)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, nil, fmt.Errorf("failed to create directory for plugin %s: %w", pluginName, err)
+	}
+
+	if info.ReadOnly {
+		return wazero.NewFSConfig().WithReadOnlyDirMount(dir, mountPath), nil, nil
+	}
+	return wazero.NewFSConfig().WithDirMount(dir, mountPath), nil, nil
+}
+
+// getPluginDataDir returns the host path of a plugin's persistent directory,
+// which must be within the plugin's own data directory.
+func getPluginDataDir(pluginName, directory string) (string, error) {
+	if directory == "" || filepath.IsAbs(directory) || slices.Contains(strings.Split(filepath.ToSlash(directory), "/"), "..") {
+		return "", fmt.Errorf("invalid directory %q for plugin %s", directory, pluginName)
+	}
+
+	base := config.PluginDataDir
+	if base == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			cacheDir = os.TempDir()
+		}
+		base = filepath.Join(cacheDir, "modus", "plugin-data")
+	}
+
+	pluginDir := filepath.Join(base, filepath.Base(pluginName))
+	dir := filepath.Join(pluginDir, directory)
+	if dir != pluginDir && !strings.HasPrefix(dir, pluginDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid directory %q for plugin %s", directory, pluginName)
+	}
+	return dir, nil
+}
+
+// cleanupModule is a module instance that runs a cleanup function after it is closed.
+type cleanupModule struct {
+	wasm.Module
+	cleanup func()
+}
+
+func (m *cleanupModule) Close(ctx context.Context) error {
+	defer m.cleanup()
+	return m.Module.Close(ctx)
+}
+
+func (m *cleanupModule) CloseWithExitCode(ctx context.Context, exitCode uint32) error {
+	defer m.cleanup()
+	return m.Module.CloseWithExitCode(ctx, exitCode)
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+)
+
+func Test_GetPluginDataDir(t *testing.T) {
+	base := t.TempDir()
+	orig := config.PluginDataDir
+	config.PluginDataDir = base
+	defer func() { config.PluginDataDir = orig }()
+
+	dir, err := getPluginDataDir("my-plugin", "cache/templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(base, "my-plugin", "cache", "templates"); dir != expected {
+		t.Errorf("expected %s, got %s", expected, dir)
+	}
+
+	for _, directory := range []string{"/etc", "..", "../other-plugin", "cache/../../other-plugin", "cache/.."} {
+		if _, err := getPluginDataDir("my-plugin", directory); err == nil {
+			t.Errorf("expected an error for directory %q", directory)
+		}
+	}
+}
@@ -16,9 +16,17 @@ import (
 	"os"
 	"time"
 
+	"github.com/hypermodeinc/modus/runtime/concurrency"
+	"github.com/hypermodeinc/modus/runtime/config"
 	"github.com/hypermodeinc/modus/runtime/functions"
+	"github.com/hypermodeinc/modus/runtime/httpclient"
+	"github.com/hypermodeinc/modus/runtime/langsupport"
 	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/metering"
 	"github.com/hypermodeinc/modus/runtime/metrics"
+	"github.com/hypermodeinc/modus/runtime/outbox"
+	"github.com/hypermodeinc/modus/runtime/progress"
 	"github.com/hypermodeinc/modus/runtime/utils"
 
 	"github.com/rs/xid"
@@ -30,6 +38,9 @@ type ExecutionInfo interface {
 	Buffers() utils.OutputBuffers
 	Messages() []utils.LogMessage
 	Result() any
+	Truncation() *ResponseTruncation
+	Trap() *WasmTrap
+	Memory() *MemoryUsage
 }
 
 type executionInfo struct {
@@ -37,6 +48,9 @@ type executionInfo struct {
 	buffers     utils.OutputBuffers
 	messages    []utils.LogMessage
 	result      any
+	truncation  *ResponseTruncation
+	trap        *WasmTrap
+	memory      *MemoryUsage
 }
 
 func (e *executionInfo) ExecutionId() string {
@@ -55,6 +69,18 @@ func (e *executionInfo) Result() any {
 	return e.result
 }
 
+func (e *executionInfo) Truncation() *ResponseTruncation {
+	return e.truncation
+}
+
+func (e *executionInfo) Trap() *WasmTrap {
+	return e.trap
+}
+
+func (e *executionInfo) Memory() *MemoryUsage {
+	return e.memory
+}
+
 func CallFunction(ctx context.Context, fnName string, paramValues ...any) (ExecutionInfo, error) {
 	return GetWasmHost(ctx).CallFunctionByName(ctx, fnName, paramValues...)
 }
@@ -78,27 +104,66 @@ func (host *wasmHost) CallFunction(ctx context.Context, fnInfo functions.Functio
 	span, ctx := utils.NewSentrySpanForCurrentFunc(ctx)
 	defer span.Finish()
 
+	// A function that the manifest isolates in a separate process is never executed in this one, whoever calls it.
+	if IsIsolated(fnInfo.Name()) {
+		return host.callIsolatedFunction(ctx, fnInfo, parameters)
+	}
+
+	fnName := fnInfo.Name()
+	plugin := fnInfo.Plugin()
+
+	maxOutput := manifestdata.GetManifest().ConsoleOutput.MaxBytesFor(plugin.Name(), config.MaxConsoleOutput)
 	execInfo := &executionInfo{
 		executionId: xid.New().String(),
-		buffers:     utils.NewOutputBuffers(),
+		buffers:     utils.NewOutputBuffers(maxOutput),
 		messages:    []utils.LogMessage{},
 	}
 
-	fnName := fnInfo.Name()
-	plugin := fnInfo.Plugin()
+	// a lazily loaded plugin is compiled when one of its functions is first called
+	if err := plugin.EnsureCompiled(ctx); err != nil {
+		logger.Err(ctx, err).Str("function", fnName).Msg("Error compiling plugin.")
+		return nil, err
+	}
 	plan := fnInfo.ExecutionPlan()
 
+	limit, err := getResponseLimit(ctx, fnName)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx = context.WithValue(ctx, utils.ExecutionIdContextKey, execInfo.executionId)
 	ctx = context.WithValue(ctx, utils.FunctionMessagesContextKey, &execInfo.messages)
 	ctx = context.WithValue(ctx, utils.FunctionNameContextKey, fnName)
 	ctx = context.WithValue(ctx, utils.PluginContextKey, plugin)
 	ctx = context.WithValue(ctx, utils.MetadataContextKey, plugin.Metadata)
 	ctx = context.WithValue(ctx, utils.WasmHostContextKey, host)
+	ctx = httpclient.ContextWithCookieJars(ctx)
+
+	// end any progress streams for the execution when the function completes
+	defer progress.Finish(ctx, execInfo.executionId)
 
 	// Each request will get its own instance of the plugin module, so that we can run
 	// multiple requests in parallel without risk of corrupting the module's memory.
 	// This also protects against security risk, as each request will have its own
 	// isolated memory space.  (One request cannot access another request's memory.)
+	// The number of instances that can exist at once is limited by the instance slots.
+
+	// Calls that the manifest serializes by a key wait for earlier calls with the same key to complete.
+	// A sandboxed worker process doesn't wait, because the main process already did.
+	if !config.IsSandboxWorker {
+		releaseKey, err := concurrency.Acquire(ctx, fnName, parameters)
+		if err != nil {
+			return nil, err
+		}
+		defer releaseKey()
+	}
+
+	ctx, releaseSlot, err := getInstanceSlots().acquireForCall(ctx, plugin.Name())
+	if err != nil {
+		logger.Warn(ctx).Err(err).Str("function", fnName).Msg("Canceled while waiting for a module instance slot.")
+		return nil, err
+	}
+	defer releaseSlot()
 
 	mod, err := host.GetModuleInstance(ctx, plugin, execInfo.buffers)
 	if err != nil {
@@ -115,16 +180,30 @@ func (host *wasmHost) CallFunction(ctx context.Context, fnInfo functions.Functio
 		Bool("user_visible", true).
 		Msg("Calling function.")
 
+	ctx, endOutbox := outbox.Begin(ctx)
+	initialMemory := memorySize(mod)
 	start := time.Now()
-	result, err := plan.InvokeFunction(ctx, wa, parameters)
+	// Functions should poll the isCancelled host function in long-running loops, and return when the request is
+	// cancelled.  A function that doesn't return within the grace period is terminated by closing its module.
+	callCtx, endCall := withCancellationGracePeriod(ctx, config.CancellationGracePeriod)
+	callCtx, getTruncation := langsupport.ContextWithResultLimit(callCtx, limit)
+	result, err := plan.InvokeFunction(callCtx, wa, parameters)
+	endCall()
 	duration := time.Since(start)
+	execInfo.memory = newMemoryUsage(initialMemory, memorySize(mod))
+	endOutbox(err == nil)
 
 	exitErr := &sys.ExitError{}
+	if errors.As(err, &exitErr) && (exitErr.ExitCode() == sys.ExitCodeContextCanceled || exitErr.ExitCode() == sys.ExitCodeDeadlineExceeded) {
+		err = fmt.Errorf("function %s was terminated after the request was cancelled: %w", fnName, context.Canceled)
+	}
 
 	if err == nil {
 		logger.Info(ctx).
 			Str("function", fnName).
 			Dur("duration_ms", duration).
+			Uint64("memory_peak_bytes", execInfo.memory.PeakBytes).
+			Uint64("memory_growth_bytes", execInfo.memory.GrowthBytes).
 			Bool("user_visible", true).
 			Msg("Function completed successfully.")
 	} else if errors.As(err, &exitErr) {
@@ -148,6 +227,23 @@ func (host *wasmHost) CallFunction(ctx context.Context, fnInfo functions.Functio
 				Int32("exit_code", exitCode).
 				Msgf("Function ended prematurely with exit code %d.  This may have been intentional, or caused by an exception or panic in your code.", exitCode)
 		}
+	} else if langsupport.IsResponseTooLarge(err) {
+		logger.Error(ctx).
+			Str("function", fnName).
+			Dur("duration_ms", duration).
+			Bool("user_visible", true).
+			Int("max_bytes", limit.MaxBytes).
+			Msg("Function response exceeded the size limit.")
+	} else if trap := parseTrap(err, plugin.Language.Name()); trap != nil {
+		// A trap is caused by the user's code, such as an out of bounds memory access, so its stack trace is user-visible.
+		execInfo.trap = trap
+		logger.Error(ctx).
+			Str("function", fnName).
+			Dur("duration_ms", duration).
+			Bool("user_visible", true).
+			Str("trap", trap.Reason).
+			Str("stack", trap.String()).
+			Msgf("Function trapped: %s.", trap.Reason)
 	} else if errors.Is(err, context.Canceled) {
 		// Cancellation is not an error, but we still want to log it.
 		// This can occur if the function takes too long to execute, or if the user cancels the request.
@@ -176,12 +272,49 @@ func (host *wasmHost) CallFunction(ctx context.Context, fnInfo functions.Functio
 			Msg("An internal runtime error occurred while executing the function.")
 	}
 
-	// Update metrics
-	metrics.FunctionExecutionsNum.Inc()
-	d := float64(duration.Milliseconds())
-	metrics.FunctionExecutionDurationMilliseconds.WithLabelValues(fnName).Observe(d)
-	metrics.FunctionExecutionDurationMillisecondsSummary.WithLabelValues(fnName).Observe(d)
+	logDiscardedOutput(ctx, fnName, execInfo.buffers)
+
+	if t := getTruncation(); t != nil && err == nil {
+		execInfo.truncation = &ResponseTruncation{*t, encodeResponseCursor(fnName, t.Next())}
+		logger.Warn(ctx).
+			Str("function", fnName).
+			Int("returned", t.Returned).
+			Int("total", t.Total).
+			Bool("user_visible", true).
+			Msg("Function response exceeded the size limit, and the list was truncated.")
+	}
+
+	// Update metrics, except for shadow calls, which are measured separately
+	if ctx.Value(utils.ShadowExecutionContextKey) == nil {
+		metrics.FunctionExecutionsNum.Inc()
+		d := float64(duration.Milliseconds())
+		metrics.FunctionExecutionDurationMilliseconds.WithLabelValues(fnName).Observe(d)
+		metrics.FunctionExecutionDurationMillisecondsSummary.WithLabelValues(fnName).Observe(d)
+		metrics.FunctionMemoryPeakBytes.WithLabelValues(fnName).Observe(float64(execInfo.memory.PeakBytes))
+		metrics.FunctionMemoryGrowthBytes.WithLabelValues(fnName).Observe(float64(execInfo.memory.GrowthBytes))
+		metering.Add(ctx, metering.UnitInvocations, 1)
+		metering.Add(ctx, metering.UnitWasmMs, d)
+	}
 
 	execInfo.result = result
 	return execInfo, err
 }
+
+func logDiscardedOutput(ctx context.Context, fnName string, buffers utils.OutputBuffers) {
+	streams := map[string]*utils.OutputBuffer{"stdout": buffers.StdOut(), "stderr": buffers.StdErr()}
+	for stream, buf := range streams {
+		discarded := buf.Discarded()
+		if discarded == 0 {
+			continue
+		}
+
+		logger.Warn(ctx).
+			Str("function", fnName).
+			Str("stream", stream).
+			Int64("discarded_bytes", discarded).
+			Bool("user_visible", true).
+			Msg("Function output exceeded the limit and was truncated.")
+
+		metrics.FunctionOutputDiscardedBytesNum.WithLabelValues(fnName, stream).Add(float64(discarded))
+	}
+}
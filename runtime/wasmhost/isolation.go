@@ -0,0 +1,46 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hypermodeinc/modus/runtime/functions"
+)
+
+// FunctionIsolator executes the functions that the manifest requires to run outside of this process.
+type FunctionIsolator interface {
+	IsIsolated(fnName string) bool
+	CallFunction(ctx context.Context, fnName string, parameters map[string]any) (ExecutionInfo, error)
+}
+
+var isolator FunctionIsolator
+
+// RegisterFunctionIsolator sets the isolator that every function call is checked against, so that no caller
+// can execute an isolated function in this process.  It must be called before any plugins are loaded.
+func RegisterFunctionIsolator(i FunctionIsolator) {
+	isolator = i
+}
+
+// IsIsolated reports whether the named function must be executed outside of this process.
+func IsIsolated(fnName string) bool {
+	return isolator != nil && isolator.IsIsolated(fnName)
+}
+
+// callIsolatedFunction executes the function with the isolator.  Only the live build of a function can be isolated,
+// because the isolator calls functions by name, so any other build of it, such as a shadow build, is refused.
+func (host *wasmHost) callIsolatedFunction(ctx context.Context, fnInfo functions.FunctionInfo, parameters map[string]any) (ExecutionInfo, error) {
+	fnName := fnInfo.Name()
+	if live, err := host.GetFunctionInfo(fnName); err != nil || live.Plugin() != fnInfo.Plugin() {
+		return nil, fmt.Errorf("function %s requires process isolation, which is only available for the loaded build of its plugin", fnName)
+	}
+	return isolator.CallFunction(ctx, fnName, parameters)
+}
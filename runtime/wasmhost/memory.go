@@ -0,0 +1,40 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import (
+	wasm "github.com/tetratelabs/wazero/api"
+)
+
+// MemoryUsage is the size of the linear memory of a function's module instance during an invocation.
+// Linear memory can grow but never shrinks, so its peak is its size when the function completes.
+type MemoryUsage struct {
+	InitialBytes uint64 `json:"initialBytes"`
+	PeakBytes    uint64 `json:"peakBytes"`
+	GrowthBytes  uint64 `json:"growthBytes"`
+}
+
+// memorySize samples the size of the linear memory of a module instance.
+func memorySize(mod wasm.Module) uint64 {
+	if mem := mod.Memory(); mem != nil {
+		return uint64(mem.Size())
+	}
+	return 0
+}
+
+func newMemoryUsage(initial, peak uint64) *MemoryUsage {
+	// the memory of a module instance that was closed can't be sampled
+	peak = max(peak, initial)
+	return &MemoryUsage{
+		InitialBytes: initial,
+		PeakBytes:    peak,
+		GrowthBytes:  peak - initial,
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/langsupport"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+// ResponseCursorHeader is the request header that holds the cursor of a truncated list response,
+// from which the function's list is read, continuing where the previous response ended.
+const ResponseCursorHeader = "X-Modus-Response-Cursor"
+
+// ResponseTruncation describes a list response that was truncated to fit the function's response limit.
+type ResponseTruncation struct {
+	langsupport.ResultTruncation
+
+	// Cursor continues reading the list from the first item that was not returned.
+	Cursor string `json:"cursor"`
+}
+
+type responseCursor struct {
+	Function string `json:"fn"`
+	Offset   int    `json:"offset"`
+}
+
+func encodeResponseCursor(fnName string, offset int) string {
+	data, _ := utils.JsonSerialize(responseCursor{fnName, offset})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeResponseCursor(s string) (*responseCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid response cursor")
+	}
+	var c responseCursor
+	if err := utils.JsonDeserialize(data, &c); err != nil || c.Function == "" || c.Offset < 0 {
+		return nil, errors.New("invalid response cursor")
+	}
+	return &c, nil
+}
+
+// getResponseLimit returns the response limit of the function.  If the request has a cursor for the function,
+// the limit starts reading the function's list response at the cursor's offset.
+func getResponseLimit(ctx context.Context, fnName string) (langsupport.ResultLimit, error) {
+	maxBytes, truncate := manifestdata.GetManifest().ResponseLimits.LimitFor(fnName, config.MaxResponseSize)
+	limit := langsupport.ResultLimit{MaxBytes: maxBytes, Truncate: truncate}
+
+	if s, ok := ctx.Value(utils.ResponseCursorContextKey).(string); ok && s != "" {
+		c, err := decodeResponseCursor(s)
+		if err != nil {
+			return limit, err
+		}
+		if c.Function == fnName {
+			limit.Offset = c.Offset
+		}
+	}
+
+	return limit, nil
+}
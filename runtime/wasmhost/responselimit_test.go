@@ -0,0 +1,30 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import "testing"
+
+func Test_ResponseCursor(t *testing.T) {
+	s := encodeResponseCursor("listProducts", 250)
+
+	c, err := decodeResponseCursor(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Function != "listProducts" || c.Offset != 250 {
+		t.Errorf("unexpected cursor %+v", c)
+	}
+
+	for _, invalid := range []string{"not a cursor", encodeResponseCursor("", 1), encodeResponseCursor("fn", -1)} {
+		if _, err := decodeResponseCursor(invalid); err == nil {
+			t.Errorf("expected cursor %q to be invalid", invalid)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/metrics"
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+var getInstanceSlots = sync.OnceValue(func() *instanceSlots {
+	metrics.WasmInstanceSlotsNum.Set(float64(max(config.MaxInstances, 0)))
+	s := newInstanceSlots(config.MaxInstances)
+	s.aging = config.PriorityAging
+	return s
+})
+
+// instanceSlots limits the number of module instances that exist at once.  When all slots are in use,
+// a freed slot goes to the waiting call with the highest priority class.  Among calls of the same class,
+// it goes to the plugin that has the fewest instances, so that a busy plugin cannot starve other plugins.
+// Calls for the same plugin and class are served in the order they arrived.  A call that waits for
+// longer than the aging period is promoted to the next class, so that batch calls are not starved.
+type instanceSlots struct {
+	mu       sync.Mutex
+	capacity int
+	aging    time.Duration
+	inUse    int
+	active   map[string]int
+	waiting  map[string][]*slotWaiter
+}
+
+type slotWaiter struct {
+	plugin   string
+	priority utils.Priority
+	enqueued time.Time
+	ready    chan struct{}
+	granted  bool
+}
+
+func newInstanceSlots(capacity int) *instanceSlots {
+	return &instanceSlots{
+		capacity: capacity,
+		active:   make(map[string]int),
+		waiting:  make(map[string][]*slotWaiter),
+	}
+}
+
+// acquire waits for a slot for an instance of the plugin, and returns a function that releases it.
+func (s *instanceSlots) acquire(ctx context.Context, plugin string) (func(), error) {
+	if s.capacity <= 0 {
+		return func() {}, nil
+	}
+
+	release := func() { s.release(plugin) }
+
+	s.mu.Lock()
+	if s.inUse < s.capacity && len(s.waiting) == 0 {
+		s.grant(plugin)
+		s.mu.Unlock()
+		metrics.WasmInstanceWaitSeconds.WithLabelValues(plugin).Observe(0)
+		return release, nil
+	}
+
+	w := &slotWaiter{plugin: plugin, priority: utils.GetPriority(ctx), enqueued: time.Now(), ready: make(chan struct{})}
+	s.waiting[plugin] = append(s.waiting[plugin], w)
+	metrics.WasmInstancesWaitingNum.WithLabelValues(plugin).Inc()
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		metrics.WasmInstanceWaitSeconds.WithLabelValues(plugin).Observe(time.Since(w.enqueued).Seconds())
+		return release, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		granted := w.granted
+		if !granted {
+			s.removeWaiter(w)
+		}
+		s.mu.Unlock()
+
+		// the slot may have been granted just as the context was canceled
+		if granted {
+			s.release(plugin)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// acquireForCall is like acquire, but a function call nested within another one, such as an embedder called
+// from a host function, shares the slot of the outermost call.  Otherwise, nested calls could wait forever
+// for slots that are held by the calls they are part of.  The returned context marks the slot as held,
+// until it is released.  Work that is detached from the call should also clear the mark with ClearSlotHold,
+// because it runs concurrently with the call.
+func (s *instanceSlots) acquireForCall(ctx context.Context, plugin string) (context.Context, func(), error) {
+	if h, _ := ctx.Value(utils.InstanceSlotContextKey).(*slotHold); h != nil && !h.released.Load() {
+		return ctx, func() {}, nil
+	}
+
+	release, err := s.acquire(ctx, plugin)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	h := &slotHold{}
+	return context.WithValue(ctx, utils.InstanceSlotContextKey, h), func() {
+		h.released.Store(true)
+		release()
+	}, nil
+}
+
+// slotHold marks the slot of a call as held, in the call's context and the contexts derived from it.
+type slotHold struct {
+	released atomic.Bool
+}
+
+// ClearSlotHold returns a context for work that is detached from the function call of the context, so that
+// the function calls it makes acquire their own instance slots, rather than sharing the slot of the call.
+func ClearSlotHold(ctx context.Context) context.Context {
+	return context.WithValue(ctx, utils.InstanceSlotContextKey, nil)
+}
+
+func (s *instanceSlots) release(plugin string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inUse--
+	s.active[plugin]--
+	if s.active[plugin] == 0 {
+		delete(s.active, plugin)
+	}
+	metrics.WasmInstancesInUseNum.WithLabelValues(plugin).Dec()
+
+	s.dispatch()
+}
+
+// dispatch grants free slots to waiting calls.  The caller must hold the lock.
+func (s *instanceSlots) dispatch() {
+	for s.inUse < s.capacity && len(s.waiting) > 0 {
+		w := s.nextWaiter()
+		s.removeWaiter(w)
+		s.grant(w.plugin)
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+// nextWaiter returns the waiting call with the highest priority class, after aging.  Ties go to the plugin
+// with the fewest instances in use, and then to the call that has waited the longest.  The caller must hold the lock.
+func (s *instanceSlots) nextWaiter() *slotWaiter {
+	now := time.Now()
+	var next *slotWaiter
+	var nextPriority utils.Priority
+	for plugin, queue := range s.waiting {
+		for _, w := range queue {
+			p := s.effectivePriority(w, now)
+			if next == nil {
+				next, nextPriority = w, p
+				continue
+			}
+			a, b := s.active[plugin], s.active[next.plugin]
+			if p > nextPriority || (p == nextPriority && (a < b || (a == b && w.enqueued.Before(next.enqueued)))) {
+				next, nextPriority = w, p
+			}
+		}
+	}
+	return next
+}
+
+// effectivePriority returns the priority class of a waiting call, promoted once for each aging period it has waited.
+func (s *instanceSlots) effectivePriority(w *slotWaiter, now time.Time) utils.Priority {
+	p := w.priority
+	if s.aging > 0 {
+		p += utils.Priority(now.Sub(w.enqueued) / s.aging)
+	}
+	return min(p, utils.PriorityHigh)
+}
+
+// removeWaiter removes a call from its plugin's queue.  The caller must hold the lock.
+func (s *instanceSlots) removeWaiter(w *slotWaiter) {
+	queue := s.waiting[w.plugin]
+	for i, q := range queue {
+		if q == w {
+			queue = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) == 0 {
+		delete(s.waiting, w.plugin)
+	} else {
+		s.waiting[w.plugin] = queue
+	}
+	metrics.WasmInstancesWaitingNum.WithLabelValues(w.plugin).Dec()
+}
+
+// grant marks a slot as in use by the plugin.  The caller must hold the lock.
+func (s *instanceSlots) grant(plugin string) {
+	s.inUse++
+	s.active[plugin]++
+	metrics.WasmInstancesInUseNum.WithLabelValues(plugin).Inc()
+}
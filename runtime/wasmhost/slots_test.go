@@ -0,0 +1,251 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/utils"
+)
+
+func Test_InstanceSlots_Unlimited(t *testing.T) {
+	s := newInstanceSlots(0)
+	for range 100 {
+		if _, err := s.acquire(context.Background(), "a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func Test_InstanceSlots_WaitsForRelease(t *testing.T) {
+	s := newInstanceSlots(1)
+	release, err := s.acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r, err := s.acquire(context.Background(), "a")
+		if err != nil {
+			t.Error(err)
+		} else {
+			r()
+		}
+		close(done)
+	}()
+
+	waitForWaiters(t, s, 1)
+	release()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("waiting call was not granted a slot")
+	}
+}
+
+func Test_InstanceSlots_Canceled(t *testing.T) {
+	s := newInstanceSlots(1)
+	release, err := s.acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.acquire(ctx, "b"); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	release()
+	if s.inUse != 0 || len(s.waiting) != 0 {
+		t.Errorf("expected no slots in use or waiting, got %d in use and %d waiting", s.inUse, len(s.waiting))
+	}
+}
+
+func Test_InstanceSlots_FairShare(t *testing.T) {
+	s := newInstanceSlots(2)
+
+	// the busy plugin holds both slots and has more calls queued
+	r1, _ := s.acquire(context.Background(), "busy")
+	r2, _ := s.acquire(context.Background(), "busy")
+
+	granted := make(chan string, 4)
+	acquire := func(plugin string) {
+		go func() {
+			if _, err := s.acquire(context.Background(), plugin); err == nil {
+				granted <- plugin
+			}
+		}()
+	}
+	acquire("busy")
+	waitForWaiters(t, s, 1)
+	acquire("busy")
+	waitForWaiters(t, s, 2)
+	acquire("quiet")
+	waitForWaiters(t, s, 3)
+
+	// the quiet plugin has no instances, so it is served first even though it arrived last
+	r1()
+	if p := <-granted; p != "quiet" {
+		t.Errorf("expected quiet plugin to be granted a slot first, got %s", p)
+	}
+
+	r2()
+	if p := <-granted; p != "busy" {
+		t.Errorf("expected busy plugin to be granted the next slot, got %s", p)
+	}
+}
+
+func Test_InstanceSlots_Priority(t *testing.T) {
+	s := newInstanceSlots(1)
+	release, _ := s.acquire(context.Background(), "a")
+
+	granted := make(chan utils.Priority, 3)
+	acquire := func(p utils.Priority) {
+		ctx := context.WithValue(context.Background(), utils.PriorityContextKey, p)
+		go func() {
+			if r, err := s.acquire(ctx, "a"); err == nil {
+				granted <- p
+				r()
+			}
+		}()
+	}
+	acquire(utils.PriorityBatch)
+	waitForWaiters(t, s, 1)
+	acquire(utils.PriorityNormal)
+	waitForWaiters(t, s, 2)
+	acquire(utils.PriorityHigh)
+	waitForWaiters(t, s, 3)
+
+	// the calls are served by priority class, regardless of the order they arrived in
+	release()
+	for _, expected := range []utils.Priority{utils.PriorityHigh, utils.PriorityNormal, utils.PriorityBatch} {
+		if p := <-granted; p != expected {
+			t.Errorf("expected a %s call to be granted a slot, got %s", expected, p)
+		}
+	}
+}
+
+func Test_InstanceSlots_PriorityAging(t *testing.T) {
+	s := newInstanceSlots(1)
+	s.aging = time.Minute
+
+	now := time.Now()
+	batch := &slotWaiter{plugin: "a", priority: utils.PriorityBatch, enqueued: now.Add(-2 * time.Minute)}
+	normal := &slotWaiter{plugin: "a", priority: utils.PriorityNormal, enqueued: now.Add(-30 * time.Second)}
+	high := &slotWaiter{plugin: "b", priority: utils.PriorityHigh, enqueued: now}
+	s.waiting["a"] = []*slotWaiter{batch, normal}
+
+	// the batch call has waited for two aging periods, so it is served before the newer normal call
+	if w := s.nextWaiter(); w != batch {
+		t.Errorf("expected the aged batch call to be next, got a %s call", w.priority)
+	}
+
+	// a promoted call ties with a high priority call, and the longest waiting one goes first
+	s.waiting["b"] = []*slotWaiter{high}
+	if w := s.nextWaiter(); w != batch {
+		t.Errorf("expected the aged batch call to be next, got a %s call", w.priority)
+	}
+	if p := s.effectivePriority(normal, now); p != utils.PriorityNormal {
+		t.Errorf("expected the normal call not to be promoted yet, got %s", p)
+	}
+}
+
+func Test_InstanceSlots_NestedCallSharesSlot(t *testing.T) {
+	s := newInstanceSlots(1)
+	ctx, release, err := s.acquireForCall(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a nested call, such as an embedder called while the outer function runs, must not wait for the outer call's slot
+	nestedCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, nestedRelease, err := s.acquireForCall(nestedCtx, "b")
+	if err != nil {
+		t.Fatalf("nested call was not given a slot: %v", err)
+	}
+	nestedRelease()
+
+	if s.inUse != 1 {
+		t.Errorf("expected the nested call to share the outer call's slot, got %d in use", s.inUse)
+	}
+
+	release()
+	if s.inUse != 0 {
+		t.Errorf("expected no slots in use, got %d", s.inUse)
+	}
+
+	// an unrelated call still has to wait for a slot
+	heldCtx, release, _ := s.acquireForCall(context.Background(), "a")
+	defer release()
+	otherCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := s.acquireForCall(otherCtx, "b"); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	// so does work that is detached from a call that holds a slot
+	detachedCtx, cancel := context.WithTimeout(ClearSlotHold(context.WithoutCancel(heldCtx)), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := s.acquireForCall(detachedCtx, "b"); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func Test_InstanceSlots_ReleasedSlotIsNotShared(t *testing.T) {
+	s := newInstanceSlots(1)
+	ctx, release, err := s.acquireForCall(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	// a call made with the context of a call that has returned must acquire its own slot
+	laterCtx, laterRelease, err := s.acquireForCall(context.WithoutCancel(ctx), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.inUse != 1 {
+		t.Errorf("expected the later call to hold its own slot, got %d in use", s.inUse)
+	}
+
+	_, nestedRelease, err := s.acquireForCall(laterCtx, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nestedRelease()
+	laterRelease()
+	if s.inUse != 0 {
+		t.Errorf("expected no slots in use, got %d", s.inUse)
+	}
+}
+
+func waitForWaiters(t *testing.T, s *instanceSlots, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		count := 0
+		for _, q := range s.waiting {
+			count += len(q)
+		}
+		s.mu.Unlock()
+		if count == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d waiting calls", n)
+}
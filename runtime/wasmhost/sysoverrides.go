@@ -0,0 +1,119 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/utils"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// SysOverrides replaces the clock and random source that a function invocation sees through WASI.
+// Any field that is nil is left bound to the real clock or crypto/rand.
+type SysOverrides struct {
+	Walltime sys.Walltime
+	Nanotime sys.Nanotime
+	Rand     io.Reader
+}
+
+// ContextWithSysOverrides returns a context whose function invocations use the given clock and random source.
+// This is intended for test harnesses that need reproducible results.
+func ContextWithSysOverrides(ctx context.Context, overrides *SysOverrides) context.Context {
+	return context.WithValue(ctx, utils.SysOverridesContextKey, overrides)
+}
+
+// NewDeterministicOverrides returns overrides with a clock that starts at the given time and advances by
+// one millisecond each time it is read, and a random source seeded with the given seed.
+func NewDeterministicOverrides(start time.Time, seed uint64) *SysOverrides {
+	clock := &steppingClock{now: start.UnixNano(), step: int64(time.Millisecond)}
+	var seedBytes [32]byte
+	for i := range 8 {
+		seedBytes[i] = byte(seed >> (8 * i))
+	}
+	return &SysOverrides{
+		Walltime: clock.walltime,
+		Nanotime: clock.nanotime,
+		Rand:     rand.NewChaCha8(seedBytes),
+	}
+}
+
+// getSysOverrides returns the overrides for an invocation, from the context or from deterministic mode.
+func getSysOverrides(ctx context.Context) *SysOverrides {
+	if o, ok := ctx.Value(utils.SysOverridesContextKey).(*SysOverrides); ok {
+		return o
+	}
+	if config.Deterministic {
+		return NewDeterministicOverrides(getDeterministicStart(ctx), config.DeterministicSeed)
+	}
+	return nil
+}
+
+var getDeterministicStart = func() func(ctx context.Context) time.Time {
+	var once sync.Once
+	var start time.Time
+	return func(ctx context.Context) time.Time {
+		once.Do(func() {
+			t, err := time.Parse(time.RFC3339Nano, config.DeterministicTime)
+			if err != nil {
+				logger.Warn(ctx).Err(err).Str("time", config.DeterministicTime).Msg("Invalid -deterministicTime.  Using the Unix epoch instead.")
+				t = time.Unix(0, 0)
+			}
+			start = t
+		})
+		return start
+	}
+}()
+
+// apply binds the module configuration to the overridden clock and random source.
+func (o *SysOverrides) apply(cfg wazero.ModuleConfig) wazero.ModuleConfig {
+	if o.Walltime != nil {
+		cfg = cfg.WithWalltime(o.Walltime, sys.ClockResolution(time.Microsecond))
+	}
+	if o.Nanotime != nil {
+		cfg = cfg.WithNanotime(o.Nanotime, sys.ClockResolution(time.Microsecond))
+	}
+	if o.Rand != nil {
+		cfg = cfg.WithRandSource(o.Rand)
+	}
+	return cfg
+}
+
+// steppingClock is a clock that advances by a fixed step each time it is read.
+type steppingClock struct {
+	mu   sync.Mutex
+	now  int64
+	step int64
+}
+
+func (c *steppingClock) next() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now
+	c.now += c.step
+	return now
+}
+
+func (c *steppingClock) walltime() (int64, int32) {
+	now := c.next()
+	return now / int64(time.Second), int32(now % int64(time.Second))
+}
+
+func (c *steppingClock) nanotime() int64 {
+	return c.next()
+}
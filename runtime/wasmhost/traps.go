@@ -0,0 +1,171 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// WasmTrap describes a trap in the WASM code of a function, such as an unreachable instruction or an out of bounds
+// memory access.  The frames of the stack are named by the plugin's name section, and have source locations when
+// the plugin was built with DWARF debug information.
+type WasmTrap struct {
+	Reason string      `json:"reason"`
+	Frames []TrapFrame `json:"frames"`
+}
+
+// TrapFrame is a frame of the stack of a trap, innermost first.
+type TrapFrame struct {
+	Function string   `json:"function"`
+	File     string   `json:"file,omitempty"`
+	Sources  []string `json:"sources,omitempty"`
+	Internal bool     `json:"internal,omitempty"`
+}
+
+const trapPrefix = "wasm error: "
+const stackTracePrefix = "\nwasm stack trace:\n"
+
+// matches a frame of a wazero stack trace, such as ".assembly/index/divide(i32,i32) i32"
+var framePattern = regexp.MustCompile(`^(.*?)\.(.+)\([a-z0-9,]*\)(?: \(?[a-z0-9,]+\)?)?$`)
+
+// parseTrap returns the trap that an error from calling a function describes, or nil if it is not a trap.
+// The function names of the stack are made readable by the conventions of the plugin's language.
+func parseTrap(err error, language string) *WasmTrap {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	i := strings.Index(msg, trapPrefix)
+	if i < 0 {
+		return nil
+	}
+	msg = msg[i+len(trapPrefix):]
+
+	trap := &WasmTrap{}
+	reason, stack, _ := strings.Cut(msg, stackTracePrefix)
+	trap.Reason = strings.TrimSpace(reason)
+
+	for _, line := range strings.Split(stack, "\n") {
+		switch {
+		case strings.HasPrefix(line, "\t\t"):
+			// a source location of the previous frame, from DWARF debug information
+			if n := len(trap.Frames); n > 0 {
+				trap.Frames[n-1].Sources = append(trap.Frames[n-1].Sources, strings.TrimSpace(line))
+			}
+		case strings.HasPrefix(line, "\t"):
+			trap.Frames = append(trap.Frames, parseFrame(strings.TrimSpace(line), language))
+		}
+	}
+
+	return trap
+}
+
+func parseFrame(line, language string) TrapFrame {
+	m := framePattern.FindStringSubmatch(line)
+	if m == nil {
+		return TrapFrame{Function: line}
+	}
+
+	module, name := m[1], m[2]
+	if module != "" {
+		// a host function, which is named by the module it was imported from
+		return TrapFrame{Function: module + "." + strings.TrimPrefix(name, module+"."), Internal: true}
+	}
+
+	if idx, ok := strings.CutPrefix(name, "$"); ok {
+		// the plugin has no name section, so only the index of the function is known
+		return TrapFrame{Function: "wasm function #" + idx}
+	}
+
+	switch language {
+	case "AssemblyScript":
+		// AssemblyScript names functions by their file, such as "assembly/index/divide",
+		// or "~lib/array/Array<i32>#push" for the standard library.
+		name = strings.TrimPrefix(name, "start:")
+		internal := strings.HasPrefix(name, "~lib/")
+		if i := lastPathSeparator(name); i > 0 {
+			return TrapFrame{Function: name[i+1:], File: name[:i] + ".ts", Internal: internal}
+		}
+		return TrapFrame{Function: name, Internal: internal}
+
+	case "Go":
+		// Go names functions by their package, such as "main.divide" or "runtime.runtimePanicAt".
+		internal := strings.HasPrefix(name, "runtime.") ||
+			strings.HasPrefix(name, "internal/") ||
+			strings.HasPrefix(name, "syscall.") ||
+			strings.HasPrefix(name, "syscall/")
+		return TrapFrame{Function: name, Internal: internal}
+	}
+
+	return TrapFrame{Function: name}
+}
+
+// lastPathSeparator returns the index of the last slash of a name, outside of any type arguments.
+func lastPathSeparator(name string) int {
+	depth := 0
+	for i := len(name) - 1; i >= 0; i-- {
+		switch name[i] {
+		case '>':
+			depth++
+		case '<':
+			depth--
+		case '/':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// String returns a readable stack trace of the trap.  Internal frames of host functions and language runtimes
+// are summarized, unless the trap has no other frames.
+func (t *WasmTrap) String() string {
+	var sb strings.Builder
+	sb.WriteString(t.Reason)
+
+	all := true
+	for _, f := range t.Frames {
+		if !f.Internal {
+			all = false
+			break
+		}
+	}
+
+	skipped := 0
+	for _, f := range t.Frames {
+		if f.Internal && !all {
+			skipped++
+			continue
+		}
+		if skipped > 0 {
+			fmt.Fprintf(&sb, "\n    ... %d internal frame(s)", skipped)
+			skipped = 0
+		}
+
+		sb.WriteString("\n    at ")
+		sb.WriteString(f.Function)
+		if f.File != "" {
+			fmt.Fprintf(&sb, " (%s)", f.File)
+		}
+		for _, src := range f.Sources {
+			sb.WriteString("\n        ")
+			sb.WriteString(src)
+		}
+	}
+	if skipped > 0 {
+		fmt.Fprintf(&sb, "\n    ... %d internal frame(s)", skipped)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package wasmhost
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func Test_ParseTrap_AssemblyScript(t *testing.T) {
+	err := fmt.Errorf("failed to call function: %w", errors.New("wasm error: integer divide by zero\n"+
+		"wasm stack trace:\n"+
+		"\t.~lib/array/Array<~lib/string/String>#__get(i32,i32) i32\n"+
+		"\t.assembly/index/divide(i32,i32) i32\n"+
+		"\t.__modus_divide(i32,i32) i32"))
+
+	trap := parseTrap(err, "AssemblyScript")
+	if trap == nil {
+		t.Fatal("expected a trap")
+	}
+	if trap.Reason != "integer divide by zero" {
+		t.Errorf("unexpected reason %q", trap.Reason)
+	}
+	if len(trap.Frames) != 3 {
+		t.Fatalf("expected 3 frames, got %d", len(trap.Frames))
+	}
+
+	f := trap.Frames[0]
+	if f.Function != "Array<~lib/string/String>#__get" || f.File != "~lib/array.ts" || !f.Internal {
+		t.Errorf("unexpected frame %+v", f)
+	}
+	f = trap.Frames[1]
+	if f.Function != "divide" || f.File != "assembly/index.ts" || f.Internal {
+		t.Errorf("unexpected frame %+v", f)
+	}
+
+	expected := "integer divide by zero\n" +
+		"    ... 1 internal frame(s)\n" +
+		"    at divide (assembly/index.ts)\n" +
+		"    at __modus_divide"
+	if s := trap.String(); s != expected {
+		t.Errorf("unexpected trace:\n%s", s)
+	}
+}
+
+func Test_ParseTrap_Go(t *testing.T) {
+	err := errors.New("wasm error: out of bounds memory access\n" +
+		"wasm stack trace:\n" +
+		"\t.runtime.runtimePanicAt(i32,i32)\n" +
+		"\t\t/usr/local/lib/tinygo/src/runtime/panic.go:115:6\n" +
+		"\t.main.readItem(i32) i32\n" +
+		"\t\t/src/main.go:12:3\n" +
+		"\t.$42()")
+
+	trap := parseTrap(err, "Go")
+	if trap == nil {
+		t.Fatal("expected a trap")
+	}
+	if len(trap.Frames) != 3 {
+		t.Fatalf("expected 3 frames, got %d", len(trap.Frames))
+	}
+	if f := trap.Frames[0]; f.Function != "runtime.runtimePanicAt" || !f.Internal {
+		t.Errorf("unexpected frame %+v", f)
+	}
+	if f := trap.Frames[1]; f.Function != "main.readItem" || len(f.Sources) != 1 || f.Sources[0] != "/src/main.go:12:3" {
+		t.Errorf("unexpected frame %+v", f)
+	}
+	if f := trap.Frames[2]; f.Function != "wasm function #42" {
+		t.Errorf("unexpected frame %+v", f)
+	}
+}
+
+func Test_ParseTrap_NotATrap(t *testing.T) {
+	if parseTrap(errors.New("connection refused"), "Go") != nil {
+		t.Error("expected no trap")
+	}
+	if parseTrap(nil, "Go") != nil {
+		t.Error("expected no trap")
+	}
+}
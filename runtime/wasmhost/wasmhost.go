@@ -13,10 +13,11 @@ import (
 	"context"
 	"crypto/rand"
 	"fmt"
-	"io"
 
+	"github.com/hypermodeinc/modus/lib/metadata"
 	"github.com/hypermodeinc/modus/runtime/functions"
 	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/manifestdata"
 	"github.com/hypermodeinc/modus/runtime/middleware"
 	"github.com/hypermodeinc/modus/runtime/plugins"
 	"github.com/hypermodeinc/modus/runtime/timezones"
@@ -32,6 +33,7 @@ type WasmHost interface {
 	RegisterHostFunction(modName, funcName string, fn any, opts ...HostFunctionOption) error
 	CallFunction(ctx context.Context, fnInfo functions.FunctionInfo, parameters map[string]any) (ExecutionInfo, error)
 	CallFunctionByName(ctx context.Context, fnName string, paramValues ...any) (ExecutionInfo, error)
+	CheckCompatibility(cm wazero.CompiledModule, md *metadata.Metadata) error
 	Close(ctx context.Context)
 	CompileModule(ctx context.Context, bytes []byte) (wazero.CompiledModule, error)
 	GetFunctionInfo(fnName string) (functions.FunctionInfo, error)
@@ -109,8 +111,9 @@ func (host *wasmHost) GetModuleInstance(ctx context.Context, plugin *plugins.Plu
 	wErrorLog := logger.NewLogWriter(&log, zerolog.ErrorLevel)
 
 	// Capture stdout/stderr both to logs, and to provided writers.
-	wOut := io.MultiWriter(buffers.StdOut(), wInfoLog)
-	wErr := io.MultiWriter(buffers.StdErr(), wErrorLog)
+	// Output beyond the buffers' limits is neither captured nor logged.
+	wOut := buffers.StdOut().Tee(wInfoLog)
+	wErr := buffers.StdErr().Tee(wErrorLog)
 
 	// Get the time zone to pass to the module instance.
 	var timeZone string
@@ -125,24 +128,60 @@ func (host *wasmHost) GetModuleInstance(ctx context.Context, plugin *plugins.Plu
 	// for concurrency and performance reasons.
 	// See https://github.com/tetratelabs/wazero/pull/2275
 	// And https://gophers.slack.com/archives/C040AKTNTE0/p1719587772724619?thread_ts=1719522663.531579&cid=C040AKTNTE0
-	jwtClaims := middleware.GetJWTClaims(ctx)
 	cfg := wazero.NewModuleConfig().
 		WithName("").
-		WithStartFunctions("_initialize", "_start").
 		WithSysWalltime().WithSysNanotime().
 		WithRandSource(rand.Reader).
 		WithStdout(wOut).WithStderr(wErr).
-		WithEnv("TZ", timeZone).
-		WithEnv("CLAIMS", jwtClaims)
+		WithEnv("TZ", timeZone)
+
+	// Plugins that can be restored from snapshots read the caller's JWT claims with a host function on every call,
+	// so they are left out of the environment, which is captured in the snapshot.  Other plugins may be built with
+	// SDKs that still read them from the environment.
+	if !plugin.SnapshotsEnabled() {
+		cfg = cfg.WithEnv("CLAIMS", middleware.GetJWTClaims(ctx))
+	}
+
+	// Replace the clock and random source, if requested for reproducible results.
+	overrides := getSysOverrides(ctx)
+	if overrides != nil {
+		cfg = overrides.apply(cfg)
+	}
+
+	// Give the plugin access to its sandboxed directory, if it has one.
+	var mountPath string
+	var cleanup func()
+	if fsInfo, ok := manifestdata.GetManifest().Filesystems[plugin.Name()]; ok {
+		fsConfig, fn, err := getFSConfig(ctx, plugin.Name(), fsInfo)
+		if err != nil {
+			return nil, err
+		}
+		cfg = cfg.WithFSConfig(fsConfig)
+		cleanup = fn
+		mountPath = fsInfo.GetMountPath()
+	}
 
 	// Instantiate the plugin as a module.
 	// NOTE: This will also invoke the plugin's `_start` function,
-	// which will call any top-level code in the plugin.
-	mod, err := host.runtime.InstantiateModule(ctx, plugin.Module, cfg)
+	// which will call any top-level code in the plugin,
+	// unless the instance is restored from a snapshot of an instance with the same environment.
+	// Instances with overridden clocks or random sources are never restored from snapshots,
+	// since the snapshot would not reflect the values they produce.
+	env := timeZone + "\x00" + mountPath
+	if overrides != nil {
+		env = ""
+	}
+	mod, err := plugin.InstantiateModule(ctx, host.runtime, cfg, env)
 	if err != nil {
+		if cleanup != nil {
+			cleanup()
+		}
 		return nil, fmt.Errorf("failed to instantiate the plugin module: %w", err)
 	}
 
+	if cleanup != nil {
+		return &cleanupModule{mod, cleanup}, nil
+	}
 	return mod, nil
 }
 
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+)
+
+var errMissingSignature = errors.New("the delivery is not signed")
+var errInvalidSignature = errors.New("the signature of the delivery is invalid")
+var errExpiredTimestamp = errors.New("the timestamp of the delivery is outside the tolerance")
+
+// verifyDelivery verifies the signature of a delivery by the scheme of its provider, and returns a key that
+// identifies the delivery, so that a delivery that is sent again can be rejected as a replay.
+// The key is made from the expected signature rather than the one that was sent, which the sender could
+// encode differently, such as in uppercase hex, to make a replay look like a new delivery.
+func verifyDelivery(v *manifest.WebhookVerifyInfo, header http.Header, body []byte, secret string, now time.Time) (string, error) {
+	tolerance := time.Duration(v.Tolerance()) * time.Second
+
+	switch v.Provider {
+	case manifest.WebhookProviderStripe:
+		return verifyStripe(header, body, secret, now, tolerance)
+	case manifest.WebhookProviderGitHub:
+		return verifyGitHub(header, body, secret)
+	case manifest.WebhookProviderSlack:
+		return verifySlack(header, body, secret, now, tolerance)
+	default:
+		return "", fmt.Errorf("unsupported webhook provider: %s", v.Provider)
+	}
+}
+
+// verifyStripe verifies a Stripe-Signature header, which has the form t=<timestamp>,v1=<signature>,...
+// The signature is of the timestamp and the payload, and there may be more than one while a secret is rolled.
+// See https://docs.stripe.com/webhooks#verify-manually
+func verifyStripe(header http.Header, body []byte, secret string, now time.Time, tolerance time.Duration) (string, error) {
+	value := header.Get("Stripe-Signature")
+	if value == "" {
+		return "", errMissingSignature
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(value, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			timestamp = v
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return "", errMissingSignature
+	}
+
+	if err := checkTimestamp(timestamp, now, tolerance); err != nil {
+		return "", err
+	}
+
+	expected := sign(secret, timestamp, ".", body)
+	for _, sig := range signatures {
+		if signatureEqual(expected, sig) {
+			return "stripe:" + hex.EncodeToString(expected), nil
+		}
+	}
+	return "", errInvalidSignature
+}
+
+// verifyGitHub verifies an X-Hub-Signature-256 header, which has the form sha256=<signature>.
+// GitHub doesn't sign a timestamp or the ID of the delivery, so a replay is recognized by the signature of
+// its payload alone, which is remembered for longer than the tolerance of the other providers.
+// See https://docs.github.com/en/webhooks/using-webhooks/validating-webhook-deliveries
+func verifyGitHub(header http.Header, body []byte, secret string) (string, error) {
+	sig, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok || sig == "" {
+		return "", errMissingSignature
+	}
+
+	expected := sign(secret, "", "", body)
+	if !signatureEqual(expected, sig) {
+		return "", errInvalidSignature
+	}
+	return "github:" + hex.EncodeToString(expected), nil
+}
+
+// verifySlack verifies an X-Slack-Signature header, which has the form v0=<signature>.  The signature is
+// of the version, the timestamp in the X-Slack-Request-Timestamp header, and the payload.
+// See https://api.slack.com/authentication/verifying-requests-from-slack
+func verifySlack(header http.Header, body []byte, secret string, now time.Time, tolerance time.Duration) (string, error) {
+	sig, ok := strings.CutPrefix(header.Get("X-Slack-Signature"), "v0=")
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	if !ok || sig == "" || timestamp == "" {
+		return "", errMissingSignature
+	}
+
+	if err := checkTimestamp(timestamp, now, tolerance); err != nil {
+		return "", err
+	}
+
+	expected := sign(secret, "v0:"+timestamp, ":", body)
+	if !signatureEqual(expected, sig) {
+		return "", errInvalidSignature
+	}
+	return "slack:" + hex.EncodeToString(expected), nil
+}
+
+func checkTimestamp(timestamp string, now time.Time, tolerance time.Duration) error {
+	secs, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp: %s", timestamp)
+	}
+
+	age := now.Sub(time.Unix(secs, 0))
+	if age > tolerance || age < -tolerance {
+		return errExpiredTimestamp
+	}
+	return nil
+}
+
+// sign returns the HMAC-SHA256 of the payload, preceded by the prefix and separator if there is a prefix.
+func sign(secret, prefix, separator string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	if prefix != "" {
+		mac.Write([]byte(prefix))
+		mac.Write([]byte(separator))
+	}
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func signatureEqual(expected []byte, signature string) bool {
+	actual, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, actual)
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package webhooks
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "whsec_test"
+
+var testBody = []byte(`{"id":"evt_1","type":"invoice.paid"}`)
+
+func TestVerifyStripe(t *testing.T) {
+	v := &manifest.WebhookVerifyInfo{Provider: manifest.WebhookProviderStripe, Secret: "STRIPE_WEBHOOK_SECRET"}
+	now := time.Unix(1700000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := hex.EncodeToString(sign(testSecret, ts, ".", testBody))
+
+	header := http.Header{}
+	header.Set("Stripe-Signature", "t="+ts+",v1=0000,v1="+sig)
+	key, err := verifyDelivery(v, header, testBody, testSecret, now)
+	require.NoError(t, err)
+	require.Equal(t, "stripe:"+sig, key)
+
+	// the key doesn't depend on how the signature is encoded
+	header.Set("Stripe-Signature", "t="+ts+",v1="+strings.ToUpper(sig))
+	key, err = verifyDelivery(v, header, testBody, testSecret, now)
+	require.NoError(t, err)
+	require.Equal(t, "stripe:"+sig, key)
+
+	_, err = verifyDelivery(v, header, []byte(`{"id":"evt_2"}`), testSecret, now)
+	require.ErrorIs(t, err, errInvalidSignature)
+
+	_, err = verifyDelivery(v, header, testBody, testSecret, now.Add(6*time.Minute))
+	require.ErrorIs(t, err, errExpiredTimestamp)
+
+	_, err = verifyDelivery(v, http.Header{}, testBody, testSecret, now)
+	require.ErrorIs(t, err, errMissingSignature)
+}
+
+func TestVerifyGitHub(t *testing.T) {
+	// the example from GitHub's documentation
+	v := &manifest.WebhookVerifyInfo{Provider: manifest.WebhookProviderGitHub, Secret: "GITHUB_WEBHOOK_SECRET"}
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17")
+	header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+
+	key, err := verifyDelivery(v, header, []byte("Hello, World!"), "It's a Secret to Everybody", time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "github:757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17", key)
+
+	// the delivery ID isn't signed, so it isn't part of the key
+	header.Set("X-GitHub-Delivery", "a-different-id")
+	key2, err := verifyDelivery(v, header, []byte("Hello, World!"), "It's a Secret to Everybody", time.Now())
+	require.NoError(t, err)
+	require.Equal(t, key, key2)
+
+	_, err = verifyDelivery(v, header, []byte("Hello, World!"), "wrong secret", time.Now())
+	require.ErrorIs(t, err, errInvalidSignature)
+}
+
+func TestVerifySlack(t *testing.T) {
+	v := &manifest.WebhookVerifyInfo{Provider: manifest.WebhookProviderSlack, Secret: "SLACK_SIGNING_SECRET", ToleranceSeconds: 60}
+	now := time.Unix(1700000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := hex.EncodeToString(sign(testSecret, "v0:"+ts, ":", testBody))
+
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", ts)
+	header.Set("X-Slack-Signature", "v0="+sig)
+	_, err := verifyDelivery(v, header, testBody, testSecret, now.Add(30*time.Second))
+	require.NoError(t, err)
+
+	_, err = verifyDelivery(v, header, testBody, testSecret, now.Add(2*time.Minute))
+	require.ErrorIs(t, err, errExpiredTimestamp)
+}
+
+func TestReplayCache(t *testing.T) {
+	c := getReplayCache("test")
+	now := time.Now()
+
+	require.True(t, c.add("key", now, now.Add(time.Minute)))
+	require.False(t, c.add("key", now.Add(30*time.Second), now.Add(90*time.Second)))
+
+	c.remove("key")
+	require.True(t, c.add("key", now, now.Add(time.Minute)))
+
+	// expired keys are forgotten
+	require.True(t, c.add("key", now.Add(2*time.Minute), now.Add(3*time.Minute)))
+
+	// keys without an expiry are kept until they are evicted
+	require.True(t, c.add("github", now, time.Time{}))
+	require.False(t, c.add("github", now.Add(time.Hour), time.Time{}))
+	for i := range maxUnexpiringReplayKeys {
+		require.True(t, c.add(strconv.Itoa(i), now, time.Time{}))
+	}
+	require.True(t, c.add("github", now, time.Time{}))
+}
@@ -0,0 +1,224 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package webhooks handles the webhook endpoints declared in the manifest.  Each delivery is verified by the
+// signature scheme of the provider that sent it, and checked for replays, before its payload is passed to a function.
+package webhooks
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hypermodeinc/modus/lib/manifest"
+	"github.com/hypermodeinc/modus/runtime/config"
+	"github.com/hypermodeinc/modus/runtime/functions"
+	"github.com/hypermodeinc/modus/runtime/logger"
+	"github.com/hypermodeinc/modus/runtime/secrets"
+	"github.com/hypermodeinc/modus/runtime/utils"
+	"github.com/hypermodeinc/modus/runtime/wasmhost"
+)
+
+// maxPayloadBytes limits the size of a delivery, which is read in full before its signature can be verified.
+const maxPayloadBytes = 10 << 20
+
+var wasmHost wasmhost.WasmHost
+
+// The replay caches are kept by endpoint name, so that they survive reloading the manifest.
+var replayCaches = make(map[string]*replayCache)
+var replayCachesMu sync.Mutex
+
+// Initialize stores the WASM host, so that webhook endpoints can call functions.
+func Initialize(ctx context.Context) {
+	wasmHost = wasmhost.GetWasmHost(ctx)
+}
+
+// NewHandler returns the handler of a webhook endpoint.  The function of the endpoint is called with the payload
+// of each delivery as its only argument, and its result is returned as the response to the delivery.
+func NewHandler(info manifest.WebhookEndpointInfo) http.Handler {
+	replays := getReplayCache(info.Name)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadBytes))
+		if err != nil {
+			http.Error(w, "Failed to read webhook payload.", http.StatusBadRequest)
+			return
+		}
+
+		var replayKey string
+		if info.Verify != nil {
+			secret, err := secrets.GetSecretValue(info.Verify.Secret)
+			if err != nil {
+				msg := "Failed to get the secret of the webhook endpoint."
+				logger.Err(ctx, err).Str("endpoint", info.Name).Str("secret", info.Verify.Secret).Msg(msg)
+				http.Error(w, msg, http.StatusInternalServerError)
+				return
+			}
+
+			now := time.Now()
+			replayKey, err = verifyDelivery(info.Verify, r.Header, body, secret, now)
+			if err != nil {
+				// NOTE: We only log these in dev, to avoid a bad actor spamming the logs in prod.
+				if config.IsDevEnvironment() {
+					logger.Warn(ctx).Err(err).Str("endpoint", info.Name).Msg("Rejected webhook delivery.")
+				}
+				http.Error(w, "Invalid webhook signature.", http.StatusUnauthorized)
+				return
+			}
+
+			// A delivery without a signed timestamp can be replayed at any time, so its key doesn't expire.
+			var expires time.Time
+			if info.Verify.Provider != manifest.WebhookProviderGitHub {
+				expires = now.Add(time.Duration(info.Verify.Tolerance()) * time.Second)
+			}
+			if !replays.add(replayKey, now, expires) {
+				if config.IsDevEnvironment() {
+					logger.Warn(ctx).Str("endpoint", info.Name).Msg("Rejected replayed webhook delivery.")
+				}
+				http.Error(w, "Webhook delivery was already received.", http.StatusConflict)
+				return
+			}
+		}
+
+		result, err := callFunction(ctx, info.Function, string(body))
+		if err != nil {
+			// Let the provider retry a delivery that failed, without it being rejected as a replay.
+			if replayKey != "" {
+				replays.remove(replayKey)
+			}
+
+			msg := "Failed to handle webhook delivery."
+			logger.Err(ctx, err).Str("endpoint", info.Name).Str("function", info.Function).Msg(msg)
+			http.Error(w, msg, http.StatusInternalServerError)
+			return
+		}
+
+		if result == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		j, err := utils.JsonSerialize(result)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		utils.WriteJsonContentHeader(w)
+		_, _ = w.Write(j)
+	})
+}
+
+func callFunction(ctx context.Context, fnName string, payload string) (any, error) {
+	if wasmHost == nil {
+		return nil, errors.New("no plugin is loaded")
+	}
+
+	fnInfo, err := wasmHost.GetFunctionInfo(fnName)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters, err := functions.CreateParametersMap(fnInfo.Metadata(), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, utils.WasmHostContextKey, wasmHost)
+
+	execInfo, err := wasmHost.CallFunction(ctx, fnInfo, parameters)
+	if err != nil {
+		return nil, err
+	}
+	return execInfo.Result(), nil
+}
+
+// maxUnexpiringReplayKeys limits the keys of deliveries without a signed timestamp that are kept per endpoint.
+// When the limit is reached, the least recently received delivery is forgotten.
+const maxUnexpiringReplayKeys = 10000
+
+// replayCache holds the keys of the deliveries that were received within the tolerance of an endpoint.
+// After that, a delivery with a signed timestamp is rejected by its timestamp, so its key can be forgotten.
+// The keys of deliveries without a signed timestamp are kept in a bounded LRU list instead.
+type replayCache struct {
+	mu        sync.Mutex
+	keys      map[string]time.Time
+	unexpired map[string]*list.Element
+	lru       *list.List
+}
+
+func getReplayCache(endpoint string) *replayCache {
+	replayCachesMu.Lock()
+	defer replayCachesMu.Unlock()
+
+	c, ok := replayCaches[endpoint]
+	if !ok {
+		c = &replayCache{
+			keys:      make(map[string]time.Time),
+			unexpired: make(map[string]*list.Element),
+			lru:       list.New(),
+		}
+		replayCaches[endpoint] = c
+	}
+	return c
+}
+
+// add records the key of a delivery until it expires, and reports false if it was already recorded.
+// A key with a zero expiry is kept until it is evicted by newer keys.
+func (c *replayCache) add(key string, now, expires time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if expires.IsZero() {
+		if el, found := c.unexpired[key]; found {
+			c.lru.MoveToFront(el)
+			return false
+		}
+		c.unexpired[key] = c.lru.PushFront(key)
+		if c.lru.Len() > maxUnexpiringReplayKeys {
+			oldest := c.lru.Back()
+			c.lru.Remove(oldest)
+			delete(c.unexpired, oldest.Value.(string))
+		}
+		return true
+	}
+
+	for k, exp := range c.keys {
+		if now.After(exp) {
+			delete(c.keys, k)
+		}
+	}
+
+	if _, found := c.keys[key]; found {
+		return false
+	}
+	c.keys[key] = expires
+	return true
+}
+
+func (c *replayCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.keys, key)
+	if el, found := c.unexpired[key]; found {
+		c.lru.Remove(el)
+		delete(c.unexpired, key)
+	}
+}
@@ -8,8 +8,15 @@
  */
 import { JSON } from "json-as";
 
+// @ts-expect-error: decorator
+@external("modus_system", "getJWTClaims")
+declare function hostGetJWTClaims(): string | null;
+
+/**
+ * Gets the claims of the token that the caller of the current function was authenticated with.
+ */
 export function getJWTClaims<T>(): T {
-  const claims = process.env.get("CLAIMS");
+  const claims = hostGetJWTClaims();
   if (!claims) {
     console.warn("No JWT claims found.");
     return instantiate<T>();
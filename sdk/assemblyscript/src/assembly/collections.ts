@@ -32,6 +32,8 @@ abstract class CollectionResult {
 export class CollectionMutationResult extends CollectionResult {
   operation: string;
   keys: string[] = [];
+  duplicates: CollectionDuplicateObject[] = [];
+  pii: CollectionPIIObject[] = [];
 
   constructor(
     collection: string,
@@ -43,6 +45,52 @@ export class CollectionMutationResult extends CollectionResult {
     this.operation = operation;
   }
 }
+
+export class CollectionCountResult extends CollectionResult {
+  namespace: string;
+  count: i64;
+  exact: bool;
+
+  constructor(
+    collection: string,
+    namespace: string,
+    status: CollectionStatus,
+    error: string,
+    count: i64,
+    exact: bool,
+  ) {
+    super(collection, status, error);
+    this.namespace = namespace;
+    this.count = count;
+    this.exact = exact;
+  }
+}
+
+export class CollectionDuplicateObject {
+  key: string;
+  duplicateOf: string;
+  distance: f64;
+  policy: string;
+
+  constructor(key: string, duplicateOf: string, distance: f64, policy: string) {
+    this.key = key;
+    this.duplicateOf = duplicateOf;
+    this.distance = distance;
+    this.policy = policy;
+  }
+}
+
+export class CollectionPIIObject {
+  key: string;
+  types: string[];
+  policy: string;
+
+  constructor(key: string, types: string[], policy: string) {
+    this.key = key;
+    this.types = types;
+    this.policy = policy;
+  }
+}
 export class SearchMethodMutationResult extends CollectionResult {
   operation: string;
   searchMethod: string;
@@ -101,6 +149,50 @@ export class CollectionSearchResultObject {
   }
 }
 
+export class CollectionRagResult extends CollectionResult {
+  searchMethod: string;
+  answer: string;
+  citations: CollectionRagCitation[];
+  spans: CollectionRagSpan[] = [];
+
+  constructor(
+    collection: string,
+    status: CollectionStatus,
+    error: string,
+    searchMethod: string,
+    answer: string,
+    citations: CollectionRagCitation[],
+  ) {
+    super(collection, status, error);
+    this.searchMethod = searchMethod;
+    this.answer = answer;
+    this.citations = citations;
+  }
+}
+
+export class CollectionRagCitation {
+  namespace: string;
+  key: string;
+  score: f64;
+
+  constructor(namespace: string, key: string, score: f64) {
+    this.namespace = namespace;
+    this.key = key;
+    this.score = score;
+  }
+}
+
+/**
+ * A span of the answer of a RAG query that cites sources, with the citations of those sources.
+ * The offsets are in characters of the answer, and the span ends before its citation markers.
+ */
+export class CollectionRagSpan {
+  start: i32 = 0;
+  end: i32 = 0;
+  text!: string;
+  citations: CollectionRagCitation[] = [];
+}
+
 export class CollectionClassificationResult extends CollectionResult {
   searchMethod: string;
   labelsResult: CollectionClassificationLabelObject[];
@@ -155,6 +247,17 @@ declare function hostUpsert(
   labels: string[][],
 ): CollectionMutationResult;
 
+// @ts-expect-error: decorator
+@external("modus_collections", "upsertBlobs")
+declare function hostUpsertBlobs(
+  collection: string,
+  namespace: string,
+  keys: string[],
+  blobs: ArrayBuffer[],
+  mediaType: string,
+  labels: string[][],
+): CollectionMutationResult;
+
 // @ts-expect-error: decorator
 @external("modus_collections", "delete")
 declare function hostDelete(
@@ -163,6 +266,14 @@ declare function hostDelete(
   key: string,
 ): CollectionMutationResult;
 
+// @ts-expect-error: decorator
+@external("modus_collections", "restore")
+declare function hostRestore(
+  collection: string,
+  namespace: string,
+  key: string,
+): CollectionMutationResult;
+
 // @ts-expect-error: decorator
 @external("modus_collections", "search")
 declare function hostSearch(
@@ -174,6 +285,18 @@ declare function hostSearch(
   returnText: bool,
 ): CollectionSearchResult;
 
+// @ts-expect-error: decorator
+@external("modus_collections", "ragQuery")
+declare function hostRagQuery(
+  collection: string,
+  namespaces: string[],
+  searchMethod: string,
+  query: string,
+  k: i32,
+  promptTemplate: string,
+  model: string,
+): CollectionRagResult;
+
 // @ts-expect-error: decorator
 @external("modus_collections", "classifyText")
 declare function hostClassifyText(
@@ -220,6 +343,13 @@ declare function hostDumpTexts(
 @external("modus_collections", "getNamespaces")
 declare function hostGetNamespaces(collection: string): string[];
 
+// @ts-expect-error: decorator
+@external("modus_collections", "swapAlias")
+declare function hostSwapAlias(
+  alias: string,
+  collection: string,
+): string | null;
+
 // @ts-expect-error: decorator
 @external("modus_collections", "getVector")
 declare function hostGetVector(
@@ -237,6 +367,21 @@ declare function hostGetLabels(
   key: string,
 ): string[];
 
+// @ts-expect-error: decorator
+@external("modus_collections", "count")
+declare function hostCount(
+  collection: string,
+  namespace: string,
+  labels: string[],
+): CollectionCountResult;
+
+// @ts-expect-error: decorator
+@external("modus_collections", "countLabels")
+declare function hostCountLabels(
+  collection: string,
+  namespace: string,
+): CollectionCountResult;
+
 // @ts-expect-error: decorator
 @external("modus_collections", "searchByVector")
 declare function hostSearchByVector(
@@ -248,6 +393,28 @@ declare function hostSearchByVector(
   returnText: bool,
 ): CollectionSearchResult;
 
+// @ts-expect-error: decorator
+@external("modus_collections", "searchByImage")
+declare function hostSearchByImage(
+  collection: string,
+  namespaces: string[],
+  searchMethod: string,
+  image: ArrayBuffer,
+  limit: i32,
+  returnText: bool,
+): CollectionSearchResult;
+
+// @ts-expect-error: decorator
+@external("modus_collections", "searchByKey")
+declare function hostSearchByKey(
+  collection: string,
+  namespace: string,
+  searchMethod: string,
+  key: string,
+  limit: i32,
+  returnText: bool,
+): CollectionSearchResult;
+
 // add batch upsert
 export function upsertBatch(
   collection: string,
@@ -341,6 +508,68 @@ export function upsert(
   return result;
 }
 
+/**
+ * Inserts or updates binary items, such as images, in a collection.
+ * Each item is embedded by the image embedder of each search method of the collection.
+ *
+ * @param collection - The name of the collection.
+ * @param keys - The keys of the items, or null to generate them.
+ * @param blobs - The contents of the items.
+ * @param mediaType - The media type of the items, such as image/png.
+ * @param labelsArr - The labels of each of the items.
+ * @param namespace - The namespace of the items.
+ */
+export function upsertBlobs(
+  collection: string,
+  keys: string[] | null,
+  blobs: ArrayBuffer[],
+  mediaType: string,
+  labelsArr: string[][] = [],
+  namespace: string = "",
+): CollectionMutationResult {
+  if (collection.length == 0) {
+    console.error("Collection is empty.");
+    return new CollectionMutationResult(
+      collection,
+      CollectionStatus.Error,
+      "Collection is empty.",
+      "upsert",
+    );
+  }
+  if (blobs.length == 0) {
+    console.error("Blobs is empty.");
+    return new CollectionMutationResult(
+      collection,
+      CollectionStatus.Error,
+      "Blobs is empty.",
+      "upsert",
+    );
+  }
+  let keysArr: string[] = [];
+  if (keys != null) {
+    keysArr = keys;
+  }
+
+  const result = hostUpsertBlobs(
+    collection,
+    namespace,
+    keysArr,
+    blobs,
+    mediaType,
+    labelsArr,
+  );
+  if (utils.resultIsInvalid(result)) {
+    console.error("Error upserting blobs to collection.");
+    return new CollectionMutationResult(
+      collection,
+      CollectionStatus.Error,
+      "Error upserting blobs to collection.",
+      "upsert",
+    );
+  }
+  return result;
+}
+
 // remove data from in-mem storage and indexes
 export function remove(
   collection: string,
@@ -378,6 +607,43 @@ export function remove(
   return result;
 }
 
+// restore data that was removed from a collection configured for soft deletion
+export function restore(
+  collection: string,
+  key: string,
+  namespace: string = "",
+): CollectionMutationResult {
+  if (collection.length == 0) {
+    console.error("Collection is empty.");
+    return new CollectionMutationResult(
+      collection,
+      CollectionStatus.Error,
+      "Collection is empty.",
+      "restore",
+    );
+  }
+  if (key.length == 0) {
+    console.error("Key is empty.");
+    return new CollectionMutationResult(
+      collection,
+      CollectionStatus.Error,
+      "Key is empty.",
+      "restore",
+    );
+  }
+  const result = hostRestore(collection, namespace, key);
+  if (utils.resultIsInvalid(result)) {
+    console.error("Error restoring to Text index.");
+    return new CollectionMutationResult(
+      collection,
+      CollectionStatus.Error,
+      "Error restoring to Text index.",
+      "restore",
+    );
+  }
+  return result;
+}
+
 // fetch embedders for collection & search method, run text through it and
 // search Text index for similar Texts, return the result keys
 // open question: how do i return a more expansive result from string array
@@ -457,6 +723,165 @@ export function searchByVector(
   return result;
 }
 
+/**
+ * Answers a query with a model, from the items of a collection that are most similar to it.
+ * The texts of the items found are given to the model as numbered sources, as many as fit
+ * in the model's context window, and the answer is returned with a citation of each source.
+ * @param collection The name of the collection.
+ * @param searchMethod The search method used to find the items.
+ * @param query The query to answer.
+ * @param k The maximum number of items to retrieve.
+ * @param promptTemplate The template of the prompt, in which {{context}} is replaced by the
+ * sources and {{query}} by the query.  If empty, a default template is used that asks the
+ * model to cite its sources.
+ * @param model The name of the model, as defined in the manifest.  It must be served by an
+ * OpenAI-compatible chat completions endpoint.
+ * @param namespaces The namespaces to search, or all namespaces if empty.
+ * @returns The answer, the citations of the sources it was given, and the spans of the
+ * answer that cite them, parsed from the citation markers in the answer such as [1].
+ */
+export function ragQuery(
+  collection: string,
+  searchMethod: string,
+  query: string,
+  k: i32,
+  promptTemplate: string,
+  model: string,
+  namespaces: string[] = [],
+): CollectionRagResult {
+  if (query.length == 0) {
+    return new CollectionRagResult(
+      collection,
+      CollectionStatus.Error,
+      "Query is empty.",
+      searchMethod,
+      "",
+      [],
+    );
+  }
+  const result = hostRagQuery(
+    collection,
+    namespaces,
+    searchMethod,
+    query,
+    k,
+    promptTemplate,
+    model,
+  );
+  if (utils.resultIsInvalid(result)) {
+    console.error("Error answering query from collection.");
+    return new CollectionRagResult(
+      collection,
+      CollectionStatus.Error,
+      "Error answering query from collection.",
+      searchMethod,
+      "",
+      [],
+    );
+  }
+  return result;
+}
+
+/**
+ * Finds the items most similar to an image, which is embedded by the image embedder
+ * of the search method.  Both text and image items are matched, when the search method
+ * embeds them into the same vector space.
+ *
+ * @param collection - The name of the collection.
+ * @param searchMethod - The search method to search with.
+ * @param image - The contents of the query image.
+ * @param limit - The maximum number of items to return.
+ * @param returnText - Whether to return the texts of the items.
+ * @param namespaces - The namespaces to search, or all namespaces if empty.
+ */
+export function searchByImage(
+  collection: string,
+  searchMethod: string,
+  image: ArrayBuffer,
+  limit: i32,
+  returnText: bool = false,
+  namespaces: string[] = [],
+): CollectionSearchResult {
+  if (image.byteLength == 0) {
+    return new CollectionSearchResult(
+      collection,
+      CollectionStatus.Error,
+      "Image is empty.",
+      searchMethod,
+      [],
+    );
+  }
+  const result = hostSearchByImage(
+    collection,
+    namespaces,
+    searchMethod,
+    image,
+    limit,
+    returnText,
+  );
+  if (utils.resultIsInvalid(result)) {
+    console.error("Error searching collection by image.");
+    return new CollectionSearchResult(
+      collection,
+      CollectionStatus.Error,
+      "Error searching collection by image.",
+      searchMethod,
+      [],
+    );
+  }
+  return result;
+}
+
+/**
+ * Finds the items most similar to an existing item, using the item's stored
+ * vector as the query, so that its text is not embedded again. The item itself
+ * is excluded from the results.
+ *
+ * @param collection - The name of the collection.
+ * @param searchMethod - The search method whose vectors are compared.
+ * @param key - The key of the item to find similar items for.
+ * @param limit - The maximum number of items to return.
+ * @param returnText - Whether to return the texts of the items.
+ * @param namespace - The namespace of the item, and of the results.
+ */
+export function searchByKey(
+  collection: string,
+  searchMethod: string,
+  key: string,
+  limit: i32,
+  returnText: bool = false,
+  namespace: string = "",
+): CollectionSearchResult {
+  if (key.length == 0) {
+    return new CollectionSearchResult(
+      collection,
+      CollectionStatus.Error,
+      "Key is empty.",
+      searchMethod,
+      [],
+    );
+  }
+  const result = hostSearchByKey(
+    collection,
+    namespace,
+    searchMethod,
+    key,
+    limit,
+    returnText,
+  );
+  if (utils.resultIsInvalid(result)) {
+    console.error("Error searching Text index by key.");
+    return new CollectionSearchResult(
+      collection,
+      CollectionStatus.Error,
+      "Error searching Text index by key.",
+      searchMethod,
+      [],
+    );
+  }
+  return result;
+}
+
 // fetch embedders for collection & search method, run text through it and
 // classify Text index for similar Texts, return the result keys
 export function nnClassify(
@@ -603,6 +1028,35 @@ export function getNamespaces(collection: string): string[] {
   return hostGetNamespaces(collection);
 }
 
+/**
+ * Points the alias to the collection, creating the alias if it doesn't exist.
+ * The alias can then be used in place of the collection name in every collection
+ * function.  The swap is atomic, so a rebuilt collection can be promoted without
+ * clients noticing.  The collection must have every search method of the collection
+ * the alias pointed to.
+ *
+ * @param alias - The alias to point to the collection.
+ * @param collection - The name of the collection.
+ * @returns The collection the alias pointed to before, an empty string if the alias
+ * is new, or null if the alias could not be swapped.
+ */
+export function swapAlias(alias: string, collection: string): string | null {
+  if (alias.length == 0) {
+    console.error("Alias is empty.");
+    return null;
+  }
+  if (collection.length == 0) {
+    console.error("Collection is empty.");
+    return null;
+  }
+  const result = hostSwapAlias(alias, collection);
+  if (utils.resultIsInvalid(result)) {
+    console.error(`Error swapping alias ${alias} to collection ${collection}.`);
+    return null;
+  }
+  return result;
+}
+
 export function getVector(
   collection: string,
   searchMethod: string,
@@ -639,3 +1093,68 @@ export function getLabels(
   }
   return hostGetLabels(collection, namespace, key);
 }
+
+// count the items in a collection namespace that have all of the given labels,
+// exactly for small namespaces and estimated from a sample for larger ones
+export function count(
+  collection: string,
+  labels: string[] = [],
+  namespace: string = "",
+): CollectionCountResult {
+  if (collection.length == 0) {
+    console.error("Collection is empty.");
+    return new CollectionCountResult(
+      collection,
+      namespace,
+      CollectionStatus.Error,
+      "Collection is empty.",
+      0,
+      false,
+    );
+  }
+  const result = hostCount(collection, namespace, labels);
+  if (utils.resultIsInvalid(result)) {
+    console.error("Error counting items in collection.");
+    return new CollectionCountResult(
+      collection,
+      namespace,
+      CollectionStatus.Error,
+      "Error counting items in collection.",
+      0,
+      false,
+    );
+  }
+  return result;
+}
+
+// count the distinct labels in use in a collection namespace,
+// exactly for small namespaces and estimated with HyperLogLog for larger ones
+export function countLabels(
+  collection: string,
+  namespace: string = "",
+): CollectionCountResult {
+  if (collection.length == 0) {
+    console.error("Collection is empty.");
+    return new CollectionCountResult(
+      collection,
+      namespace,
+      CollectionStatus.Error,
+      "Collection is empty.",
+      0,
+      false,
+    );
+  }
+  const result = hostCountLabels(collection, namespace);
+  if (utils.resultIsInvalid(result)) {
+    console.error("Error counting labels in collection.");
+    return new CollectionCountResult(
+      collection,
+      namespace,
+      CollectionStatus.Error,
+      "Error counting labels in collection.",
+      0,
+      false,
+    );
+  }
+  return result;
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+/**
+ * Information about the current function execution.
+ */
+export class ExecutionInfo {
+  /**
+   * The unique identifier of the current execution.
+   * It can be used as a correlation id in outbound calls.
+   */
+  executionId!: string;
+
+  /**
+   * The name of the function that was called.
+   */
+  functionName!: string;
+
+  /**
+   * The name of the plugin that is running.
+   */
+  pluginName!: string;
+
+  /**
+   * The version of the plugin that is running.
+   */
+  pluginVersion!: string;
+
+  /**
+   * The build id of the plugin that is running.
+   */
+  buildId!: string;
+
+  /**
+   * The number of milliseconds remaining before the execution times out,
+   * or -1 if there is no time limit.
+   */
+  remainingTimeMs: i64 = -1;
+
+  /**
+   * Information about the caller, such as the request id and user agent,
+   * when it is available.
+   */
+  callerMetadata: Map<string, string> = new Map<string, string>();
+}
+
+// @ts-expect-error: decorator
+@external("modus_system", "getExecutionInfo")
+declare function hostGetExecutionInfo(): ExecutionInfo | null;
+
+/**
+ * Gets information about the current function execution.
+ */
+export function getInfo(): ExecutionInfo {
+  const info = hostGetExecutionInfo();
+  if (info === null) {
+    throw new Error("Failed to get execution info.");
+  }
+  return info;
+}
+
+// @ts-expect-error: decorator
+@external("modus_system", "isCancelled")
+declare function hostIsCancelled(): bool;
+
+// @ts-expect-error: decorator
+@external("modus_system", "getDeadlineRemaining")
+declare function hostGetDeadlineRemaining(): i64;
+
+/**
+ * Gets the number of milliseconds remaining before the current execution times out,
+ * or -1 if there is no time limit.
+ */
+export function remainingTimeMs(): i64 {
+  return hostGetDeadlineRemaining();
+}
+
+/**
+ * Determines whether the request that the current execution is serving has
+ * been cancelled, or has timed out.  Long-running loops should check it
+ * periodically, and return promptly when it is true.  A function that keeps
+ * running is terminated after a short grace period.
+ */
+export function isCancelled(): bool {
+  return hostIsCancelled();
+}
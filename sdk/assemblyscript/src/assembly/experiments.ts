@@ -0,0 +1,35 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// @ts-expect-error: decorator
+@external("modus_experiments", "recordFeedback")
+declare function hostRecordFeedback(experimentName: string, score: f64): bool;
+
+/**
+ * Records a score for the outcome of an experiment defined in the manifest.
+ * The score is attributed to the variant the current caller is assigned to,
+ * so that the variants can be compared.
+ *
+ * @param experimentName - The name of the experiment, as defined in the manifest.
+ * @param score - The score, between 0 and 1, where higher is better.
+ */
+export function recordFeedback(experimentName: string, score: f64): void {
+  if (experimentName.length == 0) {
+    throw new Error("Experiment name is required.");
+  }
+  if (score < 0 || score > 1) {
+    throw new Error("Score must be between 0 and 1.");
+  }
+
+  if (!hostRecordFeedback(experimentName, score)) {
+    throw new Error(
+      `Failed to record feedback for experiment ${experimentName}.`,
+    );
+  }
+}
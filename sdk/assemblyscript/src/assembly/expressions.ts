@@ -0,0 +1,49 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+import { JSON } from "json-as";
+import * as utils from "./utils";
+
+// @ts-expect-error: decorator
+@external("modus_expressions", "evalExpression")
+declare function hostEvalExpression(
+  expr: string,
+  contextJson: string,
+): string | null;
+
+/**
+ * Evaluates a CEL expression on the Modus host, using the same expression
+ * language as the conditions and policies declared in the manifest.
+ *
+ * @param expr - The expression to evaluate.
+ * @param contextJson - A JSON object whose fields are the variables available
+ * to the expression.
+ * @returns The result of the expression, deserialized as the type T.
+ */
+export function evaluate<T>(expr: string, contextJson: string = "{}"): T {
+  const response = hostEvalExpression(expr, contextJson);
+  if (utils.resultIsInvalid(response)) {
+    throw new Error(`Failed to evaluate expression: ${expr}`);
+  }
+
+  return JSON.parse<T>(response!);
+}
+
+/**
+ * Evaluates a CEL expression on the Modus host, with variables taken from
+ * the fields of the given context object.
+ *
+ * @param expr - The expression to evaluate.
+ * @param context - An object whose fields are the variables available to
+ * the expression.  It must be serializable to JSON.
+ * @returns The result of the expression, deserialized as the type T.
+ */
+export function evaluateWith<T, C>(expr: string, context: C): T {
+  return evaluate<T>(expr, JSON.stringify(context));
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// @ts-expect-error: decorator
+@external("modus_feedback", "recordFeedback")
+declare function hostRecordFeedback(
+  executionId: string,
+  rating: i32,
+  comment: string,
+): bool;
+
+/**
+ * Attaches a user's rating, and an optional comment, to a previous execution.
+ * The execution id is the one reported to the caller with each GraphQL field
+ * result.
+ *
+ * @param executionId - The id of the execution the feedback is about.
+ * @param rating - The rating, within the range configured in the manifest,
+ * which defaults to 1 to 5.
+ * @param comment - An optional comment from the user.
+ */
+export function record(
+  executionId: string,
+  rating: i32,
+  comment: string = "",
+): void {
+  if (executionId.length == 0) {
+    throw new Error("Execution id is required.");
+  }
+
+  if (!hostRecordFeedback(executionId, rating, comment)) {
+    throw new Error(`Failed to record feedback for execution ${executionId}.`);
+  }
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+import { JSON } from "json-as";
+import * as utils from "./utils";
+
+// @ts-expect-error: decorator
+@external("modus_hashing", "canonicalizeJson")
+declare function hostCanonicalizeJson(json: string): string | null;
+
+// @ts-expect-error: decorator
+@external("modus_hashing", "computeHash")
+declare function hostComputeHash(
+  algorithm: string,
+  data: ArrayBuffer,
+): ArrayBuffer | null;
+
+// @ts-expect-error: decorator
+@external("modus_hashing", "computeHmac")
+declare function hostComputeHmac(
+  algorithm: string,
+  key: ArrayBuffer,
+  data: ArrayBuffer,
+): ArrayBuffer | null;
+
+// @ts-expect-error: decorator
+@external("modus_hashing", "constantTimeEqual")
+declare function hostConstantTimeEqual(a: ArrayBuffer, b: ArrayBuffer): bool;
+
+/**
+ * Hash algorithms.  HMAC signatures can be computed with SHA256 or SHA512.
+ */
+export namespace Algorithm {
+  export const SHA256 = "sha256";
+  export const SHA512 = "sha512";
+  export const BLAKE3 = "blake3";
+}
+
+/**
+ * Computes the hash of the data on the Modus host.
+ *
+ * @param algorithm - The hash algorithm, one of the `Algorithm` values.
+ * @param data - The data to hash.
+ * @returns The hash.
+ */
+export function hash(algorithm: string, data: ArrayBuffer): ArrayBuffer {
+  const result = hostComputeHash(algorithm, data);
+  if (utils.resultIsInvalid(result)) {
+    throw new Error(`Failed to compute ${algorithm} hash.`);
+  }
+  return result!;
+}
+
+/**
+ * Computes the hash of a string, encoded as UTF-8.
+ *
+ * @param algorithm - The hash algorithm, one of the `Algorithm` values.
+ * @param text - The text to hash.
+ * @returns The hash, as a lowercase hexadecimal string.
+ */
+export function hashText(algorithm: string, text: string): string {
+  return toHex(hash(algorithm, String.UTF8.encode(text)));
+}
+
+/**
+ * Computes an HMAC signature of the data on the Modus host.
+ *
+ * @param algorithm - The hash algorithm, either `Algorithm.SHA256` or
+ * `Algorithm.SHA512`.
+ * @param key - The secret key.
+ * @param data - The data to sign.
+ * @returns The signature.
+ */
+export function hmac(
+  algorithm: string,
+  key: ArrayBuffer,
+  data: ArrayBuffer,
+): ArrayBuffer {
+  if (key.byteLength == 0) {
+    throw new Error("An HMAC key is required.");
+  }
+
+  const result = hostComputeHmac(algorithm, key, data);
+  if (utils.resultIsInvalid(result)) {
+    throw new Error(`Failed to compute ${algorithm} HMAC signature.`);
+  }
+  return result!;
+}
+
+/**
+ * Reports whether two values are equal, in a time that depends only on their
+ * lengths.  Use it to compare a signature to its expected value, so that the
+ * comparison doesn't reveal how much of it matched.
+ */
+export function constantTimeEqual(a: ArrayBuffer, b: ArrayBuffer): bool {
+  return hostConstantTimeEqual(a, b);
+}
+
+/**
+ * Returns the canonical form of a JSON document, as defined by the JSON
+ * Canonicalization Scheme (RFC 8785), so that equal values always serialize
+ * to the same string.
+ *
+ * @param json - The JSON document.
+ * @returns The canonical JSON.
+ */
+export function canonicalizeJson(json: string): string {
+  const result = hostCanonicalizeJson(json);
+  if (utils.resultIsInvalid(result)) {
+    throw new Error("Failed to canonicalize JSON.");
+  }
+  return result!;
+}
+
+/**
+ * Serializes a value to canonical JSON.
+ *
+ * @param value - The value, which must be serializable to JSON.
+ * @returns The canonical JSON.
+ */
+export function canonicalJson<T>(value: T): string {
+  return canonicalizeJson(JSON.stringify(value));
+}
+
+/**
+ * Encodes bytes as a lowercase hexadecimal string.
+ */
+export function toHex(data: ArrayBuffer): string {
+  const bytes = Uint8Array.wrap(data);
+  let result = "";
+  for (let i = 0; i < bytes.length; i++) {
+    result += bytes[i].toString(16).padStart(2, "0");
+  }
+  return result;
+}
@@ -69,6 +69,12 @@ export class Request {
    */
   readonly body: ArrayBuffer;
 
+  /**
+   * The maximum time to wait for the request to complete, in milliseconds.
+   * If zero, the request does not time out.
+   */
+  readonly timeoutMs: i64;
+
   /**
    * Creates a new `Request` instance.
    * @param url - The URL to send the request to.
@@ -79,6 +85,7 @@ export class Request {
     this.method = options.method ? options.method!.toUpperCase() : "GET";
     this.headers = options.headers;
     this.body = options.body ? options.body!.data : emptyArrayBuffer;
+    this.timeoutMs = options.timeoutMs;
   }
 
   /**
@@ -94,6 +101,7 @@ export class Request {
     const newOptions = {
       method: options.method || request.method,
       body: options.body || Content.from(request.body),
+      timeoutMs: options.timeoutMs || request.timeoutMs,
     } as RequestOptions;
 
     if (options.headers.entries().length > 0) {
@@ -142,6 +150,12 @@ export class RequestOptions {
    * The HTTP request body.
    */
   body: Content | null = null;
+
+  /**
+   * The maximum time to wait for the request to complete, in milliseconds.
+   * Default: 0 (no timeout)
+   */
+  timeoutMs: i64 = 0;
 }
 
 /**
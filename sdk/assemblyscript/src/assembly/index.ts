@@ -40,4 +40,37 @@ export { utils };
 import * as localtime from "./localtime";
 export { localtime };
 
+import * as prompts from "./prompts";
+export { prompts };
+
+import * as experiments from "./experiments";
+export { experiments };
+
+import * as feedback from "./feedback";
+export { feedback };
+
+import * as outbox from "./outbox";
+export { outbox };
+
+import * as expressions from "./expressions";
+export { expressions };
+
+import * as sessions from "./sessions";
+export { sessions };
+
+import * as execution from "./execution";
+export { execution };
+
+import * as progress from "./progress";
+export { progress };
+
+import * as metrics from "./metrics";
+export { metrics };
+
+import * as hashing from "./hashing";
+export { hashing };
+
+import * as text from "./text";
+export { text };
+
 export * from "./dynamicmap";
@@ -11,8 +11,48 @@
 @external("modus_system", "getTimeInZone")
 declare function hostGetTimeInZone(tz: string | null): string | null;
 
+// @ts-expect-error: decorator
+@external("modus_system", "parseTime")
+declare function hostParseTime(
+  value: string,
+  format: string,
+  tz: string,
+): string | null;
+
+// @ts-expect-error: decorator
+@external("modus_system", "formatTime")
+declare function hostFormatTime(
+  timestamp: string,
+  format: string,
+  tz: string,
+): string | null;
+
+// @ts-expect-error: decorator
+@external("modus_system", "addDuration")
+declare function hostAddDuration(
+  timestamp: string,
+  duration: string,
+  tz: string,
+): string | null;
+
+// @ts-expect-error: decorator
+@external("modus_system", "formatRelativeTime")
+declare function hostFormatRelativeTime(
+  timestamp: string,
+  reference: string,
+): string | null;
+
+// @ts-expect-error: decorator
+@external("modus_system", "getNextCronTime")
+declare function hostGetNextCronTime(
+  expression: string,
+  after: string,
+  tz: string,
+): string | null;
+
 // TODO: Find or create a library for AssemblyScript that can handle time zones,
 //       and then expose modus_system.getTimeZoneData for that library.
+//       Until then, time zone aware parsing, formatting and arithmetic are done by the host.
 
 /**
  * Returns the current local time, in ISO 8601 extended format.
@@ -54,3 +94,141 @@ export function NowInZone(tz: string): string {
 export function GetTimeZone(): string {
   return process.env.get("TZ");
 }
+
+/**
+ * Parses a time with a strftime format, such as "%Y-%m-%d %H:%M", using the
+ * time zone database of the host.  The supported directives are %Y, %y, %m,
+ * %d, %e, %j, %H, %I, %M, %S, %f, %p, %a, %A, %b, %B, %Z, %z, %:z and %%.
+ *
+ * @param value - The time to parse.
+ * @param format - The strftime format of the value.  If empty, the value is
+ * parsed as an ISO 8601 timestamp.
+ * @param tz - The IANA time zone in which to interpret a value without an
+ * offset.  If empty, the local time zone is used.
+ * @returns The time, in ISO 8601 extended format.
+ */
+export function ParseTime(
+  value: string,
+  format: string,
+  tz: string = "",
+): string {
+  const ts = hostParseTime(value, format, tz);
+  if (ts === null) {
+    throw new Error(`Failed to parse time: ${value}`);
+  }
+  return ts;
+}
+
+/**
+ * Formats a time with a strftime format, such as "%A, %B %e at %I:%M %p",
+ * in a time zone, using the time zone database of the host.
+ *
+ * @param timestamp - The time to format, in ISO 8601 extended format.
+ * @param format - The strftime format.  See `ParseTime` for the supported
+ * directives.
+ * @param tz - The IANA time zone to format the time in.  If empty, the local
+ * time zone is used.
+ */
+export function FormatTime(
+  timestamp: string,
+  format: string,
+  tz: string = "",
+): string {
+  const result = hostFormatTime(timestamp, format, tz);
+  if (result === null) {
+    throw new Error(`Failed to format time: ${timestamp}`);
+  }
+  return result;
+}
+
+/**
+ * Converts a time to a time zone.
+ *
+ * @param timestamp - The time to convert, in ISO 8601 extended format.
+ * @param tz - A valid IANA time zone identifier, such as "America/New_York".
+ * @returns The time in the time zone, in ISO 8601 extended format.
+ */
+export function ConvertTime(timestamp: string, tz: string): string {
+  if (tz === "") {
+    throw new Error("A time zone is required.");
+  }
+  return FormatTime(timestamp, "", tz);
+}
+
+/**
+ * Adds a duration to a time.  Years, months, weeks and days are added to the
+ * calendar date in the time zone, so that adding a day keeps the same wall
+ * clock time across a daylight saving time change.
+ *
+ * @param timestamp - The time, in ISO 8601 extended format.
+ * @param duration - An ISO 8601 duration, such as "P1DT12H", or a duration
+ * such as "1h30m".  It may be negative, such as "-P1D".
+ * @param tz - The IANA time zone of the calendar.  If empty, the local time
+ * zone is used.
+ * @returns The resulting time, in ISO 8601 extended format.
+ */
+export function AddDuration(
+  timestamp: string,
+  duration: string,
+  tz: string = "",
+): string {
+  const result = hostAddDuration(timestamp, duration, tz);
+  if (result === null) {
+    throw new Error(`Failed to add duration: ${duration}`);
+  }
+  return result;
+}
+
+/**
+ * Describes a time relative to a reference time, such as "3 hours ago" or
+ * "in 2 days".
+ *
+ * @param timestamp - The time, in ISO 8601 extended format.
+ * @param reference - The reference time, in ISO 8601 extended format.
+ * If empty, the current time is used.
+ */
+export function FormatRelativeTime(
+  timestamp: string,
+  reference: string = "",
+): string {
+  if (reference === "") {
+    reference = NowInZone("UTC");
+  }
+
+  const result = hostFormatRelativeTime(timestamp, reference);
+  if (result === null) {
+    throw new Error(`Failed to format relative time: ${timestamp}`);
+  }
+  return result;
+}
+
+/**
+ * Returns the next time that matches a cron expression, with the standard
+ * five fields of minute, hour, day of month, month and day of week, such as
+ * "0 9 * * MON-FRI".  The @yearly, @monthly, @weekly, @daily and @hourly
+ * shorthands are also accepted.
+ *
+ * @param expression - The cron expression.
+ * @param after - The time after which to find the next match, in ISO 8601
+ * extended format.  If empty, the current time is used.
+ * @param tz - The IANA time zone to evaluate the expression in.  If empty,
+ * the local time zone is used.
+ * @returns The next matching time, in ISO 8601 extended format.
+ */
+export function NextCronTime(
+  expression: string,
+  after: string = "",
+  tz: string = "",
+): string {
+  if (after === "") {
+    after = NowInZone("UTC");
+  }
+
+  const result = hostGetNextCronTime(expression, after, tz);
+  if (result === null) {
+    throw new Error(
+      `Failed to compute the next time for cron expression: ${expression}`,
+    );
+  }
+  return result;
+}
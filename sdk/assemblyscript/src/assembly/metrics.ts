@@ -0,0 +1,83 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// @ts-expect-error: decorator
+@external("modus_metrics", "recordMetric")
+declare function hostRecordMetric(
+  name: string,
+  kind: string,
+  value: f64,
+  tags: Map<string, string>,
+): bool;
+
+/**
+ * Adds a value to a counter.  The runtime exposes the counter as
+ * plugin_{name}, labeled with the plugin name and the given tags.
+ *
+ * @param name - The name of the counter.
+ * @param value - The amount to add, which must not be negative.
+ * @param tags - Tags to label the value with.  A metric must always be
+ * recorded with the same tag names.
+ */
+export function incrementCounter(
+  name: string,
+  value: f64 = 1,
+  tags: Map<string, string> = new Map<string, string>(),
+): void {
+  record(name, "counter", value, tags);
+}
+
+/**
+ * Sets a gauge to a value.  The runtime exposes the gauge as
+ * plugin_{name}, labeled with the plugin name and the given tags.
+ *
+ * @param name - The name of the gauge.
+ * @param value - The value to set.
+ * @param tags - Tags to label the value with.  A metric must always be
+ * recorded with the same tag names.
+ */
+export function setGauge(
+  name: string,
+  value: f64,
+  tags: Map<string, string> = new Map<string, string>(),
+): void {
+  record(name, "gauge", value, tags);
+}
+
+/**
+ * Records a value in a histogram.  The runtime exposes the histogram as
+ * plugin_{name}, labeled with the plugin name and the given tags.
+ *
+ * @param name - The name of the histogram.
+ * @param value - The value to observe.
+ * @param tags - Tags to label the value with.  A metric must always be
+ * recorded with the same tag names.
+ */
+export function observeHistogram(
+  name: string,
+  value: f64,
+  tags: Map<string, string> = new Map<string, string>(),
+): void {
+  record(name, "histogram", value, tags);
+}
+
+function record(
+  name: string,
+  kind: string,
+  value: f64,
+  tags: Map<string, string>,
+): void {
+  if (name.length == 0) {
+    throw new Error("Metric name is required.");
+  }
+
+  if (!hostRecordMetric(name, kind, value, tags)) {
+    throw new Error(`Failed to record metric ${name}.`);
+  }
+}
@@ -23,6 +23,18 @@ declare function hostInvokeModel(
   input: string,
 ): string | null;
 
+// @ts-expect-error: decorator
+@external("modus_models", "countTokens")
+declare function hostCountTokens(modelName: string, text: string): i32;
+
+// @ts-expect-error: decorator
+@external("modus_models", "transcribeAudio")
+declare function hostTranscribeAudio(
+  modelName: string,
+  audio: ArrayBuffer,
+  options: TranscriptionOptions,
+): Transcription | null;
+
 class ModusModelFactory implements ModelFactory {
   constructor() {
     // Note, we assign this to a static property on the base Model class so that it can be accessed
@@ -45,6 +57,51 @@ class ModusModelFactory implements ModelFactory {
 
     return instantiate<T>(info);
   }
+
+  /**
+   * Counts the tokens that the text is encoded as by the model's tokenizer.
+   * The tokenizer is set in the manifest, or is inferred for known OpenAI models.
+   * @param modelName The name of the model, as defined in the manifest.
+   * @param text The text to count the tokens of.
+   * @returns The number of tokens.
+   */
+  countTokens(modelName: string, text: string): i32 {
+    if (text.length == 0) {
+      return 0;
+    }
+
+    const count = hostCountTokens(modelName, text);
+    if (count == 0) {
+      throw new Error(`Failed to count tokens for model ${modelName}.`);
+    }
+    return count;
+  }
+
+  /**
+   * Transcribes audio with a speech-to-text model, such as a Whisper model.
+   * The audio is sent to the model by the Modus host, and its format
+   * (wav, mp3, flac, ogg, webm or m4a) is detected from its content.
+   * @param modelName The name of the model, as defined in the manifest.
+   * @param audio The content of the audio file.
+   * @param options The optional settings of the transcription.
+   * @returns The transcription of the audio.
+   */
+  transcribeAudio(
+    modelName: string,
+    audio: ArrayBuffer,
+    options: TranscriptionOptions | null = null,
+  ): Transcription {
+    if (audio.byteLength == 0) {
+      throw new Error("Audio is empty.");
+    }
+
+    const opts = options ? options : new TranscriptionOptions();
+    const result = hostTranscribeAudio(modelName, audio, opts);
+    if (utils.resultIsInvalid(result)) {
+      throw new Error(`Failed to transcribe audio with model ${modelName}.`);
+    }
+    return result!;
+  }
 }
 
 export class ModelInfo {
@@ -54,8 +111,68 @@ export class ModelInfo {
   ) {}
 }
 
+/**
+ * The optional settings of an audio transcription.
+ */
+export class TranscriptionOptions {
+  /**
+   * The ISO-639-1 code of the language spoken in the audio.  It is detected if not given.
+   */
+  language: string = "";
+
+  /**
+   * Text that guides the style of the transcript, or continues a previous segment of audio.
+   */
+  prompt: string = "";
+
+  /**
+   * The sampling temperature, between 0 and 1.
+   */
+  temperature: f64 = 0;
+}
+
+/**
+ * The text of an audio transcription, along with its timed segments.
+ */
+export class Transcription {
+  /**
+   * The full text of the transcription.
+   */
+  text!: string;
+
+  /**
+   * The language of the audio, as reported by the model.
+   */
+  language!: string;
+
+  /**
+   * The duration of the audio, in seconds.
+   */
+  duration: f64 = 0;
+
+  /**
+   * The segments of the transcription, in order.
+   */
+  segments: TranscriptionSegment[] = [];
+}
+
+/**
+ * A segment of a transcription, with its start and end in seconds from the start of the audio.
+ */
+export class TranscriptionSegment {
+  start: f64 = 0;
+  end: f64 = 0;
+  text!: string;
+}
+
 export interface ModelFactory {
   getModel<T extends Model>(modelName: string): T;
+  countTokens(modelName: string, text: string): i32;
+  transcribeAudio(
+    modelName: string,
+    audio: ArrayBuffer,
+    options: TranscriptionOptions | null,
+  ): Transcription;
 }
 
 export abstract class Model<TInput = unknown, TOutput = unknown> {
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+import { Request, RequestOptions } from "./http";
+
+// @ts-expect-error: decorator
+@external("modus_outbox", "stage")
+declare function hostStage(request: Request): bool;
+
+/**
+ * Stages an HTTP request in the outbox of the current function invocation.
+ * The request is sent by the Modus runtime after the function completes
+ * successfully, and is retried if it fails. If the function throws an error,
+ * the request is discarded.
+ *
+ * @param requestOrUrl - Either a `Request` instance or a URL string.
+ * @param options - Optional request options, applied to the request.
+ */
+export function stage<T>(
+  requestOrUrl: T,
+  options: RequestOptions = new RequestOptions(),
+): void {
+  let request: Request;
+  if (isString<T>()) {
+    const url = changetype<string>(requestOrUrl);
+    request = new Request(url, options);
+  } else if (idof<T>() == idof<Request>()) {
+    const r = changetype<Request>(requestOrUrl);
+    request = Request.clone(r, options);
+  } else {
+    throw new Error("Unsupported request type.");
+  }
+
+  if (!hostStage(request)) {
+    throw new Error(
+      "Failed to stage outbox message. Check the logs for more information.",
+    );
+  }
+}
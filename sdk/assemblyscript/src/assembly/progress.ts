@@ -0,0 +1,33 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// @ts-expect-error: decorator
+@external("modus_progress", "reportProgress")
+declare function hostReportProgress(percent: f64, message: string): bool;
+
+/**
+ * Emits a progress event for the current function execution, which is
+ * streamed to clients subscribed at the runtime's /progress/{id} endpoint,
+ * with either the execution id or the request id the caller gave in the
+ * X-Request-Id header.
+ *
+ * @param percent - The percentage complete, between 0 and 100.
+ * @param message - An optional message describing the current step.
+ */
+export function report(percent: f64, message: string = ""): void {
+  if (percent < 0 || percent > 100) {
+    throw new Error(
+      `Progress percentage ${percent} is out of range, must be between 0 and 100.`,
+    );
+  }
+
+  if (!hostReportProgress(percent, message)) {
+    throw new Error("Failed to report progress.");
+  }
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// @ts-expect-error: decorator
+@external("modus_prompts", "renderPrompt")
+declare function hostRenderPrompt(
+  name: string,
+  version: string,
+  variables: Map<string, string>,
+): string | null;
+
+/**
+ * Renders a prompt template defined in the manifest, substituting the provided variables.
+ *
+ * @param name - The name of the prompt, as defined in the manifest.
+ * @param variables - The values of the variables referenced by the template.
+ * @param version - The version of the prompt to render.  If empty, the default version is used.
+ * @returns The rendered prompt text.
+ */
+export function render(
+  name: string,
+  variables: Map<string, string> = new Map<string, string>(),
+  version: string = "",
+): string {
+  if (name.length == 0) {
+    throw new Error("Prompt name is required.");
+  }
+
+  const result = hostRenderPrompt(name, version, variables);
+  if (result === null) {
+    throw new Error(`Failed to render prompt ${name}.`);
+  }
+
+  return result;
+}
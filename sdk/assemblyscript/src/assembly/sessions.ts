@@ -0,0 +1,78 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+/**
+ * A single entry in a session's history.
+ */
+export class Message {
+  constructor(
+    public role: string,
+    public content: string,
+  ) {}
+}
+
+// @ts-expect-error: decorator
+@external("modus_sessions", "append")
+declare function hostAppend(sessionId: string, messages: Message[]): bool;
+
+// @ts-expect-error: decorator
+@external("modus_sessions", "get")
+declare function hostGet(sessionId: string, maxTokens: i32): Message[] | null;
+
+// @ts-expect-error: decorator
+@external("modus_sessions", "clear")
+declare function hostClear(sessionId: string): bool;
+
+/**
+ * Adds messages to the end of a session's history, creating the session if needed.
+ *
+ * @param sessionId - The identifier of the session.
+ * @param messages - The messages to append.
+ */
+export function append(sessionId: string, messages: Message[]): void {
+  if (sessionId.length == 0) {
+    throw new Error("Session id is required.");
+  }
+  if (!hostAppend(sessionId, messages)) {
+    throw new Error(`Failed to append to session ${sessionId}.`);
+  }
+}
+
+/**
+ * Gets a session's history, oldest message first.
+ *
+ * @param sessionId - The identifier of the session.
+ * @param maxTokens - If greater than zero, only the most recent messages that fit
+ * within this (estimated) number of tokens are returned.
+ * @returns The messages in the session.
+ */
+export function get(sessionId: string, maxTokens: i32 = 0): Message[] {
+  if (sessionId.length == 0) {
+    throw new Error("Session id is required.");
+  }
+  const messages = hostGet(sessionId, maxTokens);
+  if (messages === null) {
+    throw new Error(`Failed to get session ${sessionId}.`);
+  }
+  return messages;
+}
+
+/**
+ * Removes a session and all of its history.
+ *
+ * @param sessionId - The identifier of the session.
+ */
+export function clear(sessionId: string): void {
+  if (sessionId.length == 0) {
+    throw new Error("Session id is required.");
+  }
+  if (!hostClear(sessionId)) {
+    throw new Error(`Failed to clear session ${sessionId}.`);
+  }
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+import * as utils from "./utils";
+
+// @ts-expect-error: decorator
+@external("modus_text", "segmentText")
+declare function hostSegmentText(
+  text: string,
+  granularity: string,
+): string[] | null;
+
+/**
+ * The granularities that text can be segmented at.
+ */
+export namespace Granularity {
+  export const Word = "word";
+  export const Sentence = "sentence";
+  export const Paragraph = "paragraph";
+}
+
+/**
+ * Splits the text into segments on the Modus host, following the Unicode text segmentation rules.
+ * Surrounding whitespace is removed from each segment, and empty segments are omitted.
+ * Word segments include only words and numbers, not punctuation or whitespace.
+ *
+ * @param text - The text to segment.
+ * @param granularity - The granularity, one of the `Granularity` values.
+ * @returns The segments of the text.
+ */
+export function segment(text: string, granularity: string): string[] {
+  const result = hostSegmentText(text, granularity);
+  if (utils.resultIsInvalid(result)) {
+    throw new Error(`Failed to segment text into ${granularity} segments.`);
+  }
+  return result!;
+}
+
+/**
+ * Returns the words and numbers of the text.
+ */
+export function words(text: string): string[] {
+  return segment(text, Granularity.Word);
+}
+
+/**
+ * Returns the sentences of the text.
+ */
+export function sentences(text: string): string[] {
+  return segment(text, Granularity.Sentence);
+}
+
+/**
+ * Returns the paragraphs of the text, which are separated by blank lines.
+ */
+export function paragraphs(text: string): string[] {
+  return segment(text, Granularity.Paragraph);
+}
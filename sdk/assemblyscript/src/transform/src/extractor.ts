@@ -61,6 +61,21 @@ export class Extractor {
     this.module = module;
   }
 
+  // A plugin declares that its instances can be restored from a snapshot of an instance
+  // that has already run its top-level code, by including a "//modus:snapshot" comment
+  // in its entry file.  This is only valid if the top-level code has no side effects that
+  // must happen for every instance, such as seeding a random number generator.
+  //
+  // Every restored instance starts with the memory of the instance that the snapshot was captured from.
+  // Math.random is seeded from the host the first time it is called, so if the top-level code calls it,
+  // it returns the same sequence in every restored instance.  The caller's JWT claims are not in the
+  // environment of these plugins, and are read from the host on every call instead.
+  hasSnapshotDirective(): boolean {
+    return this.program.sources
+      .filter((v) => v.sourceKind == SourceKind.UserEntry)
+      .some((v) => /^\s*\/\/modus:snapshot\s*$/m.test(v.text));
+  }
+
   getProgramInfo(): ProgramInfo {
     const exportedFunctions = this.getExportedFunctions()
       .map((e) => this.convertToFunctionSignature(e))
@@ -261,6 +276,12 @@ export class Extractor {
           const start = nodeIndex > 0 ? prevNode.range.end : 0;
           const end = node.range.start;
 
+          // A function declares that it resolves a field for a list of parent objects in one call,
+          // and returns a list with one result for each parent, with a "//modus:batch" comment.
+          signature.batch = /^\s*\/\/modus:batch\s*$/m.test(
+            source.text.slice(start, end),
+          );
+
           const newRange = new Range(start, end);
           newRange.source = source;
           const commentNodes = this.parseComments(newRange);
@@ -27,6 +27,9 @@ export default class ModusTransform extends Transform {
     m.addExportFn(info.exportFns);
     m.addImportFn(info.importFns);
     m.addTypes(info.types);
+    if (this.extractor.hasSnapshotDirective()) {
+      m.snapshot = true;
+    }
     m.writeToModule(module);
     m.logResults();
   }
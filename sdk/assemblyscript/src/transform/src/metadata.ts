@@ -18,6 +18,10 @@ import { FunctionSignature, TypeDefinition } from "./types.js";
 
 const METADATA_VERSION = 2;
 
+// The version of the runtime host function API that this SDK's imports are built against.
+// This must match the HostApiVersion of the runtime.
+const HOST_API_VERSION = 1;
+
 export class Metadata {
   public plugin: string;
   public module: string;
@@ -26,6 +30,8 @@ export class Metadata {
   public buildTs: string;
   public gitRepo?: string;
   public gitCommit?: string;
+  public hostApiVersion: number = HOST_API_VERSION;
+  public snapshot?: boolean;
   public fnExports: { [key: string]: FunctionSignature } = {};
   public fnImports: { [key: string]: FunctionSignature } = {};
   public types: { [key: string]: TypeDefinition } = {};
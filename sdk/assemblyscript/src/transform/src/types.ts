@@ -41,6 +41,9 @@ export class Docs {
 }
 
 export class FunctionSignature {
+  // whether the function resolves a field for a list of parent objects in one call
+  public batch = false;
+
   constructor(
     public name: string,
     public parameters: Parameter[],
@@ -81,6 +84,10 @@ export class FunctionSignature {
       output["docs"] = this.docs;
     }
 
+    if (this.batch) {
+      output["batch"] = true;
+    }
+
     return output;
   }
 }
@@ -11,18 +11,18 @@ package auth
 
 import (
 	"errors"
-	"os"
 
 	"github.com/hypermodeinc/modus/sdk/go/pkg/utils"
 )
 
+// GetJWTClaims returns the claims of the token that the caller of the current function was authenticated with.
 func GetJWTClaims[T any]() (T, error) {
 	var claims T
-	claimsStr := os.Getenv("CLAIMS")
-	if claimsStr == "" {
+	claimsStr := hostGetJWTClaims()
+	if claimsStr == nil || *claimsStr == "" {
 		return claims, errors.New("JWT claims not found")
 	}
-	err := utils.JsonDeserialize([]byte(claimsStr), &claims)
+	err := utils.JsonDeserialize([]byte(*claimsStr), &claims)
 	if err != nil {
 		return claims, err
 	}
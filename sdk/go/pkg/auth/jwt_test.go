@@ -0,0 +1,40 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package auth_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/auth"
+)
+
+func TestGetJWTClaims(t *testing.T) {
+	type claims struct {
+		Sub   string   `json:"sub"`
+		Roles []string `json:"roles"`
+	}
+
+	c, err := auth.GetJWTClaims[claims]()
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+
+	if c.Sub != "mock-user" {
+		t.Errorf("Expected sub: mock-user, but received: %s", c.Sub)
+	}
+	if !slices.Equal(c.Roles, []string{"reader"}) {
+		t.Errorf("Expected roles: [reader], but received: %v", c.Roles)
+	}
+
+	if auth.GetJWTClaimsCallStack.Size() == 0 {
+		t.Error("Expected a call to the host function")
+	}
+}
@@ -26,6 +26,21 @@ type CollectionMutationResult struct {
 	Error      string
 	Operation  string
 	Keys       []string
+	Duplicates []*CollectionDuplicateObject
+	PII        []*CollectionPIIObject
+}
+
+type CollectionDuplicateObject struct {
+	Key         string
+	DuplicateOf string
+	Distance    float64
+	Policy      string
+}
+
+type CollectionPIIObject struct {
+	Key    string
+	Types  []string
+	Policy string
 }
 
 type SearchMethodMutationResult struct {
@@ -74,6 +89,40 @@ type CollectionClassificationResultObject struct {
 	Score    float64
 }
 
+type CollectionRagResult struct {
+	Collection   string
+	Status       string
+	Error        string
+	SearchMethod string
+	Answer       string
+	Citations    []*CollectionRagCitation
+	Spans        []*CollectionRagSpan
+}
+
+type CollectionRagCitation struct {
+	Namespace string
+	Key       string
+	Score     float64
+}
+
+// A span of the answer of a RAG query that cites sources, with the citations of those sources.
+// The offsets are in characters of the answer, and the span ends before its citation markers.
+type CollectionRagSpan struct {
+	Start     int32
+	End       int32
+	Text      string
+	Citations []*CollectionRagCitation
+}
+
+type CollectionCountResult struct {
+	Collection string
+	Namespace  string
+	Status     string
+	Error      string
+	Count      int64
+	Exact      bool
+}
+
 type NamespaceOption func(*NamespaceOptions)
 
 type NamespaceOptions struct {
@@ -120,6 +169,47 @@ func UpsertBatch(collection string, keys []string, texts []string, labelsArr [][
 	return result, nil
 }
 
+// UpsertBlobs inserts or updates binary items, such as images, in a collection.  The media type declares
+// the modality of the items, and must be an image type such as image/png.  The items are embedded by the
+// image embedder of each search method, and their stored text is a data URI holding the media type and content.
+func UpsertBlobs(collection string, keys []string, blobs [][]byte, mediaType string, labelsArr [][]string, opts ...NamespaceOption) (*CollectionMutationResult, error) {
+	if collection == "" {
+		return nil, fmt.Errorf("Collection name is required")
+	}
+
+	if len(blobs) == 0 {
+		return nil, fmt.Errorf("Blobs is empty")
+	}
+
+	if mediaType == "" {
+		return nil, fmt.Errorf("Media type is required")
+	}
+
+	nsOpts := &NamespaceOptions{
+		namespace: "",
+	}
+
+	for _, opt := range opts {
+		opt(nsOpts)
+	}
+
+	if keys == nil {
+		keys = []string{}
+	}
+
+	if labelsArr == nil {
+		labelsArr = [][]string{}
+	}
+
+	result := hostUpsertBlobs(&collection, &nsOpts.namespace, &keys, &blobs, &mediaType, &labelsArr)
+
+	if result == nil {
+		return nil, fmt.Errorf("Failed to upsert blobs")
+	}
+
+	return result, nil
+}
+
 func Upsert(collection string, key *string, text string, labels []string, opts ...NamespaceOption) (*CollectionMutationResult, error) {
 	if collection == "" {
 		return nil, fmt.Errorf("Collection name is required")
@@ -184,6 +274,34 @@ func Remove(collection, key string, opts ...NamespaceOption) (*CollectionMutatio
 	return result, nil
 }
 
+// Restore restores an item that was removed from a collection configured for soft deletion,
+// provided the retention period for deleted items has not passed.
+func Restore(collection, key string, opts ...NamespaceOption) (*CollectionMutationResult, error) {
+	if collection == "" {
+		return nil, fmt.Errorf("Collection name is required")
+	}
+
+	if key == "" {
+		return nil, fmt.Errorf("Key is required")
+	}
+
+	nsOpts := &NamespaceOptions{
+		namespace: "",
+	}
+
+	for _, opt := range opts {
+		opt(nsOpts)
+	}
+
+	result := hostRestore(&collection, &nsOpts.namespace, &key)
+
+	if result == nil {
+		return nil, fmt.Errorf("Failed to restore")
+	}
+
+	return result, nil
+}
+
 type SearchOption func(*SearchOptions)
 
 type SearchOptions struct {
@@ -204,6 +322,8 @@ func WithLimit(limit int) SearchOption {
 	}
 }
 
+// WithReturnText sets whether the texts of the items found are included in the result.
+// The labels of the items are always included.
 func WithReturnText(returnText bool) SearchOption {
 	return func(o *SearchOptions) {
 		o.returnText = returnText
@@ -242,6 +362,60 @@ func Search(collection, searchMethod, text string, opts ...SearchOption) (*Colle
 	return result, nil
 }
 
+type RagQueryOption func(*RagQueryOptions)
+
+type RagQueryOptions struct {
+	namespaces []string
+}
+
+// WithSourceNamespaces sets the namespaces that the sources of a RAG query are retrieved from.
+func WithSourceNamespaces(namespaces []string) RagQueryOption {
+	return func(o *RagQueryOptions) {
+		o.namespaces = namespaces
+	}
+}
+
+// Answers a query with a model, from the items of the collection that the search method finds most similar to it.
+// At most k items are retrieved, and the texts of as many as fit in the model's context window are given to the
+// model as numbered sources.  In the prompt template, {{context}} is replaced by the sources and {{query}} by the
+// query.  If the template is empty, a default template is used that asks the model to cite its sources.
+// The model must be served by an OpenAI-compatible chat completions endpoint.  The result has the answer, a citation
+// of each source that was given to the model, and the spans of the answer that cite sources by markers such as [1].
+// The items are retrieved from all namespaces, unless others are set with WithSourceNamespaces.
+func RagQuery(collection, searchMethod, query string, k int, promptTemplate, model string, opts ...RagQueryOption) (*CollectionRagResult, error) {
+	if collection == "" {
+		return nil, fmt.Errorf("Collection name is required")
+	}
+
+	if searchMethod == "" {
+		return nil, fmt.Errorf("Search method is required")
+	}
+
+	if query == "" {
+		return nil, fmt.Errorf("Query is required")
+	}
+
+	if model == "" {
+		return nil, fmt.Errorf("Model name is required")
+	}
+
+	rOpts := &RagQueryOptions{
+		namespaces: []string{},
+	}
+
+	for _, opt := range opts {
+		opt(rOpts)
+	}
+
+	result := hostRagQuery(&collection, &rOpts.namespaces, &searchMethod, &query, int32(k), &promptTemplate, &model)
+
+	if result == nil {
+		return nil, fmt.Errorf("Failed to answer query")
+	}
+
+	return result, nil
+}
+
 func SearchByVector(collection, searchMethod string, vector []float32, opts ...SearchOption) (*CollectionSearchResult, error) {
 	if collection == "" {
 		return nil, fmt.Errorf("Collection name is required")
@@ -280,6 +454,84 @@ func SearchByVector(collection, searchMethod string, vector []float32, opts ...S
 	return result, nil
 }
 
+// SearchByImage finds the items most similar to an image, which is embedded by the search method's image embedder.
+func SearchByImage(collection, searchMethod string, image []byte, opts ...SearchOption) (*CollectionSearchResult, error) {
+	if collection == "" {
+		return nil, fmt.Errorf("Collection name is required")
+	}
+
+	if searchMethod == "" {
+		return nil, fmt.Errorf("Search method is required")
+	}
+
+	if len(image) == 0 {
+		return nil, fmt.Errorf("Image is required")
+	}
+
+	sOpts := &SearchOptions{
+		namespaces: []string{},
+		limit:      10,
+		returnText: false,
+	}
+
+	for _, opt := range opts {
+		opt(sOpts)
+	}
+
+	result := hostSearchByImage(&collection, &sOpts.namespaces, &searchMethod, &image, int32(sOpts.limit), sOpts.returnText)
+
+	if result == nil {
+		return nil, fmt.Errorf("Failed to search by image")
+	}
+
+	return result, nil
+}
+
+// SearchByKey finds the items most similar to an existing item, using the item's stored vector
+// as the query, so that its text is not embedded again.  The item itself is excluded from the results.
+// The item and the results are in the namespace given with WithNamespaces, which accepts at most one
+// namespace here, or in the default namespace.
+func SearchByKey(collection, searchMethod, key string, opts ...SearchOption) (*CollectionSearchResult, error) {
+	if collection == "" {
+		return nil, fmt.Errorf("Collection name is required")
+	}
+
+	if searchMethod == "" {
+		return nil, fmt.Errorf("Search method is required")
+	}
+
+	if key == "" {
+		return nil, fmt.Errorf("Key is required")
+	}
+
+	sOpts := &SearchOptions{
+		namespaces: []string{},
+		limit:      10,
+		returnText: false,
+	}
+
+	for _, opt := range opts {
+		opt(sOpts)
+	}
+
+	var namespace string
+	switch len(sOpts.namespaces) {
+	case 0:
+	case 1:
+		namespace = sOpts.namespaces[0]
+	default:
+		return nil, fmt.Errorf("Only one namespace can be searched by key")
+	}
+
+	result := hostSearchByKey(&collection, &namespace, &searchMethod, &key, int32(sOpts.limit), sOpts.returnText)
+
+	if result == nil {
+		return nil, fmt.Errorf("Failed to search")
+	}
+
+	return result, nil
+}
+
 func NnClassify(collection, searchMethod, text string, opts ...NamespaceOption) (*CollectionClassificationResult, error) {
 	if collection == "" {
 		return nil, fmt.Errorf("Collection name is required")
@@ -487,3 +739,79 @@ func GetLabels(collection, key string, opts ...NamespaceOption) ([]string, error
 
 	return *result, nil
 }
+
+// Count returns the number of items in a collection namespace that have all of the given labels.
+// Pass no labels to count every item. The count is exact for small namespaces, and estimated
+// from a sample for larger ones; the Exact field of the result indicates which.
+func Count(collection string, labels []string, opts ...NamespaceOption) (*CollectionCountResult, error) {
+	if collection == "" {
+		return nil, fmt.Errorf("Collection name is required")
+	}
+
+	if labels == nil {
+		labels = []string{}
+	}
+
+	nsOpts := &NamespaceOptions{
+		namespace: "",
+	}
+
+	for _, opt := range opts {
+		opt(nsOpts)
+	}
+
+	result := hostCount(&collection, &nsOpts.namespace, &labels)
+
+	if result == nil {
+		return nil, fmt.Errorf("Failed to count items")
+	}
+
+	return result, nil
+}
+
+// CountLabels returns the number of distinct labels in use in a collection namespace.
+// The count is exact for small namespaces, and a HyperLogLog estimate for larger ones;
+// the Exact field of the result indicates which.
+func CountLabels(collection string, opts ...NamespaceOption) (*CollectionCountResult, error) {
+	if collection == "" {
+		return nil, fmt.Errorf("Collection name is required")
+	}
+
+	nsOpts := &NamespaceOptions{
+		namespace: "",
+	}
+
+	for _, opt := range opts {
+		opt(nsOpts)
+	}
+
+	result := hostCountLabels(&collection, &nsOpts.namespace)
+
+	if result == nil {
+		return nil, fmt.Errorf("Failed to count labels")
+	}
+
+	return result, nil
+}
+
+// Points the alias to the collection, creating the alias if it doesn't exist, and returns the collection
+// it pointed to before, or an empty string.  The alias can then be used in place of the collection name
+// in every collection function.  The swap is atomic, so a rebuilt collection can be promoted without
+// clients noticing.  The collection must have every search method of the collection the alias pointed to.
+func SwapAlias(alias, collection string) (string, error) {
+	if alias == "" {
+		return "", fmt.Errorf("Alias is required")
+	}
+
+	if collection == "" {
+		return "", fmt.Errorf("Collection name is required")
+	}
+
+	result := hostSwapAlias(&alias, &collection)
+
+	if result == nil {
+		return "", fmt.Errorf("Failed to swap alias %s to collection %s", alias, collection)
+	}
+
+	return *result, nil
+}
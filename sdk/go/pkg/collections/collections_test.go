@@ -30,6 +30,8 @@ var (
 	textArr      = []string{"text"}
 	labels       = []string{"label"}
 	labelsArr    = [][]string{{"label"}}
+	image        = []byte{0x89, 'P', 'N', 'G'}
+	mediaType    = "image/png"
 )
 
 func TestHostUpsertBatchToCollection(t *testing.T) {
@@ -70,6 +72,41 @@ func TestHostUpsertBatchToCollection(t *testing.T) {
 	}
 }
 
+func TestHostUpsertBlobsToCollection(t *testing.T) {
+	blobs := [][]byte{image}
+	result, err := collections.UpsertBlobs(collection, keyArr, blobs, mediaType, labelsArr, collections.WithNamespace(namespace))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if result == nil {
+		t.Fatal("Expected a result, but none was found.")
+	}
+
+	values := collections.UpsertBlobsCallStack.Pop()
+	if values == nil {
+		t.Error("Expected a request, but none was found.")
+	} else {
+		if !reflect.DeepEqual(&collection, values[0]) {
+			t.Errorf("Expected collection: %v, but received: %v", &collection, values[0])
+		}
+		if !reflect.DeepEqual(&namespace, values[1]) {
+			t.Errorf("Expected namespace: %v, but received: %v", &namespace, values[1])
+		}
+		if !reflect.DeepEqual(&keyArr, values[2]) {
+			t.Errorf("Expected keys: %v, but received: %v", &keyArr, values[2])
+		}
+		if !reflect.DeepEqual(&blobs, values[3]) {
+			t.Errorf("Expected blobs: %v, but received: %v", &blobs, values[3])
+		}
+		if !reflect.DeepEqual(&mediaType, values[4]) {
+			t.Errorf("Expected media type: %v, but received: %v", &mediaType, values[4])
+		}
+		if !reflect.DeepEqual(&labelsArr, values[5]) {
+			t.Errorf("Expected labels: %v, but received: %v", &labelsArr, values[5])
+		}
+	}
+}
+
 func TestHostUpsertToCollection(t *testing.T) {
 	result, err := collections.Upsert(collection, nil, text, labels, collections.WithNamespace(namespace))
 	if err != nil {
@@ -140,6 +177,35 @@ func TestHostRemoveFromCollection(t *testing.T) {
 	}
 }
 
+func TestHostRestoreToCollection(t *testing.T) {
+	result, err := collections.Restore(collection, key, collections.WithNamespace(namespace))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := &collections.CollectionMutationResult{
+		Collection: "collection",
+		Status:     "success",
+	}
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("Expected result: %v, but received: %v", expected, result)
+	}
+
+	values := collections.RestoreCallStack.Pop()
+	if values == nil {
+		t.Error("Expected a request, but none was found.")
+	} else {
+		if !reflect.DeepEqual(&collection, values[0]) {
+			t.Errorf("Expected collection: %v, but received: %v", &collection, values[0])
+		}
+		if !reflect.DeepEqual(&namespace, values[1]) {
+			t.Errorf("Expected namespace: %v, but received: %v", &namespace, values[1])
+		}
+		if !reflect.DeepEqual(&key, values[2]) {
+			t.Errorf("Expected key: %v, but received: %v", &key, values[2])
+		}
+	}
+}
+
 func TestHostSearchCollection(t *testing.T) {
 	result, err := collections.Search(collection, searchMethod, text, collections.WithNamespaces([]string{namespace}), collections.WithLimit(1), collections.WithReturnText(true))
 	if err != nil {
@@ -181,6 +247,59 @@ func TestHostSearchCollection(t *testing.T) {
 	}
 }
 
+func TestHostRagQuery(t *testing.T) {
+	query := "What is the answer?"
+	k := 5
+	promptTemplate := "Sources: {{context}}\nQuestion: {{query}}"
+	model := "text-generator"
+	namespaces := []string{namespace}
+
+	result, err := collections.RagQuery(collection, searchMethod, query, k, promptTemplate, model, collections.WithSourceNamespaces(namespaces))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if result == nil {
+		t.Fatal("Expected a result, but none was found.")
+	}
+	if len(result.Citations) != 1 || result.Citations[0].Key != "key1" {
+		t.Errorf("Expected a citation of key1, but received: %v", result.Citations)
+	}
+	if len(result.Spans) != 1 || result.Spans[0].Citations[0].Key != "key1" {
+		t.Errorf("Expected a span citing key1, but received: %v", result.Spans)
+	}
+
+	values := collections.RagQueryCallStack.Pop()
+	if values == nil {
+		t.Error("Expected a request, but none was found.")
+	} else {
+		if !reflect.DeepEqual(&collection, values[0]) {
+			t.Errorf("Expected collection: %v, but received: %v", &collection, values[0])
+		}
+		if !reflect.DeepEqual(&namespaces, values[1]) {
+			t.Errorf("Expected namespaces: %v, but received: %v", &namespaces, values[1])
+		}
+		if !reflect.DeepEqual(&searchMethod, values[2]) {
+			t.Errorf("Expected search method: %v, but received: %v", &searchMethod, values[2])
+		}
+		if !reflect.DeepEqual(&query, values[3]) {
+			t.Errorf("Expected query: %v, but received: %v", &query, values[3])
+		}
+		if !reflect.DeepEqual(int32(k), values[4]) {
+			t.Errorf("Expected k: %v, but received: %v", k, values[4])
+		}
+		if !reflect.DeepEqual(&promptTemplate, values[5]) {
+			t.Errorf("Expected prompt template: %v, but received: %v", &promptTemplate, values[5])
+		}
+		if !reflect.DeepEqual(&model, values[6]) {
+			t.Errorf("Expected model: %v, but received: %v", &model, values[6])
+		}
+	}
+
+	if _, err := collections.RagQuery(collection, searchMethod, query, k, promptTemplate, ""); err == nil {
+		t.Error("Expected an error when the model name is missing.")
+	}
+}
+
 func TestHostNnClassifyCollection(t *testing.T) {
 	result, err := collections.NnClassify(collection, searchMethod, text, collections.WithNamespace(namespace))
 	if err != nil {
@@ -493,3 +612,154 @@ func TestHostSearchByVector(t *testing.T) {
 		}
 	}
 }
+
+func TestHostSearchByImage(t *testing.T) {
+	result, err := collections.SearchByImage(collection, searchMethod, image, collections.WithNamespaces([]string{namespace}), collections.WithLimit(1))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if result == nil {
+		t.Fatal("Expected a result, but none was found.")
+	}
+
+	values := collections.SearchByImageCallStack.Pop()
+	if values == nil {
+		t.Error("Expected a request, but none was found.")
+	} else {
+		if !reflect.DeepEqual(&collection, values[0]) {
+			t.Errorf("Expected collection: %v, but received: %v", &collection, values[0])
+		}
+		if !reflect.DeepEqual(&[]string{namespace}, values[1]) {
+			t.Errorf("Expected namespaces: %v, but received: %v", &[]string{namespace}, values[1])
+		}
+		if !reflect.DeepEqual(&searchMethod, values[2]) {
+			t.Errorf("Expected searchMethod: %v, but received: %v", &searchMethod, values[2])
+		}
+		if !reflect.DeepEqual(&image, values[3]) {
+			t.Errorf("Expected image: %v, but received: %v", &image, values[3])
+		}
+		if !reflect.DeepEqual(int32(1), values[4]) {
+			t.Errorf("Expected limit: %v, but received: %v", int32(1), values[4])
+		}
+		if !reflect.DeepEqual(false, values[5]) {
+			t.Errorf("Expected returnText: %v, but received: %v", false, values[5])
+		}
+	}
+}
+
+func TestHostSearchByKey(t *testing.T) {
+	result, err := collections.SearchByKey(collection, searchMethod, key, collections.WithNamespaces([]string{namespace}), collections.WithLimit(5))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if result == nil {
+		t.Fatal("Expected a result, but none was found.")
+	}
+
+	values := collections.SearchByKeyCallStack.Pop()
+	if values == nil {
+		t.Error("Expected a request, but none was found.")
+	} else {
+		if !reflect.DeepEqual(&collection, values[0]) {
+			t.Errorf("Expected collection: %v, but received: %v", &collection, values[0])
+		}
+		if !reflect.DeepEqual(&namespace, values[1]) {
+			t.Errorf("Expected namespace: %v, but received: %v", &namespace, values[1])
+		}
+		if !reflect.DeepEqual(&searchMethod, values[2]) {
+			t.Errorf("Expected searchMethod: %v, but received: %v", &searchMethod, values[2])
+		}
+		if !reflect.DeepEqual(&key, values[3]) {
+			t.Errorf("Expected key: %v, but received: %v", &key, values[3])
+		}
+		if !reflect.DeepEqual(int32(5), values[4]) {
+			t.Errorf("Expected limit: %v, but received: %v", int32(5), values[4])
+		}
+		if !reflect.DeepEqual(false, values[5]) {
+			t.Errorf("Expected returnText: %v, but received: %v", false, values[5])
+		}
+	}
+
+	_, err = collections.SearchByKey(collection, searchMethod, key, collections.WithNamespaces([]string{"a", "b"}))
+	if err == nil {
+		t.Error("Expected an error for multiple namespaces, but received none")
+	}
+}
+
+func TestHostCount(t *testing.T) {
+	labels := []string{"label1"}
+	result, err := collections.Count(collection, labels, collections.WithNamespace(namespace))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if result == nil {
+		t.Fatal("Expected a result, but none was found.")
+	}
+	if result.Count != 42 || !result.Exact {
+		t.Errorf("Expected an exact count of 42, but received: %v (exact: %v)", result.Count, result.Exact)
+	}
+
+	values := collections.CountCallStack.Pop()
+	if values == nil {
+		t.Error("Expected a request, but none was found.")
+	} else {
+		if !reflect.DeepEqual(&collection, values[0]) {
+			t.Errorf("Expected collection: %v, but received: %v", &collection, values[0])
+		}
+		if !reflect.DeepEqual(&namespace, values[1]) {
+			t.Errorf("Expected namespace: %v, but received: %v", &namespace, values[1])
+		}
+		if !reflect.DeepEqual(&labels, values[2]) {
+			t.Errorf("Expected labels: %v, but received: %v", &labels, values[2])
+		}
+	}
+}
+
+func TestHostCountLabels(t *testing.T) {
+	result, err := collections.CountLabels(collection, collections.WithNamespace(namespace))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if result == nil {
+		t.Fatal("Expected a result, but none was found.")
+	}
+	if result.Count != 3 {
+		t.Errorf("Expected a count of 3, but received: %v", result.Count)
+	}
+
+	values := collections.CountLabelsCallStack.Pop()
+	if values == nil {
+		t.Error("Expected a request, but none was found.")
+	} else {
+		if !reflect.DeepEqual(&collection, values[0]) {
+			t.Errorf("Expected collection: %v, but received: %v", &collection, values[0])
+		}
+		if !reflect.DeepEqual(&namespace, values[1]) {
+			t.Errorf("Expected namespace: %v, but received: %v", &namespace, values[1])
+		}
+	}
+}
+
+func TestHostSwapAlias(t *testing.T) {
+	alias := "collection-live"
+
+	result, err := collections.SwapAlias(alias, collection)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if result != "previous-collection" {
+		t.Errorf("Expected result: %v, but received: %v", "previous-collection", result)
+	}
+
+	values := collections.SwapAliasCallStack.Pop()
+	if values == nil {
+		t.Error("Expected a request, but none was found.")
+	} else {
+		if !reflect.DeepEqual(&alias, values[0]) {
+			t.Errorf("Expected alias: %v, but received: %v", &alias, values[0])
+		}
+		if !reflect.DeepEqual(&collection, values[1]) {
+			t.Errorf("Expected collection: %v, but received: %v", &collection, values[1])
+		}
+	}
+}
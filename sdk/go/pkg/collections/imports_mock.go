@@ -14,8 +14,11 @@ package collections
 import "github.com/hypermodeinc/modus/sdk/go/pkg/testutils"
 
 var UpsertCallStack = testutils.NewCallStack()
+var UpsertBlobsCallStack = testutils.NewCallStack()
 var DeleteCallStack = testutils.NewCallStack()
+var RestoreCallStack = testutils.NewCallStack()
 var SearchCallStack = testutils.NewCallStack()
+var RagQueryCallStack = testutils.NewCallStack()
 var NnClassifyCallStack = testutils.NewCallStack()
 var RecomputeSearchMethodCallStack = testutils.NewCallStack()
 var ComputeDistanceCallStack = testutils.NewCallStack()
@@ -25,6 +28,11 @@ var GetNamespacesCallStack = testutils.NewCallStack()
 var GetVectorCallStack = testutils.NewCallStack()
 var GetLabelsCallStack = testutils.NewCallStack()
 var SearchByVectorCallStack = testutils.NewCallStack()
+var SearchByImageCallStack = testutils.NewCallStack()
+var SearchByKeyCallStack = testutils.NewCallStack()
+var CountCallStack = testutils.NewCallStack()
+var CountLabelsCallStack = testutils.NewCallStack()
+var SwapAliasCallStack = testutils.NewCallStack()
 
 func hostUpsert(collection, namespace *string, keys, texts *[]string, labels *[][]string) *CollectionMutationResult {
 	UpsertCallStack.Push(collection, namespace, keys, texts, labels)
@@ -35,6 +43,15 @@ func hostUpsert(collection, namespace *string, keys, texts *[]string, labels *[]
 	}
 }
 
+func hostUpsertBlobs(collection, namespace *string, keys *[]string, blobs *[][]byte, mediaType *string, labels *[][]string) *CollectionMutationResult {
+	UpsertBlobsCallStack.Push(collection, namespace, keys, blobs, mediaType, labels)
+
+	return &CollectionMutationResult{
+		Collection: *collection,
+		Status:     "success",
+	}
+}
+
 func hostDelete(collection, namespace, key *string) *CollectionMutationResult {
 	DeleteCallStack.Push(collection, namespace, key)
 
@@ -44,6 +61,15 @@ func hostDelete(collection, namespace, key *string) *CollectionMutationResult {
 	}
 }
 
+func hostRestore(collection, namespace, key *string) *CollectionMutationResult {
+	RestoreCallStack.Push(collection, namespace, key)
+
+	return &CollectionMutationResult{
+		Collection: *collection,
+		Status:     "success",
+	}
+}
+
 func hostSearch(collection *string, namespaces *[]string, searchMethod, text *string, limit int32, returnText bool) *CollectionSearchResult {
 	SearchCallStack.Push(collection, namespaces, searchMethod, text, limit, returnText)
 
@@ -53,6 +79,20 @@ func hostSearch(collection *string, namespaces *[]string, searchMethod, text *st
 	}
 }
 
+func hostRagQuery(collection *string, namespaces *[]string, searchMethod, query *string, k int32, promptTemplate, model *string) *CollectionRagResult {
+	RagQueryCallStack.Push(collection, namespaces, searchMethod, query, k, promptTemplate, model)
+
+	citation := &CollectionRagCitation{Key: "key1", Score: 0.9}
+	return &CollectionRagResult{
+		Collection:   *collection,
+		Status:       "success",
+		SearchMethod: *searchMethod,
+		Answer:       "answer [1]",
+		Citations:    []*CollectionRagCitation{citation},
+		Spans:        []*CollectionRagSpan{{Start: 0, End: 6, Text: "answer", Citations: []*CollectionRagCitation{citation}}},
+	}
+}
+
 func hostClassifyText(collection, namespace, searchMethod, text *string) *CollectionClassificationResult {
 	NnClassifyCallStack.Push(collection, namespace, searchMethod, text)
 
@@ -130,3 +170,52 @@ func hostSearchByVector(collection *string, namespaces *[]string, searchMethod *
 		Status:     "success",
 	}
 }
+
+func hostSearchByImage(collection *string, namespaces *[]string, searchMethod *string, image *[]byte, limit int32, returnText bool) *CollectionSearchResult {
+	SearchByImageCallStack.Push(collection, namespaces, searchMethod, image, limit, returnText)
+
+	return &CollectionSearchResult{
+		Collection: *collection,
+		Status:     "success",
+	}
+}
+
+func hostSearchByKey(collection, namespace, searchMethod, key *string, limit int32, returnText bool) *CollectionSearchResult {
+	SearchByKeyCallStack.Push(collection, namespace, searchMethod, key, limit, returnText)
+
+	return &CollectionSearchResult{
+		Collection: *collection,
+		Status:     "success",
+	}
+}
+
+func hostCount(collection, namespace *string, labels *[]string) *CollectionCountResult {
+	CountCallStack.Push(collection, namespace, labels)
+
+	return &CollectionCountResult{
+		Collection: *collection,
+		Namespace:  *namespace,
+		Status:     "success",
+		Count:      42,
+		Exact:      true,
+	}
+}
+
+func hostCountLabels(collection, namespace *string) *CollectionCountResult {
+	CountLabelsCallStack.Push(collection, namespace)
+
+	return &CollectionCountResult{
+		Collection: *collection,
+		Namespace:  *namespace,
+		Status:     "success",
+		Count:      3,
+		Exact:      true,
+	}
+}
+
+func hostSwapAlias(alias, collection *string) *string {
+	SwapAliasCallStack.Push(alias, collection)
+
+	previous := "previous-collection"
+	return &previous
+}
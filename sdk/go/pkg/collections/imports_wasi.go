@@ -29,6 +29,22 @@ func hostUpsert(collection, namespace *string, keys, texts *[]string, labels *[]
 	return (*CollectionMutationResult)(response)
 }
 
+//go:noescape
+//go:wasmimport modus_collections upsertBlobs
+func _hostUpsertBlobs(collection, namespace *string, keys, blobs unsafe.Pointer, mediaType *string, labels unsafe.Pointer) unsafe.Pointer
+
+//modus:import modus_collections upsertBlobs
+func hostUpsertBlobs(collection, namespace *string, keys *[]string, blobs *[][]byte, mediaType *string, labels *[][]string) *CollectionMutationResult {
+	keysPointer := unsafe.Pointer(keys)
+	blobsPointer := unsafe.Pointer(blobs)
+	labelsPointer := unsafe.Pointer(labels)
+	response := _hostUpsertBlobs(collection, namespace, keysPointer, blobsPointer, mediaType, labelsPointer)
+	if response == nil {
+		return nil
+	}
+	return (*CollectionMutationResult)(response)
+}
+
 //go:noescape
 //go:wasmimport modus_collections delete
 func _hostDelete(collection, namespace, key *string) unsafe.Pointer
@@ -42,6 +58,19 @@ func hostDelete(collection, namespace, key *string) *CollectionMutationResult {
 	return (*CollectionMutationResult)(response)
 }
 
+//go:noescape
+//go:wasmimport modus_collections restore
+func _hostRestore(collection, namespace, key *string) unsafe.Pointer
+
+//modus:import modus_collections restore
+func hostRestore(collection, namespace, key *string) *CollectionMutationResult {
+	response := _hostRestore(collection, namespace, key)
+	if response == nil {
+		return nil
+	}
+	return (*CollectionMutationResult)(response)
+}
+
 //go:noescape
 //go:wasmimport modus_collections search
 func _hostSearch(collection *string, namespaces unsafe.Pointer, searchMethod, text *string, limit int32, returnText bool) unsafe.Pointer
@@ -56,6 +85,20 @@ func hostSearch(collection *string, namespaces *[]string, searchMethod, text *st
 	return (*CollectionSearchResult)(response)
 }
 
+//go:noescape
+//go:wasmimport modus_collections ragQuery
+func _hostRagQuery(collection *string, namespaces unsafe.Pointer, searchMethod, query *string, k int32, promptTemplate, model *string) unsafe.Pointer
+
+//modus:import modus_collections ragQuery
+func hostRagQuery(collection *string, namespaces *[]string, searchMethod, query *string, k int32, promptTemplate, model *string) *CollectionRagResult {
+	namespacesPtr := unsafe.Pointer(namespaces)
+	response := _hostRagQuery(collection, namespacesPtr, searchMethod, query, k, promptTemplate, model)
+	if response == nil {
+		return nil
+	}
+	return (*CollectionRagResult)(response)
+}
+
 //go:noescape
 //go:wasmimport modus_collections classifyText
 func _hostClassifyText(collection, namespace, searchMethod, text *string) unsafe.Pointer
@@ -174,3 +217,71 @@ func hostSearchByVector(collection *string, namespaces *[]string, searchMethod *
 	}
 	return (*CollectionSearchResult)(response)
 }
+
+//go:noescape
+//go:wasmimport modus_collections searchByImage
+func _hostSearchByImage(collection *string, namespaces unsafe.Pointer, searchMethod *string, image unsafe.Pointer, limit int32, returnText bool) unsafe.Pointer
+
+//modus:import modus_collections searchByImage
+func hostSearchByImage(collection *string, namespaces *[]string, searchMethod *string, image *[]byte, limit int32, returnText bool) *CollectionSearchResult {
+	namespacesPtr := unsafe.Pointer(namespaces)
+	imagePtr := unsafe.Pointer(image)
+	response := _hostSearchByImage(collection, namespacesPtr, searchMethod, imagePtr, limit, returnText)
+	if response == nil {
+		return nil
+	}
+	return (*CollectionSearchResult)(response)
+}
+
+//go:noescape
+//go:wasmimport modus_collections searchByKey
+func _hostSearchByKey(collection, namespace, searchMethod, key *string, limit int32, returnText bool) unsafe.Pointer
+
+//modus:import modus_collections searchByKey
+func hostSearchByKey(collection, namespace, searchMethod, key *string, limit int32, returnText bool) *CollectionSearchResult {
+	response := _hostSearchByKey(collection, namespace, searchMethod, key, limit, returnText)
+	if response == nil {
+		return nil
+	}
+	return (*CollectionSearchResult)(response)
+}
+
+//go:noescape
+//go:wasmimport modus_collections count
+func _hostCount(collection, namespace *string, labels unsafe.Pointer) unsafe.Pointer
+
+//modus:import modus_collections count
+func hostCount(collection, namespace *string, labels *[]string) *CollectionCountResult {
+	labelsPtr := unsafe.Pointer(labels)
+	response := _hostCount(collection, namespace, labelsPtr)
+	if response == nil {
+		return nil
+	}
+	return (*CollectionCountResult)(response)
+}
+
+//go:noescape
+//go:wasmimport modus_collections countLabels
+func _hostCountLabels(collection, namespace *string) unsafe.Pointer
+
+//modus:import modus_collections countLabels
+func hostCountLabels(collection, namespace *string) *CollectionCountResult {
+	response := _hostCountLabels(collection, namespace)
+	if response == nil {
+		return nil
+	}
+	return (*CollectionCountResult)(response)
+}
+
+//go:noescape
+//go:wasmimport modus_collections swapAlias
+func _hostSwapAlias(alias, collection *string) unsafe.Pointer
+
+//modus:import modus_collections swapAlias
+func hostSwapAlias(alias, collection *string) *string {
+	response := _hostSwapAlias(alias, collection)
+	if response == nil {
+		return nil
+	}
+	return (*string)(response)
+}
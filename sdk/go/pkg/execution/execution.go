@@ -0,0 +1,64 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package execution
+
+import (
+	"errors"
+	"time"
+)
+
+// Info describes the current function execution.
+type Info struct {
+	// ExecutionId is the unique identifier of the current execution.
+	// It can be used as a correlation id in outbound calls.
+	ExecutionId string
+
+	// FunctionName is the name of the function that was called.
+	FunctionName string
+
+	// PluginName, PluginVersion and BuildId identify the plugin that is running.
+	PluginName    string
+	PluginVersion string
+	BuildId       string
+
+	// RemainingTimeMs is the number of milliseconds remaining before the execution
+	// times out, or -1 if there is no time limit.
+	RemainingTimeMs int64
+
+	// CallerMetadata contains information about the caller, such as the request id
+	// and user agent, when it is available.
+	CallerMetadata map[string]string
+}
+
+// GetInfo returns information about the current function execution.
+func GetInfo() (*Info, error) {
+	info := hostGetExecutionInfo()
+	if info == nil {
+		return nil, errors.New("failed to get execution info")
+	}
+	return info, nil
+}
+
+// RemainingTime returns the time remaining before the current execution times out.
+// The second return value is false if there is no time limit.
+func RemainingTime() (time.Duration, bool) {
+	ms := hostGetDeadlineRemaining()
+	if ms < 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// IsCancelled reports whether the request that the current execution is serving has been cancelled, or has timed out.
+// Long-running loops should check it periodically, and return promptly when it is true.  A function that keeps
+// running is terminated after a short grace period.
+func IsCancelled() bool {
+	return hostIsCancelled()
+}
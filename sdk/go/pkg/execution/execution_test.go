@@ -0,0 +1,59 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package execution_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/execution"
+)
+
+func TestGetInfo(t *testing.T) {
+	info, err := execution.GetInfo()
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+
+	if info.ExecutionId != "mock-execution-id" {
+		t.Errorf("Expected execution id: mock-execution-id, but received: %s", info.ExecutionId)
+	}
+	if info.CallerMetadata["request_id"] != "mock-request-id" {
+		t.Errorf("Expected request id: mock-request-id, but received: %s", info.CallerMetadata["request_id"])
+	}
+
+	if execution.GetExecutionInfoCallStack.Size() == 0 {
+		t.Error("Expected a call to the host function")
+	}
+}
+
+func TestRemainingTime(t *testing.T) {
+	remaining, ok := execution.RemainingTime()
+	if !ok {
+		t.Fatal("Expected a time limit")
+	}
+	if remaining != 1500*time.Millisecond {
+		t.Errorf("Expected remaining time: 1.5s, but received: %s", remaining)
+	}
+
+	if execution.GetDeadlineRemainingCallStack.Size() == 0 {
+		t.Error("Expected a call to the host function")
+	}
+}
+
+func TestIsCancelled(t *testing.T) {
+	if execution.IsCancelled() {
+		t.Error("Expected the execution not to be cancelled")
+	}
+
+	if execution.IsCancelledCallStack.Size() == 0 {
+		t.Error("Expected a call to the host function")
+	}
+}
@@ -0,0 +1,39 @@
+//go:build !wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package execution
+
+import "github.com/hypermodeinc/modus/sdk/go/pkg/testutils"
+
+var GetExecutionInfoCallStack = testutils.NewCallStack()
+var IsCancelledCallStack = testutils.NewCallStack()
+var GetDeadlineRemainingCallStack = testutils.NewCallStack()
+
+func hostGetExecutionInfo() *Info {
+	GetExecutionInfoCallStack.Push()
+
+	return &Info{
+		ExecutionId:     "mock-execution-id",
+		FunctionName:    "mockFunction",
+		RemainingTimeMs: 1500,
+		CallerMetadata:  map[string]string{"request_id": "mock-request-id"},
+	}
+}
+
+func hostIsCancelled() bool {
+	IsCancelledCallStack.Push()
+	return false
+}
+
+func hostGetDeadlineRemaining() int64 {
+	GetDeadlineRemainingCallStack.Push()
+	return 1500
+}
@@ -0,0 +1,45 @@
+//go:build wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package execution
+
+import "unsafe"
+
+//go:noescape
+//go:wasmimport modus_system getExecutionInfo
+func _hostGetExecutionInfo() unsafe.Pointer
+
+//modus:import modus_system getExecutionInfo
+func hostGetExecutionInfo() *Info {
+	info := _hostGetExecutionInfo()
+	if info == nil {
+		return nil
+	}
+	return (*Info)(info)
+}
+
+//go:noescape
+//go:wasmimport modus_system isCancelled
+func _hostIsCancelled() bool
+
+//modus:import modus_system isCancelled
+func hostIsCancelled() bool {
+	return _hostIsCancelled()
+}
+
+//go:noescape
+//go:wasmimport modus_system getDeadlineRemaining
+func _hostGetDeadlineRemaining() int64
+
+//modus:import modus_system getDeadlineRemaining
+func hostGetDeadlineRemaining() int64 {
+	return _hostGetDeadlineRemaining()
+}
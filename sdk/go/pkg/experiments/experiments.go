@@ -0,0 +1,32 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package experiments
+
+import (
+	"fmt"
+)
+
+// RecordFeedback records a score for the outcome of the experiment with the given name.
+// The score is attributed to the variant the current caller is assigned to, so that the
+// variants can be compared.  Scores should be between 0 and 1, where higher is better.
+func RecordFeedback(experimentName string, score float64) error {
+	if experimentName == "" {
+		return fmt.Errorf("experiment name is required")
+	}
+	if score < 0 || score > 1 {
+		return fmt.Errorf("score must be between 0 and 1")
+	}
+
+	if !hostRecordFeedback(&experimentName, score) {
+		return fmt.Errorf("failed to record feedback for experiment %s", experimentName)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package experiments_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/experiments"
+)
+
+func TestRecordFeedback(t *testing.T) {
+	name := "prompt-test"
+
+	if err := experiments.RecordFeedback(name, 0.75); err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+
+	values := experiments.RecordFeedbackCallStack.Pop()
+	if len(values) != 2 {
+		t.Fatalf("Expected 2 values, but received %d", len(values))
+	}
+	if !reflect.DeepEqual(values[0], &name) {
+		t.Errorf("Expected experiment name: %s, but received: %s", name, values[0])
+	}
+	if values[1] != 0.75 {
+		t.Errorf("Expected score: 0.75, but received: %v", values[1])
+	}
+}
+
+func TestRecordFeedbackInvalid(t *testing.T) {
+	if err := experiments.RecordFeedback("", 0.5); err == nil {
+		t.Error("Expected an error for a missing name, but received none")
+	}
+	if err := experiments.RecordFeedback("prompt-test", 1.5); err == nil {
+		t.Error("Expected an error for an out of range score, but received none")
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package experiments
+
+import "github.com/hypermodeinc/modus/sdk/go/pkg/testutils"
+
+var RecordFeedbackCallStack = testutils.NewCallStack()
+
+func hostRecordFeedback(experimentName *string, score float64) bool {
+	RecordFeedbackCallStack.Push(experimentName, score)
+	return true
+}
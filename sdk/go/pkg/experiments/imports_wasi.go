@@ -0,0 +1,21 @@
+//go:build wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package experiments
+
+//go:noescape
+//go:wasmimport modus_experiments recordFeedback
+func _hostRecordFeedback(experimentName *string, score float64) bool
+
+//modus:import modus_experiments recordFeedback
+func hostRecordFeedback(experimentName *string, score float64) bool {
+	return _hostRecordFeedback(experimentName, score)
+}
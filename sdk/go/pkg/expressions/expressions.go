@@ -0,0 +1,45 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package expressions evaluates CEL expressions on the Modus host, using the same
+// expression language as the conditions and policies declared in the manifest.
+package expressions
+
+import (
+	"fmt"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/utils"
+)
+
+// Evaluate evaluates a CEL expression, and returns its result as the type T.
+// The fields of the context, which must serialize to a JSON object, are the variables
+// available to the expression.  The context may be nil if the expression has no variables.
+func Evaluate[T any](expr string, context any) (T, error) {
+	var result T
+
+	contextJson := ""
+	if context != nil {
+		bytes, err := utils.JsonSerialize(context)
+		if err != nil {
+			return result, err
+		}
+		contextJson = string(bytes)
+	}
+
+	response := hostEvalExpression(&expr, &contextJson)
+	if response == nil {
+		return result, fmt.Errorf("failed to evaluate expression: %s", expr)
+	}
+
+	if err := utils.JsonDeserialize([]byte(*response), &result); err != nil {
+		return result, fmt.Errorf("failed to deserialize the result of expression %s: %w", expr, err)
+	}
+
+	return result, nil
+}
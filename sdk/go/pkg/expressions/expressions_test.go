@@ -0,0 +1,42 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package expressions_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/expressions"
+)
+
+func TestEvaluate(t *testing.T) {
+	result, err := expressions.Evaluate[int]("1 + 2", map[string]any{"a": 1})
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+	if result != 3 {
+		t.Errorf("Expected result: 3, but received: %d", result)
+	}
+
+	values := expressions.EvalExpressionCallStack.Pop()
+	if len(values) != 2 {
+		t.Fatalf("Expected 2 values, but received %d", len(values))
+	}
+	expectedContext := `{"a":1}`
+	if !reflect.DeepEqual(values[1], &expectedContext) {
+		t.Errorf("Expected context: %s, but received: %s", expectedContext, *values[1].(*string))
+	}
+}
+
+func TestEvaluateError(t *testing.T) {
+	if _, err := expressions.Evaluate[bool]("undefined", nil); err == nil {
+		t.Error("Expected an error, but received none")
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package expressions
+
+import "github.com/hypermodeinc/modus/sdk/go/pkg/testutils"
+
+var EvalExpressionCallStack = testutils.NewCallStack()
+
+func hostEvalExpression(expr, contextJson *string) *string {
+	EvalExpressionCallStack.Push(expr, contextJson)
+
+	if *expr == "1 + 2" {
+		result := "3"
+		return &result
+	}
+	return nil
+}
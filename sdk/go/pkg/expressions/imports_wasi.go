@@ -0,0 +1,21 @@
+//go:build wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package expressions
+
+//go:noescape
+//go:wasmimport modus_expressions evalExpression
+func _hostEvalExpression(expr, contextJson *string) *string
+
+//modus:import modus_expressions evalExpression
+func hostEvalExpression(expr, contextJson *string) *string {
+	return _hostEvalExpression(expr, contextJson)
+}
@@ -0,0 +1,29 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package feedback
+
+import (
+	"fmt"
+)
+
+// Record attaches a user's rating, and an optional comment, to a previous execution.
+// The execution id is the one reported to the caller with each GraphQL field result.
+// The rating must be within the range configured in the manifest, which defaults to 1 to 5.
+func Record(executionId string, rating int32, comment string) error {
+	if executionId == "" {
+		return fmt.Errorf("execution id is required")
+	}
+
+	if !hostRecordFeedback(&executionId, rating, &comment) {
+		return fmt.Errorf("failed to record feedback for execution %s", executionId)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package feedback_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/feedback"
+)
+
+func TestRecord(t *testing.T) {
+	executionId := "mock-execution-id"
+	comment := "Very helpful."
+
+	if err := feedback.Record(executionId, 5, comment); err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+
+	values := feedback.RecordCallStack.Pop()
+	if len(values) != 3 {
+		t.Fatalf("Expected 3 values, but received %d", len(values))
+	}
+	if !reflect.DeepEqual(values[0], &executionId) {
+		t.Errorf("Expected execution id: %s, but received: %s", executionId, values[0])
+	}
+	if values[1] != int32(5) {
+		t.Errorf("Expected rating: 5, but received: %v", values[1])
+	}
+	if !reflect.DeepEqual(values[2], &comment) {
+		t.Errorf("Expected comment: %s, but received: %s", comment, values[2])
+	}
+}
+
+func TestRecordInvalid(t *testing.T) {
+	if err := feedback.Record("", 5, ""); err == nil {
+		t.Error("Expected an error for a missing execution id, but received none")
+	}
+}
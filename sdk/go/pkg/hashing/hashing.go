@@ -0,0 +1,63 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package hashing computes hashes and HMAC signatures, compares signatures in constant time,
+// and serializes JSON canonically, using the runtime's implementations.  These are useful for
+// building stable cache keys, and for verifying the signatures of webhook requests.
+package hashing
+
+import (
+	"fmt"
+)
+
+// Hash algorithms.  HMAC signatures can be computed with SHA256 or SHA512.
+const (
+	SHA256 = "sha256"
+	SHA512 = "sha512"
+	BLAKE3 = "blake3"
+)
+
+// Hash returns the hash of the data, computed with the given algorithm.
+func Hash(algorithm string, data []byte) ([]byte, error) {
+	result := hostComputeHash(&algorithm, &data)
+	if result == nil {
+		return nil, fmt.Errorf("failed to compute %s hash", algorithm)
+	}
+	return *result, nil
+}
+
+// Hmac returns the HMAC signature of the data, computed with the given key and hash algorithm.
+func Hmac(algorithm string, key, data []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("an HMAC key is required")
+	}
+
+	result := hostComputeHmac(&algorithm, &key, &data)
+	if result == nil {
+		return nil, fmt.Errorf("failed to compute %s HMAC signature", algorithm)
+	}
+	return *result, nil
+}
+
+// ConstantTimeEqual reports whether a and b are equal, in a time that depends only on their lengths.
+// Use it to compare a signature to its expected value, so that the comparison doesn't reveal how much of it matched.
+func ConstantTimeEqual(a, b []byte) bool {
+	return hostConstantTimeEqual(&a, &b)
+}
+
+// CanonicalizeJson returns the canonical form of a JSON document, as defined by the JSON Canonicalization Scheme
+// (RFC 8785), so that equal values always serialize to the same bytes.
+func CanonicalizeJson(json []byte) ([]byte, error) {
+	s := string(json)
+	result := hostCanonicalizeJson(&s)
+	if result == nil {
+		return nil, fmt.Errorf("failed to canonicalize JSON")
+	}
+	return []byte(*result), nil
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hashing_test
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/hashing"
+)
+
+func TestHash(t *testing.T) {
+	data := []byte("abc")
+
+	result, err := hashing.Hash(hashing.SHA256, data)
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+	if hex.EncodeToString(result) != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("Unexpected hash: %x", result)
+	}
+
+	values := hashing.ComputeHashCallStack.Pop()
+	if len(values) != 2 {
+		t.Fatalf("Expected 2 values, but received %d", len(values))
+	}
+	if *values[0].(*string) != hashing.SHA256 {
+		t.Errorf("Expected algorithm: %s, but received: %s", hashing.SHA256, *values[0].(*string))
+	}
+	if !reflect.DeepEqual(values[1], &data) {
+		t.Errorf("Expected data: %v, but received: %v", data, values[1])
+	}
+
+	if _, err := hashing.Hash("md5", data); err == nil {
+		t.Error("Expected an error for an unsupported algorithm, but received none")
+	}
+}
+
+func TestHmac(t *testing.T) {
+	key := []byte("Jefe")
+	data := []byte("what do ya want for nothing?")
+
+	sig, err := hashing.Hmac(hashing.SHA256, key, data)
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+	if hex.EncodeToString(sig) != "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843" {
+		t.Errorf("Unexpected signature: %x", sig)
+	}
+
+	values := hashing.ComputeHmacCallStack.Pop()
+	if len(values) != 3 {
+		t.Fatalf("Expected 3 values, but received %d", len(values))
+	}
+	if !reflect.DeepEqual(values[1], &key) {
+		t.Errorf("Expected key: %v, but received: %v", key, values[1])
+	}
+
+	if _, err := hashing.Hmac(hashing.SHA256, nil, data); err == nil {
+		t.Error("Expected an error for a missing key, but received none")
+	}
+}
+
+func TestConstantTimeEqual(t *testing.T) {
+	if !hashing.ConstantTimeEqual([]byte("signature"), []byte("signature")) {
+		t.Error("Expected equal signatures to be equal")
+	}
+	if hashing.ConstantTimeEqual([]byte("signature"), []byte("signaturf")) {
+		t.Error("Expected different signatures not to be equal")
+	}
+}
+
+func TestCanonicalizeJson(t *testing.T) {
+	result, err := hashing.CanonicalizeJson([]byte(`{ "b": 2, "a": 1 }`))
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+	if string(result) != `{"a":1,"b":2}` {
+		t.Errorf("Expected canonical JSON, but received: %s", result)
+	}
+
+	if _, err := hashing.CanonicalizeJson([]byte("not json")); err == nil {
+		t.Error("Expected an error for invalid JSON, but received none")
+	}
+}
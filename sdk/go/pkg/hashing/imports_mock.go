@@ -0,0 +1,63 @@
+//go:build !wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hashing
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/testutils"
+)
+
+var CanonicalizeJsonCallStack = testutils.NewCallStack()
+var ComputeHashCallStack = testutils.NewCallStack()
+var ComputeHmacCallStack = testutils.NewCallStack()
+var ConstantTimeEqualCallStack = testutils.NewCallStack()
+
+func hostCanonicalizeJson(json *string) *string {
+	CanonicalizeJsonCallStack.Push(json)
+
+	if *json == `{ "b": 2, "a": 1 }` {
+		result := `{"a":1,"b":2}`
+		return &result
+	}
+	return nil
+}
+
+func hostComputeHash(algorithm *string, data *[]byte) *[]byte {
+	ComputeHashCallStack.Push(algorithm, data)
+
+	if *algorithm == SHA256 {
+		sum := sha256.Sum256(*data)
+		result := sum[:]
+		return &result
+	}
+	return nil
+}
+
+func hostComputeHmac(algorithm *string, key, data *[]byte) *[]byte {
+	ComputeHmacCallStack.Push(algorithm, key, data)
+
+	if *algorithm == SHA256 {
+		mac := hmac.New(sha256.New, *key)
+		mac.Write(*data)
+		result := mac.Sum(nil)
+		return &result
+	}
+	return nil
+}
+
+func hostConstantTimeEqual(a, b *[]byte) bool {
+	ConstantTimeEqualCallStack.Push(a, b)
+	return bytes.Equal(*a, *b)
+}
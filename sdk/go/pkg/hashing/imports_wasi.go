@@ -0,0 +1,58 @@
+//go:build wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package hashing
+
+import "unsafe"
+
+//go:noescape
+//go:wasmimport modus_hashing canonicalizeJson
+func _hostCanonicalizeJson(json *string) *string
+
+//modus:import modus_hashing canonicalizeJson
+func hostCanonicalizeJson(json *string) *string {
+	return _hostCanonicalizeJson(json)
+}
+
+//go:noescape
+//go:wasmimport modus_hashing computeHash
+func _hostComputeHash(algorithm *string, data unsafe.Pointer) unsafe.Pointer
+
+//modus:import modus_hashing computeHash
+func hostComputeHash(algorithm *string, data *[]byte) *[]byte {
+	result := _hostComputeHash(algorithm, unsafe.Pointer(data))
+	if result == nil {
+		return nil
+	}
+	return (*[]byte)(result)
+}
+
+//go:noescape
+//go:wasmimport modus_hashing computeHmac
+func _hostComputeHmac(algorithm *string, key, data unsafe.Pointer) unsafe.Pointer
+
+//modus:import modus_hashing computeHmac
+func hostComputeHmac(algorithm *string, key, data *[]byte) *[]byte {
+	result := _hostComputeHmac(algorithm, unsafe.Pointer(key), unsafe.Pointer(data))
+	if result == nil {
+		return nil
+	}
+	return (*[]byte)(result)
+}
+
+//go:noescape
+//go:wasmimport modus_hashing constantTimeEqual
+func _hostConstantTimeEqual(a, b unsafe.Pointer) bool
+
+//modus:import modus_hashing constantTimeEqual
+func hostConstantTimeEqual(a, b *[]byte) bool {
+	return _hostConstantTimeEqual(unsafe.Pointer(a), unsafe.Pointer(b))
+}
@@ -14,6 +14,7 @@ package http_test
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/hypermodeinc/modus/sdk/go/pkg/http"
 )
@@ -116,6 +117,20 @@ func TestFetchWithOptions(t *testing.T) {
 	}
 }
 
+func TestRequestWithTimeout(t *testing.T) {
+	request := http.NewRequest("https://example.com", &http.RequestOptions{
+		Timeout: 5 * time.Second,
+	})
+	if request.TimeoutMs != 5000 {
+		t.Errorf("Expected timeout: 5000ms, but received: %dms", request.TimeoutMs)
+	}
+
+	clone := request.Clone(&http.RequestOptions{Method: "POST"})
+	if clone.TimeoutMs != 5000 {
+		t.Errorf("Expected cloned timeout: 5000ms, but received: %dms", clone.TimeoutMs)
+	}
+}
+
 func TestFetchTooManyOptionsParameters(t *testing.T) {
 	defer func() {
 		if r := recover(); r == nil {
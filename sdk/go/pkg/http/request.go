@@ -12,19 +12,25 @@ package http
 import (
 	"encoding/json"
 	"strings"
+	"time"
 )
 
 type Request struct {
-	Url     string
-	Method  string
-	Headers *Headers
-	Body    []byte
+	Url       string
+	Method    string
+	Headers   *Headers
+	Body      []byte
+	TimeoutMs int64
 }
 
 type RequestOptions struct {
 	Method  string
 	Headers any
 	Body    any
+
+	// Timeout is the maximum time to wait for the request to complete.
+	// If zero, the request does not time out.
+	Timeout time.Duration
 }
 
 func NewRequest(url string, options ...*RequestOptions) *Request {
@@ -58,6 +64,9 @@ func NewRequest(url string, options ...*RequestOptions) *Request {
 		if o.Body != nil {
 			req.Body = NewContent(o.Body).data
 		}
+		if o.Timeout > 0 {
+			req.TimeoutMs = o.Timeout.Milliseconds()
+		}
 	}
 
 	return req
@@ -72,6 +81,7 @@ func (r *Request) Clone(options ...*RequestOptions) *Request {
 		Method:  r.Method,
 		Headers: r.Headers,
 		Body:    &Content{r.Body},
+		Timeout: time.Duration(r.TimeoutMs) * time.Millisecond,
 	}
 
 	if len(options) == 1 && options[0] != nil {
@@ -108,6 +118,9 @@ func (r *Request) Clone(options ...*RequestOptions) *Request {
 		if o.Body != nil {
 			ro.Body = o.Body
 		}
+		if o.Timeout > 0 {
+			ro.Timeout = o.Timeout
+		}
 	}
 
 	return NewRequest(r.Url, ro)
@@ -20,6 +20,9 @@ import (
 var GetLocalTimeCallStack = testutils.NewCallStack()
 var GetTimeInZoneCallStack = testutils.NewCallStack()
 var GetTimeLocationCallStack = testutils.NewCallStack()
+var AddDurationCallStack = testutils.NewCallStack()
+var FormatRelativeTimeCallStack = testutils.NewCallStack()
+var GetNextCronTimeCallStack = testutils.NewCallStack()
 
 func hostGetLocalTime() (time.Time, error) {
 	GetLocalTimeCallStack.Push()
@@ -40,3 +43,42 @@ func hostGetTimeLocation(tz string) (*time.Location, error) {
 	GetTimeLocationCallStack.Push()
 	return time.LoadLocation(tz)
 }
+
+func hostAddDuration(timestamp, duration, tz *string) *string {
+	AddDurationCallStack.Push(timestamp, duration, tz)
+
+	t, err := time.Parse(time.RFC3339Nano, *timestamp)
+	if err != nil {
+		return nil
+	}
+	d, err := time.ParseDuration(*duration)
+	if err != nil {
+		return nil
+	}
+
+	result := t.Add(d).Format(time.RFC3339Nano)
+	return &result
+}
+
+func hostFormatRelativeTime(timestamp, reference *string) *string {
+	FormatRelativeTimeCallStack.Push(timestamp, reference)
+
+	result := "just now"
+	return &result
+}
+
+func hostGetNextCronTime(expression, after, tz *string) *string {
+	GetNextCronTimeCallStack.Push(expression, after, tz)
+
+	// only the hourly schedule is supported by the mock
+	if *expression != "@hourly" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, *after)
+	if err != nil {
+		return nil
+	}
+
+	result := t.Truncate(time.Hour).Add(time.Hour).Format(time.RFC3339Nano)
+	return &result
+}
@@ -64,3 +64,30 @@ func hostGetTimeLocation(tz string) (*time.Location, error) {
 
 	return loc, nil
 }
+
+//go:noescape
+//go:wasmimport modus_system addDuration
+func _hostAddDuration(timestamp, duration, tz *string) *string
+
+//modus:import modus_system addDuration
+func hostAddDuration(timestamp, duration, tz *string) *string {
+	return _hostAddDuration(timestamp, duration, tz)
+}
+
+//go:noescape
+//go:wasmimport modus_system formatRelativeTime
+func _hostFormatRelativeTime(timestamp, reference *string) *string
+
+//modus:import modus_system formatRelativeTime
+func hostFormatRelativeTime(timestamp, reference *string) *string {
+	return _hostFormatRelativeTime(timestamp, reference)
+}
+
+//go:noescape
+//go:wasmimport modus_system getNextCronTime
+func _hostGetNextCronTime(expression, after, tz *string) *string
+
+//modus:import modus_system getNextCronTime
+func hostGetNextCronTime(expression, after, tz *string) *string {
+	return _hostGetNextCronTime(expression, after, tz)
+}
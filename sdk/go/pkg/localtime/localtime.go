@@ -11,6 +11,7 @@ package localtime
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"time"
 )
@@ -61,3 +62,53 @@ func GetLocation(tz string) (*time.Location, error) {
 	}
 	return hostGetTimeLocation(tz)
 }
+
+// AddDuration adds a duration to a time.  The duration is either an ISO 8601 duration, such as "P1DT12H",
+// or a Go duration, such as "1h30m", and may be negative.  Years, months, weeks and days are added to the
+// calendar date in the given time zone, so that adding a day keeps the same wall clock time across a daylight
+// saving time change.  If the time zone is empty, the local time zone is used.
+func AddDuration(t time.Time, duration, tz string) (time.Time, error) {
+	ts := t.Format(time.RFC3339Nano)
+	result := hostAddDuration(&ts, &duration, &tz)
+	if result == nil {
+		return time.Time{}, fmt.Errorf("failed to add duration %s", duration)
+	}
+	return parseHostTime(*result, tz)
+}
+
+// FormatRelativeTime describes a time relative to a reference time, such as "3 hours ago" or "in 2 days".
+func FormatRelativeTime(t, reference time.Time) (string, error) {
+	ts := t.Format(time.RFC3339Nano)
+	ref := reference.Format(time.RFC3339Nano)
+	result := hostFormatRelativeTime(&ts, &ref)
+	if result == nil {
+		return "", errors.New("failed to format relative time")
+	}
+	return *result, nil
+}
+
+// NextCronTime returns the first time after the given time that matches a cron expression, with the standard
+// five fields of minute, hour, day of month, month and day of week, evaluated in the given time zone.
+// If the time zone is empty, the local time zone is used.
+func NextCronTime(expression string, after time.Time, tz string) (time.Time, error) {
+	ts := after.Format(time.RFC3339Nano)
+	result := hostGetNextCronTime(&expression, &ts, &tz)
+	if result == nil {
+		return time.Time{}, fmt.Errorf("failed to compute the next time for cron expression %s", expression)
+	}
+	return parseHostTime(*result, tz)
+}
+
+// parseHostTime parses a timestamp returned by the host, in the location of the time zone if it is available.
+func parseHostTime(ts, tz string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if tz != "" {
+		if loc, err := hostGetTimeLocation(tz); err == nil {
+			t = t.In(loc)
+		}
+	}
+	return t, nil
+}
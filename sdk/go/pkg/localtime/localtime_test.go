@@ -0,0 +1,77 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package localtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/localtime"
+)
+
+func TestAddDuration(t *testing.T) {
+	start := time.Date(2024, 7, 4, 12, 0, 0, 0, time.UTC)
+
+	result, err := localtime.AddDuration(start, "90m", "")
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+	if !result.Equal(start.Add(90 * time.Minute)) {
+		t.Errorf("Expected %s, but received %s", start.Add(90*time.Minute), result)
+	}
+
+	values := localtime.AddDurationCallStack.Pop()
+	if len(values) != 3 {
+		t.Fatalf("Expected 3 values, but received %d", len(values))
+	}
+	if *values[0].(*string) != "2024-07-04T12:00:00Z" {
+		t.Errorf("Expected timestamp: 2024-07-04T12:00:00Z, but received: %s", *values[0].(*string))
+	}
+	if *values[1].(*string) != "90m" {
+		t.Errorf("Expected duration: 90m, but received: %s", *values[1].(*string))
+	}
+}
+
+func TestNextCronTime(t *testing.T) {
+	after := time.Date(2024, 7, 4, 12, 30, 0, 0, time.UTC)
+
+	result, err := localtime.NextCronTime("@hourly", after, "UTC")
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+	expected := time.Date(2024, 7, 4, 13, 0, 0, 0, time.UTC)
+	if !result.Equal(expected) {
+		t.Errorf("Expected %s, but received %s", expected, result)
+	}
+
+	values := localtime.GetNextCronTimeCallStack.Pop()
+	if len(values) != 3 {
+		t.Fatalf("Expected 3 values, but received %d", len(values))
+	}
+	if *values[2].(*string) != "UTC" {
+		t.Errorf("Expected time zone: UTC, but received: %s", *values[2].(*string))
+	}
+
+	if _, err := localtime.NextCronTime("not a cron expression", after, "UTC"); err == nil {
+		t.Error("Expected an error for an invalid expression, but received none")
+	}
+}
+
+func TestFormatRelativeTime(t *testing.T) {
+	now := time.Now()
+
+	result, err := localtime.FormatRelativeTime(now, now)
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+	if result != "just now" {
+		t.Errorf("Expected: just now, but received: %s", result)
+	}
+}
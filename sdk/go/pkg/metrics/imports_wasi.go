@@ -0,0 +1,23 @@
+//go:build wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package metrics
+
+import "unsafe"
+
+//go:noescape
+//go:wasmimport modus_metrics recordMetric
+func _hostRecordMetric(name, kind *string, value float64, tags unsafe.Pointer) bool
+
+//modus:import modus_metrics recordMetric
+func hostRecordMetric(name, kind *string, value float64, tags *map[string]string) bool {
+	return _hostRecordMetric(name, kind, value, unsafe.Pointer(tags))
+}
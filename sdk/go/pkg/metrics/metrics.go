@@ -0,0 +1,47 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package metrics records custom metrics, which the runtime exposes with its own metrics.
+// Each metric is named plugin_{name}, and is labeled with the plugin name in addition to the given tags.
+// A metric must always be recorded with the same kind and the same tag names.
+package metrics
+
+import (
+	"fmt"
+)
+
+// IncrementCounter adds the given value, which must not be negative, to a counter.
+func IncrementCounter(name string, value float64, tags map[string]string) error {
+	return record(name, "counter", value, tags)
+}
+
+// SetGauge sets a gauge to the given value.
+func SetGauge(name string, value float64, tags map[string]string) error {
+	return record(name, "gauge", value, tags)
+}
+
+// ObserveHistogram records the given value in a histogram.
+func ObserveHistogram(name string, value float64, tags map[string]string) error {
+	return record(name, "histogram", value, tags)
+}
+
+func record(name, kind string, value float64, tags map[string]string) error {
+	if name == "" {
+		return fmt.Errorf("metric name is required")
+	}
+	if tags == nil {
+		tags = map[string]string{}
+	}
+
+	if !hostRecordMetric(&name, &kind, value, &tags) {
+		return fmt.Errorf("failed to record metric %s", name)
+	}
+
+	return nil
+}
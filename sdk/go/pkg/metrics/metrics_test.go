@@ -0,0 +1,60 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package metrics_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/metrics"
+)
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		kind   string
+		record func(string, float64, map[string]string) error
+	}{
+		{"counter", metrics.IncrementCounter},
+		{"gauge", metrics.SetGauge},
+		{"histogram", metrics.ObserveHistogram},
+	}
+
+	for _, tt := range tests {
+		name := "documents_indexed"
+		tags := map[string]string{"source": "upload"}
+
+		if err := tt.record(name, 3, tags); err != nil {
+			t.Fatalf("Expected no error, but received: %s", err)
+		}
+
+		values := metrics.RecordCallStack.Pop()
+		if len(values) != 4 {
+			t.Fatalf("Expected 4 values, but received %d", len(values))
+		}
+		if !reflect.DeepEqual(values[0], &name) {
+			t.Errorf("Expected name: %s, but received: %s", name, values[0])
+		}
+		if !reflect.DeepEqual(values[1], &tt.kind) {
+			t.Errorf("Expected kind: %s, but received: %s", tt.kind, values[1])
+		}
+		if values[2] != float64(3) {
+			t.Errorf("Expected value: 3, but received: %v", values[2])
+		}
+		if !reflect.DeepEqual(values[3], &tags) {
+			t.Errorf("Expected tags: %v, but received: %v", tags, values[3])
+		}
+	}
+}
+
+func TestRecordInvalid(t *testing.T) {
+	if err := metrics.SetGauge("", 1, nil); err == nil {
+		t.Error("Expected an error for a missing name, but received none")
+	}
+}
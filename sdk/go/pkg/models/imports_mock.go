@@ -11,10 +11,16 @@
 
 package models
 
-import "github.com/hypermodeinc/modus/sdk/go/pkg/testutils"
+import (
+	"strings"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/testutils"
+)
 
 var LookupModelCallStack = testutils.NewCallStack()
 var InvokeModelCallStack = testutils.NewCallStack()
+var CountTokensCallStack = testutils.NewCallStack()
+var TranscribeAudioCallStack = testutils.NewCallStack()
 
 const MockResponseText = "Hello, World!"
 
@@ -33,3 +39,20 @@ func hostInvokeModel(modelName *string, input *string) *string {
 	output := `{"response":"` + MockResponseText + `"}`
 	return &output
 }
+
+func hostCountTokens(modelName *string, text *string) int32 {
+	CountTokensCallStack.Push(modelName, text)
+
+	return int32(len(strings.Fields(*text)))
+}
+
+func hostTranscribeAudio(modelName *string, audio *[]byte, options *TranscriptionOptions) *Transcription {
+	TranscribeAudioCallStack.Push(modelName, audio, options)
+
+	return &Transcription{
+		Text:     MockResponseText,
+		Language: options.Language,
+		Duration: 1.5,
+		Segments: []TranscriptionSegment{{Start: 0, End: 1.5, Text: MockResponseText}},
+	}
+}
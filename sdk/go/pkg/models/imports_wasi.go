@@ -29,3 +29,20 @@ func hostGetModelInfo(modelName *string) *ModelInfo {
 //go:noescape
 //go:wasmimport modus_models invokeModel
 func hostInvokeModel(modelName *string, input *string) *string
+
+//go:noescape
+//go:wasmimport modus_models countTokens
+func hostCountTokens(modelName *string, text *string) int32
+
+//go:noescape
+//go:wasmimport modus_models transcribeAudio
+func _hostTranscribeAudio(modelName *string, audio unsafe.Pointer, options unsafe.Pointer) unsafe.Pointer
+
+//modus:import modus_models transcribeAudio
+func hostTranscribeAudio(modelName *string, audio *[]byte, options *TranscriptionOptions) *Transcription {
+	result := _hostTranscribeAudio(modelName, unsafe.Pointer(audio), unsafe.Pointer(options))
+	if result == nil {
+		return nil
+	}
+	return (*Transcription)(result)
+}
@@ -72,6 +72,74 @@ func GetModel[TModel Model[TIn, TOut], TIn, TOut any, P modelPtr[TModel]](name s
 	return model, nil
 }
 
+// Counts the tokens that the text is encoded as by the tokenizer of the model with the given name.
+// The tokenizer is set in the modus.json manifest file, or is inferred for known OpenAI models.
+func CountTokens(modelName, text string) (int, error) {
+	if text == "" {
+		return 0, nil
+	}
+
+	count := hostCountTokens(&modelName, &text)
+	if count == 0 {
+		return 0, fmt.Errorf("failed to count tokens for model %s", modelName)
+	}
+	return int(count), nil
+}
+
+// The optional settings of an audio transcription.
+type TranscriptionOptions struct {
+
+	// The ISO-639-1 code of the language spoken in the audio.  It is detected if not given.
+	Language string
+
+	// Text that guides the style of the transcript, or continues a previous segment of audio.
+	Prompt string
+
+	// The sampling temperature, between 0 and 1.
+	Temperature float64
+}
+
+// The text of an audio transcription, along with its timed segments.
+type Transcription struct {
+
+	// The full text of the transcription.
+	Text string
+
+	// The language of the audio, as reported by the model.
+	Language string
+
+	// The duration of the audio, in seconds.
+	Duration float64
+
+	// The segments of the transcription, in order.
+	Segments []TranscriptionSegment
+}
+
+// A segment of a transcription, with its start and end in seconds from the start of the audio.
+type TranscriptionSegment struct {
+	Start float64
+	End   float64
+	Text  string
+}
+
+// Transcribes the audio with the speech-to-text model with the given name, such as a Whisper model.
+// The audio is sent to the model by the Modus host, and its format (wav, mp3, flac, ogg, webm or m4a)
+// is detected from its content.  The options may be nil.
+func TranscribeAudio(modelName string, audio []byte, options *TranscriptionOptions) (*Transcription, error) {
+	if len(audio) == 0 {
+		return nil, fmt.Errorf("audio is empty")
+	}
+	if options == nil {
+		options = &TranscriptionOptions{}
+	}
+
+	result := hostTranscribeAudio(&modelName, &audio, options)
+	if result == nil {
+		return nil, fmt.Errorf("failed to transcribe audio with model %s", modelName)
+	}
+	return result, nil
+}
+
 // Invokes the model with the specified input and returns the output generated by the model.
 func (m ModelBase[TIn, TOut]) Invoke(input *TIn) (*TOut, error) {
 	if m.info == nil {
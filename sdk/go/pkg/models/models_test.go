@@ -113,3 +113,61 @@ func TestInvokeModel_bad_model_instance(t *testing.T) {
 		t.Errorf("Expected output to be nil, but received: %v", output)
 	}
 }
+
+func TestCountTokens(t *testing.T) {
+	modelName := "test"
+	text := "Count these tokens."
+
+	count, err := models.CountTokens(modelName, text)
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 tokens, but received: %d", count)
+	}
+
+	values := models.CountTokensCallStack.Pop()
+	if len(values) != 2 {
+		t.Fatalf("Expected 2 values, but received %d", len(values))
+	}
+	if !reflect.DeepEqual(values[0], &modelName) {
+		t.Errorf("Expected model name: %s, but received: %s", modelName, values[0])
+	}
+	if !reflect.DeepEqual(values[1], &text) {
+		t.Errorf("Expected text: %s, but received: %s", text, values[1])
+	}
+}
+
+func TestTranscribeAudio(t *testing.T) {
+	modelName := "test"
+	audio := []byte("RIFF\x24\x00\x00\x00WAVEfmt ")
+	options := &models.TranscriptionOptions{Language: "en"}
+
+	result, err := models.TranscribeAudio(modelName, audio, options)
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+	expected := &models.Transcription{
+		Text:     models.MockResponseText,
+		Language: "en",
+		Duration: 1.5,
+		Segments: []models.TranscriptionSegment{{Start: 0, End: 1.5, Text: models.MockResponseText}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected transcription: %v, but received: %v", expected, result)
+	}
+
+	values := models.TranscribeAudioCallStack.Pop()
+	if len(values) != 3 {
+		t.Fatalf("Expected 3 values, but received %d", len(values))
+	}
+	if !reflect.DeepEqual(values[0], &modelName) {
+		t.Errorf("Expected model name: %s, but received: %s", modelName, values[0])
+	}
+	if !reflect.DeepEqual(values[1], &audio) {
+		t.Errorf("Expected audio: %v, but received: %v", audio, values[1])
+	}
+	if !reflect.DeepEqual(values[2], options) {
+		t.Errorf("Expected options: %v, but received: %v", options, values[2])
+	}
+}
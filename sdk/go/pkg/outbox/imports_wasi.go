@@ -0,0 +1,27 @@
+//go:build wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package outbox
+
+import (
+	"unsafe"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/http"
+)
+
+//go:noescape
+//go:wasmimport modus_outbox stage
+func _hostStage(request unsafe.Pointer) bool
+
+//modus:import modus_outbox stage
+func hostStage(request *http.Request) bool {
+	return _hostStage(unsafe.Pointer(request))
+}
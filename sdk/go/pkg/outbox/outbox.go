@@ -0,0 +1,46 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package outbox stages HTTP requests that are sent only after the current function completes successfully.
+package outbox
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/http"
+)
+
+// Stage adds an HTTP request to the outbox of the current function invocation.
+// The request is sent by the Modus runtime after the function completes successfully,
+// and is retried if it fails.  If the function returns an error, the request is discarded.
+func Stage[T *http.Request | string](requestOrUrl T, options ...*http.RequestOptions) error {
+
+	if len(options) > 1 {
+		panic("Too many arguments to Stage")
+	}
+
+	var request *http.Request
+	switch t := any(requestOrUrl).(type) {
+	case *http.Request:
+		request = t.Clone(options...)
+	case string:
+		request = http.NewRequest(t, options...)
+	}
+
+	if _, err := url.ParseRequestURI(request.Url); err != nil {
+		return errors.New("Invalid URL")
+	}
+
+	if !hostStage(request) {
+		return errors.New("Failed to stage outbox message. Check the logs for more information.")
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package outbox_test
+
+import (
+	"testing"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/http"
+	"github.com/hypermodeinc/modus/sdk/go/pkg/outbox"
+)
+
+func TestStage(t *testing.T) {
+	err := outbox.Stage("https://example.com/hooks", &http.RequestOptions{
+		Method: "POST",
+		Body:   `{"event":"created"}`,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+
+	values := outbox.StageCallStack.Pop()
+	if len(values) != 1 {
+		t.Fatalf("Expected 1 value, but received %d", len(values))
+	}
+	request, ok := values[0].(*http.Request)
+	if !ok {
+		t.Fatalf("Expected a *http.Request, but received %T", values[0])
+	}
+	if request.Url != "https://example.com/hooks" {
+		t.Errorf("Expected url: https://example.com/hooks, but received: %s", request.Url)
+	}
+	if request.Method != "POST" {
+		t.Errorf("Expected method: POST, but received: %s", request.Method)
+	}
+	if request.Text() != `{"event":"created"}` {
+		t.Errorf("Expected body: %s, but received: %s", `{"event":"created"}`, request.Text())
+	}
+}
+
+func TestStageInvalidUrl(t *testing.T) {
+	if err := outbox.Stage("not a url"); err == nil {
+		t.Error("Expected an error for an invalid url, but received none")
+	}
+}
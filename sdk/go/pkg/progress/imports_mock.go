@@ -0,0 +1,21 @@
+//go:build !wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package progress
+
+import "github.com/hypermodeinc/modus/sdk/go/pkg/testutils"
+
+var ReportCallStack = testutils.NewCallStack()
+
+func hostReportProgress(percent float64, message *string) bool {
+	ReportCallStack.Push(percent, message)
+	return true
+}
@@ -0,0 +1,21 @@
+//go:build wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package progress
+
+//go:noescape
+//go:wasmimport modus_progress reportProgress
+func _hostReportProgress(percent float64, message *string) bool
+
+//modus:import modus_progress reportProgress
+func hostReportProgress(percent float64, message *string) bool {
+	return _hostReportProgress(percent, message)
+}
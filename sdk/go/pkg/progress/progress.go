@@ -0,0 +1,29 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package progress
+
+import (
+	"fmt"
+)
+
+// Report emits a progress event for the current function execution, which is streamed to clients
+// subscribed at the runtime's /progress/{id} endpoint, with either the execution id or the request id
+// the caller gave in the X-Request-Id header.  The percentage must be between 0 and 100.
+func Report(percent float64, message string) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("progress percentage %v is out of range, must be between 0 and 100", percent)
+	}
+
+	if !hostReportProgress(percent, &message) {
+		return fmt.Errorf("failed to report progress")
+	}
+
+	return nil
+}
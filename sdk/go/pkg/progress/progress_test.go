@@ -0,0 +1,42 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package progress_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/progress"
+)
+
+func TestReport(t *testing.T) {
+	message := "Embedding documents."
+
+	if err := progress.Report(40, message); err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+
+	values := progress.ReportCallStack.Pop()
+	if len(values) != 2 {
+		t.Fatalf("Expected 2 values, but received %d", len(values))
+	}
+	if values[0] != float64(40) {
+		t.Errorf("Expected percent: 40, but received: %v", values[0])
+	}
+	if !reflect.DeepEqual(values[1], &message) {
+		t.Errorf("Expected message: %s, but received: %s", message, values[1])
+	}
+}
+
+func TestReportOutOfRange(t *testing.T) {
+	if err := progress.Report(120, ""); err == nil {
+		t.Error("Expected an error for a percentage over 100, but received none")
+	}
+}
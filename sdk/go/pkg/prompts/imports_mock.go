@@ -0,0 +1,23 @@
+//go:build !wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package prompts
+
+import "github.com/hypermodeinc/modus/sdk/go/pkg/testutils"
+
+var RenderPromptCallStack = testutils.NewCallStack()
+
+func hostRenderPrompt(name, version *string, variables *map[string]string) *string {
+	RenderPromptCallStack.Push(name, version, variables)
+
+	result := "rendered " + *name
+	return &result
+}
@@ -0,0 +1,23 @@
+//go:build wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package prompts
+
+import "unsafe"
+
+//go:noescape
+//go:wasmimport modus_prompts renderPrompt
+func _hostRenderPrompt(name, version *string, variables unsafe.Pointer) *string
+
+//modus:import modus_prompts renderPrompt
+func hostRenderPrompt(name, version *string, variables *map[string]string) *string {
+	return _hostRenderPrompt(name, version, unsafe.Pointer(variables))
+}
@@ -0,0 +1,39 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package prompts
+
+import (
+	"fmt"
+)
+
+// Render renders the prompt template with the given name, using the default version
+// configured in the manifest, and substitutes the provided variables.
+func Render(name string, variables map[string]string) (string, error) {
+	return RenderVersion(name, "", variables)
+}
+
+// RenderVersion renders a specific version of the prompt template with the given name,
+// and substitutes the provided variables.
+func RenderVersion(name, version string, variables map[string]string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("prompt name is required")
+	}
+
+	if variables == nil {
+		variables = map[string]string{}
+	}
+
+	result := hostRenderPrompt(&name, &version, &variables)
+	if result == nil {
+		return "", fmt.Errorf("failed to render prompt %s", name)
+	}
+
+	return *result, nil
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package prompts_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/prompts"
+)
+
+func TestRenderVersion(t *testing.T) {
+	name := "greeting"
+	version := "2"
+	variables := map[string]string{"name": "Ann"}
+
+	result, err := prompts.RenderVersion(name, version, variables)
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+	if result != "rendered greeting" {
+		t.Errorf("Expected rendered prompt, but received: %s", result)
+	}
+
+	values := prompts.RenderPromptCallStack.Pop()
+	if len(values) != 3 {
+		t.Fatalf("Expected 3 values, but received %d", len(values))
+	}
+	if !reflect.DeepEqual(values[0], &name) {
+		t.Errorf("Expected name: %s, but received: %s", name, values[0])
+	}
+	if !reflect.DeepEqual(values[1], &version) {
+		t.Errorf("Expected version: %s, but received: %s", version, values[1])
+	}
+	if !reflect.DeepEqual(values[2], &variables) {
+		t.Errorf("Expected variables: %v, but received: %v", variables, values[2])
+	}
+}
+
+func TestRenderWithoutName(t *testing.T) {
+	if _, err := prompts.Render("", nil); err == nil {
+		t.Error("Expected an error, but received none")
+	}
+}
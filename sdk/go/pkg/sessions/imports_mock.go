@@ -0,0 +1,44 @@
+//go:build !wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package sessions
+
+import "github.com/hypermodeinc/modus/sdk/go/pkg/testutils"
+
+var AppendCallStack = testutils.NewCallStack()
+var GetCallStack = testutils.NewCallStack()
+var ClearCallStack = testutils.NewCallStack()
+
+var mockSessions = map[string][]*Message{}
+
+func hostAppend(sessionId *string, messages *[]*Message) bool {
+	AppendCallStack.Push(sessionId, messages)
+
+	mockSessions[*sessionId] = append(mockSessions[*sessionId], *messages...)
+	return true
+}
+
+func hostGet(sessionId *string, maxTokens int32) *[]*Message {
+	GetCallStack.Push(sessionId, maxTokens)
+
+	messages := mockSessions[*sessionId]
+	if messages == nil {
+		messages = []*Message{}
+	}
+	return &messages
+}
+
+func hostClear(sessionId *string) bool {
+	ClearCallStack.Push(sessionId)
+
+	delete(mockSessions, *sessionId)
+	return true
+}
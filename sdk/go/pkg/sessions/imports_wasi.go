@@ -0,0 +1,40 @@
+//go:build wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package sessions
+
+import "unsafe"
+
+//go:noescape
+//go:wasmimport modus_sessions append
+func _hostAppend(sessionId *string, messages unsafe.Pointer) bool
+
+//modus:import modus_sessions append
+func hostAppend(sessionId *string, messages *[]*Message) bool {
+	return _hostAppend(sessionId, unsafe.Pointer(messages))
+}
+
+//go:noescape
+//go:wasmimport modus_sessions get
+func _hostGet(sessionId *string, maxTokens int32) unsafe.Pointer
+
+//modus:import modus_sessions get
+func hostGet(sessionId *string, maxTokens int32) *[]*Message {
+	messages := _hostGet(sessionId, maxTokens)
+	if messages == nil {
+		return nil
+	}
+	return (*[]*Message)(messages)
+}
+
+//go:noescape
+//go:wasmimport modus_sessions clear
+func hostClear(sessionId *string) bool
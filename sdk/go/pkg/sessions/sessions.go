@@ -0,0 +1,70 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package sessions
+
+import (
+	"fmt"
+)
+
+// A Message is a single entry in a session's history.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Append adds messages to the end of the history of the given session, creating the session if needed.
+func Append(sessionId string, messages ...*Message) error {
+	if sessionId == "" {
+		return fmt.Errorf("session id is required")
+	}
+
+	if len(messages) == 0 {
+		return nil
+	}
+
+	if !hostAppend(&sessionId, &messages) {
+		return fmt.Errorf("failed to append to session %s", sessionId)
+	}
+
+	return nil
+}
+
+// Get returns the full history of the given session, oldest message first.
+func Get(sessionId string) ([]*Message, error) {
+	return GetWithinTokens(sessionId, 0)
+}
+
+// GetWithinTokens returns the most recent messages of the given session that fit within
+// the specified (estimated) number of tokens, oldest message first.
+func GetWithinTokens(sessionId string, maxTokens int) ([]*Message, error) {
+	if sessionId == "" {
+		return nil, fmt.Errorf("session id is required")
+	}
+
+	messages := hostGet(&sessionId, int32(maxTokens))
+	if messages == nil {
+		return nil, fmt.Errorf("failed to get session %s", sessionId)
+	}
+
+	return *messages, nil
+}
+
+// Clear removes the given session and all of its history.
+func Clear(sessionId string) error {
+	if sessionId == "" {
+		return fmt.Errorf("session id is required")
+	}
+
+	if !hostClear(&sessionId) {
+		return fmt.Errorf("failed to clear session %s", sessionId)
+	}
+
+	return nil
+}
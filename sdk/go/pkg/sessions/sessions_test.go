@@ -0,0 +1,60 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package sessions_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/sessions"
+)
+
+func TestSessionHistory(t *testing.T) {
+	sessionId := "session-1"
+	m1 := &sessions.Message{Role: "user", Content: "Hello"}
+	m2 := &sessions.Message{Role: "assistant", Content: "Hi there!"}
+
+	if err := sessions.Append(sessionId, m1, m2); err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+
+	values := sessions.AppendCallStack.Pop()
+	if len(values) != 2 {
+		t.Fatalf("Expected 2 values, but received %d", len(values))
+	}
+	if !reflect.DeepEqual(values[0], &sessionId) {
+		t.Errorf("Expected session id: %s, but received: %s", sessionId, values[0])
+	}
+
+	messages, err := sessions.GetWithinTokens(sessionId, 100)
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+	if !reflect.DeepEqual(messages, []*sessions.Message{m1, m2}) {
+		t.Errorf("Expected messages: %v, but received: %v", []*sessions.Message{m1, m2}, messages)
+	}
+
+	values = sessions.GetCallStack.Pop()
+	if len(values) != 2 || values[1] != int32(100) {
+		t.Errorf("Expected max tokens: 100, but received: %v", values)
+	}
+
+	if err := sessions.Clear(sessionId); err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+
+	messages, err = sessions.Get(sessionId)
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+	if len(messages) != 0 {
+		t.Errorf("Expected no messages, but received: %v", messages)
+	}
+}
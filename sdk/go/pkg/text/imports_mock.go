@@ -0,0 +1,35 @@
+//go:build !wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package text
+
+import (
+	"strings"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/testutils"
+)
+
+var SegmentTextCallStack = testutils.NewCallStack()
+
+func hostSegmentText(text, granularity *string) *[]string {
+	SegmentTextCallStack.Push(text, granularity)
+
+	var segments []string
+	switch *granularity {
+	case Word:
+		segments = strings.Fields(*text)
+	case Sentence, Paragraph:
+		segments = []string{strings.TrimSpace(*text)}
+	default:
+		return nil
+	}
+	return &segments
+}
@@ -0,0 +1,27 @@
+//go:build wasip1
+
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package text
+
+import "unsafe"
+
+//go:noescape
+//go:wasmimport modus_text segmentText
+func _hostSegmentText(text, granularity *string) unsafe.Pointer
+
+//modus:import modus_text segmentText
+func hostSegmentText(text, granularity *string) *[]string {
+	segments := _hostSegmentText(text, granularity)
+	if segments == nil {
+		return nil
+	}
+	return (*[]string)(segments)
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// The text package splits text into words, sentences and paragraphs, following the Unicode text segmentation rules.
+package text
+
+import "fmt"
+
+// The granularities that text can be segmented at.
+const (
+	Word      = "word"
+	Sentence  = "sentence"
+	Paragraph = "paragraph"
+)
+
+// Segment splits the text into segments of the given granularity.
+// Surrounding whitespace is removed from each segment, and empty segments are omitted.
+// Word segments include only words and numbers, not punctuation or whitespace.
+func Segment(text, granularity string) ([]string, error) {
+	segments := hostSegmentText(&text, &granularity)
+	if segments == nil {
+		return nil, fmt.Errorf("failed to segment text into %s segments", granularity)
+	}
+	return *segments, nil
+}
+
+// Words returns the words and numbers of the text.
+func Words(text string) ([]string, error) {
+	return Segment(text, Word)
+}
+
+// Sentences returns the sentences of the text.
+func Sentences(text string) ([]string, error) {
+	return Segment(text, Sentence)
+}
+
+// Paragraphs returns the paragraphs of the text, which are separated by blank lines.
+func Paragraphs(text string) ([]string, error) {
+	return Segment(text, Paragraph)
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2024 Hypermode Inc.
+ * Licensed under the terms of the Apache License, Version 2.0
+ * See the LICENSE file that accompanied this code for further details.
+ *
+ * SPDX-FileCopyrightText: 2024 Hypermode Inc. <hello@hypermode.com>
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package text_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hypermodeinc/modus/sdk/go/pkg/text"
+)
+
+func TestWords(t *testing.T) {
+	input := "Hello world"
+
+	words, err := text.Words(input)
+	if err != nil {
+		t.Fatalf("Expected no error, but received: %s", err)
+	}
+	if !reflect.DeepEqual(words, []string{"Hello", "world"}) {
+		t.Errorf("Unexpected words: %v", words)
+	}
+
+	values := text.SegmentTextCallStack.Pop()
+	if len(values) != 2 {
+		t.Fatalf("Expected 2 values, but received %d", len(values))
+	}
+	if *values[0].(*string) != input {
+		t.Errorf("Expected text: %s, but received: %s", input, *values[0].(*string))
+	}
+	if *values[1].(*string) != text.Word {
+		t.Errorf("Expected granularity: %s, but received: %s", text.Word, *values[1].(*string))
+	}
+}
+
+func TestSegmentError(t *testing.T) {
+	_, err := text.Segment("Hello world", "grapheme")
+	if err == nil {
+		t.Fatal("Expected an error, but received none")
+	}
+	text.SegmentTextCallStack.Pop()
+}
@@ -154,6 +154,8 @@ func ParseJsonNumberAs[T any](n json.Number) (T, error) {
 	}
 }
 
+// RawJsonString is a string of JSON.  When a function returns it, the Modus runtime
+// passes it through to the response verbatim, typed as the JSON scalar in the GraphQL schema.
 type RawJsonString string
 
 func (s RawJsonString) MarshalJSON() ([]byte, error) {
@@ -12,10 +12,13 @@ package extractor
 import (
 	"go/types"
 	"sort"
+	"strings"
 
 	"github.com/hypermodeinc/modus/sdk/go/tools/modus-go-build/config"
 	"github.com/hypermodeinc/modus/sdk/go/tools/modus-go-build/metadata"
 	"github.com/hypermodeinc/modus/sdk/go/tools/modus-go-build/utils"
+
+	"golang.org/x/tools/go/packages"
 )
 
 func CollectProgramInfo(config *config.Config, meta *metadata.Metadata) error {
@@ -24,6 +27,8 @@ func CollectProgramInfo(config *config.Config, meta *metadata.Metadata) error {
 		return err
 	}
 
+	meta.Snapshot = hasSnapshotDirective(pkgs)
+
 	requiredTypes := make(map[string]types.Type)
 
 	for name, f := range getExportedFunctions(pkgs) {
@@ -64,3 +69,39 @@ func CollectProgramInfo(config *config.Config, meta *metadata.Metadata) error {
 
 	return nil
 }
+
+func hasSnapshotDirective(pkgs map[string]*packages.Package) bool {
+	/*
+		A plugin declares that its instances can be restored from a snapshot of an instance that has
+		already run its top-level code, by including the following comment in its main package:
+
+		//modus:snapshot
+
+		This is only valid if the top-level code has no side effects that must happen for every instance,
+		such as seeding a random number generator or reading the current time.
+
+		Every restored instance starts with the state of the Go runtime at the time the snapshot was captured,
+		including the seeds of its pseudo-random number generator and of its map hashes.  So math/rand returns
+		the same sequence in each instance restored from a snapshot, and the iteration order of maps repeats.
+		Use crypto/rand, which reads from the host on every call, for values that must differ between instances.
+
+		The environment is captured too.  Instances in different time zones are restored from separate snapshots,
+		and the caller's JWT claims are not in the environment of these plugins, but are read on every call.
+	*/
+
+	for _, pkg := range pkgs {
+		if pkg.Name != "main" {
+			continue
+		}
+		for _, f := range pkg.Syntax {
+			for _, cg := range f.Comments {
+				for _, c := range cg.List {
+					if strings.TrimSpace(c.Text) == "//modus:snapshot" {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
@@ -67,8 +67,9 @@ func transformFunc(name string, f *types.Func, pkgs map[string]*packages.Package
 	}
 
 	ret := metadata.Function{
-		Name: name,
-		Docs: getDocs(funcDecl.Doc),
+		Name:  name,
+		Docs:  getDocs(funcDecl.Doc),
+		Batch: hasBatchDirective(funcDecl.Doc),
 	}
 
 	if params != nil {
@@ -120,6 +121,21 @@ func getStructDeclarationAndType(name string, pkgs map[string]*packages.Package)
 	return nil, nil
 }
 
+// hasBatchDirective reports whether a function declares that it resolves a field for a list of parent objects
+// in one call, with a "//modus:batch" comment.  Such a function takes the list of parents as its only parameter,
+// and returns a list with one result for each parent, in the same order.
+func hasBatchDirective(comments *ast.CommentGroup) bool {
+	if comments == nil {
+		return false
+	}
+	for _, c := range comments.List {
+		if strings.TrimSpace(c.Text) == "//modus:batch" {
+			return true
+		}
+	}
+	return false
+}
+
 func getDocs(comments *ast.CommentGroup) *metadata.Docs {
 	if comments == nil {
 		return nil
@@ -21,20 +21,26 @@ import (
 
 const MetadataVersion = 2
 
+// HostApiVersion is the version of the runtime host function API that this SDK's imports are built against.
+// This must match the HostApiVersion of the runtime.
+const HostApiVersion = 1
+
 type TypeMap map[string]*TypeDefinition
 type FunctionMap map[string]*Function
 
 type Metadata struct {
-	Plugin    string      `json:"plugin"`
-	Module    string      `json:"module"`
-	SDK       string      `json:"sdk"`
-	BuildId   string      `json:"buildId"`
-	BuildTime string      `json:"buildTs"`
-	GitRepo   string      `json:"gitRepo,omitempty"`
-	GitCommit string      `json:"gitCommit,omitempty"`
-	FnExports FunctionMap `json:"fnExports,omitempty"`
-	FnImports FunctionMap `json:"fnImports,omitempty"`
-	Types     TypeMap     `json:"types,omitempty"`
+	Plugin         string      `json:"plugin"`
+	Module         string      `json:"module"`
+	SDK            string      `json:"sdk"`
+	BuildId        string      `json:"buildId"`
+	BuildTime      string      `json:"buildTs"`
+	GitRepo        string      `json:"gitRepo,omitempty"`
+	GitCommit      string      `json:"gitCommit,omitempty"`
+	HostApiVersion int         `json:"hostApiVersion,omitempty"`
+	Snapshot       bool        `json:"snapshot,omitempty"`
+	FnExports      FunctionMap `json:"fnExports,omitempty"`
+	FnImports      FunctionMap `json:"fnImports,omitempty"`
+	Types          TypeMap     `json:"types,omitempty"`
 }
 
 type Docs struct {
@@ -46,6 +52,7 @@ type Function struct {
 	Parameters []*Parameter `json:"parameters,omitempty"`
 	Results    []*Result    `json:"results,omitempty"`
 	Docs       *Docs        `json:"docs,omitempty"`
+	Batch      bool         `json:"batch,omitempty"`
 }
 
 type TypeDefinition struct {
@@ -75,11 +82,12 @@ type Field struct {
 
 func NewMetadata() *Metadata {
 	return &Metadata{
-		BuildId:   xid.New().String(),
-		BuildTime: utils.TimeNow(),
-		FnExports: make(FunctionMap),
-		FnImports: make(FunctionMap),
-		Types:     make(TypeMap),
+		BuildId:        xid.New().String(),
+		BuildTime:      utils.TimeNow(),
+		HostApiVersion: HostApiVersion,
+		FnExports:      make(FunctionMap),
+		FnImports:      make(FunctionMap),
+		Types:          make(TypeMap),
 	}
 }
 